@@ -96,7 +96,7 @@ func (src *ArgoCD) ConvertTo(dstRaw conversion.Hub) error {
 	dst.Spec.DefaultClusterScopedRoleDisabled = src.Spec.DefaultClusterScopedRoleDisabled
 
 	// Status conversion
-	dst.Status = v1beta1.ArgoCDStatus(src.Status)
+	dst.Status = ConvertAlphaToBetaStatus(src.Status)
 
 	return nil
 }
@@ -164,7 +164,7 @@ func (dst *ArgoCD) ConvertFrom(srcRaw conversion.Hub) error {
 	dst.Spec.DefaultClusterScopedRoleDisabled = src.Spec.DefaultClusterScopedRoleDisabled
 
 	// Status conversion
-	dst.Status = ArgoCDStatus(src.Status)
+	dst.Status = ConvertBetaToAlphaStatus(src.Status)
 
 	return nil
 }
@@ -234,6 +234,10 @@ func ConvertAlphaToBetaWebhookServer(src *WebhookServerSpec) *v1beta1.WebhookSer
 			Host:    src.Host,
 			Ingress: v1beta1.ArgoCDIngressSpec(src.Ingress),
 			Route:   v1beta1.ArgoCDRouteSpec(src.Route),
+			Service: v1beta1.ArgoCDWebhookServiceSpec{
+				Type:              src.Service.Type,
+				ArgoCDServiceSpec: ConvertAlphaToBetaService(src.Service.ArgoCDServiceSpec),
+			},
 		}
 	}
 	return dst
@@ -348,7 +352,7 @@ func ConvertAlphaToBetaServer(src *ArgoCDServerSpec) *v1beta1.ArgoCDServerSpec {
 			Replicas:         src.Replicas,
 			Resources:        src.Resources,
 			Route:            v1beta1.ArgoCDRouteSpec(src.Route),
-			Service:          v1beta1.ArgoCDServerServiceSpec(src.Service),
+			Service:          v1beta1.ArgoCDServerServiceSpec{Type: src.Service.Type, ArgoCDServiceSpec: ConvertAlphaToBetaService(src.Service.ArgoCDServiceSpec)},
 			Env:              src.Env,
 			ExtraCommandArgs: src.ExtraCommandArgs,
 		}
@@ -356,6 +360,10 @@ func ConvertAlphaToBetaServer(src *ArgoCDServerSpec) *v1beta1.ArgoCDServerSpec {
 	return dst
 }
 
+func ConvertAlphaToBetaService(src ArgoCDServiceSpec) v1beta1.ArgoCDServiceSpec {
+	return v1beta1.ArgoCDServiceSpec(src)
+}
+
 func ConvertAlphaToBetaGRPC(src *ArgoCDServerGRPCSpec) *v1beta1.ArgoCDServerGRPCSpec {
 	var dst *v1beta1.ArgoCDServerGRPCSpec
 	if src != nil {
@@ -429,6 +437,25 @@ func ConvertAlphaToBetaResourceHealthChecks(src []ResourceHealthCheck) []v1beta1
 	return dst
 }
 
+func ConvertAlphaToBetaStatus(src ArgoCDStatus) v1beta1.ArgoCDStatus {
+	return v1beta1.ArgoCDStatus{
+		ApplicationController:     src.ApplicationController,
+		ApplicationSetController:  src.ApplicationSetController,
+		SSO:                       src.SSO,
+		NotificationsController:   src.NotificationsController,
+		Phase:                     src.Phase,
+		Redis:                     src.Redis,
+		Repo:                      src.Repo,
+		Server:                    src.Server,
+		RepoTLSChecksum:           src.RepoTLSChecksum,
+		RedisTLSChecksum:          src.RedisTLSChecksum,
+		Host:                      src.Host,
+		RedisPasswordRotation:     src.RedisPasswordRotation,
+		RedisPasswordRotationTime: src.RedisPasswordRotationTime,
+		RedisPasswordRotationID:   src.RedisPasswordRotationID,
+	}
+}
+
 // Conversion funcs for v1beta1 to v1alpha1.
 func ConvertBetaToAlphaController(src *v1beta1.ArgoCDApplicationControllerSpec) *ArgoCDApplicationControllerSpec {
 	var dst *ArgoCDApplicationControllerSpec
@@ -454,6 +481,10 @@ func ConvertBetaToAlphaWebhookServer(src *v1beta1.WebhookServerSpec) *WebhookSer
 			Host:    src.Host,
 			Ingress: ArgoCDIngressSpec(src.Ingress),
 			Route:   ArgoCDRouteSpec(src.Route),
+			Service: ArgoCDWebhookServiceSpec{
+				Type:              src.Service.Type,
+				ArgoCDServiceSpec: ConvertBetaToAlphaService(src.Service.ArgoCDServiceSpec),
+			},
 		}
 	}
 	return dst
@@ -567,7 +598,7 @@ func ConvertBetaToAlphaServer(src *v1beta1.ArgoCDServerSpec) *ArgoCDServerSpec {
 			Replicas:         src.Replicas,
 			Resources:        src.Resources,
 			Route:            ArgoCDRouteSpec(src.Route),
-			Service:          ArgoCDServerServiceSpec(src.Service),
+			Service:          ArgoCDServerServiceSpec{Type: src.Service.Type, ArgoCDServiceSpec: ConvertBetaToAlphaService(src.Service.ArgoCDServiceSpec)},
 			Env:              src.Env,
 			ExtraCommandArgs: src.ExtraCommandArgs,
 		}
@@ -575,6 +606,10 @@ func ConvertBetaToAlphaServer(src *v1beta1.ArgoCDServerSpec) *ArgoCDServerSpec {
 	return dst
 }
 
+func ConvertBetaToAlphaService(src v1beta1.ArgoCDServiceSpec) ArgoCDServiceSpec {
+	return ArgoCDServiceSpec(src)
+}
+
 func ConvertBetaToAlphaGRPC(src *v1beta1.ArgoCDServerGRPCSpec) *ArgoCDServerGRPCSpec {
 	var dst *ArgoCDServerGRPCSpec
 	if src != nil {
@@ -687,3 +722,22 @@ func ConvertBetaToAlphaRepo(src *v1beta1.ArgoCDRepoSpec) *ArgoCDRepoSpec {
 	}
 	return dst
 }
+
+func ConvertBetaToAlphaStatus(src v1beta1.ArgoCDStatus) ArgoCDStatus {
+	return ArgoCDStatus{
+		ApplicationController:     src.ApplicationController,
+		ApplicationSetController:  src.ApplicationSetController,
+		SSO:                       src.SSO,
+		NotificationsController:   src.NotificationsController,
+		Phase:                     src.Phase,
+		Redis:                     src.Redis,
+		Repo:                      src.Repo,
+		Server:                    src.Server,
+		RepoTLSChecksum:           src.RepoTLSChecksum,
+		RedisTLSChecksum:          src.RedisTLSChecksum,
+		Host:                      src.Host,
+		RedisPasswordRotation:     src.RedisPasswordRotation,
+		RedisPasswordRotationTime: src.RedisPasswordRotationTime,
+		RedisPasswordRotationID:   src.RedisPasswordRotationID,
+	}
+}
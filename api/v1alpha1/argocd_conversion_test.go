@@ -556,3 +556,61 @@ func TestBetaToAlphaConversion(t *testing.T) {
 		})
 	}
 }
+
+func TestServiceConversionRoundTrip(t *testing.T) {
+	lbClass := "service.k8s.aws/nlb"
+	service := v1beta1.ArgoCDServiceSpec{
+		Annotations:              map[string]string{"service.beta.kubernetes.io/aws-load-balancer-scheme": "internal"},
+		Labels:                   map[string]string{"exposed": "true"},
+		ExternalTrafficPolicy:    corev1.ServiceExternalTrafficPolicyTypeLocal,
+		LoadBalancerClass:        &lbClass,
+		LoadBalancerSourceRanges: []string{"10.0.0.0/8"},
+		NodePorts:                map[string]int32{"http": 30080},
+	}
+	beta := makeTestArgoCDBeta(func(cr *v1beta1.ArgoCD) {
+		cr.Spec.Server.Service = v1beta1.ArgoCDServerServiceSpec{
+			Type:              corev1.ServiceTypeLoadBalancer,
+			ArgoCDServiceSpec: service,
+		}
+		cr.Spec.ApplicationSet = &v1beta1.ArgoCDApplicationSet{
+			WebhookServer: v1beta1.WebhookServerSpec{
+				Service: v1beta1.ArgoCDWebhookServiceSpec{
+					Type:              corev1.ServiceTypeNodePort,
+					ArgoCDServiceSpec: service,
+				},
+			},
+		}
+	})
+
+	alpha := &ArgoCD{}
+	assert.NoError(t, alpha.ConvertFrom(beta))
+	assert.Equal(t, corev1.ServiceTypeLoadBalancer, alpha.Spec.Server.Service.Type)
+	assert.Equal(t, ArgoCDServiceSpec(service), alpha.Spec.Server.Service.ArgoCDServiceSpec)
+	assert.Equal(t, ArgoCDServiceSpec(service), alpha.Spec.ApplicationSet.WebhookServer.Service.ArgoCDServiceSpec)
+
+	roundTrip := &v1beta1.ArgoCD{}
+	assert.NoError(t, alpha.ConvertTo(roundTrip))
+	assert.Equal(t, beta.Spec.Server.Service, roundTrip.Spec.Server.Service)
+	assert.Equal(t, beta.Spec.ApplicationSet.WebhookServer.Service, roundTrip.Spec.ApplicationSet.WebhookServer.Service)
+}
+
+func TestStatusConversionRoundTrip_redisPasswordRotation(t *testing.T) {
+	rotationTime := metav1.Now()
+	beta := makeTestArgoCDBeta(func(cr *v1beta1.ArgoCD) {
+		cr.Status.RedisPasswordRotation = "RestartingRedis"
+		cr.Status.RedisPasswordRotationTime = &rotationTime
+		cr.Status.RedisPasswordRotationID = "12345"
+	})
+
+	alpha := &ArgoCD{}
+	assert.NoError(t, alpha.ConvertFrom(beta))
+	assert.Equal(t, "RestartingRedis", alpha.Status.RedisPasswordRotation)
+	assert.Equal(t, &rotationTime, alpha.Status.RedisPasswordRotationTime)
+	assert.Equal(t, "12345", alpha.Status.RedisPasswordRotationID)
+
+	roundTrip := &v1beta1.ArgoCD{}
+	assert.NoError(t, alpha.ConvertTo(roundTrip))
+	assert.Equal(t, beta.Status.RedisPasswordRotation, roundTrip.Status.RedisPasswordRotation)
+	assert.Equal(t, beta.Status.RedisPasswordRotationTime, roundTrip.Status.RedisPasswordRotationTime)
+	assert.Equal(t, beta.Status.RedisPasswordRotationID, roundTrip.Status.RedisPasswordRotationID)
+}
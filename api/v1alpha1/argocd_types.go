@@ -567,6 +567,39 @@ type ArgoCDServerServiceSpec struct {
 	// Type is the ServiceType to use for the Service resource.
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Service Type'",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:fieldGroup:Server","urn:alm:descriptor:com.tectonic.ui:text"}
 	Type corev1.ServiceType `json:"type"`
+
+	ArgoCDServiceSpec `json:",inline"`
+}
+
+// ArgoCDServiceSpec defines the customizations of a Service exposing an Argo CD component outside of the cluster.
+type ArgoCDServiceSpec struct {
+	// Annotations is the map of annotations to add to the Service, such as the ones configuring cloud load balancers.
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Labels is the map of labels to add to the Service.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// ExternalTrafficPolicy is the external traffic policy of a NodePort or LoadBalancer Service.
+	ExternalTrafficPolicy corev1.ServiceExternalTrafficPolicy `json:"externalTrafficPolicy,omitempty"`
+
+	// LoadBalancerClass is the class of the load balancer implementation of a LoadBalancer Service. It can not be
+	// changed once set, the Service is recreated when it is.
+	LoadBalancerClass *string `json:"loadBalancerClass,omitempty"`
+
+	// LoadBalancerSourceRanges restricts the client addresses allowed to reach a LoadBalancer Service.
+	LoadBalancerSourceRanges []string `json:"loadBalancerSourceRanges,omitempty"`
+
+	// NodePorts is the map of Service port names to the node ports to use for a NodePort or LoadBalancer Service.
+	// The node ports of the ports not listed are allocated by Kubernetes.
+	NodePorts map[string]int32 `json:"nodePorts,omitempty"`
+}
+
+// ArgoCDWebhookServiceSpec defines the Service options for the ApplicationSet Webhook Server component.
+type ArgoCDWebhookServiceSpec struct {
+	// Type is the ServiceType to use for the Service resource, defaults to ClusterIP.
+	Type corev1.ServiceType `json:"type,omitempty"`
+
+	ArgoCDServiceSpec `json:",inline"`
 }
 
 // Resource Customization for custom health check
@@ -914,6 +947,20 @@ type ArgoCDStatus struct {
 
 	// Host is the hostname of the Ingress.
 	Host string `json:"host,omitempty"`
+
+	// RedisPasswordRotation is a simple, high-level summary of where the latest rotation of the Redis password is in its lifecycle.
+	// There are three possible RedisPasswordRotation values:
+	// RestartingRedis: A new password has been written and the Redis workloads are being restarted with it.
+	// RestartingComponents: Redis is running with the new password and the Argo CD components using it are being restarted.
+	// Completed: All of the components are running with the new password.
+	RedisPasswordRotation string `json:"redisPasswordRotation,omitempty"`
+
+	// RedisPasswordRotationTime is the time at which the latest rotation of the Redis password was started.
+	RedisPasswordRotationTime *metav1.Time `json:"redisPasswordRotationTime,omitempty"`
+
+	// RedisPasswordRotationID identifies the latest rotation of the Redis password. The workloads restarted by the
+	// rotation are labeled with it, so that the rotation can be resumed without restarting them a second time.
+	RedisPasswordRotationID string `json:"redisPasswordRotationID,omitempty"`
 }
 
 // Banner defines an additional banner message to be displayed in Argo CD UI
@@ -956,6 +1003,9 @@ type WebhookServerSpec struct {
 
 	// Route defines the desired state for an OpenShift Route for the Application set webhook component.
 	Route ArgoCDRouteSpec `json:"route,omitempty"`
+
+	// Service defines the options for the Service backing the Application set webhook and metrics.
+	Service ArgoCDWebhookServiceSpec `json:"service,omitempty"`
 }
 
 // IsDeletionFinalizerPresent checks if the instance has deletion finalizer
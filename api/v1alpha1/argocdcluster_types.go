@@ -0,0 +1,176 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func init() {
+	SchemeBuilder.Register(&ArgoCDCluster{}, &ArgoCDClusterList{})
+}
+
+// ArgoCDCluster phases.
+const (
+	// ArgoCDClusterPhaseConnected reports that the cluster secret is up to date, and the cluster reachable with it.
+	ArgoCDClusterPhaseConnected = "Connected"
+
+	// ArgoCDClusterPhaseConnectionFailed reports that the cluster secret is up to date, but the connection test
+	// failed.
+	ArgoCDClusterPhaseConnectionFailed = "ConnectionFailed"
+
+	// ArgoCDClusterPhaseInvalid reports that the cluster secret cannot be written, the message telling why.
+	ArgoCDClusterPhaseInvalid = "Invalid"
+)
+
+//+kubebuilder:object:root=true
+
+// ArgoCDCluster is the Schema for the argocdclusters API, a cluster managed by the Argo CD instance of its namespace.
+// The operator turns it into the cluster secret read by Argo CD.
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=argocdclusters,scope=Namespaced
+// +kubebuilder:printcolumn:name="Server",type=string,JSONPath=`.spec.server`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Version",type=string,JSONPath=`.status.serverVersion`
+// +operator-sdk:csv:customresourcedefinitions:resources={{ArgoCDCluster,v1alpha1,""}}
+// +operator-sdk:csv:customresourcedefinitions:resources={{Secret,v1,""}}
+type ArgoCDCluster struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ArgoCDClusterSpec   `json:"spec,omitempty"`
+	Status ArgoCDClusterStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ArgoCDClusterList contains a list of ArgoCDCluster
+type ArgoCDClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ArgoCDCluster `json:"items"`
+}
+
+// ArgoCDClusterSpec defines the desired state of ArgoCDCluster
+// +k8s:openapi-gen=true
+type ArgoCDClusterSpec struct {
+	// Server is the URL of the API server of the cluster.
+	// +kubebuilder:validation:Pattern=`^https?://`
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Server",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Server string `json:"server"`
+
+	// Name is the name of the cluster in Argo CD, the name of the ArgoCDCluster by default.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Name",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Name string `json:"name,omitempty"`
+
+	// Namespaces restricts Argo CD to these namespaces of the cluster, every namespace being managed when empty.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Namespaces"
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// ClusterResources allows Argo CD to manage the cluster-scoped resources of the cluster when Namespaces are set.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Cluster Resources",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	ClusterResources bool `json:"clusterResources,omitempty"`
+
+	// Shard is the application controller shard managing the cluster, the shard being computed by Argo CD when not set.
+	// +kubebuilder:validation:Minimum=0
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Shard",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	Shard *int64 `json:"shard,omitempty"`
+
+	// Labels are added to the cluster secret, Argo CD using them as the labels of the cluster, such as for the cluster
+	// generator of the ApplicationSets.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// BearerTokenSecret is a reference to the Secret key holding the bearer token used to authenticate with the cluster.
+	BearerTokenSecret *corev1.SecretKeySelector `json:"bearerTokenSecret,omitempty"`
+
+	// ExecProvider defines the command run by Argo CD to get the credentials of the cluster, instead of a bearer token.
+	ExecProvider *ArgoCDClusterExecProvider `json:"execProvider,omitempty"`
+
+	// TLS defines the TLS options used to connect to the cluster.
+	TLS ArgoCDClusterTLS `json:"tls,omitempty"`
+}
+
+// ArgoCDClusterExecProvider defines the command run by Argo CD to get the credentials of a cluster.
+type ArgoCDClusterExecProvider struct {
+	// Command is the command to run, it must be available in the images of the Argo CD components.
+	Command string `json:"command"`
+
+	// Args are the arguments of the command.
+	Args []string `json:"args,omitempty"`
+
+	// Env are the environment variables of the command.
+	Env map[string]string `json:"env,omitempty"`
+
+	// EnvSecretName is the name of a Secret whose keys and values are added to the environment variables of the
+	// command, for the ones holding credentials.
+	EnvSecretName string `json:"envSecretName,omitempty"`
+
+	// APIVersion is the version of the client.authentication.k8s.io API returned by the command.
+	APIVersion string `json:"apiVersion"`
+
+	// InstallHint is shown to the user when the command is not found.
+	InstallHint string `json:"installHint,omitempty"`
+}
+
+// ArgoCDClusterTLS defines the TLS options used to connect to a cluster.
+type ArgoCDClusterTLS struct {
+	// Insecure skips the verification of the certificate of the API server.
+	Insecure bool `json:"insecure,omitempty"`
+
+	// ServerName is the name used to verify the certificate of the API server, the host of the server URL by default.
+	ServerName string `json:"serverName,omitempty"`
+
+	// CAConfigMap is a reference to the ConfigMap key holding the PEM encoded CA certificate used to verify the API
+	// server. The system trust store is used when not set.
+	CAConfigMap *corev1.ConfigMapKeySelector `json:"caConfigMap,omitempty"`
+
+	// ClientCertSecret is a reference to the Secret key holding the PEM encoded client certificate used to
+	// authenticate with the cluster.
+	ClientCertSecret *corev1.SecretKeySelector `json:"clientCertSecret,omitempty"`
+
+	// ClientKeySecret is a reference to the Secret key holding the PEM encoded key of the client certificate.
+	ClientKeySecret *corev1.SecretKeySelector `json:"clientKeySecret,omitempty"`
+}
+
+// ArgoCDClusterStatus defines the observed state of ArgoCDCluster
+// +k8s:openapi-gen=true
+type ArgoCDClusterStatus struct {
+	// Phase is a simple, high-level summary of the cluster.
+	// There are three possible phase values:
+	// Connected: The cluster secret is up to date, and the cluster reachable with it.
+	// ConnectionFailed: The cluster secret is up to date, but the connection test failed.
+	// Invalid: The cluster secret cannot be written, such as when a referenced Secret is missing.
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Phase",xDescriptors={"urn:alm:descriptor:io.kubernetes.phase"}
+	Phase string `json:"phase,omitempty"`
+
+	// Message explains the phase.
+	Message string `json:"message,omitempty"`
+
+	// SecretName is the name of the cluster secret.
+	SecretName string `json:"secretName,omitempty"`
+
+	// ServerVersion is the version of the API server reported by the last successful connection test.
+	ServerVersion string `json:"serverVersion,omitempty"`
+
+	// LastConnectionTime is the time of the last successful connection test.
+	LastConnectionTime *metav1.Time `json:"lastConnectionTime,omitempty"`
+
+	// ObservedGeneration is the generation of the ArgoCDCluster last reconciled.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+func init() {
+	SchemeBuilder.Register(&ArgoCDOperatorConfig{}, &ArgoCDOperatorConfigList{})
+}
+
+// ArgoCDOperatorConfig phases.
+const (
+	// ArgoCDOperatorConfigPhaseAvailable reports that the configuration is in effect.
+	ArgoCDOperatorConfigPhaseAvailable = "Available"
+
+	// ArgoCDOperatorConfigPhaseFailed reports that the configuration is not in effect, the message telling why.
+	ArgoCDOperatorConfigPhaseFailed = "Failed"
+)
+
+//+kubebuilder:object:root=true
+
+// ArgoCDOperatorConfig is the Schema for the argocdoperatorconfigs API, the configuration of the operator. The
+// operator only reads the ArgoCDOperatorConfig named cluster, and applies its changes without restarting.
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=argocdoperatorconfigs,scope=Cluster
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +operator-sdk:csv:customresourcedefinitions:resources={{ArgoCDOperatorConfig,v1alpha1,""}}
+type ArgoCDOperatorConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ArgoCDOperatorConfigSpec   `json:"spec,omitempty"`
+	Status ArgoCDOperatorConfigStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ArgoCDOperatorConfigList contains a list of ArgoCDOperatorConfig
+type ArgoCDOperatorConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ArgoCDOperatorConfig `json:"items"`
+}
+
+// ArgoCDOperatorConfigSpec defines the configuration of the operator. Each property overrides the environment
+// variable of the operator named in its description, the environment variable applying when the property is not set.
+// +k8s:openapi-gen=true
+type ArgoCDOperatorConfigSpec struct {
+	// LabelSelector restricts the ArgoCD instances reconciled by the operator to the ones matching it, an empty
+	// selector matching every instance. Overrides ARGOCD_LABEL_SELECTOR and the --label-selector flag.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Label Selector",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	LabelSelector *string `json:"labelSelector,omitempty"`
+
+	// ClusterConfigNamespaces are the namespaces whose ArgoCD instances manage the resources of the whole cluster,
+	// "*" standing for every namespace. Overrides ARGOCD_CLUSTER_CONFIG_NAMESPACES.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Cluster Config Namespaces"
+	ClusterConfigNamespaces []string `json:"clusterConfigNamespaces,omitempty"`
+
+	// ControllerClusterRole is the cluster role bound to the Argo CD application controller in the managed
+	// namespaces, instead of the default one. Overrides CONTROLLER_CLUSTER_ROLE.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Controller Cluster Role",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	ControllerClusterRole string `json:"controllerClusterRole,omitempty"`
+
+	// ServerClusterRole is the cluster role bound to the Argo CD server in the managed namespaces, instead of the
+	// default one. Overrides SERVER_CLUSTER_ROLE.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Server Cluster Role",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	ServerClusterRole string `json:"serverClusterRole,omitempty"`
+
+	// ConversionWebhook enables the conversion webhook of the ArgoCD API. Overrides ENABLE_CONVERSION_WEBHOOK. Unlike
+	// the other properties, a change is only applied once the operator restarts.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Conversion Webhook",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	ConversionWebhook *bool `json:"conversionWebhook,omitempty"`
+
+	// Images are the default container images of the Argo CD components, used when an ArgoCD sets none.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Images"
+	Images ArgoCDOperatorConfigImages `json:"images,omitempty"`
+
+	// LogLevel is the level of the logs of the operator. Overrides LOG_LEVEL.
+	// +kubebuilder:validation:Enum=debug;info;warn;error;panic;fatal
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Log Level",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	LogLevel string `json:"logLevel,omitempty"`
+}
+
+// ArgoCDOperatorConfigImages defines the default container images of the Argo CD components.
+// +k8s:openapi-gen=true
+type ArgoCDOperatorConfigImages struct {
+	// ArgoCD is the image of the Argo CD components. Overrides ARGOCD_IMAGE.
+	ArgoCD string `json:"argocd,omitempty"`
+
+	// Dex is the image of Dex. Overrides ARGOCD_DEX_IMAGE.
+	Dex string `json:"dex,omitempty"`
+
+	// Keycloak is the image of Keycloak. Overrides ARGOCD_KEYCLOAK_IMAGE.
+	Keycloak string `json:"keycloak,omitempty"`
+
+	// Redis is the image of Redis. Overrides ARGOCD_REDIS_IMAGE.
+	Redis string `json:"redis,omitempty"`
+
+	// RedisHA is the image of Redis in HA mode. Overrides ARGOCD_REDIS_HA_IMAGE.
+	RedisHA string `json:"redisHA,omitempty"`
+
+	// RedisHAProxy is the image of the Redis HA proxy. Overrides ARGOCD_REDIS_HA_PROXY_IMAGE.
+	RedisHAProxy string `json:"redisHAProxy,omitempty"`
+}
+
+// ArgoCDOperatorConfigStatus defines the observed state of ArgoCDOperatorConfig
+// +k8s:openapi-gen=true
+type ArgoCDOperatorConfigStatus struct {
+	// Phase is a simple, high-level summary of the configuration.
+	// There are two possible phase values:
+	// Available: The configuration is in effect.
+	// Failed: The configuration is invalid, or not the one read by the operator, and is not in effect.
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Phase",xDescriptors={"urn:alm:descriptor:io.kubernetes.phase"}
+	Phase string `json:"phase,omitempty"`
+
+	// Message explains the phase, or tells the changes applied once the operator restarts.
+	Message string `json:"message,omitempty"`
+
+	// ObservedGeneration is the generation of the configuration last reconciled.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Effective is the configuration in effect, the one of the spec completed with the environment variables of the
+	// operator.
+	Effective ArgoCDOperatorConfigSpec `json:"effective,omitempty"`
+}
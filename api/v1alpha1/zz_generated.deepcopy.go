@@ -36,7 +36,7 @@ func (in *ArgoCD) DeepCopyInto(out *ArgoCD) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCD.
@@ -189,6 +189,184 @@ func (in *ArgoCDCertificateSpec) DeepCopy() *ArgoCDCertificateSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDCluster) DeepCopyInto(out *ArgoCDCluster) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDCluster.
+func (in *ArgoCDCluster) DeepCopy() *ArgoCDCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ArgoCDCluster) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDClusterExecProvider) DeepCopyInto(out *ArgoCDClusterExecProvider) {
+	*out = *in
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDClusterExecProvider.
+func (in *ArgoCDClusterExecProvider) DeepCopy() *ArgoCDClusterExecProvider {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDClusterExecProvider)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDClusterList) DeepCopyInto(out *ArgoCDClusterList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ArgoCDCluster, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDClusterList.
+func (in *ArgoCDClusterList) DeepCopy() *ArgoCDClusterList {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDClusterList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ArgoCDClusterList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDClusterSpec) DeepCopyInto(out *ArgoCDClusterSpec) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Shard != nil {
+		in, out := &in.Shard, &out.Shard
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.BearerTokenSecret != nil {
+		in, out := &in.BearerTokenSecret, &out.BearerTokenSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExecProvider != nil {
+		in, out := &in.ExecProvider, &out.ExecProvider
+		*out = new(ArgoCDClusterExecProvider)
+		(*in).DeepCopyInto(*out)
+	}
+	in.TLS.DeepCopyInto(&out.TLS)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDClusterSpec.
+func (in *ArgoCDClusterSpec) DeepCopy() *ArgoCDClusterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDClusterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDClusterStatus) DeepCopyInto(out *ArgoCDClusterStatus) {
+	*out = *in
+	if in.LastConnectionTime != nil {
+		in, out := &in.LastConnectionTime, &out.LastConnectionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDClusterStatus.
+func (in *ArgoCDClusterStatus) DeepCopy() *ArgoCDClusterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDClusterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDClusterTLS) DeepCopyInto(out *ArgoCDClusterTLS) {
+	*out = *in
+	if in.CAConfigMap != nil {
+		in, out := &in.CAConfigMap, &out.CAConfigMap
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClientCertSecret != nil {
+		in, out := &in.ClientCertSecret, &out.ClientCertSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClientKeySecret != nil {
+		in, out := &in.ClientKeySecret, &out.ClientKeySecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDClusterTLS.
+func (in *ArgoCDClusterTLS) DeepCopy() *ArgoCDClusterTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDClusterTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArgoCDDexSpec) DeepCopyInto(out *ArgoCDDexSpec) {
 	*out = *in
@@ -572,6 +750,127 @@ func (in *ArgoCDNotifications) DeepCopy() *ArgoCDNotifications {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDOperatorConfig) DeepCopyInto(out *ArgoCDOperatorConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDOperatorConfig.
+func (in *ArgoCDOperatorConfig) DeepCopy() *ArgoCDOperatorConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDOperatorConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ArgoCDOperatorConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDOperatorConfigImages) DeepCopyInto(out *ArgoCDOperatorConfigImages) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDOperatorConfigImages.
+func (in *ArgoCDOperatorConfigImages) DeepCopy() *ArgoCDOperatorConfigImages {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDOperatorConfigImages)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDOperatorConfigList) DeepCopyInto(out *ArgoCDOperatorConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ArgoCDOperatorConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDOperatorConfigList.
+func (in *ArgoCDOperatorConfigList) DeepCopy() *ArgoCDOperatorConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDOperatorConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ArgoCDOperatorConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDOperatorConfigSpec) DeepCopyInto(out *ArgoCDOperatorConfigSpec) {
+	*out = *in
+	if in.LabelSelector != nil {
+		in, out := &in.LabelSelector, &out.LabelSelector
+		*out = new(string)
+		**out = **in
+	}
+	if in.ClusterConfigNamespaces != nil {
+		in, out := &in.ClusterConfigNamespaces, &out.ClusterConfigNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ConversionWebhook != nil {
+		in, out := &in.ConversionWebhook, &out.ConversionWebhook
+		*out = new(bool)
+		**out = **in
+	}
+	out.Images = in.Images
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDOperatorConfigSpec.
+func (in *ArgoCDOperatorConfigSpec) DeepCopy() *ArgoCDOperatorConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDOperatorConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDOperatorConfigStatus) DeepCopyInto(out *ArgoCDOperatorConfigStatus) {
+	*out = *in
+	in.Effective.DeepCopyInto(&out.Effective)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDOperatorConfigStatus.
+func (in *ArgoCDOperatorConfigStatus) DeepCopy() *ArgoCDOperatorConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDOperatorConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArgoCDPrometheusSpec) DeepCopyInto(out *ArgoCDPrometheusSpec) {
 	*out = *in
@@ -832,6 +1131,7 @@ func (in *ArgoCDServerGRPCSpec) DeepCopy() *ArgoCDServerGRPCSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArgoCDServerServiceSpec) DeepCopyInto(out *ArgoCDServerServiceSpec) {
 	*out = *in
+	in.ArgoCDServiceSpec.DeepCopyInto(&out.ArgoCDServiceSpec)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDServerServiceSpec.
@@ -861,7 +1161,7 @@ func (in *ArgoCDServerSpec) DeepCopyInto(out *ArgoCDServerSpec) {
 		(*in).DeepCopyInto(*out)
 	}
 	in.Route.DeepCopyInto(&out.Route)
-	out.Service = in.Service
+	in.Service.DeepCopyInto(&out.Service)
 	if in.Env != nil {
 		in, out := &in.Env, &out.Env
 		*out = make([]v1.EnvVar, len(*in))
@@ -886,6 +1186,52 @@ func (in *ArgoCDServerSpec) DeepCopy() *ArgoCDServerSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDServiceSpec) DeepCopyInto(out *ArgoCDServiceSpec) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LoadBalancerClass != nil {
+		in, out := &in.LoadBalancerClass, &out.LoadBalancerClass
+		*out = new(string)
+		**out = **in
+	}
+	if in.LoadBalancerSourceRanges != nil {
+		in, out := &in.LoadBalancerSourceRanges, &out.LoadBalancerSourceRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodePorts != nil {
+		in, out := &in.NodePorts, &out.NodePorts
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDServiceSpec.
+func (in *ArgoCDServiceSpec) DeepCopy() *ArgoCDServiceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDServiceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArgoCDSpec) DeepCopyInto(out *ArgoCDSpec) {
 	*out = *in
@@ -978,6 +1324,10 @@ func (in *ArgoCDSpec) DeepCopy() *ArgoCDSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArgoCDStatus) DeepCopyInto(out *ArgoCDStatus) {
 	*out = *in
+	if in.RedisPasswordRotationTime != nil {
+		in, out := &in.RedisPasswordRotationTime, &out.RedisPasswordRotationTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDStatus.
@@ -1013,6 +1363,22 @@ func (in *ArgoCDTLSSpec) DeepCopy() *ArgoCDTLSSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDWebhookServiceSpec) DeepCopyInto(out *ArgoCDWebhookServiceSpec) {
+	*out = *in
+	in.ArgoCDServiceSpec.DeepCopyInto(&out.ArgoCDServiceSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDWebhookServiceSpec.
+func (in *ArgoCDWebhookServiceSpec) DeepCopy() *ArgoCDWebhookServiceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDWebhookServiceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Banner) DeepCopyInto(out *Banner) {
 	*out = *in
@@ -1274,6 +1640,7 @@ func (in *WebhookServerSpec) DeepCopyInto(out *WebhookServerSpec) {
 	*out = *in
 	in.Ingress.DeepCopyInto(&out.Ingress)
 	in.Route.DeepCopyInto(&out.Route)
+	in.Service.DeepCopyInto(&out.Service)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookServerSpec.
@@ -0,0 +1,732 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/ast"
+	"github.com/yuin/gopher-lua/parse"
+	"github.com/yuin/gopher-lua/pm"
+	"k8s.io/apimachinery/pkg/api/equality"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	"sigs.k8s.io/yaml"
+)
+
+// luaScriptTimeout is how long a Lua script may run, as Argo CD does.
+const luaScriptTimeout = time.Second
+
+// luaHealthStatuses are the health statuses a health check may return.
+var luaHealthStatuses = []string{"Healthy", "Progressing", "Degraded", "Suspended", "Missing", "Unknown"}
+
+// resourceActions are the actions of a ResourceAction, as read by Argo CD from argocd-cm.
+type resourceActions struct {
+	DiscoveryLua        string                     `json:"discovery.lua,omitempty"`
+	Definitions         []resourceActionDefinition `json:"definitions,omitempty"`
+	MergeBuiltinActions bool                       `json:"mergeBuiltinActions,omitempty"`
+}
+
+// resourceActionDefinition is an action of a ResourceAction.
+type resourceActionDefinition struct {
+	Name      string `json:"name"`
+	ActionLua string `json:"action.lua"`
+}
+
+// compileLuaScript returns an error when the given Lua script does not compile.
+func compileLuaScript(name, script string) error {
+	chunk, err := parse.Parse(strings.NewReader(script), name)
+	if err != nil {
+		return err
+	}
+	_, err = lua.Compile(chunk, name)
+	return err
+}
+
+// luaStringLimit is the largest string a script may build with .., string.rep, string.format, string.gsub or
+// table.concat. The other allocations of a script, such as its tables, are only bounded by luaScriptTimeout.
+const luaStringLimit = 1 << 20
+
+// webhookMaxLuaTests is how many tests of the resource customizations the webhook runs, so that the admission of an
+// ArgoCD stays under the webhook timeout of the API server. The remaining tests are run by the operator.
+const webhookMaxLuaTests = 5
+
+// newLuaState returns a Lua state with the libraries Argo CD opens for the resource customizations: the base, table,
+// string and math libraries, and the os module limited to its time functions. As the scripts run in the operator, the
+// functions loading code or files are removed and require only returns the os module.
+func newLuaState() *lua.LState {
+	l := lua.NewState(lua.Options{SkipOpenLibs: true})
+	for _, lib := range []struct {
+		name string
+		open lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	} {
+		l.Push(l.NewFunction(lib.open))
+		l.Push(lua.LString(lib.name))
+		l.Call(1, 0)
+	}
+	for _, name := range []string{"dofile", "loadfile", "load", "loadstring", "module", "require"} {
+		l.SetGlobal(name, lua.LNil)
+	}
+
+	l.Push(l.NewFunction(lua.OpenOs))
+	l.Push(lua.LString(lua.OsLibName))
+	l.Call(1, 1)
+	lib := l.CheckTable(-1)
+	l.Pop(1)
+	os := l.NewTable()
+	for _, name := range []string{"time", "date", "clock"} {
+		os.RawSetString(name, lib.RawGetString(name))
+	}
+	l.SetGlobal(lua.OsLibName, lua.LNil)
+	l.SetGlobal("require", l.NewFunction(func(l *lua.LState) int {
+		if name := l.CheckString(1); name != lua.OsLibName {
+			l.RaiseError("module %s not found", name)
+		}
+		l.Push(os)
+		return 1
+	}))
+
+	if str, ok := l.GetGlobal(lua.StringLibName).(*lua.LTable); ok {
+		limitLuaFunction(l, str, "string.rep", luaStringRepSize)
+		limitLuaFunction(l, str, "string.format", luaStringFormatSize)
+		limitLuaFunction(l, str, "string.gsub", luaStringGsubSize)
+	}
+	if tab, ok := l.GetGlobal(lua.TabLibName).(*lua.LTable); ok {
+		limitLuaFunction(l, tab, "table.concat", luaTableConcatSize)
+	}
+	return l
+}
+
+// limitLuaFunction replaces the given function of the library with one refusing to build strings larger than
+// luaStringLimit. The size function returns the size of the string built from the arguments, and may replace them.
+func limitLuaFunction(l *lua.LState, lib *lua.LTable, name string, size func(l *lua.LState, args []lua.LValue) int) {
+	key := name[strings.Index(name, ".")+1:]
+	fn := lib.RawGetString(key)
+	lib.RawSetString(key, l.NewFunction(func(l *lua.LState) int {
+		top := l.GetTop()
+		args := make([]lua.LValue, top)
+		for i := range args {
+			args[i] = l.Get(i + 1)
+		}
+		if size(l, args) > luaStringLimit {
+			l.RaiseError("%s result is larger than %d bytes", name, luaStringLimit)
+		}
+		if err := l.CallByParam(lua.P{Fn: fn, NRet: lua.MultRet}, args...); err != nil {
+			l.RaiseError(err.Error())
+		}
+		return l.GetTop() - top
+	}))
+}
+
+// luaStringRepSize returns the size of the string built by string.rep.
+func luaStringRepSize(l *lua.LState, _ []lua.LValue) int {
+	str := l.CheckString(1)
+	n := l.CheckInt(2)
+	if n <= 0 || len(str) == 0 {
+		return 0
+	}
+	if n > luaStringLimit/len(str) {
+		return luaStringLimit + 1
+	}
+	return n * len(str)
+}
+
+// luaStringFormatSize returns an upper bound of the size of the string built by string.format: the format, the
+// arguments, and the widths and precisions of the verbs, with room for the longest formatted numbers.
+func luaStringFormatSize(l *lua.LState, args []lua.LValue) int {
+	format := l.CheckString(1)
+	size := len(format)
+	for _, arg := range args[1:] {
+		if lua.LVCanConvToString(arg) {
+			size += len(lua.LVAsString(arg))
+		}
+	}
+	for i := strings.Index(format, "%"); i >= 0 && size <= luaStringLimit; i = strings.Index(format, "%") {
+		format = format[i+1:]
+		size += 512
+		for len(format) > 0 && strings.IndexByte("-+ #0123456789.", format[0]) >= 0 {
+			j := 0
+			for j < len(format) && format[j] >= '0' && format[j] <= '9' {
+				j++
+			}
+			if j == 0 {
+				format = format[1:]
+				continue
+			}
+			n, err := strconv.Atoi(format[:j])
+			if err != nil || n > luaStringLimit {
+				return luaStringLimit + 1
+			}
+			size += n
+			format = format[j:]
+		}
+	}
+	return size
+}
+
+// luaStringGsubSize returns the size of the string built by string.gsub. As the replacement functions are only run by
+// string.gsub, they are replaced by functions adding the size of their results to the size of the subject.
+func luaStringGsubSize(l *lua.LState, args []lua.LValue) int {
+	str := l.CheckString(1)
+	pattern := l.CheckString(2)
+	l.CheckTypes(3, lua.LTString, lua.LTTable, lua.LTFunction)
+	matches, err := pm.Find(pattern, []byte(str), 0, l.OptInt(4, -1))
+	if err != nil {
+		// string.gsub raises the error
+		return 0
+	}
+
+	size := len(str)
+	switch repl := args[2].(type) {
+	case lua.LString:
+		for _, match := range matches {
+			size += luaGsubReplacementSize(string(repl), str, match) - (match.Capture(1) - match.Capture(0))
+		}
+	case *lua.LTable:
+		for _, match := range matches {
+			idx := 0
+			if match.CaptureLength() > 2 {
+				idx = 2
+			}
+			var value lua.LValue
+			if match.IsPosCapture(idx) {
+				value = l.GetTable(repl, lua.LNumber(match.Capture(idx)))
+			} else {
+				value = l.GetField(repl, str[match.Capture(idx):match.Capture(idx+1)])
+			}
+			if !lua.LVIsFalse(value) {
+				size += len(lua.LVAsString(value)) - (match.Capture(1) - match.Capture(0))
+			}
+		}
+	case *lua.LFunction:
+		args[2] = l.NewFunction(func(l *lua.LState) int {
+			top := l.GetTop()
+			callArgs := make([]lua.LValue, top)
+			for i := range callArgs {
+				callArgs[i] = l.Get(i + 1)
+			}
+			if err := l.CallByParam(lua.P{Fn: repl, NRet: 1}, callArgs...); err != nil {
+				l.RaiseError(err.Error())
+			}
+			if value := l.Get(-1); !lua.LVIsFalse(value) {
+				if size += len(lua.LVAsString(value)); size > luaStringLimit {
+					l.RaiseError("string.gsub result is larger than %d bytes", luaStringLimit)
+				}
+			}
+			return 1
+		})
+	}
+	return size
+}
+
+// luaGsubReplacementSize returns the size of the replacement of the given match by the given string of string.gsub.
+func luaGsubReplacementSize(repl, str string, match *pm.MatchData) int {
+	size := 0
+	for i := 0; i < len(repl); i++ {
+		if repl[i] != '%' || i+1 == len(repl) {
+			size++
+			continue
+		}
+		i++
+		if repl[i] < '0' || repl[i] > '9' {
+			size++
+			continue
+		}
+		idx := 2 * int(repl[i]-'0')
+		if idx >= match.CaptureLength() && idx == 2 {
+			idx = 0
+		}
+		switch {
+		case idx >= match.CaptureLength():
+			// string.gsub raises an invalid capture index error
+		case match.IsPosCapture(idx):
+			size += len(strconv.Itoa(match.Capture(idx)))
+		default:
+			size += match.Capture(idx+1) - match.Capture(idx)
+		}
+	}
+	return size
+}
+
+// luaTableConcatSize returns the size of the string built by table.concat.
+func luaTableConcatSize(l *lua.LState, _ []lua.LValue) int {
+	tbl := l.CheckTable(1)
+	sep := l.OptString(2, "")
+	i := l.OptInt(3, 1)
+	j := l.OptInt(4, tbl.Len())
+	if i < 1 {
+		i = 1
+	}
+	if j > tbl.Len() {
+		j = tbl.Len()
+	}
+	size := 0
+	for k := i; k <= j && size <= luaStringLimit; k++ {
+		if value := tbl.RawGetInt(k); lua.LVCanConvToString(value) {
+			size += len(lua.LVAsString(value))
+		}
+		if k != j {
+			size += len(sep)
+		}
+	}
+	return size
+}
+
+// luaConcatName is the name of the local variable holding luaConcat in the scripts. As it is not an identifier, the
+// scripts cannot refer to it.
+const luaConcatName = "(concat)"
+
+// luaConcat concatenates its arguments like the .. operator of Lua, refusing to build strings larger than
+// luaStringLimit.
+func luaConcat(l *lua.LState) int {
+	rhs := l.Get(l.GetTop())
+	for i := l.GetTop() - 1; i >= 1; i-- {
+		lhs := l.Get(i)
+		if lua.LVCanConvToString(lhs) && lua.LVCanConvToString(rhs) {
+			left, right := lua.LVAsString(lhs), lua.LVAsString(rhs)
+			if len(left)+len(right) > luaStringLimit {
+				l.RaiseError("string concatenation result is larger than %d bytes", luaStringLimit)
+			}
+			rhs = lua.LString(left + right)
+			continue
+		}
+		op := l.GetMetaField(lhs, "__concat")
+		if op == lua.LNil {
+			op = l.GetMetaField(rhs, "__concat")
+		}
+		if op.Type() != lua.LTFunction {
+			l.RaiseError("cannot perform concat operation between %v and %v", lhs.Type().String(), rhs.Type().String())
+		}
+		if err := l.CallByParam(lua.P{Fn: op, NRet: 1}, lhs, rhs); err != nil {
+			l.RaiseError(err.Error())
+		}
+		rhs = l.Get(-1)
+		l.Pop(1)
+	}
+	l.Push(rhs)
+	return 1
+}
+
+// loadLuaScript compiles the given Lua script, its .. operators calling luaConcat. The returned function must be called
+// with luaConcat as its argument.
+func loadLuaScript(l *lua.LState, name, script string) (*lua.LFunction, error) {
+	chunk, err := parse.Parse(strings.NewReader(script), name)
+	if err != nil {
+		return nil, err
+	}
+	limitLuaConcatStmts(chunk)
+	chunk = append([]ast.Stmt{&ast.LocalAssignStmt{
+		Names: []string{luaConcatName},
+		Exprs: []ast.Expr{&ast.Comma3Expr{}},
+	}}, chunk...)
+	proto, err := lua.Compile(chunk, name)
+	if err != nil {
+		return nil, err
+	}
+	return l.NewFunctionFromProto(proto), nil
+}
+
+// limitLuaConcatStmts replaces the .. operators of the given statements with calls to luaConcat.
+func limitLuaConcatStmts(stmts []ast.Stmt) {
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case *ast.AssignStmt:
+			limitLuaConcatExprs(s.Lhs)
+			limitLuaConcatExprs(s.Rhs)
+		case *ast.LocalAssignStmt:
+			limitLuaConcatExprs(s.Exprs)
+		case *ast.FuncCallStmt:
+			s.Expr = limitLuaConcat(s.Expr)
+		case *ast.DoBlockStmt:
+			limitLuaConcatStmts(s.Stmts)
+		case *ast.WhileStmt:
+			s.Condition = limitLuaConcat(s.Condition)
+			limitLuaConcatStmts(s.Stmts)
+		case *ast.RepeatStmt:
+			s.Condition = limitLuaConcat(s.Condition)
+			limitLuaConcatStmts(s.Stmts)
+		case *ast.IfStmt:
+			s.Condition = limitLuaConcat(s.Condition)
+			limitLuaConcatStmts(s.Then)
+			limitLuaConcatStmts(s.Else)
+		case *ast.NumberForStmt:
+			s.Init = limitLuaConcat(s.Init)
+			s.Limit = limitLuaConcat(s.Limit)
+			s.Step = limitLuaConcat(s.Step)
+			limitLuaConcatStmts(s.Stmts)
+		case *ast.GenericForStmt:
+			limitLuaConcatExprs(s.Exprs)
+			limitLuaConcatStmts(s.Stmts)
+		case *ast.FuncDefStmt:
+			s.Name.Func = limitLuaConcat(s.Name.Func)
+			s.Name.Receiver = limitLuaConcat(s.Name.Receiver)
+			limitLuaConcatStmts(s.Func.Stmts)
+		case *ast.ReturnStmt:
+			limitLuaConcatExprs(s.Exprs)
+		}
+	}
+}
+
+// limitLuaConcatExprs replaces the .. operators of the given expressions with calls to luaConcat.
+func limitLuaConcatExprs(exprs []ast.Expr) {
+	for i, expr := range exprs {
+		exprs[i] = limitLuaConcat(expr)
+	}
+}
+
+// limitLuaConcat returns the given expression, its .. operators being replaced with calls to luaConcat. As .. is right
+// associative, a chain of .. operators is a single call.
+func limitLuaConcat(expr ast.Expr) ast.Expr {
+	switch e := expr.(type) {
+	case *ast.StringConcatOpExpr:
+		call := &ast.FuncCallExpr{Func: &ast.IdentExpr{Value: luaConcatName}, AdjustRet: true}
+		call.SetLine(e.Line())
+		call.SetLastLine(e.LastLine())
+		for {
+			call.Args = append(call.Args, limitLuaConcat(e.Lhs))
+			rhs, ok := e.Rhs.(*ast.StringConcatOpExpr)
+			if !ok {
+				// like the operands of .., the last argument is adjusted to a single value
+				last := limitLuaConcat(e.Rhs)
+				switch arg := last.(type) {
+				case *ast.FuncCallExpr:
+					arg.AdjustRet = true
+				case *ast.Comma3Expr:
+					arg.AdjustRet = true
+				}
+				call.Args = append(call.Args, last)
+				return call
+			}
+			e = rhs
+		}
+	case *ast.AttrGetExpr:
+		e.Object = limitLuaConcat(e.Object)
+		e.Key = limitLuaConcat(e.Key)
+	case *ast.TableExpr:
+		for _, field := range e.Fields {
+			field.Key = limitLuaConcat(field.Key)
+			field.Value = limitLuaConcat(field.Value)
+		}
+	case *ast.FuncCallExpr:
+		e.Func = limitLuaConcat(e.Func)
+		e.Receiver = limitLuaConcat(e.Receiver)
+		limitLuaConcatExprs(e.Args)
+	case *ast.LogicalOpExpr:
+		e.Lhs = limitLuaConcat(e.Lhs)
+		e.Rhs = limitLuaConcat(e.Rhs)
+	case *ast.RelationalOpExpr:
+		e.Lhs = limitLuaConcat(e.Lhs)
+		e.Rhs = limitLuaConcat(e.Rhs)
+	case *ast.ArithmeticOpExpr:
+		e.Lhs = limitLuaConcat(e.Lhs)
+		e.Rhs = limitLuaConcat(e.Rhs)
+	case *ast.UnaryMinusOpExpr:
+		e.Expr = limitLuaConcat(e.Expr)
+	case *ast.UnaryNotOpExpr:
+		e.Expr = limitLuaConcat(e.Expr)
+	case *ast.UnaryLenOpExpr:
+		e.Expr = limitLuaConcat(e.Expr)
+	case *ast.FunctionExpr:
+		limitLuaConcatStmts(e.Stmts)
+	}
+	return expr
+}
+
+// luaTestBudget limits how many tests of the resource customizations are run. A nil luaTestBudget runs every test.
+type luaTestBudget struct {
+	remaining int
+	skipped   int
+}
+
+// take returns true if one more test may be run, and counts it as skipped otherwise.
+func (b *luaTestBudget) take() bool {
+	if b == nil {
+		return true
+	}
+	if b.remaining <= 0 {
+		b.skipped++
+		return false
+	}
+	b.remaining--
+	return true
+}
+
+// runLuaScript runs the given Lua script with the given object as the global obj, and returns the value it returns.
+func runLuaScript(script string, obj map[string]interface{}) (interface{}, error) {
+	l := newLuaState()
+	defer l.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), luaScriptTimeout)
+	defer cancel()
+	l.SetContext(ctx)
+
+	l.SetGlobal("obj", toLuaValue(l, obj))
+	fn, err := loadLuaScript(l, "<string>", script)
+	if err != nil {
+		return nil, err
+	}
+	l.Push(fn)
+	l.Push(l.NewFunction(luaConcat))
+	if err := l.PCall(1, lua.MultRet, nil); err != nil {
+		return nil, err
+	}
+	return fromLuaValue(l.Get(-1)), nil
+}
+
+// toLuaValue returns the Lua value of the given JSON value.
+func toLuaValue(l *lua.LState, value interface{}) lua.LValue {
+	switch v := value.(type) {
+	case bool:
+		return lua.LBool(v)
+	case string:
+		return lua.LString(v)
+	case int64:
+		return lua.LNumber(v)
+	case float64:
+		return lua.LNumber(v)
+	case []interface{}:
+		t := l.NewTable()
+		for _, item := range v {
+			t.Append(toLuaValue(l, item))
+		}
+		return t
+	case map[string]interface{}:
+		t := l.NewTable()
+		for key, item := range v {
+			t.RawSetString(key, toLuaValue(l, item))
+		}
+		return t
+	}
+	return lua.LNil
+}
+
+// fromLuaValue returns the JSON value of the given Lua value, the integral numbers being int64 like the ones decoded
+// from the objects. The tables whose keys are 1 to n are arrays.
+func fromLuaValue(value lua.LValue) interface{} {
+	switch v := value.(type) {
+	case lua.LBool:
+		return bool(v)
+	case lua.LString:
+		return string(v)
+	case lua.LNumber:
+		if f := float64(v); f == math.Trunc(f) && math.Abs(f) < math.MaxInt64 {
+			return int64(f)
+		}
+		return float64(v)
+	case *lua.LTable:
+		size := 0
+		v.ForEach(func(lua.LValue, lua.LValue) { size++ })
+		if n := v.MaxN(); n > 0 && n == size {
+			items := make([]interface{}, 0, n)
+			for i := 1; i <= n; i++ {
+				items = append(items, fromLuaValue(v.RawGetInt(i)))
+			}
+			return items
+		}
+		m := map[string]interface{}{}
+		v.ForEach(func(key, item lua.LValue) {
+			m[key.String()] = fromLuaValue(item)
+		})
+		return m
+	}
+	return nil
+}
+
+// decodeTestObject decodes the given object of a test.
+func decodeTestObject(data []byte) (map[string]interface{}, error) {
+	obj := map[string]interface{}{}
+	if len(data) == 0 {
+		return obj, nil
+	}
+	if err := utiljson.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// Validate returns an error when the health check does not compile, or fails one of its tests.
+func (c ResourceHealthCheck) Validate() error {
+	return c.validate(nil)
+}
+
+// validate returns an error when the health check does not compile, or fails one of the tests the budget allows.
+func (c ResourceHealthCheck) validate(budget *luaTestBudget) error {
+	if err := compileLuaScript("health.lua", c.Check); err != nil {
+		return err
+	}
+	for _, test := range c.Tests {
+		if !budget.take() {
+			continue
+		}
+		if err := c.runTest(test); err != nil {
+			return fmt.Errorf("test %s failed: %w", test.Name, err)
+		}
+	}
+	return nil
+}
+
+// runTest runs the health check against the object of the given test.
+func (c ResourceHealthCheck) runTest(test ResourceHealthCheckTest) error {
+	obj, err := decodeTestObject(test.Object.Raw)
+	if err != nil {
+		return err
+	}
+	ret, err := runLuaScript(c.Check, obj)
+	if err != nil {
+		return err
+	}
+	health, ok := ret.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("expect table output from Lua script, not %T", ret)
+	}
+
+	status, _ := health["status"].(string)
+	valid := false
+	for _, s := range luaHealthStatuses {
+		valid = valid || s == status
+	}
+	if !valid {
+		return fmt.Errorf("invalid health status %q", status)
+	}
+	if status != test.Status {
+		return fmt.Errorf("got health status %q, want %q", status, test.Status)
+	}
+	if message, _ := health["message"].(string); test.Message != "" && message != test.Message {
+		return fmt.Errorf("got health message %q, want %q", message, test.Message)
+	}
+	return nil
+}
+
+// Validate returns an error when the actions cannot be parsed, one of their scripts does not compile, or they fail
+// one of their tests.
+func (c ResourceAction) Validate() error {
+	return c.validate(nil)
+}
+
+// validate returns an error when the actions cannot be parsed, one of their scripts does not compile, or they fail
+// one of the tests the budget allows.
+func (c ResourceAction) validate(budget *luaTestBudget) error {
+	actions := resourceActions{}
+	if err := yaml.Unmarshal([]byte(c.Action), &actions); err != nil {
+		return fmt.Errorf("failed to parse the actions: %w", err)
+	}
+	if err := compileLuaScript("discovery.lua", actions.DiscoveryLua); err != nil {
+		return err
+	}
+	definitions := map[string]string{}
+	for _, definition := range actions.Definitions {
+		if strings.TrimSpace(definition.ActionLua) == "" {
+			return fmt.Errorf("action %s has no action.lua script", definition.Name)
+		}
+		if err := compileLuaScript(definition.Name, definition.ActionLua); err != nil {
+			return err
+		}
+		definitions[definition.Name] = definition.ActionLua
+	}
+
+	for _, test := range c.Tests {
+		script, ok := definitions[test.Action]
+		if !ok {
+			return fmt.Errorf("test %s failed: no action %s", test.Name, test.Action)
+		}
+		if !budget.take() {
+			continue
+		}
+		if err := runActionTest(script, test); err != nil {
+			return fmt.Errorf("test %s failed: %w", test.Name, err)
+		}
+	}
+	return nil
+}
+
+// runActionTest runs the given action script against the object of the given test.
+func runActionTest(script string, test ResourceActionTest) error {
+	obj, err := decodeTestObject(test.Object.Raw)
+	if err != nil {
+		return err
+	}
+	expected, err := decodeTestObject(test.Expected.Raw)
+	if err != nil {
+		return err
+	}
+	ret, err := runLuaScript(script, obj)
+	if err != nil {
+		return err
+	}
+
+	// the actions may return the list of the resources they impact, the object being the patched one
+	if impacted, ok := ret.([]interface{}); ok {
+		ret = nil
+		for _, item := range impacted {
+			if resource, ok := item.(map[string]interface{}); ok && resource["operation"] == "patch" {
+				ret = resource["resource"]
+			}
+		}
+	}
+	result, ok := ret.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("expect table output from Lua script, not %T", ret)
+	}
+	if !equality.Semantic.DeepEqual(result, expected) {
+		data, _ := yaml.Marshal(result)
+		return fmt.Errorf("got object:\n%s", data)
+	}
+	return nil
+}
+
+// ValidateResourceCustomizations returns the ResourceHealthChecks and ResourceActions of the ArgoCD whose Lua scripts
+// do not compile or fail their tests.
+func (argocd *ArgoCD) ValidateResourceCustomizations() []ResourceCustomizationFailure {
+	return argocd.validateResourceCustomizationsWithBudget(nil)
+}
+
+// validateResourceCustomizationsWithBudget returns the ResourceHealthChecks and ResourceActions of the ArgoCD whose
+// Lua scripts do not compile or fail one of the tests the budget allows.
+func (argocd *ArgoCD) validateResourceCustomizationsWithBudget(budget *luaTestBudget) []ResourceCustomizationFailure {
+	var failures []ResourceCustomizationFailure
+	for _, check := range argocd.Spec.ResourceHealthChecks {
+		if err := check.validate(budget); err != nil {
+			failures = append(failures, ResourceCustomizationFailure{
+				Type:    ResourceCustomizationTypeHealthCheck,
+				Group:   check.Group,
+				Kind:    check.Kind,
+				Message: err.Error(),
+			})
+		}
+	}
+	for _, action := range argocd.Spec.ResourceActions {
+		if err := action.validate(budget); err != nil {
+			failures = append(failures, ResourceCustomizationFailure{
+				Type:    ResourceCustomizationTypeAction,
+				Group:   action.Group,
+				Kind:    action.Kind,
+				Message: err.Error(),
+			})
+		}
+	}
+	return failures
+}
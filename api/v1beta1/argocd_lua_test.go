@@ -0,0 +1,315 @@
+package v1beta1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const testHealthCheck = `hs = {}
+if obj.status ~= nil and obj.status.replicas == obj.spec.replicas then
+  hs.status = "Healthy"
+  hs.message = "all " .. obj.spec.replicas .. " replicas are ready"
+  return hs
+end
+hs.status = "Progressing"
+return hs
+`
+
+const testActions = `discovery.lua: |
+  return {scale = {disabled = false}}
+definitions:
+- name: scale
+  action.lua: |
+    obj.spec.replicas = obj.spec.replicas + 1
+    obj.metadata.annotations = {scaled = "true"}
+    return obj
+- name: restart
+  action.lua: |
+    return {{operation = "create", resource = {kind = "Job"}}, {operation = "patch", resource = obj}}
+`
+
+func Test_ResourceHealthCheck_Validate(t *testing.T) {
+	object := runtime.RawExtension{Raw: []byte(`{"spec":{"replicas":3},"status":{"replicas":3}}`)}
+
+	testdata := []struct {
+		name    string
+		check   ResourceHealthCheck
+		wantErr string
+	}{
+		{
+			name:  "no test",
+			check: ResourceHealthCheck{Check: testHealthCheck},
+		},
+		{
+			name:    "syntax error",
+			check:   ResourceHealthCheck{Check: "hs = {\nreturn hs"},
+			wantErr: "health.lua",
+		},
+		{
+			name: "passing tests",
+			check: ResourceHealthCheck{Check: testHealthCheck, Tests: []ResourceHealthCheckTest{
+				{Name: "ready", Object: object, Status: "Healthy", Message: "all 3 replicas are ready"},
+				{Name: "scaling", Object: runtime.RawExtension{Raw: []byte(`{"spec":{"replicas":3}}`)}, Status: "Progressing"},
+			}},
+		},
+		{
+			name: "unexpected status",
+			check: ResourceHealthCheck{Check: testHealthCheck, Tests: []ResourceHealthCheckTest{
+				{Name: "ready", Object: object, Status: "Degraded"},
+			}},
+			wantErr: `test ready failed: got health status "Healthy", want "Degraded"`,
+		},
+		{
+			name: "unexpected message",
+			check: ResourceHealthCheck{Check: testHealthCheck, Tests: []ResourceHealthCheckTest{
+				{Name: "ready", Object: object, Status: "Healthy", Message: "ready"},
+			}},
+			wantErr: `got health message "all 3 replicas are ready", want "ready"`,
+		},
+		{
+			name: "invalid status",
+			check: ResourceHealthCheck{Check: `return {status = "Fine"}`, Tests: []ResourceHealthCheckTest{
+				{Name: "ready", Object: object, Status: "Healthy"},
+			}},
+			wantErr: `invalid health status "Fine"`,
+		},
+		{
+			name: "runtime error",
+			check: ResourceHealthCheck{Check: `return {status = obj.status.conditions[1].type}`, Tests: []ResourceHealthCheckTest{
+				{Name: "ready", Object: object, Status: "Healthy"},
+			}},
+			wantErr: "test ready failed",
+		},
+		{
+			name: "unsafe library",
+			check: ResourceHealthCheck{Check: `os.execute("true") return {status = "Healthy"}`, Tests: []ResourceHealthCheckTest{
+				{Name: "ready", Object: object, Status: "Healthy"},
+			}},
+			wantErr: "test ready failed",
+		},
+		{
+			name: "os time functions",
+			check: ResourceHealthCheck{Check: `local os = require("os") if os.time() > 0 then return {status = "Healthy"} end`, Tests: []ResourceHealthCheckTest{
+				{Name: "ready", Object: object, Status: "Healthy"},
+			}},
+		},
+		{
+			name: "file access",
+			check: ResourceHealthCheck{Check: `dofile("/etc/passwd") return {status = "Healthy"}`, Tests: []ResourceHealthCheckTest{
+				{Name: "ready", Object: object, Status: "Healthy"},
+			}},
+			wantErr: "test ready failed",
+		},
+		{
+			name: "other module",
+			check: ResourceHealthCheck{Check: `local io = require("io") return {status = "Healthy"}`, Tests: []ResourceHealthCheckTest{
+				{Name: "ready", Object: object, Status: "Healthy"},
+			}},
+			wantErr: "module io not found",
+		},
+		{
+			name: "large string",
+			check: ResourceHealthCheck{Check: `local s = string.rep("x", 2^31) return {status = "Healthy"}`, Tests: []ResourceHealthCheckTest{
+				{Name: "ready", Object: object, Status: "Healthy"},
+			}},
+			wantErr: "string.rep result is larger than",
+		},
+		{
+			name: "large concatenation",
+			check: ResourceHealthCheck{Check: `local s = "x" while true do s = s .. s end`, Tests: []ResourceHealthCheckTest{
+				{Name: "ready", Object: object, Status: "Healthy"},
+			}},
+			wantErr: "string concatenation result is larger than",
+		},
+		{
+			name: "large concatenation in a function",
+			check: ResourceHealthCheck{Check: `local function double(s) return (s .. "") .. s end local s = "x" for i = 1, 30 do s = double(s) end`, Tests: []ResourceHealthCheckTest{
+				{Name: "ready", Object: object, Status: "Healthy"},
+			}},
+			wantErr: "string concatenation result is larger than",
+		},
+		{
+			name: "large table concatenation",
+			check: ResourceHealthCheck{Check: `local t = {} for i = 1, 2048 do t[i] = string.rep("x", 1024) end local s = table.concat(t)`, Tests: []ResourceHealthCheckTest{
+				{Name: "ready", Object: object, Status: "Healthy"},
+			}},
+			wantErr: "table.concat result is larger than",
+		},
+		{
+			name: "large format",
+			check: ResourceHealthCheck{Check: `local s = string.format("%2000000000d", 1)`, Tests: []ResourceHealthCheckTest{
+				{Name: "ready", Object: object, Status: "Healthy"},
+			}},
+			wantErr: "string.format result is larger than",
+		},
+		{
+			name: "large substitution",
+			check: ResourceHealthCheck{Check: `local s = string.gsub(string.rep("x", 1024), "x", string.rep("y", 2048))`, Tests: []ResourceHealthCheckTest{
+				{Name: "ready", Object: object, Status: "Healthy"},
+			}},
+			wantErr: "string.gsub result is larger than",
+		},
+		{
+			name: "large substitution by a function",
+			check: ResourceHealthCheck{Check: `local s = string.gsub(string.rep("x", 1024), "x", function() return string.rep("y", 2048) end)`, Tests: []ResourceHealthCheckTest{
+				{Name: "ready", Object: object, Status: "Healthy"},
+			}},
+			wantErr: "string.gsub result is larger than",
+		},
+		{
+			name: "string functions",
+			check: ResourceHealthCheck{Check: `
+local t = setmetatable({}, {__concat = function(a, b) return "meta" end})
+local s = "a" .. 1 .. "b" .. (t .. "c") .. table.concat({"d", 2}, "-") .. string.format("%03d", 7) ..
+  string.gsub("hello world", "(%w+)", "<%1>") .. string.gsub("x", "x", {x = "y"}) .. string.gsub("z", "z", function(z) return z .. z end)
+if s == "a1bmetad-2007<hello> <world>yzz" then return {status = "Healthy"} end
+return {status = "Degraded", message = s}`, Tests: []ResourceHealthCheckTest{
+				{Name: "ready", Object: object, Status: "Healthy"},
+			}},
+		},
+		{
+			name: "timeout",
+			check: ResourceHealthCheck{Check: `while true do end`, Tests: []ResourceHealthCheckTest{
+				{Name: "ready", Object: object, Status: "Healthy"},
+			}},
+			wantErr: "test ready failed",
+		},
+	}
+	for _, tt := range testdata {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.check.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_ResourceAction_Validate(t *testing.T) {
+	object := runtime.RawExtension{Raw: []byte(`{"metadata":{"name":"web"},"spec":{"replicas":3}}`)}
+	scaled := runtime.RawExtension{Raw: []byte(`{"metadata":{"name":"web","annotations":{"scaled":"true"}},"spec":{"replicas":4}}`)}
+
+	testdata := []struct {
+		name    string
+		action  ResourceAction
+		wantErr string
+	}{
+		{
+			name:   "no test",
+			action: ResourceAction{Action: testActions},
+		},
+		{
+			name:    "invalid yaml",
+			action:  ResourceAction{Action: "definitions: scale"},
+			wantErr: "failed to parse the actions",
+		},
+		{
+			name:    "syntax error",
+			action:  ResourceAction{Action: "definitions:\n- name: scale\n  action.lua: return obj +"},
+			wantErr: "scale",
+		},
+		{
+			name:    "missing script",
+			action:  ResourceAction{Action: "definitions:\n- name: scale\n  action: return obj"},
+			wantErr: "action scale has no action.lua script",
+		},
+		{
+			name: "passing tests",
+			action: ResourceAction{Action: testActions, Tests: []ResourceActionTest{
+				{Name: "scale", Action: "scale", Object: object, Expected: scaled},
+				{Name: "restart", Action: "restart", Object: object, Expected: object},
+			}},
+		},
+		{
+			name: "unexpected object",
+			action: ResourceAction{Action: testActions, Tests: []ResourceActionTest{
+				{Name: "scale", Action: "scale", Object: object, Expected: object},
+			}},
+			wantErr: "test scale failed: got object",
+		},
+		{
+			name: "unknown action",
+			action: ResourceAction{Action: testActions, Tests: []ResourceActionTest{
+				{Name: "resume", Action: "resume", Object: object, Expected: object},
+			}},
+			wantErr: "test resume failed: no action resume",
+		},
+	}
+	for _, tt := range testdata {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.action.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_ArgoCD_ValidateResourceCustomizations(t *testing.T) {
+	cr := &ArgoCD{}
+	cr.Spec.ResourceHealthChecks = []ResourceHealthCheck{
+		{Group: "apps", Kind: "Deployment", Check: testHealthCheck},
+		{Group: "argoproj.io", Kind: "Rollout", Check: "return {"},
+	}
+	cr.Spec.ResourceActions = []ResourceAction{
+		{Group: "apps", Kind: "Deployment", Action: testActions},
+		{Kind: "ConfigMap", Action: "definitions: restart"},
+	}
+
+	failures := cr.ValidateResourceCustomizations()
+	assert.Len(t, failures, 2)
+	assert.Equal(t, ResourceCustomizationTypeHealthCheck, failures[0].Type)
+	assert.Equal(t, "Rollout", failures[0].Kind)
+	assert.Equal(t, ResourceCustomizationTypeAction, failures[1].Type)
+	assert.Equal(t, "ConfigMap", failures[1].Kind)
+
+	_, err := cr.ValidateCreate()
+	assert.ErrorContains(t, err, "HealthCheck argoproj.io/Rollout")
+
+	// updates leaving the failed resource customizations unchanged are accepted with warnings
+	old := cr.DeepCopy()
+	cr.Spec.DisableAdmin = true
+	warnings, err := cr.ValidateUpdate(old)
+	assert.NoError(t, err)
+	assert.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "HealthCheck argoproj.io/Rollout")
+
+	// updates changing them are rejected, unless the ArgoCD is being deleted
+	cr.Spec.ResourceHealthChecks[1].Check = "return {status = "
+	_, err = cr.ValidateUpdate(old)
+	assert.ErrorContains(t, err, "HealthCheck argoproj.io/Rollout")
+	cr.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	warnings, err = cr.ValidateUpdate(old)
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+	cr.DeletionTimestamp = nil
+
+	cr.Spec.ResourceHealthChecks = cr.Spec.ResourceHealthChecks[:1]
+	cr.Spec.ResourceActions = cr.Spec.ResourceActions[:1]
+	assert.Empty(t, cr.ValidateResourceCustomizations())
+	_, err = cr.ValidateUpdate(&ArgoCD{})
+	assert.NoError(t, err)
+}
+
+func Test_ArgoCD_ValidateCreate_testBudget(t *testing.T) {
+	object := runtime.RawExtension{Raw: []byte(`{"spec":{"replicas":3}}`)}
+	tests := []ResourceHealthCheckTest{}
+	for i := 0; i < webhookMaxLuaTests+2; i++ {
+		tests = append(tests, ResourceHealthCheckTest{Name: "scaling", Object: object, Status: "Progressing"})
+	}
+	cr := &ArgoCD{}
+	cr.Spec.ResourceHealthChecks = []ResourceHealthCheck{{Group: "apps", Kind: "Deployment", Check: testHealthCheck, Tests: tests}}
+
+	warnings, err := cr.ValidateCreate()
+	assert.NoError(t, err)
+	assert.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "2 resource customization tests were not run on admission")
+}
@@ -26,7 +26,9 @@ import (
 	autoscaling "k8s.io/api/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 func init() {
@@ -228,6 +230,39 @@ type ArgoCDDexSpec struct {
 	Env []corev1.EnvVar `json:"env,omitempty"`
 }
 
+// ArgoCDGatewayRouteSpec defines the desired state for a Gateway API route.
+type ArgoCDGatewayRouteSpec struct {
+	// Annotations is the map of annotations to use for the route resource.
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Labels is the map of labels to use for the route resource.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Enabled will toggle the creation of the Gateway API route.
+	Enabled bool `json:"enabled"`
+
+	// ParentRefs references the Gateways the route attaches to.
+	ParentRefs []ArgoCDGatewayParentReference `json:"parentRefs,omitempty"`
+
+	// Path is the path prefix matched by the route. Only used by HTTP routes, defaults to "/".
+	Path string `json:"path,omitempty"`
+}
+
+// ArgoCDGatewayParentReference identifies a Gateway a route attaches to.
+type ArgoCDGatewayParentReference struct {
+	// Name is the name of the Gateway.
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the Gateway, defaults to the namespace of the route.
+	Namespace string `json:"namespace,omitempty"`
+
+	// SectionName is the name of the Gateway listener to attach to, all listeners are used when empty.
+	SectionName string `json:"sectionName,omitempty"`
+
+	// Port is the port of the Gateway listener to attach to, all ports are used when empty.
+	Port *int32 `json:"port,omitempty"`
+}
+
 // ArgoCDGrafanaSpec defines the desired state for the Grafana component.
 type ArgoCDGrafanaSpec struct {
 	// Enabled will toggle Grafana support globally for ArgoCD.
@@ -275,6 +310,42 @@ type ArgoCDHASpec struct {
 
 	// Resources defines the Compute Resources required by the container for HA.
 	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// RedisReplicas defines the number of Redis server replicas, each paired with a Sentinel, in the Redis HA StatefulSet. Defaults to 3.
+	// +kubebuilder:validation:Minimum=3
+	RedisReplicas *int32 `json:"redisReplicas,omitempty"`
+
+	// SentinelQuorum defines the number of Sentinels that need to agree about the fact the master is not reachable,
+	// in order to start a failover. Defaults to a majority of RedisReplicas.
+	// +kubebuilder:validation:Minimum=1
+	SentinelQuorum *int32 `json:"sentinelQuorum,omitempty"`
+
+	// RedisProxyReplicas defines the number of replicas for the Redis HAProxy Deployment. Defaults to 1.
+	// +kubebuilder:validation:Minimum=1
+	RedisProxyReplicas *int32 `json:"redisProxyReplicas,omitempty"`
+
+	// Persistence defines the options for persisting Redis data across pod restarts.
+	Persistence *ArgoCDRedisHAPersistenceSpec `json:"persistence,omitempty"`
+}
+
+// ArgoCDRedisHAPersistenceSpec defines the persistence options for the Redis HA StatefulSet.
+type ArgoCDRedisHAPersistenceSpec struct {
+	// Enabled will toggle the creation of a PersistentVolumeClaim for every Redis server replica.
+	Enabled bool `json:"enabled"`
+
+	// Size is the requested storage size of each PersistentVolumeClaim. Defaults to 1Gi.
+	Size *resource.Quantity `json:"size,omitempty"`
+
+	// StorageClassName is the name of the StorageClass used for the PersistentVolumeClaims. The cluster default is used if not set.
+	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// AccessModes contains the desired access modes of the PersistentVolumeClaims. Defaults to ReadWriteOnce.
+	AccessModes []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+}
+
+// IsEnabled returns true if persistence has been requested for the Redis HA StatefulSet.
+func (p *ArgoCDRedisHAPersistenceSpec) IsEnabled() bool {
+	return p != nil && p.Enabled
 }
 
 // ArgoCDImportSpec defines the desired state for the ArgoCD import/restore process.
@@ -442,12 +513,69 @@ type ArgoCDRedisSpec struct {
 
 	// Remote specifies the remote URL of the Redis container. (optional, by default, a local instance managed by the operator is used.)
 	Remote *string `json:"remote,omitempty"`
+
+	// RemoteConfig defines the authentication, TLS and sentinel options used to connect to the remote Redis. (optional)
+	RemoteConfig *ArgoCDRemoteRedisSpec `json:"remoteConfig,omitempty"`
+
+	// PasswordRotation defines the options for rotating the password of the Redis instance managed by the operator.
+	PasswordRotation *ArgoCDRedisPasswordRotationSpec `json:"passwordRotation,omitempty"`
 }
 
 func (a *ArgoCDRedisSpec) IsEnabled() bool {
 	return a.Enabled == nil || (a.Enabled != nil && *a.Enabled)
 }
 
+// ArgoCDRemoteRedisSpec defines the options for connecting to a Redis instance that is not managed by the operator.
+type ArgoCDRemoteRedisSpec struct {
+	// UsernameSecret is a reference to the Secret key holding the username used to authenticate with the remote Redis (Redis ACL).
+	UsernameSecret *corev1.SecretKeySelector `json:"usernameSecret,omitempty"`
+
+	// PasswordSecret is a reference to the Secret key holding the password used to authenticate with the remote Redis.
+	PasswordSecret *corev1.SecretKeySelector `json:"passwordSecret,omitempty"`
+
+	// TLS enables TLS for the connections to the remote Redis.
+	TLS bool `json:"tls,omitempty"`
+
+	// CAConfigMap is a reference to the ConfigMap key holding the PEM encoded CA certificate used to verify the remote Redis.
+	// The system trust store is used when not set. Setting it implies TLS.
+	CAConfigMap *corev1.ConfigMapKeySelector `json:"caConfigMap,omitempty"`
+
+	// Sentinel defines the Redis Sentinel options, the remote Redis is accessed through sentinel when set.
+	Sentinel *ArgoCDRemoteRedisSentinelSpec `json:"sentinel,omitempty"`
+}
+
+// ArgoCDRemoteRedisSentinelSpec defines the options for connecting to a remote Redis through Redis Sentinel.
+type ArgoCDRemoteRedisSentinelSpec struct {
+	// Addresses is the list of sentinel addresses, in host:port format.
+	Addresses []string `json:"addresses"`
+
+	// MasterName is the name of the Redis master monitored by the sentinels. (optional, default `master`)
+	MasterName string `json:"masterName,omitempty"`
+
+	// UsernameSecret is a reference to the Secret key holding the username used to authenticate with the sentinels (Redis ACL).
+	UsernameSecret *corev1.SecretKeySelector `json:"usernameSecret,omitempty"`
+
+	// PasswordSecret is a reference to the Secret key holding the password used to authenticate with the sentinels.
+	// The sentinels are accessed without authentication when not set.
+	PasswordSecret *corev1.SecretKeySelector `json:"passwordSecret,omitempty"`
+}
+
+// ArgoCDRedisPasswordRotationSpec defines the options for rotating the Redis password.
+type ArgoCDRedisPasswordRotationSpec struct {
+	// Enabled will toggle the rotation of the Redis password. When enabled, a rotation can be requested at any time
+	// by setting the argocds.argoproj.io/rotate-redis-password annotation on the ArgoCD resource to a new value.
+	Enabled bool `json:"enabled"`
+
+	// Interval is the maximum age of the Redis password, after which the operator rotates it automatically (e.g. 720h).
+	// The password is only rotated on request when not set.
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// IsEnabled returns true if rotation of the Redis password has been enabled.
+func (p *ArgoCDRedisPasswordRotationSpec) IsEnabled() bool {
+	return p != nil && p.Enabled
+}
+
 // ArgoCDRepoSpec defines the desired state for the Argo CD repo server component.
 type ArgoCDRepoSpec struct {
 
@@ -507,6 +635,9 @@ type ArgoCDRepoSpec struct {
 	// SidecarContainers defines the list of sidecar containers for the repo server deployment
 	SidecarContainers []corev1.Container `json:"sidecarContainers,omitempty"`
 
+	// Plugins defines the list of Config Management Plugins (v2), each one running as a sidecar container of the repo server deployment
+	Plugins []ArgoCDRepoPluginSpec `json:"plugins,omitempty"`
+
 	// Enabled is the flag to enable Repo Server during ArgoCD installation. (optional, default `true`)
 	Enabled *bool `json:"enabled,omitempty"`
 
@@ -518,6 +649,120 @@ func (a *ArgoCDRepoSpec) IsEnabled() bool {
 	return a.Enabled == nil || (a.Enabled != nil && *a.Enabled)
 }
 
+// ArgoCDRepoPluginSpec defines a Config Management Plugin running as a sidecar of the repo server.
+type ArgoCDRepoPluginSpec struct {
+	// Name is the name of the plugin, also used as the name of its sidecar container.
+	Name string `json:"name"`
+
+	// Image is the container image providing the tools used by the plugin.
+	Image string `json:"image"`
+
+	// ImagePullPolicy is the pull policy of the plugin image. (optional, default `Always`)
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// Resources defines the Compute Resources required by the plugin container.
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Env lets you specify environment for the plugin container.
+	Env []corev1.EnvVar `json:"env,omitempty"`
+
+	// VolumeMounts adds volumeMounts to the plugin container, the volumes are declared in the repo server Volumes.
+	VolumeMounts []corev1.VolumeMount `json:"volumeMounts,omitempty"`
+
+	// Spec is the plugin configuration, written to the plugin.yaml file of the plugin.
+	Spec ArgoCDPluginSpec `json:"spec"`
+}
+
+// ArgoCDPluginSpec defines the configuration of a Config Management Plugin, as read by the cmp-server from plugin.yaml.
+type ArgoCDPluginSpec struct {
+	// Version is the version of the plugin. When set, Applications refer to the plugin as <name>-<version>.
+	Version string `json:"version,omitempty"`
+
+	// Init is the command run in the application source directory before generating the manifests.
+	Init *ArgoCDPluginCommand `json:"init,omitempty"`
+
+	// Generate is the command generating the manifests, printed as YAML or JSON on stdout.
+	Generate ArgoCDPluginCommand `json:"generate"`
+
+	// Discover defines how the plugin detects the applications it supports.
+	Discover *ArgoCDPluginDiscover `json:"discover,omitempty"`
+
+	// Parameters defines the parameters announced by the plugin.
+	Parameters *ArgoCDPluginParameters `json:"parameters,omitempty"`
+
+	// PreserveFileMode keeps the file modes of the repository files when passing them to the plugin.
+	PreserveFileMode bool `json:"preserveFileMode,omitempty"`
+}
+
+// ArgoCDPluginCommand defines a command run by a Config Management Plugin.
+type ArgoCDPluginCommand struct {
+	// Command is the command to run.
+	Command []string `json:"command,omitempty"`
+
+	// Args are the arguments of the command.
+	Args []string `json:"args,omitempty"`
+}
+
+// ArgoCDPluginDiscover defines how a Config Management Plugin detects the applications it supports.
+type ArgoCDPluginDiscover struct {
+	// Find selects the applications with a glob or a command, matching when the command prints something.
+	Find *ArgoCDPluginFind `json:"find,omitempty"`
+
+	// FileName selects the applications containing a file matching the given glob.
+	FileName string `json:"fileName,omitempty"`
+}
+
+// ArgoCDPluginFind defines the glob or command used by a Config Management Plugin to detect applications.
+type ArgoCDPluginFind struct {
+	// Command is the command to run.
+	Command []string `json:"command,omitempty"`
+
+	// Args are the arguments of the command.
+	Args []string `json:"args,omitempty"`
+
+	// Glob selects the applications containing a file matching the glob.
+	Glob string `json:"glob,omitempty"`
+}
+
+// ArgoCDPluginParameters defines the parameters announced by a Config Management Plugin.
+type ArgoCDPluginParameters struct {
+	// Static is the list of parameters known in advance.
+	Static []ArgoCDPluginStaticParameter `json:"static,omitempty"`
+
+	// Dynamic is the command printing the parameters as a JSON list, in the same format as Static.
+	Dynamic *ArgoCDPluginCommand `json:"dynamic,omitempty"`
+}
+
+// ArgoCDPluginStaticParameter defines a parameter announced by a Config Management Plugin.
+type ArgoCDPluginStaticParameter struct {
+	// Name is the name of the parameter.
+	Name string `json:"name"`
+
+	// Title is the human readable name of the parameter.
+	Title string `json:"title,omitempty"`
+
+	// Tooltip is the help text of the parameter.
+	Tooltip string `json:"tooltip,omitempty"`
+
+	// Required indicates whether the parameter must be set.
+	Required bool `json:"required,omitempty"`
+
+	// ItemType is the type of the items of the parameter. (optional, default `string`)
+	ItemType string `json:"itemType,omitempty"`
+
+	// CollectionType is the type of collection of the parameter, one of `string`, `array` or `map`. (optional, default `string`)
+	CollectionType string `json:"collectionType,omitempty"`
+
+	// String is the default value of a `string` parameter.
+	String string `json:"string,omitempty"`
+
+	// Array is the default value of an `array` parameter.
+	Array []string `json:"array,omitempty"`
+
+	// Map is the default value of a `map` parameter.
+	Map map[string]string `json:"map,omitempty"`
+}
+
 // ArgoCDRouteSpec defines the desired state for an OpenShift Route.
 type ArgoCDRouteSpec struct {
 	// Annotations is the map of annotations to use for the Route resource.
@@ -559,10 +804,21 @@ type ArgoCDServerGRPCSpec struct {
 	// Ingress defines the desired state for the Argo CD Server GRPC Ingress.
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="GRPC Ingress Enabled'",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:fieldGroup:Server","urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
 	Ingress ArgoCDIngressSpec `json:"ingress,omitempty"`
+
+	// GRPCRoute defines the desired state for a Gateway API GRPCRoute for the Argo CD Server GRPC endpoint.
+	GRPCRoute ArgoCDGatewayRouteSpec `json:"grpcRoute,omitempty"`
+
+	// Route defines the desired state for an OpenShift Route for the Argo CD Server GRPC endpoint.
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="GRPC Route Enabled'",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:fieldGroup:Server","urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	Route ArgoCDRouteSpec `json:"route,omitempty"`
 }
 
 // ArgoCDServerSpec defines the options for the ArgoCD Server component.
 type ArgoCDServerSpec struct {
+	// AdditionalHosts is a list of hostnames, in addition to Host, the Argo CD Server is reachable at through its
+	// Ingress/Route resources.
+	AdditionalHosts []string `json:"additionalHosts,omitempty"`
+
 	// Autoscale defines the autoscale options for the Argo CD Server component.
 	Autoscale ArgoCDServerAutoscaleSpec `json:"autoscale,omitempty"`
 
@@ -573,6 +829,9 @@ type ArgoCDServerSpec struct {
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Host",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:fieldGroup:Server","urn:alm:descriptor:com.tectonic.ui:text"}
 	Host string `json:"host,omitempty"`
 
+	// HTTPRoute defines the desired state for a Gateway API HTTPRoute for the Argo CD Server component.
+	HTTPRoute ArgoCDGatewayRouteSpec `json:"httpRoute,omitempty"`
+
 	// Ingress defines the desired state for an Ingress for the Argo CD Server component.
 	Ingress ArgoCDIngressSpec `json:"ingress,omitempty"`
 
@@ -620,6 +879,39 @@ type ArgoCDServerServiceSpec struct {
 	// Type is the ServiceType to use for the Service resource.
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Service Type'",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:fieldGroup:Server","urn:alm:descriptor:com.tectonic.ui:text"}
 	Type corev1.ServiceType `json:"type"`
+
+	ArgoCDServiceSpec `json:",inline"`
+}
+
+// ArgoCDServiceSpec defines the customizations of a Service exposing an Argo CD component outside of the cluster.
+type ArgoCDServiceSpec struct {
+	// Annotations is the map of annotations to add to the Service, such as the ones configuring cloud load balancers.
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Labels is the map of labels to add to the Service.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// ExternalTrafficPolicy is the external traffic policy of a NodePort or LoadBalancer Service.
+	ExternalTrafficPolicy corev1.ServiceExternalTrafficPolicy `json:"externalTrafficPolicy,omitempty"`
+
+	// LoadBalancerClass is the class of the load balancer implementation of a LoadBalancer Service. It can not be
+	// changed once set, the Service is recreated when it is.
+	LoadBalancerClass *string `json:"loadBalancerClass,omitempty"`
+
+	// LoadBalancerSourceRanges restricts the client addresses allowed to reach a LoadBalancer Service.
+	LoadBalancerSourceRanges []string `json:"loadBalancerSourceRanges,omitempty"`
+
+	// NodePorts is the map of Service port names to the node ports to use for a NodePort or LoadBalancer Service.
+	// The node ports of the ports not listed are allocated by Kubernetes.
+	NodePorts map[string]int32 `json:"nodePorts,omitempty"`
+}
+
+// ArgoCDWebhookServiceSpec defines the Service options for the ApplicationSet Webhook Server component.
+type ArgoCDWebhookServiceSpec struct {
+	// Type is the ServiceType to use for the Service resource, defaults to ClusterIP.
+	Type corev1.ServiceType `json:"type,omitempty"`
+
+	ArgoCDServiceSpec `json:",inline"`
 }
 
 // Resource Customization for custom health check
@@ -627,6 +919,27 @@ type ResourceHealthCheck struct {
 	Group string `json:"group,omitempty"`
 	Kind  string `json:"kind,omitempty"`
 	Check string `json:"check,omitempty"`
+
+	// Tests are the objects the health check is run against, along with the health expected for each of them.
+	Tests []ResourceHealthCheckTest `json:"tests,omitempty"`
+}
+
+// ResourceHealthCheckTest is a test of a health check: the health expected for an object.
+type ResourceHealthCheckTest struct {
+	// Name is the name of the test, reported when it fails.
+	Name string `json:"name"`
+
+	// Object is the resource given to the health check.
+	//+kubebuilder:pruning:PreserveUnknownFields
+	//+kubebuilder:validation:Type=object
+	Object runtime.RawExtension `json:"object"`
+
+	// Status is the health status expected from the health check.
+	//+kubebuilder:validation:Enum=Healthy;Progressing;Degraded;Suspended;Missing;Unknown
+	Status string `json:"status"`
+
+	// Message is the message expected from the health check, not checked when empty.
+	Message string `json:"message,omitempty"`
 }
 
 // Resource Customization for ignore difference
@@ -653,6 +966,29 @@ type ResourceAction struct {
 	Group  string `json:"group,omitempty"`
 	Kind   string `json:"kind,omitempty"`
 	Action string `json:"action,omitempty"`
+
+	// Tests are the objects the actions are run against, along with the object expected from each run.
+	Tests []ResourceActionTest `json:"tests,omitempty"`
+}
+
+// ResourceActionTest is a test of an action: the object expected from the action run against an object.
+type ResourceActionTest struct {
+	// Name is the name of the test, reported when it fails.
+	Name string `json:"name"`
+
+	// Action is the name of the action definition to run.
+	Action string `json:"action"`
+
+	// Object is the resource given to the action.
+	//+kubebuilder:pruning:PreserveUnknownFields
+	//+kubebuilder:validation:Type=object
+	Object runtime.RawExtension `json:"object"`
+
+	// Expected is the resource expected from the action, the one patched when the action returns the list of the
+	// resources it impacts.
+	//+kubebuilder:pruning:PreserveUnknownFields
+	//+kubebuilder:validation:Type=object
+	Expected runtime.RawExtension `json:"expected"`
 }
 
 // SSOProviderType string defines the type of SSO provider.
@@ -717,6 +1053,9 @@ type ArgoCDSpec struct {
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Application Instance Label Key'",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text","urn:alm:descriptor:com.tectonic.ui:advanced"}
 	ApplicationInstanceLabelKey string `json:"applicationInstanceLabelKey,omitempty"`
 
+	// Bootstrap defines the AppProjects and Applications the operator creates once the Argo CD server is available.
+	Bootstrap *ArgoCDBootstrapSpec `json:"bootstrap,omitempty"`
+
 	// ConfigManagementPlugins is used to specify additional config management plugins.
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Config Management Plugins'",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text","urn:alm:descriptor:com.tectonic.ui:advanced"}
 	ConfigManagementPlugins string `json:"configManagementPlugins,omitempty"`
@@ -765,6 +1104,7 @@ type ArgoCDSpec struct {
 	Import *ArgoCDImportSpec `json:"import,omitempty"`
 
 	// InitialRepositories to configure Argo CD with upon creation of the cluster.
+	// Deprecated: use Repositories instead, the repositories of this YAML list are migrated to repository Secrets.
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Initial Repositories'",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text","urn:alm:descriptor:com.tectonic.ui:advanced"}
 	InitialRepositories string `json:"initialRepositories,omitempty"`
 
@@ -782,30 +1122,62 @@ type ArgoCDSpec struct {
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="OIDC Config'",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text","urn:alm:descriptor:com.tectonic.ui:advanced"}
 	OIDCConfig string `json:"oidcConfig,omitempty"`
 
+	// ManagedNamespaceSelector selects the namespaces, in addition to the ones labeled with
+	// argocd.argoproj.io/managed-by, the Argo CD instance manages.
+	ManagedNamespaceSelector *metav1.LabelSelector `json:"managedNamespaceSelector,omitempty"`
+
 	// Monitoring defines whether workload status monitoring configuration for this instance.
 	Monitoring ArgoCDMonitoringSpec `json:"monitoring,omitempty"`
 
+	// NetworkPolicy defines the NetworkPolicies restricting the traffic of the Argo CD components.
+	NetworkPolicy *ArgoCDNetworkPolicySpec `json:"networkPolicy,omitempty"`
+
 	// NodePlacement defines NodeSelectors and Taints for Argo CD workloads
 	NodePlacement *ArgoCDNodePlacementSpec `json:"nodePlacement,omitempty"`
 
 	// Notifications defines whether the Argo CD Notifications controller should be installed.
 	Notifications ArgoCDNotifications `json:"notifications,omitempty"`
 
+	// PausedComponents lists the components whose resources are left untouched by the operator, while the other
+	// components of the instance are reconciled. The changes the operator would make to these resources are reported
+	// in the drift of the status.
+	PausedComponents []ArgoCDComponentName `json:"pausedComponents,omitempty"`
+
 	// Prometheus defines the Prometheus server options for ArgoCD.
 	Prometheus ArgoCDPrometheusSpec `json:"prometheus,omitempty"`
 
+	// Proxy defines the proxy used by the Argo CD components for outbound traffic. The proxy environment of the
+	// operator is used when not set.
+	Proxy *ArgoCDProxySpec `json:"proxy,omitempty"`
+
 	// RBAC defines the RBAC configuration for Argo CD.
 	RBAC ArgoCDRBACSpec `json:"rbac,omitempty"`
 
 	// Redis defines the Redis server options for ArgoCD.
 	Redis ArgoCDRedisSpec `json:"redis,omitempty"`
 
+	// ReconcileStrategy defines how the operator reconciles the instance. Active enforces the desired state, Paused
+	// stops reconciling the instance and ObserveOnly reports in the status and through events the drift from the
+	// desired state without changing anything. The argocds.argoproj.io/reconcile-strategy annotation, when set,
+	// takes precedence. Defaults to Active.
+	// +kubebuilder:validation:Enum=Active;Paused;ObserveOnly
+	ReconcileStrategy ArgoCDReconcileStrategy `json:"reconcileStrategy,omitempty"`
+
 	// Repo defines the repo server options for Argo CD.
 	Repo ArgoCDRepoSpec `json:"repo,omitempty"`
 
+	// Repositories are the repositories Argo CD connects to, reconciled into repository Secrets.
+	Repositories []ArgoCDRepository `json:"repositories,omitempty"`
+
 	// RepositoryCredentials are the Git pull credentials to configure Argo CD with upon creation of the cluster.
+	// Deprecated: use RepositoryCredentialTemplates instead, the templates of this YAML list are migrated to repository
+	// credential Secrets.
 	RepositoryCredentials string `json:"repositoryCredentials,omitempty"`
 
+	// RepositoryCredentialTemplates are the credentials used by the repositories whose URL starts with the URL of a
+	// template, reconciled into repository credential Secrets.
+	RepositoryCredentialTemplates []ArgoCDRepositoryCredentialTemplate `json:"repositoryCredentialTemplates,omitempty"`
+
 	// ResourceHealthChecks customizes resource health check behavior.
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Resource Health Check Customizations'",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text","urn:alm:descriptor:com.tectonic.ui:advanced"}
 	ResourceHealthChecks []ResourceHealthCheck `json:"resourceHealthChecks,omitempty"`
@@ -819,13 +1191,26 @@ type ArgoCDSpec struct {
 	ResourceActions []ResourceAction `json:"resourceActions,omitempty"`
 
 	// ResourceExclusions is used to completely ignore entire classes of resource group/kinds.
+	// Deprecated: use ResourceExclusionRules instead, the YAML list of ResourceExclusions is merged with them.
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Resource Exclusions'",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text","urn:alm:descriptor:com.tectonic.ui:advanced"}
 	ResourceExclusions string `json:"resourceExclusions,omitempty"`
 
+	// ResourceExclusionRules are the classes of resources completely ignored by Argo CD.
+	ResourceExclusionRules []ArgoCDResourceFilterRule `json:"resourceExclusionRules,omitempty"`
+
+	// ResourceExclusionPreset adds the built-in exclusions of the given preset to ResourceExclusionRules.
+	//+kubebuilder:validation:Enum=None;Recommended
+	ResourceExclusionPreset ArgoCDResourceExclusionPreset `json:"resourceExclusionPreset,omitempty"`
+
 	// ResourceInclusions is used to only include specific group/kinds in the
 	// reconciliation process.
+	// Deprecated: use ResourceInclusionRules instead, the YAML list of ResourceInclusions is merged with them.
 	ResourceInclusions string `json:"resourceInclusions,omitempty"`
 
+	// ResourceInclusionRules are the only classes of resources managed by Argo CD, every resource being included when
+	// empty.
+	ResourceInclusionRules []ArgoCDResourceFilterRule `json:"resourceInclusionRules,omitempty"`
+
 	// ResourceTrackingMethod defines how Argo CD should track resources that it manages
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Resource Tracking Method'",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text","urn:alm:descriptor:com.tectonic.ui:advanced"}
 	ResourceTrackingMethod string `json:"resourceTrackingMethod,omitempty"`
@@ -836,6 +1221,10 @@ type ArgoCDSpec struct {
 	// SourceNamespaces defines the namespaces application resources are allowed to be created in
 	SourceNamespaces []string `json:"sourceNamespaces,omitempty"`
 
+	// SourceNamespaceSelector selects the namespaces, in addition to SourceNamespaces, application resources are allowed
+	// to be created in.
+	SourceNamespaceSelector *metav1.LabelSelector `json:"sourceNamespaceSelector,omitempty"`
+
 	// SSO defines the Single Sign-on configuration for Argo CD
 	SSO *ArgoCDSSOSpec `json:"sso,omitempty"`
 
@@ -846,6 +1235,14 @@ type ArgoCDSpec struct {
 	// TLS defines the TLS options for ArgoCD.
 	TLS ArgoCDTLSSpec `json:"tls,omitempty"`
 
+	// TrustedCA is a reference to the ConfigMap key holding a PEM encoded CA bundle, added to the system trust
+	// of the Argo CD components talking to external services (Git and Helm repositories, SCM providers, OIDC and
+	// notification services).
+	TrustedCA *corev1.ConfigMapKeySelector `json:"trustedCA,omitempty"`
+
+	// Upgrade defines how a change of the Argo CD version is rolled out to the components.
+	Upgrade ArgoCDUpgradeSpec `json:"upgrade,omitempty"`
+
 	// UsersAnonymousEnabled toggles anonymous user access.
 	// The anonymous users get default role permissions specified argocd-rbac-cm.
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Anonymous Users Enabled'",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch","urn:alm:descriptor:com.tectonic.ui:advanced"}
@@ -862,6 +1259,201 @@ type ArgoCDSpec struct {
 	DefaultClusterScopedRoleDisabled bool `json:"defaultClusterScopedRoleDisabled,omitempty"`
 }
 
+// ArgoCDProxySpec defines the proxy used by the Argo CD components for outbound traffic.
+type ArgoCDProxySpec struct {
+	// HTTPProxy is the URL of the proxy for HTTP requests.
+	HTTPProxy string `json:"httpProxy,omitempty"`
+
+	// HTTPSProxy is the URL of the proxy for HTTPS requests.
+	HTTPSProxy string `json:"httpsProxy,omitempty"`
+
+	// NoProxy is a comma-separated list of hostnames, domains and CIDRs for which the proxy is not used. The
+	// cluster internal domains and the Kubernetes API server are always added.
+	NoProxy string `json:"noProxy,omitempty"`
+}
+
+// ArgoCDResourceFilterRule matches the resources of a class of group/kinds, in the clusters managed by Argo CD. The
+// values are globs, an empty list matching every value.
+type ArgoCDResourceFilterRule struct {
+	// APIGroups are the API groups of the resources, "" being the core group.
+	APIGroups []string `json:"apiGroups,omitempty"`
+
+	// Kinds are the kinds of the resources.
+	Kinds []string `json:"kinds,omitempty"`
+
+	// Clusters are the URLs of the API servers of the clusters.
+	Clusters []string `json:"clusters,omitempty"`
+}
+
+// ArgoCDResourceExclusionPreset is a set of built-in resource exclusions.
+type ArgoCDResourceExclusionPreset string
+
+const (
+	// ResourceExclusionPresetNone adds no resource exclusion.
+	ResourceExclusionPresetNone ArgoCDResourceExclusionPreset = "None"
+
+	// ResourceExclusionPresetRecommended excludes the high-volume resources which are not managed through Git, such as
+	// the events, leases, endpoints, metrics and access reviews.
+	ResourceExclusionPresetRecommended ArgoCDResourceExclusionPreset = "Recommended"
+)
+
+// ArgoCDRepositoryType is the type of a repository.
+// +kubebuilder:validation:Enum=git;helm
+type ArgoCDRepositoryType string
+
+const (
+	// ArgoCDRepositoryTypeGit is the type of the Git repositories.
+	ArgoCDRepositoryTypeGit ArgoCDRepositoryType = "git"
+
+	// ArgoCDRepositoryTypeHelm is the type of the Helm chart repositories.
+	ArgoCDRepositoryTypeHelm ArgoCDRepositoryType = "helm"
+)
+
+// ArgoCDRepository defines a repository Argo CD connects to.
+type ArgoCDRepository struct {
+	// URL is the URL of the repository.
+	URL string `json:"url"`
+
+	// Type is the type of the repository, git by default.
+	Type ArgoCDRepositoryType `json:"type,omitempty"`
+
+	// Name is the name of the repository, required for the Helm chart repositories.
+	Name string `json:"name,omitempty"`
+
+	// Project restricts the repository to the given Argo CD project.
+	Project string `json:"project,omitempty"`
+
+	// Insecure skips the verification of the server certificate and SSH host key of the repository.
+	Insecure bool `json:"insecure,omitempty"`
+
+	// EnableLFS enables Git LFS for the repository.
+	EnableLFS bool `json:"enableLFS,omitempty"`
+
+	// EnableOCI enables the OCI registry support of a Helm chart repository.
+	EnableOCI bool `json:"enableOCI,omitempty"`
+
+	// Proxy is the URL of the HTTP proxy used to connect to the repository.
+	Proxy string `json:"proxy,omitempty"`
+
+	ArgoCDRepositoryCredentials `json:",inline"`
+}
+
+// ArgoCDRepositoryCredentialTemplate defines the credentials used by the repositories whose URL starts with the URL
+// of the template, unless they define their own.
+type ArgoCDRepositoryCredentialTemplate struct {
+	// URL is the URL prefix of the repositories using the credentials.
+	URL string `json:"url"`
+
+	// Type is the type of the repositories, git by default.
+	Type ArgoCDRepositoryType `json:"type,omitempty"`
+
+	// EnableOCI enables the OCI registry support of the Helm chart repositories.
+	EnableOCI bool `json:"enableOCI,omitempty"`
+
+	// Proxy is the URL of the HTTP proxy used to connect to the repositories.
+	Proxy string `json:"proxy,omitempty"`
+
+	ArgoCDRepositoryCredentials `json:",inline"`
+}
+
+// ArgoCDRepositoryCredentials defines the credentials used to connect to a repository, read from the Secrets of the
+// namespace of the ArgoCD.
+type ArgoCDRepositoryCredentials struct {
+	// Username is the username used to authenticate with the repository.
+	Username string `json:"username,omitempty"`
+
+	// UsernameSecret is a reference to the Secret key holding the username, instead of Username.
+	UsernameSecret *corev1.SecretKeySelector `json:"usernameSecret,omitempty"`
+
+	// PasswordSecret is a reference to the Secret key holding the password or token used to authenticate with the
+	// repository.
+	PasswordSecret *corev1.SecretKeySelector `json:"passwordSecret,omitempty"`
+
+	// SSHPrivateKeySecret is a reference to the Secret key holding the SSH private key used to authenticate with the
+	// repository.
+	SSHPrivateKeySecret *corev1.SecretKeySelector `json:"sshPrivateKeySecret,omitempty"`
+
+	// GitHubApp defines the GitHub App used to authenticate with the repository.
+	GitHubApp *ArgoCDRepositoryGitHubApp `json:"githubApp,omitempty"`
+
+	// TLSClientCertSecret is a reference to the Secret key holding the PEM encoded TLS client certificate used to
+	// authenticate with the repository.
+	TLSClientCertSecret *corev1.SecretKeySelector `json:"tlsClientCertSecret,omitempty"`
+
+	// TLSClientKeySecret is a reference to the Secret key holding the PEM encoded key of the TLS client certificate.
+	TLSClientKeySecret *corev1.SecretKeySelector `json:"tlsClientKeySecret,omitempty"`
+}
+
+// ArgoCDRepositoryGitHubApp defines the GitHub App used to authenticate with a repository.
+type ArgoCDRepositoryGitHubApp struct {
+	// ID is the ID of the GitHub App.
+	// +kubebuilder:validation:Minimum=1
+	ID int64 `json:"id"`
+
+	// InstallationID is the ID of the installation of the GitHub App.
+	// +kubebuilder:validation:Minimum=1
+	InstallationID int64 `json:"installationID"`
+
+	// EnterpriseBaseURL is the base URL of the API of a GitHub Enterprise server, GitHub being used when not set.
+	EnterpriseBaseURL string `json:"enterpriseBaseURL,omitempty"`
+
+	// PrivateKeySecret is a reference to the Secret key holding the private key of the GitHub App.
+	PrivateKeySecret corev1.SecretKeySelector `json:"privateKeySecret"`
+}
+
+// ArgoCDBootstrapOwnership is the owner of a bootstrap object once it is created.
+// +kubebuilder:validation:Enum=ArgoCD;Operator
+type ArgoCDBootstrapOwnership string
+
+const (
+	// ArgoCDBootstrapOwnershipArgoCD leaves the object to Argo CD once the operator created it, such as for a root
+	// Application managing itself.
+	ArgoCDBootstrapOwnershipArgoCD ArgoCDBootstrapOwnership = "ArgoCD"
+
+	// ArgoCDBootstrapOwnershipOperator keeps the spec of the object reconciled by the operator.
+	ArgoCDBootstrapOwnershipOperator ArgoCDBootstrapOwnership = "Operator"
+)
+
+// ArgoCDBootstrapSpec defines the AppProjects and Applications created once the Argo CD server is available.
+type ArgoCDBootstrapSpec struct {
+	// Projects are the AppProjects to create, such as the default project with restrictions. They are created before
+	// the Applications.
+	Projects []ArgoCDBootstrapObject `json:"projects,omitempty"`
+
+	// Applications are the Applications to create, such as an app-of-apps root Application.
+	Applications []ArgoCDBootstrapObject `json:"applications,omitempty"`
+}
+
+// ArgoCDBootstrapObject defines an AppProject or an Application created in the namespace of the ArgoCD.
+type ArgoCDBootstrapObject struct {
+	// Name is the name of the object.
+	Name string `json:"name"`
+
+	// Labels are the labels of the object.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Spec is the spec of the object, as defined by the AppProject or Application API of Argo CD.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Type=object
+	Spec runtime.RawExtension `json:"spec"`
+
+	// Ownership is the owner of the object once it is created. With ArgoCD, the operator only applies the spec once,
+	// updating the object when it already exists. With Operator, the operator keeps applying the spec. Defaults to
+	// ArgoCD.
+	Ownership ArgoCDBootstrapOwnership `json:"ownership,omitempty"`
+}
+
+// ArgoCDNetworkPolicySpec defines the NetworkPolicies generated for the Argo CD components.
+type ArgoCDNetworkPolicySpec struct {
+	// Enabled will toggle the creation of a default deny NetworkPolicy for the Argo CD components, together with the
+	// NetworkPolicies allowing the traffic each component requires.
+	Enabled bool `json:"enabled"`
+
+	// EgressCIDRs is the list of CIDRs outside of the cluster the Argo CD components may connect to, such as Git and
+	// Helm repositories, SSO providers, managed clusters and notification services.
+	EgressCIDRs []string `json:"egressCIDRs,omitempty"`
+}
+
 // ArgoCDStatus defines the observed state of ArgoCD
 // +k8s:openapi-gen=true
 type ArgoCDStatus struct {
@@ -945,6 +1537,200 @@ type ArgoCDStatus struct {
 
 	// Host is the hostname of the Ingress.
 	Host string `json:"host,omitempty"`
+
+	// RedisPasswordRotation is a simple, high-level summary of where the latest rotation of the Redis password is in its lifecycle.
+	// There are three possible RedisPasswordRotation values:
+	// RestartingRedis: A new password has been written and the Redis workloads are being restarted with it.
+	// RestartingComponents: Redis is running with the new password and the Argo CD components using it are being restarted.
+	// Completed: All of the components are running with the new password.
+	RedisPasswordRotation string `json:"redisPasswordRotation,omitempty"`
+
+	// RedisPasswordRotationTime is the time at which the latest rotation of the Redis password was started.
+	RedisPasswordRotationTime *metav1.Time `json:"redisPasswordRotationTime,omitempty"`
+
+	// RedisPasswordRotationID identifies the latest rotation of the Redis password. The workloads restarted by the
+	// rotation are labeled with it, so that the rotation can be resumed without restarting them a second time.
+	RedisPasswordRotationID string `json:"redisPasswordRotationID,omitempty"`
+
+	// ReconcileStrategy is the strategy the latest reconciliation of the ArgoCD was made with.
+	// There are three possible ReconcileStrategy values:
+	// Active: The desired state of the ArgoCD is enforced.
+	// Paused: The ArgoCD is not reconciled.
+	// ObserveOnly: The drift from the desired state of the ArgoCD is reported, nothing is changed.
+	ReconcileStrategy ArgoCDReconcileStrategy `json:"reconcileStrategy,omitempty"`
+
+	// Drift lists the resources the latest reconciliation would have changed, but left untouched because the ArgoCD
+	// is observed only or because their component is paused.
+	Drift []ArgoCDResourceDrift `json:"drift,omitempty"`
+
+	// Inventory lists the objects written by the operator for the ArgoCD, along with the hash of their content as
+	// last written by the operator.
+	Inventory []ArgoCDManagedResource `json:"inventory,omitempty"`
+
+	// Version is the Argo CD version the components run, as of the latest completed upgrade.
+	Version string `json:"version,omitempty"`
+
+	// Upgrade is the state of the latest upgrade of the Argo CD version.
+	Upgrade *ArgoCDUpgradeStatus `json:"upgrade,omitempty"`
+
+	// ResourceCustomizationFailures are the ResourceHealthChecks and ResourceActions whose Lua scripts do not compile
+	// or fail their tests. They are left out of argocd-cm until they are fixed.
+	ResourceCustomizationFailures []ResourceCustomizationFailure `json:"resourceCustomizationFailures,omitempty"`
+
+	// ResourceCustomizationsObservedGeneration is the generation of the ArgoCD whose ResourceHealthChecks and
+	// ResourceActions were last validated. The Lua scripts are only validated again when the spec changes.
+	ResourceCustomizationsObservedGeneration int64 `json:"resourceCustomizationsObservedGeneration,omitempty"`
+
+	// Conditions are the latest observations of the state of the ArgoCD.
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+const (
+	// ArgoCDConditionConfigurationConflict is true when the argocd-cm, argocd-rbac-cm or argocd-secret of the
+	// namespace is controlled by another ArgoCD. The ArgoCD is not reconciled until the conflict is resolved.
+	ArgoCDConditionConfigurationConflict = "ConfigurationConflict"
+
+	// ArgoCDConditionInvalidRepositories is true when repositories or repository credential templates of the ArgoCD
+	// cannot be turned into Secrets, such as the ones referencing a missing Secret, or when the deprecated
+	// InitialRepositories or RepositoryCredentials cannot be parsed. The deprecated entries are left in argocd-cm.
+	ArgoCDConditionInvalidRepositories = "InvalidRepositories"
+
+	// ArgoCDConditionInvalidResourceFilters is true when resource exclusion or inclusion rules of the ArgoCD are
+	// invalid and skipped, or when the deprecated ResourceExclusions or ResourceInclusions cannot be parsed.
+	ArgoCDConditionInvalidResourceFilters = "InvalidResourceFilters"
+)
+
+// ResourceCustomizationType is the type of a resource customization.
+type ResourceCustomizationType string
+
+const (
+	// ResourceCustomizationTypeHealthCheck is the type of the ResourceHealthChecks.
+	ResourceCustomizationTypeHealthCheck ResourceCustomizationType = "HealthCheck"
+
+	// ResourceCustomizationTypeAction is the type of the ResourceActions.
+	ResourceCustomizationTypeAction ResourceCustomizationType = "Action"
+)
+
+// ResourceCustomizationFailure reports a resource customization whose Lua scripts do not compile or fail their tests.
+type ResourceCustomizationFailure struct {
+	// Type is the type of the resource customization.
+	Type ResourceCustomizationType `json:"type"`
+
+	// Group is the group of the resource customization.
+	Group string `json:"group,omitempty"`
+
+	// Kind is the kind of the resource customization.
+	Kind string `json:"kind,omitempty"`
+
+	// Message is why the resource customization failed.
+	Message string `json:"message"`
+}
+
+// ArgoCDUpgradeSpec defines how a change of the Argo CD version is rolled out: the new version is checked first, then
+// rolled out to one component at a time, each component becoming ready before the next one is upgraded.
+type ArgoCDUpgradeSpec struct {
+	// StepTimeout is the time a component has to become ready once upgraded, before the whole upgrade is rolled back
+	// to the previous version. Default is 10m.
+	StepTimeout *metav1.Duration `json:"stepTimeout,omitempty"`
+}
+
+// ArgoCDUpgradePhase is the phase of an upgrade of the Argo CD version.
+// +kubebuilder:validation:Enum=PreFlight;RollingOut;RollingBack;Completed;RolledBack;Failed
+type ArgoCDUpgradePhase string
+
+const (
+	// UpgradePhasePreFlight checks that the new version can be pulled and upgraded to.
+	UpgradePhasePreFlight ArgoCDUpgradePhase = "PreFlight"
+
+	// UpgradePhaseRollingOut upgrades the components one at a time.
+	UpgradePhaseRollingOut ArgoCDUpgradePhase = "RollingOut"
+
+	// UpgradePhaseRollingBack returns every component to the previous version, after a component failed to become
+	// ready or the upgrade was canceled.
+	UpgradePhaseRollingBack ArgoCDUpgradePhase = "RollingBack"
+
+	// UpgradePhaseCompleted is reached once every component runs the new version.
+	UpgradePhaseCompleted ArgoCDUpgradePhase = "Completed"
+
+	// UpgradePhaseRolledBack is reached once every component runs the previous version again.
+	UpgradePhaseRolledBack ArgoCDUpgradePhase = "RolledBack"
+
+	// UpgradePhaseFailed is reached when the pre-flight checks fail, no component was upgraded.
+	UpgradePhaseFailed ArgoCDUpgradePhase = "Failed"
+)
+
+// ArgoCDUpgradeStatus describes the state of an upgrade of the Argo CD version.
+type ArgoCDUpgradeStatus struct {
+	// Phase is the phase of the upgrade.
+	Phase ArgoCDUpgradePhase `json:"phase"`
+
+	// FromVersion is the version the components ran before the upgrade.
+	FromVersion string `json:"fromVersion"`
+
+	// ToVersion is the version the upgrade rolls out.
+	ToVersion string `json:"toVersion"`
+
+	// Step is the component being upgraded while rolling out.
+	Step ArgoCDComponentName `json:"step,omitempty"`
+
+	// StepStartTime is the time at which the current phase or step was started.
+	StepStartTime *metav1.Time `json:"stepStartTime,omitempty"`
+
+	// Message describes why the upgrade failed or was rolled back.
+	Message string `json:"message,omitempty"`
+}
+
+// ArgoCDReconcileStrategy defines how the operator reconciles an ArgoCD.
+type ArgoCDReconcileStrategy string
+
+const (
+	// ReconcileStrategyActive enforces the desired state of the ArgoCD.
+	ReconcileStrategyActive ArgoCDReconcileStrategy = "Active"
+
+	// ReconcileStrategyPaused stops reconciling the ArgoCD, until it is deleted.
+	ReconcileStrategyPaused ArgoCDReconcileStrategy = "Paused"
+
+	// ReconcileStrategyObserveOnly reports the drift from the desired state of the ArgoCD without changing anything.
+	ReconcileStrategyObserveOnly ArgoCDReconcileStrategy = "ObserveOnly"
+)
+
+// ArgoCDComponentName is the name of an Argo CD component, the resources of a component are named after it.
+// +kubebuilder:validation:Enum=application-controller;applicationset-controller;dex-server;notifications-controller;redis;repo-server;server
+type ArgoCDComponentName string
+
+// ArgoCDResourceDrift describes a resource that differs from its desired state.
+type ArgoCDResourceDrift struct {
+	// Kind is the kind of the resource.
+	Kind string `json:"kind"`
+
+	// Namespace is the namespace of the resource, empty for cluster scoped resources.
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name is the name of the resource.
+	Name string `json:"name"`
+
+	// Action is the change the operator would make to the resource to correct the drift.
+	// There are three possible Action values: Create, Update and Delete.
+	Action string `json:"action"`
+}
+
+// ArgoCDManagedResource describes an object written by the operator for an ArgoCD.
+type ArgoCDManagedResource struct {
+	// Kind is the kind of the object.
+	Kind string `json:"kind"`
+
+	// Namespace is the namespace of the object, empty for cluster scoped objects.
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name is the name of the object.
+	Name string `json:"name"`
+
+	// Hash is the SHA-256 hash of the content of the object as last written by the operator.
+	Hash string `json:"hash"`
 }
 
 // Banner defines an additional banner message to be displayed in Argo CD UI
@@ -982,11 +1768,17 @@ type WebhookServerSpec struct {
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Host",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:fieldGroup:Server","urn:alm:descriptor:com.tectonic.ui:text"}
 	Host string `json:"host,omitempty"`
 
+	// HTTPRoute defines the desired state for a Gateway API HTTPRoute for the Application set webhook component.
+	HTTPRoute ArgoCDGatewayRouteSpec `json:"httpRoute,omitempty"`
+
 	// Ingress defines the desired state for an Ingress for the Application set webhook component.
 	Ingress ArgoCDIngressSpec `json:"ingress,omitempty"`
 
 	// Route defines the desired state for an OpenShift Route for the Application set webhook component.
 	Route ArgoCDRouteSpec `json:"route,omitempty"`
+
+	// Service defines the options for the Service backing the Application set webhook and metrics.
+	Service ArgoCDWebhookServiceSpec `json:"service,omitempty"`
 }
 
 // IsDeletionFinalizerPresent checks if the instance has deletion finalizer
@@ -17,7 +17,17 @@ limitations under the License.
 package v1beta1
 
 import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/argoproj-labs/argocd-operator/common"
 )
 
 func (r *ArgoCD) SetupWebhookWithManager(mgr ctrl.Manager) error {
@@ -25,3 +35,143 @@ func (r *ArgoCD) SetupWebhookWithManager(mgr ctrl.Manager) error {
 		For(r).
 		Complete()
 }
+
+//+kubebuilder:webhook:path=/validate-argoproj-io-v1beta1-argocd,mutating=false,failurePolicy=ignore,sideEffects=None,groups=argoproj.io,resources=argocds,verbs=create;update,versions=v1beta1,name=vargocd.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &ArgoCD{}
+
+// ValidateCreate rejects an ArgoCD whose resource customizations do not compile or fail their tests, whose
+// Config Management Plugins cannot be run as sidecar containers, whose managed namespace selector is empty, or whose
+// Redis HA topology is invalid.
+func (r *ArgoCD) ValidateCreate() (admission.Warnings, error) {
+	warnings, err := r.validateResourceCustomizations()
+	if err != nil {
+		return warnings, err
+	}
+	if err := r.validateRepoPlugins(); err != nil {
+		return warnings, err
+	}
+	if err := r.validateManagedNamespaceSelector(); err != nil {
+		return warnings, err
+	}
+	return warnings, r.validateRedisHA()
+}
+
+// ValidateUpdate rejects an update changing the resource customizations of an ArgoCD when they do not compile or fail
+// their tests, changing its Config Management Plugins when they cannot be run as sidecar containers, changing its
+// managed namespace selector to an empty one, or changing its Redis HA topology to an invalid one. Other updates
+// are accepted with the failures as warnings, so that an ArgoCD created with failed resource customizations or
+// plugins, before the webhook was enabled or while it was unavailable, can still be edited and have its finalizers
+// removed. An ArgoCD being deleted is never validated.
+func (r *ArgoCD) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
+	if r.DeletionTimestamp != nil {
+		return nil, nil
+	}
+	oldCR, ok := old.(*ArgoCD)
+	warnings, err := r.validateResourceCustomizations()
+	if err != nil {
+		if !ok || !equality.Semantic.DeepEqual(oldCR.Spec.ResourceHealthChecks, r.Spec.ResourceHealthChecks) ||
+			!equality.Semantic.DeepEqual(oldCR.Spec.ResourceActions, r.Spec.ResourceActions) {
+			return warnings, err
+		}
+		warnings = append(warnings, err.Error())
+	}
+	if err := r.validateRepoPlugins(); err != nil {
+		if !ok || !equality.Semantic.DeepEqual(oldCR.Spec.Repo.Plugins, r.Spec.Repo.Plugins) {
+			return warnings, err
+		}
+		warnings = append(warnings, err.Error())
+	}
+	if err := r.validateManagedNamespaceSelector(); err != nil {
+		if !ok || !equality.Semantic.DeepEqual(oldCR.Spec.ManagedNamespaceSelector, r.Spec.ManagedNamespaceSelector) {
+			return warnings, err
+		}
+		warnings = append(warnings, err.Error())
+	}
+	if err := r.validateRedisHA(); err != nil {
+		if !ok || !equality.Semantic.DeepEqual(oldCR.Spec.HA.RedisReplicas, r.Spec.HA.RedisReplicas) ||
+			!equality.Semantic.DeepEqual(oldCR.Spec.HA.SentinelQuorum, r.Spec.HA.SentinelQuorum) {
+			return warnings, err
+		}
+		warnings = append(warnings, err.Error())
+	}
+	return warnings, nil
+}
+
+// ValidateDelete accepts the deletion of every ArgoCD.
+func (r *ArgoCD) ValidateDelete() (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateResourceCustomizations returns an error listing the failed resource customizations of the ArgoCD. At most
+// webhookMaxLuaTests tests are run, a warning reporting the tests left to the operator.
+func (r *ArgoCD) validateResourceCustomizations() (admission.Warnings, error) {
+	budget := &luaTestBudget{remaining: webhookMaxLuaTests}
+	failures := r.validateResourceCustomizationsWithBudget(budget)
+
+	var warnings admission.Warnings
+	if budget.skipped > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d resource customization tests were not run on admission, their "+
+			"failures are reported in the status", budget.skipped))
+	}
+	if len(failures) == 0 {
+		return warnings, nil
+	}
+	messages := make([]string, 0, len(failures))
+	for _, failure := range failures {
+		messages = append(messages, fmt.Sprintf("%s %s/%s: %s", failure.Type, failure.Group, failure.Kind, failure.Message))
+	}
+	return warnings, fmt.Errorf("invalid resource customizations: %s", strings.Join(messages, "; "))
+}
+
+// validateRepoPlugins returns an error listing the Config Management Plugins of the ArgoCD whose name is not a valid
+// container name or is already used by another repo server container.
+func (r *ArgoCD) validateRepoPlugins() error {
+	names := map[string]bool{"argocd-repo-server": true}
+	for _, c := range r.Spec.Repo.SidecarContainers {
+		names[c.Name] = true
+	}
+
+	var messages []string
+	for _, plugin := range r.Spec.Repo.Plugins {
+		if errs := validation.IsDNS1123Label(plugin.Name); len(errs) > 0 {
+			messages = append(messages, fmt.Sprintf("%q: %s", plugin.Name, strings.Join(errs, ", ")))
+			continue
+		}
+		if names[plugin.Name] {
+			messages = append(messages, fmt.Sprintf("%q: the name is already used by another repo server container", plugin.Name))
+			continue
+		}
+		names[plugin.Name] = true
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid config management plugins: %s", strings.Join(messages, "; "))
+}
+
+// validateManagedNamespaceSelector returns an error if the managed namespace selector of the ArgoCD is set but empty,
+// as an empty selector would select every namespace of the cluster.
+func (r *ArgoCD) validateManagedNamespaceSelector() error {
+	selector := r.Spec.ManagedNamespaceSelector
+	if selector == nil || len(selector.MatchLabels) > 0 || len(selector.MatchExpressions) > 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid managed namespace selector: matchLabels or matchExpressions must be set")
+}
+
+// validateRedisHA returns an error if the Redis HA topology of the ArgoCD has fewer than the default 3 replicas, or a
+// Sentinel quorum greater than its replicas. The operator uses the default replicas and caps the quorum otherwise.
+func (r *ArgoCD) validateRedisHA() error {
+	replicas := common.ArgoCDDefaultRedisHAReplicas
+	if r.Spec.HA.RedisReplicas != nil {
+		if *r.Spec.HA.RedisReplicas < common.ArgoCDDefaultRedisHAReplicas {
+			return fmt.Errorf("invalid redis HA replicas: %d, must be at least %d", *r.Spec.HA.RedisReplicas, common.ArgoCDDefaultRedisHAReplicas)
+		}
+		replicas = *r.Spec.HA.RedisReplicas
+	}
+	if quorum := r.Spec.HA.SentinelQuorum; quorum != nil && *quorum > replicas {
+		return fmt.Errorf("invalid sentinel quorum: %d, must not be greater than the %d redis HA replicas", *quorum, replicas)
+	}
+	return nil
+}
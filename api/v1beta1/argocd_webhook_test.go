@@ -0,0 +1,93 @@
+package v1beta1
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_ArgoCD_ValidateRepoPlugins(t *testing.T) {
+	cr := &ArgoCD{}
+	cr.Spec.Repo.SidecarContainers = []corev1.Container{{Name: "vault"}}
+	cr.Spec.Repo.Plugins = []ArgoCDRepoPluginSpec{
+		{Name: "envsubst", Image: "quay.io/example/envsubst:latest"},
+		{Name: "Env.Subst", Image: "quay.io/example/envsubst:latest"},
+		{Name: strings.Repeat("a", 64), Image: "quay.io/example/envsubst:latest"},
+		{Name: "vault", Image: "quay.io/example/vault:latest"},
+		{Name: "envsubst", Image: "quay.io/example/envsubst:latest"},
+	}
+
+	_, err := cr.ValidateCreate()
+	assert.ErrorContains(t, err, `"Env.Subst": a lowercase RFC 1123 label must consist of`)
+	assert.ErrorContains(t, err, "must be no more than 63 characters")
+	assert.ErrorContains(t, err, `"vault": the name is already used by another repo server container`)
+	assert.ErrorContains(t, err, `"envsubst": the name is already used by another repo server container`)
+
+	// updates leaving the invalid plugins unchanged are accepted with warnings
+	old := cr.DeepCopy()
+	cr.Spec.DisableAdmin = true
+	warnings, err := cr.ValidateUpdate(old)
+	assert.NoError(t, err)
+	assert.Len(t, warnings, 1)
+
+	// updates changing them are rejected
+	cr.Spec.Repo.Plugins = cr.Spec.Repo.Plugins[:2]
+	_, err = cr.ValidateUpdate(old)
+	assert.ErrorContains(t, err, `"Env.Subst"`)
+
+	cr.Spec.Repo.Plugins = cr.Spec.Repo.Plugins[:1]
+	_, err = cr.ValidateUpdate(old)
+	assert.NoError(t, err)
+}
+
+func Test_ArgoCD_ValidateManagedNamespaceSelector(t *testing.T) {
+	cr := &ArgoCD{}
+	cr.Spec.ManagedNamespaceSelector = &metav1.LabelSelector{}
+
+	_, err := cr.ValidateCreate()
+	assert.ErrorContains(t, err, "invalid managed namespace selector")
+
+	// updates leaving the empty selector unchanged are accepted with warnings
+	old := cr.DeepCopy()
+	cr.Spec.DisableAdmin = true
+	warnings, err := cr.ValidateUpdate(old)
+	assert.NoError(t, err)
+	assert.Len(t, warnings, 1)
+
+	// updates setting it are rejected
+	old.Spec.ManagedNamespaceSelector = nil
+	_, err = cr.ValidateUpdate(old)
+	assert.ErrorContains(t, err, "invalid managed namespace selector")
+
+	cr.Spec.ManagedNamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "team-a"}}
+	_, err = cr.ValidateCreate()
+	assert.NoError(t, err)
+}
+
+func Test_ArgoCD_ValidateRedisHA(t *testing.T) {
+	quorum, replicas := int32(4), int32(2)
+	cr := &ArgoCD{}
+	cr.Spec.HA.SentinelQuorum = &quorum
+
+	_, err := cr.ValidateCreate()
+	assert.ErrorContains(t, err, "invalid sentinel quorum: 4, must not be greater than the 3 redis HA replicas")
+
+	// updates leaving the topology unchanged are accepted with warnings
+	old := cr.DeepCopy()
+	cr.Spec.DisableAdmin = true
+	warnings, err := cr.ValidateUpdate(old)
+	assert.NoError(t, err)
+	assert.Len(t, warnings, 1)
+
+	// updates changing it are rejected
+	cr.Spec.HA.RedisReplicas = &replicas
+	_, err = cr.ValidateUpdate(old)
+	assert.ErrorContains(t, err, "invalid redis HA replicas: 2, must be at least 3")
+
+	replicas = 5
+	_, err = cr.ValidateCreate()
+	assert.NoError(t, err)
+}
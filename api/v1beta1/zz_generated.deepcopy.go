@@ -27,7 +27,7 @@ import (
 	"k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -36,7 +36,7 @@ func (in *ArgoCD) DeepCopyInto(out *ArgoCD) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCD.
@@ -179,6 +179,58 @@ func (in *ArgoCDApplicationSet) DeepCopy() *ArgoCDApplicationSet {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDBootstrapObject) DeepCopyInto(out *ArgoCDBootstrapObject) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDBootstrapObject.
+func (in *ArgoCDBootstrapObject) DeepCopy() *ArgoCDBootstrapObject {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDBootstrapObject)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDBootstrapSpec) DeepCopyInto(out *ArgoCDBootstrapSpec) {
+	*out = *in
+	if in.Projects != nil {
+		in, out := &in.Projects, &out.Projects
+		*out = make([]ArgoCDBootstrapObject, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Applications != nil {
+		in, out := &in.Applications, &out.Applications
+		*out = make([]ArgoCDBootstrapObject, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDBootstrapSpec.
+func (in *ArgoCDBootstrapSpec) DeepCopy() *ArgoCDBootstrapSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDBootstrapSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArgoCDCASpec) DeepCopyInto(out *ArgoCDCASpec) {
 	*out = *in
@@ -241,6 +293,62 @@ func (in *ArgoCDDexSpec) DeepCopy() *ArgoCDDexSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDGatewayParentReference) DeepCopyInto(out *ArgoCDGatewayParentReference) {
+	*out = *in
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDGatewayParentReference.
+func (in *ArgoCDGatewayParentReference) DeepCopy() *ArgoCDGatewayParentReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDGatewayParentReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDGatewayRouteSpec) DeepCopyInto(out *ArgoCDGatewayRouteSpec) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ParentRefs != nil {
+		in, out := &in.ParentRefs, &out.ParentRefs
+		*out = make([]ArgoCDGatewayParentReference, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDGatewayRouteSpec.
+func (in *ArgoCDGatewayRouteSpec) DeepCopy() *ArgoCDGatewayRouteSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDGatewayRouteSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArgoCDGrafanaSpec) DeepCopyInto(out *ArgoCDGrafanaSpec) {
 	*out = *in
@@ -276,6 +384,26 @@ func (in *ArgoCDHASpec) DeepCopyInto(out *ArgoCDHASpec) {
 		*out = new(v1.ResourceRequirements)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.RedisReplicas != nil {
+		in, out := &in.RedisReplicas, &out.RedisReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SentinelQuorum != nil {
+		in, out := &in.SentinelQuorum, &out.SentinelQuorum
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RedisProxyReplicas != nil {
+		in, out := &in.RedisProxyReplicas, &out.RedisProxyReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Persistence != nil {
+		in, out := &in.Persistence, &out.Persistence
+		*out = new(ArgoCDRedisHAPersistenceSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDHASpec.
@@ -399,6 +527,21 @@ func (in *ArgoCDList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDManagedResource) DeepCopyInto(out *ArgoCDManagedResource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDManagedResource.
+func (in *ArgoCDManagedResource) DeepCopy() *ArgoCDManagedResource {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDManagedResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArgoCDMonitoringSpec) DeepCopyInto(out *ArgoCDMonitoringSpec) {
 	*out = *in
@@ -419,6 +562,26 @@ func (in *ArgoCDMonitoringSpec) DeepCopy() *ArgoCDMonitoringSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDNetworkPolicySpec) DeepCopyInto(out *ArgoCDNetworkPolicySpec) {
+	*out = *in
+	if in.EgressCIDRs != nil {
+		in, out := &in.EgressCIDRs, &out.EgressCIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDNetworkPolicySpec.
+func (in *ArgoCDNetworkPolicySpec) DeepCopy() *ArgoCDNetworkPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDNetworkPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArgoCDNodePlacementSpec) DeepCopyInto(out *ArgoCDNodePlacementSpec) {
 	*out = *in
@@ -480,6 +643,161 @@ func (in *ArgoCDNotifications) DeepCopy() *ArgoCDNotifications {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDPluginCommand) DeepCopyInto(out *ArgoCDPluginCommand) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDPluginCommand.
+func (in *ArgoCDPluginCommand) DeepCopy() *ArgoCDPluginCommand {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDPluginCommand)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDPluginDiscover) DeepCopyInto(out *ArgoCDPluginDiscover) {
+	*out = *in
+	if in.Find != nil {
+		in, out := &in.Find, &out.Find
+		*out = new(ArgoCDPluginFind)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDPluginDiscover.
+func (in *ArgoCDPluginDiscover) DeepCopy() *ArgoCDPluginDiscover {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDPluginDiscover)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDPluginFind) DeepCopyInto(out *ArgoCDPluginFind) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDPluginFind.
+func (in *ArgoCDPluginFind) DeepCopy() *ArgoCDPluginFind {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDPluginFind)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDPluginParameters) DeepCopyInto(out *ArgoCDPluginParameters) {
+	*out = *in
+	if in.Static != nil {
+		in, out := &in.Static, &out.Static
+		*out = make([]ArgoCDPluginStaticParameter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Dynamic != nil {
+		in, out := &in.Dynamic, &out.Dynamic
+		*out = new(ArgoCDPluginCommand)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDPluginParameters.
+func (in *ArgoCDPluginParameters) DeepCopy() *ArgoCDPluginParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDPluginParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDPluginSpec) DeepCopyInto(out *ArgoCDPluginSpec) {
+	*out = *in
+	if in.Init != nil {
+		in, out := &in.Init, &out.Init
+		*out = new(ArgoCDPluginCommand)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Generate.DeepCopyInto(&out.Generate)
+	if in.Discover != nil {
+		in, out := &in.Discover, &out.Discover
+		*out = new(ArgoCDPluginDiscover)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = new(ArgoCDPluginParameters)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDPluginSpec.
+func (in *ArgoCDPluginSpec) DeepCopy() *ArgoCDPluginSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDPluginSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDPluginStaticParameter) DeepCopyInto(out *ArgoCDPluginStaticParameter) {
+	*out = *in
+	if in.Array != nil {
+		in, out := &in.Array, &out.Array
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Map != nil {
+		in, out := &in.Map, &out.Map
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDPluginStaticParameter.
+func (in *ArgoCDPluginStaticParameter) DeepCopy() *ArgoCDPluginStaticParameter {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDPluginStaticParameter)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArgoCDPrometheusSpec) DeepCopyInto(out *ArgoCDPrometheusSpec) {
 	*out = *in
@@ -502,6 +820,21 @@ func (in *ArgoCDPrometheusSpec) DeepCopy() *ArgoCDPrometheusSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDProxySpec) DeepCopyInto(out *ArgoCDProxySpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDProxySpec.
+func (in *ArgoCDProxySpec) DeepCopy() *ArgoCDProxySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDProxySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArgoCDRBACSpec) DeepCopyInto(out *ArgoCDRBACSpec) {
 	*out = *in
@@ -532,117 +865,422 @@ func (in *ArgoCDRBACSpec) DeepCopy() *ArgoCDRBACSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ArgoCDRBACSpec)
+	out := new(ArgoCDRBACSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDRedisHAPersistenceSpec) DeepCopyInto(out *ArgoCDRedisHAPersistenceSpec) {
+	*out = *in
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.AccessModes != nil {
+		in, out := &in.AccessModes, &out.AccessModes
+		*out = make([]v1.PersistentVolumeAccessMode, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDRedisHAPersistenceSpec.
+func (in *ArgoCDRedisHAPersistenceSpec) DeepCopy() *ArgoCDRedisHAPersistenceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDRedisHAPersistenceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDRedisPasswordRotationSpec) DeepCopyInto(out *ArgoCDRedisPasswordRotationSpec) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDRedisPasswordRotationSpec.
+func (in *ArgoCDRedisPasswordRotationSpec) DeepCopy() *ArgoCDRedisPasswordRotationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDRedisPasswordRotationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDRedisSpec) DeepCopyInto(out *ArgoCDRedisSpec) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Remote != nil {
+		in, out := &in.Remote, &out.Remote
+		*out = new(string)
+		**out = **in
+	}
+	if in.RemoteConfig != nil {
+		in, out := &in.RemoteConfig, &out.RemoteConfig
+		*out = new(ArgoCDRemoteRedisSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PasswordRotation != nil {
+		in, out := &in.PasswordRotation, &out.PasswordRotation
+		*out = new(ArgoCDRedisPasswordRotationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDRedisSpec.
+func (in *ArgoCDRedisSpec) DeepCopy() *ArgoCDRedisSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDRedisSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDRemoteRedisSentinelSpec) DeepCopyInto(out *ArgoCDRemoteRedisSentinelSpec) {
+	*out = *in
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.UsernameSecret != nil {
+		in, out := &in.UsernameSecret, &out.UsernameSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PasswordSecret != nil {
+		in, out := &in.PasswordSecret, &out.PasswordSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDRemoteRedisSentinelSpec.
+func (in *ArgoCDRemoteRedisSentinelSpec) DeepCopy() *ArgoCDRemoteRedisSentinelSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDRemoteRedisSentinelSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDRemoteRedisSpec) DeepCopyInto(out *ArgoCDRemoteRedisSpec) {
+	*out = *in
+	if in.UsernameSecret != nil {
+		in, out := &in.UsernameSecret, &out.UsernameSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PasswordSecret != nil {
+		in, out := &in.PasswordSecret, &out.PasswordSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CAConfigMap != nil {
+		in, out := &in.CAConfigMap, &out.CAConfigMap
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Sentinel != nil {
+		in, out := &in.Sentinel, &out.Sentinel
+		*out = new(ArgoCDRemoteRedisSentinelSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDRemoteRedisSpec.
+func (in *ArgoCDRemoteRedisSpec) DeepCopy() *ArgoCDRemoteRedisSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDRemoteRedisSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDRepoPluginSpec) DeepCopyInto(out *ArgoCDRepoPluginSpec) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VolumeMounts != nil {
+		in, out := &in.VolumeMounts, &out.VolumeMounts
+		*out = make([]v1.VolumeMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDRepoPluginSpec.
+func (in *ArgoCDRepoPluginSpec) DeepCopy() *ArgoCDRepoPluginSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDRepoPluginSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDRepoSpec) DeepCopyInto(out *ArgoCDRepoSpec) {
+	*out = *in
+	if in.ExtraRepoCommandArgs != nil {
+		in, out := &in.ExtraRepoCommandArgs, &out.ExtraRepoCommandArgs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExecTimeout != nil {
+		in, out := &in.ExecTimeout, &out.ExecTimeout
+		*out = new(int)
+		**out = **in
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]v1.Volume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VolumeMounts != nil {
+		in, out := &in.VolumeMounts, &out.VolumeMounts
+		*out = make([]v1.VolumeMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.InitContainers != nil {
+		in, out := &in.InitContainers, &out.InitContainers
+		*out = make([]v1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SidecarContainers != nil {
+		in, out := &in.SidecarContainers, &out.SidecarContainers
+		*out = make([]v1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Plugins != nil {
+		in, out := &in.Plugins, &out.Plugins
+		*out = make([]ArgoCDRepoPluginSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Remote != nil {
+		in, out := &in.Remote, &out.Remote
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDRepoSpec.
+func (in *ArgoCDRepoSpec) DeepCopy() *ArgoCDRepoSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDRepoSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDRepository) DeepCopyInto(out *ArgoCDRepository) {
+	*out = *in
+	in.ArgoCDRepositoryCredentials.DeepCopyInto(&out.ArgoCDRepositoryCredentials)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDRepository.
+func (in *ArgoCDRepository) DeepCopy() *ArgoCDRepository {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDRepository)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDRepositoryCredentialTemplate) DeepCopyInto(out *ArgoCDRepositoryCredentialTemplate) {
+	*out = *in
+	in.ArgoCDRepositoryCredentials.DeepCopyInto(&out.ArgoCDRepositoryCredentials)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDRepositoryCredentialTemplate.
+func (in *ArgoCDRepositoryCredentialTemplate) DeepCopy() *ArgoCDRepositoryCredentialTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDRepositoryCredentialTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDRepositoryCredentials) DeepCopyInto(out *ArgoCDRepositoryCredentials) {
+	*out = *in
+	if in.UsernameSecret != nil {
+		in, out := &in.UsernameSecret, &out.UsernameSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PasswordSecret != nil {
+		in, out := &in.PasswordSecret, &out.PasswordSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SSHPrivateKeySecret != nil {
+		in, out := &in.SSHPrivateKeySecret, &out.SSHPrivateKeySecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GitHubApp != nil {
+		in, out := &in.GitHubApp, &out.GitHubApp
+		*out = new(ArgoCDRepositoryGitHubApp)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TLSClientCertSecret != nil {
+		in, out := &in.TLSClientCertSecret, &out.TLSClientCertSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TLSClientKeySecret != nil {
+		in, out := &in.TLSClientKeySecret, &out.TLSClientKeySecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDRepositoryCredentials.
+func (in *ArgoCDRepositoryCredentials) DeepCopy() *ArgoCDRepositoryCredentials {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDRepositoryCredentials)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDRepositoryGitHubApp) DeepCopyInto(out *ArgoCDRepositoryGitHubApp) {
+	*out = *in
+	in.PrivateKeySecret.DeepCopyInto(&out.PrivateKeySecret)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDRepositoryGitHubApp.
+func (in *ArgoCDRepositoryGitHubApp) DeepCopy() *ArgoCDRepositoryGitHubApp {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDRepositoryGitHubApp)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ArgoCDRedisSpec) DeepCopyInto(out *ArgoCDRedisSpec) {
+func (in *ArgoCDResourceDrift) DeepCopyInto(out *ArgoCDResourceDrift) {
 	*out = *in
-	if in.Resources != nil {
-		in, out := &in.Resources, &out.Resources
-		*out = new(v1.ResourceRequirements)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Enabled != nil {
-		in, out := &in.Enabled, &out.Enabled
-		*out = new(bool)
-		**out = **in
-	}
-	if in.Remote != nil {
-		in, out := &in.Remote, &out.Remote
-		*out = new(string)
-		**out = **in
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDRedisSpec.
-func (in *ArgoCDRedisSpec) DeepCopy() *ArgoCDRedisSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDResourceDrift.
+func (in *ArgoCDResourceDrift) DeepCopy() *ArgoCDResourceDrift {
 	if in == nil {
 		return nil
 	}
-	out := new(ArgoCDRedisSpec)
+	out := new(ArgoCDResourceDrift)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ArgoCDRepoSpec) DeepCopyInto(out *ArgoCDRepoSpec) {
+func (in *ArgoCDResourceFilterRule) DeepCopyInto(out *ArgoCDResourceFilterRule) {
 	*out = *in
-	if in.ExtraRepoCommandArgs != nil {
-		in, out := &in.ExtraRepoCommandArgs, &out.ExtraRepoCommandArgs
+	if in.APIGroups != nil {
+		in, out := &in.APIGroups, &out.APIGroups
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	if in.Replicas != nil {
-		in, out := &in.Replicas, &out.Replicas
-		*out = new(int32)
-		**out = **in
-	}
-	if in.Resources != nil {
-		in, out := &in.Resources, &out.Resources
-		*out = new(v1.ResourceRequirements)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.ExecTimeout != nil {
-		in, out := &in.ExecTimeout, &out.ExecTimeout
-		*out = new(int)
-		**out = **in
-	}
-	if in.Env != nil {
-		in, out := &in.Env, &out.Env
-		*out = make([]v1.EnvVar, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.Volumes != nil {
-		in, out := &in.Volumes, &out.Volumes
-		*out = make([]v1.Volume, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.VolumeMounts != nil {
-		in, out := &in.VolumeMounts, &out.VolumeMounts
-		*out = make([]v1.VolumeMount, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.InitContainers != nil {
-		in, out := &in.InitContainers, &out.InitContainers
-		*out = make([]v1.Container, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.SidecarContainers != nil {
-		in, out := &in.SidecarContainers, &out.SidecarContainers
-		*out = make([]v1.Container, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.Enabled != nil {
-		in, out := &in.Enabled, &out.Enabled
-		*out = new(bool)
-		**out = **in
+	if in.Kinds != nil {
+		in, out := &in.Kinds, &out.Kinds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.Remote != nil {
-		in, out := &in.Remote, &out.Remote
-		*out = new(string)
-		**out = **in
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDRepoSpec.
-func (in *ArgoCDRepoSpec) DeepCopy() *ArgoCDRepoSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDResourceFilterRule.
+func (in *ArgoCDResourceFilterRule) DeepCopy() *ArgoCDResourceFilterRule {
 	if in == nil {
 		return nil
 	}
-	out := new(ArgoCDRepoSpec)
+	out := new(ArgoCDResourceFilterRule)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -735,6 +1373,8 @@ func (in *ArgoCDServerAutoscaleSpec) DeepCopy() *ArgoCDServerAutoscaleSpec {
 func (in *ArgoCDServerGRPCSpec) DeepCopyInto(out *ArgoCDServerGRPCSpec) {
 	*out = *in
 	in.Ingress.DeepCopyInto(&out.Ingress)
+	in.GRPCRoute.DeepCopyInto(&out.GRPCRoute)
+	in.Route.DeepCopyInto(&out.Route)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDServerGRPCSpec.
@@ -750,6 +1390,7 @@ func (in *ArgoCDServerGRPCSpec) DeepCopy() *ArgoCDServerGRPCSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArgoCDServerServiceSpec) DeepCopyInto(out *ArgoCDServerServiceSpec) {
 	*out = *in
+	in.ArgoCDServiceSpec.DeepCopyInto(&out.ArgoCDServiceSpec)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDServerServiceSpec.
@@ -765,8 +1406,14 @@ func (in *ArgoCDServerServiceSpec) DeepCopy() *ArgoCDServerServiceSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArgoCDServerSpec) DeepCopyInto(out *ArgoCDServerSpec) {
 	*out = *in
+	if in.AdditionalHosts != nil {
+		in, out := &in.AdditionalHosts, &out.AdditionalHosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	in.Autoscale.DeepCopyInto(&out.Autoscale)
 	in.GRPC.DeepCopyInto(&out.GRPC)
+	in.HTTPRoute.DeepCopyInto(&out.HTTPRoute)
 	in.Ingress.DeepCopyInto(&out.Ingress)
 	if in.Replicas != nil {
 		in, out := &in.Replicas, &out.Replicas
@@ -779,7 +1426,7 @@ func (in *ArgoCDServerSpec) DeepCopyInto(out *ArgoCDServerSpec) {
 		(*in).DeepCopyInto(*out)
 	}
 	in.Route.DeepCopyInto(&out.Route)
-	out.Service = in.Service
+	in.Service.DeepCopyInto(&out.Service)
 	if in.Env != nil {
 		in, out := &in.Env, &out.Env
 		*out = make([]v1.EnvVar, len(*in))
@@ -809,6 +1456,52 @@ func (in *ArgoCDServerSpec) DeepCopy() *ArgoCDServerSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDServiceSpec) DeepCopyInto(out *ArgoCDServiceSpec) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LoadBalancerClass != nil {
+		in, out := &in.LoadBalancerClass, &out.LoadBalancerClass
+		*out = new(string)
+		**out = **in
+	}
+	if in.LoadBalancerSourceRanges != nil {
+		in, out := &in.LoadBalancerSourceRanges, &out.LoadBalancerSourceRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodePorts != nil {
+		in, out := &in.NodePorts, &out.NodePorts
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDServiceSpec.
+func (in *ArgoCDServiceSpec) DeepCopy() *ArgoCDServiceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDServiceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArgoCDSpec) DeepCopyInto(out *ArgoCDSpec) {
 	*out = *in
@@ -817,6 +1510,11 @@ func (in *ArgoCDSpec) DeepCopyInto(out *ArgoCDSpec) {
 		*out = new(ArgoCDApplicationSet)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Bootstrap != nil {
+		in, out := &in.Bootstrap, &out.Bootstrap
+		*out = new(ArgoCDBootstrapSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	in.Controller.DeepCopyInto(&out.Controller)
 	if in.ExtraConfig != nil {
 		in, out := &in.ExtraConfig, &out.ExtraConfig
@@ -838,21 +1536,57 @@ func (in *ArgoCDSpec) DeepCopyInto(out *ArgoCDSpec) {
 		*out = make([]KustomizeVersionSpec, len(*in))
 		copy(*out, *in)
 	}
+	if in.ManagedNamespaceSelector != nil {
+		in, out := &in.ManagedNamespaceSelector, &out.ManagedNamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	in.Monitoring.DeepCopyInto(&out.Monitoring)
+	if in.NetworkPolicy != nil {
+		in, out := &in.NetworkPolicy, &out.NetworkPolicy
+		*out = new(ArgoCDNetworkPolicySpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.NodePlacement != nil {
 		in, out := &in.NodePlacement, &out.NodePlacement
 		*out = new(ArgoCDNodePlacementSpec)
 		(*in).DeepCopyInto(*out)
 	}
 	in.Notifications.DeepCopyInto(&out.Notifications)
+	if in.PausedComponents != nil {
+		in, out := &in.PausedComponents, &out.PausedComponents
+		*out = make([]ArgoCDComponentName, len(*in))
+		copy(*out, *in)
+	}
 	in.Prometheus.DeepCopyInto(&out.Prometheus)
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(ArgoCDProxySpec)
+		**out = **in
+	}
 	in.RBAC.DeepCopyInto(&out.RBAC)
 	in.Redis.DeepCopyInto(&out.Redis)
 	in.Repo.DeepCopyInto(&out.Repo)
+	if in.Repositories != nil {
+		in, out := &in.Repositories, &out.Repositories
+		*out = make([]ArgoCDRepository, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RepositoryCredentialTemplates != nil {
+		in, out := &in.RepositoryCredentialTemplates, &out.RepositoryCredentialTemplates
+		*out = make([]ArgoCDRepositoryCredentialTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.ResourceHealthChecks != nil {
 		in, out := &in.ResourceHealthChecks, &out.ResourceHealthChecks
 		*out = make([]ResourceHealthCheck, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.ResourceIgnoreDifferences != nil {
 		in, out := &in.ResourceIgnoreDifferences, &out.ResourceIgnoreDifferences
@@ -862,7 +1596,23 @@ func (in *ArgoCDSpec) DeepCopyInto(out *ArgoCDSpec) {
 	if in.ResourceActions != nil {
 		in, out := &in.ResourceActions, &out.ResourceActions
 		*out = make([]ResourceAction, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ResourceExclusionRules != nil {
+		in, out := &in.ResourceExclusionRules, &out.ResourceExclusionRules
+		*out = make([]ArgoCDResourceFilterRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ResourceInclusionRules != nil {
+		in, out := &in.ResourceInclusionRules, &out.ResourceInclusionRules
+		*out = make([]ArgoCDResourceFilterRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	in.Server.DeepCopyInto(&out.Server)
 	if in.SourceNamespaces != nil {
@@ -870,12 +1620,23 @@ func (in *ArgoCDSpec) DeepCopyInto(out *ArgoCDSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.SourceNamespaceSelector != nil {
+		in, out := &in.SourceNamespaceSelector, &out.SourceNamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.SSO != nil {
 		in, out := &in.SSO, &out.SSO
 		*out = new(ArgoCDSSOSpec)
 		(*in).DeepCopyInto(*out)
 	}
 	in.TLS.DeepCopyInto(&out.TLS)
+	if in.TrustedCA != nil {
+		in, out := &in.TrustedCA, &out.TrustedCA
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Upgrade.DeepCopyInto(&out.Upgrade)
 	if in.Banner != nil {
 		in, out := &in.Banner, &out.Banner
 		*out = new(Banner)
@@ -896,6 +1657,37 @@ func (in *ArgoCDSpec) DeepCopy() *ArgoCDSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArgoCDStatus) DeepCopyInto(out *ArgoCDStatus) {
 	*out = *in
+	if in.RedisPasswordRotationTime != nil {
+		in, out := &in.RedisPasswordRotationTime, &out.RedisPasswordRotationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Drift != nil {
+		in, out := &in.Drift, &out.Drift
+		*out = make([]ArgoCDResourceDrift, len(*in))
+		copy(*out, *in)
+	}
+	if in.Inventory != nil {
+		in, out := &in.Inventory, &out.Inventory
+		*out = make([]ArgoCDManagedResource, len(*in))
+		copy(*out, *in)
+	}
+	if in.Upgrade != nil {
+		in, out := &in.Upgrade, &out.Upgrade
+		*out = new(ArgoCDUpgradeStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResourceCustomizationFailures != nil {
+		in, out := &in.ResourceCustomizationFailures, &out.ResourceCustomizationFailures
+		*out = make([]ResourceCustomizationFailure, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDStatus.
@@ -931,6 +1723,61 @@ func (in *ArgoCDTLSSpec) DeepCopy() *ArgoCDTLSSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDUpgradeSpec) DeepCopyInto(out *ArgoCDUpgradeSpec) {
+	*out = *in
+	if in.StepTimeout != nil {
+		in, out := &in.StepTimeout, &out.StepTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDUpgradeSpec.
+func (in *ArgoCDUpgradeSpec) DeepCopy() *ArgoCDUpgradeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDUpgradeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDUpgradeStatus) DeepCopyInto(out *ArgoCDUpgradeStatus) {
+	*out = *in
+	if in.StepStartTime != nil {
+		in, out := &in.StepStartTime, &out.StepStartTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDUpgradeStatus.
+func (in *ArgoCDUpgradeStatus) DeepCopy() *ArgoCDUpgradeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDUpgradeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoCDWebhookServiceSpec) DeepCopyInto(out *ArgoCDWebhookServiceSpec) {
+	*out = *in
+	in.ArgoCDServiceSpec.DeepCopyInto(&out.ArgoCDServiceSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoCDWebhookServiceSpec.
+func (in *ArgoCDWebhookServiceSpec) DeepCopy() *ArgoCDWebhookServiceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoCDWebhookServiceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Banner) DeepCopyInto(out *Banner) {
 	*out = *in
@@ -994,6 +1841,13 @@ func (in *KustomizeVersionSpec) DeepCopy() *KustomizeVersionSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceAction) DeepCopyInto(out *ResourceAction) {
 	*out = *in
+	if in.Tests != nil {
+		in, out := &in.Tests, &out.Tests
+		*out = make([]ResourceActionTest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceAction.
@@ -1006,9 +1860,48 @@ func (in *ResourceAction) DeepCopy() *ResourceAction {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceActionTest) DeepCopyInto(out *ResourceActionTest) {
+	*out = *in
+	in.Object.DeepCopyInto(&out.Object)
+	in.Expected.DeepCopyInto(&out.Expected)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceActionTest.
+func (in *ResourceActionTest) DeepCopy() *ResourceActionTest {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceActionTest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceCustomizationFailure) DeepCopyInto(out *ResourceCustomizationFailure) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceCustomizationFailure.
+func (in *ResourceCustomizationFailure) DeepCopy() *ResourceCustomizationFailure {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceCustomizationFailure)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceHealthCheck) DeepCopyInto(out *ResourceHealthCheck) {
 	*out = *in
+	if in.Tests != nil {
+		in, out := &in.Tests, &out.Tests
+		*out = make([]ResourceHealthCheckTest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceHealthCheck.
@@ -1021,6 +1914,22 @@ func (in *ResourceHealthCheck) DeepCopy() *ResourceHealthCheck {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceHealthCheckTest) DeepCopyInto(out *ResourceHealthCheckTest) {
+	*out = *in
+	in.Object.DeepCopyInto(&out.Object)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceHealthCheckTest.
+func (in *ResourceHealthCheckTest) DeepCopy() *ResourceHealthCheckTest {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceHealthCheckTest)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceIdentifiers) DeepCopyInto(out *ResourceIdentifiers) {
 	*out = *in
@@ -1082,8 +1991,10 @@ func (in *SSHHostsSpec) DeepCopy() *SSHHostsSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WebhookServerSpec) DeepCopyInto(out *WebhookServerSpec) {
 	*out = *in
+	in.HTTPRoute.DeepCopyInto(&out.HTTPRoute)
 	in.Ingress.DeepCopyInto(&out.Ingress)
 	in.Route.DeepCopyInto(&out.Route)
+	in.Service.DeepCopyInto(&out.Service)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookServerSpec.
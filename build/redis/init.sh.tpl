@@ -4,7 +4,7 @@ INDEX="${HOSTNAME##*-}"
 SENTINEL_PORT={{- if eq .UseTLS "false" -}}26379{{- else -}}0{{- end }}
 MASTER=''
 MASTER_GROUP="argocd"
-QUORUM="2"
+QUORUM="{{.Quorum}}"
 REDIS_CONF=/data/conf/redis.conf
 {{- if eq .UseTLS "false"}}
 REDIS_PORT=6379
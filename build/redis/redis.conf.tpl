@@ -18,7 +18,11 @@ min-replicas-to-write 1
 rdbchecksum yes
 rdbcompression yes
 repl-diskless-sync yes
+{{- if eq .Persistence "true"}}
+save 3600 1 300 100 60 10000
+{{- else}}
 save ""
+{{- end}}
 protected-mode no
 requirepass replace-default-auth
 masterauth replace-default-auth
@@ -12,4 +12,41 @@ const (
 	// AnnotationOpenShiftServiceCA is the annotation on services used to
 	// request a TLS certificate from OpenShift's Service CA for AutoTLS
 	AnnotationOpenShiftServiceCA = "service.beta.openshift.io/serving-cert-secret-name"
+
+	// AnnotationRedisHAConfigChecksum is the annotation on the Redis HA pod templates that holds
+	// the checksum of the Redis HA ConfigMap, so that configuration changes roll the pods
+	AnnotationRedisHAConfigChecksum = "checksum/init-config"
+
+	// AnnotationCmpConfigChecksum is the annotation on the repo server pod template that holds the checksum
+	// of the Config Management Plugin ConfigMap, so that plugin configuration changes roll the pods
+	AnnotationCmpConfigChecksum = "checksum/cmp-config"
+
+	// AnnotationDexConfigChecksum is the annotation on the Dex pod template that holds the checksum of the dex
+	// configuration of argocd-cm, the Dex pods are rolled when it changes
+	AnnotationDexConfigChecksum = "checksum/dex-config"
+
+	// AnnotationRedisPasswordRotate is the annotation on an ArgoCD resource used to request a rotation
+	// of its Redis password, every new value requests a new rotation
+	AnnotationRedisPasswordRotate = "argocds.argoproj.io/rotate-redis-password"
+
+	// AnnotationRedisPasswordRotationID is the annotation on the Redis password Secret holding the ID of the
+	// rotation that last wrote the password, so that a rotation interrupted before the Secret is written resumes
+	AnnotationRedisPasswordRotationID = "argocds.argoproj.io/redis-password-rotation-id"
+
+	// AnnotationManagedNamespaceSelector is the annotation on namespaces labeled as managed because they are
+	// selected by the managed namespace selector of an ArgoCD, it holds the namespace/name of that ArgoCD so
+	// that the label is removed once the namespace is no longer selected
+	AnnotationManagedNamespaceSelector = "argocds.argoproj.io/managed-by-selector"
+
+	// AnnotationReconcileStrategy is the annotation on an ArgoCD resource that overrides the reconcile strategy of its
+	// spec, to pause the instance or observe it only during an incident without editing the spec
+	AnnotationReconcileStrategy = "argocds.argoproj.io/reconcile-strategy"
+
+	// AnnotationPruneDryRun is the annotation on an ArgoCD resource that, when set to true, only logs the resources
+	// the operator would prune instead of deleting them
+	AnnotationPruneDryRun = "argocds.argoproj.io/prune-dry-run"
+
+	// AnnotationBootstrapOwnership is the annotation on the AppProjects and Applications bootstrapped by an ArgoCD, it
+	// holds their ownership so that the ones left to Argo CD are only applied once
+	AnnotationBootstrapOwnership = "argocds.argoproj.io/bootstrap-ownership"
 )
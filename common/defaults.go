@@ -14,6 +14,10 @@
 
 package common
 
+import (
+	"time"
+)
+
 const (
 	// ArgoCDApplicationControllerComponent is the name of the application controller control plane component
 	ArgoCDApplicationControllerComponent = "argocd-application-controller"
@@ -148,9 +152,18 @@ const (
 	// ArgoCDKeycloakImage is the default Keycloak Image used for the non-openshift platforms when not specified.
 	ArgoCDKeycloakImage = "quay.io/keycloak/keycloak"
 
+	// ArgoCDOperatorConfigName is the name of the ArgoCDOperatorConfig read by the operator.
+	ArgoCDOperatorConfigName = "cluster"
+
 	// ArgoCDDefaultLabelSelector is the default Label Selector which will reconcile all ArgoCD instances.
 	ArgoCDDefaultLabelSelector = ""
 
+	// ArgoCDDefaultMaxConcurrentReconciles is the default number of ArgoCD instances reconciled concurrently.
+	ArgoCDDefaultMaxConcurrentReconciles = 1
+
+	// ArgoCDDefaultAPIDiscoveryInterval is the default interval at which the optional APIs served by the cluster are discovered again.
+	ArgoCDDefaultAPIDiscoveryInterval = 5 * time.Minute
+
 	// ArgoCDKeycloakVersion is the default Keycloak version used for the non-openshift platform when not specified.
 	// Version: 15.0.2
 	ArgoCDKeycloakVersion = "sha256:64fb81886fde61dee55091e6033481fa5ccdac62ae30a4fd29b54eb5e97df6a9"
@@ -183,6 +196,9 @@ const (
 	// ArgoCDDefaultRedisHAReplicas is the defaul number of replicas for Redis when rinning in HA mode.
 	ArgoCDDefaultRedisHAReplicas = int32(3)
 
+	// ArgoCDDefaultRedisHAPersistenceSize is the default size of the PersistentVolumeClaim for each Redis HA replica.
+	ArgoCDDefaultRedisHAPersistenceSize = "1Gi"
+
 	// ArgoCDDefaultRedisHAProxyImage is the default Redis HAProxy image to use when not specified.
 	ArgoCDDefaultRedisHAProxyImage = "haproxy"
 
@@ -198,6 +214,9 @@ const (
 	// ArgoCDDefaultRedisSentinelPort is the default listen port for Redis sentinel.
 	ArgoCDDefaultRedisSentinelPort = 26379
 
+	// ArgoCDDefaultRedisSentinelMasterName is the default name of the Redis master monitored by a remote Redis sentinel.
+	ArgoCDDefaultRedisSentinelMasterName = "master"
+
 	//ArgoCDDefaultRedisSuffix is the default suffix to use for Redis resources.
 	ArgoCDDefaultRedisSuffix = "redis"
 
@@ -217,4 +217,19 @@ const (
 
 	// Label Selector is an env variable for ArgoCD instance reconcilliation.
 	ArgoCDLabelSelectorKey = "ARGOCD_LABEL_SELECTOR"
+
+	// ArgoCDMaxConcurrentReconcilesKey is an env variable for the number of ArgoCD instances reconciled concurrently.
+	ArgoCDMaxConcurrentReconcilesKey = "MAX_CONCURRENT_RECONCILES"
+
+	// ArgoCDAPIDiscoveryIntervalKey is an env variable for the interval at which the optional APIs served by the cluster are discovered again.
+	ArgoCDAPIDiscoveryIntervalKey = "API_DISCOVERY_INTERVAL"
+
+	// ArgoCDClusterConfigNamespacesEnvName is an env variable for the namespaces whose Argo CD instances manage the resources of the cluster.
+	ArgoCDClusterConfigNamespacesEnvName = "ARGOCD_CLUSTER_CONFIG_NAMESPACES"
+
+	// ArgoCDEnableConversionWebhookEnvName is an env variable to enable the conversion webhook of the ArgoCD API.
+	ArgoCDEnableConversionWebhookEnvName = "ENABLE_CONVERSION_WEBHOOK"
+
+	// ArgoCDLogLevelEnvName is an env variable for the level of the logs of the operator.
+	ArgoCDLogLevelEnvName = "LOG_LEVEL"
 )
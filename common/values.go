@@ -26,6 +26,10 @@ const (
 	// ArgoCDConfigMapName is the upstream hard-coded ArgoCD ConfigMap name.
 	ArgoCDConfigMapName = "argocd-cm"
 
+	// ArgoCDCmpConfigMapSuffix is the name suffix of the ConfigMap holding the Config Management Plugin configurations
+	// of an ArgoCD instance.
+	ArgoCDCmpConfigMapSuffix = "cmp-cm"
+
 	// ArgoCDGPGKeysConfigMapName is the upstream hard-coded ArgoCD gpg-keys ConfigMap name.
 	ArgoCDGPGKeysConfigMapName = "argocd-gpg-keys-cm"
 
@@ -35,6 +39,13 @@ const (
 	// ArgoCDExportName is the export name for labels.
 	ArgoCDExportName = "argocd.export"
 
+	// ArgoCDFieldManager is the field manager of the fields the operator applies with server-side apply.
+	ArgoCDFieldManager = "argocd-operator"
+
+	// ArgoCDLegacyFieldManager is the field manager of the fields the operator wrote with updates before it moved to
+	// server-side apply, named by the API server after the operator binary.
+	ArgoCDLegacyFieldManager = "manager"
+
 	// ArgoCDExportStorageBackendAWS is the value for the AWS storage backend.
 	ArgoCDExportStorageBackendAWS = "aws"
 
@@ -77,6 +88,24 @@ const (
 	// ArgoCDRedisServerTLSSecretName is the name of the TLS secret for the redis-server
 	ArgoCDRedisServerTLSSecretName = "argocd-operator-redis-tls"
 
+	// ArgoCDRedisRemoteCAVolumeName is the name of the volume holding the CA certificate of a remote Redis
+	ArgoCDRedisRemoteCAVolumeName = "argocd-redis-remote-ca"
+
+	// ArgoCDRedisRemoteCAMountPath is the path where the CA certificate of a remote Redis is mounted
+	ArgoCDRedisRemoteCAMountPath = "/app/config/redis/remote"
+
+	// ArgoCDTrustedCAVolumeName is the name of the volume holding the trusted CA bundle of an ArgoCD instance
+	ArgoCDTrustedCAVolumeName = "argocd-trusted-ca"
+
+	// ArgoCDTrustedCAMountPath is the path where the trusted CA bundle of an ArgoCD instance is mounted
+	ArgoCDTrustedCAMountPath = "/app/config/trusted-ca"
+
+	// ArgoCDTrustedCABundleVolumeName is the name of the volume holding the system CA bundle merged with the trusted CA bundle
+	ArgoCDTrustedCABundleVolumeName = "argocd-trusted-ca-bundle"
+
+	// ArgoCDTrustedCABundleMountPath is the path where the merged CA bundle is mounted
+	ArgoCDTrustedCABundleMountPath = "/app/config/trusted-ca-bundle"
+
 	// ArgoCDRepoServerTLSSecretName is the name of the TLS secret for the repo-server
 	ArgoCDRepoServerTLSSecretName = "argocd-repo-server-tls"
 
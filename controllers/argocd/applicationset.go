@@ -18,7 +18,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"os"
 	"reflect"
 	"strings"
 
@@ -34,6 +33,7 @@ import (
 
 	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
 	"github.com/argoproj-labs/argocd-operator/common"
+	"github.com/argoproj-labs/argocd-operator/controllers/argocdoperatorconfig"
 	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
 )
 
@@ -248,45 +248,13 @@ func (r *ReconcileArgoCD) reconcileApplicationSetDeployment(cr *argoproj.ArgoCD,
 	podSpec.Containers = []corev1.Container{
 		r.applicationSetContainer(cr, addSCMGitlabVolumeMount),
 	}
-	AddSeccompProfileForOpenShift(r.Client, podSpec)
+	AddSeccompProfileForOpenShift(r.Client, r.ClusterAPIs, podSpec)
+	addTrustedCA(cr, podSpec, podSpec.Containers[0].Image)
 
 	if exists {
-
-		existingSpec := existing.Spec.Template.Spec
-
-		deploymentsDifferent := !reflect.DeepEqual(existingSpec.Containers[0], podSpec.Containers) ||
-			!reflect.DeepEqual(existingSpec.Volumes, podSpec.Volumes) ||
-			existingSpec.ServiceAccountName != podSpec.ServiceAccountName ||
-			!reflect.DeepEqual(existing.Labels, deploy.Labels) ||
-			!reflect.DeepEqual(existing.Spec.Template.Labels, deploy.Spec.Template.Labels) ||
-			!reflect.DeepEqual(existing.Spec.Selector, deploy.Spec.Selector) ||
-			!reflect.DeepEqual(existing.Spec.Template.Spec.NodeSelector, deploy.Spec.Template.Spec.NodeSelector) ||
-			!reflect.DeepEqual(existing.Spec.Template.Spec.Tolerations, deploy.Spec.Template.Spec.Tolerations)
-
-		// If the Deployment already exists, make sure the values we care about are up-to-date
-		if deploymentsDifferent {
-			existing.Spec.Template.Spec.Containers = podSpec.Containers
-			existing.Spec.Template.Spec.Volumes = podSpec.Volumes
-			existing.Spec.Template.Spec.ServiceAccountName = podSpec.ServiceAccountName
-			existing.Labels = deploy.Labels
-			existing.Spec.Template.Labels = deploy.Spec.Template.Labels
-			existing.Spec.Selector = deploy.Spec.Selector
-			existing.Spec.Template.Spec.NodeSelector = deploy.Spec.Template.Spec.NodeSelector
-			existing.Spec.Template.Spec.Tolerations = deploy.Spec.Template.Spec.Tolerations
-			return r.Client.Update(context.TODO(), existing)
-		}
-		return nil // Deployment found with nothing to do, move along...
-	}
-
-	if !cr.Spec.ApplicationSet.IsEnabled() {
-		return nil
+		return r.applyDeployment(cr, deploy, existing)
 	}
-
-	if err := controllerutil.SetControllerReference(cr, deploy, r.Scheme); err != nil {
-		return err
-	}
-	return r.Client.Create(context.TODO(), deploy)
-
+	return r.applyDeployment(cr, deploy, nil)
 }
 
 func (r *ReconcileArgoCD) applicationSetContainer(cr *argoproj.ArgoCD, addSCMGitlabVolumeMount bool) corev1.Container {
@@ -304,7 +272,7 @@ func (r *ReconcileArgoCD) applicationSetContainer(cr *argoproj.ArgoCD, addSCMGit
 	// User should be able to override the default NAMESPACE environmental variable
 	appSetEnv = argoutil.EnvMerge(cr.Spec.ApplicationSet.Env, appSetEnv, true)
 	// Environment specified in the CR take precedence over everything else
-	appSetEnv = argoutil.EnvMerge(appSetEnv, proxyEnvVars(), false)
+	appSetEnv = argoutil.EnvMerge(appSetEnv, getProxyEnvVars(cr), false)
 
 	container := corev1.Container{
 		Command:         r.getArgoApplicationSetCommand(cr),
@@ -408,7 +376,7 @@ func (r *ReconcileArgoCD) reconcileApplicationSetServiceAccount(cr *argoproj.Arg
 func (r *ReconcileArgoCD) reconcileApplicationSetClusterRole(cr *argoproj.ArgoCD) (*v1.ClusterRole, error) {
 
 	allowed := false
-	if allowedNamespace(cr.Namespace, os.Getenv("ARGOCD_CLUSTER_CONFIG_NAMESPACES")) {
+	if allowedNamespace(cr.Namespace, argocdoperatorconfig.Getenv(common.ArgoCDClusterConfigNamespacesEnvName)) {
 		allowed = true
 	}
 
@@ -458,11 +426,8 @@ func (r *ReconcileArgoCD) reconcileApplicationSetClusterRole(cr *argoproj.ArgoCD
 			// Do Nothing
 			return clusterRole, nil
 		}
-		return clusterRole, r.Client.Create(context.TODO(), clusterRole)
-	}
-
-	// ArgoCD not cluster scoped, cleanup any existing resource and exit
-	if !allowed {
+	} else if !allowed {
+		// ArgoCD not cluster scoped, cleanup any existing resource and exit
 		err := r.Client.Delete(context.TODO(), existingClusterRole)
 		if err != nil {
 			if !apierrors.IsNotFound(err) {
@@ -472,21 +437,14 @@ func (r *ReconcileArgoCD) reconcileApplicationSetClusterRole(cr *argoproj.ArgoCD
 		return existingClusterRole, nil
 	}
 
-	// if the Rules differ, update the Role
-	if !reflect.DeepEqual(existingClusterRole.Rules, clusterRole.Rules) {
-		existingClusterRole.Rules = clusterRole.Rules
-		if err := r.Client.Update(context.TODO(), existingClusterRole); err != nil {
-			return nil, err
-		}
-	}
-	return existingClusterRole, nil
+	return clusterRole, argoutil.ApplyResource(r.Client, clusterRole)
 }
 
 // reconcileApplicationSetClusterRoleBinding reconciles required clusterrolebinding for appset controller when ArgoCD is cluster-scoped
 func (r *ReconcileArgoCD) reconcileApplicationSetClusterRoleBinding(cr *argoproj.ArgoCD, role *v1.ClusterRole, sa *corev1.ServiceAccount) error {
 
 	allowed := false
-	if allowedNamespace(cr.Namespace, os.Getenv("ARGOCD_CLUSTER_CONFIG_NAMESPACES")) {
+	if allowedNamespace(cr.Namespace, argocdoperatorconfig.Getenv(common.ArgoCDClusterConfigNamespacesEnvName)) {
 		allowed = true
 	}
 
@@ -522,11 +480,8 @@ func (r *ReconcileArgoCD) reconcileApplicationSetClusterRoleBinding(cr *argoproj
 			// Do Nothing
 			return nil
 		}
-		return r.Client.Create(context.TODO(), clusterRB)
-	}
-
-	// ArgoCD not cluster scoped, cleanup any existing resource and exit
-	if !allowed {
+	} else if !allowed {
+		// ArgoCD not cluster scoped, cleanup any existing resource and exit
 		err := r.Client.Delete(context.TODO(), existingClusterRB)
 		if err != nil {
 			if !apierrors.IsNotFound(err) {
@@ -534,20 +489,13 @@ func (r *ReconcileArgoCD) reconcileApplicationSetClusterRoleBinding(cr *argoproj
 			}
 		}
 		return nil
-	}
-
-	// if subj differ, update the rolebinding
-	if !reflect.DeepEqual(existingClusterRB.Subjects, clusterRB.Subjects) {
-		existingClusterRB.Subjects = clusterRB.Subjects
-		if err := r.Client.Update(context.TODO(), existingClusterRB); err != nil {
-			return err
-		}
 	} else if !reflect.DeepEqual(existingClusterRB.RoleRef, clusterRB.RoleRef) {
 		// RoleRef can't be updated, delete the rolebinding so that it gets recreated
 		_ = r.Client.Delete(context.TODO(), existingClusterRB)
 		return fmt.Errorf("change detected in roleRef for rolebinding %s of Argo CD instance %s in namespace %s", existingClusterRB.Name, cr.Name, existingClusterRB.Namespace)
 	}
-	return nil
+
+	return argoutil.ApplyResource(r.Client, clusterRB)
 }
 
 // reconcileApplicationSetSourceNamespacesResources creates role & rolebinding in target source namespaces for appset controller
@@ -674,36 +622,19 @@ func (r *ReconcileArgoCD) reconcileApplicationSetRole(cr *argoproj.ArgoCD) (*v1.
 	role := newRole("applicationset-controller", policyRules, cr)
 	setAppSetLabels(&role.ObjectMeta)
 
-	exists := true
-	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: role.Name, Namespace: cr.Namespace}, role)
-	if err != nil {
-		if !apierrors.IsNotFound(err) {
-			return role, err
-		}
-		exists = false
-	}
-
 	if cr.Spec.ApplicationSet == nil || !cr.Spec.ApplicationSet.IsEnabled() {
-		if exists {
-			if err := r.Client.Delete(context.TODO(), role); err != nil {
-				if !apierrors.IsNotFound(err) {
-					return role, err
-				}
+		if err := r.Client.Delete(context.TODO(), role); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return role, err
 			}
 		}
 		return role, nil
 	}
 
-	role.Rules = policyRules
-	if err = controllerutil.SetControllerReference(cr, role, r.Scheme); err != nil {
+	if err := controllerutil.SetControllerReference(cr, role, r.Scheme); err != nil {
 		return role, err
 	}
-	if exists {
-		return role, r.Client.Update(context.TODO(), role)
-	} else {
-		return role, r.Client.Create(context.TODO(), role)
-	}
-
+	return role, argoutil.ApplyResource(r.Client, role)
 }
 
 func (r *ReconcileArgoCD) reconcileApplicationSetRoleBinding(cr *argoproj.ArgoCD, role *v1.Role, sa *corev1.ServiceAccount) error {
@@ -714,8 +645,9 @@ func (r *ReconcileArgoCD) reconcileApplicationSetRoleBinding(cr *argoproj.ArgoCD
 	roleBinding := newRoleBindingWithname(name, cr)
 
 	// fetch existing rolebinding by name
+	existingRoleBinding := &v1.RoleBinding{}
 	roleBindingExists := true
-	if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: roleBinding.Name, Namespace: cr.Namespace}, roleBinding); err != nil {
+	if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: roleBinding.Name, Namespace: cr.Namespace}, existingRoleBinding); err != nil {
 		if !apierrors.IsNotFound(err) {
 			return fmt.Errorf("failed to get the rolebinding associated with %s : %s", name, err)
 		}
@@ -724,7 +656,7 @@ func (r *ReconcileArgoCD) reconcileApplicationSetRoleBinding(cr *argoproj.ArgoCD
 
 	if cr.Spec.ApplicationSet == nil || !cr.Spec.ApplicationSet.IsEnabled() {
 		if roleBindingExists {
-			return r.Client.Delete(context.TODO(), roleBinding)
+			return r.Client.Delete(context.TODO(), existingRoleBinding)
 		}
 		return nil
 	}
@@ -745,15 +677,17 @@ func (r *ReconcileArgoCD) reconcileApplicationSetRoleBinding(cr *argoproj.ArgoCD
 		},
 	}
 
-	if err := controllerutil.SetControllerReference(cr, roleBinding, r.Scheme); err != nil {
-		return err
+	// RoleRef can't be updated, delete the rolebinding so that it gets recreated
+	if roleBindingExists && !reflect.DeepEqual(existingRoleBinding.RoleRef, roleBinding.RoleRef) {
+		if err := r.Client.Delete(context.TODO(), existingRoleBinding); err != nil {
+			return err
+		}
 	}
 
-	if roleBindingExists {
-		return r.Client.Update(context.TODO(), roleBinding)
+	if err := controllerutil.SetControllerReference(cr, roleBinding, r.Scheme); err != nil {
+		return err
 	}
-
-	return r.Client.Create(context.TODO(), roleBinding)
+	return argoutil.ApplyResource(r.Client, roleBinding)
 }
 
 func getApplicationSetContainerImage(cr *argoproj.ArgoCD) string {
@@ -779,7 +713,7 @@ func getApplicationSetContainerImage(cr *argoproj.ArgoCD) string {
 	}
 
 	// If an env var is specified then use that, but don't override the spec values (if they are present)
-	if e := os.Getenv(common.ArgoCDImageEnvName); e != "" && (defaultTag && defaultImg) {
+	if e := argocdoperatorconfig.Getenv(common.ArgoCDImageEnvName); e != "" && (defaultTag && defaultImg) {
 		return e
 	}
 	return argoutil.CombineImageTag(img, tag)
@@ -797,6 +731,14 @@ func getApplicationSetResources(cr *argoproj.ArgoCD) corev1.ResourceRequirements
 	return resources
 }
 
+// getApplicationSetServiceType will return the ApplicationSet webhook Service type for the ArgoCD.
+func getApplicationSetServiceType(cr *argoproj.ArgoCD) corev1.ServiceType {
+	if cr.Spec.ApplicationSet != nil && len(cr.Spec.ApplicationSet.WebhookServer.Service.Type) > 0 {
+		return cr.Spec.ApplicationSet.WebhookServer.Service.Type
+	}
+	return corev1.ServiceTypeClusterIP
+}
+
 func setAppSetLabels(obj *metav1.ObjectMeta) {
 	obj.Labels["app.kubernetes.io/name"] = "argocd-applicationset-controller"
 	obj.Labels["app.kubernetes.io/part-of"] = "argocd-applicationset"
@@ -822,11 +764,8 @@ func (r *ReconcileArgoCD) reconcileApplicationSetService(cr *argoproj.ArgoCD) er
 			}
 		}
 		return nil
-	} else {
-		if argoutil.IsObjectFound(r.Client, cr.Namespace, svc.Name, svc) {
-			return nil // Service found, do nothing
-		}
 	}
+
 	svc.Spec.Ports = []corev1.ServicePort{
 		{
 			Name:       "webhook",
@@ -848,7 +787,7 @@ func (r *ReconcileArgoCD) reconcileApplicationSetService(cr *argoproj.ArgoCD) er
 	if err := controllerutil.SetControllerReference(cr, svc, r.Scheme); err != nil {
 		return err
 	}
-	return r.Client.Create(context.TODO(), svc)
+	return r.applyCustomizedService(svc, getApplicationSetServiceType(cr), cr.Spec.ApplicationSet.WebhookServer.Service.ArgoCDServiceSpec)
 }
 
 // Returns the name of the role/rolebinding for the source namespaces for applicationset-controller in the format of "argocdName-argocdNamespace-applicationset"
@@ -966,33 +905,10 @@ func (r *ReconcileArgoCD) reconcileSourceNamespaceRole(role v1.Role, cr *argopro
 		return err
 	}
 
-	existingRole := v1.Role{}
-	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: role.Name, Namespace: role.Namespace}, &existingRole)
-	if err != nil {
-		if !apierrors.IsNotFound(err) {
-			errMsg := fmt.Errorf("failed to retrieve role %s in namespace %s", role.Name, role.Namespace)
-			return errors.Join(errMsg, err)
-		}
-
-		if err := r.Client.Create(context.TODO(), &role); err != nil {
-			errMsg := fmt.Errorf("failed to create role %s in namespace %s", role.Name, role.Namespace)
-			return errors.Join(errMsg, err)
-		}
-
-		log.Info(fmt.Sprintf("role %s created successfully for Argo CD instance %s in namespace %s", role.Name, cr.Name, role.Namespace))
-		return nil
-	}
-
-	// if the Rules differ, update the Role, ignore if role is just created.
-	if !reflect.DeepEqual(existingRole.Rules, role.Rules) {
-		existingRole.Rules = role.Rules
-		if err := r.Client.Update(context.TODO(), &existingRole); err != nil {
-			errMsg := fmt.Errorf("failed to update role %s in namespace %s", role.Name, role.Namespace)
-			return errors.Join(errMsg, err)
-		}
-		log.Info(fmt.Sprintf("role %s update successfully for Argo CD instance %s in namespace %s", role.Name, cr.Name, role.Namespace))
+	if err := argoutil.ApplyResource(r.Client, &role); err != nil {
+		errMsg := fmt.Errorf("failed to apply role %s in namespace %s", role.Name, role.Namespace)
+		return errors.Join(errMsg, err)
 	}
-
 	return nil
 }
 
@@ -1005,37 +921,22 @@ func (r *ReconcileArgoCD) reconcileSourceNamespaceRoleBinding(roleBinding v1.Rol
 
 	existingRoleBinding := v1.RoleBinding{}
 	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: roleBinding.Name, Namespace: roleBinding.Namespace}, &existingRoleBinding)
-	if err != nil {
-		if !apierrors.IsNotFound(err) {
-			errMsg := fmt.Errorf("failed to retrieve rolebinding %s in namespace %s", roleBinding.Name, roleBinding.Namespace)
-			return errors.Join(errMsg, err)
-		}
-
-		if err := r.Client.Create(context.TODO(), &roleBinding); err != nil {
-			errMsg := fmt.Errorf("failed to create rolebinding %s in namespace %s", roleBinding.Name, roleBinding.Namespace)
-			return errors.Join(errMsg, err)
-		}
-
-		log.Info(fmt.Sprintf("rolebinding %s created successfully for Argo CD instance %s in namespace %s", roleBinding.Name, cr.Name, roleBinding.Namespace))
-		return nil
+	if err != nil && !apierrors.IsNotFound(err) {
+		errMsg := fmt.Errorf("failed to retrieve rolebinding %s in namespace %s", roleBinding.Name, roleBinding.Namespace)
+		return errors.Join(errMsg, err)
 	}
 
 	// if the RoleRef changes, delete the existing role binding and create a new one
-	if !reflect.DeepEqual(roleBinding.RoleRef, existingRoleBinding.RoleRef) {
+	if err == nil && !reflect.DeepEqual(roleBinding.RoleRef, existingRoleBinding.RoleRef) {
 		if err = r.Client.Delete(context.TODO(), &existingRoleBinding); err != nil {
 			return err
 		}
-	} else {
-		// if the Subjects differ, update the role bindings
-		if !reflect.DeepEqual(roleBinding.Subjects, existingRoleBinding.Subjects) {
-			existingRoleBinding.Subjects = roleBinding.Subjects
-			if err = r.Client.Update(context.TODO(), &existingRoleBinding); err != nil {
-				return err
-			}
-			log.Info(fmt.Sprintf("rolebinding %s update successfully for Argo CD instance %s in namespace %s", roleBinding.Name, cr.Name, roleBinding.Namespace))
-		}
 	}
 
+	if err := argoutil.ApplyResource(r.Client, &roleBinding); err != nil {
+		errMsg := fmt.Errorf("failed to apply rolebinding %s in namespace %s", roleBinding.Name, roleBinding.Namespace)
+		return errors.Join(errMsg, err)
+	}
 	return nil
 }
 
@@ -265,7 +265,8 @@ func TestReconcileApplicationSet_UpdateExistingDeployments(t *testing.T) {
 				Spec: corev1.PodSpec{
 					Containers: []corev1.Container{
 						{
-							Name: "fake-container",
+							Name:  "argocd-applicationset-controller",
+							Image: "fake-image",
 						},
 					},
 				},
@@ -888,6 +889,38 @@ func TestReconcileApplicationSet_Service(t *testing.T) {
 	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Namespace: s.Namespace, Name: s.Name}, s))
 }
 
+func TestReconcileApplicationSet_Service_customization(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD()
+	a.Spec.ApplicationSet = &argoproj.ArgoCDApplicationSet{}
+
+	resObjs := []client.Object{a}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	s := newServiceWithSuffix(common.ApplicationSetServiceNameSuffix, common.ApplicationSetServiceNameSuffix, a)
+	assert.NoError(t, r.reconcileApplicationSetService(a))
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Namespace: s.Namespace, Name: s.Name}, s))
+	assert.Equal(t, corev1.ServiceTypeClusterIP, s.Spec.Type)
+
+	// Customizing the existing service updates it
+	a.Spec.ApplicationSet.WebhookServer.Service = argoproj.ArgoCDWebhookServiceSpec{
+		Type: corev1.ServiceTypeNodePort,
+		ArgoCDServiceSpec: argoproj.ArgoCDServiceSpec{
+			Annotations: map[string]string{"example.com/exposed": "true"},
+			NodePorts:   map[string]int32{"webhook": 30700},
+		},
+	}
+	assert.NoError(t, r.reconcileApplicationSetService(a))
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Namespace: s.Namespace, Name: s.Name}, s))
+	assert.Equal(t, corev1.ServiceTypeNodePort, s.Spec.Type)
+	assert.Equal(t, "true", s.Annotations["example.com/exposed"])
+	assert.Equal(t, int32(30700), s.Spec.Ports[0].NodePort)
+}
+
 func TestArgoCDApplicationSetCommand(t *testing.T) {
 	a := makeTestArgoCD()
 	a.Spec.ApplicationSet = &argoproj.ArgoCDApplicationSet{}
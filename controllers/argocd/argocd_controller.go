@@ -24,6 +24,8 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 
 	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
+	"github.com/argoproj-labs/argocd-operator/controllers/argocdoperatorconfig"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -32,8 +34,12 @@ import (
 
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logr "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 // blank assignment to verify that ReconcileArgoCD implements reconcile.Reconciler
@@ -41,6 +47,10 @@ var _ reconcile.Reconciler = &ReconcileArgoCD{}
 
 // ArgoCDReconciler reconciles a ArgoCD object
 // TODO(upgrade): rename to ArgoCDRecoonciler
+//
+// The fields describing the managed namespaces and the SSO configuration are computed for the ArgoCD being
+// reconciled, each request is reconciled with its own copy of the reconciler so that concurrent reconciles do not
+// share them.
 type ReconcileArgoCD struct {
 	client.Client
 	Scheme            *runtime.Scheme
@@ -51,16 +61,30 @@ type ReconcileArgoCD struct {
 	ManagedApplicationSetSourceNamespaces map[string]string
 	// Stores label selector used to reconcile a subset of ArgoCD
 	LabelSelector string
+	// Stores the optional APIs served by the cluster
+	ClusterAPIs *ClusterAPIs
+	// Stores the number of ArgoCD reconciled concurrently, defaults to 1
+	MaxConcurrentReconciles int
+	// Stores the interval at which the optional APIs served by the cluster are discovered again, 0 disables it
+	APIDiscoveryInterval time.Duration
+
+	// Tracks the running Argo CD instances, shared by the copies of the reconciler
+	instances *instanceTracker
+	// Keeps the results of the probes of the remote Redis instances, shared by the copies of the reconciler
+	redisProbes *redisProbeCache
+	// Starts the watches of the optional APIs discovered at runtime, shared by the copies of the reconciler
+	discovery *apiDiscovery
+	// Reads the endpoints of the kubernetes Service whatever the namespaces watched by the manager, Client being used
+	// when nil
+	apiServerEndpoints client.Reader
+	// Tracks whether the SSO configuration of the ArgoCD is legal
+	ssoConfigLegalStatus string
+	// Tracks whether the reconciler runs against an in-memory cluster, the changes made without Client are skipped
+	offline bool
 }
 
 var log = logr.Log.WithName("controller_argocd")
 
-// Map to keep track of running Argo CD instances using their namespaces as key and phase as value
-// This map will be used for the performance metrics purposes
-// Important note: This assumes that each instance only contains one Argo CD instance
-// as, having multiple Argo CD instances in the same namespace is considered an anti-pattern
-var ActiveInstanceMap = make(map[string]string)
-
 //+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles;clusterrolebindings,verbs=*
 //+kubebuilder:rbac:groups="",resources=configmaps;endpoints;events;persistentvolumeclaims;pods;namespaces;secrets;serviceaccounts;services;services/finalizers,verbs=*
 //+kubebuilder:rbac:groups=apps.openshift.io,resources=deploymentconfigs,verbs=*
@@ -74,6 +98,8 @@ var ActiveInstanceMap = make(map[string]string)
 //+kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=create;delete;get;list;patch;update;watch;
 //+kubebuilder:rbac:groups=monitoring.coreos.com,resources=prometheuses;prometheusrules;servicemonitors,verbs=*
 //+kubebuilder:rbac:groups=route.openshift.io,resources=routes;routes/custom-host,verbs=*
+//+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes;grpcroutes,verbs=*
+//+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=gateways,verbs=get;list;watch
 //+kubebuilder:rbac:groups=argoproj.io,resources=applications;appprojects,verbs=*
 //+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=*,verbs=*
 //+kubebuilder:rbac:groups="",resources=pods;pods/log,verbs=get
@@ -90,10 +116,16 @@ var ActiveInstanceMap = make(map[string]string)
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.9.2/pkg/reconcile
 func (r *ReconcileArgoCD) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	instanceReconciler := *r
+	return instanceReconciler.reconcile(ctx, request)
+}
+
+// reconcile reconciles the ArgoCD of the given request, on a copy of the reconciler dedicated to the request.
+func (r *ReconcileArgoCD) reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
 
 	reconcileStartTS := time.Now()
 	defer func() {
-		ReconcileTime.WithLabelValues(request.Namespace).Observe(time.Since(reconcileStartTS).Seconds())
+		ReconcileTime.WithLabelValues(request.Namespace, request.Name).Observe(time.Since(reconcileStartTS).Seconds())
 	}()
 
 	reqLogger := logr.FromContext(ctx, "namespace", request.Namespace, "name", request.Name)
@@ -112,61 +144,65 @@ func (r *ReconcileArgoCD) Reconcile(ctx context.Context, request ctrl.Request) (
 		return reconcile.Result{}, err
 	}
 
-	// Fetch labelSelector from r.LabelSelector (command-line option)
-	labelSelector, err := labels.Parse(r.LabelSelector)
+	// Fetch labelSelector from the ArgoCDOperatorConfig, or from r.LabelSelector (command-line option)
+	selector := r.LabelSelector
+	if value, ok := argocdoperatorconfig.Lookup(common.ArgoCDLabelSelectorKey); ok {
+		selector = value
+	}
+	labelSelector, err := labels.Parse(selector)
 	if err != nil {
-		reqLogger.Info(fmt.Sprintf("error parsing the labelSelector '%s'.", labelSelector))
+		reqLogger.Info(fmt.Sprintf("error parsing the labelSelector '%s'.", selector))
 		return reconcile.Result{}, err
 	}
 	// Match the value of labelSelector from ReconcileArgoCD to labels from the argocd instance
 	if !labelSelector.Matches(labels.Set(argocd.Labels)) {
-		reqLogger.Info(fmt.Sprintf("the ArgoCD instance '%s' does not match the label selector '%s' and skipping for reconciliation", request.NamespacedName, r.LabelSelector))
+		reqLogger.Info(fmt.Sprintf("the ArgoCD instance '%s' does not match the label selector '%s' and skipping for reconciliation", request.NamespacedName, selector))
 		return reconcile.Result{}, fmt.Errorf("error: failed to reconcile ArgoCD instance: '%s'", request.NamespacedName)
 	}
 
-	newPhase := argocd.Status.Phase
-	// If we discover a new Argo CD instance in a previously un-seen namespace
-	// we add it to the map and increment active instance count by phase
-	// as well as total active instance count
-	if _, ok := ActiveInstanceMap[request.Namespace]; !ok {
-		if newPhase != "" {
-			ActiveInstanceMap[request.Namespace] = newPhase
-			ActiveInstancesByPhase.WithLabelValues(newPhase).Inc()
-			ActiveInstancesTotal.Inc()
-		}
-	} else {
-		// If we discover an existing instance's phase has changed since we last saw it
-		// increment instance count with new phase and decrement instance count with old phase
-		// update the phase in corresponding map entry
-		// total instance count remains the same
-		if oldPhase := ActiveInstanceMap[argocd.Namespace]; oldPhase != newPhase {
-			ActiveInstanceMap[argocd.Namespace] = newPhase
-			ActiveInstancesByPhase.WithLabelValues(newPhase).Inc()
-			ActiveInstancesByPhase.WithLabelValues(oldPhase).Dec()
-		}
-	}
+	r.instances.observe(argocd)
 
-	ActiveInstanceReconciliationCount.WithLabelValues(argocd.Namespace).Inc()
+	ActiveInstanceReconciliationCount.WithLabelValues(argocd.Namespace, argocd.Name).Inc()
 
 	if argocd.GetDeletionTimestamp() != nil {
 
-		// Argo CD instance marked for deletion; remove entry from activeInstances map and decrement active instance count
-		// by phase as well as total
-		delete(ActiveInstanceMap, argocd.Namespace)
-		ActiveInstancesByPhase.WithLabelValues(newPhase).Dec()
-		ActiveInstancesTotal.Dec()
-		ActiveInstanceReconciliationCount.DeleteLabelValues(argocd.Namespace)
-		ReconcileTime.DeletePartialMatch(prometheus.Labels{"namespace": argocd.Namespace})
+		// Argo CD instance marked for deletion; remove it from the tracked instances
+		r.instances.forget(argocd)
+		r.redisProbes.forget(instanceKey(argocd))
+		ActiveInstanceReconciliationCount.DeleteLabelValues(argocd.Namespace, argocd.Name)
+		ReconcileTime.DeletePartialMatch(prometheus.Labels{"namespace": argocd.Namespace, "name": argocd.Name})
+		DriftCorrectionsTotal.DeletePartialMatch(prometheus.Labels{"namespace": argocd.Namespace, "name": argocd.Name})
 
 		if argocd.IsDeletionFinalizerPresent() {
 			if err := r.deleteClusterResources(argocd); err != nil {
 				return reconcile.Result{}, fmt.Errorf("failed to delete ClusterResources: %w", err)
 			}
 
+			// Release the namespaces labeled through the managed namespace selector
+			if err := r.reconcileManagedNamespaceSelector(argocd); err != nil {
+				return reconcile.Result{}, fmt.Errorf("failed to release namespaces selected by the managed namespace selector, error: %w", err)
+			}
+
 			if isRemoveManagedByLabelOnArgoCDDeletion() {
-				if err := r.removeManagedByLabelFromNamespaces(argocd.Namespace); err != nil {
-					return reconcile.Result{}, fmt.Errorf("failed to remove label from namespace[%v], error: %w", argocd.Namespace, err)
+				// The label names the namespace of the instance, keep it while another instance remains there
+				sharedNamespace, err := r.hasOtherArgoCDInNamespace(argocd)
+				if err != nil {
+					return reconcile.Result{}, err
 				}
+				if !sharedNamespace {
+					if err := r.removeManagedByLabelFromNamespaces(argocd.Namespace); err != nil {
+						return reconcile.Result{}, fmt.Errorf("failed to remove label from namespace[%v], error: %w", argocd.Namespace, err)
+					}
+				}
+			}
+
+			// The source namespaces are computed for each request, list the ones to clean up
+			if err := r.setManagedSourceNamespaces(argocd); err != nil {
+				return reconcile.Result{}, err
+			}
+
+			if err := r.setManagedApplicationSetSourceNamespaces(argocd); err != nil {
+				return reconcile.Result{}, err
 			}
 
 			if err := r.removeUnmanagedSourceNamespaceResources(argocd); err != nil {
@@ -180,10 +216,6 @@ func (r *ReconcileArgoCD) Reconcile(ctx context.Context, request ctrl.Request) (
 			if err := r.removeDeletionFinalizer(argocd); err != nil {
 				return reconcile.Result{}, err
 			}
-
-			// remove namespace of deleted Argo CD instance from deprecationEventEmissionTracker (if exists) so that if another instance
-			// is created in the same namespace in the future, that instance is appropriately tracked
-			delete(DeprecationEventEmissionTracker, argocd.Namespace)
 		}
 		return reconcile.Result{}, nil
 	}
@@ -199,30 +231,138 @@ func (r *ReconcileArgoCD) Reconcile(ctx context.Context, request ctrl.Request) (
 		return reconcile.Result{}, err
 	}
 
-	if err = r.setManagedNamespaces(argocd); err != nil {
+	strategy := getReconcileStrategy(argocd)
+	if strategy == argoproj.ReconcileStrategyPaused {
+		reqLogger.Info("the ArgoCD instance is paused, skipping reconciliation")
+		return reconcile.Result{}, r.reconcileStatusStrategy(argocd, strategy, nil)
+	}
+
+	// A single instance of the namespace may control the configuration read by the Argo CD components
+	conflict, err := r.getConfigurationConflict(argocd)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if err := r.reconcileStatusConfigurationConflict(argocd, conflict); err != nil {
 		return reconcile.Result{}, err
 	}
+	if conflict != "" {
+		reqLogger.Info(fmt.Sprintf("%s, skipping reconciliation", conflict))
+		return reconcile.Result{RequeueAfter: configurationConflictRequeueInterval}, nil
+	}
+
+	// The objects written for the instance are inventoried, and the changes to the resources of an observed instance
+	// or of its paused components are reported as drift
+	c := r.Client
+	inventory := newInventoryClient(c, argocd)
+	r.Client = inventory
+	var guarded *guardedClient
+	if strategy == argoproj.ReconcileStrategyObserveOnly || len(argocd.Spec.PausedComponents) > 0 {
+		guarded = newGuardedClient(r.Client, argocd, strategy == argoproj.ReconcileStrategyObserveOnly)
+		r.Client = guarded
+	}
 
-	if err = r.setManagedSourceNamespaces(argocd); err != nil {
+	err = r.reconcileInstance(argocd)
+	r.Client = c
+	var drift []argoproj.ArgoCDResourceDrift
+	if guarded != nil {
+		drift = guarded.getDrift()
+	}
+	if err != nil {
+		// Error reconciling ArgoCD sub-resources - requeue the request.
+		if guarded != nil {
+			_ = r.reconcileStatusStrategy(argocd, strategy, drift)
+		}
 		return reconcile.Result{}, err
 	}
 
-	if err = r.setManagedApplicationSetSourceNamespaces(argocd); err != nil {
+	if err := r.reconcileStatusStrategy(argocd, strategy, drift); err != nil {
 		return reconcile.Result{}, err
 	}
 
-	if err := r.reconcileResources(argocd); err != nil {
-		// Error reconciling ArgoCD sub-resources - requeue the request.
+	if err := r.reconcileStatusInventory(argocd, inventory.getInventory()); err != nil {
 		return reconcile.Result{}, err
 	}
 
+	// Requeue while the version is upgraded, to check the readiness of the components
+	if isUpgradeInProgress(argocd) {
+		return reconcile.Result{RequeueAfter: upgradeRequeueInterval}, nil
+	}
+
+	// Requeue for the next scheduled rotation of the Redis password, if any
+	if requeueAfter := r.getRedisPasswordRotationRequeueAfter(argocd); requeueAfter > 0 {
+		return reconcile.Result{RequeueAfter: requeueAfter}, nil
+	}
+
 	// Return and don't requeue
 	return reconcile.Result{}, nil
 }
 
+// reconcileInstance sets the namespaces managed by the given ArgoCD and reconciles its resources.
+func (r *ReconcileArgoCD) reconcileInstance(argocd *argoproj.ArgoCD) error {
+	if err := r.setManagedNamespaces(argocd); err != nil {
+		return err
+	}
+
+	if err := r.setManagedSourceNamespaces(argocd); err != nil {
+		return err
+	}
+
+	if err := r.setManagedApplicationSetSourceNamespaces(argocd); err != nil {
+		return err
+	}
+
+	return r.reconcileResources(argocd)
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *ReconcileArgoCD) SetupWithManager(mgr ctrl.Manager) error {
+	if r.instances == nil {
+		r.instances = newInstanceTracker()
+	}
+	if r.redisProbes == nil {
+		r.redisProbes = newRedisProbeCache()
+	}
+
+	r.discovery = newAPIDiscovery(r.ClusterAPIs)
+
 	bldr := ctrl.NewControllerManagedBy(mgr)
-	r.setResourceWatches(bldr, r.clusterResourceMapper, r.tlsSecretMapper, r.namespaceResourceMapper, r.clusterSecretResourceMapper, r.applicationSetSCMTLSConfigMapMapper)
-	return bldr.Complete(r)
+	if r.MaxConcurrentReconciles > 0 {
+		bldr.WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles})
+	}
+	r.setResourceWatches(bldr, r.clusterResourceMapper, r.tlsSecretMapper, r.namespaceResourceMapper, r.clusterSecretResourceMapper, r.applicationSetSCMTLSConfigMapMapper, r.repositorySecretMapper)
+	bldr.WatchesRawSource(&source.Channel{Source: r.discovery.requeue}, &handler.EnqueueRequestForObject{})
+
+	// the API server endpoints allowed by the network policies live in the default namespace
+	endpointsCache, err := newAPIServerEndpointsCache(mgr)
+	if err != nil {
+		return err
+	}
+	if err := mgr.Add(endpointsCache); err != nil {
+		return err
+	}
+	r.apiServerEndpoints = endpointsCache
+	bldr.WatchesRawSource(source.Kind(endpointsCache, &corev1.Endpoints{}), handler.EnqueueRequestsFromMapFunc(r.apiServerEndpointsMapper))
+
+	c, err := bldr.Build(r)
+	if err != nil {
+		return err
+	}
+
+	r.discovery.controller = c
+	r.discovery.cache = mgr.GetCache()
+	r.discovery.scheme = mgr.GetScheme()
+	r.discovery.mapper = mgr.GetRESTMapper()
+
+	active := r.ClusterAPIs.activeIntegrations()
+	recordActiveIntegrations(active)
+	for _, i := range integrations() {
+		log.Info(fmt.Sprintf("integration %s active: %t", i.name, active[i.name]))
+	}
+
+	if r.APIDiscoveryInterval <= 0 {
+		return nil
+	}
+	return mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		return r.discoverAPIs(ctx, r.APIDiscoveryInterval)
+	}))
 }
@@ -17,6 +17,7 @@ package argocd
 import (
 	"context"
 	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -27,6 +28,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/rbac/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -384,3 +386,150 @@ func clusterResources(argocd *argoproj.ArgoCD) []client.Object {
 		newClusterRoleBindingWithname(common.ArgoCDServerComponent, argocd),
 	}
 }
+
+func TestReconcileArgoCD_ActiveInstanceMapWithMultipleInstances(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	prod := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Name = "prod"
+		a.Status.Phase = "Available"
+	})
+	staging := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Name = "staging"
+		a.Status.Phase = "Pending"
+	})
+
+	resObjs := []client.Object{prod, staging}
+	subresObjs := []client.Object{prod, staging}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, createNamespace(r, testNamespace, ""))
+
+	for _, a := range []*argoproj.ArgoCD{prod, staging} {
+		_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: a.Name, Namespace: a.Namespace}})
+		assert.NoError(t, err)
+	}
+
+	phase, _ := r.instances.phase(testNamespace + "/prod")
+	assert.Equal(t, "Available", phase)
+	phase, _ = r.instances.phase(testNamespace + "/staging")
+	assert.Equal(t, "Pending", phase)
+}
+
+func TestReconcileArgoCD_ConcurrentReconciles(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+
+	const count = 8
+	instances := []*argoproj.ArgoCD{}
+	resObjs := []client.Object{}
+	for i := 0; i < count; i++ {
+		a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+			a.Namespace = fmt.Sprintf("argocd-%d", i)
+			a.Status.Phase = "Available"
+		})
+		instances = append(instances, a)
+		resObjs = append(resObjs, a)
+	}
+
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, resObjs, []runtime.Object{})
+	r := makeTestReconciler(cl, sch)
+
+	for i, a := range instances {
+		assert.NoError(t, createNamespace(r, a.Namespace, ""))
+		assert.NoError(t, createNamespace(r, fmt.Sprintf("tenant-%d", i), a.Namespace))
+	}
+
+	// reconcile all instances concurrently, twice, as the workqueue never hands out the same instance concurrently
+	for n := 0; n < 2; n++ {
+		var wg sync.WaitGroup
+		errs := make(chan error, count)
+		for _, a := range instances {
+			wg.Add(1)
+			go func(a *argoproj.ArgoCD) {
+				defer wg.Done()
+				_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: a.Name, Namespace: a.Namespace}})
+				errs <- err
+			}(a)
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			assert.NoError(t, err)
+		}
+	}
+
+	for i, a := range instances {
+		cr := &argoproj.ArgoCD{}
+		assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: a.Name, Namespace: a.Namespace}, cr))
+		phase, ok := r.instances.phase(instanceKey(cr))
+		assert.True(t, ok)
+		assert.Equal(t, cr.Status.Phase, phase)
+
+		// the managed namespace of each instance only grants access to that instance
+		roleBindings := &v1.RoleBindingList{}
+		assert.NoError(t, r.Client.List(context.TODO(), roleBindings, client.InNamespace(fmt.Sprintf("tenant-%d", i))))
+		assert.NotEmpty(t, roleBindings.Items)
+		for _, rb := range roleBindings.Items {
+			for _, subject := range rb.Subjects {
+				assert.Equal(t, a.Namespace, subject.Namespace)
+			}
+		}
+	}
+}
+
+func TestReconcileArgoCD_ConfigurationConflict(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	prod := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Name = "prod"
+		a.UID = "prod-uid"
+	})
+	staging := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Name = "staging"
+		a.UID = "staging-uid"
+	})
+
+	resObjs := []client.Object{prod, staging}
+	subresObjs := []client.Object{prod, staging}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, createNamespace(r, testNamespace, ""))
+
+	_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: prod.Name, Namespace: prod.Namespace}})
+	assert.NoError(t, err)
+	cm := &corev1.ConfigMap{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: common.ArgoCDConfigMapName, Namespace: testNamespace}, cm))
+	assert.True(t, metav1.IsControlledBy(cm, prod))
+
+	// the second instance does not take over the configuration of the first one
+	res, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: staging.Name, Namespace: staging.Namespace}})
+	assert.NoError(t, err)
+	assert.Equal(t, configurationConflictRequeueInterval, res.RequeueAfter)
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: common.ArgoCDConfigMapName, Namespace: testNamespace}, cm))
+	assert.True(t, metav1.IsControlledBy(cm, prod))
+
+	cr := &argoproj.ArgoCD{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: staging.Name, Namespace: staging.Namespace}, cr))
+	assert.Equal(t, "Failed", cr.Status.Phase)
+	condition := meta.FindStatusCondition(cr.Status.Conditions, argoproj.ArgoCDConditionConfigurationConflict)
+	assert.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Contains(t, condition.Message, "controlled by ArgoCD prod")
+
+	// once the configuration is released, the second instance takes it over
+	assert.NoError(t, r.Client.Delete(context.TODO(), cm))
+	for _, name := range []string{common.ArgoCDRBACConfigMapName} {
+		assert.NoError(t, r.Client.Delete(context.TODO(), &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace}}))
+	}
+	_ = r.Client.Delete(context.TODO(), &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: common.ArgoCDSecretName, Namespace: testNamespace}})
+	_, err = r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: staging.Name, Namespace: staging.Namespace}})
+	assert.NoError(t, err)
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: staging.Name, Namespace: staging.Namespace}, cr))
+	assert.False(t, meta.IsStatusConditionTrue(cr.Status.Conditions, argoproj.ArgoCDConditionConfigurationConflict))
+	assert.NotEqual(t, "Failed", cr.Status.Phase)
+}
@@ -0,0 +1,177 @@
+// Copyright 2019 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argocd
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
+	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
+)
+
+var (
+	// appProjectGVK is the kind of the Argo CD AppProjects.
+	appProjectGVK = schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "AppProject"}
+
+	// applicationGVK is the kind of the Argo CD Applications.
+	applicationGVK = schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Application"}
+)
+
+// isBootstrapReady returns true when the Argo CD components of the given ArgoCD serving the bootstrap objects are
+// available: the server, or the application controller when the server is disabled.
+func isBootstrapReady(cr *argoproj.ArgoCD) bool {
+	if !cr.Spec.Server.IsEnabled() {
+		return cr.Status.ApplicationController == "Running"
+	}
+	return cr.Status.Server == "Running"
+}
+
+// getBootstrapOwnership will return the ownership of the given bootstrap object, ArgoCD by default.
+func getBootstrapOwnership(obj argoproj.ArgoCDBootstrapObject) argoproj.ArgoCDBootstrapOwnership {
+	if obj.Ownership == "" {
+		return argoproj.ArgoCDBootstrapOwnershipArgoCD
+	}
+	return obj.Ownership
+}
+
+// newBootstrapObject will return the AppProject or Application of the given kind and bootstrap object, in the
+// namespace of the given ArgoCD.
+func newBootstrapObject(cr *argoproj.ArgoCD, gvk schema.GroupVersionKind, obj argoproj.ArgoCDBootstrapObject) (*unstructured.Unstructured, error) {
+	spec := map[string]interface{}{}
+	if len(obj.Spec.Raw) > 0 {
+		// integral numbers are decoded as int64, like the objects read from the API server
+		if err := utiljson.Unmarshal(obj.Spec.Raw, &spec); err != nil {
+			return nil, fmt.Errorf("invalid spec of bootstrap %s %s: %w", gvk.Kind, obj.Name, err)
+		}
+	}
+
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(gvk)
+	u.SetName(obj.Name)
+	u.SetNamespace(cr.Namespace)
+	u.SetLabels(obj.Labels)
+	u.SetAnnotations(map[string]string{
+		common.AnnotationName:               cr.Name,
+		common.AnnotationNamespace:          cr.Namespace,
+		common.AnnotationBootstrapOwnership: string(getBootstrapOwnership(obj)),
+	})
+	u.Object["spec"] = spec
+	return u, nil
+}
+
+// reconcileBootstrap will create the bootstrap AppProjects, then Applications, of the given ArgoCD once its Argo CD
+// components are available. The objects left to Argo CD are applied once, the ones owned by the operator are kept
+// up to date. The objects removed from the bootstrap spec are left as they are.
+func (r *ReconcileArgoCD) reconcileBootstrap(cr *argoproj.ArgoCD) error {
+	if cr.Spec.Bootstrap == nil {
+		return nil
+	}
+	if !isBootstrapReady(cr) {
+		log.Info(fmt.Sprintf("waiting for the Argo CD components of ArgoCD %s/%s to bootstrap its projects and applications", cr.Namespace, cr.Name))
+		return nil
+	}
+
+	for _, project := range cr.Spec.Bootstrap.Projects {
+		if err := r.reconcileBootstrapObject(cr, appProjectGVK, project); err != nil {
+			return err
+		}
+	}
+	for _, application := range cr.Spec.Bootstrap.Applications {
+		if err := r.reconcileBootstrapObject(cr, applicationGVK, application); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reconcileBootstrapObject will create or update the AppProject or Application of the given kind and bootstrap object.
+func (r *ReconcileArgoCD) reconcileBootstrapObject(cr *argoproj.ArgoCD, gvk schema.GroupVersionKind, obj argoproj.ArgoCDBootstrapObject) error {
+	desired, err := newBootstrapObject(cr, gvk, obj)
+	if err != nil {
+		return err
+	}
+
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(gvk)
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Namespace: cr.Namespace, Name: obj.Name}, existing)
+	if meta.IsNoMatchError(err) {
+		log.Info(fmt.Sprintf("the %s API is not available, skipping the bootstrap of ArgoCD %s/%s", gvk.Kind, cr.Namespace, cr.Name))
+		return nil
+	} else if apierrors.IsNotFound(err) {
+		log.Info(fmt.Sprintf("bootstrapping %s %s of ArgoCD %s/%s", gvk.Kind, obj.Name, cr.Namespace, cr.Name))
+		if err := r.Client.Create(context.TODO(), desired); err != nil {
+			return err
+		}
+		message := fmt.Sprintf("%s %s was bootstrapped", gvk.Kind, obj.Name)
+		typeMeta := metav1.TypeMeta{Kind: "ArgoCD", APIVersion: argoproj.GroupVersion.String()}
+		if err := argoutil.CreateEvent(r.Client, corev1.EventTypeNormal, "Bootstrap", message, "Bootstrapped", cr.ObjectMeta, typeMeta); err != nil {
+			log.Error(err, fmt.Sprintf("failed to report the bootstrap of %s %s", gvk.Kind, obj.Name))
+		}
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	ownership := getBootstrapOwnership(obj)
+	annotations := existing.GetAnnotations()
+	applied := annotations[common.AnnotationBootstrapOwnership] != ""
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	changed := false
+	for key, value := range desired.GetAnnotations() {
+		if annotations[key] != value {
+			annotations[key] = value
+			changed = true
+		}
+	}
+
+	// the objects left to Argo CD are only applied once, such as the default AppProject created by the Argo CD server
+	if ownership == argoproj.ArgoCDBootstrapOwnershipOperator || !applied {
+		labels := existing.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		for key, value := range obj.Labels {
+			if labels[key] != value {
+				labels[key] = value
+				changed = true
+			}
+		}
+		existing.SetLabels(labels)
+		if !equality.Semantic.DeepEqual(existing.Object["spec"], desired.Object["spec"]) {
+			existing.Object["spec"] = desired.Object["spec"]
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	existing.SetAnnotations(annotations)
+	log.Info(fmt.Sprintf("applying bootstrap %s %s of ArgoCD %s/%s", gvk.Kind, obj.Name, cr.Namespace, cr.Name))
+	return r.Client.Update(context.TODO(), existing)
+}
@@ -0,0 +1,179 @@
+package argocd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
+)
+
+// makeTestBootstrapClient returns a client serving the AppProject and Application APIs of Argo CD.
+func makeTestBootstrapClient(sch *runtime.Scheme, objs ...client.Object) client.Client {
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{appProjectGVK.GroupVersion()})
+	mapper.Add(appProjectGVK, meta.RESTScopeNamespace)
+	mapper.Add(applicationGVK, meta.RESTScopeNamespace)
+	for gvk := range sch.AllKnownTypes() {
+		mapper.Add(gvk, meta.RESTScopeNamespace)
+	}
+	return fake.NewClientBuilder().WithScheme(sch).WithRESTMapper(mapper).WithObjects(objs...).Build()
+}
+
+func makeTestBootstrapObject(name, spec string, ownership argoproj.ArgoCDBootstrapOwnership) argoproj.ArgoCDBootstrapObject {
+	return argoproj.ArgoCDBootstrapObject{
+		Name:      name,
+		Spec:      runtime.RawExtension{Raw: []byte(spec)},
+		Ownership: ownership,
+	}
+}
+
+func getTestBootstrapObject(t *testing.T, cl client.Client, gvk schema.GroupVersionKind, name string) *unstructured.Unstructured {
+	t.Helper()
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	if err := cl.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: name}, obj); err != nil {
+		return nil
+	}
+	return obj
+}
+
+func TestReconcileArgoCD_reconcileBootstrap(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.Bootstrap = &argoproj.ArgoCDBootstrapSpec{
+			Projects: []argoproj.ArgoCDBootstrapObject{
+				makeTestBootstrapObject("platform", `{"sourceRepos":["https://github.com/example/*"],"destinations":[{"namespace":"*","server":"https://kubernetes.default.svc"}]}`, argoproj.ArgoCDBootstrapOwnershipOperator),
+			},
+			Applications: []argoproj.ArgoCDBootstrapObject{
+				makeTestBootstrapObject("root", `{"project":"platform","source":{"repoURL":"https://github.com/example/apps.git","path":"apps"},"syncPolicy":{"retry":{"limit":5}}}`, ""),
+			},
+		}
+	})
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestBootstrapClient(sch, a)
+	r := makeTestReconciler(cl, sch)
+
+	// the bootstrap waits for the Argo CD server
+	assert.NoError(t, r.reconcileBootstrap(a))
+	assert.Nil(t, getTestBootstrapObject(t, cl, appProjectGVK, "platform"))
+
+	a.Status.Server = "Running"
+	assert.NoError(t, r.reconcileBootstrap(a))
+
+	project := getTestBootstrapObject(t, cl, appProjectGVK, "platform")
+	assert.NotNil(t, project)
+	assert.Equal(t, "Operator", project.GetAnnotations()[common.AnnotationBootstrapOwnership])
+	assert.Equal(t, a.Name, project.GetAnnotations()[common.AnnotationName])
+	repos, _, _ := unstructured.NestedStringSlice(project.Object, "spec", "sourceRepos")
+	assert.Equal(t, []string{"https://github.com/example/*"}, repos)
+
+	application := getTestBootstrapObject(t, cl, applicationGVK, "root")
+	assert.NotNil(t, application)
+	assert.Equal(t, "ArgoCD", application.GetAnnotations()[common.AnnotationBootstrapOwnership])
+	limit, _, _ := unstructured.NestedInt64(application.Object, "spec", "syncPolicy", "retry", "limit")
+	assert.Equal(t, int64(5), limit)
+
+	// Argo CD takes over the root Application, while the operator keeps the project reconciled
+	unstructured.SetNestedField(application.Object, "apps/production", "spec", "source", "path")
+	assert.NoError(t, cl.Update(context.TODO(), application))
+	unstructured.SetNestedStringSlice(project.Object, []string{"*"}, "spec", "sourceRepos")
+	assert.NoError(t, cl.Update(context.TODO(), project))
+
+	assert.NoError(t, r.reconcileBootstrap(a))
+
+	application = getTestBootstrapObject(t, cl, applicationGVK, "root")
+	path, _, _ := unstructured.NestedString(application.Object, "spec", "source", "path")
+	assert.Equal(t, "apps/production", path)
+	project = getTestBootstrapObject(t, cl, appProjectGVK, "platform")
+	repos, _, _ = unstructured.NestedStringSlice(project.Object, "spec", "sourceRepos")
+	assert.Equal(t, []string{"https://github.com/example/*"}, repos)
+
+	// an unchanged object is not updated
+	version := project.GetResourceVersion()
+	assert.NoError(t, r.reconcileBootstrap(a))
+	project = getTestBootstrapObject(t, cl, appProjectGVK, "platform")
+	assert.Equal(t, version, project.GetResourceVersion())
+}
+
+func TestReconcileArgoCD_reconcileBootstrap_existingDefaultProject(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.Bootstrap = &argoproj.ArgoCDBootstrapSpec{
+			Projects: []argoproj.ArgoCDBootstrapObject{
+				makeTestBootstrapObject("default", `{"sourceRepos":["https://github.com/example/*"]}`, ""),
+			},
+		}
+		a.Status.Server = "Running"
+	})
+
+	// the default AppProject created by the Argo CD server
+	project := &unstructured.Unstructured{}
+	project.SetGroupVersionKind(appProjectGVK)
+	project.SetName("default")
+	project.SetNamespace(testNamespace)
+	project.Object["spec"] = map[string]interface{}{"sourceRepos": []interface{}{"*"}}
+
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestBootstrapClient(sch, a, project)
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileBootstrap(a))
+
+	// the restrictions are applied once
+	project = getTestBootstrapObject(t, cl, appProjectGVK, "default")
+	repos, _, _ := unstructured.NestedStringSlice(project.Object, "spec", "sourceRepos")
+	assert.Equal(t, []string{"https://github.com/example/*"}, repos)
+	assert.Equal(t, "ArgoCD", project.GetAnnotations()[common.AnnotationBootstrapOwnership])
+
+	unstructured.SetNestedStringSlice(project.Object, []string{"*"}, "spec", "sourceRepos")
+	assert.NoError(t, cl.Update(context.TODO(), project))
+	assert.NoError(t, r.reconcileBootstrap(a))
+
+	project = getTestBootstrapObject(t, cl, appProjectGVK, "default")
+	repos, _, _ = unstructured.NestedStringSlice(project.Object, "spec", "sourceRepos")
+	assert.Equal(t, []string{"*"}, repos)
+}
+
+func TestReconcileArgoCD_reconcileBootstrap_serverDisabled(t *testing.T) {
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		enabled := false
+		a.Spec.Server.Enabled = &enabled
+		a.Status.Server = "Unknown"
+	})
+	assert.False(t, isBootstrapReady(a))
+
+	a.Status.ApplicationController = "Running"
+	assert.True(t, isBootstrapReady(a))
+}
+
+func TestReconcileArgoCD_reconcileBootstrap_noAPI(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.Bootstrap = &argoproj.ArgoCDBootstrapSpec{
+			Applications: []argoproj.ArgoCDBootstrapObject{
+				makeTestBootstrapObject("root", `{"project":"default"}`, ""),
+			},
+		}
+		a.Status.Server = "Running"
+	})
+
+	resObjs := []client.Object{a}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	// the Argo CD APIs are not served, the bootstrap is skipped
+	assert.NoError(t, r.reconcileBootstrap(a))
+}
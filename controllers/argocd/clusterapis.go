@@ -0,0 +1,82 @@
+// Copyright 2019 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argocd
+
+import (
+	"sync"
+)
+
+// ClusterAPIs records which of the optional APIs the operator integrates with are served by the cluster, such as
+// Prometheus, OpenShift Routes and the Gateway API. It is safe for concurrent use, and a nil ClusterAPIs reports
+// every optional API as missing.
+type ClusterAPIs struct {
+	mu               sync.RWMutex
+	route            bool
+	prometheus       bool
+	gateway          bool
+	grpcRoute        bool
+	template         bool
+	deploymentConfig bool
+	version          bool
+}
+
+// InspectCluster will verify the availability of extra features available to the cluster, such as Prometheus and
+// OpenShift Routes and the Gateway API. The returned ClusterAPIs is never nil, on error it records the APIs verified
+// before the error.
+func InspectCluster() (*ClusterAPIs, error) {
+	apis := &ClusterAPIs{}
+	return apis, apis.Inspect()
+}
+
+// Inspect verifies again the availability of each optional API.
+func (a *ClusterAPIs) Inspect() error {
+	if err := a.verifyPrometheusAPI(); err != nil {
+		return err
+	}
+
+	if err := a.verifyRouteAPI(); err != nil {
+		return err
+	}
+
+	if err := a.verifyGatewayAPI(); err != nil {
+		return err
+	}
+
+	if err := a.verifyKeycloakTemplateAPIs(); err != nil {
+		return err
+	}
+
+	if err := a.verifyVersionAPI(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// isAvailable returns the value of the given field of the ClusterAPIs, or false if a is nil.
+func (a *ClusterAPIs) isAvailable(field func(*ClusterAPIs) bool) bool {
+	if a == nil {
+		return false
+	}
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return field(a)
+}
+
+// setAvailable sets a field of the ClusterAPIs.
+func (a *ClusterAPIs) setAvailable(set func(*ClusterAPIs)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	set(a)
+}
@@ -16,33 +16,23 @@ package argocd
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
 	"reflect"
+	"sort"
 
 	"gopkg.in/yaml.v2"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	k8syaml "sigs.k8s.io/yaml"
 
 	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
 	"github.com/argoproj-labs/argocd-operator/common"
 	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
 )
 
-// createRBACConfigMap will create the Argo CD RBAC ConfigMap resource.
-func (r *ReconcileArgoCD) createRBACConfigMap(cm *corev1.ConfigMap, cr *argoproj.ArgoCD) error {
-	data := make(map[string]string)
-	data[common.ArgoCDKeyRBACPolicyCSV] = getRBACPolicy(cr)
-	data[common.ArgoCDKeyRBACPolicyDefault] = getRBACDefaultPolicy(cr)
-	data[common.ArgoCDKeyRBACScopes] = getRBACScopes(cr)
-	cm.Data = data
-
-	if err := controllerutil.SetControllerReference(cr, cm, r.Scheme); err != nil {
-		return err
-	}
-	return r.Client.Create(context.TODO(), cm)
-}
-
 // getApplicationInstanceLabelKey will return the application instance label key  for the given ArgoCD.
 func getApplicationInstanceLabelKey(cr *argoproj.ArgoCD) string {
 	key := common.ArgoCDDefaultApplicationInstanceLabelKey
@@ -149,12 +139,16 @@ func getRBACScopes(cr *argoproj.ArgoCD) string {
 	return scopes
 }
 
-// getResourceHealthChecks loads health customizations to `resource.customizations.health` from argocd-cm ConfigMap
+// getResourceHealthChecks loads health customizations to `resource.customizations.health` from argocd-cm ConfigMap,
+// the failed ones excepted.
 func getResourceHealthChecks(cr *argoproj.ArgoCD) map[string]string {
 	healthCheck := make(map[string]string)
 	if cr.Spec.ResourceHealthChecks != nil {
 		resourceHealthChecks := cr.Spec.ResourceHealthChecks
 		for _, healthCustomization := range resourceHealthChecks {
+			if hasResourceCustomizationFailure(cr, argoproj.ResourceCustomizationTypeHealthCheck, healthCustomization.Group, healthCustomization.Kind) {
+				continue
+			}
 			if healthCustomization.Group != "" {
 				healthCustomization.Group += "_"
 			}
@@ -196,12 +190,16 @@ func getResourceIgnoreDifferences(cr *argoproj.ArgoCD) (map[string]string, error
 	return ignoreDiff, nil
 }
 
-// getResourceActions loads custom actions to `resource.customizations.actions` from argocd-cm ConfigMap
+// getResourceActions loads custom actions to `resource.customizations.actions` from argocd-cm ConfigMap, the failed
+// ones excepted.
 func getResourceActions(cr *argoproj.ArgoCD) map[string]string {
 	action := make(map[string]string)
 	if cr.Spec.ResourceActions != nil {
 		resourceAction := cr.Spec.ResourceActions
 		for _, actionCustomization := range resourceAction {
+			if hasResourceCustomizationFailure(cr, argoproj.ResourceCustomizationTypeAction, actionCustomization.Group, actionCustomization.Kind) {
+				continue
+			}
 			if actionCustomization.Group != "" {
 				actionCustomization.Group += "_"
 			}
@@ -213,24 +211,6 @@ func getResourceActions(cr *argoproj.ArgoCD) map[string]string {
 	return action
 }
 
-// getResourceExclusions will return the resource exclusions for the given ArgoCD.
-func getResourceExclusions(cr *argoproj.ArgoCD) string {
-	re := common.ArgoCDDefaultResourceExclusions
-	if cr.Spec.ResourceExclusions != "" {
-		re = cr.Spec.ResourceExclusions
-	}
-	return re
-}
-
-// getResourceInclusions will return the resource inclusions for the given ArgoCD.
-func getResourceInclusions(cr *argoproj.ArgoCD) string {
-	re := common.ArgoCDDefaultResourceInclusions
-	if cr.Spec.ResourceInclusions != "" {
-		re = cr.Spec.ResourceInclusions
-	}
-	return re
-}
-
 // getResourceTrackingMethod will return the resource tracking method for the given ArgoCD.
 func getResourceTrackingMethod(cr *argoproj.ArgoCD) string {
 	rtm := argoproj.ParseResourceTrackingMethod(cr.Spec.ResourceTrackingMethod)
@@ -244,22 +224,23 @@ func getResourceTrackingMethod(cr *argoproj.ArgoCD) string {
 	return rtm.String()
 }
 
-// getInitialRepositories will return the initial repositories for the given ArgoCD.
-func getInitialRepositories(cr *argoproj.ArgoCD) string {
-	repos := common.ArgoCDDefaultRepositories
-	if len(cr.Spec.InitialRepositories) > 0 {
-		repos = cr.Spec.InitialRepositories
+// getInitialRepositories will return the initial repositories for the given ArgoCD. The initial repositories
+// migrated to repository Secrets are not repeated in argocd-cm, the ones that are not, such as the invalid ones, are.
+func (r *ReconcileArgoCD) getInitialRepositories(cr *argoproj.ArgoCD) string {
+	if len(cr.Spec.InitialRepositories) == 0 {
+		return common.ArgoCDDefaultRepositories
 	}
-	return repos
+	return r.getUnmigratedRepositories(cr, cr.Spec.InitialRepositories, repositorySecretType)
 }
 
-// getRepositoryCredentials will return the repository credentials for the given ArgoCD.
-func getRepositoryCredentials(cr *argoproj.ArgoCD) string {
-	repos := common.ArgoCDDefaultRepositoryCredentials
-	if len(cr.Spec.RepositoryCredentials) > 0 {
-		repos = cr.Spec.RepositoryCredentials
+// getRepositoryCredentials will return the repository credentials for the given ArgoCD. The repository credentials
+// migrated to repository credential Secrets are not repeated in argocd-cm, the ones that are not, such as the invalid
+// ones, are.
+func (r *ReconcileArgoCD) getRepositoryCredentials(cr *argoproj.ArgoCD) string {
+	if len(cr.Spec.RepositoryCredentials) == 0 {
+		return common.ArgoCDDefaultRepositoryCredentials
 	}
-	return repos
+	return r.getUnmigratedRepositories(cr, cr.Spec.RepositoryCredentials, repositoryCredentialsSecretType)
 }
 
 // getSSHKnownHosts will return the SSH Known Hosts data for the given ArgoCD.
@@ -336,6 +317,10 @@ func (r *ReconcileArgoCD) reconcileConfigMaps(cr *argoproj.ArgoCD, useTLSForRedi
 		return err
 	}
 
+	if err := r.reconcileCmpConfigMap(cr); err != nil {
+		return err
+	}
+
 	return r.reconcileGPGKeysConfigMap(cr)
 }
 
@@ -343,9 +328,6 @@ func (r *ReconcileArgoCD) reconcileConfigMaps(cr *argoproj.ArgoCD, useTLSForRedi
 // This ConfigMap holds the CA Certificate data for client use.
 func (r *ReconcileArgoCD) reconcileCAConfigMap(cr *argoproj.ArgoCD) error {
 	cm := newConfigMapWithName(getCAConfigMapName(cr), cr)
-	if argoutil.IsObjectFound(r.Client, cr.Namespace, cm.Name, cm) {
-		return nil // ConfigMap found, do nothing
-	}
 
 	caSecret := argoutil.NewSecretWithSuffix(cr, common.ArgoCDCASuffix)
 	if !argoutil.IsObjectFound(r.Client, cr.Namespace, caSecret.Name, caSecret) {
@@ -360,11 +342,16 @@ func (r *ReconcileArgoCD) reconcileCAConfigMap(cr *argoproj.ArgoCD) error {
 	if err := controllerutil.SetControllerReference(cr, cm, r.Scheme); err != nil {
 		return err
 	}
-	return r.Client.Create(context.TODO(), cm)
+	return argoutil.ApplyResource(r.Client, cm)
 }
 
 // reconcileConfiguration will ensure that the main ConfigMap for ArgoCD is present.
 func (r *ReconcileArgoCD) reconcileArgoConfigMap(cr *argoproj.ArgoCD) error {
+	// the failed resource customizations are reported before they are left out of the ConfigMap
+	if err := r.reconcileStatusResourceCustomizations(cr); err != nil {
+		return err
+	}
+
 	cm := newConfigMapWithName(common.ArgoCDConfigMapName, cr)
 
 	cm.Data = make(map[string]string)
@@ -406,26 +393,36 @@ func (r *ReconcileArgoCD) reconcileArgoConfigMap(cr *argoproj.ArgoCD) error {
 		}
 	}
 
-	cm.Data[common.ArgoCDKeyResourceExclusions] = getResourceExclusions(cr)
-	cm.Data[common.ArgoCDKeyResourceInclusions] = getResourceInclusions(cr)
+	existingCM := &corev1.ConfigMap{}
+	existingFound := argoutil.IsObjectFound(r.Client, cr.Namespace, cm.Name, existingCM)
+
+	// the invalid resource filters are reported, the inclusions never being widened because of them
+	var invalidFilters []string
+	exclusions, errs := getResourceExclusions(cr)
+	for _, err := range errs {
+		invalidFilters = append(invalidFilters, fmt.Sprintf("resource exclusions: %v", err))
+	}
+	cm.Data[common.ArgoCDKeyResourceExclusions] = exclusions
+	inclusions, errs := getResourceInclusions(cr, existingCM.Data[common.ArgoCDKeyResourceInclusions])
+	for _, err := range errs {
+		invalidFilters = append(invalidFilters, fmt.Sprintf("resource inclusions: %v", err))
+	}
+	cm.Data[common.ArgoCDKeyResourceInclusions] = inclusions
+	if err := r.reconcileStatusInvalidResourceFilters(cr, invalidFilters); err != nil {
+		return err
+	}
 	cm.Data[common.ArgoCDKeyResourceTrackingMethod] = getResourceTrackingMethod(cr)
-	cm.Data[common.ArgoCDKeyRepositories] = getInitialRepositories(cr)
-	cm.Data[common.ArgoCDKeyRepositoryCredentials] = getRepositoryCredentials(cr)
+	cm.Data[common.ArgoCDKeyRepositories] = r.getInitialRepositories(cr)
+	cm.Data[common.ArgoCDKeyRepositoryCredentials] = r.getRepositoryCredentials(cr)
 	cm.Data[common.ArgoCDKeyStatusBadgeEnabled] = fmt.Sprint(cr.Spec.StatusBadgeEnabled)
 	cm.Data[common.ArgoCDKeyServerURL] = r.getArgoServerURI(cr)
 	cm.Data[common.ArgoCDKeyUsersAnonymousEnabled] = fmt.Sprint(cr.Spec.UsersAnonymousEnabled)
 
 	// create dex config if dex is enabled through `.spec.sso`
 	if UseDex(cr) {
-		dexConfig := getDexConfig(cr)
-
-		// Append the default OpenShift dex config if the openShiftOAuth is requested through `.spec.sso.dex`.
-		if cr.Spec.SSO != nil && cr.Spec.SSO.Dex != nil && cr.Spec.SSO.Dex.OpenShiftOAuth {
-			cfg, err := r.getOpenShiftDexConfig(cr)
-			if err != nil {
-				return err
-			}
-			dexConfig = cfg
+		dexConfig, err := r.getDesiredDexConfig(cr)
+		if err != nil {
+			return err
 		}
 		cm.Data[common.ArgoCDKeyDexConfig] = dexConfig
 	}
@@ -449,29 +446,34 @@ func (r *ReconcileArgoCD) reconcileArgoConfigMap(cr *argoproj.ArgoCD) error {
 		return err
 	}
 
-	existingCM := &corev1.ConfigMap{}
-	if argoutil.IsObjectFound(r.Client, cr.Namespace, cm.Name, existingCM) {
+	// retain oidc.config during reconcilliation when keycloak is configured
+	if cr.Spec.SSO != nil && cr.Spec.SSO.Provider.ToLower() == argoproj.SSOProviderTypeKeycloak && existingFound {
+		cm.Data[common.ArgoCDKeyOIDCConfig] = existingCM.Data[common.ArgoCDKeyOIDCConfig]
+	}
 
-		// reconcile dex configuration if dex is enabled `.spec.sso.dex.provider` or there is
-		// existing dex configuration
-		if UseDex(cr) {
-			if err := r.reconcileDexConfiguration(existingCM, cr); err != nil {
-				return err
-			}
-			cm.Data[common.ArgoCDKeyDexConfig] = existingCM.Data[common.ArgoCDKeyDexConfig]
-		} else if cr.Spec.SSO != nil && cr.Spec.SSO.Provider.ToLower() == argoproj.SSOProviderTypeKeycloak {
-			// retain oidc.config during reconcilliation when keycloak is configured
-			cm.Data[common.ArgoCDKeyOIDCConfig] = existingCM.Data[common.ArgoCDKeyOIDCConfig]
-		}
+	desired := cm.DeepCopy()
+	if err := argoutil.ApplyResource(r.Client, cm); err != nil {
+		return err
+	}
 
-		if !reflect.DeepEqual(cm.Data, existingCM.Data) {
-			existingCM.Data = cm.Data
-			return r.Client.Update(context.TODO(), existingCM)
+	// The operator owns the whole data of argocd-cm, extra entries are set through `.spec.extraConfig`: the entries
+	// set by others, which a server-side apply leaves untouched, are removed as well.
+	patched := cm.DeepCopy()
+	for k := range cm.Data {
+		if _, ok := desired.Data[k]; !ok {
+			delete(patched.Data, k)
 		}
-		return nil // Do nothing as there is no change in the configmap.
 	}
-	return r.Client.Create(context.TODO(), cm)
-
+	if len(patched.Data) == len(cm.Data) {
+		return nil
+	}
+	if err := r.Client.Patch(context.TODO(), patched, client.MergeFrom(cm)); err != nil {
+		return err
+	}
+	if inventory := inventoryOf(r.Client); inventory != nil {
+		return inventory.reportDriftCorrection("ConfigMap", cm, patched)
+	}
+	return nil
 }
 
 // reconcileGrafanaConfiguration will ensure that the Grafana configuration ConfigMap is present.
@@ -496,47 +498,39 @@ func (r *ReconcileArgoCD) reconcileGrafanaDashboards(cr *argoproj.ArgoCD) error
 	return nil
 }
 
-// reconcileRBAC will ensure that the ArgoCD RBAC ConfigMap is present.
+// reconcileRBAC will ensure that the ArgoCD RBAC ConfigMap is present and syncronized with the given ArgoCD. The
+// settings left unset in the ArgoCD keep the value of the existing ConfigMap, such as the scopes set for Keycloak,
+// and default to the values of a new ConfigMap.
 func (r *ReconcileArgoCD) reconcileRBAC(cr *argoproj.ArgoCD) error {
 	cm := newConfigMapWithName(common.ArgoCDRBACConfigMapName, cr)
-	if argoutil.IsObjectFound(r.Client, cr.Namespace, cm.Name, cm) {
-		return r.reconcileRBACConfigMap(cm, cr)
-	}
-	return r.createRBACConfigMap(cm, cr)
-}
-
-// reconcileRBACConfigMap will ensure that the RBAC ConfigMap is syncronized with the given ArgoCD.
-func (r *ReconcileArgoCD) reconcileRBACConfigMap(cm *corev1.ConfigMap, cr *argoproj.ArgoCD) error {
-	changed := false
-	// Policy CSV
-	if cr.Spec.RBAC.Policy != nil && cm.Data[common.ArgoCDKeyRBACPolicyCSV] != *cr.Spec.RBAC.Policy {
-		cm.Data[common.ArgoCDKeyRBACPolicyCSV] = *cr.Spec.RBAC.Policy
-		changed = true
-	}
-
-	// Default Policy
-	if cr.Spec.RBAC.DefaultPolicy != nil && cm.Data[common.ArgoCDKeyRBACPolicyDefault] != *cr.Spec.RBAC.DefaultPolicy {
-		cm.Data[common.ArgoCDKeyRBACPolicyDefault] = *cr.Spec.RBAC.DefaultPolicy
-		changed = true
+	cm.Data = map[string]string{
+		common.ArgoCDKeyRBACPolicyCSV:     getRBACPolicy(cr),
+		common.ArgoCDKeyRBACPolicyDefault: getRBACDefaultPolicy(cr),
+		common.ArgoCDKeyRBACScopes:        getRBACScopes(cr),
 	}
-
-	// Default Policy Matcher Mode
-	if cr.Spec.RBAC.PolicyMatcherMode != nil && cm.Data[common.ArgoCDPolicyMatcherMode] != *cr.Spec.RBAC.PolicyMatcherMode {
+	if cr.Spec.RBAC.PolicyMatcherMode != nil {
 		cm.Data[common.ArgoCDPolicyMatcherMode] = *cr.Spec.RBAC.PolicyMatcherMode
-		changed = true
 	}
 
-	// Scopes
-	if cr.Spec.RBAC.Scopes != nil && cm.Data[common.ArgoCDKeyRBACScopes] != *cr.Spec.RBAC.Scopes {
-		cm.Data[common.ArgoCDKeyRBACScopes] = *cr.Spec.RBAC.Scopes
-		changed = true
+	existing := &corev1.ConfigMap{}
+	if argoutil.IsObjectFound(r.Client, cr.Namespace, cm.Name, existing) {
+		settings := map[string]*string{
+			common.ArgoCDKeyRBACPolicyCSV:     cr.Spec.RBAC.Policy,
+			common.ArgoCDKeyRBACPolicyDefault: cr.Spec.RBAC.DefaultPolicy,
+			common.ArgoCDPolicyMatcherMode:    cr.Spec.RBAC.PolicyMatcherMode,
+			common.ArgoCDKeyRBACScopes:        cr.Spec.RBAC.Scopes,
+		}
+		for key, setting := range settings {
+			if value, ok := existing.Data[key]; ok && setting == nil {
+				cm.Data[key] = value
+			}
+		}
 	}
 
-	if changed {
-		// TODO: Reload server (and dex?) if RBAC settings change?
-		return r.Client.Update(context.TODO(), cm)
+	if err := controllerutil.SetControllerReference(cr, cm, r.Scheme); err != nil {
+		return err
 	}
-	return nil // ConfigMap exists and nothing to do, move along...
+	return argoutil.ApplyResource(r.Client, cm)
 }
 
 // reconcileRedisConfiguration will ensure that all of the Redis ConfigMaps are present for the given ArgoCD.
@@ -553,16 +547,9 @@ func (r *ReconcileArgoCD) reconcileRedisConfiguration(cr *argoproj.ArgoCD, useTL
 // reconcileRedisHAConfigMap will ensure that the Redis HA Health ConfigMap is present for the given ArgoCD.
 func (r *ReconcileArgoCD) reconcileRedisHAHealthConfigMap(cr *argoproj.ArgoCD, useTLSForRedis bool) error {
 	cm := newConfigMapWithName(common.ArgoCDRedisHAHealthConfigMapName, cr)
-	if argoutil.IsObjectFound(r.Client, cr.Namespace, cm.Name, cm) {
-		if !cr.Spec.HA.Enabled {
-			// ConfigMap exists but HA enabled flag has been set to false, delete the ConfigMap
-			return r.Client.Delete(context.TODO(), cm)
-		}
-		return nil // ConfigMap found with nothing changed, move along...
-	}
-
 	if !cr.Spec.HA.Enabled {
-		return nil // HA not enabled, do nothing.
+		// HA enabled flag has been set to false, delete the ConfigMap if any
+		return r.deleteConfigMapIfFound(cm, cr)
 	}
 
 	cm.Data = map[string]string{
@@ -574,54 +561,83 @@ func (r *ReconcileArgoCD) reconcileRedisHAHealthConfigMap(cr *argoproj.ArgoCD, u
 	if err := controllerutil.SetControllerReference(cr, cm, r.Scheme); err != nil {
 		return err
 	}
-	return r.Client.Create(context.TODO(), cm)
+	return argoutil.ApplyResource(r.Client, cm)
 }
 
 // reconcileRedisHAConfigMap will ensure that the Redis HA ConfigMap is present for the given ArgoCD.
 func (r *ReconcileArgoCD) reconcileRedisHAConfigMap(cr *argoproj.ArgoCD, useTLSForRedis bool) error {
 	cm := newConfigMapWithName(common.ArgoCDRedisHAConfigMapName, cr)
-	if argoutil.IsObjectFound(r.Client, cr.Namespace, cm.Name, cm) {
-		if !cr.Spec.HA.Enabled {
-			// ConfigMap exists but HA enabled flag has been set to false, delete the ConfigMap
-			return r.Client.Delete(context.TODO(), cm)
-		}
-		return nil // ConfigMap found with nothing changed, move along...
+	if !cr.Spec.HA.Enabled {
+		// HA enabled flag has been set to false, delete the ConfigMap if any
+		return r.deleteConfigMapIfFound(cm, cr)
 	}
 
-	if !cr.Spec.HA.Enabled {
-		return nil // HA not enabled, do nothing.
+	// When the topology (replicas, quorum or persistence) changes, the Redis HA StatefulSet and HAProxy
+	// Deployment pick up the new configuration through the checksum annotation.
+	cm.Data = getRedisHAConfigMapData(cr, useTLSForRedis)
+
+	if err := controllerutil.SetControllerReference(cr, cm, r.Scheme); err != nil {
+		return err
 	}
+	return argoutil.ApplyResource(r.Client, cm)
+}
 
-	cm.Data = map[string]string{
+// deleteConfigMapIfFound will delete the given ConfigMap if it exists and is controlled by the given ArgoCD.
+func (r *ReconcileArgoCD) deleteConfigMapIfFound(cm *corev1.ConfigMap, cr *argoproj.ArgoCD) error {
+	if !argoutil.IsObjectFound(r.Client, cm.Namespace, cm.Name, cm) {
+		return nil
+	}
+	if !metav1.IsControlledBy(cm, cr) {
+		// the ConfigMap belongs to another ArgoCD or to the user, leave it alone
+		return nil
+	}
+	return r.Client.Delete(context.TODO(), cm)
+}
+
+// getRedisHAConfigMapData will return the rendered data of the Redis HA ConfigMap for the given ArgoCD.
+func getRedisHAConfigMapData(cr *argoproj.ArgoCD, useTLSForRedis bool) map[string]string {
+	return map[string]string{
 		"haproxy.cfg":     getRedisHAProxyConfig(cr, useTLSForRedis),
 		"haproxy_init.sh": getRedisHAProxyScript(cr),
 		"init.sh":         getRedisInitScript(cr, useTLSForRedis),
-		"redis.conf":      getRedisConf(useTLSForRedis),
+		"redis.conf":      getRedisConf(cr, useTLSForRedis),
 		"sentinel.conf":   getRedisSentinelConf(useTLSForRedis),
 	}
+}
 
-	if err := controllerutil.SetControllerReference(cr, cm, r.Scheme); err != nil {
-		return err
+// getRedisHAConfigChecksum will return the SHA256 checksum of the Redis HA ConfigMap data for the given ArgoCD.
+func getRedisHAConfigChecksum(cr *argoproj.ArgoCD, useTLSForRedis bool) string {
+	return getConfigMapDataChecksum(getRedisHAConfigMapData(cr, useTLSForRedis))
+}
+
+// getConfigMapDataChecksum will return the SHA256 checksum of the given ConfigMap data.
+func getConfigMapDataChecksum(data map[string]string) string {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
 	}
-	return r.Client.Create(context.TODO(), cm)
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, k := range keys {
+		h.Write([]byte(k))
+		h.Write([]byte(data[k]))
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
 }
 
 func (r *ReconcileArgoCD) recreateRedisHAConfigMap(cr *argoproj.ArgoCD, useTLSForRedis bool) error {
 	cm := newConfigMapWithName(common.ArgoCDRedisHAConfigMapName, cr)
-	if argoutil.IsObjectFound(r.Client, cr.Namespace, cm.Name, cm) {
-		if err := r.Client.Delete(context.TODO(), cm); err != nil {
-			return err
-		}
+	if err := r.deleteConfigMapIfFound(cm, cr); err != nil {
+		return err
 	}
 	return r.reconcileRedisHAConfigMap(cr, useTLSForRedis)
 }
 
 func (r *ReconcileArgoCD) recreateRedisHAHealthConfigMap(cr *argoproj.ArgoCD, useTLSForRedis bool) error {
 	cm := newConfigMapWithName(common.ArgoCDRedisHAHealthConfigMapName, cr)
-	if argoutil.IsObjectFound(r.Client, cr.Namespace, cm.Name, cm) {
-		if err := r.Client.Delete(context.TODO(), cm); err != nil {
-			return err
-		}
+	if err := r.deleteConfigMapIfFound(cm, cr); err != nil {
+		return err
 	}
 	return r.reconcileRedisHAHealthConfigMap(cr, useTLSForRedis)
 }
@@ -669,3 +685,64 @@ func (r *ReconcileArgoCD) reconcileGPGKeysConfigMap(cr *argoproj.ArgoCD) error {
 	}
 	return r.Client.Create(context.TODO(), cm)
 }
+
+// reconcileCmpConfigMap will ensure that the ConfigMap holding the plugin.yaml of every Config Management Plugin
+// declared in the repo server spec is present, and removed once no plugin is declared.
+func (r *ReconcileArgoCD) reconcileCmpConfigMap(cr *argoproj.ArgoCD) error {
+	cm := newConfigMapWithName(getCmpConfigMapName(cr), cr)
+	plugins := getRepoPlugins(cr)
+
+	existing := &corev1.ConfigMap{}
+	if argoutil.IsObjectFound(r.Client, cr.Namespace, cm.Name, existing) && !metav1.IsControlledBy(existing, cr) {
+		// The ConfigMap belongs to another ArgoCD or to the user, leave it alone.
+		log.Info(fmt.Sprintf("config management plugin configmap %s is not controlled by ArgoCD %s, skipping", cm.Name, cr.Name))
+		return nil
+	}
+
+	if len(plugins) == 0 {
+		if argoutil.IsObjectFound(r.Client, cr.Namespace, cm.Name, cm) {
+			log.Info(fmt.Sprintf("Deleting config management plugin configmap %s", cm.Name))
+			return r.Client.Delete(context.TODO(), cm)
+		}
+		return nil // No plugin declared, do nothing.
+	}
+
+	// The repo server deployment picks up the new configuration through the checksum annotation.
+	data, err := getCmpConfigMapData(plugins)
+	if err != nil {
+		return err
+	}
+	cm.Data = data
+
+	if err := controllerutil.SetControllerReference(cr, cm, r.Scheme); err != nil {
+		return err
+	}
+	return argoutil.ApplyResource(r.Client, cm)
+}
+
+// getCmpConfigMapData will return the plugin.yaml content of the given Config Management Plugins, keyed by
+// the name of the file mounted into each plugin sidecar.
+func getCmpConfigMapData(plugins []argoproj.ArgoCDRepoPluginSpec) (map[string]string, error) {
+	data := make(map[string]string, len(plugins))
+	for _, plugin := range plugins {
+		config := map[string]interface{}{
+			"apiVersion": "argoproj.io/v1alpha1",
+			"kind":       "ConfigManagementPlugin",
+			"metadata": map[string]string{
+				"name": plugin.Name,
+			},
+			"spec": plugin.Spec,
+		}
+		out, err := k8syaml.Marshal(config)
+		if err != nil {
+			return nil, fmt.Errorf("unable to render configuration of plugin %s: %w", plugin.Name, err)
+		}
+		data[getCmpConfigMapKey(plugin)] = string(out)
+	}
+	return data, nil
+}
+
+// getCmpConfigMapKey will return the key of the plugin.yaml of the given Config Management Plugin.
+func getCmpConfigMapKey(plugin argoproj.ArgoCDRepoPluginSpec) string {
+	return fmt.Sprintf("%s.yaml", plugin.Name)
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v2"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -306,6 +307,32 @@ func TestReconcileArgoCDCM_withRepoCredentials(t *testing.T) {
 	}, cm)
 	assert.NoError(t, err)
 
+	// the repository credentials referencing a missing Secret are left in argocd-cm
+	if got := cm.Data[common.ArgoCDKeyRepositoryCredentials]; got != a.Spec.RepositoryCredentials {
+		t.Fatalf("reconcileArgoConfigMap failed: got %s, want %s", got, a.Spec.RepositoryCredentials)
+	}
+
+	// the ones migrated to repository credential Secrets are not
+	assert.NoError(t, r.Client.Create(context.TODO(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: testNamespace},
+		Data:       map[string][]byte{"username": []byte("git"), "password": []byte("s3cr3t")},
+	}))
+	assert.NoError(t, r.reconcileRepositorySecrets(a))
+	assert.NoError(t, r.reconcileArgoConfigMap(a))
+	assert.NoError(t, r.Client.Get(context.TODO(), client.ObjectKeyFromObject(cm), cm))
+	assert.Empty(t, cm.Data[common.ArgoCDKeyRepositoryCredentials])
+
+	// unless they cannot be parsed, Argo CD reading them from argocd-cm then
+	a.Spec.RepositoryCredentials = "url: https://github.com/test/gitops.git"
+	err = r.reconcileArgoConfigMap(a)
+	assert.NoError(t, err)
+
+	err = r.Client.Get(context.TODO(), types.NamespacedName{
+		Name:      common.ArgoCDConfigMapName,
+		Namespace: testNamespace,
+	}, cm)
+	assert.NoError(t, err)
+
 	if got := cm.Data[common.ArgoCDKeyRepositoryCredentials]; got != a.Spec.RepositoryCredentials {
 		t.Fatalf("reconcileArgoConfigMap failed: got %s, want %s", got, a.Spec.RepositoryCredentials)
 	}
@@ -594,7 +621,7 @@ func TestReconcileArgoCD_reconcileArgoConfigMap_dexConfigDeletedwhenDexDisabled(
 				test.updateCrFunc(test.argoCD)
 			}
 
-			err = r.reconcileDexConfiguration(cm, test.argoCD)
+			err = r.reconcileArgoConfigMap(test.argoCD)
 			assert.NoError(t, err)
 
 			err = r.Client.Get(context.TODO(), types.NamespacedName{
@@ -827,34 +854,34 @@ managedfieldsmanagers:
 		{
 			Group: "healthFoo",
 			Kind:  "healthFoo",
-			Check: "healthFoo",
+			Check: "return healthFoo",
 		},
 		{
 			Group: "healthBar",
 			Kind:  "healthBar",
-			Check: "healthBar",
+			Check: "return healthBar",
 		},
 		{
 			Group: "",
 			Kind:  "healthFooBar",
-			Check: "healthFooBar",
+			Check: "return healthFooBar",
 		},
 	}
 	actions := []argoproj.ResourceAction{
 		{
 			Group:  "actionsFoo",
 			Kind:   "actionsFoo",
-			Action: "actionsFoo",
+			Action: "discovery.lua: return actionsFoo",
 		},
 		{
 			Group:  "actionsBar",
 			Kind:   "actionsBar",
-			Action: "actionsBar",
+			Action: "discovery.lua: return actionsBar",
 		},
 		{
 			Group:  "",
 			Kind:   "actionsFooBar",
-			Action: "actionsFooBar",
+			Action: "discovery.lua: return actionsFooBar",
 		},
 	}
 	ignoreDifferences := argoproj.ResourceIgnoreDifference{
@@ -909,12 +936,12 @@ managedfieldsmanagers:
 	assert.NoError(t, err)
 
 	desiredCM := make(map[string]string)
-	desiredCM["resource.customizations.health.healthFoo_healthFoo"] = "healthFoo"
-	desiredCM["resource.customizations.health.healthBar_healthBar"] = "healthBar"
-	desiredCM["resource.customizations.health.healthFooBar"] = "healthFooBar"
-	desiredCM["resource.customizations.actions.actionsFoo_actionsFoo"] = "actionsFoo"
-	desiredCM["resource.customizations.actions.actionsBar_actionsBar"] = "actionsBar"
-	desiredCM["resource.customizations.actions.actionsFooBar"] = "actionsFooBar"
+	desiredCM["resource.customizations.health.healthFoo_healthFoo"] = "return healthFoo"
+	desiredCM["resource.customizations.health.healthBar_healthBar"] = "return healthBar"
+	desiredCM["resource.customizations.health.healthFooBar"] = "return healthFooBar"
+	desiredCM["resource.customizations.actions.actionsFoo_actionsFoo"] = "discovery.lua: return actionsFoo"
+	desiredCM["resource.customizations.actions.actionsBar_actionsBar"] = "discovery.lua: return actionsBar"
+	desiredCM["resource.customizations.actions.actionsFooBar"] = "discovery.lua: return actionsFooBar"
 	desiredCM["resource.customizations.ignoreDifferences.all"] = desiredIgnoreDifferenceCustomization
 	desiredCM["resource.customizations.ignoreDifferences.ignoreDiffBar_ignoreDiffBar"] = desiredIgnoreDifferenceCustomization
 	desiredCM["resource.customizations.ignoreDifferences.ignoreDiffFoo"] = desiredIgnoreDifferenceCustomization
@@ -926,6 +953,67 @@ managedfieldsmanagers:
 	}
 }
 
+func TestReconcileArgoCD_reconcileArgoConfigMap_withFailedResourceCustomizations(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.ResourceHealthChecks = []argoproj.ResourceHealthCheck{
+			{Group: "apps", Kind: "Deployment", Check: `return {status = "Healthy"}`},
+			{Group: "argoproj.io", Kind: "Rollout", Check: "return {status = "},
+		}
+		a.Spec.ResourceActions = []argoproj.ResourceAction{
+			{Kind: "ConfigMap", Action: "definitions:\n- name: restart\n  action.lua: return obj", Tests: []argoproj.ResourceActionTest{
+				{
+					Name:     "restart",
+					Action:   "restart",
+					Object:   runtime.RawExtension{Raw: []byte(`{"data":{"key":"value"}}`)},
+					Expected: runtime.RawExtension{Raw: []byte(`{"data":{"key":"restarted"}}`)},
+				},
+			}},
+		}
+	})
+
+	resObjs := []client.Object{a}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileArgoConfigMap(a))
+
+	// the failures are reported, and left out of argocd-cm
+	cr := &argoproj.ArgoCD{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: a.Name, Namespace: a.Namespace}, cr))
+	assert.Len(t, cr.Status.ResourceCustomizationFailures, 2)
+	assert.Equal(t, argoproj.ResourceCustomizationTypeHealthCheck, cr.Status.ResourceCustomizationFailures[0].Type)
+	assert.Equal(t, "Rollout", cr.Status.ResourceCustomizationFailures[0].Kind)
+	assert.Equal(t, argoproj.ResourceCustomizationTypeAction, cr.Status.ResourceCustomizationFailures[1].Type)
+	assert.Contains(t, cr.Status.ResourceCustomizationFailures[1].Message, "test restart failed")
+
+	cm := &corev1.ConfigMap{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{
+		Name:      common.ArgoCDConfigMapName,
+		Namespace: testNamespace,
+	}, cm))
+	assert.Equal(t, `return {status = "Healthy"}`, cm.Data["resource.customizations.health.apps_Deployment"])
+	assert.NotContains(t, cm.Data, "resource.customizations.health.argoproj.io_Rollout")
+	assert.NotContains(t, cm.Data, "resource.customizations.actions.ConfigMap")
+
+	// once fixed, the customizations are written to argocd-cm
+	cr.Spec.ResourceHealthChecks[1].Check = `return {status = "Progressing"}`
+	cr.Spec.ResourceActions[0].Tests[0].Expected = cr.Spec.ResourceActions[0].Tests[0].Object
+	assert.NoError(t, r.Client.Update(context.TODO(), cr))
+	assert.NoError(t, r.reconcileArgoConfigMap(cr))
+	assert.Empty(t, cr.Status.ResourceCustomizationFailures)
+
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{
+		Name:      common.ArgoCDConfigMapName,
+		Namespace: testNamespace,
+	}, cm))
+	assert.Equal(t, `return {status = "Progressing"}`, cm.Data["resource.customizations.health.argoproj.io_Rollout"])
+	assert.Equal(t, "definitions:\n- name: restart\n  action.lua: return obj", cm.Data["resource.customizations.actions.ConfigMap"])
+}
+
 func TestReconcileArgoCD_reconcileArgoConfigMap_withExtraConfig(t *testing.T) {
 	a := makeTestArgoCD()
 
@@ -1040,3 +1128,156 @@ func Test_reconcileRBAC(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, cm.Data["policy.matchMode"], matcherMode)
 }
+
+func Test_reconcileRBAC_keepsExistingSettings(t *testing.T) {
+	a := makeTestArgoCD()
+	existing := newConfigMapWithName(common.ArgoCDRBACConfigMapName, a)
+	existing.Data = map[string]string{
+		common.ArgoCDKeyRBACPolicyCSV: "g, admins, role:admin",
+		common.ArgoCDKeyRBACScopes:    "[groups,email]",
+	}
+
+	resObjs := []client.Object{a, existing}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	// the settings left unset in the ArgoCD keep their existing value
+	assert.NoError(t, r.reconcileRBAC(a))
+
+	cm := &corev1.ConfigMap{}
+	assert.NoError(t, r.Client.Get(context.TODO(), client.ObjectKeyFromObject(existing), cm))
+	assert.Equal(t, map[string]string{
+		common.ArgoCDKeyRBACPolicyCSV:     "g, admins, role:admin",
+		common.ArgoCDKeyRBACPolicyDefault: common.ArgoCDDefaultRBACDefaultPolicy,
+		common.ArgoCDKeyRBACScopes:        "[groups,email]",
+	}, cm.Data)
+
+	// the ones set in the ArgoCD are enforced
+	scopes := "[groups]"
+	a.Spec.RBAC.Scopes = &scopes
+	assert.NoError(t, r.reconcileRBAC(a))
+
+	assert.NoError(t, r.Client.Get(context.TODO(), client.ObjectKeyFromObject(existing), cm))
+	assert.Equal(t, "[groups]", cm.Data[common.ArgoCDKeyRBACScopes])
+	assert.Equal(t, "g, admins, role:admin", cm.Data[common.ArgoCDKeyRBACPolicyCSV])
+}
+
+func TestReconcileArgoCD_reconcileCmpConfigMap(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.Repo.Plugins = []argoproj.ArgoCDRepoPluginSpec{{
+			Name:  "kustomize-envsubst",
+			Image: "quay.io/example/envsubst:latest",
+			Spec: argoproj.ArgoCDPluginSpec{
+				Version: "v1.0",
+				Generate: argoproj.ArgoCDPluginCommand{
+					Command: []string{"sh", "-c"},
+					Args:    []string{"kustomize build . | envsubst"},
+				},
+				Discover: &argoproj.ArgoCDPluginDiscover{FileName: "kustomization.yaml"},
+			},
+		}}
+	})
+
+	resObjs := []client.Object{a}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileCmpConfigMap(a))
+
+	cm := &corev1.ConfigMap{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: getCmpConfigMapName(a), Namespace: a.Namespace}, cm))
+	assert.Equal(t, `apiVersion: argoproj.io/v1alpha1
+kind: ConfigManagementPlugin
+metadata:
+  name: kustomize-envsubst
+spec:
+  discover:
+    fileName: kustomization.yaml
+  generate:
+    args:
+    - kustomize build . | envsubst
+    command:
+    - sh
+    - -c
+  version: v1.0
+`, cm.Data["kustomize-envsubst.yaml"])
+
+	// Changes to the plugin spec are written to the ConfigMap
+	a.Spec.Repo.Plugins[0].Spec.Discover = nil
+	assert.NoError(t, r.reconcileCmpConfigMap(a))
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: getCmpConfigMapName(a), Namespace: a.Namespace}, cm))
+	assert.NotContains(t, cm.Data["kustomize-envsubst.yaml"], "discover")
+
+	// The ConfigMap is removed with the last plugin
+	a.Spec.Repo.Plugins = nil
+	assert.NoError(t, r.reconcileCmpConfigMap(a))
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: getCmpConfigMapName(a), Namespace: a.Namespace}, cm)
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestReconcileArgoCD_reconcileRedisHAConfigMap_notControlled(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD()
+	// a ConfigMap of the user, not controlled by the ArgoCD
+	userCM := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: common.ArgoCDRedisHAConfigMapName, Namespace: a.Namespace},
+	}
+
+	resObjs := []client.Object{a, userCM}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	// the ArgoCD without HA leaves the ConfigMaps it does not control alone
+	assert.NoError(t, r.reconcileRedisConfiguration(a, false))
+	cm := &corev1.ConfigMap{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: userCM.Name, Namespace: a.Namespace}, cm))
+
+	// the ConfigMaps are removed once HA is disabled on the instance controlling them
+	a.Spec.HA.Enabled = true
+	assert.NoError(t, r.Client.Delete(context.TODO(), cm))
+	assert.NoError(t, r.reconcileRedisConfiguration(a, false))
+	a.Spec.HA.Enabled = false
+	assert.NoError(t, r.reconcileRedisConfiguration(a, false))
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: common.ArgoCDRedisHAConfigMapName, Namespace: a.Namespace}, cm)
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestReconcileArgoCD_reconcileCmpConfigMap_notControlled(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.Repo.Plugins = []argoproj.ArgoCDRepoPluginSpec{{
+			Name:  "envsubst",
+			Image: "quay.io/example/envsubst:latest",
+		}}
+	})
+	// a ConfigMap of the user, not controlled by the ArgoCD
+	userCM := newConfigMapWithName(getCmpConfigMapName(a), a)
+	userCM.Data = map[string]string{"plugin.yaml": "user"}
+
+	resObjs := []client.Object{a, userCM}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	// the ConfigMap is neither updated nor deleted
+	assert.NoError(t, r.reconcileCmpConfigMap(a))
+	cm := &corev1.ConfigMap{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: userCM.Name, Namespace: a.Namespace}, cm))
+	assert.Equal(t, map[string]string{"plugin.yaml": "user"}, cm.Data)
+
+	a.Spec.Repo.Plugins = nil
+	assert.NoError(t, r.reconcileCmpConfigMap(a))
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: userCM.Name, Namespace: a.Namespace}, cm))
+}
@@ -12,6 +12,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -136,30 +137,23 @@ func (r *ReconcileArgoCD) namespaceResourceMapper(ctx context.Context, o client.
 	labels := o.GetLabels()
 	namespaceName := o.GetName()
 	if v, ok := labels[common.ArgoCDManagedByLabel]; ok {
-		if err := r.Client.List(context.TODO(), argocds, &client.ListOptions{Namespace: v}); err != nil {
-			return result
-		}
-		if len(argocds.Items) != 1 {
-			return result
-		}
-		argocd := argocds.Items[0]
-		namespacedName := client.ObjectKey{
-			Name:      argocd.Name,
-			Namespace: argocd.Namespace,
-		}
-		result = []reconcile.Request{
-			{NamespacedName: namespacedName},
-		}
+		// The label only names the namespace of the managing instances, reconcile each of them
+		result = r.getArgoCDRequestsInNamespace(ctx, v)
 	} else {
 		// If the namespace does not have the expected managed-by label,
 		// iterate through each ArgoCD instance to identify if the observed namespace
-		// matches any configured sourceNamespace pattern. If a match is found,
-		// generate a reconcile request for the instances.
+		// matches any configured sourceNamespace pattern or namespace selector, or was
+		// labeled by one of them. If a match is found, generate a reconcile request for the instances.
 		if err := r.Client.List(ctx, argocds, &client.ListOptions{}); err != nil {
 			return result
 		}
 		for _, argocd := range argocds.Items {
-			if glob.MatchStringInList(argocd.Spec.SourceNamespaces, namespaceName, false) {
+			argocd := argocd
+			if glob.MatchStringInList(argocd.Spec.SourceNamespaces, namespaceName, false) ||
+				namespaceSelectorMatches(argocd.Spec.ManagedNamespaceSelector, labels) ||
+				namespaceSelectorMatches(argocd.Spec.SourceNamespaceSelector, labels) ||
+				labels[common.ArgoCDManagedByClusterArgoCDLabel] == argocd.Namespace ||
+				o.GetAnnotations()[common.AnnotationManagedNamespaceSelector] == instanceKey(&argocd) {
 				namespacedName := client.ObjectKey{
 					Name:      argocd.Name,
 					Namespace: argocd.Namespace,
@@ -172,30 +166,28 @@ func (r *ReconcileArgoCD) namespaceResourceMapper(ctx context.Context, o client.
 	return result
 }
 
-// clusterSecretResourceMapper maps a watch event on a namespace, back to the
-// ArgoCD object that we want to reconcile.
+// clusterSecretResourceMapper maps a watch event on a cluster secret, back to the
+// ArgoCD objects that we want to reconcile.
 func (r *ReconcileArgoCD) clusterSecretResourceMapper(ctx context.Context, o client.Object) []reconcile.Request {
 	var result = []reconcile.Request{}
 
 	labels := o.GetLabels()
 	if v, ok := labels[common.ArgoCDSecretTypeLabel]; ok && v == "cluster" {
-		argocds := &argoproj.ArgoCDList{}
-		if err := r.Client.List(context.TODO(), argocds, &client.ListOptions{Namespace: o.GetNamespace()}); err != nil {
-			return result
-		}
-
-		if len(argocds.Items) != 1 {
-			return result
+		// Cluster secrets created by the operator are owned by their instance
+		if request, ok := getArgoCDOwnerRequest(o); ok {
+			return []reconcile.Request{request}
 		}
 
-		argocd := argocds.Items[0]
-		namespacedName := client.ObjectKey{
-			Name:      argocd.Name,
-			Namespace: argocd.Namespace,
-		}
-		result = []reconcile.Request{
-			{NamespacedName: namespacedName},
+		// Route secrets labeled with the name of an instance to that instance only
+		requests := r.getArgoCDRequestsInNamespace(ctx, o.GetNamespace())
+		if name, ok := labels[common.ArgoCDKeyManagedBy]; ok {
+			for _, request := range requests {
+				if request.Name == name {
+					return []reconcile.Request{request}
+				}
+			}
 		}
+		result = requests
 	}
 
 	return result
@@ -207,24 +199,43 @@ func (r *ReconcileArgoCD) applicationSetSCMTLSConfigMapMapper(ctx context.Contex
 	var result = []reconcile.Request{}
 
 	if o.GetName() == common.ArgoCDAppSetGitlabSCMTLSCertsConfigMapName {
-		argocds := &argoproj.ArgoCDList{}
-		if err := r.Client.List(context.TODO(), argocds, &client.ListOptions{Namespace: o.GetNamespace()}); err != nil {
-			return result
-		}
+		result = r.getArgoCDRequestsInNamespace(ctx, o.GetNamespace())
+	}
 
-		if len(argocds.Items) != 1 {
-			return result
-		}
+	return result
+}
 
-		argocd := argocds.Items[0]
-		namespacedName := client.ObjectKey{
-			Name:      argocd.Name,
-			Namespace: argocd.Namespace,
-		}
-		result = []reconcile.Request{
-			{NamespacedName: namespacedName},
+// apiServerEndpointsMapper maps a watch event on the endpoints of the kubernetes Service back to the ArgoCD objects
+// whose network policies allow egress to the API server.
+func (r *ReconcileArgoCD) apiServerEndpointsMapper(ctx context.Context, o client.Object) []reconcile.Request {
+	var result = []reconcile.Request{}
+
+	if o.GetNamespace() != apiServerServiceNamespace || o.GetName() != apiServerServiceName {
+		return result
+	}
+	argocds := &argoproj.ArgoCDList{}
+	if err := r.Client.List(ctx, argocds, &client.ListOptions{}); err != nil {
+		log.Error(err, "failed to list ArgoCD instances")
+		return result
+	}
+	for _, argocd := range argocds.Items {
+		if isNetworkPolicyEnabled(&argocd) {
+			result = append(result, reconcile.Request{
+				NamespacedName: client.ObjectKey{Name: argocd.Name, Namespace: argocd.Namespace},
+			})
 		}
 	}
-
 	return result
 }
+
+// namespaceSelectorMatches returns true if the given namespace selector is set and matches the given namespace labels.
+func namespaceSelectorMatches(selector *v1.LabelSelector, namespaceLabels map[string]string) bool {
+	if selector == nil {
+		return false
+	}
+	s, err := v1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return false
+	}
+	return s.Matches(k8slabels.Set(namespaceLabels))
+}
@@ -942,3 +942,191 @@ func TestReconcileArgoCD_namespaceResourceMapperForWildCardNamespaceWithoutManag
 		})
 	}
 }
+
+func TestReconcileArgoCD_mappersWithMultipleInstances(t *testing.T) {
+	prod := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Name = "prod"
+	})
+	staging := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Name = "staging"
+	})
+
+	resObjs := []client.Object{prod, staging}
+	subresObjs := []client.Object{prod, staging}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	prodRequest := reconcile.Request{NamespacedName: types.NamespacedName{Name: prod.Name, Namespace: testNamespace}}
+	stagingRequest := reconcile.Request{NamespacedName: types.NamespacedName{Name: staging.Name, Namespace: testNamespace}}
+	isController := true
+
+	tests := []struct {
+		name   string
+		mapper func(context.Context, client.Object) []reconcile.Request
+		o      client.Object
+		want   []reconcile.Request
+	}{
+		{
+			name:   "managed namespace reconciles every instance of the managing namespace",
+			mapper: r.namespaceResourceMapper,
+			o: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:   "apps",
+				Labels: map[string]string{common.ArgoCDManagedByLabel: testNamespace},
+			}},
+			want: []reconcile.Request{prodRequest, stagingRequest},
+		},
+		{
+			name:   "cluster secret owned by an instance reconciles that instance",
+			mapper: r.clusterSecretResourceMapper,
+			o: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+				Name:      "staging-default-cluster-config",
+				Namespace: testNamespace,
+				Labels:    map[string]string{common.ArgoCDSecretTypeLabel: "cluster"},
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "argoproj.io/v1beta1", Kind: "ArgoCD", Name: staging.Name, Controller: &isController},
+				},
+			}},
+			want: []reconcile.Request{stagingRequest},
+		},
+		{
+			name:   "cluster secret labeled for an instance reconciles that instance",
+			mapper: r.clusterSecretResourceMapper,
+			o: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+				Name:      "remote-cluster",
+				Namespace: testNamespace,
+				Labels: map[string]string{
+					common.ArgoCDSecretTypeLabel: "cluster",
+					common.ArgoCDKeyManagedBy:    prod.Name,
+				},
+			}},
+			want: []reconcile.Request{prodRequest},
+		},
+		{
+			name:   "unlabeled cluster secret reconciles every instance of the namespace",
+			mapper: r.clusterSecretResourceMapper,
+			o: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+				Name:      "remote-cluster",
+				Namespace: testNamespace,
+				Labels:    map[string]string{common.ArgoCDSecretTypeLabel: "cluster"},
+			}},
+			want: []reconcile.Request{prodRequest, stagingRequest},
+		},
+		{
+			name:   "gitlab scm tls configmap reconciles every instance of the namespace",
+			mapper: r.applicationSetSCMTLSConfigMapMapper,
+			o: &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+				Name:      common.ArgoCDAppSetGitlabSCMTLSCertsConfigMapName,
+				Namespace: testNamespace,
+			}},
+			want: []reconcile.Request{prodRequest, stagingRequest},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ElementsMatch(t, tt.want, tt.mapper(context.TODO(), tt.o))
+		})
+	}
+}
+
+func TestReconcileArgoCD_namespaceResourceMapperWithNamespaceSelectors(t *testing.T) {
+	argocd1 := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Name = "argocd1"
+		a.Namespace = "argo-test-1"
+		a.Spec.ManagedNamespaceSelector = &metav1.LabelSelector{
+			MatchLabels: map[string]string{"tenant": "team-a"},
+		}
+	})
+	argocd2 := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Name = "argocd2"
+		a.Namespace = "argo-test-2"
+		a.Spec.SourceNamespaceSelector = &metav1.LabelSelector{
+			MatchLabels: map[string]string{"apps": "team-b"},
+		}
+	})
+	resObjs := []client.Object{argocd1, argocd2}
+	subresObjs := []client.Object{argocd1, argocd2}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	request1 := reconcile.Request{NamespacedName: types.NamespacedName{Name: argocd1.Name, Namespace: argocd1.Namespace}}
+	request2 := reconcile.Request{NamespacedName: types.NamespacedName{Name: argocd2.Name, Namespace: argocd2.Namespace}}
+
+	tests := []struct {
+		name string
+		o    client.Object
+		want []reconcile.Request
+	}{
+		{
+			name: "namespace selected by the managed namespace selector",
+			o: &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{Name: "tenant-a", Labels: map[string]string{"tenant": "team-a"}},
+			},
+			want: []reconcile.Request{request1},
+		},
+		{
+			name: "namespace selected by the source namespace selector",
+			o: &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{Name: "tenant-b", Labels: map[string]string{"apps": "team-b"}},
+			},
+			want: []reconcile.Request{request2},
+		},
+		{
+			name: "namespace no longer selected by the managed namespace selector",
+			o: &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "tenant-a",
+					Annotations: map[string]string{common.AnnotationManagedNamespaceSelector: instanceKey(argocd1)},
+				},
+			},
+			want: []reconcile.Request{request1},
+		},
+		{
+			name: "namespace no longer selected by the source namespace selector",
+			o: &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "tenant-b",
+					Labels: map[string]string{common.ArgoCDManagedByClusterArgoCDLabel: argocd2.Namespace},
+				},
+			},
+			want: []reconcile.Request{request2},
+		},
+		{
+			name: "namespace not selected",
+			o: &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{Name: "tenant-c", Labels: map[string]string{"tenant": "team-c"}},
+			},
+			want: []reconcile.Request{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := r.namespaceResourceMapper(context.TODO(), tt.o)
+			assert.ElementsMatch(t, tt.want, got)
+		})
+	}
+}
+
+func TestReconcileArgoCD_apiServerEndpointsMapper(t *testing.T) {
+	enabled := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.NetworkPolicy = &argoproj.ArgoCDNetworkPolicySpec{Enabled: true}
+	})
+	disabled := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Name = "argocd-no-policies"
+	})
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	r := makeTestReconciler(makeTestReconcilerClient(sch, []client.Object{enabled, disabled}, []client.Object{}, []runtime.Object{}), sch)
+
+	endpoints := &corev1.Endpoints{ObjectMeta: metav1.ObjectMeta{Name: "kubernetes", Namespace: "default"}}
+	assert.Equal(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: enabled.Name, Namespace: enabled.Namespace}},
+	}, r.apiServerEndpointsMapper(context.TODO(), endpoints))
+
+	other := &corev1.Endpoints{ObjectMeta: metav1.ObjectMeta{Name: "kubernetes", Namespace: testNamespace}}
+	assert.Empty(t, r.apiServerEndpointsMapper(context.TODO(), other))
+}
@@ -32,6 +32,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
@@ -57,6 +58,15 @@ func getArgoCDServerReplicas(cr *argoproj.ArgoCD) *int32 {
 	return nil
 }
 
+// getRedisHAProxyReplicas will return the size value for the Redis HAProxy replica count if it
+// has been set in argocd CR. Otherwise, the default of a single replica is returned.
+func getRedisHAProxyReplicas(cr *argoproj.ArgoCD) *int32 {
+	if cr.Spec.HA.RedisProxyReplicas != nil && *cr.Spec.HA.RedisProxyReplicas > 0 {
+		return cr.Spec.HA.RedisProxyReplicas
+	}
+	return int32Ptr(1)
+}
+
 func (r *ReconcileArgoCD) getArgoCDExport(cr *argoproj.ArgoCD) *argoprojv1alpha1.ArgoCDExport {
 	if cr.Spec.Import == nil {
 		return nil
@@ -234,18 +244,11 @@ func getArgoRepoCommand(cr *argoproj.ArgoCD, useTLSForRedis bool) []string {
 	cmd = append(cmd, "argocd-repo-server")
 
 	if cr.Spec.Redis.IsEnabled() {
-		cmd = append(cmd, "--redis", getRedisServerAddress(cr))
+		cmd = append(cmd, getRedisServerArgs(cr)...)
 	} else {
 		log.Info("Redis is Disabled. Skipping adding Redis configuration to Repo Server.")
 	}
-	if useTLSForRedis {
-		cmd = append(cmd, "--redis-use-tls")
-		if isRedisTLSVerificationDisabled(cr) {
-			cmd = append(cmd, "--redis-insecure-skip-tls-verify")
-		} else {
-			cmd = append(cmd, "--redis-ca-certificate", "/app/config/reposerver/tls/redis/tls.crt")
-		}
-	}
+	cmd = append(cmd, getRedisTLSArgs(cr, useTLSForRedis, "/app/config/reposerver/tls/redis/tls.crt")...)
 
 	cmd = append(cmd, "--loglevel")
 	cmd = append(cmd, getLogLevel(cr.Spec.Repo.LogLevel))
@@ -265,18 +268,142 @@ func getArgoRepoCommand(cr *argoproj.ArgoCD, useTLSForRedis bool) []string {
 	return cmd
 }
 
+// argoCmpServerPath is the path of the cmp-server entrypoint copied into the shared var-files volume.
+const argoCmpServerPath = "/var/run/argocd/argocd-cmp-server"
+
+// cmpConfigVolumeName is the name of the volume of the Config Management Plugin ConfigMap, whose name may not be a
+// valid volume name.
+const cmpConfigVolumeName = "cmp-config"
+
 // getArgoCmpServerInitCommand will return the command for the ArgoCD CMP Server init container
 func getArgoCmpServerInitCommand() []string {
 	cmd := make([]string, 0)
 	cmd = append(cmd, "cp")
 	cmd = append(cmd, "-n")
 	cmd = append(cmd, "/usr/local/bin/argocd")
-	cmd = append(cmd, "/var/run/argocd/argocd-cmp-server")
+	cmd = append(cmd, argoCmpServerPath)
 	return cmd
 }
 
+// getRepoPlugins will return the valid Config Management Plugins declared for the repo server. Plugins without
+// a name or an image, whose name is not a valid container name, or using a name already taken by another container,
+// are skipped.
+func getRepoPlugins(cr *argoproj.ArgoCD) []argoproj.ArgoCDRepoPluginSpec {
+	names := map[string]bool{"argocd-repo-server": true}
+	for _, c := range cr.Spec.Repo.SidecarContainers {
+		names[c.Name] = true
+	}
+
+	plugins := make([]argoproj.ArgoCDRepoPluginSpec, 0, len(cr.Spec.Repo.Plugins))
+	for _, plugin := range cr.Spec.Repo.Plugins {
+		if plugin.Name == "" || plugin.Image == "" {
+			log.Info("Skipping config management plugin without a name or an image")
+			continue
+		}
+		if errs := validation.IsDNS1123Label(plugin.Name); len(errs) > 0 {
+			log.Info(fmt.Sprintf("Skipping config management plugin %s, its name is not a valid container name: %s", plugin.Name, strings.Join(errs, ", ")))
+			continue
+		}
+		if names[plugin.Name] {
+			log.Info(fmt.Sprintf("Skipping config management plugin %s, its name is already used by another repo server container", plugin.Name))
+			continue
+		}
+		names[plugin.Name] = true
+		plugins = append(plugins, plugin)
+	}
+	return plugins
+}
+
+// getRepoPluginContainers will return the sidecar containers running the cmp-server for every Config Management Plugin.
+func getRepoPluginContainers(cr *argoproj.ArgoCD) []corev1.Container {
+	plugins := getRepoPlugins(cr)
+	containers := make([]corev1.Container, 0, len(plugins))
+	for _, plugin := range plugins {
+		pullPolicy := plugin.ImagePullPolicy
+		if pullPolicy == "" {
+			pullPolicy = corev1.PullAlways
+		}
+
+		resources := corev1.ResourceRequirements{}
+		if plugin.Resources != nil {
+			resources = *plugin.Resources
+		}
+
+		volumeMounts := []corev1.VolumeMount{
+			{
+				Name:      "var-files",
+				MountPath: "/var/run/argocd",
+			},
+			{
+				Name:      "plugins",
+				MountPath: "/home/argocd/cmp-server/plugins",
+			},
+			{
+				Name:      cmpConfigVolumeName,
+				MountPath: "/home/argocd/cmp-server/config/plugin.yaml",
+				SubPath:   getCmpConfigMapKey(plugin),
+			},
+			{
+				Name:      "cmp-tmp",
+				MountPath: "/tmp",
+			},
+		}
+		volumeMounts = append(volumeMounts, plugin.VolumeMounts...)
+
+		containers = append(containers, corev1.Container{
+			Name:            plugin.Name,
+			Image:           plugin.Image,
+			ImagePullPolicy: pullPolicy,
+			Command:         []string{argoCmpServerPath},
+			Env:             argoutil.EnvMerge(plugin.Env, getProxyEnvVars(cr), false),
+			Resources:       resources,
+			SecurityContext: &corev1.SecurityContext{
+				AllowPrivilegeEscalation: boolPtr(false),
+				Capabilities: &corev1.Capabilities{
+					Drop: []corev1.Capability{
+						"ALL",
+					},
+				},
+				RunAsNonRoot: boolPtr(true),
+				// The cmp-server must run as the same user as the repo server to share the plugin sockets
+				RunAsUser: int64Ptr(999),
+				SeccompProfile: &corev1.SeccompProfile{
+					Type: "RuntimeDefault",
+				},
+			},
+			VolumeMounts: volumeMounts,
+		})
+	}
+	return containers
+}
+
+// getRepoPluginVolumes will return the volumes required by the Config Management Plugin sidecars, if any.
+func getRepoPluginVolumes(cr *argoproj.ArgoCD) []corev1.Volume {
+	if len(getRepoPlugins(cr)) == 0 {
+		return nil
+	}
+	return []corev1.Volume{
+		{
+			Name: cmpConfigVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: getCmpConfigMapName(cr),
+					},
+				},
+			},
+		},
+		{
+			Name: "cmp-tmp",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		},
+	}
+}
+
 // getArgoServerCommand will return the command for the ArgoCD server component.
-func getArgoServerCommand(cr *argoproj.ArgoCD, useTLSForRedis bool) []string {
+func getArgoServerCommand(cr *argoproj.ArgoCD, useTLSForRedis bool, applicationNamespaces []string) []string {
 	cmd := make([]string, 0)
 	cmd = append(cmd, "argocd-server")
 
@@ -301,19 +428,12 @@ func getArgoServerCommand(cr *argoproj.ArgoCD, useTLSForRedis bool) []string {
 	}
 
 	if cr.Spec.Redis.IsEnabled() {
-		cmd = append(cmd, "--redis", getRedisServerAddress(cr))
+		cmd = append(cmd, getRedisServerArgs(cr)...)
 	} else {
 		log.Info("Redis is Disabled. Skipping adding Redis configuration to ArgoCD Server.")
 	}
 
-	if useTLSForRedis {
-		cmd = append(cmd, "--redis-use-tls")
-		if isRedisTLSVerificationDisabled(cr) {
-			cmd = append(cmd, "--redis-insecure-skip-tls-verify")
-		} else {
-			cmd = append(cmd, "--redis-ca-certificate", "/app/config/server/tls/redis/tls.crt")
-		}
-	}
+	cmd = append(cmd, getRedisTLSArgs(cr, useTLSForRedis, "/app/config/server/tls/redis/tls.crt")...)
 
 	cmd = append(cmd, "--loglevel")
 	cmd = append(cmd, getLogLevel(cr.Spec.Server.LogLevel))
@@ -326,8 +446,8 @@ func getArgoServerCommand(cr *argoproj.ArgoCD, useTLSForRedis bool) []string {
 	if err != nil {
 		return cmd
 	}
-	if cr.Spec.SourceNamespaces != nil && len(cr.Spec.SourceNamespaces) > 0 {
-		cmd = append(cmd, "--application-namespaces", fmt.Sprint(strings.Join(cr.Spec.SourceNamespaces, ",")))
+	if len(applicationNamespaces) > 0 {
+		cmd = append(cmd, "--application-namespaces", fmt.Sprint(strings.Join(applicationNamespaces, ",")))
 	}
 
 	cmd = append(cmd, extraArgs...)
@@ -426,7 +546,7 @@ func (r *ReconcileArgoCD) reconcileDeployments(cr *argoproj.ArgoCD, useTLSForRed
 		return err
 	}
 
-	err = r.reconcileRedisHAProxyDeployment(cr)
+	err = r.reconcileRedisHAProxyDeployment(cr, useTLSForRedis)
 	if err != nil {
 		return err
 	}
@@ -462,7 +582,7 @@ func (r *ReconcileArgoCD) reconcileGrafanaDeployment(cr *argoproj.ArgoCD) error
 func (r *ReconcileArgoCD) reconcileRedisDeployment(cr *argoproj.ArgoCD, useTLS bool) error {
 	deploy := newDeploymentWithSuffix("redis", "redis", cr)
 
-	env := append(proxyEnvVars(), corev1.EnvVar{
+	env := append(getProxyEnvVars(cr), corev1.EnvVar{
 		Name: "REDIS_PASSWORD",
 		ValueFrom: &corev1.EnvVarSource{
 			SecretKeyRef: &corev1.SecretKeySelector{
@@ -474,7 +594,7 @@ func (r *ReconcileArgoCD) reconcileRedisDeployment(cr *argoproj.ArgoCD, useTLS b
 		},
 	})
 
-	AddSeccompProfileForOpenShift(r.Client, &deploy.Spec.Template.Spec)
+	AddSeccompProfileForOpenShift(r.Client, r.ClusterAPIs, &deploy.Spec.Template.Spec)
 
 	deploy.Spec.Template.Spec.Containers = []corev1.Container{{
 		Args:            getArgoRedisArgs(useTLS),
@@ -537,39 +657,10 @@ func (r *ReconcileArgoCD) reconcileRedisDeployment(cr *argoproj.ArgoCD, useTLS b
 			// Deployment exists but HA enabled flag has been set to true, delete the Deployment
 			return r.Client.Delete(context.TODO(), deploy)
 		}
-		changed := false
-		actualImage := existing.Spec.Template.Spec.Containers[0].Image
-		desiredImage := getRedisContainerImage(cr)
-		if actualImage != desiredImage {
-			existing.Spec.Template.Spec.Containers[0].Image = desiredImage
-			existing.Spec.Template.ObjectMeta.Labels["image.upgraded"] = time.Now().UTC().Format("01022006-150406-MST")
-			changed = true
-		}
-		updateNodePlacement(existing, deploy, &changed)
-
-		if !reflect.DeepEqual(deploy.Spec.Template.Spec.Containers[0].Args, existing.Spec.Template.Spec.Containers[0].Args) {
-			existing.Spec.Template.Spec.Containers[0].Args = deploy.Spec.Template.Spec.Containers[0].Args
-			changed = true
-		}
-
-		if !reflect.DeepEqual(existing.Spec.Template.Spec.Containers[0].Env,
-			deploy.Spec.Template.Spec.Containers[0].Env) {
-			existing.Spec.Template.Spec.Containers[0].Env = deploy.Spec.Template.Spec.Containers[0].Env
-			changed = true
-		}
-
-		if !reflect.DeepEqual(deploy.Spec.Template.Spec.Containers[0].Resources, existing.Spec.Template.Spec.Containers[0].Resources) {
-			existing.Spec.Template.Spec.Containers[0].Resources = deploy.Spec.Template.Spec.Containers[0].Resources
-			changed = true
-		}
-
-		if changed {
-			return r.Client.Update(context.TODO(), existing)
-		}
-		return nil // Deployment found with nothing to do, move along...
+		return r.applyDeployment(cr, deploy, existing)
 	}
 
-	if cr.Spec.Redis.IsEnabled() && cr.Spec.Redis.Remote != nil && *cr.Spec.Redis.Remote != "" {
+	if cr.Spec.Redis.IsEnabled() && isRemoteRedis(cr) {
 		log.Info("Custom Redis Endpoint. Skipping starting redis.")
 		return nil
 	}
@@ -582,17 +673,18 @@ func (r *ReconcileArgoCD) reconcileRedisDeployment(cr *argoproj.ArgoCD, useTLS b
 	if cr.Spec.HA.Enabled {
 		return nil // HA enabled, do nothing.
 	}
-	if err := controllerutil.SetControllerReference(cr, deploy, r.Scheme); err != nil {
-		return err
-	}
-	return r.Client.Create(context.TODO(), deploy)
+	return r.applyDeployment(cr, deploy, nil)
 }
 
 // reconcileRedisHAProxyDeployment will ensure the Deployment resource is present for the Redis HA Proxy component.
-func (r *ReconcileArgoCD) reconcileRedisHAProxyDeployment(cr *argoproj.ArgoCD) error {
+func (r *ReconcileArgoCD) reconcileRedisHAProxyDeployment(cr *argoproj.ArgoCD, useTLSForRedis bool) error {
 	deploy := newDeploymentWithSuffix("redis-ha-haproxy", "redis", cr)
+	deploy.Spec.Replicas = getRedisHAProxyReplicas(cr)
+	deploy.Spec.Template.ObjectMeta.Annotations = map[string]string{
+		common.AnnotationRedisHAConfigChecksum: getRedisHAConfigChecksum(cr, useTLSForRedis),
+	}
 
-	var redisEnv = append(proxyEnvVars(), corev1.EnvVar{
+	var redisEnv = append(getProxyEnvVars(cr), corev1.EnvVar{
 		Name: "AUTH",
 		ValueFrom: &corev1.EnvVarSource{
 			SecretKeyRef: &corev1.SecretKeySelector{
@@ -692,7 +784,7 @@ func (r *ReconcileArgoCD) reconcileRedisHAProxyDeployment(cr *argoproj.ArgoCD) e
 		Image:           getRedisHAProxyContainerImage(cr),
 		ImagePullPolicy: corev1.PullIfNotPresent,
 		Name:            "config-init",
-		Env:             proxyEnvVars(),
+		Env:             getProxyEnvVars(cr),
 		Resources:       getRedisHAResources(cr),
 		SecurityContext: &corev1.SecurityContext{
 			AllowPrivilegeEscalation: boolPtr(false),
@@ -774,11 +866,11 @@ func (r *ReconcileArgoCD) reconcileRedisHAProxyDeployment(cr *argoproj.ArgoCD) e
 			Type: "RuntimeDefault",
 		},
 	}
-	AddSeccompProfileForOpenShift(r.Client, &deploy.Spec.Template.Spec)
+	AddSeccompProfileForOpenShift(r.Client, r.ClusterAPIs, &deploy.Spec.Template.Spec)
 
 	deploy.Spec.Template.Spec.ServiceAccountName = fmt.Sprintf("%s-%s", cr.Name, "argocd-redis-ha")
 
-	version, err := getClusterVersion(r.Client)
+	version, err := getClusterVersion(r.Client, r.ClusterAPIs)
 	if err != nil {
 		log.Error(err, "error getting cluster version")
 	}
@@ -792,45 +884,18 @@ func (r *ReconcileArgoCD) reconcileRedisHAProxyDeployment(cr *argoproj.ArgoCD) e
 			// Deployment exists but HA enabled flag has been set to false, delete the Deployment
 			return r.Client.Delete(context.TODO(), existing)
 		}
-		changed := false
-		actualImage := existing.Spec.Template.Spec.Containers[0].Image
-		desiredImage := getRedisHAProxyContainerImage(cr)
-
-		if actualImage != desiredImage {
-			existing.Spec.Template.Spec.Containers[0].Image = desiredImage
-			existing.Spec.Template.ObjectMeta.Labels["image.upgraded"] = time.Now().UTC().Format("01022006-150406-MST")
-			changed = true
-		}
-		updateNodePlacement(existing, deploy, &changed)
-
-		if !reflect.DeepEqual(deploy.Spec.Template.Spec.Containers[0].Resources, existing.Spec.Template.Spec.Containers[0].Resources) {
-			existing.Spec.Template.Spec.Containers[0].Resources = deploy.Spec.Template.Spec.Containers[0].Resources
-			changed = true
-		}
-
-		if !reflect.DeepEqual(deploy.Spec.Template.Spec.InitContainers[0].Resources, existing.Spec.Template.Spec.InitContainers[0].Resources) {
-			existing.Spec.Template.Spec.InitContainers[0].Resources = deploy.Spec.Template.Spec.InitContainers[0].Resources
-			changed = true
-		}
-
-		if changed {
-			return r.Client.Update(context.TODO(), existing)
-		}
-		return nil // Deployment found, do nothing
+		return r.applyDeployment(cr, deploy, existing)
 	}
 
 	if !cr.Spec.HA.Enabled {
 		return nil // HA not enabled, do nothing.
 	}
-
-	if err := controllerutil.SetControllerReference(cr, deploy, r.Scheme); err != nil {
-		return err
-	}
-	return r.Client.Create(context.TODO(), deploy)
+	return r.applyDeployment(cr, deploy, nil)
 }
 
 // reconcileRepoDeployment will ensure the Deployment resource is present for the ArgoCD Repo component.
 func (r *ReconcileArgoCD) reconcileRepoDeployment(cr *argoproj.ArgoCD, useTLSForRedis bool) error {
+	cr = argoCDForUpgradeStep(cr, "repo-server")
 	deploy := newDeploymentWithSuffix("repo-server", "repo-server", cr)
 	automountToken := false
 	if cr.Spec.Repo.MountSAToken {
@@ -845,24 +910,14 @@ func (r *ReconcileArgoCD) reconcileRepoDeployment(cr *argoproj.ArgoCD, useTLSFor
 
 	// Global proxy env vars go first
 	repoEnv := cr.Spec.Repo.Env
-	repoEnv = append(repoEnv, corev1.EnvVar{
-		Name: "REDIS_PASSWORD",
-		ValueFrom: &corev1.EnvVarSource{
-			SecretKeyRef: &corev1.SecretKeySelector{
-				LocalObjectReference: corev1.LocalObjectReference{
-					Name: fmt.Sprintf("%s-%s", cr.Name, "redis-initial-password"),
-				},
-				Key: "admin.password",
-			},
-		},
-	})
+	repoEnv = append(repoEnv, getRedisAuthEnv(cr)...)
 	// Environment specified in the CR take precedence over everything else
-	repoEnv = argoutil.EnvMerge(repoEnv, proxyEnvVars(), false)
+	repoEnv = argoutil.EnvMerge(repoEnv, getProxyEnvVars(cr), false)
 	if cr.Spec.Repo.ExecTimeout != nil {
 		repoEnv = argoutil.EnvMerge(repoEnv, []corev1.EnvVar{{Name: "ARGOCD_EXEC_TIMEOUT", Value: fmt.Sprintf("%ds", *cr.Spec.Repo.ExecTimeout)}}, true)
 	}
 
-	AddSeccompProfileForOpenShift(r.Client, &deploy.Spec.Template.Spec)
+	AddSeccompProfileForOpenShift(r.Client, r.ClusterAPIs, &deploy.Spec.Template.Spec)
 
 	deploy.Spec.Template.Spec.InitContainers = []corev1.Container{{
 		Name:            "copyutil",
@@ -870,7 +925,7 @@ func (r *ReconcileArgoCD) reconcileRepoDeployment(cr *argoproj.ArgoCD, useTLSFor
 		Command:         getArgoCmpServerInitCommand(),
 		ImagePullPolicy: corev1.PullAlways,
 		Resources:       getArgoRepoResources(cr),
-		Env:             proxyEnvVars(),
+		Env:             getProxyEnvVars(cr),
 		SecurityContext: &corev1.SecurityContext{
 			AllowPrivilegeEscalation: boolPtr(false),
 			Capabilities: &corev1.Capabilities{
@@ -930,6 +985,8 @@ func (r *ReconcileArgoCD) reconcileRepoDeployment(cr *argoproj.ArgoCD, useTLSFor
 		},
 	}
 
+	repoServerVolumeMounts = append(repoServerVolumeMounts, getRemoteRedisCAVolumeMounts(cr)...)
+
 	if cr.Spec.Repo.VolumeMounts != nil {
 		repoServerVolumeMounts = append(repoServerVolumeMounts, cr.Spec.Repo.VolumeMounts...)
 	}
@@ -983,9 +1040,7 @@ func (r *ReconcileArgoCD) reconcileRepoDeployment(cr *argoproj.ArgoCD, useTLSFor
 		VolumeMounts: repoServerVolumeMounts,
 	}}
 
-	if cr.Spec.Repo.SidecarContainers != nil {
-		deploy.Spec.Template.Spec.Containers = append(deploy.Spec.Template.Spec.Containers, cr.Spec.Repo.SidecarContainers...)
-	}
+	deploy.Spec.Template.Spec.Containers = append(deploy.Spec.Template.Spec.Containers, getRepoPluginContainers(cr)...)
 
 	repoServerVolumes := []corev1.Volume{
 		{
@@ -1062,11 +1117,34 @@ func (r *ReconcileArgoCD) reconcileRepoDeployment(cr *argoproj.ArgoCD, useTLSFor
 		},
 	}
 
+	repoServerVolumes = append(repoServerVolumes, getRemoteRedisCAVolumes(cr)...)
+	repoServerVolumes = append(repoServerVolumes, getRepoPluginVolumes(cr)...)
+
 	if cr.Spec.Repo.Volumes != nil {
 		repoServerVolumes = append(repoServerVolumes, cr.Spec.Repo.Volumes...)
 	}
 
 	deploy.Spec.Template.Spec.Volumes = repoServerVolumes
+	addTrustedCA(cr, &deploy.Spec.Template.Spec, getRepoServerContainerImage(cr))
+
+	// the sidecars of the user are left as declared
+	if cr.Spec.Repo.SidecarContainers != nil {
+		deploy.Spec.Template.Spec.Containers = append(deploy.Spec.Template.Spec.Containers, cr.Spec.Repo.SidecarContainers...)
+	}
+
+	// The plugin.yaml files are mounted with subPath and never refreshed, roll the pods when they change.
+	cmpChecksum := ""
+	if plugins := getRepoPlugins(cr); len(plugins) > 0 {
+		data, err := getCmpConfigMapData(plugins)
+		if err != nil {
+			return err
+		}
+		cmpChecksum = getConfigMapDataChecksum(data)
+		if deploy.Spec.Template.ObjectMeta.Annotations == nil {
+			deploy.Spec.Template.ObjectMeta.Annotations = map[string]string{}
+		}
+		deploy.Spec.Template.ObjectMeta.Annotations[common.AnnotationCmpConfigChecksum] = cmpChecksum
+	}
 
 	if replicas := getArgoCDRepoServerReplicas(cr); replicas != nil {
 		deploy.Spec.Replicas = replicas
@@ -1074,112 +1152,36 @@ func (r *ReconcileArgoCD) reconcileRepoDeployment(cr *argoproj.ArgoCD, useTLSFor
 
 	existing := newDeploymentWithSuffix("repo-server", "repo-server", cr)
 	if argoutil.IsObjectFound(r.Client, cr.Namespace, existing.Name, existing) {
-
 		if !cr.Spec.Repo.IsEnabled() {
 			log.Info("Existing ArgoCD Repo Server found but should be disabled. Deleting Repo Server")
 			// Delete existing deployment for ArgoCD Repo Server, if any ..
 			return r.Client.Delete(context.TODO(), existing)
 		}
-
-		changed := false
-		actualImage := existing.Spec.Template.Spec.Containers[0].Image
-		desiredImage := getRepoServerContainerImage(cr)
-		if actualImage != desiredImage {
-			existing.Spec.Template.Spec.Containers[0].Image = desiredImage
-			if existing.Spec.Template.ObjectMeta.Labels == nil {
-				existing.Spec.Template.ObjectMeta.Labels = map[string]string{
-					"image.upgraded": time.Now().UTC().Format("01022006-150406-MST"),
-				}
-			}
-			existing.Spec.Template.ObjectMeta.Labels["image.upgraded"] = time.Now().UTC().Format("01022006-150406-MST")
-			changed = true
-		}
-		updateNodePlacement(existing, deploy, &changed)
-		if !reflect.DeepEqual(deploy.Spec.Template.Spec.Volumes, existing.Spec.Template.Spec.Volumes) {
-			existing.Spec.Template.Spec.Volumes = deploy.Spec.Template.Spec.Volumes
-			changed = true
-		}
-		if !reflect.DeepEqual(deploy.Spec.Template.Spec.Containers[0].VolumeMounts,
-			existing.Spec.Template.Spec.Containers[0].VolumeMounts) {
-			existing.Spec.Template.Spec.Containers[0].VolumeMounts = deploy.Spec.Template.Spec.Containers[0].VolumeMounts
-			changed = true
-		}
-		if !reflect.DeepEqual(deploy.Spec.Template.Spec.Containers[0].Env,
-			existing.Spec.Template.Spec.Containers[0].Env) {
-			existing.Spec.Template.Spec.Containers[0].Env = deploy.Spec.Template.Spec.Containers[0].Env
-			changed = true
-		}
-		if !reflect.DeepEqual(deploy.Spec.Template.Spec.Containers[0].Resources, existing.Spec.Template.Spec.Containers[0].Resources) {
-			existing.Spec.Template.Spec.Containers[0].Resources = deploy.Spec.Template.Spec.Containers[0].Resources
-			changed = true
-		}
-		if !reflect.DeepEqual(deploy.Spec.Template.Spec.Containers[0].Command, existing.Spec.Template.Spec.Containers[0].Command) {
-			existing.Spec.Template.Spec.Containers[0].Command = deploy.Spec.Template.Spec.Containers[0].Command
-			changed = true
-		}
-		if !reflect.DeepEqual(deploy.Spec.Template.Spec.Containers[1:],
-			existing.Spec.Template.Spec.Containers[1:]) {
-			existing.Spec.Template.Spec.Containers = append(existing.Spec.Template.Spec.Containers[0:1],
-				deploy.Spec.Template.Spec.Containers[1:]...)
-			changed = true
-		}
-		if !reflect.DeepEqual(deploy.Spec.Template.Spec.InitContainers, existing.Spec.Template.Spec.InitContainers) {
-			existing.Spec.Template.Spec.InitContainers = deploy.Spec.Template.Spec.InitContainers
-			changed = true
-		}
-
-		if !reflect.DeepEqual(deploy.Spec.Replicas, existing.Spec.Replicas) {
-			existing.Spec.Replicas = deploy.Spec.Replicas
-			changed = true
-		}
-
-		if deploy.Spec.Template.Spec.AutomountServiceAccountToken != existing.Spec.Template.Spec.AutomountServiceAccountToken {
-			existing.Spec.Template.Spec.AutomountServiceAccountToken = deploy.Spec.Template.Spec.AutomountServiceAccountToken
-			changed = true
-		}
-
-		if deploy.Spec.Template.Spec.ServiceAccountName != existing.Spec.Template.Spec.ServiceAccountName {
-			existing.Spec.Template.Spec.ServiceAccountName = deploy.Spec.Template.Spec.ServiceAccountName
-			existing.Spec.Template.Spec.DeprecatedServiceAccount = deploy.Spec.Template.Spec.ServiceAccountName
-			changed = true
-		}
-
-		if changed {
-			return r.Client.Update(context.TODO(), existing)
-		}
-		return nil // Deployment found with nothing to do, move along...
+		return r.applyDeployment(cr, deploy, existing)
 	}
 
 	if !cr.Spec.Repo.IsEnabled() {
 		log.Info("ArgoCD Repo Server disabled. Skipping starting ArgoCD Repo Server.")
 		return nil
 	}
-
-	if err := controllerutil.SetControllerReference(cr, deploy, r.Scheme); err != nil {
-		return err
-	}
-	return r.Client.Create(context.TODO(), deploy)
+	return r.applyDeployment(cr, deploy, nil)
 }
 
 // reconcileServerDeployment will ensure the Deployment resource is present for the ArgoCD Server component.
 func (r *ReconcileArgoCD) reconcileServerDeployment(cr *argoproj.ArgoCD, useTLSForRedis bool) error {
+	cr = argoCDForUpgradeStep(cr, "server")
+	applicationNamespaces, err := r.getApplicationNamespaces(cr)
+	if err != nil {
+		return err
+	}
+
 	deploy := newDeploymentWithSuffix("server", "server", cr)
 	serverEnv := cr.Spec.Server.Env
-	serverEnv = append(serverEnv, corev1.EnvVar{
-		Name: "REDIS_PASSWORD",
-		ValueFrom: &corev1.EnvVarSource{
-			SecretKeyRef: &corev1.SecretKeySelector{
-				LocalObjectReference: corev1.LocalObjectReference{
-					Name: fmt.Sprintf("%s-%s", cr.Name, "redis-initial-password"),
-				},
-				Key: "admin.password",
-			},
-		},
-	})
-	serverEnv = argoutil.EnvMerge(serverEnv, proxyEnvVars(), false)
-	AddSeccompProfileForOpenShift(r.Client, &deploy.Spec.Template.Spec)
+	serverEnv = append(serverEnv, getRedisAuthEnv(cr)...)
+	serverEnv = argoutil.EnvMerge(serverEnv, getProxyEnvVars(cr), false)
+	AddSeccompProfileForOpenShift(r.Client, r.ClusterAPIs, &deploy.Spec.Template.Spec)
 	deploy.Spec.Template.Spec.Containers = []corev1.Container{{
-		Command:         getArgoServerCommand(cr, useTLSForRedis),
+		Command:         getArgoServerCommand(cr, useTLSForRedis, applicationNamespaces),
 		Image:           getArgoContainerImage(cr),
 		ImagePullPolicy: corev1.PullAlways,
 		Env:             serverEnv,
@@ -1284,6 +1286,10 @@ func (r *ReconcileArgoCD) reconcileServerDeployment(cr *argoproj.ArgoCD, useTLSF
 		},
 	}
 
+	deploy.Spec.Template.Spec.Containers[0].VolumeMounts = append(deploy.Spec.Template.Spec.Containers[0].VolumeMounts, getRemoteRedisCAVolumeMounts(cr)...)
+	deploy.Spec.Template.Spec.Volumes = append(deploy.Spec.Template.Spec.Volumes, getRemoteRedisCAVolumes(cr)...)
+	addTrustedCA(cr, &deploy.Spec.Template.Spec, getArgoContainerImage(cr))
+
 	if replicas := getArgoCDServerReplicas(cr); replicas != nil {
 		deploy.Spec.Replicas = replicas
 	}
@@ -1295,60 +1301,26 @@ func (r *ReconcileArgoCD) reconcileServerDeployment(cr *argoproj.ArgoCD, useTLSF
 			// Delete existing deployment for ArgoCD Server, if any ..
 			return r.Client.Delete(context.TODO(), existing)
 		}
-		actualImage := existing.Spec.Template.Spec.Containers[0].Image
-		desiredImage := getArgoContainerImage(cr)
-		changed := false
-		if actualImage != desiredImage {
-			existing.Spec.Template.Spec.Containers[0].Image = desiredImage
-			existing.Spec.Template.ObjectMeta.Labels["image.upgraded"] = time.Now().UTC().Format("01022006-150406-MST")
-			changed = true
-		}
-		updateNodePlacement(existing, deploy, &changed)
-		if !reflect.DeepEqual(existing.Spec.Template.Spec.Containers[0].Env,
-			deploy.Spec.Template.Spec.Containers[0].Env) {
-			existing.Spec.Template.Spec.Containers[0].Env = deploy.Spec.Template.Spec.Containers[0].Env
-			changed = true
-		}
-		if !reflect.DeepEqual(existing.Spec.Template.Spec.Containers[0].Command,
-			deploy.Spec.Template.Spec.Containers[0].Command) {
-			existing.Spec.Template.Spec.Containers[0].Command = deploy.Spec.Template.Spec.Containers[0].Command
-			changed = true
-		}
-		if !reflect.DeepEqual(deploy.Spec.Template.Spec.Volumes, existing.Spec.Template.Spec.Volumes) {
-			existing.Spec.Template.Spec.Volumes = deploy.Spec.Template.Spec.Volumes
-			changed = true
-		}
-		if !reflect.DeepEqual(deploy.Spec.Template.Spec.Containers[0].VolumeMounts,
-			existing.Spec.Template.Spec.Containers[0].VolumeMounts) {
-			existing.Spec.Template.Spec.Containers[0].VolumeMounts = deploy.Spec.Template.Spec.Containers[0].VolumeMounts
-			changed = true
-		}
-		if !reflect.DeepEqual(deploy.Spec.Template.Spec.Containers[0].Resources,
-			existing.Spec.Template.Spec.Containers[0].Resources) {
-			existing.Spec.Template.Spec.Containers[0].Resources = deploy.Spec.Template.Spec.Containers[0].Resources
-			changed = true
-		}
-		if !reflect.DeepEqual(deploy.Spec.Replicas, existing.Spec.Replicas) {
-			if !cr.Spec.Server.Autoscale.Enabled {
-				existing.Spec.Replicas = deploy.Spec.Replicas
-				changed = true
-			}
-		}
-		if changed {
-			return r.Client.Update(context.TODO(), existing)
-		}
-		return nil // Deployment found with nothing to do, move along...
+		return r.applyDeployment(cr, deploy, existing)
 	}
 
 	if !cr.Spec.Server.IsEnabled() {
 		log.Info("ArgoCD Server disabled. Skipping starting argocd server.")
 		return nil
 	}
+	return r.applyDeployment(cr, deploy, nil)
+}
 
+// applyDeployment applies the given Deployment owned by the given ArgoCD. The existing Deployment, if any, is used to
+// carry over its image.upgraded label.
+func (r *ReconcileArgoCD) applyDeployment(cr *argoproj.ArgoCD, deploy *appsv1.Deployment, existing *appsv1.Deployment) error {
+	if existing != nil {
+		carryImageUpgradedLabel(&deploy.Spec.Template, &existing.Spec.Template)
+	}
 	if err := controllerutil.SetControllerReference(cr, deploy, r.Scheme); err != nil {
 		return err
 	}
-	return r.Client.Create(context.TODO(), deploy)
+	return argoutil.ApplyResource(r.Client, deploy)
 }
 
 // triggerDeploymentRollout will update the label with the given key to trigger a new rollout of the Deployment.
@@ -1392,14 +1364,30 @@ func isRemoveManagedByLabelOnArgoCDDeletion() bool {
 	return false
 }
 
-// to update nodeSelector and tolerations in reconciler
-func updateNodePlacement(existing *appsv1.Deployment, deploy *appsv1.Deployment, changed *bool) {
-	if !reflect.DeepEqual(existing.Spec.Template.Spec.NodeSelector, deploy.Spec.Template.Spec.NodeSelector) {
-		existing.Spec.Template.Spec.NodeSelector = deploy.Spec.Template.Spec.NodeSelector
-		*changed = true
+// carryImageUpgradedLabel sets on the desired pod template the image.upgraded label of the existing one, refreshed when
+// the image of a container changes, so that applying the desired template does not drop the label.
+func carryImageUpgradedLabel(desired *corev1.PodTemplateSpec, existing *corev1.PodTemplateSpec) {
+	upgraded := existing.ObjectMeta.Labels["image.upgraded"]
+	if !reflect.DeepEqual(containerImages(desired.Spec), containerImages(existing.Spec)) {
+		upgraded = time.Now().UTC().Format("01022006-150406-MST")
+	}
+	if upgraded == "" {
+		return
+	}
+	if desired.ObjectMeta.Labels == nil {
+		desired.ObjectMeta.Labels = map[string]string{}
+	}
+	desired.ObjectMeta.Labels["image.upgraded"] = upgraded
+}
+
+// containerImages returns the images of the init containers and containers of the given pod spec, by container name.
+func containerImages(spec corev1.PodSpec) map[string]string {
+	images := map[string]string{}
+	for _, c := range spec.InitContainers {
+		images[c.Name] = c.Image
 	}
-	if !reflect.DeepEqual(existing.Spec.Template.Spec.Tolerations, deploy.Spec.Template.Spec.Tolerations) {
-		existing.Spec.Template.Spec.Tolerations = deploy.Spec.Template.Spec.Tolerations
-		*changed = true
+	for _, c := range spec.Containers {
+		images[c.Name] = c.Image
 	}
+	return images
 }
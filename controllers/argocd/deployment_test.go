@@ -3,6 +3,7 @@ package argocd
 import (
 	"context"
 	"fmt"
+	"os"
 	"reflect"
 	"strings"
 	"testing"
@@ -16,6 +17,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	"github.com/argoproj-labs/argocd-operator/common"
@@ -168,6 +170,59 @@ func TestReconcileArgoCD_reconcile_ServerDeployment_replicas(t *testing.T) {
 	}
 }
 
+// makeTestAPIServerReconciler starts an API server with envtest and returns a reconciler using it, for the tests
+// relying on the actual server-side apply. The test is skipped when the envtest binaries are not installed, see the
+// test target of the Makefile, unless it runs in CI.
+func makeTestAPIServerReconciler(t *testing.T) *ReconcileArgoCD {
+	t.Helper()
+	if os.Getenv("KUBEBUILDER_ASSETS") == "" {
+		if os.Getenv("CI") != "" {
+			t.Fatal("KUBEBUILDER_ASSETS is not set, run the tests with the test target of the Makefile")
+		}
+		t.Skip("KUBEBUILDER_ASSETS is not set, skipping the test against an API server")
+	}
+	env := &envtest.Environment{}
+	cfg, err := env.Start()
+	if err != nil {
+		t.Fatalf("failed to start the API server: %v", err)
+	}
+	t.Cleanup(func() { _ = env.Stop() })
+
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	c, err := client.New(cfg, client.Options{Scheme: sch})
+	if err != nil {
+		t.Fatalf("failed to create the client: %v", err)
+	}
+	assert.NoError(t, c.Create(context.TODO(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: testNamespace}}))
+	return makeTestReconciler(c, sch)
+}
+
+func TestReconcileArgoCD_reconcileServerDeployment_keepsAutoscaledReplicas(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	r := makeTestAPIServerReconciler(t)
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.UID = "argocd-uid"
+		a.Spec.Server.Autoscale.Enabled = true
+	})
+
+	assert.NoError(t, r.reconcileServerDeployment(a, false))
+
+	// the HorizontalPodAutoscaler scales the server up
+	deployment := &appsv1.Deployment{}
+	key := types.NamespacedName{Name: "argocd-server", Namespace: testNamespace}
+	assert.NoError(t, r.Client.Get(context.TODO(), key, deployment))
+	deployment.Spec.Replicas = int32Ptr(3)
+	assert.NoError(t, r.Client.Update(context.TODO(), deployment, client.FieldOwner("kube-controller-manager")))
+
+	a.Spec.Server.LogLevel = "debug"
+	assert.NoError(t, r.reconcileServerDeployment(a, false))
+
+	deployment = &appsv1.Deployment{}
+	assert.NoError(t, r.Client.Get(context.TODO(), key, deployment))
+	assert.Equal(t, int32Ptr(3), deployment.Spec.Replicas)
+	assert.Contains(t, deployment.Spec.Template.Spec.Containers[0].Command, "debug")
+}
+
 func TestReconcileArgoCD_reconcileRepoDeployment_loglevel(t *testing.T) {
 	logf.SetLogger(ZapLogger(true))
 
@@ -571,50 +626,39 @@ func TestReconcileArgoCD_reconcileRepoDeployment_missingInitContainers(t *testin
 }
 func TestReconcileArgoCD_reconcileRepoDeployment_unexpectedInitContainer(t *testing.T) {
 	logf.SetLogger(ZapLogger(true))
-	a := makeTestArgoCD()
-	d := &appsv1.Deployment{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "argocd-repo-server",
-			Namespace: testNamespace,
-		},
-		Spec: appsv1.DeploymentSpec{
-			Template: corev1.PodTemplateSpec{
-				Spec: corev1.PodSpec{
-					Containers: []corev1.Container{
-						{
-							Command: []string{"testing"},
-							Image:   "test-image",
-						},
-					},
-					InitContainers: []corev1.Container{
-						{
-							Name:    "unknown",
-							Command: []string{"testing-ic"},
-							Image:   "test-image-ic",
-						},
-					},
-				},
-			},
-		},
-	}
+	r := makeTestAPIServerReconciler(t)
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.UID = "argocd-uid"
+	})
 
-	resObjs := []client.Object{a, d}
-	subresObjs := []client.Object{a, d}
-	runtimeObjs := []runtime.Object{}
-	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
-	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
-	r := makeTestReconciler(cl, sch)
+	assert.NoError(t, r.reconcileRepoDeployment(a, false))
 
-	err := r.reconcileRepoDeployment(a, false)
-	assert.NoError(t, err)
+	// an admission webhook injects a sidecar and an init container into the pods
 	deployment := &appsv1.Deployment{}
-	err = r.Client.Get(context.TODO(), types.NamespacedName{
-		Name:      "argocd-repo-server",
-		Namespace: testNamespace,
-	}, deployment)
-	assert.NoError(t, err)
-	assert.Len(t, deployment.Spec.Template.Spec.InitContainers, 1)
-	assert.Equal(t, deployment.Spec.Template.Spec.InitContainers[0].Name, "copyutil")
+	key := types.NamespacedName{Name: "argocd-repo-server", Namespace: testNamespace}
+	assert.NoError(t, r.Client.Get(context.TODO(), key, deployment))
+	podSpec := &deployment.Spec.Template.Spec
+	podSpec.InitContainers = append(podSpec.InitContainers, corev1.Container{Name: "unknown", Image: "test-image-ic", Command: []string{"testing-ic"}})
+	podSpec.Containers = append(podSpec.Containers, corev1.Container{Name: "proxy", Image: "test-image-proxy"})
+	assert.NoError(t, r.Client.Update(context.TODO(), deployment, client.FieldOwner("admission-webhook")))
+
+	a.Spec.Repo.LogLevel = "debug"
+	assert.NoError(t, r.reconcileRepoDeployment(a, false))
+
+	// the containers added by others are left untouched
+	deployment = &appsv1.Deployment{}
+	assert.NoError(t, r.Client.Get(context.TODO(), key, deployment))
+	names := []string{}
+	for _, c := range deployment.Spec.Template.Spec.InitContainers {
+		names = append(names, c.Name)
+	}
+	assert.ElementsMatch(t, []string{"copyutil", "unknown"}, names)
+	names = []string{}
+	for _, c := range deployment.Spec.Template.Spec.Containers {
+		names = append(names, c.Name)
+	}
+	assert.ElementsMatch(t, []string{"argocd-repo-server", "proxy"}, names)
+	assert.Contains(t, deployment.Spec.Template.Spec.Containers[0].Command, "debug")
 }
 
 func TestReconcileArgoCD_reconcileRepoDeployment_command(t *testing.T) {
@@ -773,7 +817,7 @@ func TestReconcileArgoCD_reconcileDeployments_HA_proxy_with_resources(t *testing
 	r := makeTestReconciler(cl, sch)
 
 	// test resource is Created on reconciliation
-	assert.NoError(t, r.reconcileRedisHAProxyDeployment(a))
+	assert.NoError(t, r.reconcileRedisHAProxyDeployment(a, false))
 
 	deployment := &appsv1.Deployment{}
 	assert.NoError(t, r.Client.Get(
@@ -809,7 +853,7 @@ func TestReconcileArgoCD_reconcileDeployments_HA_proxy_with_resources(t *testing
 		},
 	}
 	a.Spec.HA.Resources = &newResources
-	assert.NoError(t, r.reconcileRedisHAProxyDeployment(a))
+	assert.NoError(t, r.reconcileRedisHAProxyDeployment(a, false))
 
 	assert.NoError(t, r.Client.Get(
 		context.TODO(),
@@ -823,6 +867,35 @@ func TestReconcileArgoCD_reconcileDeployments_HA_proxy_with_resources(t *testing
 	assert.Equal(t, deployment.Spec.Template.Spec.InitContainers[0].Resources, newResources)
 }
 
+func TestReconcileArgoCD_reconcileRedisHAProxyDeployment_replicas(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.HA.Enabled = true
+	})
+
+	resObjs := []client.Object{a}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileRedisHAProxyDeployment(a, false))
+
+	deployment := &appsv1.Deployment{}
+	key := types.NamespacedName{Name: a.Name + "-redis-ha-haproxy", Namespace: a.Namespace}
+	assert.NoError(t, r.Client.Get(context.TODO(), key, deployment))
+	assert.Equal(t, int32(1), *deployment.Spec.Replicas)
+	assert.Equal(t, getRedisHAConfigChecksum(a, false), deployment.Spec.Template.Annotations[common.AnnotationRedisHAConfigChecksum])
+
+	// test replicas and the config checksum are updated on reconciliation
+	a.Spec.HA.RedisProxyReplicas = int32Ptr(3)
+	assert.NoError(t, r.reconcileRedisHAProxyDeployment(a, true))
+	assert.NoError(t, r.Client.Get(context.TODO(), key, deployment))
+	assert.Equal(t, int32(3), *deployment.Spec.Replicas)
+	assert.Equal(t, getRedisHAConfigChecksum(a, true), deployment.Spec.Template.Annotations[common.AnnotationRedisHAConfigChecksum])
+}
+
 func TestReconcileArgoCD_reconcileRepoDeployment_updatesVolumeMounts(t *testing.T) {
 	logf.SetLogger(ZapLogger(true))
 	a := makeTestArgoCD()
@@ -1616,63 +1689,36 @@ func operationProcessors(n int32) argoCDOpt {
 	}
 }
 
-func Test_UpdateNodePlacement(t *testing.T) {
-
-	deployment := &appsv1.Deployment{
+func Test_CarryImageUpgradedLabel(t *testing.T) {
+	existing := corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "argocd-sample-server",
-			Namespace: testNamespace,
-		},
-		Spec: appsv1.DeploymentSpec{
-			Template: corev1.PodTemplateSpec{
-				Spec: corev1.PodSpec{
-					NodeSelector: map[string]string{
-						"test_key1": "test_value1",
-						"test_key2": "test_value2",
-					},
-					Tolerations: []corev1.Toleration{
-						{
-							Key:    "test_key1",
-							Value:  "test_value1",
-							Effect: corev1.TaintEffectNoSchedule,
-						},
-					},
-				},
+			Labels: map[string]string{
+				"image.upgraded": "01022006-150406-UTC",
 			},
 		},
-	}
-	deployment2 := &appsv1.Deployment{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "argocd-sample-server",
-			Namespace: testNamespace,
-		},
-		Spec: appsv1.DeploymentSpec{
-			Template: corev1.PodTemplateSpec{
-				Spec: corev1.PodSpec{
-					NodeSelector: map[string]string{
-						"test_key1": "test_value1",
-					},
-					Tolerations: []corev1.Toleration{
-						{
-							Key:    "test_key1",
-							Value:  "test_value1",
-							Effect: corev1.TaintEffectNoExecute,
-						},
-					},
-				},
-			},
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "copyutil", Image: "argocd:v1"}},
+			Containers:     []corev1.Container{{Name: "dex", Image: "dex:v1"}},
 		},
 	}
-	expectedChange := false
-	actualChange := false
-	updateNodePlacement(deployment, deployment, &actualChange)
-	if actualChange != expectedChange {
-		t.Fatalf("updateNodePlacement failed, value of changed: %t", actualChange)
-	}
-	updateNodePlacement(deployment, deployment2, &actualChange)
-	if actualChange == expectedChange {
-		t.Fatalf("updateNodePlacement failed, value of changed: %t", actualChange)
-	}
+
+	// same images, the label is carried over
+	desired := corev1.PodTemplateSpec{Spec: *existing.Spec.DeepCopy()}
+	carryImageUpgradedLabel(&desired, &existing)
+	assert.Equal(t, "01022006-150406-UTC", desired.Labels["image.upgraded"])
+
+	// the image of an init container changed, the label is refreshed
+	desired = corev1.PodTemplateSpec{Spec: *existing.Spec.DeepCopy()}
+	desired.Spec.InitContainers[0].Image = "argocd:v2"
+	carryImageUpgradedLabel(&desired, &existing)
+	assert.NotEmpty(t, desired.Labels["image.upgraded"])
+	assert.NotEqual(t, "01022006-150406-UTC", desired.Labels["image.upgraded"])
+
+	// no image ever changed, no label is set
+	existing.Labels = nil
+	desired = corev1.PodTemplateSpec{Spec: *existing.Spec.DeepCopy()}
+	carryImageUpgradedLabel(&desired, &existing)
+	assert.NotContains(t, desired.Labels, "image.upgraded")
 }
 
 func assertDeploymentHasProxyVars(t *testing.T, c client.Client, name string) {
@@ -2132,3 +2178,181 @@ func TestReconcileArgoCD_reconcileRepoDeployment_serviceAccount(t *testing.T) {
 		})
 	}
 }
+
+func TestReconcileArgoCD_reconcileRepoDeployment_remoteRedis(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.Redis.RemoteConfig = &argoproj.ArgoCDRemoteRedisSpec{
+			UsernameSecret: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "remote-redis"},
+				Key:                  "username",
+			},
+			PasswordSecret: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "remote-redis"},
+				Key:                  "password",
+			},
+			CAConfigMap: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "remote-redis-ca"},
+				Key:                  "ca.pem",
+			},
+			Sentinel: &argoproj.ArgoCDRemoteRedisSentinelSpec{
+				Addresses:  []string{"sentinel-0:26379", "sentinel-1:26379"},
+				MasterName: "argocd",
+				UsernameSecret: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "remote-redis-sentinel"},
+					Key:                  "username",
+				},
+				PasswordSecret: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "remote-redis-sentinel"},
+					Key:                  "password",
+				},
+			},
+		}
+	})
+
+	resObjs := []client.Object{a}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	assert.True(t, r.redisShouldUseTLS(a))
+	assert.NoError(t, r.reconcileRepoDeployment(a, r.redisShouldUseTLS(a)))
+
+	deployment := &appsv1.Deployment{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "argocd-repo-server", Namespace: a.Namespace}, deployment))
+	container := deployment.Spec.Template.Spec.Containers[0]
+
+	assert.Equal(t, []string{
+		"uid_entrypoint.sh",
+		"argocd-repo-server",
+		"--sentinel", "sentinel-0:26379",
+		"--sentinel", "sentinel-1:26379",
+		"--sentinelmaster", "argocd",
+		"--redis-use-tls",
+		"--redis-ca-certificate", "/app/config/redis/remote/ca.crt",
+		"--loglevel", "info",
+		"--logformat", "text",
+	}, container.Command)
+
+	assert.Contains(t, container.Env, corev1.EnvVar{
+		Name:      "REDIS_PASSWORD",
+		ValueFrom: &corev1.EnvVarSource{SecretKeyRef: a.Spec.Redis.RemoteConfig.PasswordSecret},
+	})
+	assert.Contains(t, container.Env, corev1.EnvVar{
+		Name:      "REDIS_USERNAME",
+		ValueFrom: &corev1.EnvVarSource{SecretKeyRef: a.Spec.Redis.RemoteConfig.UsernameSecret},
+	})
+	assert.Contains(t, container.Env, corev1.EnvVar{
+		Name:      "REDIS_SENTINEL_PASSWORD",
+		ValueFrom: &corev1.EnvVarSource{SecretKeyRef: a.Spec.Redis.RemoteConfig.Sentinel.PasswordSecret},
+	})
+	assert.Contains(t, container.Env, corev1.EnvVar{
+		Name:      "REDIS_SENTINEL_USERNAME",
+		ValueFrom: &corev1.EnvVarSource{SecretKeyRef: a.Spec.Redis.RemoteConfig.Sentinel.UsernameSecret},
+	})
+	assert.Contains(t, container.VolumeMounts, corev1.VolumeMount{
+		Name:      common.ArgoCDRedisRemoteCAVolumeName,
+		MountPath: common.ArgoCDRedisRemoteCAMountPath,
+		ReadOnly:  true,
+	})
+	assert.Contains(t, deployment.Spec.Template.Spec.Volumes, corev1.Volume{
+		Name: common.ArgoCDRedisRemoteCAVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "remote-redis-ca"},
+				Items:                []corev1.KeyToPath{{Key: "ca.pem", Path: "ca.crt"}},
+			},
+		},
+	})
+
+	// Without a CA, TLS connections to the remote Redis rely on the system trust store
+	a.Spec.Redis.RemoteConfig.CAConfigMap = nil
+	a.Spec.Redis.RemoteConfig.TLS = true
+	assert.NoError(t, r.reconcileRepoDeployment(a, r.redisShouldUseTLS(a)))
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "argocd-repo-server", Namespace: a.Namespace}, deployment))
+	assert.Contains(t, deployment.Spec.Template.Spec.Containers[0].Command, "--redis-use-tls")
+	assert.NotContains(t, deployment.Spec.Template.Spec.Containers[0].Command, "--redis-ca-certificate")
+	for _, v := range deployment.Spec.Template.Spec.Volumes {
+		assert.NotEqual(t, common.ArgoCDRedisRemoteCAVolumeName, v.Name)
+	}
+}
+
+func TestReconcileArgoCD_reconcileRepoDeployment_plugins(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.Repo.Plugins = []argoproj.ArgoCDRepoPluginSpec{
+			{
+				Name:  "envsubst",
+				Image: "quay.io/example/envsubst:latest",
+				Spec: argoproj.ArgoCDPluginSpec{
+					Generate: argoproj.ArgoCDPluginCommand{Command: []string{"envsubst"}},
+				},
+			},
+			{
+				// Plugins without an image are skipped
+				Name: "invalid",
+			},
+			{
+				// Plugins whose name is not a valid container name are skipped
+				Name:  "Env.Subst",
+				Image: "quay.io/example/envsubst:latest",
+			},
+			{
+				Name:  strings.Repeat("a", 64),
+				Image: "quay.io/example/envsubst:latest",
+			},
+		}
+	})
+
+	resObjs := []client.Object{a}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileRepoDeployment(a, false))
+
+	deployment := &appsv1.Deployment{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "argocd-repo-server", Namespace: a.Namespace}, deployment))
+
+	containers := deployment.Spec.Template.Spec.Containers
+	assert.Len(t, containers, 2)
+	assert.Equal(t, "envsubst", containers[1].Name)
+	assert.Equal(t, "quay.io/example/envsubst:latest", containers[1].Image)
+	assert.Equal(t, []string{"/var/run/argocd/argocd-cmp-server"}, containers[1].Command)
+	assert.Contains(t, containers[1].VolumeMounts, corev1.VolumeMount{
+		Name:      cmpConfigVolumeName,
+		MountPath: "/home/argocd/cmp-server/config/plugin.yaml",
+		SubPath:   "envsubst.yaml",
+	})
+	assert.Contains(t, containers[1].VolumeMounts, corev1.VolumeMount{
+		Name:      "plugins",
+		MountPath: "/home/argocd/cmp-server/plugins",
+	})
+
+	volumeNames := []string{}
+	for _, v := range deployment.Spec.Template.Spec.Volumes {
+		volumeNames = append(volumeNames, v.Name)
+	}
+	assert.Contains(t, volumeNames, cmpConfigVolumeName)
+	assert.Contains(t, volumeNames, "cmp-tmp")
+
+	checksum := deployment.Spec.Template.Annotations[common.AnnotationCmpConfigChecksum]
+	assert.NotEmpty(t, checksum)
+
+	// A change of the plugin configuration rolls the repo server
+	a.Spec.Repo.Plugins[0].Spec.Generate.Args = []string{"-no-unset"}
+	assert.NoError(t, r.reconcileRepoDeployment(a, false))
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "argocd-repo-server", Namespace: a.Namespace}, deployment))
+	assert.NotEqual(t, checksum, deployment.Spec.Template.Annotations[common.AnnotationCmpConfigChecksum])
+
+	// Removing the plugins removes the sidecars
+	a.Spec.Repo.Plugins = nil
+	assert.NoError(t, r.reconcileRepoDeployment(a, false))
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "argocd-repo-server", Namespace: a.Namespace}, deployment))
+	assert.Len(t, deployment.Spec.Template.Spec.Containers, 1)
+	assert.NotContains(t, deployment.Spec.Template.Annotations, common.AnnotationCmpConfigChecksum)
+}
@@ -4,9 +4,7 @@ import (
 	"context"
 	e "errors"
 	"fmt"
-	"reflect"
 	"strings"
-	"time"
 
 	"gopkg.in/yaml.v2"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -96,38 +94,13 @@ func (r *ReconcileArgoCD) getDexOAuthClientSecret(cr *argoproj.ArgoCD) (*string,
 	return &token, nil
 }
 
-// reconcileDexConfiguration will ensure that Dex is configured properly.
-func (r *ReconcileArgoCD) reconcileDexConfiguration(cm *corev1.ConfigMap, cr *argoproj.ArgoCD) error {
-	actual := cm.Data[common.ArgoCDKeyDexConfig]
-	desired := getDexConfig(cr)
-
+// getDesiredDexConfig will return the dex configuration of argocd-cm for the given ArgoCD.
+func (r *ReconcileArgoCD) getDesiredDexConfig(cr *argoproj.ArgoCD) (string, error) {
 	// Append the default OpenShift dex config if the openShiftOAuth is requested through `.spec.sso.dex`.
 	if cr.Spec.SSO != nil && cr.Spec.SSO.Dex != nil && cr.Spec.SSO.Dex.OpenShiftOAuth {
-		cfg, err := r.getOpenShiftDexConfig(cr)
-		if err != nil {
-			return err
-		}
-		desired = cfg
+		return r.getOpenShiftDexConfig(cr)
 	}
-
-	if actual != desired {
-		// Update ConfigMap with desired configuration.
-		cm.Data[common.ArgoCDKeyDexConfig] = desired
-		if err := r.Client.Update(context.TODO(), cm); err != nil {
-			return err
-		}
-
-		// Trigger rollout of Dex Deployment to pick up changes.
-		deploy := newDeploymentWithSuffix("dex-server", "dex-server", cr)
-		if !argoutil.IsObjectFound(r.Client, deploy.Namespace, deploy.Name, deploy) {
-			log.Info("unable to locate dex deployment")
-			return nil
-		}
-
-		deploy.Spec.Template.ObjectMeta.Labels["dex.config.changed"] = time.Now().UTC().Format("01022006-150406-MST")
-		return r.Client.Update(context.TODO(), deploy)
-	}
-	return nil
+	return getDexConfig(cr), nil
 }
 
 // getOpenShiftDexConfig will return the configuration for the Dex server running on OpenShift.
@@ -223,11 +196,12 @@ func (r *ReconcileArgoCD) reconcileDexServiceAccount(cr *argoproj.ArgoCD) error
 
 // reconcileDexDeployment will ensure the Deployment resource is present for the ArgoCD Dex component.
 func (r *ReconcileArgoCD) reconcileDexDeployment(cr *argoproj.ArgoCD) error {
+	cr = argoCDForUpgradeStep(cr, "dex-server")
 	deploy := newDeploymentWithSuffix("dex-server", "dex-server", cr)
 
-	AddSeccompProfileForOpenShift(r.Client, &deploy.Spec.Template.Spec)
+	AddSeccompProfileForOpenShift(r.Client, r.ClusterAPIs, &deploy.Spec.Template.Spec)
 
-	dexEnv := proxyEnvVars()
+	dexEnv := getProxyEnvVars(cr)
 	if cr.Spec.SSO != nil && cr.Spec.SSO.Dex != nil {
 		dexEnv = append(dexEnv, cr.Spec.SSO.Dex.Env...)
 	}
@@ -285,7 +259,7 @@ func (r *ReconcileArgoCD) reconcileDexDeployment(cr *argoproj.ArgoCD) error {
 			"/usr/local/bin/argocd",
 			"/shared/argocd-dex",
 		},
-		Env:             proxyEnvVars(),
+		Env:             getProxyEnvVars(cr),
 		Image:           getArgoContainerImage(cr),
 		ImagePullPolicy: corev1.PullAlways,
 		Name:            "copyutil",
@@ -312,6 +286,18 @@ func (r *ReconcileArgoCD) reconcileDexDeployment(cr *argoproj.ArgoCD) error {
 			EmptyDir: &corev1.EmptyDirVolumeSource{},
 		},
 	}}
+	addTrustedCA(cr, &deploy.Spec.Template.Spec, getDexContainerImage(cr))
+
+	// Dex reads its configuration from argocd-cm on start only, roll the pods when it changes.
+	if UseDex(cr) {
+		dexConfig, err := r.getDesiredDexConfig(cr)
+		if err != nil {
+			return err
+		}
+		deploy.Spec.Template.ObjectMeta.Annotations = map[string]string{
+			common.AnnotationDexConfigChecksum: getConfigMapDataChecksum(map[string]string{common.ArgoCDKeyDexConfig: dexConfig}),
+		}
+	}
 
 	existing := newDeploymentWithSuffix("dex-server", "dex-server", cr)
 	if argoutil.IsObjectFound(r.Client, cr.Namespace, existing.Name, existing) {
@@ -321,45 +307,7 @@ func (r *ReconcileArgoCD) reconcileDexDeployment(cr *argoproj.ArgoCD) error {
 			log.Info("deleting the existing dex deployment because dex uninstallation has been requested")
 			return r.Client.Delete(context.TODO(), existing)
 		}
-		changed := false
-
-		actualImage := existing.Spec.Template.Spec.Containers[0].Image
-		desiredImage := getDexContainerImage(cr)
-		if actualImage != desiredImage {
-			existing.Spec.Template.Spec.Containers[0].Image = desiredImage
-			existing.Spec.Template.ObjectMeta.Labels["image.upgraded"] = time.Now().UTC().Format("01022006-150406-MST")
-			changed = true
-		}
-
-		actualImage = existing.Spec.Template.Spec.InitContainers[0].Image
-		desiredImage = getArgoContainerImage(cr)
-		if actualImage != desiredImage {
-			existing.Spec.Template.Spec.InitContainers[0].Image = desiredImage
-			existing.Spec.Template.ObjectMeta.Labels["image.upgraded"] = time.Now().UTC().Format("01022006-150406-MST")
-			changed = true
-		}
-		updateNodePlacement(existing, deploy, &changed)
-		if !reflect.DeepEqual(existing.Spec.Template.Spec.Containers[0].Env,
-			deploy.Spec.Template.Spec.Containers[0].Env) {
-			existing.Spec.Template.Spec.Containers[0].Env = deploy.Spec.Template.Spec.Containers[0].Env
-			changed = true
-		}
-
-		if !reflect.DeepEqual(existing.Spec.Template.Spec.InitContainers[0].Env,
-			deploy.Spec.Template.Spec.InitContainers[0].Env) {
-			existing.Spec.Template.Spec.InitContainers[0].Env = deploy.Spec.Template.Spec.InitContainers[0].Env
-			changed = true
-		}
-
-		if !reflect.DeepEqual(deploy.Spec.Template.Spec.Containers[0].Resources, existing.Spec.Template.Spec.Containers[0].Resources) {
-			existing.Spec.Template.Spec.Containers[0].Resources = deploy.Spec.Template.Spec.Containers[0].Resources
-			changed = true
-		}
-
-		if changed {
-			return r.Client.Update(context.TODO(), existing)
-		}
-		return nil // Deployment found with nothing to do, move along...
+		return r.applyDeployment(cr, deploy, existing)
 	}
 
 	// if Dex installation has not been requested, do nothing
@@ -367,12 +315,8 @@ func (r *ReconcileArgoCD) reconcileDexDeployment(cr *argoproj.ArgoCD) error {
 		return nil
 	}
 
-	if err := controllerutil.SetControllerReference(cr, deploy, r.Scheme); err != nil {
-		return err
-	}
-
 	log.Info(fmt.Sprintf("creating deployment %s for Argo CD instance %s in namespace %s", deploy.Name, cr.Name, cr.Namespace))
-	return r.Client.Create(context.TODO(), deploy)
+	return r.applyDeployment(cr, deploy, nil)
 }
 
 // reconcileDexService will ensure that the Service for Dex is present.
@@ -487,17 +431,6 @@ func (r *ReconcileArgoCD) deleteDexResources(cr *argoproj.ArgoCD) error {
 		log.Error(err, "error reconciling dex service")
 	}
 
-	// Reconcile dex config in argocd-cm (right after dex is disabled)
-	// this is required for a one time trigger of reconcileDexConfiguration directly in case of a dex deletion event,
-	// since reconcileArgoConfigMap won't call reconcileDexConfiguration once dex has been disabled (to avoid reconciling on
-	// dexconfig unnecessarily when it isn't enabled)
-	cm := newConfigMapWithName(common.ArgoCDConfigMapName, cr)
-	if argoutil.IsObjectFound(r.Client, cr.Namespace, cm.Name, cm) {
-		if err := r.reconcileDexConfiguration(cm, cr); err != nil {
-			log.Error(err, "error reconciling dex configuration in configmap")
-		}
-	}
-
 	if err := r.reconcileRoleBinding(common.ArgoCDDexServerComponent, policyRuleForDexServer(), cr); err != nil {
 		log.Error(err, "error reconciling dex rolebinding")
 	}
@@ -2,12 +2,12 @@ package argocd
 
 import (
 	"fmt"
-	"os"
 
 	corev1 "k8s.io/api/core/v1"
 
 	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
 	"github.com/argoproj-labs/argocd-operator/common"
+	"github.com/argoproj-labs/argocd-operator/controllers/argocdoperatorconfig"
 	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
 )
 
@@ -45,7 +45,7 @@ func getDexContainerImage(cr *argoproj.ArgoCD) string {
 		tag = common.ArgoCDDefaultDexVersion
 		defaultTag = true
 	}
-	if e := os.Getenv(common.ArgoCDDexImageEnvName); e != "" && (defaultTag && defaultImg) {
+	if e := argocdoperatorconfig.Getenv(common.ArgoCDDexImageEnvName); e != "" && (defaultTag && defaultImg) {
 		return e
 	}
 	return argoutil.CombineImageTag(img, tag)
@@ -994,3 +994,37 @@ func TestReconcileArgoCD_reconcileRoleBinding_dex_disabled(t *testing.T) {
 		})
 	}
 }
+
+func TestReconcileArgoCD_reconcileDexDeployment_rolledOnConfigChange(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(cr *argoproj.ArgoCD) {
+		cr.Spec.SSO = &argoproj.ArgoCDSSOSpec{
+			Provider: argoproj.SSOProviderTypeDex,
+			Dex:      &argoproj.ArgoCDDexSpec{Config: "test-dex-config"},
+		}
+	})
+
+	resObjs := []client.Object{a}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	getChecksum := func() string {
+		deployment := &appsv1.Deployment{}
+		assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "argocd-dex-server", Namespace: a.Namespace}, deployment))
+		return deployment.Spec.Template.Annotations[common.AnnotationDexConfigChecksum]
+	}
+
+	assert.NoError(t, r.reconcileDexDeployment(a))
+	checksum := getChecksum()
+	assert.NotEmpty(t, checksum)
+
+	assert.NoError(t, r.reconcileDexDeployment(a))
+	assert.Equal(t, checksum, getChecksum())
+
+	a.Spec.SSO.Dex.Config = "updated-dex-config"
+	assert.NoError(t, r.reconcileDexDeployment(a))
+	assert.NotEqual(t, checksum, getChecksum())
+}
@@ -0,0 +1,242 @@
+// Copyright 2019 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argocd
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
+	oappsv1 "github.com/openshift/api/apps/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+)
+
+// integration is an optional API the operator integrates with, along with the sub-resources of that API owned by
+// ArgoCD instances.
+type integration struct {
+	name       string
+	available  func(*ClusterAPIs) bool
+	owns       []client.Object
+	predicates []predicate.Predicate
+}
+
+// integrations returns the optional APIs the operator integrates with.
+func integrations() []integration {
+	return []integration{
+		{
+			name:      "route",
+			available: (*ClusterAPIs).IsRouteAPIAvailable,
+			owns:      []client.Object{&routev1.Route{}},
+		},
+		{
+			name:      "gateway",
+			available: (*ClusterAPIs).IsGatewayAPIAvailable,
+			owns:      []client.Object{&gatewayv1.HTTPRoute{}},
+		},
+		{
+			name:      "grpcroute",
+			available: (*ClusterAPIs).IsGRPCRouteAPIAvailable,
+			owns:      []client.Object{&gatewayv1alpha2.GRPCRoute{}},
+		},
+		{
+			name:      "prometheus",
+			available: (*ClusterAPIs).IsPrometheusAPIAvailable,
+			owns:      []client.Object{&monitoringv1.Prometheus{}, &monitoringv1.ServiceMonitor{}},
+		},
+		{
+			name:       "keycloak-template",
+			available:  (*ClusterAPIs).CanUseKeycloakWithTemplate,
+			owns:       []client.Object{&oappsv1.DeploymentConfig{}},
+			predicates: []predicate.Predicate{deploymentConfigPredicate()},
+		},
+		{
+			name:      "openshift-version",
+			available: (*ClusterAPIs).IsVersionAPIAvailable,
+		},
+	}
+}
+
+// activeIntegrations returns, for each integration, whether its API is served by the cluster.
+func (a *ClusterAPIs) activeIntegrations() map[string]bool {
+	active := map[string]bool{}
+	for _, i := range integrations() {
+		active[i.name] = i.available(a)
+	}
+	return active
+}
+
+// recordActiveIntegrations publishes the given integrations through the ActiveIntegrations metric.
+func recordActiveIntegrations(active map[string]bool) {
+	for name, ok := range active {
+		value := 0.0
+		if ok {
+			value = 1
+		}
+		ActiveIntegrations.WithLabelValues(name).Set(value)
+	}
+}
+
+// watcher is the part of a controller used to start watches at runtime.
+type watcher interface {
+	Watch(src source.Source, eventhandler handler.EventHandler, predicates ...predicate.Predicate) error
+}
+
+// apiDiscovery starts the watches of the optional APIs that become served by the cluster while the operator runs, and
+// requeues every ArgoCD instance so that the resources of these APIs get created.
+type apiDiscovery struct {
+	mu      sync.Mutex
+	watched map[string]bool
+
+	// inspect verifies again which optional APIs are served by the cluster.
+	inspect func() error
+
+	controller watcher
+	cache      cache.Cache
+	scheme     *runtime.Scheme
+	mapper     meta.RESTMapper
+
+	// requeue carries the ArgoCD instances to reconcile once an integration changed.
+	requeue chan event.GenericEvent
+}
+
+// newAPIDiscovery returns a new apiDiscovery verifying the given ClusterAPIs.
+func newAPIDiscovery(apis *ClusterAPIs) *apiDiscovery {
+	return &apiDiscovery{
+		watched: map[string]bool{},
+		inspect: apis.Inspect,
+		requeue: make(chan event.GenericEvent),
+	}
+}
+
+// own registers, on the given builder, the watches of the integrations available at startup.
+func (d *apiDiscovery) own(bldr *builder.Builder, apis *ClusterAPIs) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, i := range integrations() {
+		if !i.available(apis) {
+			continue
+		}
+		for _, obj := range i.owns {
+			bldr.Owns(obj, builder.WithPredicates(i.predicates...))
+		}
+		d.watched[i.name] = true
+	}
+}
+
+// watch starts the watches of the integrations that became available since the controller was built. Watches are
+// never stopped, the watches of an API removed from the cluster stay in place until the operator restarts.
+func (d *apiDiscovery) watch(apis *ClusterAPIs) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, i := range integrations() {
+		if d.watched[i.name] || !i.available(apis) {
+			continue
+		}
+		for _, obj := range i.owns {
+			ownerHandler := handler.EnqueueRequestForOwner(d.scheme, d.mapper, &argoproj.ArgoCD{}, handler.OnlyControllerOwner())
+			if err := d.controller.Watch(source.Kind(d.cache, obj), ownerHandler, i.predicates...); err != nil {
+				return fmt.Errorf("failed to watch %T for integration %s: %w", obj, i.name, err)
+			}
+		}
+		d.watched[i.name] = true
+		log.Info(fmt.Sprintf("started watches for integration %s", i.name))
+	}
+	return nil
+}
+
+// discoverAPIs verifies again, every interval, which optional APIs are served by the cluster until ctx is done.
+func (r *ReconcileArgoCD) discoverAPIs(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := r.rediscoverAPIs(ctx); err != nil {
+				log.Error(err, "failed to discover the optional APIs served by the cluster")
+			}
+		}
+	}
+}
+
+// rediscoverAPIs verifies again which optional APIs are served by the cluster. When an integration changed, the
+// watches of the new integrations are started and every ArgoCD instance is requeued, as the reconcilers of each
+// integration check the availability of its API on every reconciliation.
+func (r *ReconcileArgoCD) rediscoverAPIs(ctx context.Context) error {
+	before := r.ClusterAPIs.activeIntegrations()
+	if err := r.discovery.inspect(); err != nil {
+		return err
+	}
+	after := r.ClusterAPIs.activeIntegrations()
+	recordActiveIntegrations(after)
+
+	changed := false
+	for _, i := range integrations() {
+		if after[i.name] == before[i.name] {
+			continue
+		}
+		changed = true
+		if after[i.name] {
+			log.Info(fmt.Sprintf("integration %s is now active", i.name))
+		} else {
+			log.Info(fmt.Sprintf("integration %s is no longer active", i.name))
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	if err := r.discovery.watch(r.ClusterAPIs); err != nil {
+		return err
+	}
+	return r.RequeueInstances(ctx)
+}
+
+// RequeueInstances enqueues a reconciliation of every ArgoCD instance, such as when the optional APIs served by the
+// cluster or the configuration of the operator changed.
+func (r *ReconcileArgoCD) RequeueInstances(ctx context.Context) error {
+	argocds := &argoproj.ArgoCDList{}
+	if err := r.Client.List(ctx, argocds); err != nil {
+		return err
+	}
+
+	for i := range argocds.Items {
+		select {
+		case r.discovery.requeue <- event.GenericEvent{Object: &argocds.Items[i]}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package argocd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+)
+
+// fakeWatcher counts the watches started at runtime.
+type fakeWatcher struct {
+	watches int
+}
+
+func (w *fakeWatcher) Watch(src source.Source, eventhandler handler.EventHandler, predicates ...predicate.Predicate) error {
+	w.watches++
+	return nil
+}
+
+func TestReconcileArgoCD_rediscoverAPIs(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD()
+
+	resObjs := []client.Object{a}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	w := &fakeWatcher{}
+	r.discovery = newAPIDiscovery(r.ClusterAPIs)
+	r.discovery.controller = w
+	r.discovery.scheme = sch
+	prometheusInstalled := false
+	r.discovery.inspect = func() error {
+		r.ClusterAPIs.setPrometheusAPIAvailable(prometheusInstalled)
+		return nil
+	}
+
+	// nothing changed, no watch is started and no instance is requeued
+	assert.NoError(t, r.rediscoverAPIs(context.TODO()))
+	assert.Equal(t, 0, w.watches)
+	assert.Equal(t, float64(0), testutil.ToFloat64(ActiveIntegrations.WithLabelValues("prometheus")))
+
+	// Prometheus got installed, its sub-resources are watched and the instances requeued
+	prometheusInstalled = true
+	errs := make(chan error)
+	go func() {
+		errs <- r.rediscoverAPIs(context.TODO())
+	}()
+	var requeued event.GenericEvent
+	select {
+	case requeued = <-r.discovery.requeue:
+	case err := <-errs:
+		t.Fatalf("instance was not requeued: %v", err)
+	}
+	assert.NoError(t, <-errs)
+	assert.Equal(t, a.Name, requeued.Object.GetName())
+	assert.Equal(t, a.Namespace, requeued.Object.GetNamespace())
+	assert.Equal(t, 2, w.watches)
+	assert.Equal(t, float64(1), testutil.ToFloat64(ActiveIntegrations.WithLabelValues("prometheus")))
+
+	// the watches are started only once
+	prometheusInstalled = false
+	go func() {
+		errs <- r.rediscoverAPIs(context.TODO())
+	}()
+	<-r.discovery.requeue
+	assert.NoError(t, <-errs)
+	prometheusInstalled = true
+	go func() {
+		errs <- r.rediscoverAPIs(context.TODO())
+	}()
+	<-r.discovery.requeue
+	assert.NoError(t, <-errs)
+	assert.Equal(t, 2, w.watches)
+}
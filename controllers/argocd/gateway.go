@@ -0,0 +1,439 @@
+// Copyright 2019 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argocd
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
+	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
+)
+
+// IsGatewayAPIAvailable returns true if the Gateway API, and with it the HTTPRoute API, is present.
+func (a *ClusterAPIs) IsGatewayAPIAvailable() bool {
+	return a.isAvailable(func(a *ClusterAPIs) bool { return a.gateway })
+}
+
+// IsGRPCRouteAPIAvailable returns true if the Gateway API GRPCRoute API is present.
+func (a *ClusterAPIs) IsGRPCRouteAPIAvailable() bool {
+	return a.isAvailable(func(a *ClusterAPIs) bool { return a.grpcRoute })
+}
+
+// setGatewayAPIAvailable records whether the Gateway API and its GRPCRoute API are present.
+func (a *ClusterAPIs) setGatewayAPIAvailable(gatewayFound, grpcRouteFound bool) {
+	a.setAvailable(func(a *ClusterAPIs) {
+		a.gateway = gatewayFound
+		a.grpcRoute = grpcRouteFound
+	})
+}
+
+// verifyGatewayAPI will verify that the Gateway API is present. GRPCRoute is only reported as present when the
+// v1alpha2 group/version serves grpcroutes, as the standard channel serves v1alpha2 for ReferenceGrant only.
+func (a *ClusterAPIs) verifyGatewayAPI() error {
+	gatewayFound, err := argoutil.VerifyAPI(gatewayv1.GroupName, gatewayv1.GroupVersion.Version)
+	if err != nil {
+		return err
+	}
+
+	grpcRouteFound, err := argoutil.VerifyAPIResource(gatewayv1alpha2.GroupName, gatewayv1alpha2.GroupVersion.Version, "grpcroutes")
+	if err != nil {
+		return err
+	}
+	a.setGatewayAPIAvailable(gatewayFound, grpcRouteFound)
+	return nil
+}
+
+// newHTTPRouteWithSuffix returns a new HTTPRoute with the given name suffix for the ArgoCD.
+func newHTTPRouteWithSuffix(suffix string, cr *argoproj.ArgoCD) *gatewayv1.HTTPRoute {
+	name := fmt.Sprintf("%s-%s", cr.Name, suffix)
+	lbls := argoutil.LabelsForCluster(cr)
+	lbls[common.ArgoCDKeyName] = name
+	return &gatewayv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: cr.Namespace,
+			Labels:    lbls,
+		},
+	}
+}
+
+// newGRPCRouteWithSuffix returns a new GRPCRoute with the given name suffix for the ArgoCD.
+func newGRPCRouteWithSuffix(suffix string, cr *argoproj.ArgoCD) *gatewayv1alpha2.GRPCRoute {
+	name := fmt.Sprintf("%s-%s", cr.Name, suffix)
+	lbls := argoutil.LabelsForCluster(cr)
+	lbls[common.ArgoCDKeyName] = name
+	return &gatewayv1alpha2.GRPCRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: cr.Namespace,
+			Labels:    lbls,
+		},
+	}
+}
+
+// reconcileGatewayRoutes will ensure that all ArgoCD Gateway API routes are present.
+func (r *ReconcileArgoCD) reconcileGatewayRoutes(cr *argoproj.ArgoCD) error {
+	if err := r.reconcileArgoServerHTTPRoute(cr); err != nil {
+		return err
+	}
+
+	if r.ClusterAPIs.IsGRPCRouteAPIAvailable() {
+		if err := r.reconcileArgoServerGRPCRoute(cr); err != nil {
+			return err
+		}
+	}
+
+	if err := r.reconcileApplicationSetControllerHTTPRoute(cr); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// reconcileArgoServerHTTPRoute will ensure that the ArgoCD Server HTTPRoute is present.
+func (r *ReconcileArgoCD) reconcileArgoServerHTTPRoute(cr *argoproj.ArgoCD) error {
+	spec := cr.Spec.Server.HTTPRoute
+	enabled := cr.Spec.Server.IsEnabled() && spec.Enabled
+	hosts := []string{}
+	if len(cr.Spec.Server.Host) > 0 {
+		hosts = append(hosts, cr.Spec.Server.Host)
+	}
+	hosts = append(hosts, cr.Spec.Server.AdditionalHosts...)
+
+	routeSpec, err := getHTTPRouteSpec(spec, hosts, getPathOrDefault(spec.Path), nameWithSuffix("server", cr), 80)
+	if err != nil {
+		return err
+	}
+	desired := newHTTPRouteWithSuffix("server", cr)
+	desired.Spec = routeSpec
+	return r.reconcileHTTPRoute(cr, desired, spec, enabled)
+}
+
+// reconcileApplicationSetControllerHTTPRoute will ensure that the ApplicationSet webhook HTTPRoute is present.
+func (r *ReconcileArgoCD) reconcileApplicationSetControllerHTTPRoute(cr *argoproj.ArgoCD) error {
+	spec := argoproj.ArgoCDGatewayRouteSpec{}
+	hosts := []string{}
+	if cr.Spec.ApplicationSet != nil {
+		spec = cr.Spec.ApplicationSet.WebhookServer.HTTPRoute
+		if len(cr.Spec.ApplicationSet.WebhookServer.Host) > 0 {
+			hosts = append(hosts, cr.Spec.ApplicationSet.WebhookServer.Host)
+		}
+	}
+
+	path := "/api/webhook"
+	if len(spec.Path) > 0 {
+		path = spec.Path
+	}
+
+	routeSpec, err := getHTTPRouteSpec(spec, hosts, path, nameWithSuffix(common.ApplicationSetServiceNameSuffix, cr), 7000)
+	if err != nil {
+		return err
+	}
+	desired := newHTTPRouteWithSuffix(common.ApplicationSetServiceNameSuffix, cr)
+	desired.Spec = routeSpec
+	return r.reconcileHTTPRoute(cr, desired, spec, cr.Spec.ApplicationSet != nil && spec.Enabled)
+}
+
+// reconcileArgoServerGRPCRoute will ensure that the ArgoCD Server GRPCRoute is present.
+func (r *ReconcileArgoCD) reconcileArgoServerGRPCRoute(cr *argoproj.ArgoCD) error {
+	spec := cr.Spec.Server.GRPC.GRPCRoute
+	enabled := cr.Spec.Server.IsEnabled() && spec.Enabled
+
+	hosts := []string{}
+	if len(cr.Spec.Server.GRPC.Host) > 0 {
+		hosts = append(hosts, cr.Spec.Server.GRPC.Host)
+	}
+	hostnames, err := getGatewayHostnames(hosts)
+	if err != nil {
+		return err
+	}
+
+	desired := newGRPCRouteWithSuffix("grpc", cr)
+	desired.Annotations = spec.Annotations
+	for k, v := range spec.Labels {
+		desired.Labels[k] = v
+	}
+	desired.Spec = gatewayv1alpha2.GRPCRouteSpec{
+		CommonRouteSpec: gatewayv1.CommonRouteSpec{
+			ParentRefs: getGatewayParentRefs(spec),
+		},
+		Hostnames: hostnames,
+		Rules: []gatewayv1alpha2.GRPCRouteRule{
+			{
+				BackendRefs: []gatewayv1alpha2.GRPCBackendRef{
+					{
+						BackendRef: getGatewayBackendRef(nameWithSuffix("server", cr), 80),
+					},
+				},
+			},
+		},
+	}
+
+	existing := newGRPCRouteWithSuffix("grpc", cr)
+	if argoutil.IsObjectFound(r.Client, cr.Namespace, existing.Name, existing) {
+		if !enabled {
+			// GRPCRoute exists but enabled flag has been set to false, delete the GRPCRoute
+			return r.Client.Delete(context.TODO(), existing)
+		}
+
+		changed := false
+		if !reflect.DeepEqual(existing.Spec, desired.Spec) {
+			existing.Spec = desired.Spec
+			changed = true
+		}
+		if !reflect.DeepEqual(existing.Annotations, desired.Annotations) {
+			existing.Annotations = desired.Annotations
+			changed = true
+		}
+		if !reflect.DeepEqual(existing.Labels, desired.Labels) {
+			existing.Labels = desired.Labels
+			changed = true
+		}
+		if changed {
+			return r.Client.Update(context.TODO(), existing)
+		}
+		return nil // GRPCRoute found and up to date, do nothing
+	}
+
+	if !enabled {
+		return nil // GRPCRoute not enabled, move along...
+	}
+
+	if err := controllerutil.SetControllerReference(cr, desired, r.Scheme); err != nil {
+		return err
+	}
+	log.Info(fmt.Sprintf("creating GRPCRoute %s", desired.Name))
+	return r.Client.Create(context.TODO(), desired)
+}
+
+// reconcileHTTPRoute will ensure that the given HTTPRoute is present and up to date when enabled, and deleted
+// otherwise.
+func (r *ReconcileArgoCD) reconcileHTTPRoute(cr *argoproj.ArgoCD, desired *gatewayv1.HTTPRoute, spec argoproj.ArgoCDGatewayRouteSpec, enabled bool) error {
+	desired.Annotations = spec.Annotations
+	for k, v := range spec.Labels {
+		desired.Labels[k] = v
+	}
+
+	existing := &gatewayv1.HTTPRoute{}
+	if argoutil.IsObjectFound(r.Client, cr.Namespace, desired.Name, existing) {
+		if !enabled {
+			// HTTPRoute exists but enabled flag has been set to false, delete the HTTPRoute
+			return r.Client.Delete(context.TODO(), existing)
+		}
+
+		changed := false
+		if !reflect.DeepEqual(existing.Spec, desired.Spec) {
+			existing.Spec = desired.Spec
+			changed = true
+		}
+		if !reflect.DeepEqual(existing.Annotations, desired.Annotations) {
+			existing.Annotations = desired.Annotations
+			changed = true
+		}
+		if !reflect.DeepEqual(existing.Labels, desired.Labels) {
+			existing.Labels = desired.Labels
+			changed = true
+		}
+		if changed {
+			return r.Client.Update(context.TODO(), existing)
+		}
+		return nil // HTTPRoute found and up to date, do nothing
+	}
+
+	if !enabled {
+		return nil // HTTPRoute not enabled, move along...
+	}
+
+	if err := controllerutil.SetControllerReference(cr, desired, r.Scheme); err != nil {
+		return err
+	}
+	log.Info(fmt.Sprintf("creating HTTPRoute %s", desired.Name))
+	return r.Client.Create(context.TODO(), desired)
+}
+
+// getHTTPRouteSpec will return the HTTPRoute spec routing the given path prefix to the given Service port.
+func getHTTPRouteSpec(spec argoproj.ArgoCDGatewayRouteSpec, hosts []string, path, service string, port int32) (gatewayv1.HTTPRouteSpec, error) {
+	hostnames, err := getGatewayHostnames(hosts)
+	if err != nil {
+		return gatewayv1.HTTPRouteSpec{}, err
+	}
+
+	pathType := gatewayv1.PathMatchPathPrefix
+	return gatewayv1.HTTPRouteSpec{
+		CommonRouteSpec: gatewayv1.CommonRouteSpec{
+			ParentRefs: getGatewayParentRefs(spec),
+		},
+		Hostnames: hostnames,
+		Rules: []gatewayv1.HTTPRouteRule{
+			{
+				Matches: []gatewayv1.HTTPRouteMatch{
+					{
+						Path: &gatewayv1.HTTPPathMatch{
+							Type:  &pathType,
+							Value: &path,
+						},
+					},
+				},
+				BackendRefs: []gatewayv1.HTTPBackendRef{
+					{
+						BackendRef: getGatewayBackendRef(service, port),
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+// getGatewayParentRefs will return the Gateway API parent references of the given route spec.
+func getGatewayParentRefs(spec argoproj.ArgoCDGatewayRouteSpec) []gatewayv1.ParentReference {
+	refs := make([]gatewayv1.ParentReference, 0, len(spec.ParentRefs))
+	for _, ref := range spec.ParentRefs {
+		group := gatewayv1.Group(gatewayv1.GroupName)
+		kind := gatewayv1.Kind("Gateway")
+		parent := gatewayv1.ParentReference{
+			Group: &group,
+			Kind:  &kind,
+			Name:  gatewayv1.ObjectName(ref.Name),
+		}
+		if len(ref.Namespace) > 0 {
+			ns := gatewayv1.Namespace(ref.Namespace)
+			parent.Namespace = &ns
+		}
+		if len(ref.SectionName) > 0 {
+			section := gatewayv1.SectionName(ref.SectionName)
+			parent.SectionName = &section
+		}
+		if ref.Port != nil {
+			port := gatewayv1.PortNumber(*ref.Port)
+			parent.Port = &port
+		}
+		refs = append(refs, parent)
+	}
+	return refs
+}
+
+// getGatewayHostnames will return the route hostnames for the given hosts, shortened to valid hostnames. Routes
+// without a host match every hostname accepted by the listeners of their parent Gateways.
+func getGatewayHostnames(hosts []string) ([]gatewayv1.Hostname, error) {
+	if len(hosts) == 0 {
+		return nil, nil
+	}
+	hostnames := make([]gatewayv1.Hostname, 0, len(hosts))
+	for _, host := range hosts {
+		hostname, err := shortenHostname(host)
+		if err != nil {
+			return nil, err
+		}
+		hostnames = append(hostnames, gatewayv1.Hostname(hostname))
+	}
+	return hostnames, nil
+}
+
+// getGatewayBackendRef will return a reference to the given port of the given Service. The defaults of the Gateway
+// API are set explicitly so that the stored route compares equal to the desired one.
+func getGatewayBackendRef(service string, port int32) gatewayv1.BackendRef {
+	group := gatewayv1.Group("")
+	kind := gatewayv1.Kind("Service")
+	portNumber := gatewayv1.PortNumber(port)
+	weight := int32(1)
+	return gatewayv1.BackendRef{
+		BackendObjectReference: gatewayv1.BackendObjectReference{
+			Group: &group,
+			Kind:  &kind,
+			Name:  gatewayv1.ObjectName(service),
+			Port:  &portNumber,
+		},
+		Weight: &weight,
+	}
+}
+
+// isGatewayRouteAccepted will return true if at least one parent Gateway accepted the route with the given status.
+func isGatewayRouteAccepted(status gatewayv1.RouteStatus) bool {
+	for _, parent := range status.Parents {
+		if cond := meta.FindStatusCondition(parent.Conditions, string(gatewayv1.RouteConditionAccepted)); cond != nil && cond.Status == metav1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// getArgoServerHTTPRouteHost will return the hostname the Argo CD Server is reachable at through its HTTPRoute: the
+// Host of the server when set, otherwise the hostnames of the parent Gateway listeners, otherwise the addresses of
+// the parent Gateways. An empty string is returned while no Gateway accepted the route.
+func (r *ReconcileArgoCD) getArgoServerHTTPRouteHost(cr *argoproj.ArgoCD) (string, error) {
+	route := newHTTPRouteWithSuffix("server", cr)
+	if !argoutil.IsObjectFound(r.Client, cr.Namespace, route.Name, route) {
+		return "", fmt.Errorf("argocd-server HTTPRoute requested but not found on cluster")
+	}
+
+	if !isGatewayRouteAccepted(route.Status.RouteStatus) {
+		return "", nil
+	}
+
+	if len(cr.Spec.Server.Host) > 0 {
+		return shortenHostname(cr.Spec.Server.Host)
+	}
+
+	var hosts []string
+	for _, ref := range cr.Spec.Server.HTTPRoute.ParentRefs {
+		ns := ref.Namespace
+		if len(ns) == 0 {
+			ns = cr.Namespace
+		}
+		gateway := &gatewayv1.Gateway{}
+		if err := r.Client.Get(context.TODO(), types.NamespacedName{Namespace: ns, Name: ref.Name}, gateway); err != nil {
+			log.Info(fmt.Sprintf("unable to get Gateway %s/%s: %v", ns, ref.Name, err))
+			continue
+		}
+		hosts = append(hosts, getGatewayHosts(gateway, ref)...)
+	}
+	return strings.Join(hosts, ", "), nil
+}
+
+// getGatewayHosts will return the hostnames of the listeners of the given Gateway the given reference attaches
+// to, or the addresses of the Gateway when these listeners accept any hostname.
+func getGatewayHosts(gateway *gatewayv1.Gateway, ref argoproj.ArgoCDGatewayParentReference) []string {
+	var hosts []string
+	for _, listener := range gateway.Spec.Listeners {
+		if len(ref.SectionName) > 0 && string(listener.Name) != ref.SectionName {
+			continue
+		}
+		if ref.Port != nil && int32(listener.Port) != *ref.Port {
+			continue
+		}
+		if listener.Hostname != nil && len(*listener.Hostname) > 0 {
+			hosts = append(hosts, string(*listener.Hostname))
+		}
+	}
+	if len(hosts) > 0 {
+		return hosts
+	}
+
+	for _, address := range gateway.Status.Addresses {
+		hosts = append(hosts, address.Value)
+	}
+	return hosts
+}
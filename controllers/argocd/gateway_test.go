@@ -0,0 +1,192 @@
+package argocd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+)
+
+func TestReconcileArgoCD_reconcileArgoServerHTTPRoute(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.Server.HTTPRoute = argoproj.ArgoCDGatewayRouteSpec{
+			Enabled:    true,
+			ParentRefs: []argoproj.ArgoCDGatewayParentReference{{Name: "shared", Namespace: "gateways", SectionName: "https"}},
+		}
+	})
+
+	resObjs := []client.Object{a}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme, gatewayv1.Install)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileArgoServerHTTPRoute(a))
+
+	route := &gatewayv1.HTTPRoute{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: testArgoCDName + "-server", Namespace: testNamespace}, route))
+	assert.Len(t, route.Spec.ParentRefs, 1)
+	assert.Equal(t, gatewayv1.ObjectName("shared"), route.Spec.ParentRefs[0].Name)
+	assert.Equal(t, gatewayv1.Namespace("gateways"), *route.Spec.ParentRefs[0].Namespace)
+	assert.Equal(t, gatewayv1.SectionName("https"), *route.Spec.ParentRefs[0].SectionName)
+	assert.Empty(t, route.Spec.Hostnames)
+	assert.Equal(t, "/", *route.Spec.Rules[0].Matches[0].Path.Value)
+	assert.Equal(t, gatewayv1.ObjectName(testArgoCDName+"-server"), route.Spec.Rules[0].BackendRefs[0].Name)
+	assert.Equal(t, gatewayv1.PortNumber(80), *route.Spec.Rules[0].BackendRefs[0].Port)
+
+	// Setting the server host updates the route hostnames
+	a.Spec.Server.Host = "argocd.example.com"
+	a.Spec.Server.HTTPRoute.Labels = map[string]string{"gateway": "shared"}
+	assert.NoError(t, r.reconcileArgoServerHTTPRoute(a))
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: testArgoCDName + "-server", Namespace: testNamespace}, route))
+	assert.Equal(t, []gatewayv1.Hostname{"argocd.example.com"}, route.Spec.Hostnames)
+	assert.Equal(t, "shared", route.Labels["gateway"])
+
+	// Disabling the route deletes it
+	a.Spec.Server.HTTPRoute.Enabled = false
+	assert.NoError(t, r.reconcileArgoServerHTTPRoute(a))
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: testArgoCDName + "-server", Namespace: testNamespace}, route)
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestReconcileArgoCD_reconcileArgoServerGRPCRoute(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.Server.GRPC.Host = "grpc.argocd.example.com"
+		a.Spec.Server.GRPC.GRPCRoute = argoproj.ArgoCDGatewayRouteSpec{
+			Enabled:    true,
+			ParentRefs: []argoproj.ArgoCDGatewayParentReference{{Name: "shared"}},
+		}
+	})
+
+	resObjs := []client.Object{a}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme, gatewayv1.Install, gatewayv1alpha2.Install)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileArgoServerGRPCRoute(a))
+
+	route := &gatewayv1alpha2.GRPCRoute{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: testArgoCDName + "-grpc", Namespace: testNamespace}, route))
+	assert.Equal(t, []gatewayv1.Hostname{"grpc.argocd.example.com"}, route.Spec.Hostnames)
+	assert.Equal(t, gatewayv1.ObjectName(testArgoCDName+"-server"), route.Spec.Rules[0].BackendRefs[0].Name)
+
+	a.Spec.Server.GRPC.GRPCRoute.Enabled = false
+	assert.NoError(t, r.reconcileArgoServerGRPCRoute(a))
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: testArgoCDName + "-grpc", Namespace: testNamespace}, route)
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestReconcileArgoCD_reconcileApplicationSetControllerHTTPRoute(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.ApplicationSet = &argoproj.ArgoCDApplicationSet{
+			WebhookServer: argoproj.WebhookServerSpec{
+				Host: "webhook.example.com",
+				HTTPRoute: argoproj.ArgoCDGatewayRouteSpec{
+					Enabled:    true,
+					ParentRefs: []argoproj.ArgoCDGatewayParentReference{{Name: "shared"}},
+				},
+			},
+		}
+	})
+
+	resObjs := []client.Object{a}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme, gatewayv1.Install)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileApplicationSetControllerHTTPRoute(a))
+
+	route := &gatewayv1.HTTPRoute{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: testArgoCDName + "-applicationset-controller", Namespace: testNamespace}, route))
+	assert.Equal(t, []gatewayv1.Hostname{"webhook.example.com"}, route.Spec.Hostnames)
+	assert.Equal(t, "/api/webhook", *route.Spec.Rules[0].Matches[0].Path.Value)
+	assert.Equal(t, gatewayv1.PortNumber(7000), *route.Spec.Rules[0].BackendRefs[0].Port)
+
+	a.Spec.ApplicationSet = nil
+	assert.NoError(t, r.reconcileApplicationSetControllerHTTPRoute(a))
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: testArgoCDName + "-applicationset-controller", Namespace: testNamespace}, route)
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestReconcileArgoCD_reconcileStatusHost_httpRoute(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+
+	hostname := gatewayv1.Hostname("*.apps.example.com")
+	gateway := &gatewayv1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "gateways"},
+		Spec: gatewayv1.GatewaySpec{
+			Listeners: []gatewayv1.Listener{
+				{Name: "http", Port: 80, Protocol: gatewayv1.HTTPProtocolType},
+				{Name: "https", Port: 443, Protocol: gatewayv1.HTTPSProtocolType, Hostname: &hostname},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		host     string
+		accepted bool
+		expected string
+	}{
+		{name: "server host is reported", host: "argocd.example.com", accepted: true, expected: "argocd.example.com"},
+		{name: "gateway listener hostname is reported", accepted: true, expected: "*.apps.example.com"},
+		{name: "nothing is reported until accepted", host: "argocd.example.com", accepted: false, expected: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+				a.Spec.Server.Host = test.host
+				a.Spec.Server.HTTPRoute = argoproj.ArgoCDGatewayRouteSpec{
+					Enabled:    true,
+					ParentRefs: []argoproj.ArgoCDGatewayParentReference{{Name: "shared", Namespace: "gateways", SectionName: "https"}},
+				}
+			})
+
+			route := newHTTPRouteWithSuffix("server", a)
+			status := metav1.ConditionFalse
+			if test.accepted {
+				status = metav1.ConditionTrue
+			}
+			route.Status.Parents = []gatewayv1.RouteParentStatus{{
+				ParentRef:      gatewayv1.ParentReference{Name: "shared"},
+				ControllerName: "example.com/gateway-controller",
+				Conditions: []metav1.Condition{{
+					Type:   string(gatewayv1.RouteConditionAccepted),
+					Status: status,
+				}},
+			}}
+
+			resObjs := []client.Object{a, gateway, route}
+			subresObjs := []client.Object{a}
+			runtimeObjs := []runtime.Object{}
+			sch := makeTestReconcilerScheme(argoproj.AddToScheme, gatewayv1.Install)
+			cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+			r := makeTestReconciler(cl, sch)
+			r.ClusterAPIs.setGatewayAPIAvailable(true, false)
+
+			assert.NoError(t, r.reconcileStatusHost(a))
+			assert.Equal(t, test.expected, a.Status.Host)
+			if !test.accepted {
+				assert.Equal(t, "Pending", a.Status.Phase)
+			}
+		})
+	}
+}
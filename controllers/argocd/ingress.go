@@ -17,6 +17,7 @@ package argocd
 import (
 	"context"
 	"fmt"
+	"reflect"
 
 	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -92,43 +93,31 @@ func (r *ReconcileArgoCD) reconcileIngresses(cr *argoproj.ArgoCD) error {
 // reconcileArgoServerIngress will ensure that the ArgoCD Server Ingress is present.
 func (r *ReconcileArgoCD) reconcileArgoServerIngress(cr *argoproj.ArgoCD) error {
 	ingress := newIngressWithSuffix("server", cr)
-	if argoutil.IsObjectFound(r.Client, cr.Namespace, ingress.Name, ingress) {
-		if !cr.Spec.Server.Ingress.Enabled {
-			// Ingress exists but enabled flag has been set to false, delete the Ingress
-			return r.Client.Delete(context.TODO(), ingress)
-		}
-
-		// If Ingress found and enabled, make sure the ingressClassName is up-to-date
-		if ingress.Spec.IngressClassName != cr.Spec.Server.Ingress.IngressClassName {
-			ingress.Spec.IngressClassName = cr.Spec.Server.Ingress.IngressClassName
-			return r.Client.Update(context.TODO(), ingress)
-		}
-		return nil // Ingress found and enabled, do nothing
+	found := argoutil.IsObjectFound(r.Client, cr.Namespace, ingress.Name, ingress)
+	if found && !cr.Spec.Server.Ingress.Enabled {
+		// Ingress exists but enabled flag has been set to false, delete the Ingress
+		return r.Client.Delete(context.TODO(), ingress)
 	}
 
 	if !cr.Spec.Server.Ingress.Enabled {
 		return nil // Ingress not enabled, move along...
 	}
 
-	// Add default annotations
-	atns := make(map[string]string)
-	atns[common.ArgoCDKeyIngressSSLRedirect] = "true"
-	atns[common.ArgoCDKeyIngressBackendProtocol] = "HTTP"
-
-	// Override default annotations if specified
-	if len(cr.Spec.Server.Ingress.Annotations) > 0 {
-		atns = cr.Spec.Server.Ingress.Annotations
+	host, err := getArgoServerHost(cr)
+	if err != nil {
+		return err
 	}
-
-	ingress.ObjectMeta.Annotations = atns
-
-	ingress.Spec.IngressClassName = cr.Spec.Server.Ingress.IngressClassName
+	additionalHosts, err := getArgoServerAdditionalHosts(cr)
+	if err != nil {
+		return err
+	}
+	hosts := append([]string{host}, additionalHosts...)
 
 	pathType := networkingv1.PathTypeImplementationSpecific
-	// Add rules
-	ingress.Spec.Rules = []networkingv1.IngressRule{
-		{
-			Host: getArgoServerHost(cr),
+	rules := make([]networkingv1.IngressRule, 0, len(hosts))
+	for _, host := range hosts {
+		rules = append(rules, networkingv1.IngressRule{
+			Host: host,
 			IngressRuleValue: networkingv1.IngressRuleValue{
 				HTTP: &networkingv1.HTTPIngressRuleValue{
 					Paths: []networkingv1.HTTPIngressPath{
@@ -147,24 +136,59 @@ func (r *ReconcileArgoCD) reconcileArgoServerIngress(cr *argoproj.ArgoCD) error
 					},
 				},
 			},
-		},
+		})
 	}
 
 	// Add default TLS options
-	ingress.Spec.TLS = []networkingv1.IngressTLS{
+	tls := []networkingv1.IngressTLS{
 		{
-			Hosts: []string{
-				getArgoServerHost(cr),
-			},
+			Hosts:      hosts,
 			SecretName: common.ArgoCDSecretName,
 		},
 	}
 
 	// Allow override of TLS options if specified
 	if len(cr.Spec.Server.Ingress.TLS) > 0 {
-		ingress.Spec.TLS = cr.Spec.Server.Ingress.TLS
+		tls = cr.Spec.Server.Ingress.TLS
+	}
+
+	if found {
+		// If Ingress found and enabled, make sure the ingressClassName, rules and TLS options are up-to-date
+		changed := false
+		if !reflect.DeepEqual(ingress.Spec.IngressClassName, cr.Spec.Server.Ingress.IngressClassName) {
+			ingress.Spec.IngressClassName = cr.Spec.Server.Ingress.IngressClassName
+			changed = true
+		}
+		if !reflect.DeepEqual(ingress.Spec.Rules, rules) {
+			ingress.Spec.Rules = rules
+			changed = true
+		}
+		if !reflect.DeepEqual(ingress.Spec.TLS, tls) {
+			ingress.Spec.TLS = tls
+			changed = true
+		}
+		if changed {
+			return r.Client.Update(context.TODO(), ingress)
+		}
+		return nil // Ingress found and up-to-date, do nothing
 	}
 
+	// Add default annotations
+	atns := make(map[string]string)
+	atns[common.ArgoCDKeyIngressSSLRedirect] = "true"
+	atns[common.ArgoCDKeyIngressBackendProtocol] = "HTTP"
+
+	// Override default annotations if specified
+	if len(cr.Spec.Server.Ingress.Annotations) > 0 {
+		atns = cr.Spec.Server.Ingress.Annotations
+	}
+
+	ingress.ObjectMeta.Annotations = atns
+
+	ingress.Spec.IngressClassName = cr.Spec.Server.Ingress.IngressClassName
+	ingress.Spec.Rules = rules
+	ingress.Spec.TLS = tls
+
 	if err := controllerutil.SetControllerReference(cr, ingress, r.Scheme); err != nil {
 		return err
 	}
@@ -199,11 +223,16 @@ func (r *ReconcileArgoCD) reconcileArgoServerGRPCIngress(cr *argoproj.ArgoCD) er
 
 	ingress.Spec.IngressClassName = cr.Spec.Server.GRPC.Ingress.IngressClassName
 
+	host, err := getArgoServerGRPCHost(cr)
+	if err != nil {
+		return err
+	}
+
 	pathType := networkingv1.PathTypeImplementationSpecific
 	// Add rules
 	ingress.Spec.Rules = []networkingv1.IngressRule{
 		{
-			Host: getArgoServerGRPCHost(cr),
+			Host: host,
 			IngressRuleValue: networkingv1.IngressRuleValue{
 				HTTP: &networkingv1.HTTPIngressRuleValue{
 					Paths: []networkingv1.HTTPIngressPath{
@@ -229,7 +258,7 @@ func (r *ReconcileArgoCD) reconcileArgoServerGRPCIngress(cr *argoproj.ArgoCD) er
 	ingress.Spec.TLS = []networkingv1.IngressTLS{
 		{
 			Hosts: []string{
-				getArgoServerGRPCHost(cr),
+				host,
 			},
 			SecretName: common.ArgoCDSecretName,
 		},
@@ -296,11 +325,16 @@ func (r *ReconcileArgoCD) reconcilePrometheusIngress(cr *argoproj.ArgoCD) error
 
 	ingress.Spec.IngressClassName = cr.Spec.Prometheus.Ingress.IngressClassName
 
+	host, err := getPrometheusHost(cr)
+	if err != nil {
+		return err
+	}
+
 	pathType := networkingv1.PathTypeImplementationSpecific
 	// Add rules
 	ingress.Spec.Rules = []networkingv1.IngressRule{
 		{
-			Host: getPrometheusHost(cr),
+			Host: host,
 			IngressRuleValue: networkingv1.IngressRuleValue{
 				HTTP: &networkingv1.HTTPIngressRuleValue{
 					Paths: []networkingv1.HTTPIngressPath{
@@ -2,6 +2,7 @@ package argocd
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -226,3 +227,43 @@ func TestReconcileApplicationSetService_Ingress(t *testing.T) {
 	assert.NoError(t, r.reconcileApplicationSetControllerIngress(a))
 	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}, ingress))
 }
+
+func TestReconcileArgoCD_reconcile_ServerIngress_additionalHosts(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.Server.Ingress.Enabled = true
+		a.Spec.Server.Host = "argocd.example.com"
+		a.Spec.Server.AdditionalHosts = []string{"argocd.internal.example.com"}
+	})
+
+	resObjs := []client.Object{a}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileArgoServerIngress(a))
+
+	ingress := &networkingv1.Ingress{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "argocd-server", Namespace: testNamespace}, ingress))
+	assert.Len(t, ingress.Spec.Rules, 2)
+	assert.Equal(t, "argocd.example.com", ingress.Spec.Rules[0].Host)
+	assert.Equal(t, "argocd.internal.example.com", ingress.Spec.Rules[1].Host)
+	assert.Equal(t, []string{"argocd.example.com", "argocd.internal.example.com"}, ingress.Spec.TLS[0].Hosts)
+
+	// Changing the additional hosts updates the existing Ingress
+	a.Spec.Server.AdditionalHosts = nil
+	assert.NoError(t, r.reconcileArgoServerIngress(a))
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "argocd-server", Namespace: testNamespace}, ingress))
+	assert.Len(t, ingress.Spec.Rules, 1)
+	assert.Equal(t, []string{"argocd.example.com"}, ingress.Spec.TLS[0].Hosts)
+
+	// Additional hosts are shortened like every other generated host
+	a.Spec.Server.AdditionalHosts = []string{strings.Repeat("a", 70) + "." + strings.Repeat(strings.Repeat("b", 60)+".", 3) + "example.com"}
+	assert.NoError(t, r.reconcileArgoServerIngress(a))
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "argocd-server", Namespace: testNamespace}, ingress))
+	assert.Len(t, ingress.Spec.Rules, 2)
+	assert.Equal(t, strings.Repeat("a", 58)+"."+strings.Repeat(strings.Repeat("b", 60)+".", 3)+"example.com", ingress.Spec.Rules[1].Host)
+}
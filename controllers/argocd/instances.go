@@ -0,0 +1,171 @@
+// Copyright 2019 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argocd
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
+)
+
+// configurationConflictRequeueInterval is how often an ArgoCD whose configuration is controlled by another ArgoCD
+// checks whether the conflict was resolved, as the deletion of the other ArgoCD does not trigger its reconciliation.
+const configurationConflictRequeueInterval = time.Minute
+
+// instanceTracker keeps track of the running Argo CD instances using their namespace/name as key and phase as value,
+// for the performance metrics purposes. It is safe for concurrent use.
+type instanceTracker struct {
+	mu     sync.Mutex
+	phases map[string]string
+}
+
+func newInstanceTracker() *instanceTracker {
+	return &instanceTracker{phases: make(map[string]string)}
+}
+
+// observe records the phase of the given ArgoCD, and updates the active instance counts when it changed.
+func (t *instanceTracker) observe(cr *argoproj.ArgoCD) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := instanceKey(cr)
+	newPhase := cr.Status.Phase
+	oldPhase, ok := t.phases[key]
+	if !ok {
+		// If we discover a previously un-seen Argo CD instance
+		// we add it to the map and increment active instance count by phase
+		// as well as total active instance count
+		if newPhase != "" {
+			t.phases[key] = newPhase
+			ActiveInstancesByPhase.WithLabelValues(newPhase).Inc()
+			ActiveInstancesTotal.Inc()
+		}
+	} else if oldPhase != newPhase {
+		// If we discover an existing instance's phase has changed since we last saw it
+		// increment instance count with new phase and decrement instance count with old phase
+		// update the phase in corresponding map entry
+		// total instance count remains the same
+		t.phases[key] = newPhase
+		ActiveInstancesByPhase.WithLabelValues(newPhase).Inc()
+		ActiveInstancesByPhase.WithLabelValues(oldPhase).Dec()
+	}
+}
+
+// forget removes the given ArgoCD, and decrements the active instance counts by phase as well as total.
+func (t *instanceTracker) forget(cr *argoproj.ArgoCD) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := instanceKey(cr)
+	if oldPhase, ok := t.phases[key]; ok {
+		delete(t.phases, key)
+		ActiveInstancesByPhase.WithLabelValues(oldPhase).Dec()
+		ActiveInstancesTotal.Dec()
+	}
+}
+
+// phase returns the phase recorded for the ArgoCD with the given key.
+func (t *instanceTracker) phase(key string) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	phase, ok := t.phases[key]
+	return phase, ok
+}
+
+// instanceKey returns the key identifying the given ArgoCD in the bookkeeping of the operator.
+func instanceKey(cr *argoproj.ArgoCD) string {
+	return types.NamespacedName{Namespace: cr.Namespace, Name: cr.Name}.String()
+}
+
+// getArgoCDRequestsInNamespace returns a reconcile request for each ArgoCD in the given namespace.
+func (r *ReconcileArgoCD) getArgoCDRequestsInNamespace(ctx context.Context, namespace string) []reconcile.Request {
+	var result = []reconcile.Request{}
+
+	argocds := &argoproj.ArgoCDList{}
+	if err := r.Client.List(ctx, argocds, &client.ListOptions{Namespace: namespace}); err != nil {
+		log.Error(err, fmt.Sprintf("failed to list ArgoCD instances in namespace %s", namespace))
+		return result
+	}
+
+	for _, argocd := range argocds.Items {
+		result = append(result, reconcile.Request{
+			NamespacedName: client.ObjectKey{Name: argocd.Name, Namespace: argocd.Namespace},
+		})
+	}
+	return result
+}
+
+// getArgoCDOwnerRequest returns a reconcile request for the ArgoCD controlling the given object, if any.
+func getArgoCDOwnerRequest(o client.Object) (reconcile.Request, bool) {
+	owner := metav1.GetControllerOf(o)
+	if owner == nil || owner.Kind != "ArgoCD" {
+		return reconcile.Request{}, false
+	}
+	return reconcile.Request{
+		NamespacedName: client.ObjectKey{Name: owner.Name, Namespace: o.GetNamespace()},
+	}, true
+}
+
+// hasOtherArgoCDInNamespace returns true if the namespace of the given ArgoCD contains another ArgoCD.
+func (r *ReconcileArgoCD) hasOtherArgoCDInNamespace(cr *argoproj.ArgoCD) (bool, error) {
+	argocds := &argoproj.ArgoCDList{}
+	if err := r.Client.List(context.TODO(), argocds, &client.ListOptions{Namespace: cr.Namespace}); err != nil {
+		return false, err
+	}
+	for _, argocd := range argocds.Items {
+		if argocd.Name != cr.Name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// getConfigurationConflict returns a message naming the configuration object of the namespace of the given ArgoCD that
+// is controlled by another ArgoCD, or an empty message. The Argo CD components read their configuration from the
+// hard-coded argocd-cm, argocd-rbac-cm and argocd-secret, a single ArgoCD of the namespace may therefore control them.
+func (r *ReconcileArgoCD) getConfigurationConflict(cr *argoproj.ArgoCD) (string, error) {
+	for _, obj := range []struct {
+		kind   string
+		object client.Object
+	}{
+		{"ConfigMap", &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: common.ArgoCDConfigMapName}}},
+		{"ConfigMap", &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: common.ArgoCDRBACConfigMapName}}},
+		{"Secret", &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: common.ArgoCDSecretName}}},
+	} {
+		name := obj.object.GetName()
+		if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: cr.Namespace}, obj.object); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return "", err
+		}
+		owner := metav1.GetControllerOf(obj.object)
+		if owner != nil && owner.Kind == "ArgoCD" && !metav1.IsControlledBy(obj.object, cr) {
+			return fmt.Sprintf("%s %s is controlled by ArgoCD %s", obj.kind, name, owner.Name), nil
+		}
+	}
+	return "", nil
+}
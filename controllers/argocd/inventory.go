@@ -0,0 +1,396 @@
+// Copyright 2019 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argocd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
+)
+
+// maxDriftFields is the maximum number of fields listed by the event reporting a drift correction.
+const maxDriftFields = 10
+
+// inventoryClient is a client that keeps the inventory of the objects written for an ArgoCD, and reports the drift it
+// corrects: an apply of the same desired state as the one last applied that still changes the object reverts a change
+// made by someone else. It also tracks the objects read or written during the reconciliation, which are the ones the
+// pruning keeps. The ArgoCD itself, the Namespaces and the Events are not inventoried, and neither are the dry-run
+// changes. It is safe for concurrent use.
+type inventoryClient struct {
+	client.Client
+
+	cr *argoproj.ArgoCD
+
+	mu        sync.Mutex
+	inventory map[string]argoproj.ArgoCDManagedResource
+	seen      map[string]bool
+}
+
+// newInventoryClient returns an inventoryClient starting from the inventory of the status of the given ArgoCD.
+func newInventoryClient(c client.Client, cr *argoproj.ArgoCD) *inventoryClient {
+	inventory := make(map[string]argoproj.ArgoCDManagedResource, len(cr.Status.Inventory))
+	for _, resource := range cr.Status.Inventory {
+		inventory[inventoryKey(resource.Kind, resource.Namespace, resource.Name)] = resource
+	}
+	return &inventoryClient{
+		Client:    c,
+		cr:        cr,
+		inventory: inventory,
+		seen:      make(map[string]bool),
+	}
+}
+
+func inventoryKey(kind, namespace, name string) string {
+	return fmt.Sprintf("%s/%s/%s", kind, namespace, name)
+}
+
+// inventoried returns the kind of the given object, and whether its changes are inventoried.
+func (c *inventoryClient) inventoried(obj client.Object, dryRun []string) (string, bool) {
+	switch obj.(type) {
+	case *argoproj.ArgoCD, *corev1.Namespace, *corev1.Event:
+		return "", false
+	}
+	if len(dryRun) > 0 {
+		return "", false
+	}
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return "", false
+	}
+	return gvk.Kind, true
+}
+
+// get returns the inventoried hash of the given object, if any.
+func (c *inventoryClient) get(kind string, obj client.Object) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	resource, ok := c.inventory[inventoryKey(kind, obj.GetNamespace(), obj.GetName())]
+	return resource.Hash, ok
+}
+
+// set inventories the given object with the given hash.
+func (c *inventoryClient) set(kind string, obj client.Object, hash string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := inventoryKey(kind, obj.GetNamespace(), obj.GetName())
+	c.seen[key] = true
+	c.inventory[key] = argoproj.ArgoCDManagedResource{
+		Kind:      kind,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		Hash:      hash,
+	}
+}
+
+// wasSeen returns true when the given object was read or written during the reconciliation.
+func (c *inventoryClient) wasSeen(kind string, obj client.Object) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.seen[inventoryKey(kind, obj.GetNamespace(), obj.GetName())]
+}
+
+// getInventory returns the inventory, sorted by kind, namespace and name.
+func (c *inventoryClient) getInventory() []argoproj.ArgoCDManagedResource {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	inventory := make([]argoproj.ArgoCDManagedResource, 0, len(c.inventory))
+	for _, resource := range c.inventory {
+		inventory = append(inventory, resource)
+	}
+	sort.Slice(inventory, func(i, j int) bool {
+		if inventory[i].Kind != inventory[j].Kind {
+			return inventory[i].Kind < inventory[j].Kind
+		}
+		if inventory[i].Namespace != inventory[j].Namespace {
+			return inventory[i].Namespace < inventory[j].Namespace
+		}
+		return inventory[i].Name < inventory[j].Name
+	})
+	return inventory
+}
+
+// Get gets the given object and marks it as seen.
+func (c *inventoryClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	if err := c.Client.Get(ctx, key, obj, opts...); err != nil {
+		return err
+	}
+	if kind, ok := c.inventoried(obj, nil); ok {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.seen[inventoryKey(kind, obj.GetNamespace(), obj.GetName())] = true
+	}
+	return nil
+}
+
+// Create creates the given object and inventories it with the hash of the created object, as the updates of the
+// object hold the fields set on its creation by the API server as well.
+func (c *inventoryClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	createOpts := &client.CreateOptions{}
+	createOpts.ApplyOptions(opts)
+	kind, ok := c.inventoried(obj, createOpts.DryRun)
+	if !ok {
+		return c.Client.Create(ctx, obj, opts...)
+	}
+	if err := c.Client.Create(ctx, obj, opts...); err != nil {
+		return err
+	}
+	hash, err := contentHash(obj)
+	if err != nil {
+		return err
+	}
+	c.set(kind, obj, hash)
+	return nil
+}
+
+// Update updates the given object and inventories it with the hash of the updated object. An update writing the same
+// object as the one last written that changes the object is reported as a drift correction.
+func (c *inventoryClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	updateOpts := &client.UpdateOptions{}
+	updateOpts.ApplyOptions(opts)
+	kind, ok := c.inventoried(obj, updateOpts.DryRun)
+	if !ok {
+		return c.Client.Update(ctx, obj, opts...)
+	}
+	hash, err := contentHash(obj)
+	if err != nil {
+		return err
+	}
+	var live client.Object
+	if lastHash, found := c.get(kind, obj); found && lastHash == hash {
+		if live, err = getLiveObject(ctx, c.Client, obj); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	if err := c.Client.Update(ctx, obj, opts...); err != nil {
+		return err
+	}
+	if hash, err = contentHash(obj); err != nil {
+		return err
+	}
+	c.set(kind, obj, hash)
+
+	if live != nil {
+		if err := c.reportDriftCorrection(kind, live, obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Patch patches the given object and inventories it. An apply patch of the desired state last applied that changes
+// the object is reported as a drift correction.
+func (c *inventoryClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	patchOpts := &client.PatchOptions{}
+	patchOpts.ApplyOptions(opts)
+	kind, ok := c.inventoried(obj, patchOpts.DryRun)
+	if !ok || patch.Type() != client.Apply.Type() {
+		// the other patches hold only part of the object, they leave the inventory as it is
+		return c.Client.Patch(ctx, obj, patch, opts...)
+	}
+
+	hash, err := contentHash(obj)
+	if err != nil {
+		return err
+	}
+	var live client.Object
+	if lastHash, found := c.get(kind, obj); found && lastHash == hash {
+		if live, err = getLiveObject(ctx, c.Client, obj); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	if err := c.Client.Patch(ctx, obj, patch, opts...); err != nil {
+		return err
+	}
+	c.set(kind, obj, hash)
+
+	if live != nil {
+		if err := c.reportDriftCorrection(kind, live, obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete deletes the given object and removes it from the inventory.
+func (c *inventoryClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	deleteOpts := &client.DeleteOptions{}
+	deleteOpts.ApplyOptions(opts)
+	kind, ok := c.inventoried(obj, deleteOpts.DryRun)
+	if err := c.Client.Delete(ctx, obj, opts...); err != nil {
+		return err
+	}
+	if ok {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		key := inventoryKey(kind, obj.GetNamespace(), obj.GetName())
+		delete(c.inventory, key)
+		delete(c.seen, key)
+	}
+	return nil
+}
+
+// inventoryOf returns the inventoryClient the given client is or wraps, if any.
+func inventoryOf(c client.Client) *inventoryClient {
+	switch c := c.(type) {
+	case *inventoryClient:
+		return c
+	case *guardedClient:
+		return inventoryOf(c.Client)
+	}
+	return nil
+}
+
+// reportDriftCorrection reports the fields of the given object that were reverted to their desired state, if any,
+// through an event and the drift corrections metric.
+func (c *inventoryClient) reportDriftCorrection(kind string, live, corrected client.Object) error {
+	liveContent, err := inventoryComparable(live)
+	if err != nil {
+		return err
+	}
+	correctedContent, err := inventoryComparable(corrected)
+	if err != nil {
+		return err
+	}
+	fields := changedFields("", liveContent, correctedContent)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	component := string(componentOf(c.cr, corrected.GetName()))
+	if component == "" {
+		component = "other"
+	}
+	DriftCorrectionsTotal.WithLabelValues(c.cr.Namespace, c.cr.Name, component).Inc()
+
+	summary := strings.Join(fields, ", ")
+	if len(fields) > maxDriftFields {
+		summary = fmt.Sprintf("%s and %d more", strings.Join(fields[:maxDriftFields], ", "), len(fields)-maxDriftFields)
+	}
+	message := fmt.Sprintf("%s %s was changed outside of the operator, reverted %s", kind, corrected.GetName(), summary)
+	log.Info(fmt.Sprintf("%s of ArgoCD %s/%s", message, c.cr.Namespace, c.cr.Name))
+	if corrected.GetNamespace() == "" {
+		return nil
+	}
+	gvk, err := apiutil.GVKForObject(corrected, c.Scheme())
+	if err != nil {
+		return err
+	}
+	objectMeta := metav1.ObjectMeta{Name: corrected.GetName(), Namespace: corrected.GetNamespace(), UID: corrected.GetUID(), Labels: corrected.GetLabels()}
+	typeMeta := metav1.TypeMeta{Kind: kind, APIVersion: gvk.GroupVersion().String()}
+	if err := argoutil.CreateEvent(c.Client, corev1.EventTypeWarning, "Reconcile", message, "DriftCorrected", objectMeta, typeMeta); err != nil {
+		log.Error(err, fmt.Sprintf("failed to report the drift correction of %s %s/%s", kind, corrected.GetNamespace(), corrected.GetName()))
+	}
+	return nil
+}
+
+// getLiveObject returns the persisted state of the given object.
+func getLiveObject(ctx context.Context, c client.Client, obj client.Object) (client.Object, error) {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return nil, err
+	}
+	live, err := c.Scheme().New(gvk)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(obj), live.(client.Object)); err != nil {
+		return nil, err
+	}
+	return live.(client.Object), nil
+}
+
+// inventoryComparable returns the content of the given object, without the metadata maintained by the API server and
+// the status, as well as the image.upgraded label of the pod templates, which is refreshed when the images of the
+// existing workload differ from the desired ones.
+func inventoryComparable(obj client.Object) (map[string]interface{}, error) {
+	content, err := driftComparable(obj)
+	if err != nil {
+		return nil, err
+	}
+	unstructured.RemoveNestedField(content, "spec", "template", "metadata", "labels", "image.upgraded")
+	return content, nil
+}
+
+// contentHash returns the SHA-256 hash of the content of the given object, as compared by the inventory.
+func contentHash(obj client.Object) (string, error) {
+	content, err := inventoryComparable(obj)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(content)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(data)), nil
+}
+
+// changedFields returns the paths of the fields that differ between before and after, sorted.
+func changedFields(path string, before, after interface{}) []string {
+	if reflect.DeepEqual(before, after) {
+		return nil
+	}
+	var fields []string
+	switch b := before.(type) {
+	case map[string]interface{}:
+		a, ok := after.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := map[string]bool{}
+		for k := range b {
+			keys[k] = true
+		}
+		for k := range a {
+			keys[k] = true
+		}
+		for k := range keys {
+			fields = append(fields, changedFields(joinFieldPath(path, k), b[k], a[k])...)
+		}
+		sort.Strings(fields)
+		return fields
+	case []interface{}:
+		a, ok := after.([]interface{})
+		if !ok || len(a) != len(b) {
+			break
+		}
+		for i := range b {
+			fields = append(fields, changedFields(fmt.Sprintf("%s[%d]", path, i), b[i], a[i])...)
+		}
+		return fields
+	}
+	return []string{path}
+}
+
+func joinFieldPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,192 @@
+// Copyright 2019 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argocd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
+)
+
+func findInventory(inventory []argoproj.ArgoCDManagedResource, kind, name string) *argoproj.ArgoCDManagedResource {
+	for i := range inventory {
+		if inventory[i].Kind == kind && inventory[i].Name == name {
+			return &inventory[i]
+		}
+	}
+	return nil
+}
+
+func getDriftCorrectedEvents(t *testing.T, r *ReconcileArgoCD) []corev1.Event {
+	events := &corev1.EventList{}
+	assert.NoError(t, r.Client.List(context.TODO(), events, client.InNamespace(testNamespace)))
+	var corrected []corev1.Event
+	for _, event := range events.Items {
+		if event.Reason == "DriftCorrected" {
+			corrected = append(corrected, event)
+		}
+	}
+	return corrected
+}
+
+func TestReconcileArgoCD_Reconcile_inventory(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Name = "inventory"
+	})
+	r, req := makeTestStrategyReconciler(t, a)
+
+	_, err := r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+
+	assert.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, a))
+	server := findInventory(a.Status.Inventory, "Deployment", "inventory-server")
+	if assert.NotNil(t, server) {
+		assert.Equal(t, testNamespace, server.Namespace)
+		assert.Len(t, server.Hash, 64)
+	}
+	assert.NotNil(t, findInventory(a.Status.Inventory, "ConfigMap", "argocd-cm"))
+	assert.NotNil(t, findInventory(a.Status.Inventory, "Secret", "inventory-cluster"))
+	assert.Nil(t, findInventory(a.Status.Inventory, "ArgoCD", "inventory"))
+
+	// reconciling again corrects nothing
+	_, err = r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+	assert.Empty(t, getDriftCorrectedEvents(t, r))
+	assert.Equal(t, float64(0), testutil.ToFloat64(DriftCorrectionsTotal.WithLabelValues(testNamespace, "inventory", "server")))
+
+	// a change to the spec is not a drift
+	assert.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, a))
+	a.Spec.Repo.Replicas = int32Ptr(2)
+	assert.NoError(t, r.Client.Update(context.TODO(), a))
+	_, err = r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+	assert.Empty(t, getDriftCorrectedEvents(t, r))
+	assert.Equal(t, float64(0), testutil.ToFloat64(DriftCorrectionsTotal.WithLabelValues(testNamespace, "inventory", "repo-server")))
+
+	// a change made by someone else is reverted and reported
+	wantImage := getTestDeploymentImage(t, r, "inventory-server")
+	setTestDeploymentImage(t, r, "inventory-server", "manual:latest")
+	_, err = r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+
+	assert.Equal(t, wantImage, getTestDeploymentImage(t, r, "inventory-server"))
+	assert.Equal(t, float64(1), testutil.ToFloat64(DriftCorrectionsTotal.WithLabelValues(testNamespace, "inventory", "server")))
+	events := getDriftCorrectedEvents(t, r)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, corev1.EventTypeWarning, events[0].Type)
+		assert.Equal(t, "Deployment", events[0].InvolvedObject.Kind)
+		assert.Equal(t, "inventory-server", events[0].InvolvedObject.Name)
+		assert.Contains(t, events[0].Message, "spec.template.spec.containers[0].image")
+		assert.NotContains(t, events[0].Message, "image.upgraded")
+	}
+}
+
+func TestChangedFields(t *testing.T) {
+	before := map[string]interface{}{
+		"data": map[string]interface{}{"a": "1", "b": "2"},
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"name": "foo", "image": "foo:1"},
+			},
+		},
+		"kept": "yes",
+	}
+	after := map[string]interface{}{
+		"data": map[string]interface{}{"a": "1", "c": "3"},
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"name": "foo", "image": "foo:2"},
+			},
+		},
+		"kept": "yes",
+	}
+
+	assert.Equal(t, []string{"data.b", "data.c", "spec.containers[0].image"}, changedFields("", before, after))
+	assert.Empty(t, changedFields("", before, before))
+	assert.Equal(t, []string{"spec.containers"}, changedFields("", before, map[string]interface{}{
+		"data": map[string]interface{}{"a": "1", "b": "2"},
+		"spec": map[string]interface{}{"containers": []interface{}{}},
+		"kept": "yes",
+	}))
+}
+
+func TestReconcileArgoCD_Reconcile_inventoryUpdatedObjects(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Name = "inventory-updated"
+	})
+	r, req := makeTestStrategyReconciler(t, a)
+
+	_, err := r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+	_, err = r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+	assert.Empty(t, getDriftCorrectedEvents(t, r))
+
+	// a change to an object the operator updates is reverted and reported
+	policy := &networkingv1.NetworkPolicy{}
+	key := types.NamespacedName{Name: "inventory-updated-redis-network-policy", Namespace: testNamespace}
+	assert.NoError(t, r.Client.Get(context.TODO(), key, policy))
+	wantPolicyTypes := policy.Spec.PolicyTypes
+	policy.Spec.PolicyTypes = []networkingv1.PolicyType{networkingv1.PolicyTypeEgress}
+	assert.NoError(t, r.Client.Update(context.TODO(), policy))
+
+	_, err = r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+
+	assert.NoError(t, r.Client.Get(context.TODO(), key, policy))
+	assert.Equal(t, wantPolicyTypes, policy.Spec.PolicyTypes)
+	events := getDriftCorrectedEvents(t, r)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, "NetworkPolicy", events[0].InvolvedObject.Kind)
+		assert.Contains(t, events[0].Message, "spec.policyTypes")
+	}
+
+	// and so is an entry added to argocd-cm by someone else
+	cm := &corev1.ConfigMap{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: common.ArgoCDConfigMapName, Namespace: testNamespace}, cm))
+	cm.Data["ping"] = "pong"
+	assert.NoError(t, r.Client.Update(context.TODO(), cm))
+
+	_, err = r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: common.ArgoCDConfigMapName, Namespace: testNamespace}, cm))
+	assert.NotContains(t, cm.Data, "ping")
+	events = getDriftCorrectedEvents(t, r)
+	assert.Len(t, events, 2)
+	var cmEvent *corev1.Event
+	for i := range events {
+		if events[i].InvolvedObject.Kind == "ConfigMap" {
+			cmEvent = &events[i]
+		}
+	}
+	if assert.NotNil(t, cmEvent) {
+		assert.Equal(t, common.ArgoCDConfigMapName, cmEvent.InvolvedObject.Name)
+		assert.Contains(t, cmEvent.Message, "data.ping")
+	}
+	assert.Equal(t, float64(1), testutil.ToFloat64(DriftCorrectionsTotal.WithLabelValues(testNamespace, "inventory-updated", "other")))
+}
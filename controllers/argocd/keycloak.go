@@ -20,10 +20,10 @@ import (
 	b64 "encoding/base64"
 	json "encoding/json"
 	"fmt"
-	"os"
 
 	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
 	"github.com/argoproj-labs/argocd-operator/common"
+	"github.com/argoproj-labs/argocd-operator/controllers/argocdoperatorconfig"
 	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
 
 	appsv1 "github.com/openshift/api/apps/v1"
@@ -99,7 +99,7 @@ var (
 // that if the spec is not configured.
 // 3. the default is configured in common.ArgoCDKeycloakVersion and
 // common.ArgoCDKeycloakImageName.
-func getKeycloakContainerImage(cr *argoproj.ArgoCD) string {
+func getKeycloakContainerImage(cr *argoproj.ArgoCD, apis *ClusterAPIs) string {
 	defaultImg, defaultTag := false, false
 
 	img := ""
@@ -111,7 +111,7 @@ func getKeycloakContainerImage(cr *argoproj.ArgoCD) string {
 
 	if img == "" {
 		img = common.ArgoCDKeycloakImage
-		if CanUseKeycloakWithTemplate() {
+		if apis.CanUseKeycloakWithTemplate() {
 			img = common.ArgoCDKeycloakImageForOpenShift
 		}
 		defaultImg = true
@@ -123,12 +123,12 @@ func getKeycloakContainerImage(cr *argoproj.ArgoCD) string {
 
 	if tag == "" {
 		tag = common.ArgoCDKeycloakVersion
-		if CanUseKeycloakWithTemplate() {
+		if apis.CanUseKeycloakWithTemplate() {
 			tag = common.ArgoCDKeycloakVersionForOpenShift
 		}
 		defaultTag = true
 	}
-	if e := os.Getenv(common.ArgoCDKeycloakImageEnvName); e != "" && (defaultTag && defaultImg) {
+	if e := argocdoperatorconfig.Getenv(common.ArgoCDKeycloakImageEnvName); e != "" && (defaultTag && defaultImg) {
 		return e
 	}
 	return argoutil.CombineImageTag(img, tag)
@@ -196,7 +196,7 @@ func getKeycloakResources(cr *argoproj.ArgoCD) corev1.ResourceRequirements {
 	return resources
 }
 
-func getKeycloakContainer(cr *argoproj.ArgoCD) corev1.Container {
+func getKeycloakContainer(cr *argoproj.ArgoCD, apis *ClusterAPIs) corev1.Container {
 	envVars := []corev1.EnvVar{
 		{Name: "SSO_HOSTNAME", Value: "${SSO_HOSTNAME}"},
 		{Name: "DB_MIN_POOL_SIZE", Value: "${DB_MIN_POOL_SIZE}"},
@@ -213,8 +213,8 @@ func getKeycloakContainer(cr *argoproj.ArgoCD) corev1.Container {
 	}
 
 	return corev1.Container{
-		Env:             proxyEnvVars(envVars...),
-		Image:           getKeycloakContainerImage(cr),
+		Env:             getProxyEnvVars(cr, envVars...),
+		Image:           getKeycloakContainerImage(cr, apis),
 		ImagePullPolicy: "Always",
 		LivenessProbe: &corev1.Probe{
 			TimeoutSeconds: 240,
@@ -270,10 +270,10 @@ func getKeycloakContainer(cr *argoproj.ArgoCD) corev1.Container {
 	}
 }
 
-func getKeycloakDeploymentConfigTemplate(cr *argoproj.ArgoCD) *appsv1.DeploymentConfig {
+func getKeycloakDeploymentConfigTemplate(cr *argoproj.ArgoCD, apis *ClusterAPIs) *appsv1.DeploymentConfig {
 	ns := cr.Namespace
 	var medium corev1.StorageMedium = "Memory"
-	keycloakContainer := getKeycloakContainer(cr)
+	keycloakContainer := getKeycloakContainer(cr, apis)
 
 	dc := &appsv1.DeploymentConfig{
 		ObjectMeta: metav1.ObjectMeta{
@@ -415,8 +415,8 @@ func getKeycloakRouteTemplate(ns string, cr argoproj.ArgoCD) *routev1.Route {
 	}
 }
 
-func newKeycloakTemplateInstance(cr *argoproj.ArgoCD) (*template.TemplateInstance, error) {
-	tpl, err := newKeycloakTemplate(cr)
+func newKeycloakTemplateInstance(cr *argoproj.ArgoCD, apis *ClusterAPIs) (*template.TemplateInstance, error) {
+	tpl, err := newKeycloakTemplate(cr, apis)
 	if err != nil {
 		return nil, err
 	}
@@ -431,12 +431,12 @@ func newKeycloakTemplateInstance(cr *argoproj.ArgoCD) (*template.TemplateInstanc
 	}, nil
 }
 
-func newKeycloakTemplate(cr *argoproj.ArgoCD) (template.Template, error) {
+func newKeycloakTemplate(cr *argoproj.ArgoCD, apis *ClusterAPIs) (template.Template, error) {
 	ns := cr.Namespace
 	tmpl := template.Template{}
 	configMapTemplate := getKeycloakConfigMapTemplate(ns)
 	secretTemplate := getKeycloakSecretTemplate(ns)
-	deploymentConfigTemplate := getKeycloakDeploymentConfigTemplate(cr)
+	deploymentConfigTemplate := getKeycloakDeploymentConfigTemplate(cr, apis)
 	serviceTemplate := getKeycloakServiceTemplate(ns)
 	routeTemplate := getKeycloakRouteTemplate(ns, *cr)
 
@@ -590,7 +590,7 @@ func getKeycloakContainerEnv() []corev1.EnvVar {
 	}
 }
 
-func newKeycloakDeployment(cr *argoproj.ArgoCD) *k8sappsv1.Deployment {
+func newKeycloakDeployment(cr *argoproj.ArgoCD, apis *ClusterAPIs) *k8sappsv1.Deployment {
 
 	var replicas int32 = 1
 	return &k8sappsv1.Deployment{
@@ -621,8 +621,8 @@ func newKeycloakDeployment(cr *argoproj.ArgoCD) *k8sappsv1.Deployment {
 					Containers: []corev1.Container{
 						{
 							Name:  defaultKeycloakIdentifier,
-							Image: getKeycloakContainerImage(cr),
-							Env:   proxyEnvVars(getKeycloakContainerEnv()...),
+							Image: getKeycloakContainerImage(cr, apis),
+							Env:   getProxyEnvVars(cr, getKeycloakContainerEnv()...),
 							Ports: []corev1.ContainerPort{
 								{Name: "http", ContainerPort: httpPort},
 								{Name: "https", ContainerPort: portTLS},
@@ -684,7 +684,7 @@ func (r *ReconcileArgoCD) newKeycloakInstance(cr *argoproj.ArgoCD) error {
 	}
 
 	// Create Keycloak Deployment
-	dep := newKeycloakDeployment(cr)
+	dep := newKeycloakDeployment(cr, r.ClusterAPIs)
 	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: dep.Name,
 		Namespace: dep.Namespace}, dep)
 
@@ -778,6 +778,8 @@ func (r *ReconcileArgoCD) prepareKeycloakConfig(cr *argoproj.ArgoCD) (*keycloakC
 		ArgoCDURL:          aRouteURL,
 		KeycloakServerCert: serverCert,
 		VerifyTLS:          tlsVerification,
+
+		OpenShiftIdentityProvider: r.ClusterAPIs.CanUseKeycloakWithTemplate(),
 	}
 
 	return cfg, nil
@@ -824,6 +826,8 @@ func (r *ReconcileArgoCD) prepareKeycloakConfigForK8s(cr *argoproj.ArgoCD) (*key
 		KeycloakURL:   kIngURL,
 		ArgoCDURL:     aIngURL,
 		VerifyTLS:     false,
+
+		OpenShiftIdentityProvider: r.ClusterAPIs.CanUseKeycloakWithTemplate(),
 	}
 
 	return cfg, nil
@@ -911,7 +915,7 @@ func createRealmConfig(cfg *keycloakConfig) ([]byte, error) {
 
 	// Add OpenShift-v4 as Identity Provider only for OpenShift environment.
 	// No Identity Provider is configured by default for non-openshift environments.
-	if CanUseKeycloakWithTemplate() {
+	if cfg.OpenShiftIdentityProvider {
 		baseURL := "https://kubernetes.default.svc.cluster.local"
 		if isProxyCluster() {
 			baseURL = getOpenShiftAPIURL()
@@ -1006,7 +1010,7 @@ func (r *ReconcileArgoCD) updateArgoCDConfiguration(cr *argoproj.ArgoCD, kRouteU
 	}
 
 	// Create openshift OAuthClient
-	if CanUseKeycloakWithTemplate() {
+	if r.ClusterAPIs.CanUseKeycloakWithTemplate() {
 		oAuthClient := &oauthv1.OAuthClient{
 			TypeMeta: metav1.TypeMeta{
 				Kind:       "OAuthClient",
@@ -1129,7 +1133,7 @@ func handleKeycloakPodDeletion(dc *appsv1.DeploymentConfig) error {
 func (r *ReconcileArgoCD) reconcileKeycloakConfiguration(cr *argoproj.ArgoCD) error {
 
 	// TemplateAPI is available, Install keycloak using openshift templates.
-	if CanUseKeycloakWithTemplate() {
+	if r.ClusterAPIs.CanUseKeycloakWithTemplate() {
 		err := r.reconcileKeycloakForOpenShift(cr)
 		if err != nil {
 			return err
@@ -1144,10 +1148,10 @@ func (r *ReconcileArgoCD) reconcileKeycloakConfiguration(cr *argoproj.ArgoCD) er
 	return nil
 }
 
-func deleteKeycloakConfiguration(cr *argoproj.ArgoCD) error {
+func deleteKeycloakConfiguration(cr *argoproj.ArgoCD, apis *ClusterAPIs) error {
 
 	// If SSO is installed using OpenShift templates.
-	if CanUseKeycloakWithTemplate() {
+	if apis.CanUseKeycloakWithTemplate() {
 		err := deleteKeycloakConfigForOpenShift(cr)
 		if err != nil {
 			return err
@@ -1293,7 +1297,7 @@ func deleteKeycloakConfigForK8s(cr *argoproj.ArgoCD) error {
 // Installs and configures Keycloak for OpenShift
 func (r *ReconcileArgoCD) reconcileKeycloakForOpenShift(cr *argoproj.ArgoCD) error {
 
-	templateInstanceRef, err := newKeycloakTemplateInstance(cr)
+	templateInstanceRef, err := newKeycloakTemplateInstance(cr, r.ClusterAPIs)
 	if err != nil {
 		return err
 	}
@@ -1329,7 +1333,7 @@ func (r *ReconcileArgoCD) reconcileKeycloakForOpenShift(cr *argoproj.ArgoCD) err
 			cr.Name, cr.Namespace))
 	} else {
 		// Handle Image upgrades
-		desiredImage := getKeycloakContainerImage(cr)
+		desiredImage := getKeycloakContainerImage(cr, r.ClusterAPIs)
 		if existingDC.Spec.Template.Spec.Containers[0].Image != desiredImage {
 			existingDC.Spec.Template.Spec.Containers[0].Image = desiredImage
 
@@ -1432,7 +1436,7 @@ func (r *ReconcileArgoCD) reconcileKeycloak(cr *argoproj.ArgoCD) error {
 			cr.Name, cr.Namespace))
 	} else {
 		// Handle Image upgrades
-		desiredImage := getKeycloakContainerImage(cr)
+		desiredImage := getKeycloakContainerImage(cr, r.ClusterAPIs)
 		if existingDeployment.Spec.Template.Spec.Containers[0].Image != desiredImage {
 			existingDeployment.Spec.Template.Spec.Containers[0].Image = desiredImage
 
@@ -82,7 +82,6 @@ func getFakeKeycloakResources() corev1.ResourceRequirements {
 
 func TestKeycloakContainerImage(t *testing.T) {
 
-	defer removeTemplateAPI()
 	tests := []struct {
 		name                     string
 		setEnvVarFunc            func(*testing.T, string)
@@ -165,9 +164,6 @@ func TestKeycloakContainerImage(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			templateAPIFound = test.templateAPIFound
-			deploymentConfigAPIFound = test.deploymentConfigAPIFound
-
 			if test.setEnvVarFunc != nil {
 				test.setEnvVarFunc(t, test.envVar)
 			}
@@ -175,7 +171,7 @@ func TestKeycloakContainerImage(t *testing.T) {
 				test.updateCrFunc(test.argoCD)
 			}
 
-			testImage := getKeycloakContainerImage(test.argoCD)
+			testImage := getKeycloakContainerImage(test.argoCD, keycloakTemplateAPIs(test.templateAPIFound, test.deploymentConfigAPIFound))
 			assert.Equal(t, test.wantContainerImage, testImage)
 
 		})
@@ -184,14 +180,11 @@ func TestKeycloakContainerImage(t *testing.T) {
 
 func TestNewKeycloakTemplateInstance(t *testing.T) {
 	// For OpenShift Container Platform.
-	templateAPIFound = true
-	defer removeTemplateAPI()
-
 	a := makeTestArgoCD()
 	a.Spec.SSO = &argoproj.ArgoCDSSOSpec{
 		Provider: "keycloak",
 	}
-	tmplInstance, err := newKeycloakTemplateInstance(a)
+	tmplInstance, err := newKeycloakTemplateInstance(a, keycloakTemplateAPIs(true, false))
 	assert.NoError(t, err)
 
 	assert.Equal(t, tmplInstance.Name, "rhsso")
@@ -200,14 +193,11 @@ func TestNewKeycloakTemplateInstance(t *testing.T) {
 
 func TestNewKeycloakTemplate(t *testing.T) {
 	// For OpenShift Container Platform.
-	templateAPIFound = true
-	defer removeTemplateAPI()
-
 	a := makeTestArgoCD()
 	a.Spec.SSO = &argoproj.ArgoCDSSOSpec{
 		Provider: "keycloak",
 	}
-	tmpl, err := newKeycloakTemplate(a)
+	tmpl, err := newKeycloakTemplate(a, keycloakTemplateAPIs(true, false))
 	assert.NoError(t, err)
 
 	assert.Equal(t, tmpl.Name, "rhsso")
@@ -216,14 +206,11 @@ func TestNewKeycloakTemplate(t *testing.T) {
 
 func TestNewKeycloakTemplate_testDeploymentConfig(t *testing.T) {
 	// For OpenShift Container Platform.
-	templateAPIFound = true
-	defer removeTemplateAPI()
-
 	a := makeTestArgoCD()
 	a.Spec.SSO = &argoproj.ArgoCDSSOSpec{
 		Provider: "keycloak",
 	}
-	dc := getKeycloakDeploymentConfigTemplate(a)
+	dc := getKeycloakDeploymentConfigTemplate(a, keycloakTemplateAPIs(true, false))
 
 	assert.Equal(t, dc.Spec.Replicas, fakeReplicas)
 
@@ -249,16 +236,12 @@ func TestNewKeycloakTemplate_testDeploymentConfig(t *testing.T) {
 func TestNewKeycloakTemplate_testKeycloakContainer(t *testing.T) {
 	// For OpenShift Container Platform.
 	t.Setenv(common.ArgoCDKeycloakImageEnvName, "")
-	templateAPIFound = true
-	deploymentConfigAPIFound = true
-
-	defer removeTemplateAPI()
 
 	a := makeTestArgoCD()
 	a.Spec.SSO = &argoproj.ArgoCDSSOSpec{
 		Provider: "keycloak",
 	}
-	kc := getKeycloakContainer(a)
+	kc := getKeycloakContainer(a, keycloakTemplateAPIs(true, true))
 	assert.Equal(t,
 		"registry.redhat.io/rh-sso-7/sso76-openshift-rhel8@sha256:ec9f60018694dcc5d431ba47d5536b761b71cb3f66684978fe6bb74c157679ac", kc.Image)
 	assert.Equal(t, corev1.PullAlways, kc.ImagePullPolicy)
@@ -266,7 +249,6 @@ func TestNewKeycloakTemplate_testKeycloakContainer(t *testing.T) {
 }
 
 func TestKeycloakResources(t *testing.T) {
-	defer removeTemplateAPI()
 	fR := getFakeKeycloakResources()
 
 	tests := []struct {
@@ -309,7 +291,7 @@ func TestKeycloakResources(t *testing.T) {
 				test.updateCrFunc(test.argoCD)
 			}
 
-			testResources := getKeycloakContainer(test.argoCD).Resources
+			testResources := getKeycloakContainer(test.argoCD, &ClusterAPIs{}).Resources
 			assert.Equal(t, test.wantResources, testResources)
 
 		})
@@ -520,7 +502,7 @@ func TestKeycloak_NodeLabelSelector(t *testing.T) {
 		Tolerations:  deploymentDefaultTolerations(),
 	}
 
-	dc := getKeycloakDeploymentConfigTemplate(a)
+	dc := getKeycloakDeploymentConfigTemplate(a, &ClusterAPIs{})
 
 	nSelectors := deploymentDefaultNodeSelector()
 	nSelectors = argoutil.AppendStringMap(nSelectors, common.DefaultNodeSelector())
@@ -528,7 +510,9 @@ func TestKeycloak_NodeLabelSelector(t *testing.T) {
 	assert.Equal(t, dc.Spec.Template.Spec.Tolerations, a.Spec.NodePlacement.Tolerations)
 }
 
-func removeTemplateAPI() {
-	templateAPIFound = false
-	deploymentConfigAPIFound = false
+// keycloakTemplateAPIs returns the ClusterAPIs of a cluster serving the given Template and DeploymentConfig APIs.
+func keycloakTemplateAPIs(templateFound, deploymentConfigFound bool) *ClusterAPIs {
+	apis := &ClusterAPIs{}
+	apis.setKeycloakTemplateAPIsAvailable(templateFound, deploymentConfigFound)
+	return apis
 }
@@ -104,6 +104,8 @@ type keycloakConfig struct {
 	ArgoCDURL          string
 	KeycloakServerCert []byte
 	VerifyTLS          bool
+	// Adds OpenShift-v4 as Identity Provider of the realm
+	OpenShiftIdentityProvider bool
 }
 
 type oidcConfig struct {
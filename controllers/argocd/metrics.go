@@ -26,7 +26,7 @@ var (
 			Name: "active_argocd_instance_reconciliation_count",
 			Help: "Number of reconciliations performed for a given instance",
 		},
-		[]string{"namespace"},
+		[]string{"namespace", "name"},
 	)
 
 	// ReconcileTime is a prometheus metric which keeps track of the duration
@@ -35,9 +35,29 @@ var (
 		Name:    "controller_runtime_reconcile_time_seconds_per_instance",
 		Help:    "Length of time per reconciliation per instance",
 		Buckets: []float64{0.05, 0.075, 0.1, 0.15, 0.2, 0.22, 0.24, 0.26, 0.28, 0.3, 0.32, 0.34, 0.37, 0.4, 0.42, 0.44, 0.48, 0.5, 0.55, 0.6, 0.75, 0.9, 1.00},
-	}, []string{"namespace"})
+	}, []string{"namespace", "name"})
+
+	// ActiveIntegrations is a prometheus metric which reports whether the optional
+	// API of each integration, such as OpenShift Routes or Prometheus, is served by the cluster
+	ActiveIntegrations = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "argocd_operator_integration_active",
+			Help: "Whether the optional API of an integration is served by the cluster (1) or not (0)",
+		},
+		[]string{"integration"},
+	)
+
+	// DriftCorrectionsTotal is a prometheus metric which counts the changes made outside of the operator
+	// to the resources of each component of an instance, and reverted by the operator
+	DriftCorrectionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "argocd_operator_drift_corrections_total",
+			Help: "Number of resources of a given component reverted to their desired state after they were changed outside of the operator",
+		},
+		[]string{"namespace", "name", "component"},
+	)
 )
 
 func init() {
-	metrics.Registry.MustRegister(ActiveInstancesTotal, ActiveInstancesByPhase, ActiveInstanceReconciliationCount, ReconcileTime)
+	metrics.Registry.MustRegister(ActiveInstancesTotal, ActiveInstancesByPhase, ActiveInstanceReconciliationCount, ReconcileTime, ActiveIntegrations, DriftCorrectionsTotal)
 }
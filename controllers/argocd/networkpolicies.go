@@ -3,14 +3,22 @@ package argocd
 import (
 	"context"
 	"fmt"
+	"net"
 	"reflect"
+	"sort"
 
 	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
 	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
@@ -19,6 +27,10 @@ var (
 		tcpProtocol := corev1.ProtocolTCP
 		return &tcpProtocol
 	}()
+	UDPProtocol = func() *corev1.Protocol {
+		udpProtocol := corev1.ProtocolUDP
+		return &udpProtocol
+	}()
 )
 
 const (
@@ -26,6 +38,13 @@ const (
 	RedisNetworkPolicy = "redis-network-policy"
 	// RedisHAIngressNetworkPolicy is the name of the network policy which controls Redis HA Ingress traffic
 	RedisHANetworkPolicy = "redis-ha-network-policy"
+	// DefaultDenyNetworkPolicy is the name of the network policy which denies all traffic of the Argo CD components
+	DefaultDenyNetworkPolicy = "default-deny-network-policy"
+
+	// apiServerServiceNamespace is the namespace of the Service of the Kubernetes API server
+	apiServerServiceNamespace = "default"
+	// apiServerServiceName is the name of the Service of the Kubernetes API server
+	apiServerServiceName = "kubernetes"
 )
 
 func (r *ReconcileArgoCD) ReconcileNetworkPolicies(cr *argoproj.ArgoCD) error {
@@ -40,6 +59,11 @@ func (r *ReconcileArgoCD) ReconcileNetworkPolicies(cr *argoproj.ArgoCD) error {
 		return err
 	}
 
+	// Reconcile the opt-in network policies of the Argo CD components
+	if err := r.ReconcileComponentNetworkPolicies(cr); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -262,3 +286,320 @@ func (r *ReconcileArgoCD) ReconcileRedisHANetworkPolicy(cr *argoproj.ArgoCD) err
 	return nil
 
 }
+
+// componentNetworkPolicy describes the traffic allowed to and from the pods of an Argo CD component.
+type componentNetworkPolicy struct {
+	// component is the suffix of the name of the component pods.
+	component string
+	enabled   bool
+	ingress   []networkingv1.NetworkPolicyIngressRule
+	egress    []networkingv1.NetworkPolicyEgressRule
+}
+
+// isNetworkPolicyEnabled returns true if the NetworkPolicies of the Argo CD components are enabled.
+func isNetworkPolicyEnabled(cr *argoproj.ArgoCD) bool {
+	return cr.Spec.NetworkPolicy != nil && cr.Spec.NetworkPolicy.Enabled
+}
+
+// ReconcileComponentNetworkPolicies creates and reconciles the default deny network policy and the network policies
+// of the Argo CD components when enabled, and deletes them otherwise.
+func (r *ReconcileArgoCD) ReconcileComponentNetworkPolicies(cr *argoproj.ArgoCD) error {
+	enabled := isNetworkPolicyEnabled(cr)
+	policies := r.getComponentNetworkPolicies(cr)
+
+	components := make([]string, 0, len(policies))
+	for _, policy := range policies {
+		components = append(components, fmt.Sprintf("%s-%s", cr.Name, policy.component))
+	}
+
+	defaultDeny := newNetworkPolicyWithSuffix(DefaultDenyNetworkPolicy, cr)
+	defaultDeny.Spec = networkingv1.NetworkPolicySpec{
+		PodSelector: metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{
+				{
+					Key:      common.ArgoCDKeyName,
+					Operator: metav1.LabelSelectorOpIn,
+					Values:   components,
+				},
+			},
+		},
+		PolicyTypes: []networkingv1.PolicyType{
+			networkingv1.PolicyTypeIngress,
+			networkingv1.PolicyTypeEgress,
+		},
+	}
+	if err := r.reconcileNetworkPolicy(cr, defaultDeny, enabled); err != nil {
+		return err
+	}
+
+	for _, policy := range policies {
+		networkPolicy := newNetworkPolicyWithSuffix(fmt.Sprintf("%s-network-policy", policy.component), cr)
+		networkPolicy.Spec = networkingv1.NetworkPolicySpec{
+			PodSelector: *getComponentPodSelector(cr, policy.component),
+			PolicyTypes: []networkingv1.PolicyType{
+				networkingv1.PolicyTypeIngress,
+				networkingv1.PolicyTypeEgress,
+			},
+			Ingress: policy.ingress,
+			Egress:  policy.egress,
+		}
+		if err := r.reconcileNetworkPolicy(cr, networkPolicy, enabled && policy.enabled); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getComponentNetworkPolicies returns the traffic allowed for each of the Argo CD components.
+func (r *ReconcileArgoCD) getComponentNetworkPolicies(cr *argoproj.ArgoCD) []componentNetworkPolicy {
+	dns := getDNSEgressRule()
+	apiServer := r.getAPIServerEgressRule()
+	redis := getComponentEgressRule(cr, []int32{common.ArgoCDDefaultRedisPort}, "redis", "redis-ha-haproxy")
+	repoServer := getComponentEgressRule(cr, []int32{common.ArgoCDDefaultRepoServerPort}, "repo-server")
+	dex := getComponentEgressRule(cr, []int32{common.ArgoCDDefaultDexHTTPPort, common.ArgoCDDefaultDexGRPCPort}, "dex-server")
+	external := getExternalEgressRules(cr)
+
+	return []componentNetworkPolicy{
+		{
+			component: "server",
+			enabled:   cr.Spec.Server.IsEnabled(),
+			ingress: []networkingv1.NetworkPolicyIngressRule{
+				{Ports: getTCPNetworkPolicyPorts(8080, 8083)},
+			},
+			egress: append([]networkingv1.NetworkPolicyEgressRule{dns, apiServer, repoServer, redis, dex}, external...),
+		},
+		{
+			component: "repo-server",
+			enabled:   cr.Spec.Repo.IsEnabled(),
+			ingress: []networkingv1.NetworkPolicyIngressRule{
+				{
+					From:  getComponentNetworkPolicyPeers(cr, "server", "application-controller", "applicationset-controller", "notifications-controller"),
+					Ports: getTCPNetworkPolicyPorts(common.ArgoCDDefaultRepoServerPort),
+				},
+				{Ports: getTCPNetworkPolicyPorts(common.ArgoCDDefaultRepoMetricsPort)},
+			},
+			egress: append([]networkingv1.NetworkPolicyEgressRule{dns, redis}, external...),
+		},
+		{
+			component: "application-controller",
+			enabled:   cr.Spec.Controller.IsEnabled(),
+			ingress: []networkingv1.NetworkPolicyIngressRule{
+				{Ports: getTCPNetworkPolicyPorts(8082)},
+			},
+			egress: append([]networkingv1.NetworkPolicyEgressRule{dns, apiServer, repoServer, redis}, external...),
+		},
+		{
+			component: "dex-server",
+			enabled:   UseDex(cr),
+			ingress: []networkingv1.NetworkPolicyIngressRule{
+				{
+					From:  getComponentNetworkPolicyPeers(cr, "server"),
+					Ports: getTCPNetworkPolicyPorts(common.ArgoCDDefaultDexHTTPPort, common.ArgoCDDefaultDexGRPCPort),
+				},
+				{Ports: getTCPNetworkPolicyPorts(common.ArgoCDDefaultDexMetricsPort)},
+			},
+			egress: append([]networkingv1.NetworkPolicyEgressRule{dns, apiServer}, external...),
+		},
+		{
+			component: "notifications-controller",
+			enabled:   cr.Spec.Notifications.Enabled,
+			ingress: []networkingv1.NetworkPolicyIngressRule{
+				{Ports: getTCPNetworkPolicyPorts(9001)},
+			},
+			egress: append([]networkingv1.NetworkPolicyEgressRule{dns, apiServer, repoServer}, external...),
+		},
+		{
+			component: "applicationset-controller",
+			enabled:   cr.Spec.ApplicationSet != nil && cr.Spec.ApplicationSet.IsEnabled(),
+			ingress: []networkingv1.NetworkPolicyIngressRule{
+				{Ports: getTCPNetworkPolicyPorts(7000, 8080)},
+			},
+			egress: append([]networkingv1.NetworkPolicyEgressRule{dns, apiServer, repoServer}, external...),
+		},
+	}
+}
+
+// newNetworkPolicyWithSuffix returns a new NetworkPolicy with the given name suffix for the ArgoCD.
+func newNetworkPolicyWithSuffix(suffix string, cr *argoproj.ArgoCD) *networkingv1.NetworkPolicy {
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%s", cr.Name, suffix),
+			Namespace: cr.Namespace,
+			Labels:    argoutil.LabelsForCluster(cr),
+		},
+	}
+}
+
+// getComponentPodSelector returns the selector of the pods of the given Argo CD component.
+func getComponentPodSelector(cr *argoproj.ArgoCD, component string) *metav1.LabelSelector {
+	return &metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			common.ArgoCDKeyName: fmt.Sprintf("%s-%s", cr.Name, component),
+		},
+	}
+}
+
+// getComponentNetworkPolicyPeers returns the peers matching the pods of the given Argo CD components.
+func getComponentNetworkPolicyPeers(cr *argoproj.ArgoCD, components ...string) []networkingv1.NetworkPolicyPeer {
+	peers := make([]networkingv1.NetworkPolicyPeer, 0, len(components))
+	for _, component := range components {
+		peers = append(peers, networkingv1.NetworkPolicyPeer{PodSelector: getComponentPodSelector(cr, component)})
+	}
+	return peers
+}
+
+// getTCPNetworkPolicyPorts returns the given TCP ports.
+func getTCPNetworkPolicyPorts(ports ...int32) []networkingv1.NetworkPolicyPort {
+	result := make([]networkingv1.NetworkPolicyPort, 0, len(ports))
+	for _, port := range ports {
+		result = append(result, networkingv1.NetworkPolicyPort{
+			Protocol: TCPProtocol,
+			Port:     &intstr.IntOrString{Type: intstr.Int, IntVal: port},
+		})
+	}
+	return result
+}
+
+// getComponentEgressRule returns the rule allowing egress to the given ports of the given Argo CD components.
+func getComponentEgressRule(cr *argoproj.ArgoCD, ports []int32, components ...string) networkingv1.NetworkPolicyEgressRule {
+	return networkingv1.NetworkPolicyEgressRule{
+		To:    getComponentNetworkPolicyPeers(cr, components...),
+		Ports: getTCPNetworkPolicyPorts(ports...),
+	}
+}
+
+// getDNSEgressRule returns the rule allowing DNS lookups, on port 53 and on port 5353 used by the OpenShift DNS.
+func getDNSEgressRule() networkingv1.NetworkPolicyEgressRule {
+	rule := networkingv1.NetworkPolicyEgressRule{}
+	for _, port := range []int32{53, 5353} {
+		rule.Ports = append(rule.Ports,
+			networkingv1.NetworkPolicyPort{Protocol: UDPProtocol, Port: &intstr.IntOrString{Type: intstr.Int, IntVal: port}},
+			networkingv1.NetworkPolicyPort{Protocol: TCPProtocol, Port: &intstr.IntOrString{Type: intstr.Int, IntVal: port}},
+		)
+	}
+	return rule
+}
+
+// getAPIServerEgressRule returns the rule allowing egress to the Kubernetes API server. The addresses and ports of
+// the API server are read from the endpoints of the kubernetes Service, egress to ports 443 and 6443 is allowed
+// when they are not available.
+func (r *ReconcileArgoCD) getAPIServerEgressRule() networkingv1.NetworkPolicyEgressRule {
+	var reader client.Reader = r.Client
+	if r.apiServerEndpoints != nil {
+		reader = r.apiServerEndpoints
+	}
+	endpoints := &corev1.Endpoints{}
+	if err := reader.Get(context.TODO(), types.NamespacedName{Namespace: apiServerServiceNamespace, Name: apiServerServiceName}, endpoints); err != nil {
+		log.Info(fmt.Sprintf("unable to get the Kubernetes API server endpoints, allowing egress to ports 443 and 6443: %v", err))
+		return networkingv1.NetworkPolicyEgressRule{Ports: getTCPNetworkPolicyPorts(443, 6443)}
+	}
+
+	addresses := map[string]bool{}
+	ports := map[int32]bool{}
+	for _, subset := range endpoints.Subsets {
+		for _, address := range subset.Addresses {
+			addresses[address.IP] = true
+		}
+		for _, port := range subset.Ports {
+			ports[port.Port] = true
+		}
+	}
+	if len(addresses) == 0 || len(ports) == 0 {
+		return networkingv1.NetworkPolicyEgressRule{Ports: getTCPNetworkPolicyPorts(443, 6443)}
+	}
+
+	cidrs := make([]string, 0, len(addresses))
+	for address := range addresses {
+		if ip := net.ParseIP(address); ip != nil && ip.To4() == nil {
+			cidrs = append(cidrs, address+"/128")
+		} else {
+			cidrs = append(cidrs, address+"/32")
+		}
+	}
+	sort.Strings(cidrs)
+
+	portNumbers := make([]int32, 0, len(ports))
+	for port := range ports {
+		portNumbers = append(portNumbers, port)
+	}
+	sort.Slice(portNumbers, func(i, j int) bool { return portNumbers[i] < portNumbers[j] })
+
+	rule := networkingv1.NetworkPolicyEgressRule{Ports: getTCPNetworkPolicyPorts(portNumbers...)}
+	for _, cidr := range cidrs {
+		rule.To = append(rule.To, networkingv1.NetworkPolicyPeer{IPBlock: &networkingv1.IPBlock{CIDR: cidr}})
+	}
+	return rule
+}
+
+// newAPIServerEndpointsCache returns a cache holding the endpoints of the kubernetes Service only, which the cache of
+// the manager misses when it only watches the namespaces of the ArgoCD instances.
+func newAPIServerEndpointsCache(mgr ctrl.Manager) (cache.Cache, error) {
+	return cache.New(mgr.GetConfig(), cache.Options{
+		Scheme:            mgr.GetScheme(),
+		Mapper:            mgr.GetRESTMapper(),
+		DefaultNamespaces: map[string]cache.Config{apiServerServiceNamespace: {}},
+		ByObject: map[client.Object]cache.ByObject{
+			&corev1.Endpoints{}: {Field: fields.OneTermEqualSelector("metadata.name", apiServerServiceName)},
+		},
+	})
+}
+
+// getExternalEgressRules returns the rules allowing egress to the CIDRs configured for the ArgoCD, if any.
+func getExternalEgressRules(cr *argoproj.ArgoCD) []networkingv1.NetworkPolicyEgressRule {
+	if cr.Spec.NetworkPolicy == nil || len(cr.Spec.NetworkPolicy.EgressCIDRs) == 0 {
+		return nil
+	}
+	rule := networkingv1.NetworkPolicyEgressRule{}
+	for _, cidr := range cr.Spec.NetworkPolicy.EgressCIDRs {
+		rule.To = append(rule.To, networkingv1.NetworkPolicyPeer{IPBlock: &networkingv1.IPBlock{CIDR: cidr}})
+	}
+	return []networkingv1.NetworkPolicyEgressRule{rule}
+}
+
+// reconcileNetworkPolicy ensures the given network policy is present and up to date when enabled, and deleted
+// otherwise.
+func (r *ReconcileArgoCD) reconcileNetworkPolicy(cr *argoproj.ArgoCD, networkPolicy *networkingv1.NetworkPolicy, enabled bool) error {
+	existing := &networkingv1.NetworkPolicy{}
+	if argoutil.IsObjectFound(r.Client, cr.Namespace, networkPolicy.Name, existing) {
+		if !enabled {
+			log.Info("Deleting network policy", "namespace", existing.Namespace, "name", existing.Name)
+			return r.Client.Delete(context.TODO(), existing)
+		}
+
+		modified := false
+		if !reflect.DeepEqual(existing.Spec.PodSelector, networkPolicy.Spec.PodSelector) {
+			existing.Spec.PodSelector = networkPolicy.Spec.PodSelector
+			modified = true
+		}
+		if !reflect.DeepEqual(existing.Spec.PolicyTypes, networkPolicy.Spec.PolicyTypes) {
+			existing.Spec.PolicyTypes = networkPolicy.Spec.PolicyTypes
+			modified = true
+		}
+		if !reflect.DeepEqual(existing.Spec.Ingress, networkPolicy.Spec.Ingress) {
+			existing.Spec.Ingress = networkPolicy.Spec.Ingress
+			modified = true
+		}
+		if !reflect.DeepEqual(existing.Spec.Egress, networkPolicy.Spec.Egress) {
+			existing.Spec.Egress = networkPolicy.Spec.Egress
+			modified = true
+		}
+
+		if modified {
+			log.Info("Updating network policy", "namespace", existing.Namespace, "name", existing.Name)
+			return r.Client.Update(context.TODO(), existing)
+		}
+		return nil
+	}
+
+	if !enabled {
+		return nil
+	}
+
+	if err := controllerutil.SetControllerReference(cr, networkPolicy, r.Scheme); err != nil {
+		log.Error(err, "Failed to set controller reference on network policy")
+		return err
+	}
+
+	log.Info("Creating network policy", "namespace", networkPolicy.Namespace, "name", networkPolicy.Name)
+	return r.Client.Create(context.TODO(), networkPolicy)
+}
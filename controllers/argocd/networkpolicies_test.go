@@ -7,7 +7,10 @@ import (
 
 	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -79,3 +82,109 @@ func TestRedisHANetworkPolicy(t *testing.T) {
 	assert.Equal(t, intstr.FromInt(6379), *np.Spec.Ingress[0].Ports[0].Port)
 	assert.Equal(t, intstr.FromInt(26379), *np.Spec.Ingress[0].Ports[1].Port)
 }
+
+func TestComponentNetworkPolicies(t *testing.T) {
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.NetworkPolicy = &argoproj.ArgoCDNetworkPolicySpec{
+			Enabled:     true,
+			EgressCIDRs: []string{"140.82.112.0/20"},
+		}
+	})
+	endpoints := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: "kubernetes", Namespace: "default"},
+		Subsets: []corev1.EndpointSubset{{
+			Addresses: []corev1.EndpointAddress{{IP: "10.0.0.2"}, {IP: "10.0.0.1"}},
+			Ports:     []corev1.EndpointPort{{Name: "https", Port: 6443}},
+		}},
+	}
+	r := makeTestReconciler(makeTestReconcilerClient(makeTestReconcilerScheme(argoproj.AddToScheme), []client.Object{a, endpoints}, []client.Object{a}, []runtime.Object{}), makeTestReconcilerScheme(argoproj.AddToScheme))
+
+	assert.NoError(t, r.ReconcileComponentNetworkPolicies(a))
+
+	// The default deny policy selects the pods of every component
+	np := &networkingv1.NetworkPolicy{}
+	assert.NoError(t, r.Get(context.TODO(), client.ObjectKey{Name: fmt.Sprintf("%s-%s", a.Name, DefaultDenyNetworkPolicy), Namespace: a.Namespace}, np))
+	assert.Equal(t, []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress}, np.Spec.PolicyTypes)
+	assert.Empty(t, np.Spec.Ingress)
+	assert.Empty(t, np.Spec.Egress)
+	assert.ElementsMatch(t, []string{
+		"argocd-server", "argocd-repo-server", "argocd-application-controller", "argocd-dex-server",
+		"argocd-notifications-controller", "argocd-applicationset-controller",
+	}, np.Spec.PodSelector.MatchExpressions[0].Values)
+
+	// The repo server accepts connections from the other components and reaches the Git hosts
+	assert.NoError(t, r.Get(context.TODO(), client.ObjectKey{Name: "argocd-repo-server-network-policy", Namespace: a.Namespace}, np))
+	assert.Equal(t, "argocd-repo-server", np.Spec.PodSelector.MatchLabels["app.kubernetes.io/name"])
+	assert.Len(t, np.Spec.Ingress[0].From, 4)
+	assert.Equal(t, intstr.FromInt(8081), *np.Spec.Ingress[0].Ports[0].Port)
+	egress := np.Spec.Egress[len(np.Spec.Egress)-1]
+	assert.Equal(t, "140.82.112.0/20", egress.To[0].IPBlock.CIDR)
+	assert.Empty(t, egress.Ports)
+
+	// The server reaches the API server endpoints
+	assert.NoError(t, r.Get(context.TODO(), client.ObjectKey{Name: "argocd-server-network-policy", Namespace: a.Namespace}, np))
+	apiServer := np.Spec.Egress[1]
+	assert.Equal(t, "10.0.0.1/32", apiServer.To[0].IPBlock.CIDR)
+	assert.Equal(t, "10.0.0.2/32", apiServer.To[1].IPBlock.CIDR)
+	assert.Equal(t, intstr.FromInt(6443), *apiServer.Ports[0].Port)
+
+	// Components which are not enabled get no policy
+	err := r.Get(context.TODO(), client.ObjectKey{Name: "argocd-dex-server-network-policy", Namespace: a.Namespace}, np)
+	assert.True(t, apierrors.IsNotFound(err))
+	err = r.Get(context.TODO(), client.ObjectKey{Name: "argocd-applicationset-controller-network-policy", Namespace: a.Namespace}, np)
+	assert.True(t, apierrors.IsNotFound(err))
+
+	// Disabling the network policies deletes them
+	a.Spec.NetworkPolicy.Enabled = false
+	assert.NoError(t, r.ReconcileComponentNetworkPolicies(a))
+	list := &networkingv1.NetworkPolicyList{}
+	assert.NoError(t, r.List(context.TODO(), list, client.InNamespace(a.Namespace)))
+	assert.Empty(t, list.Items)
+}
+
+func TestComponentNetworkPolicies_apiServerFallback(t *testing.T) {
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.NetworkPolicy = &argoproj.ArgoCDNetworkPolicySpec{Enabled: true}
+	})
+	r := makeTestReconciler(makeTestReconcilerClient(makeTestReconcilerScheme(argoproj.AddToScheme), []client.Object{a}, []client.Object{a}, []runtime.Object{}), makeTestReconcilerScheme(argoproj.AddToScheme))
+
+	assert.NoError(t, r.ReconcileComponentNetworkPolicies(a))
+
+	np := &networkingv1.NetworkPolicy{}
+	assert.NoError(t, r.Get(context.TODO(), client.ObjectKey{Name: "argocd-application-controller-network-policy", Namespace: a.Namespace}, np))
+	apiServer := np.Spec.Egress[1]
+	assert.Empty(t, apiServer.To)
+	assert.Equal(t, getTCPNetworkPolicyPorts(443, 6443), apiServer.Ports)
+
+	// Without egress CIDRs nothing outside of the cluster is reachable
+	for _, rule := range np.Spec.Egress {
+		for _, peer := range rule.To {
+			assert.Nil(t, peer.IPBlock)
+		}
+	}
+}
+
+func TestComponentNetworkPolicies_apiServerEndpointsReader(t *testing.T) {
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.NetworkPolicy = &argoproj.ArgoCDNetworkPolicySpec{Enabled: true}
+	})
+	endpoints := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: "kubernetes", Namespace: "default"},
+		Subsets: []corev1.EndpointSubset{{
+			Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}},
+			Ports:     []corev1.EndpointPort{{Name: "https", Port: 6443}},
+		}},
+	}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	r := makeTestReconciler(makeTestReconcilerClient(sch, []client.Object{a}, []client.Object{a}, []runtime.Object{}), sch)
+	// the endpoints live outside of the namespaces watched by the client
+	r.apiServerEndpoints = makeTestReconcilerClient(sch, []client.Object{endpoints}, nil, []runtime.Object{})
+
+	assert.NoError(t, r.ReconcileComponentNetworkPolicies(a))
+
+	np := &networkingv1.NetworkPolicy{}
+	assert.NoError(t, r.Get(context.TODO(), client.ObjectKey{Name: "argocd-application-controller-network-policy", Namespace: a.Namespace}, np))
+	apiServer := np.Spec.Egress[1]
+	assert.Equal(t, "10.0.0.1/32", apiServer.To[0].IPBlock.CIDR)
+	assert.Equal(t, getTCPNetworkPolicyPorts(6443), apiServer.Ports)
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"reflect"
-	"time"
 
 	monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
 	appsv1 "k8s.io/api/apps/v1"
@@ -66,7 +65,7 @@ func (r *ReconcileArgoCD) reconcileNotificationsController(cr *argoproj.ArgoCD)
 		return err
 	}
 
-	if prometheusAPIFound {
+	if r.ClusterAPIs.IsPrometheusAPIAvailable() {
 		log.Info("reconciling notifications metrics service monitor")
 		if err := r.reconcileNotificationsServiceMonitor(cr); err != nil {
 			return err
@@ -234,36 +233,16 @@ func (r *ReconcileArgoCD) reconcileNotificationsRole(cr *argoproj.ArgoCD) (*rbac
 		if !cr.Spec.Notifications.Enabled {
 			return nil, nil
 		}
-
-		// role does not exist but should, so it should be created
-		if err := controllerutil.SetControllerReference(cr, desiredRole, r.Scheme); err != nil {
-			return nil, err
-		}
-
-		log.Info(fmt.Sprintf("Creating role %s", desiredRole.Name))
-		err := r.Client.Create(context.TODO(), desiredRole)
-		if err != nil {
-			return nil, err
-		}
-		return desiredRole, nil
-	}
-
-	// role exists but shouldn't, so it should be deleted
-	if !cr.Spec.Notifications.Enabled {
+	} else if !cr.Spec.Notifications.Enabled {
+		// role exists but shouldn't, so it should be deleted
 		log.Info(fmt.Sprintf("Deleting role %s as notifications is disabled", existingRole.Name))
 		return nil, r.Client.Delete(context.TODO(), existingRole)
 	}
 
-	// role exists and should. Reconcile role if changed
-	if !reflect.DeepEqual(existingRole.Rules, desiredRole.Rules) {
-		existingRole.Rules = desiredRole.Rules
-		if err := controllerutil.SetControllerReference(cr, existingRole, r.Scheme); err != nil {
-			return nil, err
-		}
-		return existingRole, r.Client.Update(context.TODO(), existingRole)
+	if err := controllerutil.SetControllerReference(cr, desiredRole, r.Scheme); err != nil {
+		return nil, err
 	}
-
-	return desiredRole, nil
+	return desiredRole, argoutil.ApplyResource(r.Client, desiredRole)
 }
 
 func (r *ReconcileArgoCD) reconcileNotificationsRoleBinding(cr *argoproj.ArgoCD, role *rbacv1.Role, sa *corev1.ServiceAccount) error {
@@ -294,41 +273,25 @@ func (r *ReconcileArgoCD) reconcileNotificationsRoleBinding(cr *argoproj.ArgoCD,
 		if !cr.Spec.Notifications.Enabled {
 			return nil
 		}
-
-		// roleBinding does not exist but should, so it should be created
-		if err := controllerutil.SetControllerReference(cr, desiredRoleBinding, r.Scheme); err != nil {
-			return err
-		}
-
-		log.Info(fmt.Sprintf("Creating roleBinding %s", desiredRoleBinding.Name))
-		return r.Client.Create(context.TODO(), desiredRoleBinding)
-	}
-
-	// roleBinding exists but shouldn't, so it should be deleted
-	if !cr.Spec.Notifications.Enabled {
+	} else if !cr.Spec.Notifications.Enabled {
+		// roleBinding exists but shouldn't, so it should be deleted
 		log.Info(fmt.Sprintf("Deleting roleBinding %s as notifications is disabled", existingRoleBinding.Name))
 		return r.Client.Delete(context.TODO(), existingRoleBinding)
-	}
-
-	// roleBinding exists and should. Reconcile roleBinding if changed
-	if !reflect.DeepEqual(existingRoleBinding.RoleRef, desiredRoleBinding.RoleRef) {
-		// if the RoleRef changes, delete the existing role binding and create a new one
+	} else if !reflect.DeepEqual(existingRoleBinding.RoleRef, desiredRoleBinding.RoleRef) {
+		// the RoleRef of a role binding is immutable, if it changes delete the existing role binding and create a new one
 		if err := r.Client.Delete(context.TODO(), existingRoleBinding); err != nil {
 			return err
 		}
-	} else if !reflect.DeepEqual(existingRoleBinding.Subjects, desiredRoleBinding.Subjects) {
-		existingRoleBinding.Subjects = desiredRoleBinding.Subjects
-		if err := controllerutil.SetControllerReference(cr, existingRoleBinding, r.Scheme); err != nil {
-			return err
-		}
-		return r.Client.Update(context.TODO(), existingRoleBinding)
 	}
 
-	return nil
+	if err := controllerutil.SetControllerReference(cr, desiredRoleBinding, r.Scheme); err != nil {
+		return err
+	}
+	return argoutil.ApplyResource(r.Client, desiredRoleBinding)
 }
 
 func (r *ReconcileArgoCD) reconcileNotificationsDeployment(cr *argoproj.ArgoCD, sa *corev1.ServiceAccount) error {
-
+	cr = argoCDForUpgradeStep(cr, "notifications-controller")
 	desiredDeployment := newDeploymentWithSuffix("notifications-controller", "controller", cr)
 
 	desiredDeployment.Spec.Strategy = appsv1.DeploymentStrategy{
@@ -341,13 +304,13 @@ func (r *ReconcileArgoCD) reconcileNotificationsDeployment(cr *argoproj.ArgoCD,
 
 	notificationEnv := cr.Spec.Notifications.Env
 	// Let user specify their own environment first
-	notificationEnv = argoutil.EnvMerge(notificationEnv, proxyEnvVars(), false)
+	notificationEnv = argoutil.EnvMerge(notificationEnv, getProxyEnvVars(cr), false)
 
 	podSpec := &desiredDeployment.Spec.Template.Spec
 	podSpec.SecurityContext = &corev1.PodSecurityContext{
 		RunAsNonRoot: boolPtr(true),
 	}
-	AddSeccompProfileForOpenShift(r.Client, podSpec)
+	AddSeccompProfileForOpenShift(r.Client, r.ClusterAPIs, podSpec)
 	podSpec.ServiceAccountName = sa.ObjectMeta.Name
 	podSpec.Volumes = []corev1.Volume{
 		{
@@ -407,9 +370,9 @@ func (r *ReconcileArgoCD) reconcileNotificationsDeployment(cr *argoproj.ArgoCD,
 		},
 		WorkingDir: "/app",
 	}}
+	addTrustedCA(cr, podSpec, getArgoContainerImage(cr))
 
 	// fetch existing deployment by name
-	deploymentChanged := false
 	existingDeployment := &appsv1.Deployment{}
 	if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: desiredDeployment.Name, Namespace: cr.Namespace}, existingDeployment); err != nil {
 		if !errors.IsNotFound(err) {
@@ -422,12 +385,8 @@ func (r *ReconcileArgoCD) reconcileNotificationsDeployment(cr *argoproj.ArgoCD,
 		}
 
 		// deployment does not exist but should, so it should be created
-		if err := controllerutil.SetControllerReference(cr, desiredDeployment, r.Scheme); err != nil {
-			return err
-		}
-
 		log.Info(fmt.Sprintf("Creating deployment %s", desiredDeployment.Name))
-		return r.Client.Create(context.TODO(), desiredDeployment)
+		return r.applyDeployment(cr, desiredDeployment, nil)
 	}
 
 	// deployment exists but shouldn't, so it should be deleted
@@ -436,72 +395,8 @@ func (r *ReconcileArgoCD) reconcileNotificationsDeployment(cr *argoproj.ArgoCD,
 		return r.Client.Delete(context.TODO(), existingDeployment)
 	}
 
-	// deployment exists and should. Reconcile deployment if changed
-	updateNodePlacement(existingDeployment, desiredDeployment, &deploymentChanged)
-
-	if existingDeployment.Spec.Template.Spec.Containers[0].Image != desiredDeployment.Spec.Template.Spec.Containers[0].Image {
-		existingDeployment.Spec.Template.Spec.Containers[0].Image = desiredDeployment.Spec.Template.Spec.Containers[0].Image
-		existingDeployment.Spec.Template.ObjectMeta.Labels["image.upgraded"] = time.Now().UTC().Format("01022006-150406-MST")
-		deploymentChanged = true
-	}
-
-	if !reflect.DeepEqual(existingDeployment.Spec.Template.Spec.Containers[0].Command, desiredDeployment.Spec.Template.Spec.Containers[0].Command) {
-		existingDeployment.Spec.Template.Spec.Containers[0].Command = desiredDeployment.Spec.Template.Spec.Containers[0].Command
-		deploymentChanged = true
-	}
-
-	if !reflect.DeepEqual(existingDeployment.Spec.Template.Spec.Containers[0].Env,
-		desiredDeployment.Spec.Template.Spec.Containers[0].Env) {
-		existingDeployment.Spec.Template.Spec.Containers[0].Env = desiredDeployment.Spec.Template.Spec.Containers[0].Env
-		deploymentChanged = true
-	}
-
-	if !reflect.DeepEqual(existingDeployment.Spec.Template.Spec.Volumes, desiredDeployment.Spec.Template.Spec.Volumes) {
-		existingDeployment.Spec.Template.Spec.Volumes = desiredDeployment.Spec.Template.Spec.Volumes
-		deploymentChanged = true
-	}
-
-	if !reflect.DeepEqual(existingDeployment.Spec.Replicas, desiredDeployment.Spec.Replicas) {
-		existingDeployment.Spec.Replicas = desiredDeployment.Spec.Replicas
-		deploymentChanged = true
-	}
-
-	if !reflect.DeepEqual(existingDeployment.Spec.Template.Spec.Containers[0].VolumeMounts, desiredDeployment.Spec.Template.Spec.Containers[0].VolumeMounts) {
-		existingDeployment.Spec.Template.Spec.Containers[0].VolumeMounts = desiredDeployment.Spec.Template.Spec.Containers[0].VolumeMounts
-		deploymentChanged = true
-	}
-
-	if !reflect.DeepEqual(existingDeployment.Spec.Template.Spec.Containers[0].Resources, desiredDeployment.Spec.Template.Spec.Containers[0].Resources) {
-		existingDeployment.Spec.Template.Spec.Containers[0].Resources = desiredDeployment.Spec.Template.Spec.Containers[0].Resources
-		deploymentChanged = true
-	}
-
-	if !reflect.DeepEqual(existingDeployment.Spec.Template.Spec.ServiceAccountName, desiredDeployment.Spec.Template.Spec.ServiceAccountName) {
-		existingDeployment.Spec.Template.Spec.ServiceAccountName = desiredDeployment.Spec.Template.Spec.ServiceAccountName
-		deploymentChanged = true
-	}
-
-	if !reflect.DeepEqual(existingDeployment.Labels, desiredDeployment.Labels) {
-		existingDeployment.Labels = desiredDeployment.Labels
-		deploymentChanged = true
-	}
-
-	if !reflect.DeepEqual(existingDeployment.Spec.Template.Labels, desiredDeployment.Spec.Template.Labels) {
-		existingDeployment.Spec.Template.Labels = desiredDeployment.Spec.Template.Labels
-		deploymentChanged = true
-	}
-
-	if !reflect.DeepEqual(existingDeployment.Spec.Selector, desiredDeployment.Spec.Selector) {
-		existingDeployment.Spec.Selector = desiredDeployment.Spec.Selector
-		deploymentChanged = true
-	}
-
-	if deploymentChanged {
-		return r.Client.Update(context.TODO(), existingDeployment)
-	}
-
-	return nil
-
+	// deployment exists and should
+	return r.applyDeployment(cr, desiredDeployment, existingDeployment)
 }
 
 // reconcileNotificationsService will ensure that the Service for the Notifications controller metrics is present.
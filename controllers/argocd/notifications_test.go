@@ -311,7 +311,7 @@ func TestReconcileNotifications_CreateServiceMonitor(t *testing.T) {
 	r := makeTestReconciler(cl, sch)
 
 	// Notifications controller service monitor should not be created when Prometheus API is not found.
-	prometheusAPIFound = false
+	r.ClusterAPIs.setPrometheusAPIAvailable(false)
 	err := r.reconcileNotificationsController(a)
 	assert.NoError(t, err)
 
@@ -322,7 +322,7 @@ func TestReconcileNotifications_CreateServiceMonitor(t *testing.T) {
 	}, testServiceMonitor))
 
 	// Prometheus API found, Verify notification controller service monitor exists.
-	prometheusAPIFound = true
+	r.ClusterAPIs.setPrometheusAPIAvailable(true)
 	err = r.reconcileNotificationsController(a)
 	assert.NoError(t, err)
 
@@ -407,7 +407,7 @@ func TestReconcileNotifications_testEnvVars(t *testing.T) {
 		t.Fatalf("failed to reconcile notifications-controller deployment env:\n%s", diff)
 	}
 
-	// Verify any manual updates to the env vars should be overridden by the operator.
+	// Verify the env vars removed manually are restored by the operator, while the ones added manually are left untouched.
 	unwantedEnv := []corev1.EnvVar{
 		{
 			Name:  "foo",
@@ -419,6 +419,8 @@ func TestReconcileNotifications_testEnvVars(t *testing.T) {
 		},
 	}
 
+	wantEnv := append([]corev1.EnvVar{{Name: "ping", Value: "pong"}}, envMap...)
+
 	deployment.Spec.Template.Spec.Containers[0].Env = unwantedEnv
 	assert.NoError(t, r.Client.Update(context.TODO(), deployment))
 
@@ -434,9 +436,8 @@ func TestReconcileNotifications_testEnvVars(t *testing.T) {
 		},
 		deployment))
 
-	if diff := cmp.Diff(envMap, deployment.Spec.Template.Spec.Containers[0].Env); diff != "" {
-		t.Fatalf("operator failed to override the manual changes to notification controller:\n%s", diff)
-	}
+	assert.ElementsMatch(t, wantEnv, deployment.Spec.Template.Spec.Containers[0].Env,
+		"operator failed to restore the env vars of notification controller")
 }
 
 func TestReconcileNotifications_testLogLevel(t *testing.T) {
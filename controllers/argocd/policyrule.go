@@ -28,7 +28,7 @@ func policyRuleForApplicationController() []v1.PolicyRule {
 	}
 }
 
-func policyRuleForRedis(client client.Client) []v1.PolicyRule {
+func policyRuleForRedis(client client.Client, apis *ClusterAPIs) []v1.PolicyRule {
 	rules := []v1.PolicyRule{
 		{
 			APIGroups: []string{
@@ -47,12 +47,12 @@ func policyRuleForRedis(client client.Client) []v1.PolicyRule {
 
 	// Need additional policy rules if we are running on openshift, else the stateful set won't have the right
 	// permissions to start
-	rules = appendOpenShiftNonRootSCC(rules, client)
+	rules = appendOpenShiftNonRootSCC(rules, client, apis)
 
 	return rules
 }
 
-func policyRuleForRedisHa(client client.Client) []v1.PolicyRule {
+func policyRuleForRedisHa(client client.Client, apis *ClusterAPIs) []v1.PolicyRule {
 
 	rules := []v1.PolicyRule{
 		{
@@ -83,7 +83,7 @@ func policyRuleForRedisHa(client client.Client) []v1.PolicyRule {
 
 	// Need additional policy rules if we are running on openshift, else the stateful set won't have the right
 	// permissions to start
-	rules = appendOpenShiftNonRootSCC(rules, client)
+	rules = appendOpenShiftNonRootSCC(rules, client, apis)
 
 	return rules
 }
@@ -343,7 +343,7 @@ func policyRuleForServerClusterRole() []v1.PolicyRule {
 	}
 }
 
-func getPolicyRuleList(client client.Client) []struct {
+func getPolicyRuleList(client client.Client, apis *ClusterAPIs) []struct {
 	name       string
 	policyRule []v1.PolicyRule
 } {
@@ -362,10 +362,10 @@ func getPolicyRuleList(client client.Client) []struct {
 			policyRule: policyRuleForServer(),
 		}, {
 			name:       common.ArgoCDRedisHAComponent,
-			policyRule: policyRuleForRedisHa(client),
+			policyRule: policyRuleForRedisHa(client, apis),
 		}, {
 			name:       common.ArgoCDRedisComponent,
-			policyRule: policyRuleForRedis(client),
+			policyRule: policyRuleForRedis(client, apis),
 		},
 	}
 }
@@ -388,11 +388,11 @@ func getPolicyRuleClusterRoleList() []struct {
 	}
 }
 
-func appendOpenShiftNonRootSCC(rules []v1.PolicyRule, client client.Client) []v1.PolicyRule {
-	if IsVersionAPIAvailable() {
+func appendOpenShiftNonRootSCC(rules []v1.PolicyRule, client client.Client, apis *ClusterAPIs) []v1.PolicyRule {
+	if apis.IsVersionAPIAvailable() {
 		// Starting with OpenShift 4.11, we need to use the resource name "nonroot-v2" instead of "nonroot"
 		resourceName := "nonroot"
-		version, err := getClusterVersion(client)
+		version, err := getClusterVersion(client, apis)
 		if err != nil {
 			log.Error(err, "couldn't get OpenShift version")
 		}
@@ -28,15 +28,16 @@ import (
 	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
 )
 
-var prometheusAPIFound = false
+// componentStatusAlertRuleSuffix is the name suffix of the PrometheusRule tracking the status of the workloads.
+const componentStatusAlertRuleSuffix = "component-status-alert"
 
 // getPrometheusHost will return the hostname value for Prometheus.
-func getPrometheusHost(cr *argoproj.ArgoCD) string {
+func getPrometheusHost(cr *argoproj.ArgoCD) (string, error) {
 	host := nameWithSuffix("prometheus", cr)
 	if len(cr.Spec.Prometheus.Host) > 0 {
 		host = cr.Spec.Prometheus.Host
 	}
-	return host
+	return shortenHostname(host)
 }
 
 // getPrometheusSize will return the size value for the Prometheus replica count.
@@ -51,8 +52,13 @@ func getPrometheusReplicas(cr *argoproj.ArgoCD) *int32 {
 }
 
 // IsPrometheusAPIAvailable returns true if the Prometheus API is present.
-func IsPrometheusAPIAvailable() bool {
-	return prometheusAPIFound
+func (a *ClusterAPIs) IsPrometheusAPIAvailable() bool {
+	return a.isAvailable(func(a *ClusterAPIs) bool { return a.prometheus })
+}
+
+// setPrometheusAPIAvailable records whether the Prometheus API is present.
+func (a *ClusterAPIs) setPrometheusAPIAvailable(found bool) {
+	a.setAvailable(func(a *ClusterAPIs) { a.prometheus = found })
 }
 
 // hasPrometheusSpecChanged will return true if the supported properties differs in the actual versus the desired state.
@@ -75,12 +81,12 @@ func hasPrometheusSpecChanged(actual *monitoringv1.Prometheus, desired *argoproj
 }
 
 // verifyPrometheusAPI will verify that the Prometheus API is present.
-func verifyPrometheusAPI() error {
+func (a *ClusterAPIs) verifyPrometheusAPI() error {
 	found, err := argoutil.VerifyAPI(monitoringv1.SchemeGroupVersion.Group, monitoringv1.SchemeGroupVersion.Version)
 	if err != nil {
 		return err
 	}
-	prometheusAPIFound = found
+	a.setPrometheusAPIAvailable(found)
 	return nil
 }
 
@@ -252,7 +258,11 @@ func (r *ReconcileArgoCD) reconcileServerMetricsServiceMonitor(cr *argoproj.Argo
 // reconcilePrometheusRule reconciles the PrometheusRule that triggers alerts based on workload statuses
 func (r *ReconcileArgoCD) reconcilePrometheusRule(cr *argoproj.ArgoCD) error {
 
-	promRule := newPrometheusRule(cr.Namespace, "argocd-component-status-alert")
+	if err := r.deleteLegacyPrometheusRule(cr); err != nil {
+		return err
+	}
+
+	promRule := newPrometheusRule(cr.Namespace, nameWithSuffix(componentStatusAlertRuleSuffix, cr))
 
 	if argoutil.IsObjectFound(r.Client, cr.Namespace, promRule.Name, promRule) {
 
@@ -383,6 +393,22 @@ func (r *ReconcileArgoCD) reconcilePrometheusRule(cr *argoproj.ArgoCD) error {
 	return r.Client.Create(context.TODO(), promRule) // Create PrometheusRule
 }
 
+// deleteLegacyPrometheusRule deletes the PrometheusRule of the given ArgoCD created under the name shared by every
+// instance of the namespace, before the rule was named after its instance.
+func (r *ReconcileArgoCD) deleteLegacyPrometheusRule(cr *argoproj.ArgoCD) error {
+	legacyName := fmt.Sprintf("%s-%s", common.ArgoCDAppName, componentStatusAlertRuleSuffix)
+	if legacyName == nameWithSuffix(componentStatusAlertRuleSuffix, cr) {
+		return nil
+	}
+
+	promRule := newPrometheusRule(cr.Namespace, legacyName)
+	if !argoutil.IsObjectFound(r.Client, cr.Namespace, promRule.Name, promRule) || !metav1.IsControlledBy(promRule, cr) {
+		return nil
+	}
+	log.Info(fmt.Sprintf("deleting legacy prometheusRule %s", promRule.Name))
+	return r.Client.Delete(context.TODO(), promRule)
+}
+
 // newPrometheusRule returns an empty PrometheusRule
 func newPrometheusRule(namespace, alertRuleName string) *monitoringv1.PrometheusRule {
 
@@ -7,10 +7,13 @@ import (
 
 	monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
 	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
 )
@@ -208,3 +211,29 @@ func TestReconcileWorkloadStatusAlertRule(t *testing.T) {
 		})
 	}
 }
+
+func TestReconcileArgoCD_reconcilePrometheusRule_perInstance(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Name = "prod"
+		a.Spec.Monitoring.Enabled = true
+	})
+
+	resObjs := []client.Object{a}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme, monitoringv1.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	// A rule created by the instance under the name shared by every instance of the namespace
+	legacy := newPrometheusRule(a.Namespace, "argocd-component-status-alert")
+	assert.NoError(t, controllerutil.SetControllerReference(a, legacy, r.Scheme))
+	assert.NoError(t, r.Client.Create(context.TODO(), legacy))
+
+	assert.NoError(t, r.reconcilePrometheusRule(a))
+
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "prod-component-status-alert", Namespace: a.Namespace}, &monitoringv1.PrometheusRule{}))
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: "argocd-component-status-alert", Namespace: a.Namespace}, &monitoringv1.PrometheusRule{})
+	assert.True(t, apierrors.IsNotFound(err))
+}
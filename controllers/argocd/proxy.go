@@ -0,0 +1,170 @@
+package argocd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
+	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
+)
+
+// trustedCABundleFile is the name of the file holding the system CA bundle merged with the trusted CA bundle.
+const trustedCABundleFile = "ca-certificates.crt"
+
+// getProxyEnvVars will return the given environment followed by the proxy settings of the given ArgoCD.
+// The proxy settings of the operator are used when the ArgoCD does not define any.
+func getProxyEnvVars(cr *argoproj.ArgoCD, vars ...corev1.EnvVar) []corev1.EnvVar {
+	proxy := cr.Spec.Proxy
+	if proxy == nil {
+		return proxyEnvVars(vars...)
+	}
+
+	result := []corev1.EnvVar{}
+	result = append(result, vars...)
+	if proxy.HTTPProxy != "" {
+		result = append(result, corev1.EnvVar{Name: "HTTP_PROXY", Value: proxy.HTTPProxy})
+	}
+	if proxy.HTTPSProxy != "" {
+		result = append(result, corev1.EnvVar{Name: "HTTPS_PROXY", Value: proxy.HTTPSProxy})
+	}
+	if proxy.HTTPProxy != "" || proxy.HTTPSProxy != "" {
+		result = append(result, corev1.EnvVar{Name: "NO_PROXY", Value: getNoProxy(cr)})
+	}
+	return result
+}
+
+// getNoProxy will return the NO_PROXY value for the given ArgoCD: the user provided entries followed by the
+// cluster internal domains and the Kubernetes API server, so that traffic between the components and to the
+// API server never goes through the proxy.
+func getNoProxy(cr *argoproj.ArgoCD) string {
+	entries := []string{}
+	if cr.Spec.Proxy != nil {
+		entries = append(entries, strings.Split(cr.Spec.Proxy.NoProxy, ",")...)
+	}
+	entries = append(entries, "localhost", "127.0.0.1", ".svc", ".cluster.local")
+	if host := os.Getenv("KUBERNETES_SERVICE_HOST"); host != "" {
+		entries = append(entries, host)
+	}
+
+	seen := map[string]bool{}
+	result := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || seen[entry] {
+			continue
+		}
+		seen[entry] = true
+		result = append(result, entry)
+	}
+	return strings.Join(result, ",")
+}
+
+// getTrustedCAEnv will return the environment pointing the TLS clients of a component to the merged CA bundle.
+func getTrustedCAEnv(cr *argoproj.ArgoCD) []corev1.EnvVar {
+	if cr.Spec.TrustedCA == nil {
+		return nil
+	}
+	bundle := fmt.Sprintf("%s/%s", common.ArgoCDTrustedCABundleMountPath, trustedCABundleFile)
+	return []corev1.EnvVar{
+		{Name: "SSL_CERT_FILE", Value: bundle},
+		{Name: "GIT_SSL_CAINFO", Value: bundle},
+	}
+}
+
+// getTrustedCAVolumes will return the volumes holding the trusted CA bundle and the merged CA bundle, if any.
+func getTrustedCAVolumes(cr *argoproj.ArgoCD) []corev1.Volume {
+	ca := cr.Spec.TrustedCA
+	if ca == nil {
+		return nil
+	}
+	return []corev1.Volume{
+		{
+			Name: common.ArgoCDTrustedCAVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: ca.LocalObjectReference,
+					Items: []corev1.KeyToPath{{
+						Key:  ca.Key,
+						Path: "ca-bundle.crt",
+					}},
+				},
+			},
+		},
+		{
+			Name: common.ArgoCDTrustedCABundleVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		},
+	}
+}
+
+// getTrustedCAVolumeMounts will return the mount of the merged CA bundle, if any.
+func getTrustedCAVolumeMounts(cr *argoproj.ArgoCD) []corev1.VolumeMount {
+	if cr.Spec.TrustedCA == nil {
+		return nil
+	}
+	return []corev1.VolumeMount{{
+		Name:      common.ArgoCDTrustedCABundleVolumeName,
+		MountPath: common.ArgoCDTrustedCABundleMountPath,
+		ReadOnly:  true,
+	}}
+}
+
+// getTrustedCAInitContainers will return the init container merging the system CA bundle of the given image
+// with the trusted CA bundle of the ArgoCD, if any.
+func getTrustedCAInitContainers(cr *argoproj.ArgoCD, image string) []corev1.Container {
+	if cr.Spec.TrustedCA == nil {
+		return nil
+	}
+
+	script := fmt.Sprintf(`for f in /etc/ssl/certs/ca-certificates.crt /etc/pki/tls/certs/ca-bundle.crt /etc/ssl/cert.pem; do
+  if [ -f "$f" ]; then cat "$f"; break; fi
+done > %[1]s/%[2]s
+cat %[3]s/ca-bundle.crt >> %[1]s/%[2]s`, common.ArgoCDTrustedCABundleMountPath, trustedCABundleFile, common.ArgoCDTrustedCAMountPath)
+
+	return []corev1.Container{{
+		Name:            "trusted-ca",
+		Image:           image,
+		ImagePullPolicy: corev1.PullAlways,
+		Command:         []string{"sh", "-c", script},
+		SecurityContext: &corev1.SecurityContext{
+			AllowPrivilegeEscalation: boolPtr(false),
+			Capabilities: &corev1.Capabilities{
+				Drop: []corev1.Capability{
+					"ALL",
+				},
+			},
+			RunAsNonRoot: boolPtr(true),
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      common.ArgoCDTrustedCAVolumeName,
+				MountPath: common.ArgoCDTrustedCAMountPath,
+			},
+			{
+				Name:      common.ArgoCDTrustedCABundleVolumeName,
+				MountPath: common.ArgoCDTrustedCABundleMountPath,
+			},
+		},
+	}}
+}
+
+// addTrustedCA will add the init container, volumes, mounts and environment required to use the trusted CA
+// bundle of the given ArgoCD to the containers of the given pod built by the operator, using the given image to merge
+// the bundles. The environment set by the user is kept.
+func addTrustedCA(cr *argoproj.ArgoCD, podSpec *corev1.PodSpec, image string) {
+	if cr.Spec.TrustedCA == nil || len(podSpec.Containers) == 0 {
+		return
+	}
+	podSpec.InitContainers = append(podSpec.InitContainers, getTrustedCAInitContainers(cr, image)...)
+	podSpec.Volumes = append(podSpec.Volumes, getTrustedCAVolumes(cr)...)
+	for i := range podSpec.Containers {
+		podSpec.Containers[i].VolumeMounts = append(podSpec.Containers[i].VolumeMounts, getTrustedCAVolumeMounts(cr)...)
+		podSpec.Containers[i].Env = argoutil.EnvMerge(podSpec.Containers[i].Env, getTrustedCAEnv(cr), false)
+	}
+}
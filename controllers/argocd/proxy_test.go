@@ -0,0 +1,178 @@
+package argocd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
+)
+
+func Test_getProxyEnvVars(t *testing.T) {
+	t.Setenv("HTTP_PROXY", testHTTPProxy)
+	t.Setenv("KUBERNETES_SERVICE_HOST", "172.30.0.1")
+
+	t.Run("operator proxy is used by default", func(t *testing.T) {
+		a := makeTestArgoCD()
+		assert.Equal(t, []corev1.EnvVar{
+			{Name: "HOME", Value: "/home/argocd"},
+			{Name: "HTTP_PROXY", Value: testHTTPProxy},
+		}, getProxyEnvVars(a, corev1.EnvVar{Name: "HOME", Value: "/home/argocd"}))
+	})
+
+	t.Run("instance proxy replaces the operator proxy", func(t *testing.T) {
+		a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+			a.Spec.Proxy = &argoproj.ArgoCDProxySpec{
+				HTTPSProxy: "https://proxy.example.com:3128",
+				NoProxy:    "git.internal, .example.com,localhost",
+			}
+		})
+		assert.Equal(t, []corev1.EnvVar{
+			{Name: "HTTPS_PROXY", Value: "https://proxy.example.com:3128"},
+			{Name: "NO_PROXY", Value: "git.internal,.example.com,localhost,127.0.0.1,.svc,.cluster.local,172.30.0.1"},
+		}, getProxyEnvVars(a))
+	})
+
+	t.Run("empty instance proxy disables the operator proxy", func(t *testing.T) {
+		a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+			a.Spec.Proxy = &argoproj.ArgoCDProxySpec{}
+		})
+		assert.Empty(t, getProxyEnvVars(a))
+	})
+}
+
+func TestReconcileArgoCD_reconcileRepoDeployment_trustedCA(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD()
+
+	resObjs := []client.Object{a}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileRepoDeployment(a, false))
+
+	// Setting a trusted CA updates the existing deployment
+	a.Spec.TrustedCA = &corev1.ConfigMapKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "corporate-ca"},
+		Key:                  "ca-bundle.crt",
+	}
+	assert.NoError(t, r.reconcileRepoDeployment(a, false))
+
+	deployment := &appsv1.Deployment{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "argocd-repo-server", Namespace: a.Namespace}, deployment))
+	podSpec := deployment.Spec.Template.Spec
+
+	initContainer := podSpec.InitContainers[len(podSpec.InitContainers)-1]
+	assert.Equal(t, "trusted-ca", initContainer.Name)
+	assert.Equal(t, getRepoServerContainerImage(a), initContainer.Image)
+
+	assert.Contains(t, podSpec.Volumes, corev1.Volume{
+		Name: common.ArgoCDTrustedCAVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "corporate-ca"},
+				Items:                []corev1.KeyToPath{{Key: "ca-bundle.crt", Path: "ca-bundle.crt"}},
+			},
+		},
+	})
+	assert.Contains(t, podSpec.Containers[0].VolumeMounts, corev1.VolumeMount{
+		Name:      common.ArgoCDTrustedCABundleVolumeName,
+		MountPath: common.ArgoCDTrustedCABundleMountPath,
+		ReadOnly:  true,
+	})
+	assert.Contains(t, podSpec.Containers[0].Env, corev1.EnvVar{Name: "SSL_CERT_FILE", Value: "/app/config/trusted-ca-bundle/ca-certificates.crt"})
+	assert.Contains(t, podSpec.Containers[0].Env, corev1.EnvVar{Name: "GIT_SSL_CAINFO", Value: "/app/config/trusted-ca-bundle/ca-certificates.crt"})
+
+	// Removing the trusted CA restores the deployment
+	a.Spec.TrustedCA = nil
+	assert.NoError(t, r.reconcileRepoDeployment(a, false))
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "argocd-repo-server", Namespace: a.Namespace}, deployment))
+	for _, c := range deployment.Spec.Template.Spec.InitContainers {
+		assert.NotEqual(t, "trusted-ca", c.Name)
+	}
+	assert.NotContains(t, deployment.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{Name: "SSL_CERT_FILE", Value: "/app/config/trusted-ca-bundle/ca-certificates.crt"})
+}
+
+func TestReconcileArgoCD_reconcileServerDeployment_trustedCA(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD()
+
+	resObjs := []client.Object{a}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileServerDeployment(a, false))
+
+	a.Spec.TrustedCA = &corev1.ConfigMapKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "corporate-ca"},
+		Key:                  "ca-bundle.crt",
+	}
+	assert.NoError(t, r.reconcileServerDeployment(a, false))
+
+	deployment := &appsv1.Deployment{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "argocd-server", Namespace: a.Namespace}, deployment))
+	assert.Len(t, deployment.Spec.Template.Spec.InitContainers, 1)
+	assert.Equal(t, "trusted-ca", deployment.Spec.Template.Spec.InitContainers[0].Name)
+	assert.Contains(t, deployment.Spec.Template.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+		Name:      common.ArgoCDTrustedCABundleVolumeName,
+		MountPath: common.ArgoCDTrustedCABundleMountPath,
+		ReadOnly:  true,
+	})
+}
+
+func TestReconcileArgoCD_reconcileRepoDeployment_trustedCAPluginSidecars(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.TrustedCA = &corev1.ConfigMapKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "corporate-ca"},
+			Key:                  "ca-bundle.crt",
+		}
+		a.Spec.Repo.Plugins = []argoproj.ArgoCDRepoPluginSpec{{
+			Name:  "envsubst",
+			Image: "quay.io/example/envsubst:latest",
+			Spec: argoproj.ArgoCDPluginSpec{
+				Generate: argoproj.ArgoCDPluginCommand{Command: []string{"envsubst"}},
+			},
+		}}
+		a.Spec.Repo.Env = []corev1.EnvVar{{Name: "GIT_SSL_CAINFO", Value: "/custom/ca.crt"}}
+		a.Spec.Repo.SidecarContainers = []corev1.Container{{Name: "sidecar", Image: "quay.io/example/sidecar:latest"}}
+	})
+
+	resObjs := []client.Object{a}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileRepoDeployment(a, false))
+
+	deployment := &appsv1.Deployment{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "argocd-repo-server", Namespace: a.Namespace}, deployment))
+	containers := deployment.Spec.Template.Spec.Containers
+	assert.Len(t, containers, 3)
+	assert.Equal(t, a.Spec.Repo.SidecarContainers[0], containers[2])
+	assert.Contains(t, containers[0].Env, corev1.EnvVar{Name: "GIT_SSL_CAINFO", Value: "/custom/ca.crt"})
+	for _, c := range containers[:2] {
+		assert.Contains(t, c.VolumeMounts, corev1.VolumeMount{
+			Name:      common.ArgoCDTrustedCABundleVolumeName,
+			MountPath: common.ArgoCDTrustedCABundleMountPath,
+			ReadOnly:  true,
+		}, c.Name)
+		assert.Contains(t, c.Env, corev1.EnvVar{Name: "SSL_CERT_FILE", Value: "/app/config/trusted-ca-bundle/ca-certificates.crt"}, c.Name)
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2019 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argocd
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
+	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
+)
+
+// pruneResources deletes the objects of the given ArgoCD that the reconciliation neither read nor wrote, such as the
+// resources of a component that was disabled or renamed. The objects of the ArgoCD are the ones labeled as managed by
+// it, and either controlled by it or annotated with its name and namespace, like the cluster-scoped resources. With
+// the prune dry-run annotation, the objects are only logged.
+func (r *ReconcileArgoCD) pruneResources(cr *argoproj.ArgoCD) error {
+	inventory := inventoryOf(r.Client)
+	if inventory == nil {
+		// the objects of the reconciliation are unknown
+		return nil
+	}
+	dryRun := cr.Annotations[common.AnnotationPruneDryRun] == "true"
+
+	for _, obj := range managedObjects(r.ClusterAPIs) {
+		if _, ok := obj.(*corev1.Namespace); ok {
+			continue
+		}
+		gvk, err := apiutil.GVKForObject(obj, r.Scheme)
+		if runtime.IsNotRegisteredError(err) {
+			// the operator does not manage this kind
+			continue
+		} else if err != nil {
+			return err
+		}
+		list, err := r.Scheme.New(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err != nil {
+			return err
+		}
+		objList, ok := list.(client.ObjectList)
+		if !ok {
+			return fmt.Errorf("%s is not a list", gvk.Kind+"List")
+		}
+		if err := r.Client.List(context.TODO(), objList, client.MatchingLabels{common.ArgoCDKeyManagedBy: cr.Name}); err != nil {
+			return fmt.Errorf("failed to list the %s objects of ArgoCD %s/%s: %w", gvk.Kind, cr.Namespace, cr.Name, err)
+		}
+		items, err := meta.ExtractList(objList)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			obj, ok := item.(client.Object)
+			if !ok || !isOwnedByArgoCD(cr, obj) || obj.GetDeletionTimestamp() != nil || inventory.wasSeen(gvk.Kind, obj) {
+				continue
+			}
+			if dryRun {
+				log.Info(fmt.Sprintf("%s %s/%s of ArgoCD %s/%s is no longer desired, skipping its pruning in dry-run", gvk.Kind, obj.GetNamespace(), obj.GetName(), cr.Namespace, cr.Name))
+				continue
+			}
+			log.Info(fmt.Sprintf("pruning %s %s/%s of ArgoCD %s/%s, it is no longer desired", gvk.Kind, obj.GetNamespace(), obj.GetName(), cr.Namespace, cr.Name))
+			if err := r.Client.Delete(context.TODO(), obj, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("failed to prune %s %s/%s: %w", gvk.Kind, obj.GetNamespace(), obj.GetName(), err)
+			}
+
+			message := fmt.Sprintf("%s %s/%s was pruned, it is no longer desired", gvk.Kind, obj.GetNamespace(), obj.GetName())
+			typeMeta := metav1.TypeMeta{Kind: "ArgoCD", APIVersion: argoproj.GroupVersion.String()}
+			if err := argoutil.CreateEvent(r.Client, corev1.EventTypeNormal, "Prune", message, "ResourcePruned", cr.ObjectMeta, typeMeta); err != nil {
+				log.Error(err, fmt.Sprintf("failed to report the pruning of %s %s/%s", gvk.Kind, obj.GetNamespace(), obj.GetName()))
+			}
+		}
+	}
+	return nil
+}
+
+// isOwnedByArgoCD returns true when the given object is controlled by the given ArgoCD, or annotated with its name
+// and namespace. An owner reference only stands for an owner of the same namespace.
+func isOwnedByArgoCD(cr *argoproj.ArgoCD, obj client.Object) bool {
+	if obj.GetNamespace() == cr.Namespace && metav1.IsControlledBy(obj, cr) {
+		return true
+	}
+	annotations := obj.GetAnnotations()
+	return annotations[common.AnnotationName] == cr.Name && annotations[common.AnnotationNamespace] == cr.Namespace
+}
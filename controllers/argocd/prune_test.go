@@ -0,0 +1,144 @@
+// Copyright 2019 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argocd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/argoproj-labs/argocd-operator/api/v1alpha1"
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
+)
+
+func makeTestPruneReconciler(t *testing.T, a *argoproj.ArgoCD, objs ...client.Object) (*ReconcileArgoCD, reconcile.Request) {
+	resObjs := append([]client.Object{a}, objs...)
+	subresObjs := []client.Object{a}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme, v1alpha1.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, nil)
+	r := makeTestReconciler(cl, sch)
+	assert.NoError(t, createNamespace(r, a.Namespace, ""))
+
+	return r, reconcile.Request{NamespacedName: types.NamespacedName{Name: a.Name, Namespace: a.Namespace}}
+}
+
+func getResourcePrunedEvents(t *testing.T, r *ReconcileArgoCD) []string {
+	events := &corev1.EventList{}
+	assert.NoError(t, r.Client.List(context.TODO(), events, client.InNamespace(testNamespace)))
+	var messages []string
+	for _, event := range events.Items {
+		if event.Reason == "ResourcePruned" {
+			messages = append(messages, event.Message)
+		}
+	}
+	return messages
+}
+
+func TestReconcileArgoCD_Reconcile_prune(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.Notifications.Enabled = true
+	})
+	// a secret labeled by a user, that the operator does not own
+	userSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "user-secret",
+			Namespace: testNamespace,
+			Labels:    map[string]string{common.ArgoCDKeyManagedBy: a.Name},
+		},
+	}
+	r, req := makeTestPruneReconciler(t, a, userSecret)
+
+	_, err := r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+	sa := types.NamespacedName{Name: "argocd-argocd-notifications-controller", Namespace: testNamespace}
+	assert.NoError(t, r.Client.Get(context.TODO(), sa, &corev1.ServiceAccount{}))
+
+	// reconciling again prunes nothing
+	_, err = r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+	assert.Empty(t, getResourcePrunedEvents(t, r))
+
+	// the resources left behind by the disabled notifications controller are pruned
+	assert.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, a))
+	a.Spec.Notifications.Enabled = false
+	assert.NoError(t, r.Client.Update(context.TODO(), a))
+	_, err = r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+
+	err = r.Client.Get(context.TODO(), sa, &corev1.ServiceAccount{})
+	assert.True(t, apierrors.IsNotFound(err))
+	assert.Contains(t, getResourcePrunedEvents(t, r), "ServiceAccount argocd/argocd-argocd-notifications-controller was pruned, it is no longer desired")
+
+	// but the objects the operator does not own are kept
+	assert.NoError(t, r.Client.Get(context.TODO(), client.ObjectKeyFromObject(userSecret), &corev1.Secret{}))
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "argocd-argocd-server", Namespace: testNamespace}, &corev1.ServiceAccount{}))
+}
+
+func TestReconcileArgoCD_Reconcile_pruneDryRun(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Annotations = map[string]string{common.AnnotationPruneDryRun: "true"}
+		a.Spec.Notifications.Enabled = true
+	})
+	r, req := makeTestPruneReconciler(t, a)
+
+	_, err := r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+
+	assert.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, a))
+	a.Spec.Notifications.Enabled = false
+	assert.NoError(t, r.Client.Update(context.TODO(), a))
+	_, err = r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+
+	// nothing is pruned
+	sa := types.NamespacedName{Name: "argocd-argocd-notifications-controller", Namespace: testNamespace}
+	assert.NoError(t, r.Client.Get(context.TODO(), sa, &corev1.ServiceAccount{}))
+	assert.Empty(t, getResourcePrunedEvents(t, r))
+}
+
+func TestIsOwnedByArgoCD(t *testing.T) {
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.UID = "uid"
+	})
+	controlled := &corev1.ConfigMap{}
+	controlled.Namespace = a.Namespace
+	controlled.OwnerReferences = []metav1.OwnerReference{{UID: "uid", Controller: boolPtr(true)}}
+	controlledElsewhere := controlled.DeepCopy()
+	controlledElsewhere.Namespace = "other"
+	annotated := &corev1.ConfigMap{}
+	annotated.Annotations = map[string]string{common.AnnotationName: a.Name, common.AnnotationNamespace: a.Namespace}
+	otherNamespace := &corev1.ConfigMap{}
+	otherNamespace.Annotations = map[string]string{common.AnnotationName: a.Name, common.AnnotationNamespace: "other"}
+	otherOwner := &corev1.ConfigMap{}
+	otherOwner.OwnerReferences = []metav1.OwnerReference{{UID: "other", Controller: boolPtr(true)}}
+
+	assert.True(t, isOwnedByArgoCD(a, controlled))
+	assert.True(t, isOwnedByArgoCD(a, annotated))
+	assert.False(t, isOwnedByArgoCD(a, otherNamespace))
+	assert.False(t, isOwnedByArgoCD(a, otherOwner))
+	assert.False(t, isOwnedByArgoCD(a, controlledElsewhere))
+	assert.False(t, isOwnedByArgoCD(a, &corev1.ConfigMap{}))
+}
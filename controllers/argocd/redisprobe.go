@@ -0,0 +1,82 @@
+// Copyright 2019 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argocd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
+)
+
+// redisProbeInterval is how long the result of the probe of a remote Redis is reused before it is probed again.
+const redisProbeInterval = time.Minute
+
+// redisProbeCache keeps the result of the latest probe of the remote Redis of each ArgoCD, so that the sentinels and
+// the Redis servers, each probed with a timeout, are contacted once per redisProbeInterval at most rather than on every
+// reconciliation. A change of the addresses or options probes again at once. It is safe for concurrent use, a nil
+// redisProbeCache probes every time.
+type redisProbeCache struct {
+	mu      sync.Mutex
+	results map[string]redisProbeResult
+}
+
+// redisProbeResult is the result of a probe, along with the checksum of the addresses and options it was made with.
+type redisProbeResult struct {
+	checksum string
+	err      error
+	time     time.Time
+}
+
+func newRedisProbeCache() *redisProbeCache {
+	return &redisProbeCache{results: make(map[string]redisProbeResult)}
+}
+
+// probe returns the result of the latest probe of the given key when it was made with the same addresses and options
+// less than redisProbeInterval ago, and runs the given probe otherwise.
+func (c *redisProbeCache) probe(key string, addrs []string, opts argoutil.RedisProbeOptions, probe func() error) error {
+	if c == nil {
+		return probe()
+	}
+
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%v/%+v", addrs, opts)))
+	checksum := hex.EncodeToString(sum[:])
+
+	c.mu.Lock()
+	result, ok := c.results[key]
+	c.mu.Unlock()
+	if ok && result.checksum == checksum && time.Since(result.time) < redisProbeInterval {
+		return result.err
+	}
+
+	err := probe()
+	c.mu.Lock()
+	c.results[key] = redisProbeResult{checksum: checksum, err: err, time: time.Now()}
+	c.mu.Unlock()
+	return err
+}
+
+// forget removes the result of the latest probe of the given key.
+func (c *redisProbeCache) forget(key string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.results, key)
+}
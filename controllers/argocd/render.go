@@ -0,0 +1,256 @@
+// Copyright 2019 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argocd
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscaling "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/argoproj-labs/argocd-operator/api/v1alpha1"
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/controllers/inmemory"
+)
+
+// renderPasses is the number of reconciliations Render runs, so that the resources depending on the status of the
+// ArgoCD, updated by the previous reconciliation, are rendered as well.
+const renderPasses = 2
+
+// RenderOptions configures Render.
+type RenderOptions struct {
+	// Existing are the objects held by the cluster before the reconciliation.
+	Existing []client.Object
+
+	// APIs are the names of the optional APIs served by the cluster: route, gateway, grpcroute or prometheus.
+	APIs []string
+}
+
+// Render runs the reconciliation of the given ArgoCD against an in-memory cluster holding the existing objects of
+// opts, without contacting any cluster, and returns the resulting objects managed by the operator, without their
+// status, sorted by kind, namespace and name. The scheme must hold the types of the existing objects and of the optional APIs.
+func Render(ctx context.Context, sch *runtime.Scheme, cr *argoproj.ArgoCD, opts RenderOptions) ([]*unstructured.Unstructured, error) {
+	rr, err := newRenderer(sch, cr, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer rr.instances.forget(cr)
+	if err := rr.reconcile(ctx); err != nil {
+		return nil, err
+	}
+	return rr.objects(ctx)
+}
+
+// RenderRevisions runs the reconciliation of the previous revision of an ArgoCD, then of its current revision, against
+// the same in-memory cluster, and returns the objects managed by the operator after each reconciliation. As with
+// Render, no cluster is contacted. Both revisions must have the same namespace and name.
+func RenderRevisions(ctx context.Context, sch *runtime.Scheme, previous, cr *argoproj.ArgoCD, opts RenderOptions) ([]*unstructured.Unstructured, []*unstructured.Unstructured, error) {
+	if client.ObjectKeyFromObject(previous) != client.ObjectKeyFromObject(cr) {
+		return nil, nil, fmt.Errorf("the revisions of the ArgoCD must have the same namespace and name, found %s and %s", client.ObjectKeyFromObject(previous), client.ObjectKeyFromObject(cr))
+	}
+	rr, err := newRenderer(sch, previous, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rr.instances.forget(cr)
+	if err := rr.reconcile(ctx); err != nil {
+		return nil, nil, err
+	}
+	before, err := rr.objects(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the current revision replaces the previous one, as a user would edit the ArgoCD
+	existing := &argoproj.ArgoCD{}
+	if err := rr.Client.Get(ctx, client.ObjectKeyFromObject(cr), existing); err != nil {
+		return nil, nil, err
+	}
+	existing.Labels = cr.Labels
+	existing.Annotations = cr.Annotations
+	existing.Spec = cr.Spec
+	if err := rr.Client.Update(ctx, existing); err != nil {
+		return nil, nil, err
+	}
+	if err := rr.reconcile(ctx); err != nil {
+		return nil, nil, err
+	}
+	after, err := rr.objects(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return before, after, nil
+}
+
+// renderer reconciles an ArgoCD against an in-memory cluster.
+type renderer struct {
+	*ReconcileArgoCD
+	request ctrl.Request
+}
+
+func newRenderer(sch *runtime.Scheme, cr *argoproj.ArgoCD, opts RenderOptions) (*renderer, error) {
+	apis, err := newRenderClusterAPIs(opts.APIs)
+	if err != nil {
+		return nil, err
+	}
+
+	cr = cr.DeepCopy()
+	cr.ResourceVersion = ""
+	objs := append([]client.Object{cr}, opts.Existing...)
+	if !hasObject(objs, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: cr.Namespace}}) {
+		objs = append(objs, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: cr.Namespace}})
+	}
+	c := inmemory.NewClientBuilder().
+		WithScheme(sch).
+		WithObjects(objs...).
+		WithStatusSubresource(cr).
+		Build()
+
+	return &renderer{
+		ReconcileArgoCD: &ReconcileArgoCD{
+			Client:      c,
+			Scheme:      sch,
+			ClusterAPIs: apis,
+			instances:   newInstanceTracker(),
+			offline:     true,
+		},
+		request: ctrl.Request{NamespacedName: client.ObjectKeyFromObject(cr)},
+	}, nil
+}
+
+// reconcile runs the reconciliation passes of the ArgoCD.
+func (rr *renderer) reconcile(ctx context.Context) error {
+	for i := 0; i < renderPasses; i++ {
+		if _, err := rr.Reconcile(ctx, rr.request); err != nil {
+			return fmt.Errorf("failed to reconcile ArgoCD %s: %w", rr.request.NamespacedName, err)
+		}
+	}
+	return nil
+}
+
+// objects returns the objects managed by the operator held by the in-memory cluster, sorted by kind, namespace and
+// name.
+func (rr *renderer) objects(ctx context.Context) ([]*unstructured.Unstructured, error) {
+	var rendered []*unstructured.Unstructured
+	for _, obj := range managedObjects(rr.ClusterAPIs) {
+		gvk, err := apiutil.GVKForObject(obj, rr.Scheme)
+		if err != nil {
+			// the type is not known by the scheme, the operator cannot have created such objects
+			continue
+		}
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := rr.Client.List(ctx, list); err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			item := &list.Items[i]
+			item.SetGroupVersionKind(gvk)
+			item.SetResourceVersion("")
+			item.SetManagedFields(nil)
+			unstructured.RemoveNestedField(item.Object, "metadata", "creationTimestamp")
+			unstructured.RemoveNestedField(item.Object, "status")
+			rendered = append(rendered, item)
+		}
+	}
+	sort.SliceStable(rendered, func(i, j int) bool {
+		if rendered[i].GetKind() != rendered[j].GetKind() {
+			return rendered[i].GetKind() < rendered[j].GetKind()
+		}
+		if rendered[i].GetNamespace() != rendered[j].GetNamespace() {
+			return rendered[i].GetNamespace() < rendered[j].GetNamespace()
+		}
+		return rendered[i].GetName() < rendered[j].GetName()
+	})
+	return rendered, nil
+}
+
+// newRenderClusterAPIs returns the ClusterAPIs reporting the given optional APIs as served.
+func newRenderClusterAPIs(names []string) (*ClusterAPIs, error) {
+	apis := &ClusterAPIs{}
+	for _, name := range names {
+		switch name {
+		case "route":
+			apis.setRouteAPIAvailable(true)
+		case "gateway":
+			apis.setGatewayAPIAvailable(true, apis.IsGRPCRouteAPIAvailable())
+		case "grpcroute":
+			apis.setGatewayAPIAvailable(apis.IsGatewayAPIAvailable(), true)
+		case "prometheus":
+			apis.setPrometheusAPIAvailable(true)
+		default:
+			return nil, fmt.Errorf("unsupported API %q, must be one of route, gateway, grpcroute or prometheus", name)
+		}
+	}
+	return apis, nil
+}
+
+// managedObjects returns an object of each kind managed by the operator, including the sub-resources of the optional
+// APIs.
+func managedObjects(apis *ClusterAPIs) []client.Object {
+	objs := []client.Object{
+		&corev1.Namespace{},
+		&corev1.ConfigMap{},
+		&corev1.Secret{},
+		&corev1.Service{},
+		&corev1.ServiceAccount{},
+		&appsv1.Deployment{},
+		&appsv1.StatefulSet{},
+		&rbacv1.Role{},
+		&rbacv1.RoleBinding{},
+		&rbacv1.ClusterRole{},
+		&rbacv1.ClusterRoleBinding{},
+		&networkingv1.Ingress{},
+		&networkingv1.NetworkPolicy{},
+		&autoscaling.HorizontalPodAutoscaler{},
+		&v1alpha1.NotificationsConfiguration{},
+	}
+	if apis.IsPrometheusAPIAvailable() {
+		objs = append(objs, &monitoringv1.PrometheusRule{})
+	}
+	if apis.IsGatewayAPIAvailable() {
+		objs = append(objs, &gatewayv1.Gateway{})
+	}
+	for _, i := range integrations() {
+		if i.available(apis) {
+			objs = append(objs, i.owns...)
+		}
+	}
+	return objs
+}
+
+// hasObject returns true when objs holds an object of the same type, namespace and name as obj.
+func hasObject(objs []client.Object, obj client.Object) bool {
+	for _, o := range objs {
+		if reflect.TypeOf(o) == reflect.TypeOf(obj) && client.ObjectKeyFromObject(o) == client.ObjectKeyFromObject(obj) {
+			return true
+		}
+	}
+	return false
+}
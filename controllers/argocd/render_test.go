@@ -0,0 +1,141 @@
+// Copyright 2019 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argocd
+
+import (
+	"context"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+)
+
+func findRendered(objs []*unstructured.Unstructured, kind, name string) *unstructured.Unstructured {
+	for _, obj := range objs {
+		if obj.GetKind() == kind && obj.GetName() == name {
+			return obj
+		}
+	}
+	return nil
+}
+
+func TestRender(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.Repo.Replicas = int32Ptr(2)
+	})
+	existing := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: testNamespace},
+		Data:       map[string]string{"foo": "bar"},
+	}
+
+	rendered, err := Render(context.TODO(), sch, a, RenderOptions{Existing: []client.Object{existing}})
+	assert.NoError(t, err)
+
+	repo := findRendered(rendered, "Deployment", "argocd-repo-server")
+	if assert.NotNil(t, repo) {
+		replicas, _, _ := unstructured.NestedInt64(repo.Object, "spec", "replicas")
+		assert.Equal(t, int64(2), replicas)
+		assert.Equal(t, "apps/v1", repo.GetAPIVersion())
+		assert.Empty(t, repo.GetResourceVersion())
+	}
+	assert.NotNil(t, findRendered(rendered, "ConfigMap", "argocd-cm"))
+	assert.NotNil(t, findRendered(rendered, "Namespace", testNamespace))
+	assert.NotNil(t, findRendered(rendered, "ConfigMap", "unrelated"))
+
+	// the objects are sorted and the ArgoCD is not part of them
+	for i := 1; i < len(rendered); i++ {
+		assert.LessOrEqual(t, rendered[i-1].GetKind(), rendered[i].GetKind())
+		assert.NotEqual(t, "ArgoCD", rendered[i].GetKind())
+	}
+
+	// the ArgoCD itself is left untouched
+	assert.Empty(t, a.Status.Phase)
+	assert.Empty(t, a.Finalizers)
+}
+
+func TestRender_apis(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme, configv1.Install, routev1.Install)
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.Server.Route.Enabled = true
+	})
+
+	rendered, err := Render(context.TODO(), sch, a, RenderOptions{})
+	assert.NoError(t, err)
+	assert.Nil(t, findRendered(rendered, "Route", "argocd-server"))
+
+	rendered, err = Render(context.TODO(), sch, a, RenderOptions{APIs: []string{"route"}})
+	assert.NoError(t, err)
+	assert.NotNil(t, findRendered(rendered, "Route", "argocd-server"))
+
+	_, err = Render(context.TODO(), sch, a, RenderOptions{APIs: []string{"keycloak"}})
+	assert.Error(t, err)
+}
+
+func TestRender_dex(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.SSO = &argoproj.ArgoCDSSOSpec{
+			Provider: argoproj.SSOProviderTypeDex,
+			Dex:      &argoproj.ArgoCDDexSpec{Config: "test-config"},
+		}
+	})
+
+	rendered, err := Render(context.TODO(), sch, a, RenderOptions{})
+	assert.NoError(t, err)
+	assert.NotNil(t, findRendered(rendered, "Deployment", "argocd-dex-server"))
+}
+
+func TestRenderRevisions(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	previous := makeTestArgoCD()
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.Repo.Replicas = int32Ptr(2)
+	})
+
+	before, after, err := RenderRevisions(context.TODO(), sch, previous, a, RenderOptions{})
+	assert.NoError(t, err)
+
+	// only the repo server changed, the generated secrets are kept
+	beforeRepo := findRendered(before, "Deployment", "argocd-repo-server")
+	afterRepo := findRendered(after, "Deployment", "argocd-repo-server")
+	if assert.NotNil(t, beforeRepo) && assert.NotNil(t, afterRepo) {
+		_, found, _ := unstructured.NestedInt64(beforeRepo.Object, "spec", "replicas")
+		assert.False(t, found)
+		replicas, _, _ := unstructured.NestedInt64(afterRepo.Object, "spec", "replicas")
+		assert.Equal(t, int64(2), replicas)
+	}
+	assert.Equal(t, findRendered(before, "Secret", "argocd-cluster"), findRendered(after, "Secret", "argocd-cluster"))
+	assert.Len(t, after, len(before))
+
+	// both revisions must be the same instance
+	other := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Name = "other"
+	})
+	_, _, err = RenderRevisions(context.TODO(), sch, previous, other, RenderOptions{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,485 @@
+// Copyright 2019 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argocd
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	k8syaml "sigs.k8s.io/yaml"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
+	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
+)
+
+const (
+	// repositorySecretType is the secret type label value of the repository Secrets read by Argo CD.
+	repositorySecretType = "repository"
+
+	// repositoryCredentialsSecretType is the secret type label value of the repository credential template Secrets
+	// read by Argo CD.
+	repositoryCredentialsSecretType = "repo-creds"
+)
+
+// errInvalidRepository reports a repository that cannot be turned into a Secret until it, or a Secret it references,
+// changes.
+var errInvalidRepository = errors.New("invalid repository")
+
+// legacyRepository is a repository, or a repository credential template, of the YAML lists of the deprecated
+// InitialRepositories and RepositoryCredentials properties, as read by Argo CD from argocd-cm.
+type legacyRepository struct {
+	URL                        string                    `json:"url"`
+	Type                       string                    `json:"type,omitempty"`
+	Name                       string                    `json:"name,omitempty"`
+	UsernameSecret             *corev1.SecretKeySelector `json:"usernameSecret,omitempty"`
+	PasswordSecret             *corev1.SecretKeySelector `json:"passwordSecret,omitempty"`
+	SSHPrivateKeySecret        *corev1.SecretKeySelector `json:"sshPrivateKeySecret,omitempty"`
+	TLSClientCertDataSecret    *corev1.SecretKeySelector `json:"tlsClientCertDataSecret,omitempty"`
+	TLSClientCertKeySecret     *corev1.SecretKeySelector `json:"tlsClientCertKeySecret,omitempty"`
+	GithubAppPrivateKeySecret  *corev1.SecretKeySelector `json:"githubAppPrivateKeySecret,omitempty"`
+	GithubAppID                int64                     `json:"githubAppID,omitempty"`
+	GithubAppInstallationID    int64                     `json:"githubAppInstallationID,omitempty"`
+	GithubAppEnterpriseBaseURL string                    `json:"githubAppEnterpriseBaseUrl,omitempty"`
+	Insecure                   bool                      `json:"insecure,omitempty"`
+	EnableLFS                  bool                      `json:"enableLfs,omitempty"`
+	EnableOCI                  bool                      `json:"enableOci,omitempty"`
+	Proxy                      string                    `json:"proxy,omitempty"`
+}
+
+// credentials returns the credentials of the legacy repository.
+func (l legacyRepository) credentials() argoproj.ArgoCDRepositoryCredentials {
+	creds := argoproj.ArgoCDRepositoryCredentials{
+		UsernameSecret:      l.UsernameSecret,
+		PasswordSecret:      l.PasswordSecret,
+		SSHPrivateKeySecret: l.SSHPrivateKeySecret,
+		TLSClientCertSecret: l.TLSClientCertDataSecret,
+		TLSClientKeySecret:  l.TLSClientCertKeySecret,
+	}
+	if l.GithubAppPrivateKeySecret != nil {
+		creds.GitHubApp = &argoproj.ArgoCDRepositoryGitHubApp{
+			ID:                l.GithubAppID,
+			InstallationID:    l.GithubAppInstallationID,
+			EnterpriseBaseURL: l.GithubAppEnterpriseBaseURL,
+			PrivateKeySecret:  *l.GithubAppPrivateKeySecret,
+		}
+	}
+	return creds
+}
+
+// parseLegacyRepositories parses the given YAML list of the deprecated InitialRepositories or RepositoryCredentials
+// properties.
+func parseLegacyRepositories(data string) ([]legacyRepository, error) {
+	repos := []legacyRepository{}
+	if strings.TrimSpace(data) == "" {
+		return repos, nil
+	}
+	if err := k8syaml.Unmarshal([]byte(data), &repos); err != nil {
+		return nil, err
+	}
+	for _, repo := range repos {
+		if repo.URL == "" {
+			return nil, fmt.Errorf("repository without url")
+		}
+	}
+	return repos, nil
+}
+
+// getRepositories will return the repositories of the given ArgoCD, the ones of the deprecated InitialRepositories
+// property included. A repository of InitialRepositories is ignored when Repositories defines the same URL. When
+// InitialRepositories cannot be parsed, it is left to Argo CD: the repositories of Repositories are returned along
+// with the parse error.
+func getRepositories(cr *argoproj.ArgoCD) ([]argoproj.ArgoCDRepository, error) {
+	repos := append([]argoproj.ArgoCDRepository{}, cr.Spec.Repositories...)
+	legacy, err := parseLegacyRepositories(cr.Spec.InitialRepositories)
+	if err != nil {
+		return repos, err
+	}
+	for _, l := range legacy {
+		if hasRepositoryURL(cr.Spec.Repositories, l.URL) {
+			continue
+		}
+		repos = append(repos, argoproj.ArgoCDRepository{
+			URL:                         l.URL,
+			Type:                        argoproj.ArgoCDRepositoryType(l.Type),
+			Name:                        l.Name,
+			Insecure:                    l.Insecure,
+			EnableLFS:                   l.EnableLFS,
+			EnableOCI:                   l.EnableOCI,
+			Proxy:                       l.Proxy,
+			ArgoCDRepositoryCredentials: l.credentials(),
+		})
+	}
+	return repos, nil
+}
+
+// getRepositoryCredentialTemplates will return the repository credential templates of the given ArgoCD, the ones of
+// the deprecated RepositoryCredentials property included. A template of RepositoryCredentials is ignored when
+// RepositoryCredentialTemplates defines the same URL. When RepositoryCredentials cannot be parsed, it is left to Argo
+// CD: the templates of RepositoryCredentialTemplates are returned along with the parse error.
+func getRepositoryCredentialTemplates(cr *argoproj.ArgoCD) ([]argoproj.ArgoCDRepositoryCredentialTemplate, error) {
+	templates := append([]argoproj.ArgoCDRepositoryCredentialTemplate{}, cr.Spec.RepositoryCredentialTemplates...)
+	legacy, err := parseLegacyRepositories(cr.Spec.RepositoryCredentials)
+	if err != nil {
+		return templates, err
+	}
+	for _, l := range legacy {
+		if hasRepositoryCredentialTemplateURL(cr.Spec.RepositoryCredentialTemplates, l.URL) {
+			continue
+		}
+		templates = append(templates, argoproj.ArgoCDRepositoryCredentialTemplate{
+			URL:                         l.URL,
+			Type:                        argoproj.ArgoCDRepositoryType(l.Type),
+			EnableOCI:                   l.EnableOCI,
+			Proxy:                       l.Proxy,
+			ArgoCDRepositoryCredentials: l.credentials(),
+		})
+	}
+	return templates, nil
+}
+
+func hasRepositoryURL(repos []argoproj.ArgoCDRepository, url string) bool {
+	for _, repo := range repos {
+		if repo.URL == url {
+			return true
+		}
+	}
+	return false
+}
+
+func hasRepositoryCredentialTemplateURL(templates []argoproj.ArgoCDRepositoryCredentialTemplate, url string) bool {
+	for _, template := range templates {
+		if template.URL == url {
+			return true
+		}
+	}
+	return false
+}
+
+// getRepositorySecretName will return the name of the Secret of the given ArgoCD holding the repository, or the
+// repository credential template, of the given secret type and URL.
+func getRepositorySecretName(cr *argoproj.ArgoCD, secretType, url string) string {
+	prefix := "repo"
+	if secretType == repositoryCredentialsSecretType {
+		prefix = "repo-creds"
+	}
+	sum := sha256.Sum256([]byte(url))
+	return fmt.Sprintf("%s-%s-%x", cr.Name, prefix, sum[:5])
+}
+
+// getRepositorySecretValue will return the value of the Secret key of the given reference, in the namespace of the
+// given ArgoCD.
+func (r *ReconcileArgoCD) getRepositorySecretValue(cr *argoproj.ArgoCD, ref *corev1.SecretKeySelector) ([]byte, error) {
+	secret := &corev1.Secret{}
+	if err := r.Client.Get(context.TODO(), types.NamespacedName{Namespace: cr.Namespace, Name: ref.Name}, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: secret %s not found", errInvalidRepository, ref.Name)
+		}
+		return nil, err
+	}
+	value, ok := secret.Data[ref.Key]
+	if !ok {
+		return nil, fmt.Errorf("%w: key %s not found in secret %s", errInvalidRepository, ref.Key, ref.Name)
+	}
+	return value, nil
+}
+
+// getRepositoryCredentialsData will return the Secret data of the given repository credentials, read from the Secrets
+// they reference.
+func (r *ReconcileArgoCD) getRepositoryCredentialsData(cr *argoproj.ArgoCD, creds argoproj.ArgoCDRepositoryCredentials) (map[string][]byte, error) {
+	if creds.Username != "" && creds.UsernameSecret != nil {
+		return nil, fmt.Errorf("%w: the username and the username secret are mutually exclusive", errInvalidRepository)
+	}
+	if (creds.TLSClientCertSecret == nil) != (creds.TLSClientKeySecret == nil) {
+		return nil, fmt.Errorf("%w: the TLS client certificate and its key must be set together", errInvalidRepository)
+	}
+
+	data := map[string][]byte{}
+	if creds.Username != "" {
+		data["username"] = []byte(creds.Username)
+	}
+	refs := map[string]*corev1.SecretKeySelector{
+		"username":          creds.UsernameSecret,
+		"password":          creds.PasswordSecret,
+		"sshPrivateKey":     creds.SSHPrivateKeySecret,
+		"tlsClientCertData": creds.TLSClientCertSecret,
+		"tlsClientCertKey":  creds.TLSClientKeySecret,
+	}
+	if app := creds.GitHubApp; app != nil {
+		data["githubAppID"] = []byte(strconv.FormatInt(app.ID, 10))
+		data["githubAppInstallationID"] = []byte(strconv.FormatInt(app.InstallationID, 10))
+		if app.EnterpriseBaseURL != "" {
+			data["githubAppEnterpriseBaseUrl"] = []byte(app.EnterpriseBaseURL)
+		}
+		refs["githubAppPrivateKey"] = &app.PrivateKeySecret
+	}
+	for key, ref := range refs {
+		if ref == nil {
+			continue
+		}
+		value, err := r.getRepositorySecretValue(cr, ref)
+		if err != nil {
+			return nil, err
+		}
+		data[key] = value
+	}
+	return data, nil
+}
+
+// newRepositorySecret will return the Secret of the given ArgoCD holding the repository, or the repository credential
+// template, of the given secret type, URL and data.
+func newRepositorySecret(cr *argoproj.ArgoCD, secretType, url string, data map[string][]byte) *corev1.Secret {
+	secret := argoutil.NewSecretWithName(cr, getRepositorySecretName(cr, secretType, url))
+	secret.Labels[common.ArgoCDSecretTypeLabel] = secretType
+	data["url"] = []byte(url)
+	secret.Data = data
+	return secret
+}
+
+// newRepositorySecretForRepository will return the repository Secret of the given repository.
+func (r *ReconcileArgoCD) newRepositorySecretForRepository(cr *argoproj.ArgoCD, repo argoproj.ArgoCDRepository) (*corev1.Secret, error) {
+	data, err := r.getRepositoryCredentialsData(cr, repo.ArgoCDRepositoryCredentials)
+	if err != nil {
+		return nil, err
+	}
+	if repo.Type != "" {
+		data["type"] = []byte(repo.Type)
+	}
+	if repo.Name != "" {
+		data["name"] = []byte(repo.Name)
+	}
+	if repo.Project != "" {
+		data["project"] = []byte(repo.Project)
+	}
+	if repo.Insecure {
+		data["insecure"] = []byte("true")
+	}
+	if repo.EnableLFS {
+		data["enableLfs"] = []byte("true")
+	}
+	if repo.EnableOCI {
+		data["enableOCI"] = []byte("true")
+	}
+	if repo.Proxy != "" {
+		data["proxy"] = []byte(repo.Proxy)
+	}
+	return newRepositorySecret(cr, repositorySecretType, repo.URL, data), nil
+}
+
+// newRepositorySecretForCredentialTemplate will return the repository credential Secret of the given template.
+func (r *ReconcileArgoCD) newRepositorySecretForCredentialTemplate(cr *argoproj.ArgoCD, template argoproj.ArgoCDRepositoryCredentialTemplate) (*corev1.Secret, error) {
+	data, err := r.getRepositoryCredentialsData(cr, template.ArgoCDRepositoryCredentials)
+	if err != nil {
+		return nil, err
+	}
+	if template.Type != "" {
+		data["type"] = []byte(template.Type)
+	}
+	if template.EnableOCI {
+		data["enableOCI"] = []byte("true")
+	}
+	if template.Proxy != "" {
+		data["proxy"] = []byte(template.Proxy)
+	}
+	return newRepositorySecret(cr, repositoryCredentialsSecretType, template.URL, data), nil
+}
+
+// repositorySecret is the Secret of a repository, or of a repository credential template, of an ArgoCD, along with
+// the error preventing to build it, if any.
+type repositorySecret struct {
+	secretType string
+	url        string
+	secret     *corev1.Secret
+	err        error
+}
+
+// getRepositorySecrets will return the repository and repository credential Secrets of the given ArgoCD. A
+// repository, or a repository credential template, defined twice is returned once.
+func (r *ReconcileArgoCD) getRepositorySecrets(cr *argoproj.ArgoCD) []repositorySecret {
+	seen := map[string]bool{}
+	secrets := []repositorySecret{}
+	repos, _ := getRepositories(cr)
+	for _, repo := range repos {
+		if seen[repositorySecretType+repo.URL] {
+			continue
+		}
+		seen[repositorySecretType+repo.URL] = true
+		secret, err := r.newRepositorySecretForRepository(cr, repo)
+		secrets = append(secrets, repositorySecret{secretType: repositorySecretType, url: repo.URL, secret: secret, err: err})
+	}
+
+	templates, _ := getRepositoryCredentialTemplates(cr)
+	for _, template := range templates {
+		if seen[repositoryCredentialsSecretType+template.URL] {
+			continue
+		}
+		seen[repositoryCredentialsSecretType+template.URL] = true
+		secret, err := r.newRepositorySecretForCredentialTemplate(cr, template)
+		secrets = append(secrets, repositorySecret{secretType: repositoryCredentialsSecretType, url: template.URL, secret: secret, err: err})
+	}
+	return secrets
+}
+
+// getUnmigratedRepositories will return the YAML list of the entries of the given YAML list of the deprecated
+// InitialRepositories or RepositoryCredentials properties that no Secret of the given secret type holds yet, such as
+// the invalid ones: Argo CD keeps reading them from argocd-cm. A list that cannot be parsed is returned as is.
+func (r *ReconcileArgoCD) getUnmigratedRepositories(cr *argoproj.ArgoCD, data, secretType string) string {
+	legacy, err := parseLegacyRepositories(data)
+	if err != nil {
+		return data
+	}
+	// the entries are kept as written, with the fields the operator does not know of
+	raw := []map[string]interface{}{}
+	if err := k8syaml.Unmarshal([]byte(data), &raw); err != nil || len(raw) != len(legacy) {
+		return data
+	}
+
+	migrated := map[string]bool{}
+	for _, s := range r.getRepositorySecrets(cr) {
+		if s.secretType == secretType && s.err == nil {
+			migrated[s.url] = argoutil.IsObjectFound(r.Client, cr.Namespace, s.secret.Name, &corev1.Secret{})
+		}
+	}
+	unmigrated := []map[string]interface{}{}
+	for i, l := range legacy {
+		if !migrated[l.URL] {
+			unmigrated = append(unmigrated, raw[i])
+		}
+	}
+	if len(unmigrated) == 0 {
+		return ""
+	} else if len(unmigrated) == len(legacy) {
+		return data
+	}
+	out, err := k8syaml.Marshal(unmigrated)
+	if err != nil {
+		return data
+	}
+	return string(out)
+}
+
+// reconcileRepositorySecrets will ensure that the repository and repository credential Secrets of the given ArgoCD
+// are present and up to date. The Secrets of the repositories that are no longer defined are pruned. A repository
+// that is invalid, such as one referencing a missing Secret, keeps its current Secret, if any, and is reported in the
+// status of the ArgoCD, as are the deprecated lists of repositories that cannot be parsed.
+func (r *ReconcileArgoCD) reconcileRepositorySecrets(cr *argoproj.ArgoCD) error {
+	invalid := []string{}
+	if _, err := getRepositories(cr); err != nil {
+		log.Error(err, fmt.Sprintf("failed to parse the initial repositories of ArgoCD %s/%s, leaving them to Argo CD", cr.Namespace, cr.Name))
+		invalid = append(invalid, fmt.Sprintf("initialRepositories: %v", err))
+	}
+	if _, err := getRepositoryCredentialTemplates(cr); err != nil {
+		log.Error(err, fmt.Sprintf("failed to parse the repository credentials of ArgoCD %s/%s, leaving them to Argo CD", cr.Namespace, cr.Name))
+		invalid = append(invalid, fmt.Sprintf("repositoryCredentials: %v", err))
+	}
+
+	for _, s := range r.getRepositorySecrets(cr) {
+		if errors.Is(s.err, errInvalidRepository) {
+			invalid = append(invalid, fmt.Sprintf("%s: %v", s.url, s.err))
+		}
+		if err := r.reconcileRepositorySecret(cr, s.secretType, s.url, s.secret, s.err); err != nil {
+			return err
+		}
+	}
+	return r.reconcileStatusInvalidRepositories(cr, invalid)
+}
+
+// reconcileRepositorySecret will create or update the given repository Secret. When the Secret could not be built,
+// the existing Secret is kept as is: it is read anyway, so that it is not pruned.
+func (r *ReconcileArgoCD) reconcileRepositorySecret(cr *argoproj.ArgoCD, secretType, url string, secret *corev1.Secret, buildErr error) error {
+	existing := &corev1.Secret{}
+	found := argoutil.IsObjectFound(r.Client, cr.Namespace, getRepositorySecretName(cr, secretType, url), existing)
+
+	if errors.Is(buildErr, errInvalidRepository) {
+		log.Error(buildErr, fmt.Sprintf("skipping the %s secret of %s for ArgoCD %s/%s", secretType, url, cr.Namespace, cr.Name))
+		return nil
+	} else if buildErr != nil {
+		return buildErr
+	}
+
+	if !found {
+		if err := controllerutil.SetControllerReference(cr, secret, r.Scheme); err != nil {
+			return err
+		}
+		log.Info(fmt.Sprintf("creating %s secret %s for ArgoCD %s/%s", secretType, secret.Name, cr.Namespace, cr.Name))
+		return r.Client.Create(context.TODO(), secret)
+	}
+
+	if reflect.DeepEqual(existing.Data, secret.Data) && reflect.DeepEqual(existing.Labels, secret.Labels) {
+		return nil
+	}
+	existing.Data = secret.Data
+	existing.Labels = secret.Labels
+	log.Info(fmt.Sprintf("updating %s secret %s for ArgoCD %s/%s", secretType, secret.Name, cr.Namespace, cr.Name))
+	return r.Client.Update(context.TODO(), existing)
+}
+
+// referencesRepositorySecret returns true when a repository, or a repository credential template, of the given ArgoCD
+// reads its credentials from the named Secret.
+func referencesRepositorySecret(cr *argoproj.ArgoCD, name string) bool {
+	creds := []argoproj.ArgoCDRepositoryCredentials{}
+	repos, _ := getRepositories(cr)
+	for _, repo := range repos {
+		creds = append(creds, repo.ArgoCDRepositoryCredentials)
+	}
+	templates, _ := getRepositoryCredentialTemplates(cr)
+	for _, template := range templates {
+		creds = append(creds, template.ArgoCDRepositoryCredentials)
+	}
+
+	for _, c := range creds {
+		refs := []*corev1.SecretKeySelector{c.UsernameSecret, c.PasswordSecret, c.SSHPrivateKeySecret, c.TLSClientCertSecret, c.TLSClientKeySecret}
+		if c.GitHubApp != nil {
+			refs = append(refs, &c.GitHubApp.PrivateKeySecret)
+		}
+		for _, ref := range refs {
+			if ref != nil && ref.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// repositorySecretMapper maps a watch event on a Secret back to the ArgoCD objects of its namespace whose repositories
+// read their credentials from it.
+func (r *ReconcileArgoCD) repositorySecretMapper(ctx context.Context, o client.Object) []reconcile.Request {
+	var result = []reconcile.Request{}
+
+	argocds := &argoproj.ArgoCDList{}
+	if err := r.Client.List(ctx, argocds, &client.ListOptions{Namespace: o.GetNamespace()}); err != nil {
+		log.Error(err, fmt.Sprintf("failed to list ArgoCD instances in namespace %s", o.GetNamespace()))
+		return result
+	}
+	for _, argocd := range argocds.Items {
+		if referencesRepositorySecret(&argocd, o.GetName()) {
+			result = append(result, reconcile.Request{
+				NamespacedName: client.ObjectKey{Name: argocd.Name, Namespace: argocd.Namespace},
+			})
+		}
+	}
+	return result
+}
@@ -0,0 +1,344 @@
+package argocd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
+)
+
+func makeTestRepositoryCredentialsSecret() *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "git-creds", Namespace: testNamespace},
+		Data: map[string][]byte{
+			"username": []byte("git"),
+			"password": []byte("s3cr3t"),
+			"sshKey":   []byte("ssh-key"),
+			"appKey":   []byte("app-key"),
+			"tls.crt":  []byte("cert"),
+			"tls.key":  []byte("key"),
+		},
+	}
+}
+
+func secretKeyRef(name, key string) *corev1.SecretKeySelector {
+	return &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: name}, Key: key}
+}
+
+func getTestRepositorySecret(t *testing.T, cl client.Client, a *argoproj.ArgoCD, secretType, url string) *corev1.Secret {
+	t.Helper()
+	secret := &corev1.Secret{}
+	err := cl.Get(context.TODO(), types.NamespacedName{Namespace: a.Namespace, Name: getRepositorySecretName(a, secretType, url)}, secret)
+	if err != nil {
+		return nil
+	}
+	return secret
+}
+
+func TestReconcileArgoCD_reconcileRepositorySecrets(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.Repositories = []argoproj.ArgoCDRepository{
+			{
+				URL:       "https://github.com/example/private.git",
+				Project:   "team-a",
+				EnableLFS: true,
+				ArgoCDRepositoryCredentials: argoproj.ArgoCDRepositoryCredentials{
+					UsernameSecret: secretKeyRef("git-creds", "username"),
+					PasswordSecret: secretKeyRef("git-creds", "password"),
+				},
+			},
+			{
+				URL:  "https://charts.example.com",
+				Type: argoproj.ArgoCDRepositoryTypeHelm,
+				Name: "charts",
+				ArgoCDRepositoryCredentials: argoproj.ArgoCDRepositoryCredentials{
+					Username:            "charts",
+					PasswordSecret:      secretKeyRef("git-creds", "password"),
+					TLSClientCertSecret: secretKeyRef("git-creds", "tls.crt"),
+					TLSClientKeySecret:  secretKeyRef("git-creds", "tls.key"),
+				},
+			},
+		}
+		a.Spec.RepositoryCredentialTemplates = []argoproj.ArgoCDRepositoryCredentialTemplate{
+			{
+				URL: "https://github.com/example",
+				ArgoCDRepositoryCredentials: argoproj.ArgoCDRepositoryCredentials{
+					GitHubApp: &argoproj.ArgoCDRepositoryGitHubApp{
+						ID:               123,
+						InstallationID:   456,
+						PrivateKeySecret: *secretKeyRef("git-creds", "appKey"),
+					},
+				},
+			},
+		}
+	})
+
+	resObjs := []client.Object{a, makeTestRepositoryCredentialsSecret()}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileRepositorySecrets(a))
+
+	secret := getTestRepositorySecret(t, cl, a, repositorySecretType, "https://github.com/example/private.git")
+	assert.NotNil(t, secret)
+	assert.Equal(t, "repository", secret.Labels[common.ArgoCDSecretTypeLabel])
+	assert.Equal(t, a.Name, secret.Labels[common.ArgoCDKeyManagedBy])
+	assert.True(t, metav1.IsControlledBy(secret, a))
+	assert.Equal(t, map[string][]byte{
+		"url":       []byte("https://github.com/example/private.git"),
+		"project":   []byte("team-a"),
+		"enableLfs": []byte("true"),
+		"username":  []byte("git"),
+		"password":  []byte("s3cr3t"),
+	}, secret.Data)
+
+	secret = getTestRepositorySecret(t, cl, a, repositorySecretType, "https://charts.example.com")
+	assert.NotNil(t, secret)
+	assert.Equal(t, map[string][]byte{
+		"url":               []byte("https://charts.example.com"),
+		"type":              []byte("helm"),
+		"name":              []byte("charts"),
+		"username":          []byte("charts"),
+		"password":          []byte("s3cr3t"),
+		"tlsClientCertData": []byte("cert"),
+		"tlsClientCertKey":  []byte("key"),
+	}, secret.Data)
+
+	secret = getTestRepositorySecret(t, cl, a, repositoryCredentialsSecretType, "https://github.com/example")
+	assert.NotNil(t, secret)
+	assert.Equal(t, "repo-creds", secret.Labels[common.ArgoCDSecretTypeLabel])
+	assert.Equal(t, map[string][]byte{
+		"url":                     []byte("https://github.com/example"),
+		"githubAppID":             []byte("123"),
+		"githubAppInstallationID": []byte("456"),
+		"githubAppPrivateKey":     []byte("app-key"),
+	}, secret.Data)
+
+	// the repository Secrets follow the Secrets they reference
+	creds := makeTestRepositoryCredentialsSecret()
+	creds.Data["password"] = []byte("rotated")
+	assert.NoError(t, cl.Update(context.TODO(), creds))
+	assert.NoError(t, r.reconcileRepositorySecrets(a))
+
+	secret = getTestRepositorySecret(t, cl, a, repositorySecretType, "https://github.com/example/private.git")
+	assert.Equal(t, "rotated", string(secret.Data["password"]))
+}
+
+func TestReconcileArgoCD_reconcileRepositorySecrets_legacy(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.InitialRepositories = `
+- url: https://github.com/example/private.git
+  usernameSecret:
+    name: git-creds
+    key: username
+  passwordSecret:
+    name: git-creds
+    key: password
+- type: helm
+  url: https://charts.example.com
+  name: charts
+  enableOci: true`
+		a.Spec.RepositoryCredentials = `
+- url: git@github.com:example
+  sshPrivateKeySecret:
+    name: git-creds
+    key: sshKey`
+		// the typed repositories take precedence over the legacy ones
+		a.Spec.Repositories = []argoproj.ArgoCDRepository{
+			{URL: "https://charts.example.com", Type: argoproj.ArgoCDRepositoryTypeHelm, Name: "typed-charts"},
+		}
+	})
+
+	resObjs := []client.Object{a, makeTestRepositoryCredentialsSecret()}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileRepositorySecrets(a))
+
+	secret := getTestRepositorySecret(t, cl, a, repositorySecretType, "https://github.com/example/private.git")
+	assert.NotNil(t, secret)
+	assert.Equal(t, "git", string(secret.Data["username"]))
+	assert.Equal(t, "s3cr3t", string(secret.Data["password"]))
+
+	secret = getTestRepositorySecret(t, cl, a, repositorySecretType, "https://charts.example.com")
+	assert.NotNil(t, secret)
+	assert.Equal(t, "typed-charts", string(secret.Data["name"]))
+	assert.NotContains(t, secret.Data, "enableOCI")
+
+	secret = getTestRepositorySecret(t, cl, a, repositoryCredentialsSecretType, "git@github.com:example")
+	assert.NotNil(t, secret)
+	assert.Equal(t, "ssh-key", string(secret.Data["sshPrivateKey"]))
+
+	// the migrated repositories are no longer written to argocd-cm
+	assert.Empty(t, r.getInitialRepositories(a))
+	assert.Empty(t, r.getRepositoryCredentials(a))
+}
+
+func TestReconcileArgoCD_reconcileRepositorySecrets_legacyInvalid(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.InitialRepositories = `
+- url: https://github.com/example/private.git
+  passwordSecret:
+    name: git-creds
+    key: password
+- url: https://github.com/example/other.git
+  passwordSecret:
+    name: missing-creds
+    key: password
+  unknownField: kept`
+		a.Spec.RepositoryCredentials = `
+- url: git@github.com:example
+  sshPrivateKeySecret:
+    name: missing-creds
+    key: sshKey`
+	})
+
+	resObjs := []client.Object{a, makeTestRepositoryCredentialsSecret()}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	// before the migration, every repository is left in argocd-cm
+	assert.Equal(t, a.Spec.InitialRepositories, r.getInitialRepositories(a))
+
+	assert.NoError(t, r.reconcileRepositorySecrets(a))
+	assert.NotNil(t, getTestRepositorySecret(t, cl, a, repositorySecretType, "https://github.com/example/private.git"))
+	assert.Nil(t, getTestRepositorySecret(t, cl, a, repositorySecretType, "https://github.com/example/other.git"))
+
+	// the invalid repositories are kept in argocd-cm, as written
+	assert.Equal(t, `- passwordSecret:
+    key: password
+    name: missing-creds
+  unknownField: kept
+  url: https://github.com/example/other.git
+`, r.getInitialRepositories(a))
+	assert.Equal(t, a.Spec.RepositoryCredentials, r.getRepositoryCredentials(a))
+
+	// and reported in the status
+	condition := meta.FindStatusCondition(a.Status.Conditions, argoproj.ArgoCDConditionInvalidRepositories)
+	if assert.NotNil(t, condition) {
+		assert.Equal(t, metav1.ConditionTrue, condition.Status)
+		assert.Contains(t, condition.Message, "https://github.com/example/other.git: invalid repository: secret missing-creds not found")
+		assert.Contains(t, condition.Message, "git@github.com:example: invalid repository: secret missing-creds not found")
+	}
+
+	// until the Secrets they reference are created
+	creds := makeTestRepositoryCredentialsSecret()
+	creds.Name = "missing-creds"
+	assert.NoError(t, cl.Create(context.TODO(), creds))
+	assert.NoError(t, r.reconcileRepositorySecrets(a))
+
+	assert.Empty(t, r.getInitialRepositories(a))
+	assert.Empty(t, r.getRepositoryCredentials(a))
+	assert.True(t, meta.IsStatusConditionFalse(a.Status.Conditions, argoproj.ArgoCDConditionInvalidRepositories))
+}
+
+func TestReconcileArgoCD_reconcileRepositorySecrets_invalid(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	url := "https://github.com/example/private.git"
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.Repositories = []argoproj.ArgoCDRepository{
+			{
+				URL: url,
+				ArgoCDRepositoryCredentials: argoproj.ArgoCDRepositoryCredentials{
+					PasswordSecret: secretKeyRef("git-creds", "password"),
+				},
+			},
+		}
+	})
+
+	resObjs := []client.Object{a}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	// a repository referencing a missing Secret is skipped
+	assert.NoError(t, r.reconcileRepositorySecrets(a))
+	assert.Nil(t, getTestRepositorySecret(t, cl, a, repositorySecretType, url))
+
+	assert.NoError(t, cl.Create(context.TODO(), makeTestRepositoryCredentialsSecret()))
+	assert.NoError(t, r.reconcileRepositorySecrets(a))
+	assert.NotNil(t, getTestRepositorySecret(t, cl, a, repositorySecretType, url))
+
+	// an invalid repository keeps its current Secret
+	a.Spec.Repositories[0].PasswordSecret = secretKeyRef("git-creds", "missing")
+	assert.NoError(t, r.reconcileRepositorySecrets(a))
+	secret := getTestRepositorySecret(t, cl, a, repositorySecretType, url)
+	assert.NotNil(t, secret)
+	assert.Equal(t, "s3cr3t", string(secret.Data["password"]))
+
+	a.Spec.Repositories[0].PasswordSecret = secretKeyRef("git-creds", "password")
+	a.Spec.Repositories[0].Username = "git"
+	a.Spec.Repositories[0].UsernameSecret = secretKeyRef("git-creds", "username")
+	_, err := r.getRepositoryCredentialsData(a, a.Spec.Repositories[0].ArgoCDRepositoryCredentials)
+	assert.ErrorIs(t, err, errInvalidRepository)
+}
+
+func Test_parseLegacyRepositories(t *testing.T) {
+	repos, err := parseLegacyRepositories("")
+	assert.NoError(t, err)
+	assert.Empty(t, repos)
+
+	_, err = parseLegacyRepositories("url: https://github.com/example/private.git")
+	assert.Error(t, err)
+
+	_, err = parseLegacyRepositories("- name: no-url")
+	assert.EqualError(t, err, "repository without url")
+
+	// an unparsable list is left to Argo CD
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.InitialRepositories = "- name: no-url"
+	})
+	r := makeTestReconciler(makeTestReconcilerClient(makeTestReconcilerScheme(argoproj.AddToScheme), nil, nil, nil), makeTestReconcilerScheme(argoproj.AddToScheme))
+	assert.Equal(t, a.Spec.InitialRepositories, r.getInitialRepositories(a))
+}
+
+func TestReconcileArgoCD_repositorySecretMapper(t *testing.T) {
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.RepositoryCredentials = `
+- url: https://github.com/example
+  passwordSecret:
+    name: git-creds
+    key: password`
+	})
+	b := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Name = "other"
+	})
+
+	resObjs := []client.Object{a, b}
+	subresObjs := []client.Object{a, b}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	requests := r.repositorySecretMapper(context.TODO(), makeTestRepositoryCredentialsSecret())
+	assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: a.Namespace, Name: a.Name}}}, requests)
+
+	requests = r.repositorySecretMapper(context.TODO(), &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: testNamespace}})
+	assert.Empty(t, requests)
+}
@@ -0,0 +1,158 @@
+// Copyright 2019 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argocd
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gobwas/glob"
+	k8syaml "sigs.k8s.io/yaml"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
+)
+
+// recommendedResourceExclusions are the resource exclusions of the Recommended preset: the high-volume resources
+// which are not managed through Git, as excluded by the default installation of Argo CD.
+var recommendedResourceExclusions = []argoproj.ArgoCDResourceFilterRule{
+	{APIGroups: []string{"", "events.k8s.io"}, Kinds: []string{"Event"}},
+	{APIGroups: []string{"", "discovery.k8s.io"}, Kinds: []string{"Endpoints", "EndpointSlice"}},
+	{APIGroups: []string{"coordination.k8s.io"}, Kinds: []string{"Lease"}},
+	{APIGroups: []string{"metrics.k8s.io"}, Kinds: []string{"*"}},
+	{APIGroups: []string{"authentication.k8s.io", "authorization.k8s.io"}, Kinds: []string{"SelfSubjectReview", "TokenReview", "LocalSubjectAccessReview", "SelfSubjectAccessReview", "SelfSubjectRulesReview", "SubjectAccessReview"}},
+	{APIGroups: []string{"certificates.k8s.io"}, Kinds: []string{"CertificateSigningRequest"}},
+	{APIGroups: []string{"cert-manager.io"}, Kinds: []string{"CertificateRequest"}},
+	{APIGroups: []string{"cilium.io"}, Kinds: []string{"CiliumIdentity", "CiliumEndpoint", "CiliumEndpointSlice"}},
+	{APIGroups: []string{"kyverno.io", "reports.kyverno.io", "wgpolicyk8s.io"}, Kinds: []string{"PolicyReport", "ClusterPolicyReport", "EphemeralReport", "ClusterEphemeralReport", "AdmissionReport", "ClusterAdmissionReport", "BackgroundScanReport", "ClusterBackgroundScanReport", "UpdateRequest"}},
+}
+
+// parseLegacyResourceFilterRules parses the given YAML list of the deprecated ResourceExclusions or
+// ResourceInclusions properties.
+func parseLegacyResourceFilterRules(data string) ([]argoproj.ArgoCDResourceFilterRule, error) {
+	rules := []argoproj.ArgoCDResourceFilterRule{}
+	if strings.TrimSpace(data) == "" {
+		return rules, nil
+	}
+	if err := k8syaml.UnmarshalStrict([]byte(data), &rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// validateResourceFilterRule returns an error when the given rule matches every resource of every cluster, which is
+// never intended, or one of its globs is invalid. A rule with only clusters matches every resource of these clusters.
+func validateResourceFilterRule(rule argoproj.ArgoCDResourceFilterRule) error {
+	if len(rule.APIGroups) == 0 && len(rule.Kinds) == 0 && len(rule.Clusters) == 0 {
+		return fmt.Errorf("the rule has neither apiGroups, kinds nor clusters and matches every resource")
+	}
+	for _, values := range [][]string{rule.APIGroups, rule.Kinds, rule.Clusters} {
+		for _, value := range values {
+			if _, err := glob.Compile(value); err != nil {
+				return fmt.Errorf("invalid glob %q: %w", value, err)
+			}
+		}
+	}
+	for _, kind := range rule.Kinds {
+		if kind == "" {
+			return fmt.Errorf("the rule has an empty kind")
+		}
+	}
+	return nil
+}
+
+// getResourceFilterRules returns the argocd-cm value of the given legacy YAML list merged with the given rules. The
+// legacy list is returned as it is when there is no rule to merge. When the legacy list cannot be parsed, it is
+// ignored in favor of the rules, or left to Argo CD when there is no rule. The invalid rules are skipped; they are
+// returned along with the parse error.
+func getResourceFilterRules(legacy string, rules []argoproj.ArgoCDResourceFilterRule) (string, []error) {
+	var errs []error
+	parsed, err := parseLegacyResourceFilterRules(legacy)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to parse %q: %w", legacy, err))
+		if len(rules) == 0 {
+			return legacy, errs
+		}
+	}
+	if len(rules) == 0 {
+		for _, rule := range parsed {
+			if err := validateResourceFilterRule(rule); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return legacy, errs
+	}
+
+	merged := []argoproj.ArgoCDResourceFilterRule{}
+	for _, rule := range append(parsed, rules...) {
+		if err := validateResourceFilterRule(rule); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if hasResourceFilterRule(merged, rule) {
+			continue
+		}
+		merged = append(merged, rule)
+	}
+	if len(merged) == 0 {
+		return "", errs
+	}
+	data, err := k8syaml.Marshal(merged)
+	if err != nil {
+		return legacy, append(errs, err)
+	}
+	return string(data), errs
+}
+
+// hasResourceFilterRule returns true when the given rules contain the given rule.
+func hasResourceFilterRule(rules []argoproj.ArgoCDResourceFilterRule, rule argoproj.ArgoCDResourceFilterRule) bool {
+	for _, r := range rules {
+		if reflect.DeepEqual(r, rule) {
+			return true
+		}
+	}
+	return false
+}
+
+// getResourceExclusions will return the resource exclusions for the given ArgoCD: the rules of the deprecated
+// ResourceExclusions property, of its preset and of ResourceExclusionRules.
+func getResourceExclusions(cr *argoproj.ArgoCD) (string, []error) {
+	legacy := common.ArgoCDDefaultResourceExclusions
+	if cr.Spec.ResourceExclusions != "" {
+		legacy = cr.Spec.ResourceExclusions
+	}
+	rules := []argoproj.ArgoCDResourceFilterRule{}
+	if cr.Spec.ResourceExclusionPreset == argoproj.ResourceExclusionPresetRecommended {
+		rules = append(rules, recommendedResourceExclusions...)
+	}
+	rules = append(rules, cr.Spec.ResourceExclusionRules...)
+	return getResourceFilterRules(legacy, rules)
+}
+
+// getResourceInclusions will return the resource inclusions for the given ArgoCD: the rules of the deprecated
+// ResourceInclusions property and of ResourceInclusionRules. When no rule is left because they are all invalid, the
+// given current inclusions are kept, since an empty list would include every resource.
+func getResourceInclusions(cr *argoproj.ArgoCD, current string) (string, []error) {
+	legacy := common.ArgoCDDefaultResourceInclusions
+	if cr.Spec.ResourceInclusions != "" {
+		legacy = cr.Spec.ResourceInclusions
+	}
+	inclusions, errs := getResourceFilterRules(legacy, cr.Spec.ResourceInclusionRules)
+	if inclusions == "" && len(errs) > 0 {
+		return current, errs
+	}
+	return inclusions, errs
+}
@@ -0,0 +1,202 @@
+package argocd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	k8syaml "sigs.k8s.io/yaml"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
+)
+
+func TestGetResourceExclusions(t *testing.T) {
+	legacy := `- apiGroups:
+  - repositories.stash.appscode.com
+  kinds:
+  - Snapshot
+  clusters:
+  - "*.local"
+`
+	snapshots := argoproj.ArgoCDResourceFilterRule{
+		APIGroups: []string{"repositories.stash.appscode.com"},
+		Kinds:     []string{"Snapshot"},
+		Clusters:  []string{"*.local"},
+	}
+	backups := argoproj.ArgoCDResourceFilterRule{
+		APIGroups: []string{"velero.io"},
+		Kinds:     []string{"Backup"},
+	}
+
+	tests := []struct {
+		name    string
+		opts    []argoCDOpt
+		want    []argoproj.ArgoCDResourceFilterRule
+		wantRaw string
+		wantErr bool
+	}{
+		{
+			name: "none",
+		},
+		{
+			name: "legacy only",
+			opts: []argoCDOpt{func(a *argoproj.ArgoCD) {
+				a.Spec.ResourceExclusions = legacy
+			}},
+			wantRaw: legacy,
+		},
+		{
+			name: "legacy merged with the rules",
+			opts: []argoCDOpt{func(a *argoproj.ArgoCD) {
+				a.Spec.ResourceExclusions = legacy
+				a.Spec.ResourceExclusionRules = []argoproj.ArgoCDResourceFilterRule{snapshots, backups}
+			}},
+			want: []argoproj.ArgoCDResourceFilterRule{snapshots, backups},
+		},
+		{
+			name: "recommended preset",
+			opts: []argoCDOpt{func(a *argoproj.ArgoCD) {
+				a.Spec.ResourceExclusionPreset = argoproj.ResourceExclusionPresetRecommended
+				a.Spec.ResourceExclusionRules = []argoproj.ArgoCDResourceFilterRule{backups}
+			}},
+			want: append(append([]argoproj.ArgoCDResourceFilterRule{}, recommendedResourceExclusions...), backups),
+		},
+		{
+			name: "invalid rules are skipped",
+			opts: []argoCDOpt{func(a *argoproj.ArgoCD) {
+				a.Spec.ResourceExclusionRules = []argoproj.ArgoCDResourceFilterRule{
+					{},
+					{APIGroups: []string{"velero.io"}, Kinds: []string{"[Backup"}},
+					backups,
+				}
+			}},
+			want:    []argoproj.ArgoCDResourceFilterRule{backups},
+			wantErr: true,
+		},
+		{
+			name: "rule excluding a whole cluster",
+			opts: []argoCDOpt{func(a *argoproj.ArgoCD) {
+				a.Spec.ResourceExclusionRules = []argoproj.ArgoCDResourceFilterRule{{Clusters: []string{"https://192.168.0.20"}}}
+			}},
+			want: []argoproj.ArgoCDResourceFilterRule{{Clusters: []string{"https://192.168.0.20"}}},
+		},
+		{
+			name: "unparsable legacy is ignored in favor of the rules",
+			opts: []argoCDOpt{func(a *argoproj.ArgoCD) {
+				a.Spec.ResourceExclusions = "- apiGroups:\n  - velero.io\n  kind:\n  - Backup\n"
+				a.Spec.ResourceExclusionPreset = argoproj.ResourceExclusionPresetRecommended
+				a.Spec.ResourceExclusionRules = []argoproj.ArgoCDResourceFilterRule{backups}
+			}},
+			want:    append(append([]argoproj.ArgoCDResourceFilterRule{}, recommendedResourceExclusions...), backups),
+			wantErr: true,
+		},
+		{
+			name: "unparsable legacy without rules is left to Argo CD",
+			opts: []argoCDOpt{func(a *argoproj.ArgoCD) {
+				a.Spec.ResourceExclusions = "- apiGroups:\n  - velero.io\n  kind:\n  - Backup\n"
+			}},
+			wantRaw: "- apiGroups:\n  - velero.io\n  kind:\n  - Backup\n",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, errs := getResourceExclusions(makeTestArgoCD(test.opts...))
+			if test.wantErr {
+				assert.NotEmpty(t, errs)
+			} else {
+				assert.Empty(t, errs)
+			}
+
+			if test.want == nil {
+				assert.Equal(t, test.wantRaw, got)
+				return
+			}
+			rules := []argoproj.ArgoCDResourceFilterRule{}
+			assert.NoError(t, k8syaml.Unmarshal([]byte(got), &rules))
+			assert.Equal(t, test.want, rules)
+		})
+	}
+}
+
+func TestReconcileArgoCD_reconcileArgoConfigMap_withResourceInclusionRules(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.ResourceInclusionRules = []argoproj.ArgoCDResourceFilterRule{
+			{APIGroups: []string{"*"}, Kinds: []string{"Deployment"}, Clusters: []string{"https://192.168.0.20"}},
+		}
+	})
+
+	resObjs := []client.Object{a}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileArgoConfigMap(a))
+
+	cm := &corev1.ConfigMap{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{
+		Name:      common.ArgoCDConfigMapName,
+		Namespace: testNamespace,
+	}, cm))
+
+	want := `- apiGroups:
+  - '*'
+  clusters:
+  - https://192.168.0.20
+  kinds:
+  - Deployment
+`
+	assert.Equal(t, want, cm.Data[common.ArgoCDKeyResourceInclusions])
+	assert.Equal(t, "", cm.Data[common.ArgoCDKeyResourceExclusions])
+}
+
+func TestReconcileArgoCD_reconcileArgoConfigMap_withInvalidResourceInclusionRules(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	deployments := argoproj.ArgoCDResourceFilterRule{APIGroups: []string{"apps"}, Kinds: []string{"Deployment"}}
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.ResourceInclusionRules = []argoproj.ArgoCDResourceFilterRule{deployments}
+	})
+
+	resObjs := []client.Object{a}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileArgoConfigMap(a))
+	cm := &corev1.ConfigMap{}
+	key := types.NamespacedName{Name: common.ArgoCDConfigMapName, Namespace: testNamespace}
+	assert.NoError(t, r.Client.Get(context.TODO(), key, cm))
+	valid := cm.Data[common.ArgoCDKeyResourceInclusions]
+	assert.NotEmpty(t, valid)
+	assert.Nil(t, meta.FindStatusCondition(a.Status.Conditions, argoproj.ArgoCDConditionInvalidResourceFilters))
+
+	// every rule being invalid, the current inclusions are kept rather than including every resource
+	a.Spec.ResourceInclusionRules = []argoproj.ArgoCDResourceFilterRule{{}}
+	assert.NoError(t, r.reconcileArgoConfigMap(a))
+	assert.NoError(t, r.Client.Get(context.TODO(), key, cm))
+	assert.Equal(t, valid, cm.Data[common.ArgoCDKeyResourceInclusions])
+	condition := meta.FindStatusCondition(a.Status.Conditions, argoproj.ArgoCDConditionInvalidResourceFilters)
+	assert.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Contains(t, condition.Message, "resource inclusions: the rule has neither apiGroups, kinds nor clusters")
+
+	// the condition is cleared once the rules are fixed
+	a.Spec.ResourceInclusionRules = []argoproj.ArgoCDResourceFilterRule{deployments}
+	assert.NoError(t, r.reconcileArgoConfigMap(a))
+	condition = meta.FindStatusCondition(a.Status.Conditions, argoproj.ArgoCDConditionInvalidResourceFilters)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+}
@@ -3,8 +3,6 @@ package argocd
 import (
 	"context"
 	"fmt"
-	"os"
-	"reflect"
 
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/rbac/v1"
@@ -16,6 +14,7 @@ import (
 
 	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
 	"github.com/argoproj-labs/argocd-operator/common"
+	"github.com/argoproj-labs/argocd-operator/controllers/argocdoperatorconfig"
 	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
 )
 
@@ -64,7 +63,7 @@ func newClusterRole(name string, rules []v1.PolicyRule, cr *argoproj.ArgoCD) *v1
 
 // reconcileRoles will ensure that all ArgoCD Service Accounts are configured.
 func (r *ReconcileArgoCD) reconcileRoles(cr *argoproj.ArgoCD) error {
-	params := getPolicyRuleList(r.Client)
+	params := getPolicyRuleList(r.Client, r.ClusterAPIs)
 
 	for _, param := range params {
 		if _, err := r.reconcileRole(param.name, param.policyRule, cr); err != nil {
@@ -141,32 +140,15 @@ func (r *ReconcileArgoCD) reconcileRole(name string, policyRules []v1.PolicyRule
 			if customRole != "" {
 				continue // skip creating default role if custom cluster role is provided
 			}
-			roles = append(roles, role)
-
 			if name == common.ArgoCDDexServerComponent && !UseDex(cr) {
-
+				roles = append(roles, role)
 				continue // Dex installation not requested, do nothing
 			}
-
-			// Only set ownerReferences for roles in same namespace as ArgoCD CR
-			if cr.Namespace == role.Namespace {
-				if err = controllerutil.SetControllerReference(cr, role, r.Scheme); err != nil {
-					return nil, fmt.Errorf("failed to set ArgoCD CR \"%s\" as owner for role \"%s\": %s", cr.Name, role.Name, err)
-				}
-			}
-
 			log.Info(fmt.Sprintf("creating role %s for Argo CD instance %s in namespace %s", role.Name, cr.Name, cr.Namespace))
-			if err := r.Client.Create(context.TODO(), role); err != nil {
-				return nil, err
-			}
-			continue
-		}
-
-		// Delete the existing default role if custom role is specified
-		// or if there is an existing Role created for Dex but dex is disabled or not configured
-		if customRole != "" ||
+		} else if customRole != "" ||
 			(name == common.ArgoCDDexServerComponent && !UseDex(cr)) {
-
+			// Delete the existing default role if custom role is specified
+			// or if there is an existing Role created for Dex but dex is disabled or not configured
 			log.Info("deleting the existing Dex role because dex is not configured")
 			if err := r.Client.Delete(context.TODO(), &existingRole); err != nil {
 				return nil, err
@@ -174,14 +156,17 @@ func (r *ReconcileArgoCD) reconcileRole(name string, policyRules []v1.PolicyRule
 			continue
 		}
 
-		// if the Rules differ, update the Role
-		if !reflect.DeepEqual(existingRole.Rules, role.Rules) {
-			existingRole.Rules = role.Rules
-			if err := r.Client.Update(context.TODO(), &existingRole); err != nil {
-				return nil, err
+		// Only set ownerReferences for roles in same namespace as ArgoCD CR
+		if cr.Namespace == role.Namespace {
+			if err = controllerutil.SetControllerReference(cr, role, r.Scheme); err != nil {
+				return nil, fmt.Errorf("failed to set ArgoCD CR \"%s\" as owner for role \"%s\": %s", cr.Name, role.Name, err)
 			}
 		}
-		roles = append(roles, &existingRole)
+
+		if err := argoutil.ApplyResource(r.Client, role); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
 	}
 	return roles, nil
 }
@@ -232,19 +217,8 @@ func (r *ReconcileArgoCD) reconcileRoleForApplicationSourceNamespaces(name strin
 			role.Rules = append(role.Rules, policyRuleForServerApplicationSetSourceNamespaces()...)
 		}
 
-		created := false
-		existingRole := v1.Role{}
-		err := r.Client.Get(context.TODO(), types.NamespacedName{Name: role.Name, Namespace: namespace.Name}, &existingRole)
-		if err != nil {
-			if !errors.IsNotFound(err) {
-				return fmt.Errorf("failed to reconcile the role for the service account associated with %s : %s", name, err)
-			}
-
-			log.Info(fmt.Sprintf("creating role %s for Argo CD instance %s in namespace %s", role.Name, cr.Name, namespace))
-			if err := r.Client.Create(context.TODO(), role); err != nil {
-				return err
-			}
-			created = true
+		if err := argoutil.ApplyResource(r.Client, role); err != nil {
+			return fmt.Errorf("failed to reconcile the role for the service account associated with %s : %s", name, err)
 		}
 
 		// Get the latest value of namespace before updating it
@@ -260,14 +234,6 @@ func (r *ReconcileArgoCD) reconcileRoleForApplicationSourceNamespaces(name strin
 			log.Error(err, fmt.Sprintf("failed to add label from namespace [%s]", namespace.Name))
 		}
 
-		// if the Rules differ, update the Role
-		if !created && !reflect.DeepEqual(existingRole.Rules, role.Rules) {
-			existingRole.Rules = role.Rules
-			if err := r.Client.Update(context.TODO(), &existingRole); err != nil {
-				return err
-			}
-		}
-
 		if _, ok := r.ManagedSourceNamespaces[sourceNamespace]; !ok {
 			if r.ManagedSourceNamespaces == nil {
 				r.ManagedSourceNamespaces = make(map[string]string)
@@ -282,7 +248,7 @@ func (r *ReconcileArgoCD) reconcileRoleForApplicationSourceNamespaces(name strin
 func (r *ReconcileArgoCD) reconcileClusterRole(name string, policyRules []v1.PolicyRule, cr *argoproj.ArgoCD) (*v1.ClusterRole, error) {
 
 	allowed := false
-	if allowedNamespace(cr.Namespace, os.Getenv("ARGOCD_CLUSTER_CONFIG_NAMESPACES")) {
+	if allowedNamespace(cr.Namespace, argocdoperatorconfig.Getenv(common.ArgoCDClusterConfigNamespacesEnvName)) {
 		allowed = true
 	}
 
@@ -318,21 +284,11 @@ func (r *ReconcileArgoCD) reconcileClusterRole(name string, policyRules []v1.Pol
 			// Do Nothing
 			return nil, nil
 		}
-		return clusterRole, r.Client.Create(context.TODO(), clusterRole)
-	}
-
-	if !allowed {
+	} else if !allowed {
 		return nil, r.Client.Delete(context.TODO(), existingClusterRole)
 	}
 
-	// if the Rules differ, update the Role
-	if !reflect.DeepEqual(existingClusterRole.Rules, clusterRole.Rules) {
-		existingClusterRole.Rules = clusterRole.Rules
-		if err := r.Client.Update(context.TODO(), existingClusterRole); err != nil {
-			return nil, err
-		}
-	}
-	return existingClusterRole, nil
+	return clusterRole, argoutil.ApplyResource(r.Client, clusterRole)
 }
 
 func deleteClusterRoles(c client.Client, clusterRoleList *v1.ClusterRoleList) error {
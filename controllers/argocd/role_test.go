@@ -49,7 +49,7 @@ func TestReconcileArgoCD_reconcileRole(t *testing.T) {
 	assert.Equal(t, expectedRules, reconciledRole.Rules)
 
 	// update reconciledRole policy rules to RedisHa policy rules
-	reconciledRole.Rules = policyRuleForRedisHa(r.Client)
+	reconciledRole.Rules = policyRuleForRedisHa(r.Client, r.ClusterAPIs)
 	assert.NoError(t, r.Client.Update(context.TODO(), reconciledRole))
 
 	// Check if the RedisHa policy rules are overwritten to Application Controller
@@ -85,14 +85,14 @@ func TestReconcileArgoCD_reconcileRole_for_new_namespace(t *testing.T) {
 	assert.Equal(t, expectedRoleNamespace, dexRoles[0].ObjectMeta.Namespace)
 	// check no redisHa role is created for the new namespace with managed-by label
 	workloadIdentifier = common.ArgoCDRedisHAComponent
-	expectedRedisHaRules := policyRuleForRedisHa(r.Client)
+	expectedRedisHaRules := policyRuleForRedisHa(r.Client, r.ClusterAPIs)
 	redisHaRoles, err := r.reconcileRole(workloadIdentifier, expectedRedisHaRules, a)
 	assert.NoError(t, err)
 	assert.Equal(t, expectedNumberOfRoles, len(redisHaRoles))
 	assert.Equal(t, expectedRoleNamespace, redisHaRoles[0].ObjectMeta.Namespace)
 	// check no redis role is created for the new namespace with managed-by label
 	workloadIdentifier = common.ArgoCDRedisComponent
-	expectedRedisRules := policyRuleForRedis(r.Client)
+	expectedRedisRules := policyRuleForRedis(r.Client, r.ClusterAPIs)
 	redisRoles, err := r.reconcileRole(workloadIdentifier, expectedRedisRules, a)
 	assert.NoError(t, err)
 	assert.Equal(t, expectedNumberOfRoles, len(redisRoles))
@@ -131,7 +131,7 @@ func TestReconcileArgoCD_reconcileClusterRole(t *testing.T) {
 	assert.Equal(t, expectedRules, reconciledClusterRole.Rules)
 
 	// update reconciledRole policy rules to RedisHa policy rules
-	reconciledClusterRole.Rules = policyRuleForRedisHa(r.Client)
+	reconciledClusterRole.Rules = policyRuleForRedisHa(r.Client, r.ClusterAPIs)
 	assert.NoError(t, r.Client.Update(context.TODO(), reconciledClusterRole))
 
 	// Check if the RedisHa policy rules are overwritten to Application Controller
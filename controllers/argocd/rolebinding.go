@@ -3,7 +3,6 @@ package argocd
 import (
 	"context"
 	"fmt"
-	"os"
 	"reflect"
 
 	corev1 "k8s.io/api/core/v1"
@@ -16,6 +15,7 @@ import (
 
 	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
 	"github.com/argoproj-labs/argocd-operator/common"
+	"github.com/argoproj-labs/argocd-operator/controllers/argocdoperatorconfig"
 	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
 )
 
@@ -84,7 +84,7 @@ func newRoleBindingWithname(name string, cr *argoproj.ArgoCD) *v1.RoleBinding {
 
 // reconcileRoleBindings will ensure that all ArgoCD RoleBindings are configured.
 func (r *ReconcileArgoCD) reconcileRoleBindings(cr *argoproj.ArgoCD) error {
-	params := getPolicyRuleList(r.Client)
+	params := getPolicyRuleList(r.Client, r.ClusterAPIs)
 
 	for _, param := range params {
 		if err := r.reconcileRoleBinding(param.name, param.policyRule, cr); err != nil {
@@ -186,20 +186,11 @@ func (r *ReconcileArgoCD) reconcileRoleBinding(name string, rules []v1.PolicyRul
 				continue
 			}
 
-			// if the RoleRef changes, delete the existing role binding and create a new one
+			// the RoleRef of a role binding is immutable, if it changes delete the existing role binding and create a new one
 			if !reflect.DeepEqual(roleBinding.RoleRef, existingRoleBinding.RoleRef) {
 				if err = r.Client.Delete(context.TODO(), existingRoleBinding); err != nil {
 					return err
 				}
-			} else {
-				// if the Subjects differ, update the role bindings
-				if !reflect.DeepEqual(roleBinding.Subjects, existingRoleBinding.Subjects) {
-					existingRoleBinding.Subjects = roleBinding.Subjects
-					if err = r.Client.Update(context.TODO(), existingRoleBinding); err != nil {
-						return err
-					}
-				}
-				continue
 			}
 		}
 
@@ -210,8 +201,7 @@ func (r *ReconcileArgoCD) reconcileRoleBinding(name string, rules []v1.PolicyRul
 			}
 		}
 
-		log.Info(fmt.Sprintf("creating rolebinding %s for Argo CD instance %s in namespace %s", roleBinding.Name, cr.Name, cr.Namespace))
-		if err = r.Client.Create(context.TODO(), roleBinding); err != nil {
+		if err = argoutil.ApplyResource(r.Client, roleBinding); err != nil {
 			return err
 		}
 	}
@@ -286,25 +276,15 @@ func (r *ReconcileArgoCD) reconcileRoleBinding(name string, rules []v1.PolicyRul
 				if n, ok := namespace.Labels[common.ArgoCDManagedByClusterArgoCDLabel]; !ok || n != cr.Namespace {
 					continue
 				}
-				// if the RoleRef changes, delete the existing role binding and create a new one
+				// the RoleRef of a role binding is immutable, if it changes delete the existing role binding and create a new one
 				if !reflect.DeepEqual(roleBinding.RoleRef, existingRoleBinding.RoleRef) {
 					if err = r.Client.Delete(context.TODO(), existingRoleBinding); err != nil {
 						return err
 					}
-				} else {
-					// if the Subjects differ, update the role bindings
-					if !reflect.DeepEqual(roleBinding.Subjects, existingRoleBinding.Subjects) {
-						existingRoleBinding.Subjects = roleBinding.Subjects
-						if err = r.Client.Update(context.TODO(), existingRoleBinding); err != nil {
-							return err
-						}
-					}
-					continue
 				}
 			}
 
-			log.Info(fmt.Sprintf("creating rolebinding %s for Argo CD instance %s in namespace %s", roleBinding.Name, cr.Name, namespace))
-			if err = r.Client.Create(context.TODO(), roleBinding); err != nil {
+			if err = argoutil.ApplyResource(r.Client, roleBinding); err != nil {
 				return err
 			}
 		}
@@ -314,10 +294,10 @@ func (r *ReconcileArgoCD) reconcileRoleBinding(name string, rules []v1.PolicyRul
 
 func getCustomRoleName(name string) string {
 	if name == common.ArgoCDApplicationControllerComponent {
-		return os.Getenv(common.ArgoCDControllerClusterRoleEnvName)
+		return argocdoperatorconfig.Getenv(common.ArgoCDControllerClusterRoleEnvName)
 	}
 	if name == common.ArgoCDServerComponent {
-		return os.Getenv(common.ArgoCDServerClusterRoleEnvName)
+		return argocdoperatorconfig.Getenv(common.ArgoCDServerClusterRoleEnvName)
 	}
 	return ""
 }
@@ -360,18 +340,18 @@ func (r *ReconcileArgoCD) reconcileClusterRoleBinding(name string, role *v1.Clus
 	// get expected name
 	roleBinding := newClusterRoleBindingWithname(name, cr)
 	// fetch existing rolebinding by name
-	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: roleBinding.Name}, roleBinding)
+	existingRoleBinding := &v1.ClusterRoleBinding{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: roleBinding.Name}, existingRoleBinding)
 	roleBindingExists := true
 	if err != nil {
 		if !errors.IsNotFound(err) {
 			return err
 		}
 		roleBindingExists = false
-		roleBinding = newClusterRoleBindingWithname(name, cr)
 	}
 
 	if roleBindingExists && role == nil {
-		return r.Client.Delete(context.TODO(), roleBinding)
+		return r.Client.Delete(context.TODO(), existingRoleBinding)
 	}
 
 	if !roleBindingExists && role == nil {
@@ -392,16 +372,7 @@ func (r *ReconcileArgoCD) reconcileClusterRoleBinding(name string, role *v1.Clus
 		Name:     GenerateUniqueResourceName(name, cr),
 	}
 
-	if cr.Namespace == roleBinding.Namespace {
-		if err = controllerutil.SetControllerReference(cr, roleBinding, r.Scheme); err != nil {
-			return fmt.Errorf("failed to set ArgoCD CR \"%s\" as owner for roleBinding \"%s\": %s", cr.Name, roleBinding.Name, err)
-		}
-	}
-
-	if roleBindingExists {
-		return r.Client.Update(context.TODO(), roleBinding)
-	}
-	return r.Client.Create(context.TODO(), roleBinding)
+	return argoutil.ApplyResource(r.Client, roleBinding)
 }
 
 func deleteClusterRoleBindings(c client.Client, clusterBindingList *v1.ClusterRoleBindingList) error {
@@ -79,13 +79,13 @@ func TestReconcileArgoCD_reconcileRoleBinding_for_new_namespace(t *testing.T) {
 
 	// check no redisHa rolebinding is created for the new namespace with managed-by label
 	workloadIdentifier = common.ArgoCDRedisHAComponent
-	expectedRedisHaRules := policyRuleForRedisHa(r.Client)
+	expectedRedisHaRules := policyRuleForRedisHa(r.Client, r.ClusterAPIs)
 	assert.NoError(t, r.reconcileRoleBinding(workloadIdentifier, expectedRedisHaRules, a))
 	assert.Error(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: expectedName, Namespace: "newTestNamespace"}, roleBinding))
 
 	// check no redis rolebinding is created for the new namespace with managed-by label
 	workloadIdentifier = common.ArgoCDRedisComponent
-	expectedRedisRules := policyRuleForRedis(r.Client)
+	expectedRedisRules := policyRuleForRedis(r.Client, r.ClusterAPIs)
 	assert.NoError(t, r.reconcileRoleBinding(workloadIdentifier, expectedRedisRules, a))
 	assert.Error(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: expectedName, Namespace: "newTestNamespace"}, roleBinding))
 }
@@ -17,12 +17,16 @@ package argocd
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 
 	routev1 "github.com/openshift/api/route/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
@@ -36,20 +40,23 @@ const (
 	minFirstLabelSize = 20
 )
 
-var routeAPIFound = false
-
 // IsRouteAPIAvailable returns true if the Route API is present.
-func IsRouteAPIAvailable() bool {
-	return routeAPIFound
+func (a *ClusterAPIs) IsRouteAPIAvailable() bool {
+	return a.isAvailable(func(a *ClusterAPIs) bool { return a.route })
+}
+
+// setRouteAPIAvailable records whether the Route API is present.
+func (a *ClusterAPIs) setRouteAPIAvailable(found bool) {
+	a.setAvailable(func(a *ClusterAPIs) { a.route = found })
 }
 
 // verifyRouteAPI will verify that the Route API is present.
-func verifyRouteAPI() error {
+func (a *ClusterAPIs) verifyRouteAPI() error {
 	found, err := argoutil.VerifyAPI(routev1.GroupName, routev1.GroupVersion.Version)
 	if err != nil {
 		return err
 	}
-	routeAPIFound = found
+	a.setRouteAPIAvailable(found)
 	return nil
 }
 
@@ -95,6 +102,10 @@ func (r *ReconcileArgoCD) reconcileRoutes(cr *argoproj.ArgoCD) error {
 		return err
 	}
 
+	if err := r.reconcileServerGRPCRoute(cr); err != nil {
+		return err
+	}
+
 	if err := r.reconcileApplicationSetControllerWebhookRoute(cr); err != nil {
 		return err
 	}
@@ -154,7 +165,11 @@ func (r *ReconcileArgoCD) reconcilePrometheusRoute(cr *argoproj.ArgoCD) error {
 
 	// Allow override of the Host for the Route.
 	if len(cr.Spec.Prometheus.Host) > 0 {
-		route.Spec.Host = cr.Spec.Prometheus.Host // TODO: What additional role needed for this?
+		hostname, err := shortenHostname(cr.Spec.Prometheus.Host)
+		if err != nil {
+			return err
+		}
+		route.Spec.Host = hostname // TODO: What additional role needed for this?
 	}
 
 	route.Spec.Port = &routev1.RoutePort{
@@ -180,10 +195,72 @@ func (r *ReconcileArgoCD) reconcilePrometheusRoute(cr *argoproj.ArgoCD) error {
 	return r.Client.Create(context.TODO(), route)
 }
 
-// reconcileServerRoute will ensure that the ArgoCD Server Route is present.
+// reconcileServerRoute will ensure that the ArgoCD Server Routes are present, one for the Host of the server and
+// one for each of its additional hosts.
 func (r *ReconcileArgoCD) reconcileServerRoute(cr *argoproj.ArgoCD) error {
+	if err := r.reconcileServerRouteForHost(cr, "server", cr.Spec.Server.Host); err != nil {
+		return err
+	}
 
-	route := newRouteWithSuffix("server", cr)
+	if cr.Spec.Server.Route.Enabled {
+		for i, host := range cr.Spec.Server.AdditionalHosts {
+			if err := r.reconcileServerRouteForHost(cr, getAdditionalServerRouteSuffix(i), host); err != nil {
+				return err
+			}
+		}
+	}
+
+	return r.deleteStaleAdditionalServerRoutes(cr)
+}
+
+// getAdditionalServerRouteSuffix will return the name suffix of the Route for the additional host at the given index.
+func getAdditionalServerRouteSuffix(index int) string {
+	return fmt.Sprintf("server-%d", index+1)
+}
+
+// deleteStaleAdditionalServerRoutes will delete the Routes of additional hosts that have been removed from the
+// ArgoCD, or all of them when the server Route is disabled.
+func (r *ReconcileArgoCD) deleteStaleAdditionalServerRoutes(cr *argoproj.ArgoCD) error {
+	routes := &routev1.RouteList{}
+	opts := &client.ListOptions{
+		Namespace: cr.Namespace,
+		LabelSelector: labels.SelectorFromSet(map[string]string{
+			common.ArgoCDKeyPartOf:    common.ArgoCDAppName,
+			common.ArgoCDKeyManagedBy: cr.Name,
+		}),
+	}
+	if err := r.Client.List(context.TODO(), routes, opts); err != nil {
+		return err
+	}
+
+	count := 0
+	if cr.Spec.Server.Route.Enabled {
+		count = len(cr.Spec.Server.AdditionalHosts)
+	}
+
+	prefix := nameWithSuffix("server-", cr)
+	for i := range routes.Items {
+		route := &routes.Items[i]
+		if !strings.HasPrefix(route.Name, prefix) {
+			continue
+		}
+		index, err := strconv.Atoi(strings.TrimPrefix(route.Name, prefix))
+		if err != nil || index <= count {
+			continue
+		}
+		log.Info(fmt.Sprintf("deleting route %s of removed additional host %s", route.Name, route.Spec.Host))
+		if err := r.Client.Delete(context.TODO(), route); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// reconcileServerRouteForHost will ensure that the ArgoCD Server Route with the given name suffix is present and
+// serves the given host.
+func (r *ReconcileArgoCD) reconcileServerRouteForHost(cr *argoproj.ArgoCD, suffix, host string) error {
+
+	route := newRouteWithSuffix(suffix, cr)
 	found := argoutil.IsObjectFound(r.Client, cr.Namespace, route.Name, route)
 	if found {
 		if !cr.Spec.Server.Route.Enabled {
@@ -211,8 +288,8 @@ func (r *ReconcileArgoCD) reconcileServerRoute(cr *argoproj.ArgoCD) error {
 	}
 
 	// Allow override of the Host for the Route.
-	if len(cr.Spec.Server.Host) > 0 {
-		route.Spec.Host = cr.Spec.Server.Host // TODO: What additional role needed for this?
+	if len(host) > 0 {
+		route.Spec.Host = host // TODO: What additional role needed for this?
 	}
 
 	hostname, err := shortenHostname(route.Spec.Host)
@@ -274,6 +351,86 @@ func (r *ReconcileArgoCD) reconcileServerRoute(cr *argoproj.ArgoCD) error {
 	return r.Client.Update(context.TODO(), route)
 }
 
+// reconcileServerGRPCRoute will ensure that the ArgoCD Server GRPC Route is present.
+func (r *ReconcileArgoCD) reconcileServerGRPCRoute(cr *argoproj.ArgoCD) error {
+	route := newRouteWithSuffix("grpc", cr)
+	found := argoutil.IsObjectFound(r.Client, cr.Namespace, route.Name, route)
+	if found {
+		if !cr.Spec.Server.GRPC.Route.Enabled {
+			// Route exists but enabled flag has been set to false, delete the Route
+			return r.Client.Delete(context.TODO(), route)
+		}
+	}
+
+	if !cr.Spec.Server.GRPC.Route.Enabled {
+		return nil // Route not enabled, move along...
+	}
+
+	// Allow override of the Annotations for the Route.
+	if len(cr.Spec.Server.GRPC.Route.Annotations) > 0 {
+		route.Annotations = cr.Spec.Server.GRPC.Route.Annotations
+	}
+
+	// Allow override of the Labels for the Route.
+	if len(cr.Spec.Server.GRPC.Route.Labels) > 0 {
+		labels := route.Labels
+		for key, val := range cr.Spec.Server.GRPC.Route.Labels {
+			labels[key] = val
+		}
+		route.Labels = labels
+	}
+
+	// Allow override of the Host for the Route, the router generates one otherwise.
+	hostname, err := shortenHostname(cr.Spec.Server.GRPC.Host)
+	if err != nil {
+		return err
+	}
+	route.Spec.Host = hostname
+	route.Spec.Path = cr.Spec.Server.GRPC.Route.Path
+
+	if cr.Spec.Server.Insecure {
+		// The server does not serve TLS, terminate it at the router. Clients have to fall back to gRPC-Web unless
+		// HTTP/2 is enabled on the ingress controller.
+		route.Spec.Port = &routev1.RoutePort{
+			TargetPort: intstr.FromString("http"),
+		}
+		route.Spec.TLS = &routev1.TLSConfig{
+			InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+			Termination:                   routev1.TLSTerminationEdge,
+		}
+	} else {
+		// Pass the TLS connection through so that clients negotiate HTTP/2 with the server directly.
+		route.Spec.Port = &routev1.RoutePort{
+			TargetPort: intstr.FromString("https"),
+		}
+		route.Spec.TLS = &routev1.TLSConfig{
+			InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyNone,
+			Termination:                   routev1.TLSTerminationPassthrough,
+		}
+	}
+
+	// Allow override of TLS options for the Route
+	if cr.Spec.Server.GRPC.Route.TLS != nil {
+		route.Spec.TLS = cr.Spec.Server.GRPC.Route.TLS
+	}
+
+	route.Spec.To.Kind = "Service"
+	route.Spec.To.Name = nameWithSuffix("server", cr)
+
+	// Allow override of the WildcardPolicy for the Route
+	if cr.Spec.Server.GRPC.Route.WildcardPolicy != nil && len(*cr.Spec.Server.GRPC.Route.WildcardPolicy) > 0 {
+		route.Spec.WildcardPolicy = *cr.Spec.Server.GRPC.Route.WildcardPolicy
+	}
+
+	if err := controllerutil.SetControllerReference(cr, route, r.Scheme); err != nil {
+		return err
+	}
+	if !found {
+		return r.Client.Create(context.TODO(), route)
+	}
+	return r.Client.Update(context.TODO(), route)
+}
+
 // reconcileApplicationSetControllerWebhookRoute will ensure that the ArgoCD Server Route is present.
 func (r *ReconcileArgoCD) reconcileApplicationSetControllerWebhookRoute(cr *argoproj.ArgoCD) error {
 	name := fmt.Sprintf("%s-%s", common.ApplicationSetServiceNameSuffix, "webhook")
@@ -8,6 +8,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -27,7 +28,6 @@ import (
 )
 
 func TestReconcileRouteSetLabels(t *testing.T) {
-	routeAPIFound = true
 	ctx := context.Background()
 	logf.SetLogger(ZapLogger(true))
 	argoCD := makeArgoCD(func(a *argoproj.ArgoCD) {
@@ -43,6 +43,7 @@ func TestReconcileRouteSetLabels(t *testing.T) {
 	sch := makeTestReconcilerScheme(argoproj.AddToScheme, configv1.Install, routev1.Install)
 	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
 	r := makeTestReconciler(cl, sch)
+	r.ClusterAPIs.setRouteAPIAvailable(true)
 
 	assert.NoError(t, createNamespace(r, argoCD.Namespace, ""))
 
@@ -66,7 +67,6 @@ func TestReconcileRouteSetLabels(t *testing.T) {
 
 }
 func TestReconcileRouteSetsInsecure(t *testing.T) {
-	routeAPIFound = true
 	ctx := context.Background()
 	logf.SetLogger(ZapLogger(true))
 	argoCD := makeArgoCD(func(a *argoproj.ArgoCD) {
@@ -79,6 +79,7 @@ func TestReconcileRouteSetsInsecure(t *testing.T) {
 	sch := makeTestReconcilerScheme(argoproj.AddToScheme, configv1.Install, routev1.Install)
 	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
 	r := makeTestReconciler(cl, sch)
+	r.ClusterAPIs.setRouteAPIAvailable(true)
 
 	assert.NoError(t, createNamespace(r, argoCD.Namespace, ""))
 
@@ -141,7 +142,6 @@ func TestReconcileRouteSetsInsecure(t *testing.T) {
 }
 
 func TestReconcileRouteUnsetsInsecure(t *testing.T) {
-	routeAPIFound = true
 	ctx := context.Background()
 	logf.SetLogger(ZapLogger(true))
 	argoCD := makeArgoCD(func(a *argoproj.ArgoCD) {
@@ -155,6 +155,7 @@ func TestReconcileRouteUnsetsInsecure(t *testing.T) {
 	sch := makeTestReconcilerScheme(argoproj.AddToScheme, configv1.Install, routev1.Install)
 	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
 	r := makeTestReconciler(cl, sch)
+	r.ClusterAPIs.setRouteAPIAvailable(true)
 
 	assert.NoError(t, createNamespace(r, argoCD.Namespace, ""))
 
@@ -217,7 +218,6 @@ func TestReconcileRouteUnsetsInsecure(t *testing.T) {
 }
 
 func TestReconcileRouteApplicationSetHost(t *testing.T) {
-	routeAPIFound = true
 	ctx := context.Background()
 	logf.SetLogger(ZapLogger(true))
 	argoCD := makeArgoCD(func(a *argoproj.ArgoCD) {
@@ -238,6 +238,7 @@ func TestReconcileRouteApplicationSetHost(t *testing.T) {
 	sch := makeTestReconcilerScheme(argoproj.AddToScheme, configv1.Install, routev1.Install)
 	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
 	r := makeTestReconciler(cl, sch)
+	r.ClusterAPIs.setRouteAPIAvailable(true)
 
 	assert.NoError(t, createNamespace(r, argoCD.Namespace, ""))
 
@@ -269,7 +270,6 @@ func TestReconcileRouteApplicationSetHost(t *testing.T) {
 }
 
 func TestReconcileRouteApplicationSetTlsTermination(t *testing.T) {
-	routeAPIFound = true
 	ctx := context.Background()
 	logf.SetLogger(ZapLogger(true))
 	argoCD := makeArgoCD(func(a *argoproj.ArgoCD) {
@@ -294,6 +294,7 @@ func TestReconcileRouteApplicationSetTlsTermination(t *testing.T) {
 	sch := makeTestReconcilerScheme(argoproj.AddToScheme, configv1.Install, routev1.Install)
 	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
 	r := makeTestReconciler(cl, sch)
+	r.ClusterAPIs.setRouteAPIAvailable(true)
 
 	assert.NoError(t, createNamespace(r, argoCD.Namespace, ""))
 
@@ -325,7 +326,6 @@ func TestReconcileRouteApplicationSetTlsTermination(t *testing.T) {
 }
 
 func TestReconcileRouteApplicationSetTls(t *testing.T) {
-	routeAPIFound = true
 	ctx := context.Background()
 	logf.SetLogger(ZapLogger(true))
 	wildcardPolicy := routev1.WildcardPolicyType("subdomain")
@@ -356,6 +356,7 @@ func TestReconcileRouteApplicationSetTls(t *testing.T) {
 	sch := makeTestReconcilerScheme(argoproj.AddToScheme, configv1.Install, routev1.Install)
 	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
 	r := makeTestReconciler(cl, sch)
+	r.ClusterAPIs.setRouteAPIAvailable(true)
 
 	assert.NoError(t, createNamespace(r, argoCD.Namespace, ""))
 
@@ -408,7 +409,6 @@ func TestReconcileRouteApplicationSetTls(t *testing.T) {
 }
 
 func TestReconcileRouteForShorteningHostname(t *testing.T) {
-	routeAPIFound = true
 	ctx := context.Background()
 	logf.SetLogger(ZapLogger(true))
 
@@ -450,6 +450,7 @@ func TestReconcileRouteForShorteningHostname(t *testing.T) {
 			sch := makeTestReconcilerScheme(argoproj.AddToScheme, configv1.Install, routev1.Install)
 			cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
 			r := makeTestReconciler(cl, sch)
+			r.ClusterAPIs.setRouteAPIAvailable(true)
 
 			assert.NoError(t, createNamespace(r, argoCD.Namespace, ""))
 
@@ -492,7 +493,6 @@ func TestReconcileRouteForShorteningHostname(t *testing.T) {
 }
 
 func TestReconcileRouteTLSConfig(t *testing.T) {
-	routeAPIFound = true
 	ctx := context.Background()
 	logf.SetLogger(ZapLogger(true))
 
@@ -560,6 +560,7 @@ func TestReconcileRouteTLSConfig(t *testing.T) {
 			sch := makeTestReconcilerScheme(argoproj.AddToScheme, configv1.Install, routev1.Install)
 			fakeClient := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
 			reconciler := makeTestReconciler(fakeClient, sch)
+			reconciler.ClusterAPIs.setRouteAPIAvailable(true)
 
 			test.createResources(fakeClient, argoCD)
 			req := reconcile.Request{
@@ -594,8 +595,10 @@ func makeReconciler(t *testing.T, acd *argoproj.ArgoCD, objs ...runtime.Object)
 	cl := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(objs...).WithStatusSubresource(clientObjs...).Build()
 
 	return &ReconcileArgoCD{
-		Client: cl,
-		Scheme: s,
+		Client:      cl,
+		Scheme:      s,
+		ClusterAPIs: &ClusterAPIs{},
+		instances:   newInstanceTracker(),
 	}
 }
 
@@ -634,3 +637,94 @@ func testNamespacedName(name string) types.NamespacedName {
 		Namespace: testNamespace,
 	}
 }
+
+func TestReconcileServerGRPCRoute(t *testing.T) {
+	ctx := context.Background()
+	logf.SetLogger(ZapLogger(true))
+	argoCD := makeArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.Server.GRPC.Host = "grpc.argocd.example.com"
+		a.Spec.Server.GRPC.Route.Enabled = true
+	})
+
+	resObjs := []client.Object{argoCD}
+	subresObjs := []client.Object{argoCD}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme, configv1.Install, routev1.Install)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+	r.ClusterAPIs.setRouteAPIAvailable(true)
+
+	assert.NoError(t, r.reconcileServerGRPCRoute(argoCD))
+
+	loaded := &routev1.Route{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: testArgoCDName + "-grpc", Namespace: testNamespace}, loaded)
+	fatalIfError(t, err, "failed to load route %q: %s", testArgoCDName+"-grpc", err)
+
+	assert.Equal(t, "grpc.argocd.example.com", loaded.Spec.Host)
+	assert.Equal(t, testArgoCDName+"-server", loaded.Spec.To.Name)
+	assert.Equal(t, intstr.FromString("https"), loaded.Spec.Port.TargetPort)
+	assert.Equal(t, routev1.TLSTerminationPassthrough, loaded.Spec.TLS.Termination)
+
+	// An insecure server is served through edge termination
+	argoCD.Spec.Server.Insecure = true
+	assert.NoError(t, r.reconcileServerGRPCRoute(argoCD))
+	err = r.Client.Get(ctx, types.NamespacedName{Name: testArgoCDName + "-grpc", Namespace: testNamespace}, loaded)
+	fatalIfError(t, err, "failed to load route %q: %s", testArgoCDName+"-grpc", err)
+	assert.Equal(t, intstr.FromString("http"), loaded.Spec.Port.TargetPort)
+	assert.Equal(t, routev1.TLSTerminationEdge, loaded.Spec.TLS.Termination)
+
+	// Disabling the route deletes it
+	argoCD.Spec.Server.GRPC.Route.Enabled = false
+	assert.NoError(t, r.reconcileServerGRPCRoute(argoCD))
+	err = r.Client.Get(ctx, types.NamespacedName{Name: testArgoCDName + "-grpc", Namespace: testNamespace}, loaded)
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestReconcileServerRouteAdditionalHosts(t *testing.T) {
+	ctx := context.Background()
+	logf.SetLogger(ZapLogger(true))
+	longHost := "myhostnameaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa.aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa.aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa.aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa.aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa.redhat.com"
+	argoCD := makeArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.Server.Host = "argocd.example.com"
+		a.Spec.Server.AdditionalHosts = []string{"argocd.internal.example.com", longHost}
+		a.Spec.Server.Route.Enabled = true
+	})
+
+	resObjs := []client.Object{argoCD}
+	subresObjs := []client.Object{argoCD}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme, configv1.Install, routev1.Install)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+	r.ClusterAPIs.setRouteAPIAvailable(true)
+
+	assert.NoError(t, r.reconcileServerRoute(argoCD))
+
+	expected := map[string]string{
+		testArgoCDName + "-server":   "argocd.example.com",
+		testArgoCDName + "-server-1": "argocd.internal.example.com",
+		testArgoCDName + "-server-2": "myhostnameaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa.aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa.aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa.aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa.aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa.redhat.com",
+	}
+	for name, host := range expected {
+		loaded := &routev1.Route{}
+		err := r.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: testNamespace}, loaded)
+		fatalIfError(t, err, "failed to load route %q: %s", name, err)
+		assert.Equal(t, host, loaded.Spec.Host)
+		assert.Equal(t, testArgoCDName+"-server", loaded.Spec.To.Name)
+	}
+
+	// Removing an additional host deletes its route
+	argoCD.Spec.Server.AdditionalHosts = argoCD.Spec.Server.AdditionalHosts[:1]
+	assert.NoError(t, r.reconcileServerRoute(argoCD))
+	err := r.Client.Get(ctx, types.NamespacedName{Name: testArgoCDName + "-server-2", Namespace: testNamespace}, &routev1.Route{})
+	assert.True(t, apierrors.IsNotFound(err))
+	assert.NoError(t, r.Client.Get(ctx, types.NamespacedName{Name: testArgoCDName + "-server-1", Namespace: testNamespace}, &routev1.Route{}))
+
+	// Disabling the route deletes all of them
+	argoCD.Spec.Server.Route.Enabled = false
+	assert.NoError(t, r.reconcileServerRoute(argoCD))
+	for name := range expected {
+		err := r.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: testNamespace}, &routev1.Route{})
+		assert.True(t, apierrors.IsNotFound(err))
+	}
+}
@@ -21,7 +21,6 @@ import (
 	"crypto/x509"
 	"encoding/json"
 	"fmt"
-	"os"
 	"sort"
 	"strings"
 	"time"
@@ -31,10 +30,13 @@ import (
 
 	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
 	"github.com/argoproj-labs/argocd-operator/common"
+	"github.com/argoproj-labs/argocd-operator/controllers/argocdoperatorconfig"
 	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -128,7 +130,11 @@ func newCertificateSecret(suffix string, caCert *x509.Certificate, caKey *rsa.Pr
 		log.Info(grafanaDeprecatedWarning)
 	}
 	if cr.Spec.Prometheus.Enabled {
-		dnsNames = append(dnsNames, getPrometheusHost(cr))
+		host, err := getPrometheusHost(cr)
+		if err != nil {
+			return nil, err
+		}
+		dnsNames = append(dnsNames, host)
 	}
 
 	cert, err := argoutil.NewSignedCertificate(cfg, dnsNames, key, caCert, caKey)
@@ -410,7 +416,7 @@ func (r *ReconcileArgoCD) reconcileClusterPermissionsSecret(cr *argoproj.ArgoCD)
 		"namespaces": []byte(strings.Join(namespaces, ",")),
 	}
 
-	if allowedNamespace(cr.Namespace, os.Getenv("ARGOCD_CLUSTER_CONFIG_NAMESPACES")) {
+	if allowedNamespace(cr.Namespace, argocdoperatorconfig.Getenv(common.ArgoCDClusterConfigNamespacesEnvName)) {
 		clusterConfigInstance = true
 	}
 
@@ -632,6 +638,10 @@ func (r *ReconcileArgoCD) reconcileSecrets(cr *argoproj.ArgoCD) error {
 		return err
 	}
 
+	if err := r.reconcileRepositorySecrets(cr); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -665,8 +675,9 @@ func (r *ReconcileArgoCD) reconcileRedisInitialPasswordSecret(cr *argoproj.ArgoC
 	}
 
 	secret.Data = map[string][]byte{
-		"immutable":                   []byte("true"),
-		common.ArgoCDKeyAdminPassword: redisInitialPassword,
+		"immutable":                        []byte("true"),
+		common.ArgoCDKeyAdminPassword:      redisInitialPassword,
+		common.ArgoCDKeyAdminPasswordMTime: nowBytes(),
 	}
 
 	if err := controllerutil.SetControllerReference(cr, secret, r.Scheme); err != nil {
@@ -674,3 +685,216 @@ func (r *ReconcileArgoCD) reconcileRedisInitialPasswordSecret(cr *argoproj.ArgoC
 	}
 	return r.Client.Create(context.TODO(), secret)
 }
+
+const (
+	// redisPasswordRotationRestartingRedis is the rotation status while Redis is restarted with a new password.
+	redisPasswordRotationRestartingRedis = "RestartingRedis"
+
+	// redisPasswordRotationRestartingComponents is the rotation status while the Redis clients are restarted.
+	redisPasswordRotationRestartingComponents = "RestartingComponents"
+
+	// redisPasswordRotationCompleted is the rotation status once every component uses the new password.
+	redisPasswordRotationCompleted = "Completed"
+
+	// redisPasswordRotatedKey is the pod template label used to restart the workloads using the Redis password.
+	redisPasswordRotatedKey = "redis.password.rotated"
+)
+
+// getRedisPasswordAge will return the time elapsed since the given Redis password Secret was last modified.
+func getRedisPasswordAge(secret *corev1.Secret) time.Duration {
+	mtime := secret.CreationTimestamp.Time
+	if value, ok := secret.Data[common.ArgoCDKeyAdminPasswordMTime]; ok {
+		if t, err := time.Parse(time.RFC3339, string(value)); err == nil {
+			mtime = t
+		}
+	}
+	return time.Since(mtime)
+}
+
+// isRedisPasswordRotationRequested will return true if the Redis password of the given ArgoCD should be rotated,
+// either because a rotation was requested through the annotation, or because the password has exceeded its interval.
+func isRedisPasswordRotationRequested(cr *argoproj.ArgoCD, secret *corev1.Secret) bool {
+	rotation := cr.Spec.Redis.PasswordRotation
+	if !rotation.IsEnabled() {
+		return false
+	}
+
+	if trigger, ok := cr.Annotations[common.AnnotationRedisPasswordRotate]; ok && trigger != secret.Annotations[common.AnnotationRedisPasswordRotate] {
+		log.Info(fmt.Sprintf("rotation of the redis password requested for argocd %s in namespace %s", cr.Name, cr.Namespace))
+		return true
+	}
+
+	if rotation.Interval != nil && rotation.Interval.Duration > 0 && getRedisPasswordAge(secret) >= rotation.Interval.Duration {
+		log.Info(fmt.Sprintf("redis password for argocd %s in namespace %s is older than %s", cr.Name, cr.Namespace, rotation.Interval.Duration))
+		return true
+	}
+	return false
+}
+
+// getRedisPasswordRotationRequeueAfter will return the duration after which the given ArgoCD should be reconciled
+// again for its next scheduled Redis password rotation. Zero is returned if no rotation is scheduled.
+func (r *ReconcileArgoCD) getRedisPasswordRotationRequeueAfter(cr *argoproj.ArgoCD) time.Duration {
+	rotation := cr.Spec.Redis.PasswordRotation
+	if !rotation.IsEnabled() || rotation.Interval == nil || rotation.Interval.Duration <= 0 {
+		return 0
+	}
+
+	secret := argoutil.NewSecretWithSuffix(cr, "redis-initial-password")
+	if !argoutil.IsObjectFound(r.Client, cr.Namespace, secret.Name, secret) {
+		return 0
+	}
+
+	remaining := rotation.Interval.Duration - getRedisPasswordAge(secret)
+	if remaining < time.Second {
+		remaining = time.Second
+	}
+	return remaining
+}
+
+// getRedisPasswordWorkloads will return the Redis workloads that need to be restarted for a new password to be used.
+func getRedisPasswordWorkloads(cr *argoproj.ArgoCD) []client.Object {
+	if cr.Spec.HA.Enabled {
+		return []client.Object{
+			newStatefulSetWithSuffix("redis-ha-server", "redis", cr),
+			newDeploymentWithSuffix("redis-ha-haproxy", "redis", cr),
+		}
+	}
+	return []client.Object{
+		newDeploymentWithSuffix("redis", "redis", cr),
+	}
+}
+
+// getRedisPasswordConsumers will return the Argo CD workloads that need to be restarted to use a new Redis password.
+func getRedisPasswordConsumers(cr *argoproj.ArgoCD) []client.Object {
+	return []client.Object{
+		newDeploymentWithSuffix("repo-server", "repo-server", cr),
+		newStatefulSetWithSuffix("application-controller", "application-controller", cr),
+		newDeploymentWithSuffix("server", "server", cr),
+		newDeploymentWithSuffix("applicationset-controller", "controller", cr),
+	}
+}
+
+// areWorkloadsRolledOut will return true if every one of the given workloads that exists has completed its rollout.
+func (r *ReconcileArgoCD) areWorkloadsRolledOut(objs []client.Object) bool {
+	for _, obj := range objs {
+		if !argoutil.IsObjectFound(r.Client, obj.GetNamespace(), obj.GetName(), obj) {
+			continue
+		}
+		switch res := obj.(type) {
+		case *appsv1.Deployment:
+			if !isDeploymentRolledOut(res) {
+				return false
+			}
+		case *appsv1.StatefulSet:
+			if !isStatefulSetRolledOut(res) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// reconcileRedisPasswordRotation will rotate the Redis password when requested, and restart Redis first and the
+// Argo CD components using it second, so that the components always reconnect to a Redis using the new password.
+//
+// The rotation is recorded in the status with a new ID before anything else is changed. Every step is then applied
+// again for that ID on each reconciliation until it is done, so that a rotation interrupted at any point resumes
+// where it stopped instead of rotating the password a second time or restarting the components too early.
+func (r *ReconcileArgoCD) reconcileRedisPasswordRotation(cr *argoproj.ArgoCD) error {
+	switch cr.Status.RedisPasswordRotation {
+	case redisPasswordRotationRestartingRedis:
+		if err := r.rotateRedisPassword(cr); err != nil {
+			return err
+		}
+		for _, obj := range getRedisPasswordWorkloads(cr) {
+			if err := r.applyRollout(obj, redisPasswordRotatedKey, cr.Status.RedisPasswordRotationID); err != nil {
+				return err
+			}
+		}
+		if !r.areWorkloadsRolledOut(getRedisPasswordWorkloads(cr)) {
+			return nil // Redis is still restarting, check again on the next reconciliation
+		}
+		log.Info("redis restarted with the new password, restarting argo cd components")
+		cr.Status.RedisPasswordRotation = redisPasswordRotationRestartingComponents
+		if err := r.Client.Status().Update(context.TODO(), cr); err != nil {
+			return err
+		}
+		return r.reconcileRedisPasswordRotation(cr)
+	case redisPasswordRotationRestartingComponents:
+		for _, obj := range getRedisPasswordConsumers(cr) {
+			if err := r.applyRollout(obj, redisPasswordRotatedKey, cr.Status.RedisPasswordRotationID); err != nil {
+				return err
+			}
+		}
+		if !r.areWorkloadsRolledOut(getRedisPasswordConsumers(cr)) {
+			return nil // Components are still restarting, check again on the next reconciliation
+		}
+		log.Info("redis password rotation completed")
+		cr.Status.RedisPasswordRotation = redisPasswordRotationCompleted
+		return r.Client.Status().Update(context.TODO(), cr)
+	}
+
+	if !cr.Spec.Redis.IsEnabled() || isRemoteRedis(cr) {
+		return nil // Redis is not managed by the operator, nothing to rotate
+	}
+
+	secret := argoutil.NewSecretWithSuffix(cr, "redis-initial-password")
+	if !argoutil.IsObjectFound(r.Client, cr.Namespace, secret.Name, secret) {
+		return nil
+	}
+
+	if !isRedisPasswordRotationRequested(cr, secret) {
+		return nil
+	}
+
+	log.Info(fmt.Sprintf("rotating the redis password for argocd %s in namespace %s", cr.Name, cr.Namespace))
+	now := metav1.Now()
+	cr.Status.RedisPasswordRotation = redisPasswordRotationRestartingRedis
+	cr.Status.RedisPasswordRotationTime = &now
+	cr.Status.RedisPasswordRotationID = nowNano()
+	if err := r.Client.Status().Update(context.TODO(), cr); err != nil {
+		return err
+	}
+	return r.reconcileRedisPasswordRotation(cr)
+}
+
+// rotateRedisPassword will write a new Redis password for the current rotation of the given ArgoCD, unless the
+// password has already been written for it.
+func (r *ReconcileArgoCD) rotateRedisPassword(cr *argoproj.ArgoCD) error {
+	secret := argoutil.NewSecretWithSuffix(cr, "redis-initial-password")
+	if !argoutil.IsObjectFound(r.Client, cr.Namespace, secret.Name, secret) {
+		return nil
+	}
+	if secret.Annotations[common.AnnotationRedisPasswordRotationID] == cr.Status.RedisPasswordRotationID {
+		return nil // Password already written for this rotation
+	}
+
+	redisPassword, err := generateRedisAdminPassword()
+	if err != nil {
+		return err
+	}
+
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations[common.AnnotationRedisPasswordRotationID] = cr.Status.RedisPasswordRotationID
+	if trigger, ok := cr.Annotations[common.AnnotationRedisPasswordRotate]; ok {
+		secret.Annotations[common.AnnotationRedisPasswordRotate] = trigger
+	}
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[common.ArgoCDKeyAdminPassword] = redisPassword
+	secret.Data[common.ArgoCDKeyAdminPasswordMTime] = nowBytes()
+	if err := r.Client.Update(context.TODO(), secret); err != nil {
+		return err
+	}
+
+	// In HA mode the Redis pods are rolled one at a time, as for any other change of their template, so that Redis
+	// stays available and its data is kept. Until the roll completes, the pods not yet restarted still run with the
+	// old password, both for Redis and for the auth-pass of their Sentinel, so the restarted replicas cannot sync
+	// from the master and a failover during the roll may fail. The components are only restarted once all the Redis
+	// pods have rolled out.
+	log.Info(fmt.Sprintf("redis password rotated for argocd %s in namespace %s, restarting redis", cr.Name, cr.Namespace))
+	return nil
+}
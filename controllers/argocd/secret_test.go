@@ -8,11 +8,13 @@ import (
 	"reflect"
 	"sort"
 	"testing"
+	"time"
 
 	configv1 "github.com/openshift/api/config/v1"
 	routev1 "github.com/openshift/api/route/v1"
 	"github.com/stretchr/testify/assert"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -503,6 +505,221 @@ func Test_ReconcileArgoCD_ReconcileRedisTLSSecret(t *testing.T) {
 	})
 }
 
+func Test_ReconcileArgoCD_ReconcileRedisPasswordRotation(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.Redis.PasswordRotation = &argoproj.ArgoCDRedisPasswordRotationSpec{Enabled: true}
+	})
+	redisDepl := newDeploymentWithSuffix("redis", "redis", a)
+	serverDepl := newDeploymentWithSuffix("server", "server", a)
+	repoDepl := newDeploymentWithSuffix("repo-server", "repo-server", a)
+
+	resObjs := []client.Object{a, redisDepl, serverDepl, repoDepl}
+	subresObjs := []client.Object{a, redisDepl, serverDepl, repoDepl}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileRedisInitialPasswordSecret(a))
+	secret := argoutil.NewSecretWithSuffix(a, "redis-initial-password")
+	assert.NoError(t, argoutil.FetchObject(r.Client, a.Namespace, secret.Name, secret))
+	initialPassword := string(secret.Data[common.ArgoCDKeyAdminPassword])
+
+	// no rotation happens until it is requested
+	assert.NoError(t, r.reconcileRedisPasswordRotation(a))
+	assert.NoError(t, argoutil.FetchObject(r.Client, a.Namespace, secret.Name, secret))
+	assert.Equal(t, initialPassword, string(secret.Data[common.ArgoCDKeyAdminPassword]))
+	assert.Empty(t, a.Status.RedisPasswordRotation)
+
+	// requesting a rotation writes a new password and restarts redis
+	a.Annotations = map[string]string{common.AnnotationRedisPasswordRotate: "1"}
+	assert.NoError(t, r.Client.Update(context.TODO(), a))
+	assert.NoError(t, r.reconcileRedisPasswordRotation(a))
+	assert.NoError(t, argoutil.FetchObject(r.Client, a.Namespace, secret.Name, secret))
+	rotatedPassword := string(secret.Data[common.ArgoCDKeyAdminPassword])
+	assert.NotEqual(t, initialPassword, rotatedPassword)
+	assert.Equal(t, "1", secret.Annotations[common.AnnotationRedisPasswordRotate])
+	assert.Equal(t, redisPasswordRotationRestartingRedis, a.Status.RedisPasswordRotation)
+	assert.NotNil(t, a.Status.RedisPasswordRotationTime)
+	assert.NotEmpty(t, a.Status.RedisPasswordRotationID)
+	assert.NoError(t, argoutil.FetchObject(r.Client, a.Namespace, redisDepl.Name, redisDepl))
+	assert.Equal(t, a.Status.RedisPasswordRotationID, redisDepl.Spec.Template.Labels[redisPasswordRotatedKey])
+
+	// components are not restarted until redis has rolled out
+	assert.NoError(t, r.reconcileRedisPasswordRotation(a))
+	assert.Equal(t, redisPasswordRotationRestartingRedis, a.Status.RedisPasswordRotation)
+	assert.NoError(t, argoutil.FetchObject(r.Client, a.Namespace, serverDepl.Name, serverDepl))
+	assert.NotContains(t, serverDepl.Spec.Template.Labels, redisPasswordRotatedKey)
+
+	redisDepl.Status = appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1, ReadyReplicas: 1}
+	assert.NoError(t, r.Client.Status().Update(context.TODO(), redisDepl))
+	assert.NoError(t, r.reconcileRedisPasswordRotation(a))
+	assert.Equal(t, redisPasswordRotationRestartingComponents, a.Status.RedisPasswordRotation)
+	for _, deploy := range []*appsv1.Deployment{serverDepl, repoDepl} {
+		assert.NoError(t, argoutil.FetchObject(r.Client, a.Namespace, deploy.Name, deploy))
+		assert.Equal(t, a.Status.RedisPasswordRotationID, deploy.Spec.Template.Labels[redisPasswordRotatedKey])
+	}
+
+	// the rotation completes once all the components have rolled out
+	for _, deploy := range []*appsv1.Deployment{serverDepl, repoDepl} {
+		deploy.Status = appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1, ReadyReplicas: 1}
+		assert.NoError(t, r.Client.Status().Update(context.TODO(), deploy))
+	}
+	assert.NoError(t, r.reconcileRedisPasswordRotation(a))
+	assert.Equal(t, redisPasswordRotationCompleted, a.Status.RedisPasswordRotation)
+
+	// the same request does not rotate the password again
+	assert.NoError(t, r.reconcileRedisPasswordRotation(a))
+	assert.NoError(t, argoutil.FetchObject(r.Client, a.Namespace, secret.Name, secret))
+	assert.Equal(t, rotatedPassword, string(secret.Data[common.ArgoCDKeyAdminPassword]))
+	assert.Equal(t, redisPasswordRotationCompleted, a.Status.RedisPasswordRotation)
+}
+
+func Test_ReconcileArgoCD_ReconcileRedisPasswordRotation_HA(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.HA.Enabled = true
+		a.Spec.Redis.PasswordRotation = &argoproj.ArgoCDRedisPasswordRotationSpec{Enabled: true}
+	})
+	redisSts := newStatefulSetWithSuffix("redis-ha-server", "redis", a)
+	redisSts.Spec.Replicas = int32Ptr(3)
+	haProxyDepl := newDeploymentWithSuffix("redis-ha-haproxy", "redis", a)
+	serverDepl := newDeploymentWithSuffix("server", "server", a)
+
+	resObjs := []client.Object{a, redisSts, haProxyDepl, serverDepl}
+	subresObjs := []client.Object{a, redisSts, haProxyDepl, serverDepl}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileRedisInitialPasswordSecret(a))
+	a.Annotations = map[string]string{common.AnnotationRedisPasswordRotate: "1"}
+	assert.NoError(t, r.reconcileRedisPasswordRotation(a))
+	assert.Equal(t, redisPasswordRotationRestartingRedis, a.Status.RedisPasswordRotation)
+
+	// the Redis HA pods are rolled rather than deleted along with their StatefulSet
+	assert.NoError(t, argoutil.FetchObject(r.Client, a.Namespace, redisSts.Name, redisSts))
+	assert.Nil(t, redisSts.DeletionTimestamp)
+	assert.Equal(t, a.Status.RedisPasswordRotationID, redisSts.Spec.Template.Labels[redisPasswordRotatedKey])
+	assert.NoError(t, argoutil.FetchObject(r.Client, a.Namespace, haProxyDepl.Name, haProxyDepl))
+	assert.Equal(t, a.Status.RedisPasswordRotationID, haProxyDepl.Spec.Template.Labels[redisPasswordRotatedKey])
+
+	// while the StatefulSet is rolling, some Redis pods and their Sentinels still use the old password, so the
+	// components are not restarted until every Redis pod has been rolled, even if HAProxy has rolled out already
+	haProxyDepl.Status = appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1, ReadyReplicas: 1}
+	assert.NoError(t, r.Client.Status().Update(context.TODO(), haProxyDepl))
+	redisSts.Status = appsv1.StatefulSetStatus{
+		Replicas:        3,
+		ReadyReplicas:   3,
+		UpdatedReplicas: 1,
+		CurrentRevision: "old",
+		UpdateRevision:  "new",
+	}
+	assert.NoError(t, r.Client.Status().Update(context.TODO(), redisSts))
+	assert.NoError(t, r.reconcileRedisPasswordRotation(a))
+	assert.Equal(t, redisPasswordRotationRestartingRedis, a.Status.RedisPasswordRotation)
+	assert.NoError(t, argoutil.FetchObject(r.Client, a.Namespace, serverDepl.Name, serverDepl))
+	assert.NotContains(t, serverDepl.Spec.Template.Labels, redisPasswordRotatedKey)
+
+	redisSts.Status = appsv1.StatefulSetStatus{
+		Replicas:        3,
+		ReadyReplicas:   3,
+		UpdatedReplicas: 3,
+		CurrentRevision: "new",
+		UpdateRevision:  "new",
+	}
+	assert.NoError(t, r.Client.Status().Update(context.TODO(), redisSts))
+	assert.NoError(t, r.reconcileRedisPasswordRotation(a))
+	assert.Equal(t, redisPasswordRotationRestartingComponents, a.Status.RedisPasswordRotation)
+	assert.NoError(t, argoutil.FetchObject(r.Client, a.Namespace, serverDepl.Name, serverDepl))
+	assert.Equal(t, a.Status.RedisPasswordRotationID, serverDepl.Spec.Template.Labels[redisPasswordRotatedKey])
+}
+
+func Test_ReconcileArgoCD_ReconcileRedisPasswordRotation_resume(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.Redis.PasswordRotation = &argoproj.ArgoCDRedisPasswordRotationSpec{Enabled: true}
+		a.Annotations = map[string]string{common.AnnotationRedisPasswordRotate: "1"}
+		// a rotation interrupted right after it was recorded in the status
+		a.Status.RedisPasswordRotation = redisPasswordRotationRestartingRedis
+		a.Status.RedisPasswordRotationID = "12345"
+	})
+	redisDepl := newDeploymentWithSuffix("redis", "redis", a)
+	secret := argoutil.NewSecretWithSuffix(a, "redis-initial-password")
+	secret.Data = map[string][]byte{
+		common.ArgoCDKeyAdminPassword: []byte("password"),
+	}
+
+	resObjs := []client.Object{a, redisDepl, secret}
+	subresObjs := []client.Object{a, redisDepl}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	// the password is written and redis restarted for the recorded rotation
+	assert.NoError(t, r.reconcileRedisPasswordRotation(a))
+	assert.NoError(t, argoutil.FetchObject(r.Client, a.Namespace, secret.Name, secret))
+	rotatedPassword := string(secret.Data[common.ArgoCDKeyAdminPassword])
+	assert.NotEqual(t, "password", rotatedPassword)
+	assert.Equal(t, "12345", secret.Annotations[common.AnnotationRedisPasswordRotationID])
+	assert.Equal(t, "1", secret.Annotations[common.AnnotationRedisPasswordRotate])
+	assert.NoError(t, argoutil.FetchObject(r.Client, a.Namespace, redisDepl.Name, redisDepl))
+	assert.Equal(t, "12345", redisDepl.Spec.Template.Labels[redisPasswordRotatedKey])
+	assert.Equal(t, redisPasswordRotationRestartingRedis, a.Status.RedisPasswordRotation)
+
+	// resuming again neither rotates the password a second time nor restarts redis again
+	generation := redisDepl.Generation
+	resourceVersion := redisDepl.ResourceVersion
+	assert.NoError(t, r.reconcileRedisPasswordRotation(a))
+	assert.NoError(t, argoutil.FetchObject(r.Client, a.Namespace, secret.Name, secret))
+	assert.Equal(t, rotatedPassword, string(secret.Data[common.ArgoCDKeyAdminPassword]))
+	assert.NoError(t, argoutil.FetchObject(r.Client, a.Namespace, redisDepl.Name, redisDepl))
+	assert.Equal(t, generation, redisDepl.Generation)
+	assert.Equal(t, resourceVersion, redisDepl.ResourceVersion)
+}
+
+func Test_ReconcileArgoCD_ReconcileRedisPasswordRotation_interval(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.Redis.PasswordRotation = &argoproj.ArgoCDRedisPasswordRotationSpec{
+			Enabled:  true,
+			Interval: &metav1.Duration{Duration: time.Hour},
+		}
+	})
+	redisDepl := newDeploymentWithSuffix("redis", "redis", a)
+	secret := argoutil.NewSecretWithSuffix(a, "redis-initial-password")
+	secret.Data = map[string][]byte{
+		common.ArgoCDKeyAdminPassword:      []byte("password"),
+		common.ArgoCDKeyAdminPasswordMTime: []byte(time.Now().UTC().Add(-30 * time.Minute).Format(time.RFC3339)),
+	}
+
+	resObjs := []client.Object{a, redisDepl, secret}
+	subresObjs := []client.Object{a, redisDepl}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	// the password is not rotated before the interval has elapsed
+	requeueAfter := r.getRedisPasswordRotationRequeueAfter(a)
+	assert.True(t, requeueAfter > 29*time.Minute && requeueAfter <= 30*time.Minute, "unexpected requeue after %s", requeueAfter)
+	assert.NoError(t, r.reconcileRedisPasswordRotation(a))
+	assert.NoError(t, argoutil.FetchObject(r.Client, a.Namespace, secret.Name, secret))
+	assert.Equal(t, "password", string(secret.Data[common.ArgoCDKeyAdminPassword]))
+
+	// the password is rotated once the interval has elapsed
+	secret.Data[common.ArgoCDKeyAdminPasswordMTime] = []byte(time.Now().UTC().Add(-2 * time.Hour).Format(time.RFC3339))
+	assert.NoError(t, r.Client.Update(context.TODO(), secret))
+	assert.NoError(t, r.reconcileRedisPasswordRotation(a))
+	assert.NoError(t, argoutil.FetchObject(r.Client, a.Namespace, secret.Name, secret))
+	assert.NotEqual(t, "password", string(secret.Data[common.ArgoCDKeyAdminPassword]))
+	assert.Equal(t, redisPasswordRotationRestartingRedis, a.Status.RedisPasswordRotation)
+	assert.True(t, r.getRedisPasswordRotationRequeueAfter(a) > 59*time.Minute)
+}
+
 func Test_ReconcileArgoCD_ClusterPermissionsSecret(t *testing.T) {
 	logf.SetLogger(ZapLogger(true))
 	a := makeTestArgoCD()
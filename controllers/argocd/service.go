@@ -89,10 +89,6 @@ func (r *ReconcileArgoCD) reconcileGrafanaService(cr *argoproj.ArgoCD) error {
 // reconcileMetricsService will ensure that the Service for the Argo CD application controller metrics is present.
 func (r *ReconcileArgoCD) reconcileMetricsService(cr *argoproj.ArgoCD) error {
 	svc := newServiceWithSuffix("metrics", "metrics", cr)
-	if argoutil.IsObjectFound(r.Client, cr.Namespace, svc.Name, svc) {
-		// Service found, do nothing
-		return nil
-	}
 
 	svc.Spec.Selector = map[string]string{
 		common.ArgoCDKeyName: nameWithSuffix("application-controller", cr),
@@ -110,22 +106,19 @@ func (r *ReconcileArgoCD) reconcileMetricsService(cr *argoproj.ArgoCD) error {
 	if err := controllerutil.SetControllerReference(cr, svc, r.Scheme); err != nil {
 		return err
 	}
-	return r.Client.Create(context.TODO(), svc)
+	return argoutil.ApplyResource(r.Client, svc)
 }
 
 // reconcileRedisHAAnnounceServices will ensure that the announce Services are present for Redis when running in HA mode.
 func (r *ReconcileArgoCD) reconcileRedisHAAnnounceServices(cr *argoproj.ArgoCD) error {
-	for i := int32(0); i < common.ArgoCDDefaultRedisHAReplicas; i++ {
+	replicas := *getRedisHAReplicas(cr)
+	for i := int32(0); i < replicas; i++ {
 		svc := newServiceWithSuffix(fmt.Sprintf("redis-ha-announce-%d", i), "redis", cr)
-		if argoutil.IsObjectFound(r.Client, cr.Namespace, svc.Name, svc) {
-			if !cr.Spec.HA.Enabled || !cr.Spec.Redis.IsEnabled() {
-				return r.Client.Delete(context.TODO(), svc)
-			}
-			return nil // Service found, do nothing
-		}
-
 		if !cr.Spec.HA.Enabled || !cr.Spec.Redis.IsEnabled() {
-			return nil //return as Ha is not enabled do nothing
+			if err := r.deleteServiceIfFound(svc); err != nil {
+				return err
+			}
+			continue
 		}
 
 		svc.ObjectMeta.Annotations = map[string]string{
@@ -157,7 +150,18 @@ func (r *ReconcileArgoCD) reconcileRedisHAAnnounceServices(cr *argoproj.ArgoCD)
 			return err
 		}
 
-		if err := r.Client.Create(context.TODO(), svc); err != nil {
+		if err := argoutil.ApplyResource(r.Client, svc); err != nil {
+			return err
+		}
+	}
+
+	// Remove the announce Services left behind after the number of replicas has been reduced.
+	for i := replicas; ; i++ {
+		svc := newServiceWithSuffix(fmt.Sprintf("redis-ha-announce-%d", i), "redis", cr)
+		if !argoutil.IsObjectFound(r.Client, cr.Namespace, svc.Name, svc) {
+			break
+		}
+		if err := r.Client.Delete(context.TODO(), svc); err != nil {
 			return err
 		}
 	}
@@ -167,15 +171,8 @@ func (r *ReconcileArgoCD) reconcileRedisHAAnnounceServices(cr *argoproj.ArgoCD)
 // reconcileRedisHAMasterService will ensure that the "master" Service is present for Redis when running in HA mode.
 func (r *ReconcileArgoCD) reconcileRedisHAMasterService(cr *argoproj.ArgoCD) error {
 	svc := newServiceWithSuffix("redis-ha", "redis", cr)
-	if argoutil.IsObjectFound(r.Client, cr.Namespace, svc.Name, svc) {
-		if !cr.Spec.HA.Enabled || !cr.Spec.Redis.IsEnabled() {
-			return r.Client.Delete(context.TODO(), svc)
-		}
-		return nil // Service found, do nothing
-	}
-
 	if !cr.Spec.HA.Enabled || !cr.Spec.Redis.IsEnabled() {
-		return nil //return as Ha is not enabled do nothing
+		return r.deleteServiceIfFound(svc)
 	}
 
 	svc.Spec.Selector = map[string]string{
@@ -199,29 +196,18 @@ func (r *ReconcileArgoCD) reconcileRedisHAMasterService(cr *argoproj.ArgoCD) err
 	if err := controllerutil.SetControllerReference(cr, svc, r.Scheme); err != nil {
 		return err
 	}
-	return r.Client.Create(context.TODO(), svc)
+	return argoutil.ApplyResource(r.Client, svc)
 }
 
 // reconcileRedisHAProxyService will ensure that the HA Proxy Service is present for Redis when running in HA mode.
 func (r *ReconcileArgoCD) reconcileRedisHAProxyService(cr *argoproj.ArgoCD) error {
 	svc := newServiceWithSuffix("redis-ha-haproxy", "redis", cr)
-	if argoutil.IsObjectFound(r.Client, cr.Namespace, svc.Name, svc) {
-
-		if !cr.Spec.HA.Enabled || !cr.Spec.Redis.IsEnabled() {
-			return r.Client.Delete(context.TODO(), svc)
-		}
-
-		if ensureAutoTLSAnnotation(r.Client, svc, common.ArgoCDRedisServerTLSSecretName, cr.Spec.Redis.WantsAutoTLS()) {
-			return r.Client.Update(context.TODO(), svc)
-		}
-		return nil // Service found, do nothing
-	}
-
 	if !cr.Spec.HA.Enabled || !cr.Spec.Redis.IsEnabled() {
-		return nil //return as Ha is not enabled do nothing
+		return r.deleteServiceIfFound(svc)
 	}
 
-	ensureAutoTLSAnnotation(r.Client, svc, common.ArgoCDRedisServerTLSSecretName, cr.Spec.Redis.WantsAutoTLS())
+	r.copyAutoTLSAnnotation(svc)
+	ensureAutoTLSAnnotation(r.Client, r.ClusterAPIs, svc, common.ArgoCDRedisServerTLSSecretName, cr.Spec.Redis.WantsAutoTLS())
 
 	svc.Spec.Selector = map[string]string{
 		common.ArgoCDKeyName: nameWithSuffix("redis-ha-haproxy", cr),
@@ -239,7 +225,7 @@ func (r *ReconcileArgoCD) reconcileRedisHAProxyService(cr *argoproj.ArgoCD) erro
 	if err := controllerutil.SetControllerReference(cr, svc, r.Scheme); err != nil {
 		return err
 	}
-	return r.Client.Create(context.TODO(), svc)
+	return argoutil.ApplyResource(r.Client, svc)
 }
 
 // reconcileRedisHAServices will ensure that all required Services are present for Redis when running in HA mode.
@@ -262,25 +248,12 @@ func (r *ReconcileArgoCD) reconcileRedisHAServices(cr *argoproj.ArgoCD) error {
 // reconcileRedisService will ensure that the Service for Redis is present.
 func (r *ReconcileArgoCD) reconcileRedisService(cr *argoproj.ArgoCD) error {
 	svc := newServiceWithSuffix("redis", "redis", cr)
-
-	if argoutil.IsObjectFound(r.Client, cr.Namespace, svc.Name, svc) {
-		if !cr.Spec.Redis.IsEnabled() {
-			return r.Client.Delete(context.TODO(), svc)
-		}
-		if ensureAutoTLSAnnotation(r.Client, svc, common.ArgoCDRedisServerTLSSecretName, cr.Spec.Redis.WantsAutoTLS()) {
-			return r.Client.Update(context.TODO(), svc)
-		}
-		if cr.Spec.HA.Enabled {
-			return r.Client.Delete(context.TODO(), svc)
-		}
-		return nil // Service found, do nothing
-	}
-
 	if cr.Spec.HA.Enabled || !cr.Spec.Redis.IsEnabled() {
-		return nil //return as Ha is enabled do nothing
+		return r.deleteServiceIfFound(svc)
 	}
 
-	ensureAutoTLSAnnotation(r.Client, svc, common.ArgoCDRedisServerTLSSecretName, cr.Spec.Redis.WantsAutoTLS())
+	r.copyAutoTLSAnnotation(svc)
+	ensureAutoTLSAnnotation(r.Client, r.ClusterAPIs, svc, common.ArgoCDRedisServerTLSSecretName, cr.Spec.Redis.WantsAutoTLS())
 
 	svc.Spec.Selector = map[string]string{
 		common.ArgoCDKeyName: nameWithSuffix("redis", cr),
@@ -298,7 +271,30 @@ func (r *ReconcileArgoCD) reconcileRedisService(cr *argoproj.ArgoCD) error {
 	if err := controllerutil.SetControllerReference(cr, svc, r.Scheme); err != nil {
 		return err
 	}
-	return r.Client.Create(context.TODO(), svc)
+	return argoutil.ApplyResource(r.Client, svc)
+}
+
+// deleteServiceIfFound will delete the given Service if it exists.
+func (r *ReconcileArgoCD) deleteServiceIfFound(svc *corev1.Service) error {
+	if !argoutil.IsObjectFound(r.Client, svc.Namespace, svc.Name, svc) {
+		return nil
+	}
+	return r.Client.Delete(context.TODO(), svc)
+}
+
+// copyAutoTLSAnnotation will copy the auto TLS annotation of the existing Service, if any, on the given Service, so
+// that ensureAutoTLSAnnotation keeps requesting the TLS certificate requested before.
+func (r *ReconcileArgoCD) copyAutoTLSAnnotation(svc *corev1.Service) {
+	existing := &corev1.Service{}
+	if !argoutil.IsObjectFound(r.Client, svc.Namespace, svc.Name, existing) {
+		return
+	}
+	if val, ok := existing.Annotations[common.AnnotationOpenShiftServiceCA]; ok {
+		if svc.Annotations == nil {
+			svc.Annotations = map[string]string{}
+		}
+		svc.Annotations[common.AnnotationOpenShiftServiceCA] = val
+	}
 }
 
 // ensureAutoTLSAnnotation ensures that the service svc has the desired state
@@ -309,11 +305,11 @@ func (r *ReconcileArgoCD) reconcileRedisService(cr *argoproj.ArgoCD) error {
 //
 // When this method returns true, the svc resource will need to be updated on
 // the cluster.
-func ensureAutoTLSAnnotation(k8sClient client.Client, svc *corev1.Service, secretName string, enabled bool) bool {
+func ensureAutoTLSAnnotation(k8sClient client.Client, apis *ClusterAPIs, svc *corev1.Service, secretName string, enabled bool) bool {
 	var autoTLSAnnotationName, autoTLSAnnotationValue string
 
 	// We currently only support OpenShift for automatic TLS
-	if IsRouteAPIAvailable() {
+	if apis.IsRouteAPIAvailable() {
 		autoTLSAnnotationName = common.AnnotationOpenShiftServiceCA
 		if svc.Annotations == nil {
 			svc.Annotations = make(map[string]string)
@@ -350,22 +346,12 @@ func ensureAutoTLSAnnotation(k8sClient client.Client, svc *corev1.Service, secre
 // reconcileRepoService will ensure that the Service for the Argo CD repo server is present.
 func (r *ReconcileArgoCD) reconcileRepoService(cr *argoproj.ArgoCD) error {
 	svc := newServiceWithSuffix("repo-server", "repo-server", cr)
-
-	if argoutil.IsObjectFound(r.Client, cr.Namespace, svc.Name, svc) {
-		if !cr.Spec.Repo.IsEnabled() {
-			return r.Client.Delete(context.TODO(), svc)
-		}
-		if ensureAutoTLSAnnotation(r.Client, svc, common.ArgoCDRepoServerTLSSecretName, cr.Spec.Repo.WantsAutoTLS()) {
-			return r.Client.Update(context.TODO(), svc)
-		}
-		return nil // Service found, do nothing
-	}
-
 	if !cr.Spec.Repo.IsEnabled() {
-		return nil
+		return r.deleteServiceIfFound(svc)
 	}
 
-	ensureAutoTLSAnnotation(r.Client, svc, common.ArgoCDRepoServerTLSSecretName, cr.Spec.Repo.WantsAutoTLS())
+	r.copyAutoTLSAnnotation(svc)
+	ensureAutoTLSAnnotation(r.Client, r.ClusterAPIs, svc, common.ArgoCDRepoServerTLSSecretName, cr.Spec.Repo.WantsAutoTLS())
 
 	svc.Spec.Selector = map[string]string{
 		common.ArgoCDKeyName: nameWithSuffix("repo-server", cr),
@@ -388,15 +374,12 @@ func (r *ReconcileArgoCD) reconcileRepoService(cr *argoproj.ArgoCD) error {
 	if err := controllerutil.SetControllerReference(cr, svc, r.Scheme); err != nil {
 		return err
 	}
-	return r.Client.Create(context.TODO(), svc)
+	return argoutil.ApplyResource(r.Client, svc)
 }
 
 // reconcileServerMetricsService will ensure that the Service for the Argo CD server metrics is present.
 func (r *ReconcileArgoCD) reconcileServerMetricsService(cr *argoproj.ArgoCD) error {
 	svc := newServiceWithSuffix("server-metrics", "server", cr)
-	if argoutil.IsObjectFound(r.Client, cr.Namespace, svc.Name, svc) {
-		return nil // Service found, do nothing
-	}
 
 	svc.Spec.Selector = map[string]string{
 		common.ArgoCDKeyName: nameWithSuffix("server", cr),
@@ -414,27 +397,18 @@ func (r *ReconcileArgoCD) reconcileServerMetricsService(cr *argoproj.ArgoCD) err
 	if err := controllerutil.SetControllerReference(cr, svc, r.Scheme); err != nil {
 		return err
 	}
-	return r.Client.Create(context.TODO(), svc)
+	return argoutil.ApplyResource(r.Client, svc)
 }
 
 // reconcileServerService will ensure that the Service is present for the Argo CD server component.
 func (r *ReconcileArgoCD) reconcileServerService(cr *argoproj.ArgoCD) error {
 	svc := newServiceWithSuffix("server", "server", cr)
-	if argoutil.IsObjectFound(r.Client, cr.Namespace, svc.Name, svc) {
-		if !cr.Spec.Server.IsEnabled() {
-			return r.Client.Delete(context.TODO(), svc)
-		}
-		if ensureAutoTLSAnnotation(r.Client, svc, common.ArgoCDServerTLSSecretName, cr.Spec.Server.WantsAutoTLS()) {
-			return r.Client.Update(context.TODO(), svc)
-		}
-		return nil // Service found, do nothing
+	if !cr.Spec.Server.IsEnabled() {
+		return r.deleteServiceIfFound(svc)
 	}
 
-	if !cr.Spec.Repo.IsEnabled() {
-		return nil
-	}
-
-	ensureAutoTLSAnnotation(r.Client, svc, common.ArgoCDServerTLSSecretName, cr.Spec.Server.WantsAutoTLS())
+	r.copyAutoTLSAnnotation(svc)
+	ensureAutoTLSAnnotation(r.Client, r.ClusterAPIs, svc, common.ArgoCDServerTLSSecretName, cr.Spec.Server.WantsAutoTLS())
 
 	svc.Spec.Ports = []corev1.ServicePort{
 		{
@@ -454,12 +428,50 @@ func (r *ReconcileArgoCD) reconcileServerService(cr *argoproj.ArgoCD) error {
 		common.ArgoCDKeyName: nameWithSuffix("server", cr),
 	}
 
-	svc.Spec.Type = getArgoServerServiceType(cr)
-
 	if err := controllerutil.SetControllerReference(cr, svc, r.Scheme); err != nil {
 		return err
 	}
-	return r.Client.Create(context.TODO(), svc)
+	return r.applyCustomizedService(svc, getArgoServerServiceType(cr), cr.Spec.Server.Service.ArgoCDServiceSpec)
+}
+
+// applyCustomizedService will apply the given Service with the given type and customizations. The load balancer class
+// of a LoadBalancer Service is immutable, when it changes the Service is deleted to be recreated by the next
+// reconciliation.
+func (r *ReconcileArgoCD) applyCustomizedService(svc *corev1.Service, svcType corev1.ServiceType, spec argoproj.ArgoCDServiceSpec) error {
+	customizeService(svc, svcType, spec)
+
+	existing := &corev1.Service{}
+	if argoutil.IsObjectFound(r.Client, svc.Namespace, svc.Name, existing) &&
+		existing.Spec.Type == corev1.ServiceTypeLoadBalancer && svc.Spec.LoadBalancerClass != nil &&
+		(existing.Spec.LoadBalancerClass == nil || *existing.Spec.LoadBalancerClass != *svc.Spec.LoadBalancerClass) {
+		log.Info(fmt.Sprintf("recreating service %s to change its load balancer class", svc.Name))
+		return r.Client.Delete(context.TODO(), existing)
+	}
+	return argoutil.ApplyResource(r.Client, svc)
+}
+
+// customizeService will set the given type and customizations on the given Service. The annotations and labels of
+// the customization are added to the ones of the Service, and the fields only valid for the Services reachable from
+// outside the cluster are only set on these.
+func customizeService(svc *corev1.Service, svcType corev1.ServiceType, spec argoproj.ArgoCDServiceSpec) {
+	if len(spec.Annotations) > 0 {
+		svc.Annotations = argoutil.AppendStringMap(svc.Annotations, spec.Annotations)
+	}
+	if len(spec.Labels) > 0 {
+		svc.Labels = argoutil.AppendStringMap(svc.Labels, spec.Labels)
+	}
+
+	svc.Spec.Type = svcType
+	if svcType == corev1.ServiceTypeNodePort || svcType == corev1.ServiceTypeLoadBalancer {
+		svc.Spec.ExternalTrafficPolicy = spec.ExternalTrafficPolicy
+		for i := range svc.Spec.Ports {
+			svc.Spec.Ports[i].NodePort = spec.NodePorts[svc.Spec.Ports[i].Name]
+		}
+	}
+	if svcType == corev1.ServiceTypeLoadBalancer {
+		svc.Spec.LoadBalancerSourceRanges = spec.LoadBalancerSourceRanges
+		svc.Spec.LoadBalancerClass = spec.LoadBalancerClass
+	}
 }
 
 // reconcileServices will ensure that all Services are present for the given ArgoCD.
@@ -62,7 +62,7 @@ func TestReconcileArgoCD_reconcileServiceAccountPermissions(t *testing.T) {
 	assert.Equal(t, expectedRules, reconciledRole.Rules)
 
 	// undesirable changes
-	reconciledRole.Rules = policyRuleForRedisHa(r.Client)
+	reconciledRole.Rules = policyRuleForRedisHa(r.Client, r.ClusterAPIs)
 	assert.NoError(t, r.Client.Update(context.TODO(), reconciledRole))
 
 	// fetch it
@@ -2,14 +2,18 @@ package argocd
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
+
 	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
 	"github.com/argoproj-labs/argocd-operator/common"
 )
@@ -21,47 +25,45 @@ func TestEnsureAutoTLSAnnotation(t *testing.T) {
 	runtimeObjs := []runtime.Object{}
 	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
 	fakeClient := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	openShiftAPIs := &ClusterAPIs{}
+	openShiftAPIs.setRouteAPIAvailable(true)
 	t.Run("Ensure annotation will be set for OpenShift", func(t *testing.T) {
-		routeAPIFound = true
 		svc := newService(a)
 
 		// Annotation is inserted, update is required
-		needUpdate := ensureAutoTLSAnnotation(fakeClient, svc, "some-secret", true)
+		needUpdate := ensureAutoTLSAnnotation(fakeClient, openShiftAPIs, svc, "some-secret", true)
 		assert.Equal(t, needUpdate, true)
 		atls, ok := svc.Annotations[common.AnnotationOpenShiftServiceCA]
 		assert.Equal(t, ok, true)
 		assert.Equal(t, atls, "some-secret")
 
 		// Annotation already set, doesn't need update
-		needUpdate = ensureAutoTLSAnnotation(fakeClient, svc, "some-secret", true)
+		needUpdate = ensureAutoTLSAnnotation(fakeClient, openShiftAPIs, svc, "some-secret", true)
 		assert.Equal(t, needUpdate, false)
 	})
 	t.Run("Ensure annotation will be unset for OpenShift", func(t *testing.T) {
-		routeAPIFound = true
 		svc := newService(a)
 		svc.Annotations = make(map[string]string)
 		svc.Annotations[common.AnnotationOpenShiftServiceCA] = "some-secret"
 
 		// Annotation getting removed, update required
-		needUpdate := ensureAutoTLSAnnotation(fakeClient, svc, "some-secret", false)
+		needUpdate := ensureAutoTLSAnnotation(fakeClient, openShiftAPIs, svc, "some-secret", false)
 		assert.Equal(t, needUpdate, true)
 		_, ok := svc.Annotations[common.AnnotationOpenShiftServiceCA]
 		assert.Equal(t, ok, false)
 
 		// Annotation does not exist, no update required
-		needUpdate = ensureAutoTLSAnnotation(fakeClient, svc, "some-secret", false)
+		needUpdate = ensureAutoTLSAnnotation(fakeClient, openShiftAPIs, svc, "some-secret", false)
 		assert.Equal(t, needUpdate, false)
 	})
 	t.Run("Ensure annotation will not be set for non-OpenShift", func(t *testing.T) {
-		routeAPIFound = false
 		svc := newService(a)
-		needUpdate := ensureAutoTLSAnnotation(fakeClient, svc, "some-secret", true)
+		needUpdate := ensureAutoTLSAnnotation(fakeClient, &ClusterAPIs{}, svc, "some-secret", true)
 		assert.Equal(t, needUpdate, false)
 		_, ok := svc.Annotations[common.AnnotationOpenShiftServiceCA]
 		assert.Equal(t, ok, false)
 	})
 	t.Run("Ensure annotation will not be set if the TLS secret is already present", func(t *testing.T) {
-		routeAPIFound = true
 		svc := newService(a)
 		secret := &corev1.Secret{
 			ObjectMeta: metav1.ObjectMeta{
@@ -71,13 +73,128 @@ func TestEnsureAutoTLSAnnotation(t *testing.T) {
 		}
 		err := fakeClient.Create(context.Background(), secret)
 		assert.NoError(t, err)
-		needUpdate := ensureAutoTLSAnnotation(fakeClient, svc, secret.Name, true)
+		needUpdate := ensureAutoTLSAnnotation(fakeClient, openShiftAPIs, svc, secret.Name, true)
 		assert.Equal(t, needUpdate, false)
 		_, ok := svc.Annotations[common.AnnotationOpenShiftServiceCA]
 		assert.Equal(t, ok, false)
 
 		// Annotation does not exist, no update required
-		needUpdate = ensureAutoTLSAnnotation(fakeClient, svc, "some-secret", false)
+		needUpdate = ensureAutoTLSAnnotation(fakeClient, openShiftAPIs, svc, "some-secret", false)
 		assert.Equal(t, needUpdate, false)
 	})
 }
+
+func TestReconcileArgoCD_reconcileRedisHAAnnounceServices(t *testing.T) {
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.HA.Enabled = true
+		a.Spec.HA.RedisReplicas = int32Ptr(5)
+	})
+	resObjs := []client.Object{a}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileRedisHAAnnounceServices(a))
+	for i := 0; i < 5; i++ {
+		svc := newServiceWithSuffix(fmt.Sprintf("redis-ha-announce-%d", i), "redis", a)
+		assert.True(t, argoutil.IsObjectFound(r.Client, a.Namespace, svc.Name, svc))
+	}
+
+	// test surplus announce Services are removed when the replicas are reduced
+	a.Spec.HA.RedisReplicas = int32Ptr(3)
+	assert.NoError(t, r.reconcileRedisHAAnnounceServices(a))
+	for i := 0; i < 5; i++ {
+		svc := newServiceWithSuffix(fmt.Sprintf("redis-ha-announce-%d", i), "redis", a)
+		assert.Equal(t, i < 3, argoutil.IsObjectFound(r.Client, a.Namespace, svc.Name, svc))
+	}
+}
+
+func TestReconcileArgoCD_reconcileServerService_customization(t *testing.T) {
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.Server.Service = argoproj.ArgoCDServerServiceSpec{
+			Type: corev1.ServiceTypeLoadBalancer,
+			ArgoCDServiceSpec: argoproj.ArgoCDServiceSpec{
+				Annotations:              map[string]string{"service.beta.kubernetes.io/aws-load-balancer-internal": "true"},
+				Labels:                   map[string]string{"exposed": "true"},
+				ExternalTrafficPolicy:    corev1.ServiceExternalTrafficPolicyLocal,
+				LoadBalancerSourceRanges: []string{"10.0.0.0/8"},
+				NodePorts:                map[string]int32{"https": 30443},
+			},
+		}
+	})
+	resObjs := []client.Object{a}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileServerService(a))
+
+	svc := &corev1.Service{}
+	key := types.NamespacedName{Name: "argocd-server", Namespace: a.Namespace}
+	assert.NoError(t, r.Client.Get(context.TODO(), key, svc))
+	assert.Equal(t, corev1.ServiceTypeLoadBalancer, svc.Spec.Type)
+	assert.Equal(t, "true", svc.Annotations["service.beta.kubernetes.io/aws-load-balancer-internal"])
+	assert.Equal(t, "true", svc.Labels["exposed"])
+	assert.Equal(t, corev1.ServiceExternalTrafficPolicyLocal, svc.Spec.ExternalTrafficPolicy)
+	assert.Equal(t, []string{"10.0.0.0/8"}, svc.Spec.LoadBalancerSourceRanges)
+	assert.Equal(t, int32(0), svc.Spec.Ports[0].NodePort)
+	assert.Equal(t, int32(30443), svc.Spec.Ports[1].NodePort)
+
+	// Fields set by others are left untouched
+	svc.Annotations["cloud.example.com/load-balancer-id"] = "lb-1234"
+	svc.Spec.Ports[0].NodePort = 31080
+	assert.NoError(t, r.Client.Update(context.TODO(), svc))
+
+	// Drift on customized fields is corrected and removed customizations are removed
+	a.Spec.Server.Service.Annotations = nil
+	a.Spec.Server.Service.LoadBalancerSourceRanges = []string{"192.168.0.0/16"}
+	assert.NoError(t, r.reconcileServerService(a))
+	assert.NoError(t, r.Client.Get(context.TODO(), key, svc))
+	assert.NotContains(t, svc.Annotations, "service.beta.kubernetes.io/aws-load-balancer-internal")
+	assert.Equal(t, "lb-1234", svc.Annotations["cloud.example.com/load-balancer-id"])
+	assert.Equal(t, int32(31080), svc.Spec.Ports[0].NodePort)
+	assert.Equal(t, []string{"192.168.0.0/16"}, svc.Spec.LoadBalancerSourceRanges)
+	assert.Equal(t, "true", svc.Labels["exposed"])
+
+	// Switching back to ClusterIP clears the fields only valid for external services
+	a.Spec.Server.Service = argoproj.ArgoCDServerServiceSpec{Type: corev1.ServiceTypeClusterIP}
+	assert.NoError(t, r.reconcileServerService(a))
+	assert.NoError(t, r.Client.Get(context.TODO(), key, svc))
+	assert.Equal(t, corev1.ServiceTypeClusterIP, svc.Spec.Type)
+	assert.Empty(t, svc.Spec.ExternalTrafficPolicy)
+	assert.Empty(t, svc.Spec.LoadBalancerSourceRanges)
+	// the node port allocated to the first port is not applied by the operator, the API server drops it on type change
+	assert.Equal(t, int32(0), svc.Spec.Ports[1].NodePort)
+	assert.NotContains(t, svc.Labels, "exposed")
+	assert.Equal(t, "lb-1234", svc.Annotations["cloud.example.com/load-balancer-id"])
+}
+
+func TestReconcileArgoCD_reconcileServerService_loadBalancerClass(t *testing.T) {
+	class := "example.com/internal"
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.Server.Service.Type = corev1.ServiceTypeLoadBalancer
+	})
+	resObjs := []client.Object{a}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileServerService(a))
+
+	// The load balancer class is immutable, the service is recreated
+	a.Spec.Server.Service.LoadBalancerClass = &class
+	assert.NoError(t, r.reconcileServerService(a))
+	svc := &corev1.Service{}
+	key := types.NamespacedName{Name: "argocd-server", Namespace: a.Namespace}
+	assert.Error(t, r.Client.Get(context.TODO(), key, svc))
+
+	assert.NoError(t, r.reconcileServerService(a))
+	assert.NoError(t, r.Client.Get(context.TODO(), key, svc))
+	assert.Equal(t, class, *svc.Spec.LoadBalancerClass)
+}
@@ -33,30 +33,42 @@ const (
 	illegalSSOConfiguration string = "illegal SSO configuration: "
 )
 
-var (
-	templateAPIFound         = false
-	deploymentConfigAPIFound = false
-	ssoConfigLegalStatus     string
-)
-
 // CanUseKeycloakWithTemplate checks if the required APIs are available to
 // manage a Keycloak instance using Templates.
-func CanUseKeycloakWithTemplate() bool {
-	return templateAPIFound && deploymentConfigAPIFound
+func (a *ClusterAPIs) CanUseKeycloakWithTemplate() bool {
+	return a.isAvailable(func(a *ClusterAPIs) bool { return a.template && a.deploymentConfig })
+}
+
+// isTemplateAPIAvailable returns true if the Template API is present.
+func (a *ClusterAPIs) isTemplateAPIAvailable() bool {
+	return a.isAvailable(func(a *ClusterAPIs) bool { return a.template })
+}
+
+// isDeploymentConfigAPIAvailable returns true if the DeploymentConfig API is present.
+func (a *ClusterAPIs) isDeploymentConfigAPIAvailable() bool {
+	return a.isAvailable(func(a *ClusterAPIs) bool { return a.deploymentConfig })
+}
+
+// setKeycloakTemplateAPIsAvailable records whether the Template and DeploymentConfig APIs are present.
+func (a *ClusterAPIs) setKeycloakTemplateAPIsAvailable(templateFound, deploymentConfigFound bool) {
+	a.setAvailable(func(a *ClusterAPIs) {
+		a.template = templateFound
+		a.deploymentConfig = deploymentConfigFound
+	})
 }
 
-func verifyKeycloakTemplateAPIs() error {
-	var err error
-	deploymentConfigAPIFound, err = argoutil.VerifyAPI(deploymentConfig.GroupVersion.Group, deploymentConfig.GroupVersion.Version)
+func (a *ClusterAPIs) verifyKeycloakTemplateAPIs() error {
+	deploymentConfigFound, err := argoutil.VerifyAPI(deploymentConfig.GroupVersion.Group, deploymentConfig.GroupVersion.Version)
 	if err != nil {
 		return err
 	}
 
-	templateAPIFound, err = argoutil.VerifyAPI(template.GroupVersion.Group, template.GroupVersion.Version)
+	templateFound, err := argoutil.VerifyAPI(template.GroupVersion.Group, template.GroupVersion.Version)
 	if err != nil {
 		return err
 	}
 
+	a.setKeycloakTemplateAPIsAvailable(templateFound, deploymentConfigFound)
 	return nil
 }
 
@@ -67,8 +79,8 @@ func verifyKeycloakTemplateAPIs() error {
 // active provider, contradicting configuration etc, and throw the appropriate errors.
 func (r *ReconcileArgoCD) reconcileSSO(cr *argoproj.ArgoCD) error {
 
-	// reset ssoConfigLegalStatus at the beginning of each SSO reconciliation round
-	ssoConfigLegalStatus = ssoLegalUnknown
+	// reset r.ssoConfigLegalStatus at the beginning of each SSO reconciliation round
+	r.ssoConfigLegalStatus = ssoLegalUnknown
 
 	// case 1
 	if cr.Spec.SSO == nil {
@@ -100,7 +112,7 @@ func (r *ReconcileArgoCD) reconcileSSO(cr *argoproj.ArgoCD) error {
 			if isError {
 				err = errors.New(illegalSSOConfiguration + errMsg)
 				log.Error(err, fmt.Sprintf("Illegal expression of SSO configuration detected for Argo CD %s in namespace %s. %s", cr.Name, cr.Namespace, errMsg))
-				ssoConfigLegalStatus = ssoLegalFailed // set global indicator that SSO config has gone wrong
+				r.ssoConfigLegalStatus = ssoLegalFailed // set indicator that SSO config has gone wrong
 				_ = r.reconcileStatusSSO(cr)
 				return err
 			}
@@ -119,15 +131,15 @@ func (r *ReconcileArgoCD) reconcileSSO(cr *argoproj.ArgoCD) error {
 
 			if isError {
 				log.Error(err, fmt.Sprintf("Illegal expression of SSO configuration detected for Argo CD %s in namespace %s. %s", cr.Name, cr.Namespace, errMsg))
-				ssoConfigLegalStatus = ssoLegalFailed // set global indicator that SSO config has gone wrong
+				r.ssoConfigLegalStatus = ssoLegalFailed // set indicator that SSO config has gone wrong
 				_ = r.reconcileStatusSSO(cr)
 				return err
 			}
 
 			// DeploymentConfig API is being deprecated with OpenShift 4.14. Users who wish to
 			// install Keycloak using Template should enable the DeploymentConfig API.
-			if templateAPIFound && !deploymentConfigAPIFound {
-				ssoConfigLegalStatus = ssoLegalFailed
+			if r.ClusterAPIs.isTemplateAPIAvailable() && !r.ClusterAPIs.isDeploymentConfigAPIAvailable() {
+				r.ssoConfigLegalStatus = ssoLegalFailed
 				if err := r.reconcileStatusSSO(cr); err != nil {
 					return err
 				}
@@ -146,7 +158,7 @@ func (r *ReconcileArgoCD) reconcileSSO(cr *argoproj.ArgoCD) error {
 				errMsg = "Cannot specify SSO provider spec without specifying SSO provider type"
 				err = errors.New(illegalSSOConfiguration + errMsg)
 				log.Error(err, fmt.Sprintf("Cannot specify SSO provider spec without specifying SSO provider type for Argo CD %s in namespace %s.", cr.Name, cr.Namespace))
-				ssoConfigLegalStatus = ssoLegalFailed // set global indicator that SSO config has gone wrong
+				r.ssoConfigLegalStatus = ssoLegalFailed // set indicator that SSO config has gone wrong
 				_ = r.reconcileStatusSSO(cr)
 				return err
 			}
@@ -159,7 +171,7 @@ func (r *ReconcileArgoCD) reconcileSSO(cr *argoproj.ArgoCD) error {
 			errMsg = fmt.Sprintf("Unsupported SSO provider type. Supported providers are %s and %s", argoproj.SSOProviderTypeDex, argoproj.SSOProviderTypeKeycloak)
 			err = errors.New(illegalSSOConfiguration + errMsg)
 			log.Error(err, fmt.Sprintf("Unsupported SSO provider type for Argo CD %s in namespace %s.", cr.Name, cr.Namespace))
-			ssoConfigLegalStatus = ssoLegalFailed // set global indicator that SSO config has gone wrong
+			r.ssoConfigLegalStatus = ssoLegalFailed // set indicator that SSO config has gone wrong
 			_ = r.reconcileStatusSSO(cr)
 			return err
 		}
@@ -167,7 +179,7 @@ func (r *ReconcileArgoCD) reconcileSSO(cr *argoproj.ArgoCD) error {
 
 	// control reaching this point means that none of the illegal config combinations were detected. SSO is configured legally
 	// set global indicator that SSO config has been successful
-	ssoConfigLegalStatus = ssoLegalSuccess
+	r.ssoConfigLegalStatus = ssoLegalSuccess
 
 	// reconcile resources based on enabled provider
 	// keycloak
@@ -185,7 +197,11 @@ func (r *ReconcileArgoCD) reconcileSSO(cr *argoproj.ArgoCD) error {
 	} else if UseDex(cr) {
 		// dex
 		// Delete any lingering keycloak artifacts before Dex is configured as this is not handled by the reconcilliation loop
-		if err := deleteKeycloakConfiguration(cr); err != nil && !apiErrors.IsNotFound(err) {
+		// The keycloak artifacts are deleted with their own clients, they are left untouched when observing only or
+		// rendering offline
+		if r.observeOnly() || r.offline {
+			log.Info("skipping the deletion of the keycloak configuration")
+		} else if err := deleteKeycloakConfiguration(cr, r.ClusterAPIs); err != nil && !apiErrors.IsNotFound(err) {
 			log.Error(err, "Unable to delete existing SSO configuration before configuring Dex")
 			return err
 		}
@@ -205,7 +221,9 @@ func (r *ReconcileArgoCD) deleteSSOConfiguration(newCr *argoproj.ArgoCD, oldCr *
 	log.Info("uninstalling existing SSO configuration")
 
 	if oldCr.Spec.SSO.Provider.ToLower() == argoproj.SSOProviderTypeKeycloak {
-		if err := deleteKeycloakConfiguration(newCr); err != nil {
+		if r.observeOnly() || r.offline {
+			log.Info("skipping the deletion of the keycloak configuration")
+		} else if err := deleteKeycloakConfiguration(newCr, r.ClusterAPIs); err != nil {
 			log.Error(err, "Unable to delete existing keycloak configuration")
 			return err
 		}
@@ -41,15 +41,13 @@ func TestReconcile_testKeycloakTemplateInstance(t *testing.T) {
 	logf.SetLogger(ZapLogger(true))
 	a := makeTestArgoCDForKeycloak()
 
-	templateAPIFound = true
-	deploymentConfigAPIFound = true
-
 	resObjs := []client.Object{a}
 	subresObjs := []client.Object{a}
 	runtimeObjs := []runtime.Object{}
 	sch := makeTestReconcilerScheme(argoproj.AddToScheme, templatev1.Install, oappsv1.Install, routev1.Install)
 	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
 	r := makeTestReconciler(cl, sch)
+	r.ClusterAPIs.setKeycloakTemplateAPIsAvailable(true, true)
 
 	assert.NoError(t, createNamespace(r, a.Namespace, ""))
 
@@ -225,13 +223,13 @@ func TestReconcile_illegalSSOConfiguration(t *testing.T) {
 			assert.NoError(t, createNamespace(r, test.argoCD.Namespace, ""))
 
 			err := r.reconcileSSO(test.argoCD)
-			assert.Equal(t, test.wantSSOConfigLegalStatus, ssoConfigLegalStatus)
+			assert.Equal(t, test.wantSSOConfigLegalStatus, r.ssoConfigLegalStatus)
 			if err != nil {
 				if !test.wantErr {
 					// ignore unexpected errors for legal sso configurations.
 					// keycloak reconciliation code expects a live cluster &
 					// therefore throws unexpected errors during unit testing
-					if ssoConfigLegalStatus != ssoLegalSuccess {
+					if r.ssoConfigLegalStatus != ssoLegalSuccess {
 						t.Errorf("Got unexpected error")
 					}
 				} else {
@@ -252,7 +250,6 @@ func TestReconcile_testKeycloakK8sInstance(t *testing.T) {
 	a := makeTestArgoCDForKeycloak()
 
 	// Cluster does not have a template instance
-	templateAPIFound = false
 
 	resObjs := []client.Object{a}
 	subresObjs := []client.Object{a}
@@ -271,9 +268,6 @@ func TestReconcile_KeycloakTemplateWithoutDeploymentConfig(t *testing.T) {
 	a := makeTestArgoCDForKeycloak()
 
 	// Cluster has Template API but no DeploymentConfig API
-	templateAPIFound = true
-	deploymentConfigAPIFound = false
-	defer removeTemplateAPI()
 
 	resObjs := []client.Object{a}
 	subresObjs := []client.Object{a}
@@ -281,6 +275,7 @@ func TestReconcile_KeycloakTemplateWithoutDeploymentConfig(t *testing.T) {
 	sch := makeTestReconcilerScheme(argoproj.AddToScheme, templatev1.Install, oappsv1.Install, routev1.Install)
 	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
 	r := makeTestReconciler(cl, sch)
+	r.ClusterAPIs.setKeycloakTemplateAPIsAvailable(true, false)
 
 	assert.NoError(t, createNamespace(r, a.Namespace, ""))
 
@@ -301,7 +296,6 @@ func TestReconcile_testKeycloakInstanceResources(t *testing.T) {
 	a := makeTestArgoCDForKeycloak()
 
 	// Cluster does not have a template instance
-	templateAPIFound = false
 
 	resObjs := []client.Object{a}
 	subresObjs := []client.Object{a}
@@ -338,7 +332,7 @@ func TestReconcile_testKeycloakInstanceResources(t *testing.T) {
 	assert.Equal(t, deployment.Spec.Template.Spec.Containers[0].Name,
 		defaultKeycloakIdentifier)
 	assert.Equal(t, deployment.Spec.Template.Spec.Containers[0].Image,
-		getKeycloakContainerImage(a))
+		getKeycloakContainerImage(a, r.ClusterAPIs))
 
 	testEnv := []corev1.EnvVar{
 		{Name: "KEYCLOAK_USER", Value: defaultKeycloakAdminUser},
@@ -409,7 +403,6 @@ func TestReconcile_testKeycloakIngressHost(t *testing.T) {
 	a.Spec.SSO.Keycloak = &argoproj.ArgoCDKeycloakSpec{
 		Host: "sso.test.example.com",
 	}
-
 	resObjs := []client.Object{a}
 	subresObjs := []client.Object{a}
 	runtimeObjs := []runtime.Object{}
@@ -472,12 +465,6 @@ func TestReconcile_testKeycloakRouteHost(t *testing.T) {
 		Host: "sso.test.example.com",
 	}
 
-	// Set templateAPIFound to true, to simulate running on an OpenShift machine
-	templateAPIFound = true
-	deploymentConfigAPIFound = true
-	ssoConfigLegalStatus = ""
-	defer removeTemplateAPI()
-
 	resObjs := []client.Object{a}
 	subresObjs := []client.Object{a}
 	runtimeObjs := []runtime.Object{}
@@ -485,6 +472,9 @@ func TestReconcile_testKeycloakRouteHost(t *testing.T) {
 	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
 	r := makeTestReconciler(cl, sch)
 
+	// Simulate running on an OpenShift machine
+	r.ClusterAPIs.setKeycloakTemplateAPIsAvailable(true, true)
+
 	assert.NoError(t, createNamespace(r, a.Namespace, ""))
 
 	assert.NoError(t, r.reconcileSSO(a))
@@ -16,13 +16,15 @@ package argocd
 
 import (
 	"context"
+	"crypto/sha1"
 	"fmt"
 	"reflect"
 	"strconv"
-	"time"
+	"strings"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -33,12 +35,125 @@ import (
 	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
 )
 
-func getRedisHAReplicas() *int32 {
+// redisHASentinelIDs are the Sentinel IDs historically used for the first three Redis HA replicas, they
+// are kept as-is so that existing Sentinels do not see their peers change identity.
+var redisHASentinelIDs = []string{
+	"3c0d9c0320bb34888c2df5757c718ce6ca992ce6",
+	"40000915ab58c3fa8fd888fb8b24711944e6cbb4",
+	"2bbec7894d954a8af3bb54d13eaec53cb024e2ca",
+}
+
+// getRedisHAReplicas will return the number of Redis server replicas for the Redis HA StatefulSet if it
+// has been set in argocd CR. Otherwise, the default replica count is returned, as it is for the fewer replicas the
+// CRD and the webhook reject.
+func getRedisHAReplicas(cr *argoproj.ArgoCD) *int32 {
 	replicas := common.ArgoCDDefaultRedisHAReplicas
-	// TODO: Allow override of this value through CR?
+	if cr.Spec.HA.RedisReplicas != nil && *cr.Spec.HA.RedisReplicas >= common.ArgoCDDefaultRedisHAReplicas {
+		replicas = *cr.Spec.HA.RedisReplicas
+	}
 	return &replicas
 }
 
+// getRedisHASentinelIDEnv will return the SENTINEL_ID_<n> environment variables for every Redis HA replica.
+// Replicas beyond the well-known IDs get a stable ID derived from their pod name.
+func getRedisHASentinelIDEnv(cr *argoproj.ArgoCD) []corev1.EnvVar {
+	env := make([]corev1.EnvVar, 0)
+	for _, i := range getRedisHAReplicaIndexes(cr) {
+		id := fmt.Sprintf("%x", sha1.Sum([]byte(fmt.Sprintf("%s-%d", nameWithSuffix("redis-ha-server", cr), i))))
+		if int(i) < len(redisHASentinelIDs) {
+			id = redisHASentinelIDs[i]
+		}
+		env = append(env, corev1.EnvVar{
+			Name:  fmt.Sprintf("SENTINEL_ID_%d", i),
+			Value: id,
+		})
+	}
+	return env
+}
+
+// getRedisHAVolumeClaimTemplates will return the volume claim templates for the Redis HA StatefulSet when
+// persistence has been enabled in argocd CR. Otherwise, nil is returned.
+func getRedisHAVolumeClaimTemplates(cr *argoproj.ArgoCD) []corev1.PersistentVolumeClaim {
+	persistence := cr.Spec.HA.Persistence
+	if !persistence.IsEnabled() {
+		return nil
+	}
+
+	size := resource.MustParse(common.ArgoCDDefaultRedisHAPersistenceSize)
+	if persistence.Size != nil {
+		size = *persistence.Size
+	}
+
+	accessModes := []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+	if len(persistence.AccessModes) > 0 {
+		accessModes = persistence.AccessModes
+	}
+
+	volumeMode := corev1.PersistentVolumeFilesystem
+	return []corev1.PersistentVolumeClaim{{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "data",
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: accessModes,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: size,
+				},
+			},
+			StorageClassName: persistence.StorageClassName,
+			VolumeMode:       &volumeMode,
+		},
+	}}
+}
+
+// redisHAVolumeClaimTemplatesChanged will return true if the storage settings of the given volume claim
+// templates differ. Only the fields set by the operator are compared, as the API server defaults the rest.
+func redisHAVolumeClaimTemplatesChanged(existing, desired []corev1.PersistentVolumeClaim) bool {
+	if len(existing) != len(desired) {
+		return true
+	}
+	for i := range desired {
+		if existing[i].Name != desired[i].Name ||
+			!reflect.DeepEqual(existing[i].Spec.AccessModes, desired[i].Spec.AccessModes) ||
+			!reflect.DeepEqual(existing[i].Spec.StorageClassName, desired[i].Spec.StorageClassName) ||
+			!existing[i].Spec.Resources.Requests.Storage().Equal(*desired[i].Spec.Resources.Requests.Storage()) {
+			return true
+		}
+	}
+	return false
+}
+
+// resizeRedisHAPersistentVolumeClaims will request the storage of the given volume claim templates for the existing
+// PersistentVolumeClaims of the given Redis HA StatefulSet. Claims are only grown, as they cannot be shrunk.
+func (r *ReconcileArgoCD) resizeRedisHAPersistentVolumeClaims(cr *argoproj.ArgoCD, ss *appsv1.StatefulSet, templates []corev1.PersistentVolumeClaim) error {
+	pvcs := &corev1.PersistentVolumeClaimList{}
+	if err := r.Client.List(context.TODO(), pvcs, client.InNamespace(cr.Namespace)); err != nil {
+		return err
+	}
+
+	for _, template := range templates {
+		size := template.Spec.Resources.Requests.Storage()
+		prefix := fmt.Sprintf("%s-%s-", template.Name, ss.Name)
+		for i := range pvcs.Items {
+			pvc := &pvcs.Items[i]
+			if !strings.HasPrefix(pvc.Name, prefix) || pvc.Spec.Resources.Requests.Storage().Cmp(*size) >= 0 {
+				continue
+			}
+			log.Info(fmt.Sprintf("resizing PersistentVolumeClaim %s to %s", pvc.Name, size.String()))
+			patch := client.MergeFrom(pvc.DeepCopy())
+			if pvc.Spec.Resources.Requests == nil {
+				pvc.Spec.Resources.Requests = corev1.ResourceList{}
+			}
+			pvc.Spec.Resources.Requests[corev1.ResourceStorage] = *size
+			if err := r.Client.Patch(context.TODO(), pvc, patch); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // newStatefulSet returns a new StatefulSet instance for the given ArgoCD instance.
 func newStatefulSet(cr *argoproj.ArgoCD) *appsv1.StatefulSet {
 	return &appsv1.StatefulSet{
@@ -91,10 +206,10 @@ func newStatefulSetWithSuffix(suffix string, component string, cr *argoproj.Argo
 	return newStatefulSetWithName(fmt.Sprintf("%s-%s", cr.Name, suffix), component, cr)
 }
 
-func (r *ReconcileArgoCD) reconcileRedisStatefulSet(cr *argoproj.ArgoCD) error {
+func (r *ReconcileArgoCD) reconcileRedisStatefulSet(cr *argoproj.ArgoCD, useTLSForRedis bool) error {
 	ss := newStatefulSetWithSuffix("redis-ha-server", "redis", cr)
 
-	redisEnv := append(proxyEnvVars(), corev1.EnvVar{
+	redisEnv := append(getProxyEnvVars(cr), corev1.EnvVar{
 		Name: "AUTH",
 		ValueFrom: &corev1.EnvVarSource{
 			SecretKeyRef: &corev1.SecretKeySelector{
@@ -107,7 +222,7 @@ func (r *ReconcileArgoCD) reconcileRedisStatefulSet(cr *argoproj.ArgoCD) error {
 	})
 
 	ss.Spec.PodManagementPolicy = appsv1.OrderedReadyPodManagement
-	ss.Spec.Replicas = getRedisHAReplicas()
+	ss.Spec.Replicas = getRedisHAReplicas(cr)
 	ss.Spec.Selector = &metav1.LabelSelector{
 		MatchLabels: map[string]string{
 			common.ArgoCDKeyName: nameWithSuffix("redis-ha", cr),
@@ -118,7 +233,7 @@ func (r *ReconcileArgoCD) reconcileRedisStatefulSet(cr *argoproj.ArgoCD) error {
 
 	ss.Spec.Template.ObjectMeta = metav1.ObjectMeta{
 		Annotations: map[string]string{
-			"checksum/init-config": "7128bfbb51eafaffe3c33b1b463e15f0cf6514cec570f9d9c4f2396f28c724ac", // TODO: Should this be hard-coded?
+			common.AnnotationRedisHAConfigChecksum: getRedisHAConfigChecksum(cr, useTLSForRedis),
 		},
 		Labels: map[string]string{
 			common.ArgoCDKeyName: nameWithSuffix("redis-ha", cr),
@@ -295,31 +410,17 @@ func (r *ReconcileArgoCD) reconcileRedisStatefulSet(cr *argoproj.ArgoCD) error {
 		Command: []string{
 			"sh",
 		},
-		Env: []corev1.EnvVar{
-			{
-				Name:  "SENTINEL_ID_0",
-				Value: "3c0d9c0320bb34888c2df5757c718ce6ca992ce6", // TODO: Should this be hard-coded?
-			},
-			{
-				Name:  "SENTINEL_ID_1",
-				Value: "40000915ab58c3fa8fd888fb8b24711944e6cbb4", // TODO: Should this be hard-coded?
-			},
-			{
-				Name:  "SENTINEL_ID_2",
-				Value: "2bbec7894d954a8af3bb54d13eaec53cb024e2ca", // TODO: Should this be hard-coded?
-			},
-			{
-				Name: "AUTH",
-				ValueFrom: &corev1.EnvVarSource{
-					SecretKeyRef: &corev1.SecretKeySelector{
-						LocalObjectReference: corev1.LocalObjectReference{
-							Name: fmt.Sprintf("%s-%s", cr.Name, "redis-initial-password"),
-						},
-						Key: "admin.password",
+		Env: append(getRedisHASentinelIDEnv(cr), corev1.EnvVar{
+			Name: "AUTH",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: fmt.Sprintf("%s-%s", cr.Name, "redis-initial-password"),
 					},
+					Key: "admin.password",
 				},
 			},
-		},
+		}),
 		Image:           getRedisHAContainerImage(cr),
 		ImagePullPolicy: corev1.PullIfNotPresent,
 		Name:            "config-init",
@@ -359,7 +460,7 @@ func (r *ReconcileArgoCD) reconcileRedisStatefulSet(cr *argoproj.ArgoCD) error {
 		RunAsNonRoot: &runAsNonRoot,
 		RunAsUser:    &runAsUser,
 	}
-	AddSeccompProfileForOpenShift(r.Client, &ss.Spec.Template.Spec)
+	AddSeccompProfileForOpenShift(r.Client, r.ClusterAPIs, &ss.Spec.Template.Spec)
 
 	ss.Spec.Template.Spec.ServiceAccountName = nameWithSuffix("argocd-redis-ha", cr)
 
@@ -389,12 +490,6 @@ func (r *ReconcileArgoCD) reconcileRedisStatefulSet(cr *argoproj.ArgoCD) error {
 				},
 			},
 		},
-		{
-			Name: "data",
-			VolumeSource: corev1.VolumeSource{
-				EmptyDir: &corev1.EmptyDirVolumeSource{},
-			},
-		},
 		{
 			Name: common.ArgoCDRedisServerTLSSecretName,
 			VolumeSource: corev1.VolumeSource{
@@ -406,6 +501,17 @@ func (r *ReconcileArgoCD) reconcileRedisStatefulSet(cr *argoproj.ArgoCD) error {
 		},
 	}
 
+	// The data volume is either claimed per replica when persistence is enabled, or an emptyDir otherwise.
+	ss.Spec.VolumeClaimTemplates = getRedisHAVolumeClaimTemplates(cr)
+	if ss.Spec.VolumeClaimTemplates == nil {
+		ss.Spec.Template.Spec.Volumes = append(ss.Spec.Template.Spec.Volumes, corev1.Volume{
+			Name: "data",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		})
+	}
+
 	ss.Spec.UpdateStrategy = appsv1.StatefulSetUpdateStrategy{
 		Type: appsv1.RollingUpdateStatefulSetStrategyType,
 	}
@@ -421,35 +527,22 @@ func (r *ReconcileArgoCD) reconcileRedisStatefulSet(cr *argoproj.ArgoCD) error {
 			return r.Client.Delete(context.TODO(), existing)
 		}
 
-		desiredImage := getRedisHAContainerImage(cr)
-		changed := false
-		updateNodePlacementStateful(existing, ss, &changed)
-		for i, container := range existing.Spec.Template.Spec.Containers {
-			if container.Image != desiredImage {
-				existing.Spec.Template.Spec.Containers[i].Image = getRedisHAContainerImage(cr)
-				existing.Spec.Template.ObjectMeta.Labels["image.upgraded"] = time.Now().UTC().Format("01022006-150406-MST")
-				changed = true
-			}
-
-			if !reflect.DeepEqual(ss.Spec.Template.Spec.Containers[i].Resources, existing.Spec.Template.Spec.Containers[i].Resources) {
-				existing.Spec.Template.Spec.Containers[i].Resources = ss.Spec.Template.Spec.Containers[i].Resources
-				changed = true
+		if redisHAVolumeClaimTemplatesChanged(existing.Spec.VolumeClaimTemplates, ss.Spec.VolumeClaimTemplates) {
+			// volumeClaimTemplates are immutable, the StatefulSet will be recreated on the next reconciliation.
+			// The StatefulSet is deleted without its pods, which the new StatefulSet adopts and rolls one at a time.
+			// Existing PersistentVolumeClaims are retained and re-attached when the claim name is unchanged, and
+			// resized to the new size.
+			log.Info(fmt.Sprintf("redis HA persistence settings changed, recreating StatefulSet %s", existing.Name))
+			if err := r.resizeRedisHAPersistentVolumeClaims(cr, existing, ss.Spec.VolumeClaimTemplates); err != nil {
+				return err
 			}
+			return r.Client.Delete(context.TODO(), existing, client.PropagationPolicy(metav1.DeletePropagationOrphan))
 		}
 
-		if !reflect.DeepEqual(ss.Spec.Template.Spec.InitContainers[0].Resources, existing.Spec.Template.Spec.InitContainers[0].Resources) {
-			existing.Spec.Template.Spec.InitContainers[0].Resources = ss.Spec.Template.Spec.InitContainers[0].Resources
-			changed = true
-		}
-
-		if changed {
-			return r.Client.Update(context.TODO(), existing)
-		}
-
-		return nil // StatefulSet found, do nothing
+		return r.applyStatefulSet(cr, ss, existing)
 	}
 
-	if cr.Spec.Redis.IsEnabled() && cr.Spec.Redis.Remote != nil && *cr.Spec.Redis.Remote != "" {
+	if cr.Spec.Redis.IsEnabled() && isRemoteRedis(cr) {
 		log.Info("Custom Redis Endpoint. Skipping starting redis.")
 		return nil
 	}
@@ -462,11 +555,7 @@ func (r *ReconcileArgoCD) reconcileRedisStatefulSet(cr *argoproj.ArgoCD) error {
 	if !cr.Spec.HA.Enabled {
 		return nil // HA not enabled, do nothing.
 	}
-
-	if err := controllerutil.SetControllerReference(cr, ss, r.Scheme); err != nil {
-		return err
-	}
-	return r.Client.Create(context.TODO(), ss)
+	return r.applyStatefulSet(cr, ss, nil)
 }
 
 func getArgoControllerContainerEnv(cr *argoproj.ArgoCD) []corev1.EnvVar {
@@ -477,17 +566,7 @@ func getArgoControllerContainerEnv(cr *argoproj.ArgoCD) []corev1.EnvVar {
 		Value: "/home/argocd",
 	})
 
-	env = append(env, corev1.EnvVar{
-		Name: "REDIS_PASSWORD",
-		ValueFrom: &corev1.EnvVarSource{
-			SecretKeyRef: &corev1.SecretKeySelector{
-				LocalObjectReference: corev1.LocalObjectReference{
-					Name: fmt.Sprintf("%s-%s", cr.Name, "redis-initial-password"),
-				},
-				Key: "admin.password",
-			},
-		},
-	})
+	env = append(env, getRedisAuthEnv(cr)...)
 
 	if cr.Spec.Controller.Sharding.Enabled {
 		env = append(env, corev1.EnvVar{
@@ -557,19 +636,28 @@ func (r *ReconcileArgoCD) getApplicationControllerReplicaCount(cr *argoproj.Argo
 }
 
 func (r *ReconcileArgoCD) reconcileApplicationControllerStatefulSet(cr *argoproj.ArgoCD, useTLSForRedis bool) error {
-
+	cr = argoCDForUpgradeStep(cr, "application-controller")
 	replicas := r.getApplicationControllerReplicaCount(cr)
 
+	applicationNamespaces, err := r.getApplicationNamespaces(cr)
+	if err != nil {
+		return err
+	}
+
 	ss := newStatefulSetWithSuffix("application-controller", "application-controller", cr)
 	ss.Spec.Replicas = &replicas
 	controllerEnv := cr.Spec.Controller.Env
 	// Sharding setting explicitly overrides a value set in the env
 	controllerEnv = argoutil.EnvMerge(controllerEnv, getArgoControllerContainerEnv(cr), true)
 	// Let user specify their own environment first
-	controllerEnv = argoutil.EnvMerge(controllerEnv, proxyEnvVars(), false)
+	controllerEnv = argoutil.EnvMerge(controllerEnv, getProxyEnvVars(cr), false)
+	controllerCommand := getArgoApplicationControllerCommand(cr, useTLSForRedis, applicationNamespaces)
+	if isRepoServerTLSVerificationRequested(cr) {
+		controllerCommand = append(controllerCommand, "--repo-server-strict-tls")
+	}
 	podSpec := &ss.Spec.Template.Spec
 	podSpec.Containers = []corev1.Container{{
-		Command:         getArgoApplicationControllerCommand(cr, useTLSForRedis),
+		Command:         controllerCommand,
 		Image:           getArgoContainerImage(cr),
 		ImagePullPolicy: corev1.PullAlways,
 		Name:            "argocd-application-controller",
@@ -610,7 +698,7 @@ func (r *ReconcileArgoCD) reconcileApplicationControllerStatefulSet(cr *argoproj
 			},
 		},
 	}}
-	AddSeccompProfileForOpenShift(r.Client, podSpec)
+	AddSeccompProfileForOpenShift(r.Client, r.ClusterAPIs, podSpec)
 	podSpec.ServiceAccountName = nameWithSuffix("argocd-application-controller", cr)
 	podSpec.Volumes = []corev1.Volume{
 		{
@@ -667,7 +755,7 @@ func (r *ReconcileArgoCD) reconcileApplicationControllerStatefulSet(cr *argoproj
 	} else {
 		podSpec.InitContainers = []corev1.Container{{
 			Command:         getArgoImportCommand(r.Client, cr),
-			Env:             proxyEnvVars(getArgoImportContainerEnv(export)...),
+			Env:             getProxyEnvVars(cr, getArgoImportContainerEnv(export)...),
 			Resources:       getArgoApplicationControllerResources(cr),
 			Image:           getArgoImportContainerImage(export),
 			ImagePullPolicy: corev1.PullAlways,
@@ -687,6 +775,10 @@ func (r *ReconcileArgoCD) reconcileApplicationControllerStatefulSet(cr *argoproj
 		podSpec.Volumes = getArgoImportVolumes(export)
 	}
 
+	podSpec.Containers[0].VolumeMounts = append(podSpec.Containers[0].VolumeMounts, getRemoteRedisCAVolumeMounts(cr)...)
+	podSpec.Volumes = append(podSpec.Volumes, getRemoteRedisCAVolumes(cr)...)
+	addTrustedCA(cr, podSpec, getArgoContainerImage(cr))
+
 	invalidImagePod := containsInvalidImage(cr, r)
 	if invalidImagePod {
 		if err := r.Client.Delete(context.TODO(), ss); err != nil {
@@ -701,51 +793,7 @@ func (r *ReconcileArgoCD) reconcileApplicationControllerStatefulSet(cr *argoproj
 			// Delete existing deployment for Application Controller, if any ..
 			return r.Client.Delete(context.TODO(), existing)
 		}
-		actualImage := existing.Spec.Template.Spec.Containers[0].Image
-		desiredImage := getArgoContainerImage(cr)
-		changed := false
-		if actualImage != desiredImage {
-			existing.Spec.Template.Spec.Containers[0].Image = desiredImage
-			existing.Spec.Template.ObjectMeta.Labels["image.upgraded"] = time.Now().UTC().Format("01022006-150406-MST")
-			changed = true
-		}
-		desiredCommand := getArgoApplicationControllerCommand(cr, useTLSForRedis)
-		if isRepoServerTLSVerificationRequested(cr) {
-			desiredCommand = append(desiredCommand, "--repo-server-strict-tls")
-		}
-		updateNodePlacementStateful(existing, ss, &changed)
-		if !reflect.DeepEqual(desiredCommand, existing.Spec.Template.Spec.Containers[0].Command) {
-			existing.Spec.Template.Spec.Containers[0].Command = desiredCommand
-			changed = true
-		}
-
-		if !reflect.DeepEqual(existing.Spec.Template.Spec.Containers[0].Env,
-			ss.Spec.Template.Spec.Containers[0].Env) {
-			existing.Spec.Template.Spec.Containers[0].Env = ss.Spec.Template.Spec.Containers[0].Env
-			changed = true
-		}
-		if !reflect.DeepEqual(ss.Spec.Template.Spec.Volumes, existing.Spec.Template.Spec.Volumes) {
-			existing.Spec.Template.Spec.Volumes = ss.Spec.Template.Spec.Volumes
-			changed = true
-		}
-		if !reflect.DeepEqual(ss.Spec.Template.Spec.Containers[0].VolumeMounts,
-			existing.Spec.Template.Spec.Containers[0].VolumeMounts) {
-			existing.Spec.Template.Spec.Containers[0].VolumeMounts = ss.Spec.Template.Spec.Containers[0].VolumeMounts
-			changed = true
-		}
-		if !reflect.DeepEqual(ss.Spec.Template.Spec.Containers[0].Resources, existing.Spec.Template.Spec.Containers[0].Resources) {
-			existing.Spec.Template.Spec.Containers[0].Resources = ss.Spec.Template.Spec.Containers[0].Resources
-			changed = true
-		}
-		if !reflect.DeepEqual(ss.Spec.Replicas, existing.Spec.Replicas) {
-			existing.Spec.Replicas = ss.Spec.Replicas
-			changed = true
-		}
-
-		if changed {
-			return r.Client.Update(context.TODO(), existing)
-		}
-		return nil // StatefulSet found with nothing to do, move along...
+		return r.applyStatefulSet(cr, ss, existing)
 	}
 
 	if !cr.Spec.Controller.IsEnabled() {
@@ -761,10 +809,7 @@ func (r *ReconcileArgoCD) reconcileApplicationControllerStatefulSet(cr *argoproj
 		}
 	}
 
-	if err := controllerutil.SetControllerReference(cr, ss, r.Scheme); err != nil {
-		return err
-	}
-	return r.Client.Create(context.TODO(), ss)
+	return r.applyStatefulSet(cr, ss, nil)
 }
 
 // reconcileStatefulSets will ensure that all StatefulSets are present for the given ArgoCD.
@@ -772,7 +817,7 @@ func (r *ReconcileArgoCD) reconcileStatefulSets(cr *argoproj.ArgoCD, useTLSForRe
 	if err := r.reconcileApplicationControllerStatefulSet(cr, useTLSForRedis); err != nil {
 		return err
 	}
-	if err := r.reconcileRedisStatefulSet(cr); err != nil {
+	if err := r.reconcileRedisStatefulSet(cr, useTLSForRedis); err != nil {
 		return err
 	}
 	return nil
@@ -789,16 +834,16 @@ func (r *ReconcileArgoCD) triggerStatefulSetRollout(sts *appsv1.StatefulSet, key
 	return r.Client.Update(context.TODO(), sts)
 }
 
-// to update nodeSelector and tolerations in reconciler
-func updateNodePlacementStateful(existing *appsv1.StatefulSet, ss *appsv1.StatefulSet, changed *bool) {
-	if !reflect.DeepEqual(existing.Spec.Template.Spec.NodeSelector, ss.Spec.Template.Spec.NodeSelector) {
-		existing.Spec.Template.Spec.NodeSelector = ss.Spec.Template.Spec.NodeSelector
-		*changed = true
+// applyStatefulSet applies the given StatefulSet owned by the given ArgoCD. The existing StatefulSet, if any, is used
+// to carry over its image.upgraded label.
+func (r *ReconcileArgoCD) applyStatefulSet(cr *argoproj.ArgoCD, ss *appsv1.StatefulSet, existing *appsv1.StatefulSet) error {
+	if existing != nil {
+		carryImageUpgradedLabel(&ss.Spec.Template, &existing.Spec.Template)
 	}
-	if !reflect.DeepEqual(existing.Spec.Template.Spec.Tolerations, ss.Spec.Template.Spec.Tolerations) {
-		existing.Spec.Template.Spec.Tolerations = ss.Spec.Template.Spec.Tolerations
-		*changed = true
+	if err := controllerutil.SetControllerReference(cr, ss, r.Scheme); err != nil {
+		return err
 	}
+	return argoutil.ApplyResource(r.Client, ss)
 }
 
 // Returns true if a StatefulSet has pods in ErrImagePull or ImagePullBackoff state.
@@ -84,7 +84,7 @@ func TestReconcileArgoCD_reconcileRedisStatefulSet_HA_disabled(t *testing.T) {
 
 	s := newStatefulSetWithSuffix("redis-ha-server", "redis", a)
 
-	assert.NoError(t, r.reconcileRedisStatefulSet(a))
+	assert.NoError(t, r.reconcileRedisStatefulSet(a, false))
 	// resource Creation should fail as HA was disabled
 	assert.Errorf(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: s.Name, Namespace: a.Namespace}, s), "not found")
 }
@@ -105,7 +105,7 @@ func TestReconcileArgoCD_reconcileRedisStatefulSet_HA_enabled(t *testing.T) {
 
 	a.Spec.HA.Enabled = true
 	// test resource is Created when HA is enabled
-	assert.NoError(t, r.reconcileRedisStatefulSet(a))
+	assert.NoError(t, r.reconcileRedisStatefulSet(a, false))
 	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: s.Name, Namespace: a.Namespace}, s))
 
 	// test resource is Updated on reconciliation
@@ -122,7 +122,7 @@ func TestReconcileArgoCD_reconcileRedisStatefulSet_HA_enabled(t *testing.T) {
 		},
 	}
 	a.Spec.HA.Resources = &newResources
-	assert.NoError(t, r.reconcileRedisStatefulSet(a))
+	assert.NoError(t, r.reconcileRedisStatefulSet(a, false))
 	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: s.Name, Namespace: a.Namespace}, s))
 	for _, container := range s.Spec.Template.Spec.Containers {
 		assert.Equal(t, container.Image, fmt.Sprintf("%s:%s", testRedisImage, testRedisImageVersion))
@@ -132,10 +132,139 @@ func TestReconcileArgoCD_reconcileRedisStatefulSet_HA_enabled(t *testing.T) {
 
 	// test resource is Deleted, when HA is disabled
 	a.Spec.HA.Enabled = false
-	assert.NoError(t, r.reconcileRedisStatefulSet(a))
+	assert.NoError(t, r.reconcileRedisStatefulSet(a, false))
 	assert.Errorf(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: s.Name, Namespace: a.Namespace}, s), "not found")
 }
 
+func TestReconcileArgoCD_reconcileRedisStatefulSet_HA_topology(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.HA.Enabled = true
+		a.Spec.HA.RedisReplicas = int32Ptr(5)
+	})
+
+	resObjs := []client.Object{a}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	s := newStatefulSetWithSuffix("redis-ha-server", "redis", a)
+	assert.NoError(t, r.reconcileRedisStatefulSet(a, false))
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: s.Name, Namespace: a.Namespace}, s))
+	assert.Equal(t, int32(5), *s.Spec.Replicas)
+	assert.Equal(t, getRedisHAConfigChecksum(a, false), s.Spec.Template.Annotations[common.AnnotationRedisHAConfigChecksum])
+
+	sentinelIDs := map[string]string{}
+	for _, env := range s.Spec.Template.Spec.InitContainers[0].Env {
+		if env.Value != "" {
+			sentinelIDs[env.Name] = env.Value
+		}
+	}
+	assert.Len(t, sentinelIDs, 5)
+	assert.Equal(t, "3c0d9c0320bb34888c2df5757c718ce6ca992ce6", sentinelIDs["SENTINEL_ID_0"])
+	assert.Len(t, sentinelIDs["SENTINEL_ID_4"], 40)
+	assert.NotEqual(t, sentinelIDs["SENTINEL_ID_3"], sentinelIDs["SENTINEL_ID_4"])
+
+	// test replicas and the config checksum are updated on reconciliation
+	a.Spec.HA.RedisReplicas = int32Ptr(3)
+	a.Spec.HA.SentinelQuorum = int32Ptr(3)
+	assert.NoError(t, r.reconcileRedisStatefulSet(a, false))
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: s.Name, Namespace: a.Namespace}, s))
+	assert.Equal(t, int32(3), *s.Spec.Replicas)
+	assert.Len(t, s.Spec.Template.Spec.InitContainers[0].Env, 4)
+	assert.Equal(t, getRedisHAConfigChecksum(a, false), s.Spec.Template.Annotations[common.AnnotationRedisHAConfigChecksum])
+}
+
+func TestReconcileArgoCD_reconcileRedisStatefulSet_HA_persistence(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.HA.Enabled = true
+	})
+
+	resObjs := []client.Object{a}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	s := newStatefulSetWithSuffix("redis-ha-server", "redis", a)
+	assert.NoError(t, r.reconcileRedisStatefulSet(a, false))
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: s.Name, Namespace: a.Namespace}, s))
+	assert.Empty(t, s.Spec.VolumeClaimTemplates)
+	assert.Contains(t, s.Spec.Template.Spec.Volumes, corev1.Volume{
+		Name: "data",
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		},
+	})
+
+	// test StatefulSet is recreated with volumeClaimTemplates when persistence is enabled
+	storageClass := "standard"
+	a.Spec.HA.Persistence = &argoproj.ArgoCDRedisHAPersistenceSpec{
+		Enabled:          true,
+		StorageClassName: &storageClass,
+	}
+	assert.NoError(t, r.reconcileRedisStatefulSet(a, false))
+	assert.Errorf(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: s.Name, Namespace: a.Namespace}, s), "not found")
+
+	s = newStatefulSetWithSuffix("redis-ha-server", "redis", a)
+	assert.NoError(t, r.reconcileRedisStatefulSet(a, false))
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: s.Name, Namespace: a.Namespace}, s))
+	assert.Len(t, s.Spec.VolumeClaimTemplates, 1)
+	pvc := s.Spec.VolumeClaimTemplates[0]
+	assert.Equal(t, "data", pvc.Name)
+	assert.Equal(t, []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}, pvc.Spec.AccessModes)
+	assert.Equal(t, &storageClass, pvc.Spec.StorageClassName)
+	assert.True(t, resourcev1.MustParse("1Gi").Equal(pvc.Spec.Resources.Requests[corev1.ResourceStorage]))
+	for _, v := range s.Spec.Template.Spec.Volumes {
+		assert.NotEqual(t, "data", v.Name)
+	}
+
+	// test StatefulSet is left alone when persistence settings are unchanged
+	assert.NoError(t, r.reconcileRedisStatefulSet(a, false))
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: s.Name, Namespace: a.Namespace}, s))
+
+	// test the claims of the StatefulSet are grown and its pods are orphaned when the size changes
+	for i := 0; i < 3; i++ {
+		claim := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("data-%s-%d", s.Name, i), Namespace: a.Namespace},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resourcev1.MustParse("1Gi")},
+				},
+			},
+		}
+		assert.NoError(t, r.Client.Create(context.TODO(), claim))
+	}
+	size := resourcev1.MustParse("5Gi")
+	a.Spec.HA.Persistence.Size = &size
+	var deleteOpts []client.DeleteOption
+	r.Client = &deleteInterceptor{Client: r.Client, onDelete: func(opts []client.DeleteOption) { deleteOpts = opts }}
+	assert.NoError(t, r.reconcileRedisStatefulSet(a, false))
+	assert.Contains(t, deleteOpts, client.PropagationPolicy(metav1.DeletePropagationOrphan))
+	for i := 0; i < 3; i++ {
+		claim := &corev1.PersistentVolumeClaim{}
+		assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: fmt.Sprintf("data-%s-%d", s.Name, i), Namespace: a.Namespace}, claim))
+		assert.True(t, size.Equal(claim.Spec.Resources.Requests[corev1.ResourceStorage]))
+	}
+}
+
+// deleteInterceptor records the options of the deletions made through its client.
+type deleteInterceptor struct {
+	client.Client
+	onDelete func([]client.DeleteOption)
+}
+
+func (c *deleteInterceptor) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	c.onDelete(opts)
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
 func TestReconcileArgoCD_reconcileApplicationController(t *testing.T) {
 	logf.SetLogger(ZapLogger(true))
 	a := makeTestArgoCD()
@@ -549,63 +678,57 @@ func TestReconcileArgoCD_reconcileApplicationController_withEnv(t *testing.T) {
 	}
 }
 
-func Test_UpdateNodePlacementStateful(t *testing.T) {
-
-	ss := &appsv1.StatefulSet{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "argocd-sample-server",
-			Namespace: testNamespace,
-		},
-		Spec: appsv1.StatefulSetSpec{
-			Template: corev1.PodTemplateSpec{
-				Spec: corev1.PodSpec{
-					NodeSelector: map[string]string{
-						"test_key1": "test_value1",
-						"test_key2": "test_value2",
-					},
-					Tolerations: []corev1.Toleration{
-						{
-							Key:    "test_key1",
-							Value:  "test_value1",
-							Effect: corev1.TaintEffectNoSchedule,
-						},
-					},
+func TestReconcileArgoCD_reconcileApplicationController_withNodePlacement(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.NodePlacement = &argoproj.ArgoCDNodePlacementSpec{
+			NodeSelector: map[string]string{
+				"test_key1": "test_value1",
+				"test_key2": "test_value2",
+			},
+			Tolerations: []corev1.Toleration{
+				{
+					Key:    "test_key1",
+					Value:  "test_value1",
+					Effect: corev1.TaintEffectNoSchedule,
 				},
 			},
+		}
+	})
+
+	resObjs := []client.Object{a}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileApplicationControllerStatefulSet(a, false))
+
+	a.Spec.NodePlacement = &argoproj.ArgoCDNodePlacementSpec{
+		NodeSelector: map[string]string{
+			"test_key1": "test_value1",
 		},
-	}
-	ss2 := &appsv1.StatefulSet{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "argocd-sample-server",
-			Namespace: testNamespace,
-		},
-		Spec: appsv1.StatefulSetSpec{
-			Template: corev1.PodTemplateSpec{
-				Spec: corev1.PodSpec{
-					NodeSelector: map[string]string{
-						"test_key1": "test_value1",
-					},
-					Tolerations: []corev1.Toleration{
-						{
-							Key:    "test_key1",
-							Value:  "test_value1",
-							Effect: corev1.TaintEffectNoExecute,
-						},
-					},
-				},
+		Tolerations: []corev1.Toleration{
+			{
+				Key:    "test_key1",
+				Value:  "test_value1",
+				Effect: corev1.TaintEffectNoExecute,
 			},
 		},
 	}
-	expectedChange := false
-	actualChange := false
-	updateNodePlacementStateful(ss, ss, &actualChange)
-	if actualChange != expectedChange {
-		t.Fatalf("updateNodePlacement failed, value of changed: %t", actualChange)
-	}
-	updateNodePlacementStateful(ss, ss2, &actualChange)
-	if actualChange == expectedChange {
-		t.Fatalf("updateNodePlacement failed, value of changed: %t", actualChange)
-	}
+	assert.NoError(t, r.reconcileApplicationControllerStatefulSet(a, false))
+
+	ss := &appsv1.StatefulSet{}
+	assert.NoError(t, r.Client.Get(
+		context.TODO(),
+		types.NamespacedName{
+			Name:      "argocd-application-controller",
+			Namespace: a.Namespace,
+		},
+		ss))
+	assert.Equal(t, argoutil.AppendStringMap(common.DefaultNodeSelector(), a.Spec.NodePlacement.NodeSelector), ss.Spec.Template.Spec.NodeSelector)
+	assert.Equal(t, a.Spec.NodePlacement.Tolerations, ss.Spec.Template.Spec.Tolerations)
 }
 
 func Test_ContainsValidImage(t *testing.T) {
@@ -16,19 +16,23 @@ package argocd
 
 import (
 	"context"
+	"fmt"
 	"reflect"
 	"strings"
 
 	oappsv1 "github.com/openshift/api/apps/v1"
 	routev1 "github.com/openshift/api/route/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	appsv1 "k8s.io/api/apps/v1"
 
 	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
 	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
 )
 
@@ -130,7 +134,7 @@ func (r *ReconcileArgoCD) reconcileStatusDex(cr *argoproj.ArgoCD) error {
 func (r *ReconcileArgoCD) reconcileStatusKeycloak(cr *argoproj.ArgoCD) error {
 	status := "Unknown"
 
-	if CanUseKeycloakWithTemplate() {
+	if r.ClusterAPIs.CanUseKeycloakWithTemplate() {
 		// keycloak is installed using OpenShift templates.
 		dc := &oappsv1.DeploymentConfig{
 			ObjectMeta: metav1.ObjectMeta{
@@ -218,7 +222,7 @@ func (r *ReconcileArgoCD) reconcileStatusApplicationSetController(cr *argoproj.A
 func (r *ReconcileArgoCD) reconcileStatusSSO(cr *argoproj.ArgoCD) error {
 
 	// set status to track ssoConfigLegalStatus so it is always up to date with latest sso situation
-	status := ssoConfigLegalStatus
+	status := r.ssoConfigLegalStatus
 
 	// perform dex/keycloak status reconciliation only if sso configurations are legal
 	if status == ssoLegalSuccess {
@@ -243,7 +247,7 @@ func (r *ReconcileArgoCD) reconcileStatusPhase(cr *argoproj.ArgoCD) error {
 	var phase string
 
 	if ((!cr.Spec.Controller.IsEnabled() && cr.Status.ApplicationController == "Unknown") || cr.Status.ApplicationController == "Running") &&
-		((!cr.Spec.Redis.IsEnabled() && cr.Status.Redis == "Unknown") || cr.Status.Redis == "Running" || (cr.Spec.Redis.IsEnabled() && cr.Spec.Redis.Remote != nil && *cr.Spec.Redis.Remote != "")) &&
+		((!cr.Spec.Redis.IsEnabled() && cr.Status.Redis == "Unknown") || cr.Status.Redis == "Running") &&
 		((!cr.Spec.Repo.IsEnabled() && cr.Status.Repo == "Unknown") || cr.Status.Repo == "Running") &&
 		((!cr.Spec.Server.IsEnabled() && cr.Status.Server == "Unknown") || cr.Status.Server == "Running") {
 		phase = "Available"
@@ -258,11 +262,152 @@ func (r *ReconcileArgoCD) reconcileStatusPhase(cr *argoproj.ArgoCD) error {
 	return nil
 }
 
+// reconcileStatusStrategy will ensure that the ReconcileStrategy and Drift status are updated for the given ArgoCD,
+// reporting the changes of strategy and the new drift through events.
+func (r *ReconcileArgoCD) reconcileStatusStrategy(cr *argoproj.ArgoCD, strategy argoproj.ArgoCDReconcileStrategy, drift []argoproj.ArgoCDResourceDrift) error {
+	if cr.Status.ReconcileStrategy == strategy && reflect.DeepEqual(cr.Status.Drift, drift) {
+		return nil
+	}
+
+	typeMeta := metav1.TypeMeta{Kind: "ArgoCD", APIVersion: argoproj.GroupVersion.String()}
+	if cr.Status.ReconcileStrategy != strategy && (cr.Status.ReconcileStrategy != "" || strategy != argoproj.ReconcileStrategyActive) {
+		message := fmt.Sprintf("reconcile strategy changed to %s", strategy)
+		if err := argoutil.CreateEvent(r.Client, corev1.EventTypeNormal, "Reconcile", message, "ReconcileStrategyChanged", cr.ObjectMeta, typeMeta); err != nil {
+			log.Error(err, fmt.Sprintf("failed to report the reconcile strategy of ArgoCD %s/%s", cr.Namespace, cr.Name))
+		}
+	}
+	if len(drift) > 0 && !reflect.DeepEqual(cr.Status.Drift, drift) {
+		resources := make([]string, 0, len(drift))
+		for _, d := range drift {
+			resources = append(resources, fmt.Sprintf("%s %s %s", d.Action, d.Kind, d.Name))
+		}
+		message := fmt.Sprintf("%d resources drifted from the desired state: %s", len(drift), strings.Join(resources, ", "))
+		if err := argoutil.CreateEvent(r.Client, corev1.EventTypeWarning, "Reconcile", message, "DriftDetected", cr.ObjectMeta, typeMeta); err != nil {
+			log.Error(err, fmt.Sprintf("failed to report the drift of ArgoCD %s/%s", cr.Namespace, cr.Name))
+		}
+	}
+
+	cr.Status.ReconcileStrategy = strategy
+	cr.Status.Drift = drift
+	return r.Client.Status().Update(context.TODO(), cr)
+}
+
+// reconcileStatusConfigurationConflict will ensure that the ConfigurationConflict condition is updated for the given
+// ArgoCD, an ArgoCD whose configuration is controlled by another ArgoCD being Failed.
+func (r *ReconcileArgoCD) reconcileStatusConfigurationConflict(cr *argoproj.ArgoCD, conflict string) error {
+	if conflict == "" {
+		if !meta.IsStatusConditionTrue(cr.Status.Conditions, argoproj.ArgoCDConditionConfigurationConflict) {
+			return nil
+		}
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:               argoproj.ArgoCDConditionConfigurationConflict,
+			Status:             metav1.ConditionFalse,
+			Reason:             "ConfigurationControlled",
+			Message:            "the configuration of the namespace is controlled by this ArgoCD",
+			ObservedGeneration: cr.Generation,
+		})
+		return r.Client.Status().Update(context.TODO(), cr)
+	}
+
+	existing := meta.FindStatusCondition(cr.Status.Conditions, argoproj.ArgoCDConditionConfigurationConflict)
+	changed := existing == nil || existing.Status != metav1.ConditionTrue || existing.Message != conflict
+	meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+		Type:               argoproj.ArgoCDConditionConfigurationConflict,
+		Status:             metav1.ConditionTrue,
+		Reason:             "ControlledByAnotherInstance",
+		Message:            conflict,
+		ObservedGeneration: cr.Generation,
+	})
+	if changed {
+		typeMeta := metav1.TypeMeta{Kind: "ArgoCD", APIVersion: argoproj.GroupVersion.String()}
+		if err := argoutil.CreateEvent(r.Client, corev1.EventTypeWarning, "Reconcile", conflict, "ConfigurationConflict", cr.ObjectMeta, typeMeta); err != nil {
+			log.Error(err, fmt.Sprintf("failed to report the configuration conflict of ArgoCD %s/%s", cr.Namespace, cr.Name))
+		}
+	}
+	if cr.Status.Phase != "Failed" {
+		cr.Status.Phase = "Failed"
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return r.Client.Status().Update(context.TODO(), cr)
+}
+
+// reconcileStatusInvalidRepositories will ensure that the InvalidRepositories condition of the given ArgoCD reports
+// the given invalid repositories, if any. A new report is also sent as an event.
+func (r *ReconcileArgoCD) reconcileStatusInvalidRepositories(cr *argoproj.ArgoCD, invalid []string) error {
+	return r.reconcileStatusInvalidCondition(cr, argoproj.ArgoCDConditionInvalidRepositories, "RepositoriesValid",
+		"the repositories are held by Secrets", invalid)
+}
+
+// reconcileStatusInvalidResourceFilters will ensure that the InvalidResourceFilters condition of the given ArgoCD
+// reports the given invalid resource exclusions and inclusions, if any. A new report is also sent as an event.
+func (r *ReconcileArgoCD) reconcileStatusInvalidResourceFilters(cr *argoproj.ArgoCD, invalid []string) error {
+	return r.reconcileStatusInvalidCondition(cr, argoproj.ArgoCDConditionInvalidResourceFilters, "ResourceFiltersValid",
+		"the resource exclusions and inclusions are valid", invalid)
+}
+
+// reconcileStatusInvalidCondition will ensure that the given condition of the given ArgoCD is true, with the
+// condition type as reason, when there are invalid items, and false with the given reason and message once there are
+// none left. A new report of invalid items is also sent as a warning event.
+func (r *ReconcileArgoCD) reconcileStatusInvalidCondition(cr *argoproj.ArgoCD, conditionType, validReason, validMessage string, invalid []string) error {
+	if len(invalid) == 0 {
+		if !meta.IsStatusConditionTrue(cr.Status.Conditions, conditionType) {
+			return nil
+		}
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:               conditionType,
+			Status:             metav1.ConditionFalse,
+			Reason:             validReason,
+			Message:            validMessage,
+			ObservedGeneration: cr.Generation,
+		})
+		return r.Client.Status().Update(context.TODO(), cr)
+	}
+
+	message := strings.Join(invalid, "; ")
+	existing := meta.FindStatusCondition(cr.Status.Conditions, conditionType)
+	if existing != nil && existing.Status == metav1.ConditionTrue && existing.Message == message {
+		return nil
+	}
+	meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             metav1.ConditionTrue,
+		Reason:             conditionType,
+		Message:            message,
+		ObservedGeneration: cr.Generation,
+	})
+	typeMeta := metav1.TypeMeta{Kind: "ArgoCD", APIVersion: argoproj.GroupVersion.String()}
+	if err := argoutil.CreateEvent(r.Client, corev1.EventTypeWarning, "Reconcile", message, conditionType, cr.ObjectMeta, typeMeta); err != nil {
+		log.Error(err, fmt.Sprintf("failed to report the %s condition of ArgoCD %s/%s", conditionType, cr.Namespace, cr.Name))
+	}
+	return r.Client.Status().Update(context.TODO(), cr)
+}
+
+// reconcileStatusInventory will ensure that the Inventory status is updated for the given ArgoCD.
+func (r *ReconcileArgoCD) reconcileStatusInventory(cr *argoproj.ArgoCD, inventory []argoproj.ArgoCDManagedResource) error {
+	if len(cr.Status.Inventory) == 0 && len(inventory) == 0 || reflect.DeepEqual(cr.Status.Inventory, inventory) {
+		return nil
+	}
+	cr.Status.Inventory = inventory
+	return r.Client.Status().Update(context.TODO(), cr)
+}
+
 // reconcileStatusRedis will ensure that the Redis status is updated for the given ArgoCD.
 func (r *ReconcileArgoCD) reconcileStatusRedis(cr *argoproj.ArgoCD) error {
 	status := "Unknown"
 
-	if !cr.Spec.HA.Enabled {
+	if cr.Spec.Redis.IsEnabled() && isRemoteRedis(cr) {
+		// the remote Redis is not contacted when rendering, its status stays unknown
+		if !r.offline {
+			status = "Running"
+			if err := r.probeRemoteRedis(cr); err != nil {
+				log.Error(err, fmt.Sprintf("remote redis for ArgoCD %s in namespace %s is unreachable", cr.Name, cr.Namespace))
+				status = "Failed"
+			}
+		}
+	} else if !cr.Spec.HA.Enabled {
 		deploy := newDeploymentWithSuffix("redis", "redis", cr)
 		if argoutil.IsObjectFound(r.Client, cr.Namespace, deploy.Name, deploy) {
 			status = "Pending"
@@ -300,6 +445,78 @@ func (r *ReconcileArgoCD) reconcileStatusRedis(cr *argoproj.ArgoCD) error {
 	return nil
 }
 
+// probeRemoteRedis will verify that the remote Redis of the given ArgoCD can be reached with the configured
+// credentials and TLS settings, through the sentinels when sentinel mode is used. The result of the probe is reused
+// for redisProbeInterval.
+func (r *ReconcileArgoCD) probeRemoteRedis(cr *argoproj.ArgoCD) error {
+	opts := argoutil.RedisProbeOptions{
+		TLS:                isRemoteRedisTLSEnabled(cr),
+		InsecureSkipVerify: isRedisTLSVerificationDisabled(cr),
+	}
+
+	if remote := cr.Spec.Redis.RemoteConfig; remote != nil {
+		if remote.PasswordSecret != nil {
+			password, err := r.getSecretKeyValue(cr.Namespace, remote.PasswordSecret)
+			if err != nil {
+				return err
+			}
+			opts.Password = password
+		}
+		if remote.UsernameSecret != nil {
+			username, err := r.getSecretKeyValue(cr.Namespace, remote.UsernameSecret)
+			if err != nil {
+				return err
+			}
+			opts.Username = username
+		}
+		if sentinel := remote.Sentinel; sentinel != nil && sentinel.PasswordSecret != nil {
+			password, err := r.getSecretKeyValue(cr.Namespace, sentinel.PasswordSecret)
+			if err != nil {
+				return err
+			}
+			opts.SentinelPassword = password
+		}
+		if sentinel := remote.Sentinel; sentinel != nil && sentinel.UsernameSecret != nil {
+			username, err := r.getSecretKeyValue(cr.Namespace, sentinel.UsernameSecret)
+			if err != nil {
+				return err
+			}
+			opts.SentinelUsername = username
+		}
+		if ca := getRemoteRedisCAConfigMap(cr); ca != nil && !opts.InsecureSkipVerify {
+			cm := &corev1.ConfigMap{}
+			if err := r.Client.Get(context.TODO(), types.NamespacedName{Namespace: cr.Namespace, Name: ca.Name}, cm); err != nil {
+				return err
+			}
+			opts.CACert = []byte(cm.Data[ca.Key])
+		}
+	}
+
+	if sentinel := getRemoteRedisSentinel(cr); sentinel != nil {
+		master := getRemoteRedisSentinelMasterName(cr)
+		return r.redisProbes.probe(instanceKey(cr), append([]string{master}, sentinel.Addresses...), opts, func() error {
+			return argoutil.ProbeRedisSentinel(sentinel.Addresses, master, opts)
+		})
+	}
+	addr := getRedisServerAddress(cr)
+	return r.redisProbes.probe(instanceKey(cr), []string{addr}, opts, func() error {
+		return argoutil.ProbeRedis(addr, opts)
+	})
+}
+
+// getSecretKeyValue will return the value of the referenced key of a Secret in the given namespace.
+func (r *ReconcileArgoCD) getSecretKeyValue(namespace string, ref *corev1.SecretKeySelector) (string, error) {
+	secret := &corev1.Secret{}
+	if err := r.Client.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: ref.Name}, secret); err != nil {
+		return "", err
+	}
+	value, ok := secret.Data[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("key %s not found in secret %s", ref.Key, ref.Name)
+	}
+	return string(value), nil
+}
+
 // reconcileStatusRepo will ensure that the Repo status is updated for the given ArgoCD.
 func (r *ReconcileArgoCD) reconcileStatusRepo(cr *argoproj.ArgoCD) error {
 	status := "Unknown"
@@ -399,7 +616,7 @@ func (r *ReconcileArgoCD) reconcileStatusNotifications(cr *argoproj.ArgoCD) erro
 func (r *ReconcileArgoCD) reconcileStatusHost(cr *argoproj.ArgoCD) error {
 	cr.Status.Host = ""
 
-	if (cr.Spec.Server.Route.Enabled || cr.Spec.Server.Ingress.Enabled) && IsRouteAPIAvailable() {
+	if (cr.Spec.Server.Route.Enabled || cr.Spec.Server.Ingress.Enabled) && r.ClusterAPIs.IsRouteAPIAvailable() {
 		route := newRouteWithSuffix("server", cr)
 
 		// The Red Hat OpenShift ingress controller implementation is designed to watch ingress objects and create one or more routes
@@ -472,6 +689,49 @@ func (r *ReconcileArgoCD) reconcileStatusHost(cr *argoproj.ArgoCD) error {
 				cr.Status.Host = hosts
 			}
 		}
+	} else if cr.Spec.Server.HTTPRoute.Enabled && r.ClusterAPIs.IsGatewayAPIAvailable() {
+		host, err := r.getArgoServerHTTPRouteHost(cr)
+		if err != nil {
+			log.Info(err.Error())
+			cr.Status.Phase = "Pending"
+			return nil
+		}
+		if host == "" {
+			// no parent Gateway accepted the route yet
+			cr.Status.Phase = "Pending"
+		}
+		cr.Status.Host = host
 	}
 	return r.Client.Status().Update(context.TODO(), cr)
 }
+
+// reconcileStatusResourceCustomizations will ensure that the ResourceCustomizationFailures Status is updated for the
+// given ArgoCD, by compiling the Lua scripts of its resource customizations and running their tests.
+func (r *ReconcileArgoCD) reconcileStatusResourceCustomizations(cr *argoproj.ArgoCD) error {
+	if cr.Generation != 0 && cr.Status.ResourceCustomizationsObservedGeneration == cr.Generation {
+		return nil // the resource customizations of this spec were already validated
+	}
+	failures := cr.ValidateResourceCustomizations()
+	if reflect.DeepEqual(failures, cr.Status.ResourceCustomizationFailures) &&
+		cr.Status.ResourceCustomizationsObservedGeneration == cr.Generation {
+		return nil
+	}
+	for _, failure := range failures {
+		log.Info(fmt.Sprintf("%s %s/%s of ArgoCD %s/%s is left out of %s: %s", failure.Type, failure.Group, failure.Kind,
+			cr.Namespace, cr.Name, common.ArgoCDConfigMapName, failure.Message))
+	}
+	cr.Status.ResourceCustomizationFailures = failures
+	cr.Status.ResourceCustomizationsObservedGeneration = cr.Generation
+	return r.Client.Status().Update(context.TODO(), cr)
+}
+
+// hasResourceCustomizationFailure returns true when the resource customization of the given type, group and kind
+// failed for the given ArgoCD.
+func hasResourceCustomizationFailure(cr *argoproj.ArgoCD, customizationType argoproj.ResourceCustomizationType, group, kind string) bool {
+	for _, failure := range cr.Status.ResourceCustomizationFailures {
+		if failure.Type == customizationType && failure.Group == group && failure.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -1,7 +1,10 @@
 package argocd
 
 import (
+	"bufio"
 	"context"
+	"net"
+	"strings"
 	"testing"
 
 	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
@@ -10,6 +13,7 @@ import (
 	configv1 "github.com/openshift/api/config/v1"
 	routev1 "github.com/openshift/api/route/v1"
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -31,7 +35,7 @@ func TestReconcileArgoCD_reconcileStatusKeycloak_K8s(t *testing.T) {
 
 	assert.NoError(t, createNamespace(r, a.Namespace, ""))
 
-	d := newKeycloakDeployment(a)
+	d := newKeycloakDeployment(a, r.ClusterAPIs)
 
 	// keycloak not installed
 	_ = r.reconcileStatusKeycloak(a)
@@ -66,11 +70,9 @@ func TestReconcileArgoCD_reconcileStatusKeycloak_OpenShift(t *testing.T) {
 	assert.NoError(t, createNamespace(r, a.Namespace, ""))
 
 	assert.NoError(t, oappsv1.Install(r.Scheme))
-	templateAPIFound = true
-	deploymentConfigAPIFound = true
-	defer removeTemplateAPI()
+	r.ClusterAPIs.setKeycloakTemplateAPIsAvailable(true, true)
 
-	dc := getKeycloakDeploymentConfigTemplate(a)
+	dc := getKeycloakDeploymentConfigTemplate(a, r.ClusterAPIs)
 	dc.ObjectMeta.Name = defaultKeycloakIdentifier
 
 	// keycloak not installed
@@ -214,8 +216,6 @@ func TestReconcileArgoCD_reconcileStatusHost(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 
-			routeAPIFound = test.testRouteAPIFound
-
 			a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
 				a.Spec.Server.Route.Enabled = test.routeEnabled
 				a.Spec.Server.Ingress.Enabled = test.ingressEnabled
@@ -275,6 +275,7 @@ func TestReconcileArgoCD_reconcileStatusHost(t *testing.T) {
 			sch := makeTestReconcilerScheme(argoproj.AddToScheme, configv1.Install, routev1.Install)
 			cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
 			r := makeTestReconciler(cl, sch)
+			r.ClusterAPIs.setRouteAPIAvailable(test.testRouteAPIFound)
 
 			if test.routeEnabled {
 				err := r.Client.Create(context.TODO(), route)
@@ -338,3 +339,108 @@ func TestReconcileArgoCD_reconcileStatusApplicationSetController(t *testing.T) {
 	assert.NoError(t, r.reconcileStatusApplicationSetController(a))
 	assert.Equal(t, "Pending", a.Status.ApplicationSetController)
 }
+
+func TestReconcileArgoCD_reconcileStatusRedis_remote(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					// Answer every PING sent as a RESP array, ignoring everything else
+					if strings.EqualFold(strings.TrimSpace(line), "PING") {
+						_, _ = conn.Write([]byte("+PONG\r\n"))
+					}
+				}
+			}(conn)
+		}
+	}()
+
+	remote := l.Addr().String()
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.Redis.Remote = &remote
+	})
+
+	resObjs := []client.Object{a}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileStatusRedis(a))
+	assert.Equal(t, "Running", a.Status.Redis)
+
+	// The password secret is missing
+	a.Spec.Redis.RemoteConfig = &argoproj.ArgoCDRemoteRedisSpec{
+		PasswordSecret: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "remote-redis"},
+			Key:                  "password",
+		},
+	}
+	assert.NoError(t, r.reconcileStatusRedis(a))
+	assert.Equal(t, "Failed", a.Status.Redis)
+
+	// The result of the latest probe is reused for the same configuration
+	a.Spec.Redis.RemoteConfig = nil
+	l.Close()
+	assert.NoError(t, r.reconcileStatusRedis(a))
+	assert.Equal(t, "Running", a.Status.Redis)
+
+	r.redisProbes.forget(instanceKey(a))
+	assert.NoError(t, r.reconcileStatusRedis(a))
+	assert.Equal(t, "Failed", a.Status.Redis)
+
+	assert.NoError(t, r.reconcileStatusPhase(a))
+	assert.Equal(t, "Pending", a.Status.Phase)
+
+	// The remote Redis is not probed when rendering
+	r.offline = true
+	assert.NoError(t, r.reconcileStatusRedis(a))
+	assert.Equal(t, "Unknown", a.Status.Redis)
+}
+
+func TestReconcileArgoCD_reconcileStatusResourceCustomizations_observedGeneration(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Generation = 1
+		a.Spec.ResourceHealthChecks = []argoproj.ResourceHealthCheck{
+			{Group: "argoproj.io", Kind: "Rollout", Check: "return {status = "},
+		}
+	})
+
+	resObjs := []client.Object{a}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.reconcileStatusResourceCustomizations(a))
+	assert.Len(t, a.Status.ResourceCustomizationFailures, 1)
+	assert.Equal(t, int64(1), a.Status.ResourceCustomizationsObservedGeneration)
+
+	// the scripts are not validated again for the same generation
+	a.Spec.ResourceHealthChecks[0].Check = `return {status = "Healthy"}`
+	assert.NoError(t, r.reconcileStatusResourceCustomizations(a))
+	assert.Len(t, a.Status.ResourceCustomizationFailures, 1)
+
+	// they are once the spec changes
+	a.Generation = 2
+	assert.NoError(t, r.reconcileStatusResourceCustomizations(a))
+	assert.Empty(t, a.Status.ResourceCustomizationFailures)
+	assert.Equal(t, int64(2), a.Status.ResourceCustomizationsObservedGeneration)
+}
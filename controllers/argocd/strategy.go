@@ -0,0 +1,276 @@
+// Copyright 2019 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argocd
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
+)
+
+const (
+	driftActionCreate = "Create"
+	driftActionUpdate = "Update"
+	driftActionDelete = "Delete"
+)
+
+// getReconcileStrategy returns the reconcile strategy of the given ArgoCD: the one of the reconcile strategy
+// annotation when valid, otherwise the one of the spec, defaulting to Active.
+func getReconcileStrategy(cr *argoproj.ArgoCD) argoproj.ArgoCDReconcileStrategy {
+	if value, ok := cr.Annotations[common.AnnotationReconcileStrategy]; ok {
+		switch strategy := argoproj.ArgoCDReconcileStrategy(value); strategy {
+		case argoproj.ReconcileStrategyActive, argoproj.ReconcileStrategyPaused, argoproj.ReconcileStrategyObserveOnly:
+			return strategy
+		default:
+			log.Info(fmt.Sprintf("ignoring the invalid %s annotation '%s' of ArgoCD %s/%s", common.AnnotationReconcileStrategy, value, cr.Namespace, cr.Name))
+		}
+	}
+	if cr.Spec.ReconcileStrategy != "" {
+		return cr.Spec.ReconcileStrategy
+	}
+	return argoproj.ReconcileStrategyActive
+}
+
+// argoCDComponents are the components of an ArgoCD.
+var argoCDComponents = []argoproj.ArgoCDComponentName{
+	"application-controller",
+	"applicationset-controller",
+	"dex-server",
+	"notifications-controller",
+	"redis",
+	"repo-server",
+	"server",
+}
+
+// componentOf returns the component of the given ArgoCD the named resource belongs to, or an empty name when the
+// resource belongs to no component in particular, such as the argocd-cm ConfigMap. The resources of a component are
+// named after it.
+func componentOf(cr *argoproj.ArgoCD, name string) argoproj.ArgoCDComponentName {
+	for _, component := range argoCDComponents {
+		for _, prefix := range []string{
+			fmt.Sprintf("%s-%s", cr.Name, component),
+			fmt.Sprintf("%s-argocd-%s", cr.Name, component),
+			fmt.Sprintf("%s-%s-argocd-%s", cr.Name, cr.Namespace, component),
+		} {
+			if name == prefix || strings.HasPrefix(name, prefix+"-") {
+				return component
+			}
+		}
+	}
+	return ""
+}
+
+// observeOnly returns true when the changes made through the client of the reconciler are not persisted, but only
+// reported as drift.
+func (r *ReconcileArgoCD) observeOnly() bool {
+	guarded, ok := r.Client.(*guardedClient)
+	return ok && guarded.observeOnly
+}
+
+// guardedClient is a client that does not persist the changes made to the resources it guards, but records them as
+// drift instead. It guards every resource when observeOnly is set, and the resources of the paused components of the
+// ArgoCD otherwise. The ArgoCD itself, as well as its status, is never guarded. It is safe for concurrent use.
+type guardedClient struct {
+	client.Client
+
+	cr          *argoproj.ArgoCD
+	observeOnly bool
+
+	mu    sync.Mutex
+	drift map[string]argoproj.ArgoCDResourceDrift
+}
+
+func newGuardedClient(c client.Client, cr *argoproj.ArgoCD, observeOnly bool) *guardedClient {
+	return &guardedClient{
+		Client:      c,
+		cr:          cr,
+		observeOnly: observeOnly,
+		drift:       make(map[string]argoproj.ArgoCDResourceDrift),
+	}
+}
+
+// guarded returns true when the changes to the given object must not be persisted.
+func (c *guardedClient) guarded(obj client.Object) bool {
+	if _, ok := obj.(*argoproj.ArgoCD); ok {
+		return false
+	}
+	if c.observeOnly {
+		return true
+	}
+	component := componentOf(c.cr, obj.GetName())
+	for _, paused := range c.cr.Spec.PausedComponents {
+		if component == paused {
+			return true
+		}
+	}
+	return false
+}
+
+// record adds the given change of obj to the drift.
+func (c *guardedClient) record(obj client.Object, action string) {
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+	if gvk, err := apiutil.GVKForObject(obj, c.Scheme()); err == nil {
+		kind = gvk.Kind
+	}
+	drift := argoproj.ArgoCDResourceDrift{Kind: kind, Namespace: obj.GetNamespace(), Name: obj.GetName(), Action: action}
+	log.Info(fmt.Sprintf("%s %s/%s of ArgoCD %s/%s drifted, skipping %s", kind, drift.Namespace, drift.Name, c.cr.Namespace, c.cr.Name, strings.ToLower(action)))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := fmt.Sprintf("%s/%s/%s", kind, drift.Namespace, drift.Name)
+	// a resource created then updated during the same reconciliation is still to be created
+	if existing, ok := c.drift[key]; ok && existing.Action == driftActionCreate && action == driftActionUpdate {
+		return
+	}
+	c.drift[key] = drift
+}
+
+// getDrift returns the recorded drift, sorted by kind, namespace and name.
+func (c *guardedClient) getDrift() []argoproj.ArgoCDResourceDrift {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	drift := make([]argoproj.ArgoCDResourceDrift, 0, len(c.drift))
+	for _, d := range c.drift {
+		drift = append(drift, d)
+	}
+	sort.Slice(drift, func(i, j int) bool {
+		if drift[i].Kind != drift[j].Kind {
+			return drift[i].Kind < drift[j].Kind
+		}
+		if drift[i].Namespace != drift[j].Namespace {
+			return drift[i].Namespace < drift[j].Namespace
+		}
+		return drift[i].Name < drift[j].Name
+	})
+	return drift
+}
+
+// Create validates the creation of a guarded object with a dry-run and records it.
+func (c *guardedClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if !c.guarded(obj) {
+		return c.Client.Create(ctx, obj, opts...)
+	}
+	if _, ok := obj.(*corev1.Event); ok {
+		// the events of the operator are changes too, they are dropped
+		return nil
+	}
+	if err := c.Client.Create(ctx, obj, append(opts, client.DryRunAll)...); err != nil {
+		return err
+	}
+	c.record(obj, driftActionCreate)
+	return nil
+}
+
+// Update validates the update of a guarded object with a dry-run and records it when it changes the object.
+func (c *guardedClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if !c.guarded(obj) {
+		return c.Client.Update(ctx, obj, opts...)
+	}
+	live, err := getLiveObject(ctx, c.Client, obj)
+	if err != nil {
+		return err
+	}
+	if err := c.Client.Update(ctx, obj, append(opts, client.DryRunAll)...); err != nil {
+		return err
+	}
+	return c.recordUpdate(live, obj)
+}
+
+// Patch validates the patch of a guarded object with a dry-run and records it when it changes the object. An apply
+// patch of a missing object is recorded as a creation.
+func (c *guardedClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if !c.guarded(obj) {
+		return c.Client.Patch(ctx, obj, patch, opts...)
+	}
+	live, err := getLiveObject(ctx, c.Client, obj)
+	if apierrors.IsNotFound(err) && patch.Type() == client.Apply.Type() {
+		if err := c.Client.Patch(ctx, obj, patch, append(opts, client.DryRunAll)...); err != nil {
+			return err
+		}
+		c.record(obj, driftActionCreate)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if err := c.Client.Patch(ctx, obj, patch, append(opts, client.DryRunAll)...); err != nil {
+		return err
+	}
+	return c.recordUpdate(live, obj)
+}
+
+// Delete validates the deletion of a guarded object with a dry-run and records it.
+func (c *guardedClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	if !c.guarded(obj) {
+		return c.Client.Delete(ctx, obj, opts...)
+	}
+	if err := c.Client.Delete(ctx, obj, append(opts, client.DryRunAll)...); err != nil {
+		return err
+	}
+	c.record(obj, driftActionDelete)
+	return nil
+}
+
+// DeleteAllOf deletes nothing when observing only, the objects it would delete are unknown.
+func (c *guardedClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	if c.observeOnly {
+		log.Info(fmt.Sprintf("skipping the deletion of the %T objects of ArgoCD %s/%s", obj, c.cr.Namespace, c.cr.Name))
+		return nil
+	}
+	return c.Client.DeleteAllOf(ctx, obj, opts...)
+}
+
+// recordUpdate records the update of live into updated when they differ, ignoring the metadata maintained by the
+// API server and the status.
+func (c *guardedClient) recordUpdate(live, updated client.Object) error {
+	liveContent, err := driftComparable(live)
+	if err != nil {
+		return err
+	}
+	updatedContent, err := driftComparable(updated)
+	if err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(liveContent, updatedContent) {
+		c.record(updated, driftActionUpdate)
+	}
+	return nil
+}
+
+func driftComparable(obj client.Object) (map[string]interface{}, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range []string{"apiVersion", "kind", "status"} {
+		delete(content, field)
+	}
+	for _, field := range []string{"managedFields", "resourceVersion", "generation", "creationTimestamp"} {
+		unstructured.RemoveNestedField(content, "metadata", field)
+	}
+	return content, nil
+}
@@ -0,0 +1,192 @@
+// Copyright 2019 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argocd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
+)
+
+func makeTestStrategyReconciler(t *testing.T, a *argoproj.ArgoCD) (*ReconcileArgoCD, reconcile.Request) {
+	resObjs := []client.Object{a}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+	assert.NoError(t, createNamespace(r, a.Namespace, ""))
+
+	return r, reconcile.Request{NamespacedName: types.NamespacedName{Name: a.Name, Namespace: a.Namespace}}
+}
+
+// setTestDeploymentImage changes the image of the first container of the named deployment, as a user would.
+func setTestDeploymentImage(t *testing.T, r *ReconcileArgoCD, name, image string) {
+	deploy := &appsv1.Deployment{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: testNamespace}, deploy))
+	deploy.Spec.Template.Spec.Containers[0].Image = image
+	assert.NoError(t, r.Client.Update(context.TODO(), deploy))
+}
+
+func getTestDeploymentImage(t *testing.T, r *ReconcileArgoCD, name string) string {
+	deploy := &appsv1.Deployment{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: testNamespace}, deploy))
+	return deploy.Spec.Template.Spec.Containers[0].Image
+}
+
+func TestGetReconcileStrategy(t *testing.T) {
+	tests := []struct {
+		name       string
+		annotation string
+		spec       argoproj.ArgoCDReconcileStrategy
+		want       argoproj.ArgoCDReconcileStrategy
+	}{
+		{"default", "", "", argoproj.ReconcileStrategyActive},
+		{"spec", "", argoproj.ReconcileStrategyObserveOnly, argoproj.ReconcileStrategyObserveOnly},
+		{"annotation overrides spec", "Paused", argoproj.ReconcileStrategyObserveOnly, argoproj.ReconcileStrategyPaused},
+		{"annotation overrides spec with default", "Active", argoproj.ReconcileStrategyPaused, argoproj.ReconcileStrategyActive},
+		{"invalid annotation is ignored", "Stopped", argoproj.ReconcileStrategyObserveOnly, argoproj.ReconcileStrategyObserveOnly},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+				a.Spec.ReconcileStrategy = test.spec
+				if test.annotation != "" {
+					a.Annotations = map[string]string{common.AnnotationReconcileStrategy: test.annotation}
+				}
+			})
+			assert.Equal(t, test.want, getReconcileStrategy(a))
+		})
+	}
+}
+
+func TestReconcileArgoCD_Reconcile_paused(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.ReconcileStrategy = argoproj.ReconcileStrategyPaused
+	})
+	r, req := makeTestStrategyReconciler(t, a)
+
+	_, err := r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+
+	// nothing is created
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: "argocd-redis", Namespace: testNamespace}, &appsv1.Deployment{})
+	assert.True(t, apierrors.IsNotFound(err))
+
+	assert.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, a))
+	assert.Equal(t, argoproj.ReconcileStrategyPaused, a.Status.ReconcileStrategy)
+	assert.Empty(t, a.Status.Drift)
+
+	// resuming the instance reconciles it
+	a.Spec.ReconcileStrategy = argoproj.ReconcileStrategyActive
+	assert.NoError(t, r.Client.Update(context.TODO(), a))
+	_, err = r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "argocd-redis", Namespace: testNamespace}, &appsv1.Deployment{}))
+
+	assert.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, a))
+	assert.Equal(t, argoproj.ReconcileStrategyActive, a.Status.ReconcileStrategy)
+}
+
+func TestReconcileArgoCD_Reconcile_observeOnly(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD()
+	r, req := makeTestStrategyReconciler(t, a)
+
+	_, err := r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+	wantImage := getTestDeploymentImage(t, r, "argocd-server")
+	setTestDeploymentImage(t, r, "argocd-server", "manual:latest")
+
+	assert.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, a))
+	a.Annotations = map[string]string{common.AnnotationReconcileStrategy: string(argoproj.ReconcileStrategyObserveOnly)}
+	a.Spec.Repo.Replicas = int32Ptr(3)
+	assert.NoError(t, r.Client.Update(context.TODO(), a))
+
+	_, err = r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+
+	// the manual change is kept, and so is the repo server
+	assert.Equal(t, "manual:latest", getTestDeploymentImage(t, r, "argocd-server"))
+	repo := &appsv1.Deployment{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "argocd-repo-server", Namespace: testNamespace}, repo))
+	assert.Nil(t, repo.Spec.Replicas)
+
+	// but the drift is reported
+	assert.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, a))
+	assert.Equal(t, argoproj.ReconcileStrategyObserveOnly, a.Status.ReconcileStrategy)
+	assert.Contains(t, a.Status.Drift, argoproj.ArgoCDResourceDrift{Kind: "Deployment", Namespace: testNamespace, Name: "argocd-server", Action: "Update"})
+	assert.Contains(t, a.Status.Drift, argoproj.ArgoCDResourceDrift{Kind: "Deployment", Namespace: testNamespace, Name: "argocd-repo-server", Action: "Update"})
+
+	events := &corev1.EventList{}
+	assert.NoError(t, r.Client.List(context.TODO(), events, client.InNamespace(testNamespace)))
+	reasons := []string{}
+	for _, event := range events.Items {
+		reasons = append(reasons, event.Reason)
+	}
+	assert.Contains(t, reasons, "DriftDetected")
+	assert.Contains(t, reasons, "ReconcileStrategyChanged")
+
+	// back to active, the drift is corrected
+	a.Annotations = nil
+	assert.NoError(t, r.Client.Update(context.TODO(), a))
+	_, err = r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+
+	assert.Equal(t, wantImage, getTestDeploymentImage(t, r, "argocd-server"))
+	assert.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, a))
+	assert.Equal(t, argoproj.ReconcileStrategyActive, a.Status.ReconcileStrategy)
+	assert.Empty(t, a.Status.Drift)
+}
+
+func TestReconcileArgoCD_Reconcile_pausedComponents(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD()
+	r, req := makeTestStrategyReconciler(t, a)
+
+	_, err := r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+	wantImage := getTestDeploymentImage(t, r, "argocd-server")
+	setTestDeploymentImage(t, r, "argocd-server", "manual:latest")
+	setTestDeploymentImage(t, r, "argocd-repo-server", "manual:latest")
+
+	assert.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, a))
+	a.Spec.PausedComponents = []argoproj.ArgoCDComponentName{"repo-server"}
+	assert.NoError(t, r.Client.Update(context.TODO(), a))
+
+	_, err = r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+
+	// only the repo server is left untouched
+	assert.Equal(t, "manual:latest", getTestDeploymentImage(t, r, "argocd-repo-server"))
+	assert.Equal(t, wantImage, getTestDeploymentImage(t, r, "argocd-server"))
+
+	assert.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, a))
+	assert.Equal(t, argoproj.ReconcileStrategyActive, a.Status.ReconcileStrategy)
+	assert.Equal(t, []argoproj.ArgoCDResourceDrift{{Kind: "Deployment", Namespace: testNamespace, Name: "argocd-repo-server", Action: "Update"}}, a.Status.Drift)
+}
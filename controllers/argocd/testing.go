@@ -32,10 +32,10 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
+	"github.com/argoproj-labs/argocd-operator/controllers/inmemory"
 
 	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
 )
@@ -54,13 +54,16 @@ type SchemeOpt func(*runtime.Scheme) error
 
 func makeTestReconciler(client client.Client, sch *runtime.Scheme) *ReconcileArgoCD {
 	return &ReconcileArgoCD{
-		Client: client,
-		Scheme: sch,
+		Client:      client,
+		Scheme:      sch,
+		ClusterAPIs: &ClusterAPIs{},
+		instances:   newInstanceTracker(),
+		redisProbes: newRedisProbeCache(),
 	}
 }
 
 func makeTestReconcilerClient(sch *runtime.Scheme, resObjs, subresObjs []client.Object, runtimeObj []runtime.Object) client.Client {
-	client := fake.NewClientBuilder().WithScheme(sch)
+	client := inmemory.NewClientBuilder().WithScheme(sch)
 	if len(resObjs) > 0 {
 		client = client.WithObjects(resObjs...)
 	}
@@ -0,0 +1,504 @@
+// Copyright 2019 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argocd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/semver"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/common"
+	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
+)
+
+const (
+	// upgradeDefaultStepTimeout is the default time a component has to become ready once upgraded.
+	upgradeDefaultStepTimeout = 10 * time.Minute
+
+	// upgradeRequeueInterval is the interval at which an ArgoCD is reconciled while it is upgraded.
+	upgradeRequeueInterval = 10 * time.Second
+)
+
+// upgradeSteps are the components in the order they are upgraded: the ones others depend on first.
+var upgradeSteps = []argoproj.ArgoCDComponentName{
+	"redis",
+	"repo-server",
+	"application-controller",
+	"server",
+	"applicationset-controller",
+	"notifications-controller",
+	"dex-server",
+}
+
+// getArgoVersion returns the Argo CD version of the given ArgoCD, resolving the default one.
+func getArgoVersion(cr *argoproj.ArgoCD) string {
+	if cr.Spec.Version == "" {
+		return common.ArgoCDDefaultArgoVersion
+	}
+	return cr.Spec.Version
+}
+
+// withArgoVersion returns a copy of the given ArgoCD running the given Argo CD version.
+func withArgoVersion(cr *argoproj.ArgoCD, version string) *argoproj.ArgoCD {
+	versioned := cr.DeepCopy()
+	versioned.Spec.Version = version
+	if version == common.ArgoCDDefaultArgoVersion {
+		// keep the default version unset, for the image of the environment to still apply
+		versioned.Spec.Version = ""
+	}
+	return versioned
+}
+
+// argoCDForUpgradeStep returns the given ArgoCD as seen by the given component during an upgrade: the components that
+// are not upgraded yet, or while the upgrade is checked or rolled back, run the version they ran before the upgrade.
+func argoCDForUpgradeStep(cr *argoproj.ArgoCD, component argoproj.ArgoCDComponentName) *argoproj.ArgoCD {
+	upgrade := cr.Status.Upgrade
+	if upgrade == nil || getArgoVersion(cr) != upgrade.ToVersion {
+		return cr
+	}
+	switch upgrade.Phase {
+	case argoproj.UpgradePhasePreFlight, argoproj.UpgradePhaseRollingBack, argoproj.UpgradePhaseRolledBack, argoproj.UpgradePhaseFailed:
+		return withArgoVersion(cr, upgrade.FromVersion)
+	case argoproj.UpgradePhaseRollingOut:
+		if upgradeStepIndex(component) > upgradeStepIndex(upgrade.Step) {
+			return withArgoVersion(cr, upgrade.FromVersion)
+		}
+	}
+	return cr
+}
+
+func upgradeStepIndex(component argoproj.ArgoCDComponentName) int {
+	for i, step := range upgradeSteps {
+		if step == component {
+			return i
+		}
+	}
+	return len(upgradeSteps)
+}
+
+// isUpgradeInProgress returns true when the Argo CD version of the given ArgoCD is being upgraded or rolled back.
+func isUpgradeInProgress(cr *argoproj.ArgoCD) bool {
+	if cr.Status.Upgrade == nil {
+		return false
+	}
+	switch cr.Status.Upgrade.Phase {
+	case argoproj.UpgradePhasePreFlight, argoproj.UpgradePhaseRollingOut, argoproj.UpgradePhaseRollingBack:
+		return true
+	}
+	return false
+}
+
+// getUpgradeStepTimeout returns the time a component of the given ArgoCD has to become ready once upgraded.
+func getUpgradeStepTimeout(cr *argoproj.ArgoCD) time.Duration {
+	if cr.Spec.Upgrade.StepTimeout != nil && cr.Spec.Upgrade.StepTimeout.Duration > 0 {
+		return cr.Spec.Upgrade.StepTimeout.Duration
+	}
+	return upgradeDefaultStepTimeout
+}
+
+// isUpgradeStepTimedOut returns true when the current phase or step of the upgrade of the given ArgoCD exceeded its
+// timeout.
+func isUpgradeStepTimedOut(cr *argoproj.ArgoCD) bool {
+	start := cr.Status.Upgrade.StepStartTime
+	return start != nil && time.Since(start.Time) > getUpgradeStepTimeout(cr)
+}
+
+// checkUpgradeCompatibility returns an error when the components cannot be upgraded from one version to the other.
+// Only the semantic versions are checked, the other tags and the digests are assumed compatible.
+func checkUpgradeCompatibility(from, to string) error {
+	if !semver.IsValid(from) || !semver.IsValid(to) {
+		return nil
+	}
+	if semver.Major(from) != semver.Major(to) {
+		return fmt.Errorf("upgrading from %s to %s crosses a major version, the components must be migrated manually", from, to)
+	}
+	if semver.Compare(semver.MajorMinor(to), semver.MajorMinor(from)) < 0 {
+		return fmt.Errorf("downgrading from %s to %s is not supported, only patch versions can be downgraded", from, to)
+	}
+	return nil
+}
+
+// getUpgradeStepWorkloads returns the workloads of the given component, that are ready once it is upgraded, and
+// whether they run the Argo CD image.
+func getUpgradeStepWorkloads(cr *argoproj.ArgoCD, component argoproj.ArgoCDComponentName) ([]client.Object, bool) {
+	switch component {
+	case "redis":
+		return getRedisPasswordWorkloads(cr), false
+	case "repo-server":
+		return []client.Object{newDeploymentWithSuffix("repo-server", "repo-server", cr)}, true
+	case "application-controller":
+		return []client.Object{newStatefulSetWithSuffix("application-controller", "application-controller", cr)}, true
+	case "server":
+		return []client.Object{newDeploymentWithSuffix("server", "server", cr)}, true
+	case "applicationset-controller":
+		return []client.Object{newDeploymentWithSuffix("applicationset-controller", "controller", cr)}, false
+	case "notifications-controller":
+		return []client.Object{newDeploymentWithSuffix("notifications-controller", "controller", cr)}, true
+	case "dex-server":
+		return []client.Object{newDeploymentWithSuffix("dex-server", "dex-server", cr)}, true
+	}
+	return nil, false
+}
+
+// isUpgradeStepReady returns true when the workloads of the given component that exist run the given image, if they
+// run the Argo CD image, and completed their rollout.
+func (r *ReconcileArgoCD) isUpgradeStepReady(cr *argoproj.ArgoCD, component argoproj.ArgoCDComponentName, image string) bool {
+	workloads, versioned := getUpgradeStepWorkloads(cr, component)
+	if !r.areWorkloadsRolledOut(workloads) {
+		return false
+	}
+	if !versioned {
+		return true
+	}
+	for _, obj := range workloads {
+		var podSpec *corev1.PodSpec
+		switch res := obj.(type) {
+		case *appsv1.Deployment:
+			podSpec = &res.Spec.Template.Spec
+		case *appsv1.StatefulSet:
+			podSpec = &res.Spec.Template.Spec
+		}
+		// the workload is missing when its resource version is unset
+		if podSpec == nil || obj.GetResourceVersion() == "" {
+			continue
+		}
+		if !podSpecUsesImage(podSpec, image) {
+			return false
+		}
+	}
+	return true
+}
+
+func podSpecUsesImage(podSpec *corev1.PodSpec, image string) bool {
+	for _, container := range append(podSpec.InitContainers, podSpec.Containers...) {
+		if container.Image == image {
+			return true
+		}
+	}
+	return false
+}
+
+// getImageVersion returns the tag or the digest of the given image reference, if any.
+func getImageVersion(image string) string {
+	if i := strings.LastIndex(image, "@"); i >= 0 {
+		return image[i+1:]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[i+1:]
+	}
+	return ""
+}
+
+// getRunningArgoVersion returns the Argo CD version run by the existing workloads of the given ArgoCD, when they run
+// the Argo CD image of the ArgoCD.
+func (r *ReconcileArgoCD) getRunningArgoVersion(cr *argoproj.ArgoCD) (string, bool) {
+	for _, component := range []argoproj.ArgoCDComponentName{"server", "application-controller", "repo-server"} {
+		workloads, _ := getUpgradeStepWorkloads(cr, component)
+		for _, obj := range workloads {
+			if !argoutil.IsObjectFound(r.Client, cr.Namespace, obj.GetName(), obj) {
+				continue
+			}
+			var podSpec *corev1.PodSpec
+			switch res := obj.(type) {
+			case *appsv1.Deployment:
+				podSpec = &res.Spec.Template.Spec
+			case *appsv1.StatefulSet:
+				podSpec = &res.Spec.Template.Spec
+			}
+			for _, container := range podSpec.Containers {
+				version := getImageVersion(container.Image)
+				if version != "" && getArgoContainerImage(withArgoVersion(cr, version)) == container.Image {
+					return version, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// newUpgradeProbePod returns the Pod checking that the Argo CD image of the given version can be pulled and run.
+func newUpgradeProbePod(cr *argoproj.ArgoCD, version string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-upgrade-probe", cr.Name),
+			Namespace: cr.Namespace,
+			Labels:    argoutil.LabelsForCluster(cr),
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:            "probe",
+				Image:           getArgoContainerImage(withArgoVersion(cr, version)),
+				Command:         []string{"argocd", "version", "--client"},
+				ImagePullPolicy: corev1.PullAlways,
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("10m"),
+						corev1.ResourceMemory: resource.MustParse("32Mi"),
+					},
+					Limits: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("100m"),
+						corev1.ResourceMemory: resource.MustParse("128Mi"),
+					},
+				},
+				SecurityContext: &corev1.SecurityContext{
+					AllowPrivilegeEscalation: boolPtr(false),
+					Capabilities: &corev1.Capabilities{
+						Drop: []corev1.Capability{
+							"ALL",
+						},
+					},
+					RunAsNonRoot: boolPtr(true),
+					SeccompProfile: &corev1.SeccompProfile{
+						Type: "RuntimeDefault",
+					},
+				},
+			}},
+			RestartPolicy: corev1.RestartPolicyNever,
+		},
+	}
+}
+
+// getImagePullFailure returns the reason why the image of the given Pod cannot be pulled, if any.
+func getImagePullFailure(pod *corev1.Pod) string {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Waiting == nil {
+			continue
+		}
+		switch status.State.Waiting.Reason {
+		case "ErrImagePull", "ImagePullBackOff", "InvalidImageName", "ErrImageNeverPull":
+			return fmt.Sprintf("%s: %s", status.State.Waiting.Reason, status.State.Waiting.Message)
+		}
+	}
+	return ""
+}
+
+// reconcileUpgrade orchestrates the upgrades of the Argo CD version of the given ArgoCD. A change of version is
+// checked first, with a probe Pod pulling the new image, then rolled out to one component at a time in the order of
+// upgradeSteps, each component becoming ready before the next one is upgraded. A component that is not ready within
+// the step timeout rolls every component back to the previous version.
+func (r *ReconcileArgoCD) reconcileUpgrade(cr *argoproj.ArgoCD) error {
+	if r.offline || r.observeOnly() {
+		// the probe Pod never runs, and the workloads never become ready
+		return nil
+	}
+
+	version := getArgoVersion(cr)
+	upgrade := cr.Status.Upgrade
+	if cr.Status.Version == "" {
+		// the first reconciliation, or the first one of an instance created before the upgrades were orchestrated,
+		// whose components are upgraded from the version they already run
+		cr.Status.Version = version
+		if running, ok := r.getRunningArgoVersion(cr); ok {
+			cr.Status.Version = running
+		}
+		if err := r.Client.Status().Update(context.TODO(), cr); err != nil {
+			return err
+		}
+	}
+
+	if !isUpgradeInProgress(cr) {
+		if version == cr.Status.Version || (upgrade != nil && upgrade.ToVersion == version) {
+			// up to date, or the upgrade to this version already failed
+			return nil
+		}
+		return r.startUpgrade(cr, cr.Status.Version, version)
+	}
+
+	if version != upgrade.ToVersion {
+		if version == upgrade.FromVersion {
+			return r.rollBackUpgrade(cr, fmt.Sprintf("the upgrade to %s was canceled", upgrade.ToVersion))
+		}
+		// the components run the previous version until the new one is checked
+		return r.startUpgrade(cr, upgrade.FromVersion, version)
+	}
+
+	switch upgrade.Phase {
+	case argoproj.UpgradePhasePreFlight:
+		return r.reconcileUpgradePreFlight(cr)
+	case argoproj.UpgradePhaseRollingOut:
+		return r.reconcileUpgradeRollout(cr)
+	case argoproj.UpgradePhaseRollingBack:
+		return r.reconcileUpgradeRollback(cr)
+	}
+	return nil
+}
+
+// startUpgrade starts the upgrade of the given ArgoCD from one version to the other with the pre-flight checks.
+func (r *ReconcileArgoCD) startUpgrade(cr *argoproj.ArgoCD, from, to string) error {
+	log.Info(fmt.Sprintf("upgrading argocd %s in namespace %s from %s to %s", cr.Name, cr.Namespace, from, to))
+	now := metav1.Now()
+	cr.Status.Upgrade = &argoproj.ArgoCDUpgradeStatus{
+		Phase:         argoproj.UpgradePhasePreFlight,
+		FromVersion:   from,
+		ToVersion:     to,
+		StepStartTime: &now,
+	}
+	if err := r.Client.Status().Update(context.TODO(), cr); err != nil {
+		return err
+	}
+	return r.reconcileUpgradePreFlight(cr)
+}
+
+// reconcileUpgradePreFlight checks that the components of the given ArgoCD can be upgraded to the new version, and
+// that its image can be pulled and run, before rolling it out.
+func (r *ReconcileArgoCD) reconcileUpgradePreFlight(cr *argoproj.ArgoCD) error {
+	upgrade := cr.Status.Upgrade
+	if err := checkUpgradeCompatibility(upgrade.FromVersion, upgrade.ToVersion); err != nil {
+		return r.failUpgrade(cr, err.Error())
+	}
+
+	probe := newUpgradeProbePod(cr, upgrade.ToVersion)
+	existing := &corev1.Pod{}
+	if !argoutil.IsObjectFound(r.Client, probe.Namespace, probe.Name, existing) {
+		if err := controllerutil.SetControllerReference(cr, probe, r.Scheme); err != nil {
+			return err
+		}
+		log.Info(fmt.Sprintf("checking the image %s for the upgrade of argocd %s in namespace %s", probe.Spec.Containers[0].Image, cr.Name, cr.Namespace))
+		if err := r.Client.Create(context.TODO(), probe); err != nil {
+			// such as a Pod rejected by the Pod Security admission or a ResourceQuota of the namespace
+			return r.failUpgrade(cr, fmt.Sprintf("the probe checking the image %s cannot be created, %v", probe.Spec.Containers[0].Image, err))
+		}
+		return nil
+	}
+	if len(existing.Spec.Containers) == 0 || existing.Spec.Containers[0].Image != probe.Spec.Containers[0].Image {
+		// the probe of a previous upgrade, it is created again once deleted
+		return r.Client.Delete(context.TODO(), existing)
+	}
+
+	var failure string
+	switch {
+	case existing.Status.Phase == corev1.PodSucceeded:
+	case getImagePullFailure(existing) != "":
+		failure = fmt.Sprintf("the image %s cannot be pulled, %s", probe.Spec.Containers[0].Image, getImagePullFailure(existing))
+	case existing.Status.Phase == corev1.PodFailed:
+		failure = fmt.Sprintf("the image %s failed to run", probe.Spec.Containers[0].Image)
+	case isUpgradeStepTimedOut(cr):
+		failure = fmt.Sprintf("the image %s was not pulled and run within %s", probe.Spec.Containers[0].Image, getUpgradeStepTimeout(cr))
+	default:
+		return nil // the probe is still running, check again on the next reconciliation
+	}
+	if err := r.Client.Delete(context.TODO(), existing); err != nil {
+		return err
+	}
+	if failure != "" {
+		return r.failUpgrade(cr, failure)
+	}
+
+	log.Info(fmt.Sprintf("pre-flight checks passed, rolling out %s to argocd %s in namespace %s", upgrade.ToVersion, cr.Name, cr.Namespace))
+	return r.startUpgradeStep(cr, upgradeSteps[0])
+}
+
+// startUpgradeStep starts the upgrade of the given component.
+func (r *ReconcileArgoCD) startUpgradeStep(cr *argoproj.ArgoCD, component argoproj.ArgoCDComponentName) error {
+	now := metav1.Now()
+	cr.Status.Upgrade.Phase = argoproj.UpgradePhaseRollingOut
+	cr.Status.Upgrade.Step = component
+	cr.Status.Upgrade.StepStartTime = &now
+	return r.Client.Status().Update(context.TODO(), cr)
+}
+
+// reconcileUpgradeRollout moves the upgrade of the given ArgoCD to the next component once the current one is ready,
+// and rolls it back when the current one is not ready in time.
+func (r *ReconcileArgoCD) reconcileUpgradeRollout(cr *argoproj.ArgoCD) error {
+	upgrade := cr.Status.Upgrade
+	if !r.isUpgradeStepReady(cr, upgrade.Step, getArgoContainerImage(withArgoVersion(cr, upgrade.ToVersion))) {
+		if isUpgradeStepTimedOut(cr) {
+			return r.rollBackUpgrade(cr, fmt.Sprintf("the %s component was not ready within %s", upgrade.Step, getUpgradeStepTimeout(cr)))
+		}
+		return nil // the component is still rolling out, check again on the next reconciliation
+	}
+
+	if next := upgradeStepIndex(upgrade.Step) + 1; next < len(upgradeSteps) {
+		log.Info(fmt.Sprintf("%s upgraded to %s, upgrading %s of argocd %s in namespace %s", upgrade.Step, upgrade.ToVersion, upgradeSteps[next], cr.Name, cr.Namespace))
+		return r.startUpgradeStep(cr, upgradeSteps[next])
+	}
+
+	log.Info(fmt.Sprintf("argocd %s in namespace %s upgraded to %s", cr.Name, cr.Namespace, upgrade.ToVersion))
+	upgrade.Phase = argoproj.UpgradePhaseCompleted
+	upgrade.Step = ""
+	upgrade.StepStartTime = nil
+	cr.Status.Version = upgrade.ToVersion
+	if err := r.Client.Status().Update(context.TODO(), cr); err != nil {
+		return err
+	}
+	r.reportUpgrade(cr, corev1.EventTypeNormal, "UpgradeCompleted", fmt.Sprintf("upgraded from %s to %s", upgrade.FromVersion, upgrade.ToVersion))
+	return nil
+}
+
+// rollBackUpgrade returns every component of the given ArgoCD to the version they ran before the upgrade.
+func (r *ReconcileArgoCD) rollBackUpgrade(cr *argoproj.ArgoCD, message string) error {
+	log.Info(fmt.Sprintf("rolling back the upgrade of argocd %s in namespace %s to %s: %s", cr.Name, cr.Namespace, cr.Status.Upgrade.FromVersion, message))
+	now := metav1.Now()
+	cr.Status.Upgrade.Phase = argoproj.UpgradePhaseRollingBack
+	cr.Status.Upgrade.StepStartTime = &now
+	cr.Status.Upgrade.Message = message
+	if err := r.Client.Status().Update(context.TODO(), cr); err != nil {
+		return err
+	}
+	r.reportUpgrade(cr, corev1.EventTypeWarning, "UpgradeRollingBack", fmt.Sprintf("rolling back to %s, %s", cr.Status.Upgrade.FromVersion, message))
+	return nil
+}
+
+// reconcileUpgradeRollback completes the rollback of the given ArgoCD once every component is ready again.
+func (r *ReconcileArgoCD) reconcileUpgradeRollback(cr *argoproj.ArgoCD) error {
+	upgrade := cr.Status.Upgrade
+	image := getArgoContainerImage(withArgoVersion(cr, upgrade.FromVersion))
+	for _, component := range upgradeSteps {
+		if !r.isUpgradeStepReady(cr, component, image) {
+			return nil // the components are still rolling back, check again on the next reconciliation
+		}
+	}
+
+	log.Info(fmt.Sprintf("argocd %s in namespace %s rolled back to %s", cr.Name, cr.Namespace, upgrade.FromVersion))
+	upgrade.Phase = argoproj.UpgradePhaseRolledBack
+	upgrade.Step = ""
+	upgrade.StepStartTime = nil
+	if err := r.Client.Status().Update(context.TODO(), cr); err != nil {
+		return err
+	}
+	r.reportUpgrade(cr, corev1.EventTypeWarning, "UpgradeRolledBack", fmt.Sprintf("rolled back from %s to %s, %s", upgrade.ToVersion, upgrade.FromVersion, upgrade.Message))
+	return nil
+}
+
+// failUpgrade stops the upgrade of the given ArgoCD before any component was upgraded.
+func (r *ReconcileArgoCD) failUpgrade(cr *argoproj.ArgoCD, message string) error {
+	log.Info(fmt.Sprintf("the upgrade of argocd %s in namespace %s to %s failed its pre-flight checks: %s", cr.Name, cr.Namespace, cr.Status.Upgrade.ToVersion, message))
+	cr.Status.Upgrade.Phase = argoproj.UpgradePhaseFailed
+	cr.Status.Upgrade.StepStartTime = nil
+	cr.Status.Upgrade.Message = message
+	if err := r.Client.Status().Update(context.TODO(), cr); err != nil {
+		return err
+	}
+	r.reportUpgrade(cr, corev1.EventTypeWarning, "UpgradeFailed", fmt.Sprintf("the upgrade to %s failed, %s", cr.Status.Upgrade.ToVersion, message))
+	return nil
+}
+
+// reportUpgrade reports the progress of the upgrade of the given ArgoCD through an event.
+func (r *ReconcileArgoCD) reportUpgrade(cr *argoproj.ArgoCD, eventType, reason, message string) {
+	typeMeta := metav1.TypeMeta{Kind: "ArgoCD", APIVersion: argoproj.GroupVersion.String()}
+	if err := argoutil.CreateEvent(r.Client, eventType, "Upgrade", message, reason, cr.ObjectMeta, typeMeta); err != nil {
+		log.Error(err, fmt.Sprintf("failed to report the upgrade of ArgoCD %s/%s", cr.Namespace, cr.Name))
+	}
+}
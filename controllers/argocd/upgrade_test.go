@@ -0,0 +1,356 @@
+// Copyright 2019 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argocd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+)
+
+// setTestWorkloadsReady marks the Deployments and StatefulSets of the test namespace as rolled out.
+func setTestWorkloadsReady(t *testing.T, r *ReconcileArgoCD) {
+	deployments := &appsv1.DeploymentList{}
+	assert.NoError(t, r.Client.List(context.TODO(), deployments, client.InNamespace(testNamespace)))
+	for i := range deployments.Items {
+		deploy := &deployments.Items[i]
+		replicas := int32(1)
+		if deploy.Spec.Replicas != nil {
+			replicas = *deploy.Spec.Replicas
+		}
+		deploy.Status = appsv1.DeploymentStatus{ObservedGeneration: deploy.Generation, Replicas: replicas, UpdatedReplicas: replicas, ReadyReplicas: replicas}
+		assert.NoError(t, r.Client.Status().Update(context.TODO(), deploy))
+	}
+	statefulSets := &appsv1.StatefulSetList{}
+	assert.NoError(t, r.Client.List(context.TODO(), statefulSets, client.InNamespace(testNamespace)))
+	for i := range statefulSets.Items {
+		ss := &statefulSets.Items[i]
+		replicas := int32(1)
+		if ss.Spec.Replicas != nil {
+			replicas = *ss.Spec.Replicas
+		}
+		ss.Status = appsv1.StatefulSetStatus{ObservedGeneration: ss.Generation, Replicas: replicas, UpdatedReplicas: replicas, ReadyReplicas: replicas, CurrentRevision: "1", UpdateRevision: "1"}
+		assert.NoError(t, r.Client.Status().Update(context.TODO(), ss))
+	}
+}
+
+// setTestDeploymentNotReady marks the named Deployment as rolling out.
+func setTestDeploymentNotReady(t *testing.T, r *ReconcileArgoCD, name string) {
+	deploy := &appsv1.Deployment{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: testNamespace}, deploy))
+	deploy.Status.ReadyReplicas = 0
+	assert.NoError(t, r.Client.Status().Update(context.TODO(), deploy))
+}
+
+func getTestUpgradeProbe(t *testing.T, r *ReconcileArgoCD) *corev1.Pod {
+	probe := &corev1.Pod{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "argocd-upgrade-probe", Namespace: testNamespace}, probe))
+	return probe
+}
+
+func getTestStatefulSetImage(t *testing.T, r *ReconcileArgoCD, name string) string {
+	ss := &appsv1.StatefulSet{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: testNamespace}, ss))
+	return ss.Spec.Template.Spec.Containers[0].Image
+}
+
+func getTestEventReasons(t *testing.T, r *ReconcileArgoCD) []string {
+	events := &corev1.EventList{}
+	assert.NoError(t, r.Client.List(context.TODO(), events, client.InNamespace(testNamespace)))
+	reasons := []string{}
+	for _, event := range events.Items {
+		reasons = append(reasons, event.Reason)
+	}
+	return reasons
+}
+
+// startTestUpgrade reconciles the given ArgoCD at version v2.10.0, then requests its upgrade to v2.10.1.
+func startTestUpgrade(t *testing.T) (*ReconcileArgoCD, reconcile.Request) {
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.Version = "v2.10.0"
+	})
+	r, req := makeTestStrategyReconciler(t, a)
+
+	_, err := r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, a))
+	assert.Equal(t, "v2.10.0", a.Status.Version)
+	assert.Nil(t, a.Status.Upgrade)
+	setTestWorkloadsReady(t, r)
+
+	a.Spec.Version = "v2.10.1"
+	assert.NoError(t, r.Client.Update(context.TODO(), a))
+	res, err := r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, upgradeRequeueInterval, res.RequeueAfter)
+
+	// the new image is checked before any component is upgraded
+	assert.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, a))
+	if assert.NotNil(t, a.Status.Upgrade) {
+		assert.Equal(t, argoproj.UpgradePhasePreFlight, a.Status.Upgrade.Phase)
+		assert.Equal(t, "v2.10.0", a.Status.Upgrade.FromVersion)
+		assert.Equal(t, "v2.10.1", a.Status.Upgrade.ToVersion)
+	}
+	assert.Equal(t, "quay.io/argoproj/argocd:v2.10.1", getTestUpgradeProbe(t, r).Spec.Containers[0].Image)
+	assert.Equal(t, "quay.io/argoproj/argocd:v2.10.0", getTestDeploymentImage(t, r, "argocd-server"))
+	return r, req
+}
+
+// passTestUpgradePreFlight completes the upgrade probe and reconciles the upgrade.
+func passTestUpgradePreFlight(t *testing.T, r *ReconcileArgoCD, req reconcile.Request) {
+	probe := getTestUpgradeProbe(t, r)
+	probe.Status.Phase = corev1.PodSucceeded
+	assert.NoError(t, r.Client.Status().Update(context.TODO(), probe))
+	_, err := r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+
+	err = r.Client.Get(context.TODO(), client.ObjectKeyFromObject(probe), &corev1.Pod{})
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestReconcileArgoCD_Reconcile_upgrade(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	r, req := startTestUpgrade(t)
+	passTestUpgradePreFlight(t, r, req)
+
+	a := &argoproj.ArgoCD{}
+	assert.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, a))
+	assert.Equal(t, argoproj.UpgradePhaseRollingOut, a.Status.Upgrade.Phase)
+	assert.Equal(t, argoproj.ArgoCDComponentName("redis"), a.Status.Upgrade.Step)
+
+	// redis is ready, the repo server is upgraded alone
+	_, err := r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, a))
+	assert.Equal(t, argoproj.ArgoCDComponentName("repo-server"), a.Status.Upgrade.Step)
+	assert.Equal(t, "quay.io/argoproj/argocd:v2.10.1", getTestDeploymentImage(t, r, "argocd-repo-server"))
+	assert.Equal(t, "quay.io/argoproj/argocd:v2.10.0", getTestStatefulSetImage(t, r, "argocd-application-controller"))
+	assert.Equal(t, "quay.io/argoproj/argocd:v2.10.0", getTestDeploymentImage(t, r, "argocd-server"))
+
+	// the repo server is not ready yet
+	setTestDeploymentNotReady(t, r, "argocd-repo-server")
+	_, err = r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, a))
+	assert.Equal(t, argoproj.ArgoCDComponentName("repo-server"), a.Status.Upgrade.Step)
+
+	// every component is upgraded in turn once the previous one is ready
+	for i := 0; i < len(upgradeSteps) && a.Status.Upgrade.Phase == argoproj.UpgradePhaseRollingOut; i++ {
+		setTestWorkloadsReady(t, r)
+		_, err = r.Reconcile(context.TODO(), req)
+		assert.NoError(t, err)
+		assert.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, a))
+	}
+	assert.Equal(t, argoproj.UpgradePhaseCompleted, a.Status.Upgrade.Phase)
+	assert.Equal(t, "v2.10.1", a.Status.Version)
+	assert.Equal(t, "quay.io/argoproj/argocd:v2.10.1", getTestStatefulSetImage(t, r, "argocd-application-controller"))
+	assert.Equal(t, "quay.io/argoproj/argocd:v2.10.1", getTestDeploymentImage(t, r, "argocd-server"))
+	assert.Contains(t, getTestEventReasons(t, r), "UpgradeCompleted")
+
+	res, err := r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+	assert.Zero(t, res.RequeueAfter)
+}
+
+func TestReconcileArgoCD_Reconcile_upgradeRollback(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	r, req := startTestUpgrade(t)
+	passTestUpgradePreFlight(t, r, req)
+	_, err := r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+
+	a := &argoproj.ArgoCD{}
+	assert.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, a))
+	assert.Equal(t, argoproj.ArgoCDComponentName("repo-server"), a.Status.Upgrade.Step)
+	assert.Equal(t, "quay.io/argoproj/argocd:v2.10.1", getTestDeploymentImage(t, r, "argocd-repo-server"))
+
+	// the repo server is not ready in time
+	setTestDeploymentNotReady(t, r, "argocd-repo-server")
+	past := metav1.NewTime(time.Now().Add(-upgradeDefaultStepTimeout - time.Minute))
+	a.Status.Upgrade.StepStartTime = &past
+	assert.NoError(t, r.Client.Status().Update(context.TODO(), a))
+	_, err = r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+
+	assert.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, a))
+	assert.Equal(t, argoproj.UpgradePhaseRollingBack, a.Status.Upgrade.Phase)
+	assert.Equal(t, "the repo-server component was not ready within 10m0s", a.Status.Upgrade.Message)
+	assert.Equal(t, "quay.io/argoproj/argocd:v2.10.0", getTestDeploymentImage(t, r, "argocd-repo-server"))
+
+	setTestWorkloadsReady(t, r)
+	_, err = r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, a))
+	assert.Equal(t, argoproj.UpgradePhaseRolledBack, a.Status.Upgrade.Phase)
+	assert.Equal(t, "v2.10.0", a.Status.Version)
+	reasons := getTestEventReasons(t, r)
+	assert.Contains(t, reasons, "UpgradeRollingBack")
+	assert.Contains(t, reasons, "UpgradeRolledBack")
+
+	// the failed version is not tried again until the version changes
+	res, err := r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+	assert.Zero(t, res.RequeueAfter)
+	assert.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, a))
+	assert.Equal(t, argoproj.UpgradePhaseRolledBack, a.Status.Upgrade.Phase)
+	assert.Equal(t, "quay.io/argoproj/argocd:v2.10.0", getTestDeploymentImage(t, r, "argocd-repo-server"))
+
+	a.Spec.Version = "v2.10.2"
+	assert.NoError(t, r.Client.Update(context.TODO(), a))
+	_, err = r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, a))
+	assert.Equal(t, argoproj.UpgradePhasePreFlight, a.Status.Upgrade.Phase)
+	assert.Equal(t, "v2.10.0", a.Status.Upgrade.FromVersion)
+	assert.Equal(t, "v2.10.2", a.Status.Upgrade.ToVersion)
+}
+
+func TestReconcileArgoCD_Reconcile_upgradePreFlightFailure(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	r, req := startTestUpgrade(t)
+
+	probe := getTestUpgradeProbe(t, r)
+	probe.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:  "probe",
+		State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "not found"}},
+	}}
+	assert.NoError(t, r.Client.Status().Update(context.TODO(), probe))
+	_, err := r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+
+	a := &argoproj.ArgoCD{}
+	assert.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, a))
+	assert.Equal(t, argoproj.UpgradePhaseFailed, a.Status.Upgrade.Phase)
+	assert.Equal(t, "the image quay.io/argoproj/argocd:v2.10.1 cannot be pulled, ImagePullBackOff: not found", a.Status.Upgrade.Message)
+	assert.Equal(t, "v2.10.0", a.Status.Version)
+	assert.Equal(t, "quay.io/argoproj/argocd:v2.10.0", getTestDeploymentImage(t, r, "argocd-repo-server"))
+	assert.Contains(t, getTestEventReasons(t, r), "UpgradeFailed")
+
+	err = r.Client.Get(context.TODO(), client.ObjectKeyFromObject(probe), &corev1.Pod{})
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestReconcileArgoCD_Reconcile_upgradeExistingInstance(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.Version = "v2.10.0"
+	})
+	r, req := makeTestStrategyReconciler(t, a)
+	_, err := r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+	setTestWorkloadsReady(t, r)
+
+	// an instance reconciled before the upgrades were orchestrated has no version in its status
+	assert.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, a))
+	a.Status.Version = ""
+	assert.NoError(t, r.Client.Status().Update(context.TODO(), a))
+	a.Spec.Version = "v2.10.1"
+	assert.NoError(t, r.Client.Update(context.TODO(), a))
+
+	// the version the components run is upgraded from
+	_, err = r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, a))
+	assert.Equal(t, "v2.10.0", a.Status.Version)
+	if assert.NotNil(t, a.Status.Upgrade) {
+		assert.Equal(t, argoproj.UpgradePhasePreFlight, a.Status.Upgrade.Phase)
+		assert.Equal(t, "v2.10.0", a.Status.Upgrade.FromVersion)
+	}
+	assert.Equal(t, "quay.io/argoproj/argocd:v2.10.0", getTestDeploymentImage(t, r, "argocd-server"))
+}
+
+// podCreateFailer fails the creation of Pods, as a Pod Security admission or a ResourceQuota would.
+type podCreateFailer struct {
+	client.Client
+}
+
+func (c *podCreateFailer) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if _, ok := obj.(*corev1.Pod); ok {
+		return apierrors.NewForbidden(corev1.Resource("pods"), obj.GetName(), errors.New("violates PodSecurity"))
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func TestReconcileArgoCD_Reconcile_upgradeProbeRejected(t *testing.T) {
+	logf.SetLogger(ZapLogger(true))
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.Version = "v2.10.0"
+	})
+	r, req := makeTestStrategyReconciler(t, a)
+	_, err := r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+
+	r.Client = &podCreateFailer{Client: r.Client}
+	assert.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, a))
+	a.Spec.Version = "v2.10.1"
+	assert.NoError(t, r.Client.Update(context.TODO(), a))
+	_, err = r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+
+	assert.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, a))
+	assert.Equal(t, argoproj.UpgradePhaseFailed, a.Status.Upgrade.Phase)
+	assert.Contains(t, a.Status.Upgrade.Message, "violates PodSecurity")
+	assert.Equal(t, "quay.io/argoproj/argocd:v2.10.0", getTestDeploymentImage(t, r, "argocd-server"))
+}
+
+func TestNewUpgradeProbePod(t *testing.T) {
+	probe := newUpgradeProbePod(makeTestArgoCD(), "v2.10.1")
+	container := probe.Spec.Containers[0]
+	assert.True(t, *container.SecurityContext.RunAsNonRoot)
+	assert.False(t, *container.SecurityContext.AllowPrivilegeEscalation)
+	assert.Equal(t, []corev1.Capability{"ALL"}, container.SecurityContext.Capabilities.Drop)
+	assert.Equal(t, corev1.SeccompProfileTypeRuntimeDefault, container.SecurityContext.SeccompProfile.Type)
+	assert.NotEmpty(t, container.Resources.Requests)
+	assert.NotEmpty(t, container.Resources.Limits)
+}
+
+func TestCheckUpgradeCompatibility(t *testing.T) {
+	tests := []struct {
+		from    string
+		to      string
+		wantErr bool
+	}{
+		{"v2.10.0", "v2.10.1", false},
+		{"v2.10.1", "v2.10.0", false},
+		{"v2.10.0", "v2.11.0", false},
+		{"v2.11.0", "v2.10.3", true},
+		{"v2.11.0", "v3.0.0", true},
+		{"v2.10.0", "latest", false},
+		{"sha256:d2c274ff26c7ab164907de05826bdfe2e6f326af70edd0bb83194b75fbb71f9e", "v2.11.0", false},
+	}
+	for _, test := range tests {
+		t.Run(test.from+" to "+test.to, func(t *testing.T) {
+			err := checkUpgradeCompatibility(test.from, test.to)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
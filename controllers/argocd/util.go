@@ -36,12 +36,11 @@ import (
 	"github.com/argoproj-labs/argocd-operator/api/v1alpha1"
 	argoproj "github.com/argoproj-labs/argocd-operator/api/v1beta1"
 	"github.com/argoproj-labs/argocd-operator/common"
+	"github.com/argoproj-labs/argocd-operator/controllers/argocdoperatorconfig"
 	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
 
-	monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
 	oappsv1 "github.com/openshift/api/apps/v1"
 	configv1 "github.com/openshift/api/config/v1"
-	routev1 "github.com/openshift/api/route/v1"
 	configv1client "github.com/openshift/client-go/config/clientset/versioned/typed/config/v1"
 	"github.com/sethvargo/go-password/password"
 	"golang.org/x/mod/semver"
@@ -69,22 +68,23 @@ const (
 	grafanaDeprecatedWarning = "Warning: grafana field is deprecated from ArgoCD: field will be ignored."
 )
 
-var (
-	versionAPIFound = false
-)
-
 // IsVersionAPIAvailable returns true if the version api is present
-func IsVersionAPIAvailable() bool {
-	return versionAPIFound
+func (a *ClusterAPIs) IsVersionAPIAvailable() bool {
+	return a.isAvailable(func(a *ClusterAPIs) bool { return a.version })
+}
+
+// setVersionAPIAvailable records whether the version api is present
+func (a *ClusterAPIs) setVersionAPIAvailable(found bool) {
+	a.setAvailable(func(a *ClusterAPIs) { a.version = found })
 }
 
 // verifyVersionAPI will verify that the template API is present.
-func verifyVersionAPI() error {
+func (a *ClusterAPIs) verifyVersionAPI() error {
 	found, err := argoutil.VerifyAPI(configv1.GroupName, configv1.GroupVersion.Version)
 	if err != nil {
 		return err
 	}
-	versionAPIFound = found
+	a.setVersionAPIAvailable(found)
 	return nil
 }
 
@@ -134,26 +134,19 @@ func getArgoApplicationControllerResources(cr *argoproj.ArgoCD) corev1.ResourceR
 }
 
 // getArgoApplicationControllerCommand will return the command for the ArgoCD Application Controller component.
-func getArgoApplicationControllerCommand(cr *argoproj.ArgoCD, useTLSForRedis bool) []string {
+func getArgoApplicationControllerCommand(cr *argoproj.ArgoCD, useTLSForRedis bool, applicationNamespaces []string) []string {
 	cmd := []string{
 		"argocd-application-controller",
 		"--operation-processors", fmt.Sprint(getArgoServerOperationProcessors(cr)),
 	}
 
 	if cr.Spec.Redis.IsEnabled() {
-		cmd = append(cmd, "--redis", getRedisServerAddress(cr))
+		cmd = append(cmd, getRedisServerArgs(cr)...)
 	} else {
 		log.Info("Redis is Disabled. Skipping adding Redis configuration to Application Controller.")
 	}
 
-	if useTLSForRedis {
-		cmd = append(cmd, "--redis-use-tls")
-		if isRedisTLSVerificationDisabled(cr) {
-			cmd = append(cmd, "--redis-insecure-skip-tls-verify")
-		} else {
-			cmd = append(cmd, "--redis-ca-certificate", "/app/config/controller/tls/redis/tls.crt")
-		}
-	}
+	cmd = append(cmd, getRedisTLSArgs(cr, useTLSForRedis, "/app/config/controller/tls/redis/tls.crt")...)
 
 	if cr.Spec.Repo.IsEnabled() {
 		cmd = append(cmd, "--repo-server", getRepoServerAddress(cr))
@@ -164,8 +157,8 @@ func getArgoApplicationControllerCommand(cr *argoproj.ArgoCD, useTLSForRedis boo
 	cmd = append(cmd, "--status-processors", fmt.Sprint(getArgoServerStatusProcessors(cr)))
 	cmd = append(cmd, "--kubectl-parallelism-limit", fmt.Sprint(getArgoControllerParellismLimit(cr)))
 
-	if cr.Spec.SourceNamespaces != nil && len(cr.Spec.SourceNamespaces) > 0 {
-		cmd = append(cmd, "--application-namespaces", fmt.Sprint(strings.Join(cr.Spec.SourceNamespaces, ",")))
+	if len(applicationNamespaces) > 0 {
+		cmd = append(cmd, "--application-namespaces", fmt.Sprint(strings.Join(applicationNamespaces, ",")))
 	}
 
 	cmd = append(cmd, "--loglevel")
@@ -191,7 +184,7 @@ func getArgoContainerImage(cr *argoproj.ArgoCD) string {
 		tag = common.ArgoCDDefaultArgoVersion
 		defaultTag = true
 	}
-	if e := os.Getenv(common.ArgoCDImageEnvName); e != "" && (defaultTag && defaultImg) {
+	if e := argocdoperatorconfig.Getenv(common.ArgoCDImageEnvName); e != "" && (defaultTag && defaultImg) {
 		return e
 	}
 
@@ -230,7 +223,7 @@ func getRepoServerContainerImage(cr *argoproj.ArgoCD) string {
 			defaultTag = true
 		}
 	}
-	if e := os.Getenv(common.ArgoCDImageEnvName); e != "" && (defaultTag && defaultImg) {
+	if e := argocdoperatorconfig.Getenv(common.ArgoCDImageEnvName); e != "" && (defaultTag && defaultImg) {
 		return e
 	}
 	return argoutil.CombineImageTag(img, tag)
@@ -262,21 +255,34 @@ func isRedisTLSVerificationDisabled(cr *argoproj.ArgoCD) bool {
 }
 
 // getArgoServerGRPCHost will return the GRPC host for the given ArgoCD.
-func getArgoServerGRPCHost(cr *argoproj.ArgoCD) string {
+func getArgoServerGRPCHost(cr *argoproj.ArgoCD) (string, error) {
 	host := nameWithSuffix("grpc", cr)
 	if len(cr.Spec.Server.GRPC.Host) > 0 {
 		host = cr.Spec.Server.GRPC.Host
 	}
-	return host
+	return shortenHostname(host)
 }
 
 // getArgoServerHost will return the host for the given ArgoCD.
-func getArgoServerHost(cr *argoproj.ArgoCD) string {
+func getArgoServerHost(cr *argoproj.ArgoCD) (string, error) {
 	host := cr.Name
 	if len(cr.Spec.Server.Host) > 0 {
 		host = cr.Spec.Server.Host
 	}
-	return host
+	return shortenHostname(host)
+}
+
+// getArgoServerAdditionalHosts will return the additional hosts for the given ArgoCD, shortened to valid hostnames.
+func getArgoServerAdditionalHosts(cr *argoproj.ArgoCD) ([]string, error) {
+	hosts := make([]string, 0, len(cr.Spec.Server.AdditionalHosts))
+	for _, host := range cr.Spec.Server.AdditionalHosts {
+		hostname, err := shortenHostname(host)
+		if err != nil {
+			return nil, fmt.Errorf("invalid additional host %s: %w", host, err)
+		}
+		hosts = append(hosts, hostname)
+	}
+	return hosts, nil
 }
 
 // getKeycloakIngressHost will return the host for the given ArgoCD.
@@ -340,7 +346,7 @@ func (r *ReconcileArgoCD) getArgoServerURI(cr *argoproj.ArgoCD) string {
 	}
 
 	// Use Route host if available, override Ingress if both exist
-	if IsRouteAPIAvailable() {
+	if r.ClusterAPIs.IsRouteAPIAvailable() {
 		route := newRouteWithSuffix("server", cr)
 		if argoutil.IsObjectFound(r.Client, cr.Namespace, route.Name, route) {
 			host = route.Spec.Host
@@ -386,12 +392,13 @@ func getRedisConfigPath() string {
 	return common.ArgoCDDefaultRedisConfigPath
 }
 
-// getRedisInitScript will load the redis configuration from a template on disk for the given ArgoCD.
+// getRedisConf will load the redis configuration from a template on disk for the given ArgoCD.
 // If an error occurs, an empty string value will be returned.
-func getRedisConf(useTLSForRedis bool) string {
+func getRedisConf(cr *argoproj.ArgoCD, useTLSForRedis bool) string {
 	path := fmt.Sprintf("%s/redis.conf.tpl", getRedisConfigPath())
 	params := map[string]string{
-		"UseTLS": strconv.FormatBool(useTLSForRedis),
+		"UseTLS":      strconv.FormatBool(useTLSForRedis),
+		"Persistence": strconv.FormatBool(cr.Spec.HA.Persistence.IsEnabled()),
 	}
 	conf, err := loadTemplateFile(path, params)
 	if err != nil {
@@ -414,7 +421,7 @@ func getRedisContainerImage(cr *argoproj.ArgoCD) string {
 		tag = common.ArgoCDDefaultRedisVersion
 		defaultTag = true
 	}
-	if e := os.Getenv(common.ArgoCDRedisImageEnvName); e != "" && (defaultTag && defaultImg) {
+	if e := argocdoperatorconfig.Getenv(common.ArgoCDRedisImageEnvName); e != "" && (defaultTag && defaultImg) {
 		return e
 	}
 	return argoutil.CombineImageTag(img, tag)
@@ -433,7 +440,7 @@ func getRedisHAContainerImage(cr *argoproj.ArgoCD) string {
 		tag = common.ArgoCDDefaultRedisVersionHA
 		defaultTag = true
 	}
-	if e := os.Getenv(common.ArgoCDRedisHAImageEnvName); e != "" && (defaultTag && defaultImg) {
+	if e := argocdoperatorconfig.Getenv(common.ArgoCDRedisHAImageEnvName); e != "" && (defaultTag && defaultImg) {
 		return e
 	}
 	return argoutil.CombineImageTag(img, tag)
@@ -459,7 +466,7 @@ func getRedisHAProxyContainerImage(cr *argoproj.ArgoCD) string {
 		defaultTag = true
 	}
 
-	if e := os.Getenv(common.ArgoCDRedisHAProxyImageEnvName); e != "" && (defaultTag && defaultImg) {
+	if e := argocdoperatorconfig.Getenv(common.ArgoCDRedisHAProxyImageEnvName); e != "" && (defaultTag && defaultImg) {
 		return e
 	}
 
@@ -473,6 +480,7 @@ func getRedisInitScript(cr *argoproj.ArgoCD, useTLSForRedis bool) string {
 	vars := map[string]string{
 		"ServiceName": nameWithSuffix("redis-ha", cr),
 		"UseTLS":      strconv.FormatBool(useTLSForRedis),
+		"Quorum":      fmt.Sprint(getRedisHASentinelQuorum(cr)),
 	}
 
 	script, err := loadTemplateFile(path, vars)
@@ -487,9 +495,11 @@ func getRedisInitScript(cr *argoproj.ArgoCD, useTLSForRedis bool) string {
 // If an error occurs, an empty string value will be returned.
 func getRedisHAProxyConfig(cr *argoproj.ArgoCD, useTLSForRedis bool) string {
 	path := fmt.Sprintf("%s/haproxy.cfg.tpl", getRedisConfigPath())
-	vars := map[string]string{
+	vars := map[string]interface{}{
 		"ServiceName": nameWithSuffix("redis-ha", cr),
 		"UseTLS":      strconv.FormatBool(useTLSForRedis),
+		"Quorum":      getRedisHASentinelQuorum(cr),
+		"Replicas":    getRedisHAReplicaIndexes(cr),
 	}
 
 	script, err := loadTemplateFile(path, vars)
@@ -504,8 +514,9 @@ func getRedisHAProxyConfig(cr *argoproj.ArgoCD, useTLSForRedis bool) string {
 // If an error occurs, an empty string value will be returned.
 func getRedisHAProxyScript(cr *argoproj.ArgoCD) string {
 	path := fmt.Sprintf("%s/haproxy_init.sh.tpl", getRedisConfigPath())
-	vars := map[string]string{
+	vars := map[string]interface{}{
 		"ServiceName": nameWithSuffix("redis-ha", cr),
+		"Replicas":    getRedisHAReplicaIndexes(cr),
 	}
 
 	script, err := loadTemplateFile(path, vars)
@@ -540,6 +551,31 @@ func getRedisHAResources(cr *argoproj.ArgoCD) corev1.ResourceRequirements {
 	return resources
 }
 
+// getRedisHASentinelQuorum will return the number of Sentinels that need to agree on a failed master for the given ArgoCD.
+// The quorum defaults to a majority of the Redis HA replicas and is capped at the replica count, the webhook rejecting
+// greater quorums.
+func getRedisHASentinelQuorum(cr *argoproj.ArgoCD) int32 {
+	replicas := *getRedisHAReplicas(cr)
+	quorum := replicas/2 + 1
+	if cr.Spec.HA.SentinelQuorum != nil && *cr.Spec.HA.SentinelQuorum > 0 {
+		quorum = *cr.Spec.HA.SentinelQuorum
+	}
+	if quorum > replicas {
+		quorum = replicas
+	}
+	return quorum
+}
+
+// getRedisHAReplicaIndexes will return the ordinal index of every Redis HA StatefulSet replica for the given ArgoCD.
+func getRedisHAReplicaIndexes(cr *argoproj.ArgoCD) []int32 {
+	replicas := *getRedisHAReplicas(cr)
+	indexes := make([]int32, 0, replicas)
+	for i := int32(0); i < replicas; i++ {
+		indexes = append(indexes, i)
+	}
+	return indexes
+}
+
 // getRedisSentinelConf will load the redis sentinel configuration from a template on disk for the given ArgoCD.
 // If an error occurs, an empty string value will be returned.
 func getRedisSentinelConf(useTLSForRedis bool) string {
@@ -611,8 +647,165 @@ func getRedisServerAddress(cr *argoproj.ArgoCD) string {
 	return fqdnServiceRef(common.ArgoCDDefaultRedisSuffix, common.ArgoCDDefaultRedisPort, cr)
 }
 
+// isRemoteRedis returns true if the ArgoCD components use a Redis instance that is not managed by the operator.
+func isRemoteRedis(cr *argoproj.ArgoCD) bool {
+	if cr.Spec.Redis.Remote != nil && *cr.Spec.Redis.Remote != "" {
+		return true
+	}
+	return getRemoteRedisSentinel(cr) != nil
+}
+
+// getRemoteRedisSentinel will return the sentinel options of the remote Redis, or nil if sentinel is not used.
+func getRemoteRedisSentinel(cr *argoproj.ArgoCD) *argoproj.ArgoCDRemoteRedisSentinelSpec {
+	remote := cr.Spec.Redis.RemoteConfig
+	if remote == nil || remote.Sentinel == nil || len(remote.Sentinel.Addresses) == 0 {
+		return nil
+	}
+	return remote.Sentinel
+}
+
+// getRemoteRedisSentinelMasterName will return the name of the Redis master monitored by the remote sentinels.
+func getRemoteRedisSentinelMasterName(cr *argoproj.ArgoCD) string {
+	if sentinel := getRemoteRedisSentinel(cr); sentinel != nil && sentinel.MasterName != "" {
+		return sentinel.MasterName
+	}
+	return common.ArgoCDDefaultRedisSentinelMasterName
+}
+
+// getRemoteRedisCAConfigMap will return the reference to the CA certificate of the remote Redis, if any.
+func getRemoteRedisCAConfigMap(cr *argoproj.ArgoCD) *corev1.ConfigMapKeySelector {
+	if !isRemoteRedis(cr) || cr.Spec.Redis.RemoteConfig == nil {
+		return nil
+	}
+	return cr.Spec.Redis.RemoteConfig.CAConfigMap
+}
+
+// isRemoteRedisTLSEnabled returns true if the connections to the remote Redis should use TLS.
+func isRemoteRedisTLSEnabled(cr *argoproj.ArgoCD) bool {
+	remote := cr.Spec.Redis.RemoteConfig
+	return remote != nil && (remote.TLS || remote.CAConfigMap != nil)
+}
+
+// getRedisServerArgs will return the command line arguments pointing the Argo CD components to Redis.
+func getRedisServerArgs(cr *argoproj.ArgoCD) []string {
+	sentinel := getRemoteRedisSentinel(cr)
+	if sentinel == nil {
+		return []string{"--redis", getRedisServerAddress(cr)}
+	}
+
+	args := make([]string, 0)
+	for _, addr := range sentinel.Addresses {
+		args = append(args, "--sentinel", addr)
+	}
+	return append(args, "--sentinelmaster", getRemoteRedisSentinelMasterName(cr))
+}
+
+// getRedisTLSArgs will return the command line arguments for the TLS connections to Redis. The given caPath is
+// the location of the certificate of the Redis instance managed by the operator.
+func getRedisTLSArgs(cr *argoproj.ArgoCD, useTLSForRedis bool, caPath string) []string {
+	if !useTLSForRedis {
+		return nil
+	}
+
+	args := []string{"--redis-use-tls"}
+	if isRedisTLSVerificationDisabled(cr) {
+		args = append(args, "--redis-insecure-skip-tls-verify")
+	} else if isRemoteRedis(cr) {
+		// Remote Redis certificates are verified with the system trust store unless a CA is given
+		if getRemoteRedisCAConfigMap(cr) != nil {
+			args = append(args, "--redis-ca-certificate", getRemoteRedisCAPath())
+		}
+	} else {
+		args = append(args, "--redis-ca-certificate", caPath)
+	}
+	return args
+}
+
+// getRemoteRedisCAPath will return the path of the mounted CA certificate of the remote Redis.
+func getRemoteRedisCAPath() string {
+	return fmt.Sprintf("%s/ca.crt", common.ArgoCDRedisRemoteCAMountPath)
+}
+
+// getRemoteRedisCAVolumes will return the volume holding the CA certificate of the remote Redis, if any.
+func getRemoteRedisCAVolumes(cr *argoproj.ArgoCD) []corev1.Volume {
+	ca := getRemoteRedisCAConfigMap(cr)
+	if ca == nil {
+		return nil
+	}
+	return []corev1.Volume{{
+		Name: common.ArgoCDRedisRemoteCAVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: ca.LocalObjectReference,
+				Items: []corev1.KeyToPath{{
+					Key:  ca.Key,
+					Path: "ca.crt",
+				}},
+			},
+		},
+	}}
+}
+
+// getRemoteRedisCAVolumeMounts will return the mount of the CA certificate of the remote Redis, if any.
+func getRemoteRedisCAVolumeMounts(cr *argoproj.ArgoCD) []corev1.VolumeMount {
+	if getRemoteRedisCAConfigMap(cr) == nil {
+		return nil
+	}
+	return []corev1.VolumeMount{{
+		Name:      common.ArgoCDRedisRemoteCAVolumeName,
+		MountPath: common.ArgoCDRedisRemoteCAMountPath,
+		ReadOnly:  true,
+	}}
+}
+
+// getRedisAuthEnv will return the environment variables holding the Redis credentials for the Argo CD components.
+func getRedisAuthEnv(cr *argoproj.ArgoCD) []corev1.EnvVar {
+	passwordRef := &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{
+			Name: fmt.Sprintf("%s-%s", cr.Name, "redis-initial-password"),
+		},
+		Key: "admin.password",
+	}
+
+	var usernameRef *corev1.SecretKeySelector
+	if remote := cr.Spec.Redis.RemoteConfig; remote != nil && isRemoteRedis(cr) {
+		if remote.PasswordSecret != nil {
+			passwordRef = remote.PasswordSecret.DeepCopy()
+		}
+		if remote.UsernameSecret != nil {
+			usernameRef = remote.UsernameSecret.DeepCopy()
+		}
+	}
+
+	env := []corev1.EnvVar{{
+		Name:      "REDIS_PASSWORD",
+		ValueFrom: &corev1.EnvVarSource{SecretKeyRef: passwordRef},
+	}}
+	if usernameRef != nil {
+		env = append(env, corev1.EnvVar{
+			Name:      "REDIS_USERNAME",
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: usernameRef},
+		})
+	}
+	if sentinel := getRemoteRedisSentinel(cr); sentinel != nil && isRemoteRedis(cr) {
+		if sentinel.PasswordSecret != nil {
+			env = append(env, corev1.EnvVar{
+				Name:      "REDIS_SENTINEL_PASSWORD",
+				ValueFrom: &corev1.EnvVarSource{SecretKeyRef: sentinel.PasswordSecret.DeepCopy()},
+			})
+		}
+		if sentinel.UsernameSecret != nil {
+			env = append(env, corev1.EnvVar{
+				Name:      "REDIS_SENTINEL_USERNAME",
+				ValueFrom: &corev1.EnvVarSource{SecretKeyRef: sentinel.UsernameSecret.DeepCopy()},
+			})
+		}
+	}
+	return env
+}
+
 // loadTemplateFile will parse a template with the given path and execute it with the given params.
-func loadTemplateFile(path string, params map[string]string) (string, error) {
+func loadTemplateFile(path string, params interface{}) (string, error) {
 	tmpl, err := template.ParseFiles(path)
 	if err != nil {
 		log.Error(err, "unable to parse template")
@@ -635,33 +828,17 @@ func nameWithSuffix(suffix string, cr *argoproj.ArgoCD) string {
 	return fmt.Sprintf("%s-%s", cr.Name, suffix)
 }
 
+// getCmpConfigMapName will return the name of the Config Management Plugin ConfigMap for the given ArgoCD.
+func getCmpConfigMapName(cr *argoproj.ArgoCD) string {
+	return nameWithSuffix(common.ArgoCDCmpConfigMapSuffix, cr)
+}
+
 // fqdnServiceRef will return the FQDN referencing a specific service name, as set up by the operator, with the
 // given port.
 func fqdnServiceRef(service string, port int, cr *argoproj.ArgoCD) string {
 	return fmt.Sprintf("%s.%s.svc.cluster.local:%d", nameWithSuffix(service, cr), cr.Namespace, port)
 }
 
-// InspectCluster will verify the availability of extra features available to the cluster, such as Prometheus and
-// OpenShift Routes.
-func InspectCluster() error {
-	if err := verifyPrometheusAPI(); err != nil {
-		return err
-	}
-
-	if err := verifyRouteAPI(); err != nil {
-		return err
-	}
-
-	if err := verifyKeycloakTemplateAPIs(); err != nil {
-		return err
-	}
-
-	if err := verifyVersionAPI(); err != nil {
-		return err
-	}
-	return nil
-}
-
 // reconcileCertificateAuthority will reconcile all Certificate Authority resources.
 func (r *ReconcileArgoCD) reconcileCertificateAuthority(cr *argoproj.ArgoCD) error {
 	log.Info("reconciling CA secret")
@@ -677,6 +854,10 @@ func (r *ReconcileArgoCD) reconcileCertificateAuthority(cr *argoproj.ArgoCD) err
 }
 
 func (r *ReconcileArgoCD) redisShouldUseTLS(cr *argoproj.ArgoCD) bool {
+	if isRemoteRedis(cr) {
+		return isRemoteRedisTLSEnabled(cr)
+	}
+
 	var tlsSecretObj corev1.Secret
 	tlsSecretName := types.NamespacedName{Namespace: cr.Namespace, Name: common.ArgoCDRedisServerTLSSecretName}
 	err := r.Client.Get(context.TODO(), tlsSecretName, &tlsSecretObj)
@@ -730,14 +911,24 @@ func (r *ReconcileArgoCD) reconcileResources(cr *argoproj.ArgoCD) error {
 
 	// we reconcile SSO first so that we can catch and throw errors for any illegal SSO configurations right away, and return control from here
 	// preventing dex resources from getting created anyway through the other function calls, effectively bypassing the SSO checks
+	// the upgrade is reconciled first, it decides the version each component runs
+	log.Info("reconciling upgrade")
+	if err := r.reconcileUpgrade(cr); err != nil {
+		return err
+	}
+
 	log.Info("reconciling SSO")
+	// the resources are pruned only when all of them were reconciled
+	prune := true
 	if err := r.reconcileSSO(cr); err != nil {
 		log.Info(err.Error())
+		prune = false
 	}
 
 	log.Info("reconciling status")
 	if err := r.reconcileStatus(cr); err != nil {
 		log.Info(err.Error())
+		prune = false
 	}
 
 	log.Info("reconciling roles")
@@ -800,14 +991,21 @@ func (r *ReconcileArgoCD) reconcileResources(cr *argoproj.ArgoCD) error {
 		return err
 	}
 
-	if IsRouteAPIAvailable() {
+	if r.ClusterAPIs.IsRouteAPIAvailable() {
 		log.Info("reconciling routes")
 		if err := r.reconcileRoutes(cr); err != nil {
 			return err
 		}
 	}
 
-	if IsPrometheusAPIAvailable() {
+	if r.ClusterAPIs.IsGatewayAPIAvailable() {
+		log.Info("reconciling gateway routes")
+		if err := r.reconcileGatewayRoutes(cr); err != nil {
+			return err
+		}
+	}
+
+	if r.ClusterAPIs.IsPrometheusAPIAvailable() {
 		log.Info("reconciling prometheus")
 		if err := r.reconcilePrometheus(cr); err != nil {
 			return err
@@ -854,10 +1052,26 @@ func (r *ReconcileArgoCD) reconcileResources(cr *argoproj.ArgoCD) error {
 		return err
 	}
 
+	if err := r.reconcileRedisPasswordRotation(cr); err != nil {
+		return err
+	}
+
 	if err := r.ReconcileNetworkPolicies(cr); err != nil {
 		return err
 	}
 
+	log.Info("reconciling bootstrap")
+	if err := r.reconcileBootstrap(cr); err != nil {
+		return err
+	}
+
+	if prune {
+		log.Info("pruning resources")
+		if err := r.pruneResources(cr); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -960,40 +1174,7 @@ func removeString(slice []string, s string) []string {
 }
 
 // setResourceWatches will register Watches for each of the supported Resources.
-func (r *ReconcileArgoCD) setResourceWatches(bldr *builder.Builder, clusterResourceMapper, tlsSecretMapper, namespaceResourceMapper, clusterSecretResourceMapper, applicationSetGitlabSCMTLSConfigMapMapper handler.MapFunc) *builder.Builder {
-
-	deploymentConfigPred := predicate.Funcs{
-		UpdateFunc: func(e event.UpdateEvent) bool {
-			// Ignore updates to CR status in which case metadata.Generation does not change
-			var count int32 = 1
-			newDC, ok := e.ObjectNew.(*oappsv1.DeploymentConfig)
-			if !ok {
-				return false
-			}
-			oldDC, ok := e.ObjectOld.(*oappsv1.DeploymentConfig)
-			if !ok {
-				return false
-			}
-			if newDC.Name == defaultKeycloakIdentifier {
-				if newDC.Status.AvailableReplicas == count {
-					return true
-				}
-				if newDC.Status.AvailableReplicas == int32(0) &&
-					!reflect.DeepEqual(oldDC.Status.AvailableReplicas, newDC.Status.AvailableReplicas) {
-					// Handle the deletion of keycloak pod.
-					log.Info(fmt.Sprintf("Handle the pod deletion event for keycloak deployment config %s in namespace %s",
-						newDC.Name, newDC.Namespace))
-					err := handleKeycloakPodDeletion(newDC)
-					if err != nil {
-						log.Error(err, fmt.Sprintf("Failed to update Deployment Config %s for keycloak pod deletion in namespace %s",
-							newDC.Name, newDC.Namespace))
-					}
-				}
-			}
-			return false
-		},
-	}
-
+func (r *ReconcileArgoCD) setResourceWatches(bldr *builder.Builder, clusterResourceMapper, tlsSecretMapper, namespaceResourceMapper, clusterSecretResourceMapper, applicationSetGitlabSCMTLSConfigMapMapper, repositorySecretMapper handler.MapFunc) *builder.Builder {
 	deleteSSOPred := predicate.Funcs{
 		UpdateFunc: func(e event.UpdateEvent) bool {
 			newCR, ok := e.ObjectNew.(*argoproj.ArgoCD)
@@ -1067,6 +1248,9 @@ func (r *ReconcileArgoCD) setResourceWatches(bldr *builder.Builder, clusterResou
 	// Watch for changes to Ingress sub-resources owned by ArgoCD instances.
 	bldr.Owns(&networkingv1.Ingress{})
 
+	// Watch for changes to NetworkPolicy sub-resources owned by ArgoCD instances.
+	bldr.Owns(&networkingv1.NetworkPolicy{})
+
 	bldr.Owns(&v1.Role{})
 
 	bldr.Owns(&v1.RoleBinding{})
@@ -1096,33 +1280,15 @@ func (r *ReconcileArgoCD) setResourceWatches(bldr *builder.Builder, clusterResou
 			common.ArgoCDManagedByClusterArgoCDLabel: "cluster",
 		}}}, clusterSecretResourceHandler)
 
+	// Watch for secrets holding the credentials of the repositories of the argocd instances
+	bldr.Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(repositorySecretMapper))
+
 	// Watch for changes to Secret sub-resources owned by ArgoCD instances.
 	bldr.Owns(&appsv1.StatefulSet{})
 
-	// Inspect cluster to verify availability of extra features
-	// This sets the flags that are used in subsequent checks
-	if err := InspectCluster(); err != nil {
-		log.Info("unable to inspect cluster")
-	}
-
-	if IsRouteAPIAvailable() {
-		// Watch OpenShift Route sub-resources owned by ArgoCD instances.
-		bldr.Owns(&routev1.Route{})
-	}
-
-	if IsPrometheusAPIAvailable() {
-		// Watch Prometheus sub-resources owned by ArgoCD instances.
-		bldr.Owns(&monitoringv1.Prometheus{})
-
-		// Watch Prometheus ServiceMonitor sub-resources owned by ArgoCD instances.
-		bldr.Owns(&monitoringv1.ServiceMonitor{})
-	}
-
-	if CanUseKeycloakWithTemplate() {
-		// Watch for the changes to Deployment Config
-		bldr.Owns(&oappsv1.DeploymentConfig{}, builder.WithPredicates(deploymentConfigPred))
-
-	}
+	// Watch sub-resources of the optional APIs served by the cluster, such as OpenShift Routes and Prometheus. The
+	// optional APIs installed later on are watched once discovered.
+	r.discovery.own(bldr, r.ClusterAPIs)
 
 	// Watch for changes to NotificationsConfiguration CR
 	bldr.Owns(&v1alpha1.NotificationsConfiguration{})
@@ -1134,6 +1300,42 @@ func (r *ReconcileArgoCD) setResourceWatches(bldr *builder.Builder, clusterResou
 	return bldr
 }
 
+// deploymentConfigPredicate filters the events of the Keycloak DeploymentConfig, and handles the deletion of the
+// Keycloak pod.
+func deploymentConfigPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			// Ignore updates to CR status in which case metadata.Generation does not change
+			var count int32 = 1
+			newDC, ok := e.ObjectNew.(*oappsv1.DeploymentConfig)
+			if !ok {
+				return false
+			}
+			oldDC, ok := e.ObjectOld.(*oappsv1.DeploymentConfig)
+			if !ok {
+				return false
+			}
+			if newDC.Name == defaultKeycloakIdentifier {
+				if newDC.Status.AvailableReplicas == count {
+					return true
+				}
+				if newDC.Status.AvailableReplicas == int32(0) &&
+					!reflect.DeepEqual(oldDC.Status.AvailableReplicas, newDC.Status.AvailableReplicas) {
+					// Handle the deletion of keycloak pod.
+					log.Info(fmt.Sprintf("Handle the pod deletion event for keycloak deployment config %s in namespace %s",
+						newDC.Name, newDC.Namespace))
+					err := handleKeycloakPodDeletion(newDC)
+					if err != nil {
+						log.Error(err, fmt.Sprintf("Failed to update Deployment Config %s for keycloak pod deletion in namespace %s",
+							newDC.Name, newDC.Namespace))
+					}
+				}
+			}
+			return false
+		},
+	}
+}
+
 // boolPtr returns a pointer to val
 func boolPtr(val bool) *bool {
 	return &val
@@ -1143,6 +1345,11 @@ func int64Ptr(val int64) *int64 {
 	return &val
 }
 
+// int32Ptr returns a pointer to val
+func int32Ptr(val int32) *int32 {
+	return &val
+}
+
 // triggerRollout will trigger a rollout of a Kubernetes resource specified as
 // obj. It currently supports Deployment and StatefulSet resources.
 func (r *ReconcileArgoCD) triggerRollout(obj interface{}, key string) error {
@@ -1156,6 +1363,58 @@ func (r *ReconcileArgoCD) triggerRollout(obj interface{}, key string) error {
 	}
 }
 
+// applyRollout will set the given pod template label of the given Deployment or StatefulSet to the given value,
+// restarting its pods only if the label did not already have that value.
+func (r *ReconcileArgoCD) applyRollout(obj client.Object, key, value string) error {
+	if !argoutil.IsObjectFound(r.Client, obj.GetNamespace(), obj.GetName(), obj) {
+		log.Info(fmt.Sprintf("unable to locate %T with name: %s", obj, obj.GetName()))
+		return nil
+	}
+
+	var template *corev1.PodTemplateSpec
+	switch res := obj.(type) {
+	case *appsv1.Deployment:
+		template = &res.Spec.Template
+	case *appsv1.StatefulSet:
+		template = &res.Spec.Template
+	default:
+		return fmt.Errorf("resource of unknown type %T, cannot trigger rollout", res)
+	}
+
+	if template.Labels[key] == value {
+		return nil
+	}
+	if template.Labels == nil {
+		template.Labels = map[string]string{}
+	}
+	template.Labels[key] = value
+	return r.Client.Update(context.TODO(), obj)
+}
+
+// isDeploymentRolledOut will return true if all the replicas of the given Deployment run its latest pod template and are ready.
+func isDeploymentRolledOut(deploy *appsv1.Deployment) bool {
+	replicas := int32(1)
+	if deploy.Spec.Replicas != nil {
+		replicas = *deploy.Spec.Replicas
+	}
+	return deploy.Status.ObservedGeneration >= deploy.Generation &&
+		deploy.Status.UpdatedReplicas == replicas &&
+		deploy.Status.ReadyReplicas == replicas &&
+		deploy.Status.Replicas == replicas
+}
+
+// isStatefulSetRolledOut will return true if all the replicas of the given StatefulSet run its latest revision and are ready.
+func isStatefulSetRolledOut(ss *appsv1.StatefulSet) bool {
+	replicas := int32(1)
+	if ss.Spec.Replicas != nil {
+		replicas = *ss.Spec.Replicas
+	}
+	return ss.Status.ObservedGeneration >= ss.Generation &&
+		ss.Status.UpdateRevision == ss.Status.CurrentRevision &&
+		ss.Status.UpdatedReplicas == replicas &&
+		ss.Status.ReadyReplicas == replicas
+}
+
 func allowedNamespace(current string, namespaces string) bool {
 
 	clusterConfigNamespaces := splitList(namespaces)
@@ -1190,19 +1449,6 @@ func containsString(arr []string, s string) bool {
 	return false
 }
 
-// DeprecationEventEmissionStatus is meant to track which deprecation events have been emitted already. This is temporary and can be removed in v0.0.6 once we have provided enough
-// deprecation notice
-type DeprecationEventEmissionStatus struct {
-	SSOSpecDeprecationWarningEmitted    bool
-	DexSpecDeprecationWarningEmitted    bool
-	DisableDexDeprecationWarningEmitted bool
-}
-
-// DeprecationEventEmissionTracker map stores the namespace containing ArgoCD instance as key and DeprecationEventEmissionStatus as value,
-// where DeprecationEventEmissionStatus tracks the events that have been emitted for the instance in the particular namespace.
-// This is temporary and can be removed in v0.0.6 when we remove the deprecated fields.
-var DeprecationEventEmissionTracker = make(map[string]DeprecationEventEmissionStatus)
-
 func namespaceFilterPredicate() predicate.Predicate {
 	return predicate.Funcs{
 		UpdateFunc: func(e event.UpdateEvent) bool {
@@ -1253,7 +1499,8 @@ func namespaceFilterPredicate() predicate.Predicate {
 				}
 
 			}
-			return false
+			// Label changes may add or remove the namespace from the managed or source namespace selectors
+			return !reflect.DeepEqual(e.ObjectOld.GetLabels(), e.ObjectNew.GetLabels())
 		},
 		DeleteFunc: func(e event.DeleteEvent) bool {
 			if ns, ok := e.Object.GetLabels()[common.ArgoCDManagedByLabel]; ok && ns != "" {
@@ -1270,10 +1517,6 @@ func namespaceFilterPredicate() predicate.Predicate {
 					log.Info(fmt.Sprintf("Successfully deleted namespace %s from cluster secret", e.Object.GetName()))
 				}
 			}
-
-			// if a namespace is deleted, remove it from deprecationEventEmissionTracker (if exists) so that if a namespace with the same name
-			// is created in the future and contains an Argo CD instance, it will be tracked appropriately
-			delete(DeprecationEventEmissionTracker, e.Object.GetName())
 			return false
 		},
 	}
@@ -1393,6 +1636,10 @@ func getLogFormat(logField string) string {
 }
 
 func (r *ReconcileArgoCD) setManagedNamespaces(cr *argoproj.ArgoCD) error {
+	if err := r.reconcileManagedNamespaceSelector(cr); err != nil {
+		return err
+	}
+
 	namespaces := &corev1.NamespaceList{}
 	listOption := client.MatchingLabels{
 		common.ArgoCDManagedByLabel: cr.Namespace,
@@ -1408,18 +1655,113 @@ func (r *ReconcileArgoCD) setManagedNamespaces(cr *argoproj.ArgoCD) error {
 	return nil
 }
 
+// reconcileManagedNamespaceSelector labels the namespaces selected by the managed namespace selector of the given
+// ArgoCD as managed by it, and removes the label from the namespaces it labeled that are no longer selected. The
+// namespaces labeled by the selector are annotated with the ArgoCD, so that namespaces labeled by users are left
+// untouched. All the labels set by the selector are removed when the ArgoCD is deleted.
+func (r *ReconcileArgoCD) reconcileManagedNamespaceSelector(cr *argoproj.ArgoCD) error {
+	var selector labels.Selector
+	if cr.Spec.ManagedNamespaceSelector != nil && cr.GetDeletionTimestamp() == nil {
+		s, err := getManagedNamespaceSelector(cr.Spec.ManagedNamespaceSelector)
+		if err != nil {
+			return err
+		}
+		selector = s
+	}
+
+	namespaces := &corev1.NamespaceList{}
+	if err := r.Client.List(context.TODO(), namespaces, &client.ListOptions{}); err != nil {
+		return err
+	}
+
+	key := instanceKey(cr)
+	for i := range namespaces.Items {
+		namespace := &namespaces.Items[i]
+		if namespace.Name == cr.Namespace {
+			continue
+		}
+
+		selected := selector != nil && selector.Matches(labels.Set(namespace.Labels))
+		marked := namespace.Annotations[common.AnnotationManagedNamespaceSelector] == key
+		if selected == marked {
+			continue
+		}
+
+		if selected {
+			if value, ok := namespace.Labels[common.ArgoCDManagedByLabel]; ok {
+				if value != cr.Namespace {
+					log.Info(fmt.Sprintf("Namespace %s is selected by argocd instance %s but already managed-by namespace %s, skipping", namespace.Name, key, value))
+				}
+				continue
+			}
+			if namespace.Labels == nil {
+				namespace.Labels = make(map[string]string)
+			}
+			if namespace.Annotations == nil {
+				namespace.Annotations = make(map[string]string)
+			}
+			namespace.Labels[common.ArgoCDManagedByLabel] = cr.Namespace
+			namespace.Annotations[common.AnnotationManagedNamespaceSelector] = key
+			log.Info(fmt.Sprintf("Labeling namespace %s selected by argocd instance %s as managed", namespace.Name, key))
+		} else {
+			if namespace.Labels[common.ArgoCDManagedByLabel] == cr.Namespace {
+				delete(namespace.Labels, common.ArgoCDManagedByLabel)
+			}
+			delete(namespace.Annotations, common.AnnotationManagedNamespaceSelector)
+			log.Info(fmt.Sprintf("Removing managed label of namespace %s no longer selected by argocd instance %s", namespace.Name, key))
+		}
+
+		if err := r.Client.Update(context.TODO(), namespace); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getManagedNamespaceSelector returns the selector of the given managed namespace selector. An empty selector, which
+// would select every namespace of the cluster, is rejected.
+func getManagedNamespaceSelector(selector *metav1.LabelSelector) (labels.Selector, error) {
+	if len(selector.MatchLabels) == 0 && len(selector.MatchExpressions) == 0 {
+		return nil, fmt.Errorf("invalid managed namespace selector: matchLabels or matchExpressions must be set")
+	}
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid managed namespace selector: %w", err)
+	}
+	return s, nil
+}
+
+// getSourceNamespaceSelector returns the selector of the source namespaces of the given ArgoCD, or nil if it has none.
+func getSourceNamespaceSelector(cr *argoproj.ArgoCD) (labels.Selector, error) {
+	if cr.Spec.SourceNamespaceSelector == nil {
+		return nil, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(cr.Spec.SourceNamespaceSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source namespace selector: %w", err)
+	}
+	return selector, nil
+}
+
 // getSourceNamespaces retrieves a list of namespaces that match the sourceNamespaces
-// pattern specified in the given ArgoCD
+// pattern or the sourceNamespaceSelector specified in the given ArgoCD
 func (r *ReconcileArgoCD) getSourceNamespaces(cr *argoproj.ArgoCD) ([]string, error) {
 	sourceNamespaces := []string{}
 	namespaces := &corev1.NamespaceList{}
 
+	selector, err := getSourceNamespaceSelector(cr)
+	if err != nil {
+		return nil, err
+	}
+
 	if err := r.Client.List(context.TODO(), namespaces, &client.ListOptions{}); err != nil {
 		return nil, err
 	}
 
 	for _, namespace := range namespaces.Items {
-		if glob.MatchStringInList(cr.Spec.SourceNamespaces, namespace.Name, false) {
+		if glob.MatchStringInList(cr.Spec.SourceNamespaces, namespace.Name, false) ||
+			(selector != nil && selector.Matches(labels.Set(namespace.Labels))) {
 			sourceNamespaces = append(sourceNamespaces, namespace.Name)
 		}
 	}
@@ -1427,6 +1769,32 @@ func (r *ReconcileArgoCD) getSourceNamespaces(cr *argoproj.ArgoCD) ([]string, er
 	return sourceNamespaces, nil
 }
 
+// getApplicationNamespaces returns the value of the --application-namespaces argument of the Argo CD components: the
+// sourceNamespaces patterns of the given ArgoCD followed by the namespaces selected by its sourceNamespaceSelector.
+func (r *ReconcileArgoCD) getApplicationNamespaces(cr *argoproj.ArgoCD) ([]string, error) {
+	applicationNamespaces := append([]string{}, cr.Spec.SourceNamespaces...)
+
+	selector, err := getSourceNamespaceSelector(cr)
+	if err != nil || selector == nil {
+		return applicationNamespaces, err
+	}
+
+	namespaces := &corev1.NamespaceList{}
+	if err := r.Client.List(context.TODO(), namespaces, &client.ListOptions{LabelSelector: selector}); err != nil {
+		return nil, err
+	}
+
+	selected := []string{}
+	for _, namespace := range namespaces.Items {
+		if !contains(applicationNamespaces, namespace.Name) {
+			selected = append(selected, namespace.Name)
+		}
+	}
+	sort.Strings(selected)
+
+	return append(applicationNamespaces, selected...), nil
+}
+
 func (r *ReconcileArgoCD) setManagedSourceNamespaces(cr *argoproj.ArgoCD) error {
 	r.ManagedSourceNamespaces = make(map[string]string)
 	namespaces := &corev1.NamespaceList{}
@@ -1443,6 +1811,19 @@ func (r *ReconcileArgoCD) setManagedSourceNamespaces(cr *argoproj.ArgoCD) error
 		r.ManagedSourceNamespaces[namespace.Name] = ""
 	}
 
+	// namespaces selected by the source namespace selector are members as well, even before being labeled
+	selector, err := getSourceNamespaceSelector(cr)
+	if err != nil || selector == nil {
+		return err
+	}
+	selected := &corev1.NamespaceList{}
+	if err := r.Client.List(context.TODO(), selected, &client.ListOptions{LabelSelector: selector}); err != nil {
+		return err
+	}
+	for _, namespace := range selected.Items {
+		r.ManagedSourceNamespaces[namespace.Name] = ""
+	}
+
 	return nil
 }
 
@@ -1580,11 +1961,11 @@ func getOpenShiftAPIURL() string {
 	return out
 }
 
-func AddSeccompProfileForOpenShift(client client.Client, podspec *corev1.PodSpec) {
-	if !IsVersionAPIAvailable() {
+func AddSeccompProfileForOpenShift(client client.Client, apis *ClusterAPIs, podspec *corev1.PodSpec) {
+	if !apis.IsVersionAPIAvailable() {
 		return
 	}
-	version, err := getClusterVersion(client)
+	version, err := getClusterVersion(client, apis)
 	if err != nil {
 		log.Error(err, "couldn't get OpenShift version")
 	}
@@ -1602,8 +1983,8 @@ func AddSeccompProfileForOpenShift(client client.Client, podspec *corev1.PodSpec
 }
 
 // getClusterVersion returns the OpenShift Cluster version in which the operator is installed
-func getClusterVersion(client client.Client) (string, error) {
-	if !IsVersionAPIAvailable() {
+func getClusterVersion(client client.Client, apis *ClusterAPIs) (string, error) {
+	if !apis.IsVersionAPIAvailable() {
 		return "", nil
 	}
 	clusterVersion := &configv1.ClusterVersion{}
@@ -21,6 +21,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	testclient "k8s.io/client-go/kubernetes/fake"
 )
 
@@ -249,20 +250,12 @@ var argoServerURITests = []struct {
 	},
 }
 
-func setRouteAPIFound(t *testing.T, routeEnabled bool) {
-	routeAPIEnabledTemp := routeAPIFound
-	t.Cleanup(func() {
-		routeAPIFound = routeAPIEnabledTemp
-	})
-	routeAPIFound = routeEnabled
-}
-
 func TestGetArgoServerURI(t *testing.T) {
 	for _, tt := range argoServerURITests {
 		t.Run(tt.name, func(t *testing.T) {
 			cr := makeTestArgoCD(tt.opts...)
-			r := &ReconcileArgoCD{}
-			setRouteAPIFound(t, tt.routeEnabled)
+			r := &ReconcileArgoCD{ClusterAPIs: &ClusterAPIs{}}
+			r.ClusterAPIs.setRouteAPIAvailable(tt.routeEnabled)
 			result := r.getArgoServerURI(cr)
 			if result != tt.want {
 				t.Errorf("%s test failed, got=%q want=%q", tt.name, result, tt.want)
@@ -568,7 +561,7 @@ func TestGetArgoApplicationControllerCommand(t *testing.T) {
 
 	for _, tt := range cmdTests {
 		cr := makeTestArgoCD(tt.opts...)
-		cmd := getArgoApplicationControllerCommand(cr, false)
+		cmd := getArgoApplicationControllerCommand(cr, false, cr.Spec.SourceNamespaces)
 
 		if !reflect.DeepEqual(cmd, tt.want) {
 			t.Fatalf("got %#v, want %#v", cmd, tt.want)
@@ -849,6 +842,166 @@ func TestSetManagedSourceNamespaces(t *testing.T) {
 	assert.Contains(t, r.ManagedSourceNamespaces, "test-namespace-1")
 }
 
+func TestSetManagedNamespacesWithSelector(t *testing.T) {
+	a := makeTestArgoCD()
+	a.Spec.ManagedNamespaceSelector = &metav1.LabelSelector{
+		MatchLabels: map[string]string{"tenant": "team-a"},
+	}
+
+	// selected namespace, to be labeled
+	ns1 := v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "test-namespace-1",
+			Labels: map[string]string{"tenant": "team-a"},
+		},
+	}
+	// previously selected namespace, to be released
+	ns2 := v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-namespace-2",
+			Labels: map[string]string{
+				common.ArgoCDManagedByLabel: testNamespace,
+			},
+			Annotations: map[string]string{
+				common.AnnotationManagedNamespaceSelector: instanceKey(a),
+			},
+		},
+	}
+	// selected namespace already managed by another namespace
+	ns3 := v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-namespace-3",
+			Labels: map[string]string{
+				"tenant":                    "team-a",
+				common.ArgoCDManagedByLabel: "random-namespace",
+			},
+		},
+	}
+	// namespace labeled by the user, not selected
+	ns4 := v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-namespace-4",
+			Labels: map[string]string{
+				common.ArgoCDManagedByLabel: testNamespace,
+			},
+		},
+	}
+
+	resObjs := []client.Object{a, &ns1, &ns2, &ns3, &ns4}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.setManagedNamespaces(a))
+
+	managed := []string{}
+	for _, n := range r.ManagedNamespaces.Items {
+		managed = append(managed, n.Name)
+	}
+	assert.ElementsMatch(t, []string{testNamespace, "test-namespace-1", "test-namespace-4"}, managed)
+
+	ns := &v1.Namespace{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "test-namespace-1"}, ns))
+	assert.Equal(t, testNamespace, ns.Labels[common.ArgoCDManagedByLabel])
+	assert.Equal(t, instanceKey(a), ns.Annotations[common.AnnotationManagedNamespaceSelector])
+
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "test-namespace-2"}, ns))
+	assert.NotContains(t, ns.Labels, common.ArgoCDManagedByLabel)
+	assert.NotContains(t, ns.Annotations, common.AnnotationManagedNamespaceSelector)
+
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "test-namespace-3"}, ns))
+	assert.Equal(t, "random-namespace", ns.Labels[common.ArgoCDManagedByLabel])
+	assert.NotContains(t, ns.Annotations, common.AnnotationManagedNamespaceSelector)
+
+	// the labels set by the selector are removed on deletion, the ones set by users are kept
+	now := metav1.Now()
+	a.DeletionTimestamp = &now
+	assert.NoError(t, r.reconcileManagedNamespaceSelector(a))
+
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "test-namespace-1"}, ns))
+	assert.NotContains(t, ns.Labels, common.ArgoCDManagedByLabel)
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "test-namespace-4"}, ns))
+	assert.Equal(t, testNamespace, ns.Labels[common.ArgoCDManagedByLabel])
+}
+
+func TestSetManagedNamespacesWithEmptySelector(t *testing.T) {
+	a := makeTestArgoCD()
+	a.Spec.ManagedNamespaceSelector = &metav1.LabelSelector{}
+
+	ns1 := v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "test-namespace-1",
+			Labels: map[string]string{"tenant": "team-a"},
+		},
+	}
+
+	resObjs := []client.Object{a, &ns1}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	// an empty selector would select every namespace of the cluster, it is rejected
+	assert.ErrorContains(t, r.setManagedNamespaces(a), "matchLabels or matchExpressions must be set")
+
+	ns := &v1.Namespace{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "test-namespace-1"}, ns))
+	assert.NotContains(t, ns.Labels, common.ArgoCDManagedByLabel)
+}
+
+func TestSetManagedSourceNamespacesWithSelector(t *testing.T) {
+	a := makeTestArgoCD()
+	a.Spec = argoproj.ArgoCDSpec{
+		SourceNamespaceSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"tenant": "team-a"},
+		},
+	}
+	ns1 := v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "test-namespace-1",
+			Labels: map[string]string{"tenant": "team-a"},
+		},
+	}
+	ns2 := v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "test-namespace-2",
+			Labels: map[string]string{"tenant": "team-b"},
+		},
+	}
+
+	resObjs := []client.Object{a, &ns1, &ns2}
+	subresObjs := []client.Object{a}
+	runtimeObjs := []runtime.Object{}
+	sch := makeTestReconcilerScheme(argoproj.AddToScheme)
+	cl := makeTestReconcilerClient(sch, resObjs, subresObjs, runtimeObjs)
+	r := makeTestReconciler(cl, sch)
+
+	assert.NoError(t, r.setManagedSourceNamespaces(a))
+	assert.Equal(t, 1, len(r.ManagedSourceNamespaces))
+	assert.Contains(t, r.ManagedSourceNamespaces, "test-namespace-1")
+
+	sourceNamespaces, err := r.getSourceNamespaces(a)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"test-namespace-1"}, sourceNamespaces)
+
+	a.Spec.SourceNamespaces = []string{"test-namespace-1", "other-*"}
+	applicationNamespaces, err := r.getApplicationNamespaces(a)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"test-namespace-1", "other-*"}, applicationNamespaces)
+
+	a.Spec.SourceNamespaces = []string{"other-*"}
+	applicationNamespaces, err = r.getApplicationNamespaces(a)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"other-*", "test-namespace-1"}, applicationNamespaces)
+
+	a.Spec.SourceNamespaceSelector.MatchExpressions = []metav1.LabelSelectorRequirement{{Key: "tenant", Operator: "Invalid"}}
+	_, err = r.getApplicationNamespaces(a)
+	assert.Error(t, err)
+}
+
 func TestGetSourceNamespacesWithWildcardPatternNamespace(t *testing.T) {
 	a := makeTestArgoCD()
 	a.Spec = argoproj.ArgoCDSpec{
@@ -1069,3 +1222,51 @@ func TestReconcileArgoCD_reconcileDexOAuthClientSecret(t *testing.T) {
 	}
 	assert.True(t, tokenExists, "Dex is enabled but unable to create oauth client secret")
 }
+
+func TestGetRedisHASentinelQuorum(t *testing.T) {
+	tests := []struct {
+		name     string
+		replicas *int32
+		quorum   *int32
+		want     int32
+	}{
+		{"defaults", nil, nil, 2},
+		{"majority of replicas", int32Ptr(5), nil, 3},
+		{"quorum override", int32Ptr(5), int32Ptr(4), 4},
+		{"quorum capped at replicas", int32Ptr(3), int32Ptr(5), 3},
+		{"replicas below minimum", int32Ptr(1), nil, 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+				a.Spec.HA.RedisReplicas = test.replicas
+				a.Spec.HA.SentinelQuorum = test.quorum
+			})
+			assert.Equal(t, test.want, getRedisHASentinelQuorum(a))
+		})
+	}
+}
+
+func TestGetRedisHAConfig_topology(t *testing.T) {
+	t.Setenv("REDIS_CONFIG_PATH", "../../build/redis")
+
+	a := makeTestArgoCD(func(a *argoproj.ArgoCD) {
+		a.Spec.HA.RedisReplicas = int32Ptr(5)
+		a.Spec.HA.Persistence = &argoproj.ArgoCDRedisHAPersistenceSpec{Enabled: true}
+	})
+
+	haproxyConfig := getRedisHAProxyConfig(a, false)
+	assert.Contains(t, haproxyConfig, "backend check_if_redis_is_master_4")
+	assert.Contains(t, haproxyConfig, "server R4 argocd-redis-ha-announce-4:26379 check inter 3s")
+	assert.Contains(t, haproxyConfig, "use-server R4 if { srv_is_up(R4) } { nbsrv(check_if_redis_is_master_4) ge 3 }")
+	assert.NotContains(t, haproxyConfig, "check_if_redis_is_master_5")
+
+	haproxyScript := getRedisHAProxyScript(a)
+	assert.Contains(t, haproxyScript, `sed -i "s/REPLACE_ANNOUNCE4$/$ANNOUNCE_IP4/" "$HAPROXY_CONF"`)
+
+	assert.Contains(t, getRedisInitScript(a, false), `QUORUM="3"`)
+	assert.Contains(t, getRedisConf(a, false), "save 3600 1 300 100 60 10000")
+
+	a.Spec.HA.Persistence = nil
+	assert.Contains(t, getRedisConf(a, false), `save ""`)
+}
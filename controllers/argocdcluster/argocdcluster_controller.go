@@ -0,0 +1,192 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package argocdcluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logr "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1alpha1 "github.com/argoproj-labs/argocd-operator/api/v1alpha1"
+)
+
+const (
+	// connectionCheckInterval is the interval at which a connected cluster is tested again.
+	connectionCheckInterval = 10 * time.Minute
+
+	// connectionRetryInterval is the interval at which a cluster whose connection test failed is tested again.
+	connectionRetryInterval = time.Minute
+)
+
+var log = logr.Log.WithName("controller_argocdcluster")
+
+// blank assignment to verify that ArgoCDClusterReconciler implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ArgoCDClusterReconciler{}
+
+// ArgoCDClusterReconciler reconciles an ArgoCDCluster object: it writes the cluster secret read by Argo CD, and tests
+// the connection to the cluster with it.
+type ArgoCDClusterReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=argoproj.io,resources=argocdclusters;argocdclusters/finalizers,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=argoproj.io,resources=argocdclusters/status,verbs=get;update;patch
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.9.2/pkg/reconcile
+func (r *ArgoCDClusterReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	reqLogger := logr.FromContext(ctx, "Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	reqLogger.Info("Reconciling ArgoCDCluster")
+
+	cluster := &v1alpha1.ArgoCDCluster{}
+	err := r.Client.Get(ctx, request.NamespacedName, cluster)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
+			// Return and don't requeue
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, err
+	}
+	if cluster.DeletionTimestamp != nil {
+		return reconcile.Result{}, nil
+	}
+
+	status := cluster.Status.DeepCopy()
+	status.ObservedGeneration = cluster.Generation
+	status.SecretName = getClusterSecretName(cluster)
+
+	config, err := r.reconcileCluster(ctx, cluster)
+	var invalid invalidError
+	if errors.As(err, &invalid) {
+		// the ArgoCDCluster, or the resources it references, are watched
+		status.Phase = v1alpha1.ArgoCDClusterPhaseInvalid
+		status.Message = invalid.Error()
+		return reconcile.Result{}, r.updateStatus(ctx, cluster, status)
+	} else if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	result := reconcile.Result{RequeueAfter: connectionCheckInterval}
+	version, err := testConnection(cluster.Spec.Server, config)
+	if err != nil {
+		log.Info(fmt.Sprintf("connection test of ArgoCDCluster %s/%s failed: %v", cluster.Namespace, cluster.Name, err))
+		status.Phase = v1alpha1.ArgoCDClusterPhaseConnectionFailed
+		status.Message = fmt.Sprintf("connection test failed: %v", err)
+		result.RequeueAfter = connectionRetryInterval
+	} else {
+		now := metav1.Now()
+		status.Phase = v1alpha1.ArgoCDClusterPhaseConnected
+		status.Message = ""
+		if config.ExecProviderConfig != nil {
+			status.Message = "the credentials of the exec provider are not verified, the operator does not run its command"
+		}
+		status.ServerVersion = version
+		status.LastConnectionTime = &now
+	}
+	return result, r.updateStatus(ctx, cluster, status)
+}
+
+// reconcileCluster writes the cluster secret of the given ArgoCDCluster, and returns the configuration of the cluster.
+func (r *ArgoCDClusterReconciler) reconcileCluster(ctx context.Context, cluster *v1alpha1.ArgoCDCluster) (clusterConfig, error) {
+	if err := validateCluster(cluster); err != nil {
+		return clusterConfig{}, err
+	}
+	config, err := r.getClusterConfig(ctx, cluster)
+	if err != nil {
+		return config, err
+	}
+	return config, r.reconcileClusterSecret(ctx, cluster, config)
+}
+
+// updateStatus updates the status of the given ArgoCDCluster when it changed.
+func (r *ArgoCDClusterReconciler) updateStatus(ctx context.Context, cluster *v1alpha1.ArgoCDCluster, status *v1alpha1.ArgoCDClusterStatus) error {
+	if reflect.DeepEqual(cluster.Status, *status) {
+		return nil
+	}
+	cluster.Status = *status
+	return r.Client.Status().Update(ctx, cluster)
+}
+
+// referenceMapper maps a watch event on a Secret or ConfigMap back to the ArgoCDClusters of its namespace referencing
+// it.
+func (r *ArgoCDClusterReconciler) referenceMapper(ctx context.Context, o client.Object) []reconcile.Request {
+	clusters := &v1alpha1.ArgoCDClusterList{}
+	if err := r.Client.List(ctx, clusters, client.InNamespace(o.GetNamespace())); err != nil {
+		log.Error(err, fmt.Sprintf("failed to list the ArgoCDClusters of namespace %s", o.GetNamespace()))
+		return nil
+	}
+
+	_, isConfigMap := o.(*corev1.ConfigMap)
+	var result []reconcile.Request
+	for _, cluster := range clusters.Items {
+		if isConfigMap && referencesConfigMap(&cluster, o.GetName()) || !isConfigMap && referencesSecret(&cluster, o.GetName()) {
+			result = append(result, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}})
+		}
+	}
+	return result
+}
+
+// referencesSecret returns true when the given ArgoCDCluster reads the credentials of its cluster from the named
+// Secret.
+func referencesSecret(cluster *v1alpha1.ArgoCDCluster, name string) bool {
+	spec := cluster.Spec
+	for _, ref := range []*corev1.SecretKeySelector{spec.BearerTokenSecret, spec.TLS.ClientCertSecret, spec.TLS.ClientKeySecret} {
+		if ref != nil && ref.Name == name {
+			return true
+		}
+	}
+	return spec.ExecProvider != nil && spec.ExecProvider.EnvSecretName == name
+}
+
+// referencesConfigMap returns true when the given ArgoCDCluster reads the CA certificate of its cluster from the named
+// ConfigMap.
+func referencesConfigMap(cluster *v1alpha1.ArgoCDCluster, name string) bool {
+	return cluster.Spec.TLS.CAConfigMap != nil && cluster.Spec.TLS.CAConfigMap.Name == name
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ArgoCDClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	referenceHandler := handler.EnqueueRequestsFromMapFunc(r.referenceMapper)
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.ArgoCDCluster{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Owns(&corev1.Secret{}).
+		Watches(&corev1.Secret{}, referenceHandler).
+		Watches(&corev1.ConfigMap{}, referenceHandler).
+		Complete(r)
+}
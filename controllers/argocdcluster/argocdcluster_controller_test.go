@@ -0,0 +1,334 @@
+package argocdcluster
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/argoproj-labs/argocd-operator/api/v1alpha1"
+	"github.com/argoproj-labs/argocd-operator/common"
+)
+
+const (
+	testNamespace = "argocd"
+	testToken     = "s3cr3t"
+)
+
+// newTestAPIServer returns a stand-in for the API server of a cluster. Like a real API server, it serves its version
+// to every request, and its API resources to the requests authenticated with the given bearer token only, or to every
+// request when the token is empty.
+func newTestAPIServer(t *testing.T, token string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch req.URL.Path {
+		case "/version":
+			_ = json.NewEncoder(w).Encode(map[string]string{"gitVersion": "v1.28.3"})
+		case "/api":
+			if token != "" && req.Header.Get("Authorization") != "Bearer "+token {
+				w.WriteHeader(http.StatusUnauthorized)
+				_ = json.NewEncoder(w).Encode(metav1.Status{
+					TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"},
+					Status:   metav1.StatusFailure,
+					Message:  "Unauthorized",
+					Reason:   metav1.StatusReasonUnauthorized,
+					Code:     http.StatusUnauthorized,
+				})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(metav1.APIVersions{
+				TypeMeta: metav1.TypeMeta{Kind: "APIVersions"},
+				Versions: []string{"v1"},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// makeTestCAConfigMap returns a ConfigMap holding the certificate of the given server, as its CA.
+func makeTestCAConfigMap(server *httptest.Server) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-ca", Namespace: testNamespace},
+		Data: map[string]string{
+			"ca.crt": string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})),
+		},
+	}
+}
+
+func makeTestTokenSecret(token string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-token", Namespace: testNamespace},
+		Data:       map[string][]byte{"token": []byte(token + "\n")},
+	}
+}
+
+func makeTestCluster(server string, opts ...func(*v1alpha1.ArgoCDCluster)) *v1alpha1.ArgoCDCluster {
+	cluster := &v1alpha1.ArgoCDCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "remote",
+			Namespace:  testNamespace,
+			Generation: 1,
+		},
+		Spec: v1alpha1.ArgoCDClusterSpec{
+			Server: server,
+			BearerTokenSecret: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "cluster-token"},
+				Key:                  "token",
+			},
+			TLS: v1alpha1.ArgoCDClusterTLS{
+				CAConfigMap: &corev1.ConfigMapKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "cluster-ca"},
+					Key:                  "ca.crt",
+				},
+			},
+		},
+	}
+	for _, opt := range opts {
+		opt(cluster)
+	}
+	return cluster
+}
+
+func makeTestReconciler(t *testing.T, objs ...client.Object) *ArgoCDClusterReconciler {
+	t.Helper()
+	sch := runtime.NewScheme()
+	assert.NoError(t, scheme.AddToScheme(sch))
+	assert.NoError(t, v1alpha1.AddToScheme(sch))
+	cl := fake.NewClientBuilder().WithScheme(sch).WithObjects(objs...).WithStatusSubresource(&v1alpha1.ArgoCDCluster{}).Build()
+	return &ArgoCDClusterReconciler{Client: cl, Scheme: sch}
+}
+
+func reconcileTestCluster(t *testing.T, r *ArgoCDClusterReconciler, cluster *v1alpha1.ArgoCDCluster) (reconcile.Result, *v1alpha1.ArgoCDCluster) {
+	t.Helper()
+	key := types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}
+	result, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+	assert.NoError(t, err)
+
+	cluster = &v1alpha1.ArgoCDCluster{}
+	assert.NoError(t, r.Client.Get(context.TODO(), key, cluster))
+	return result, cluster
+}
+
+func getTestClusterSecret(t *testing.T, r *ArgoCDClusterReconciler) *corev1.Secret {
+	t.Helper()
+	secret := &corev1.Secret{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: "cluster-remote"}, secret))
+	return secret
+}
+
+func TestArgoCDClusterReconciler_Reconcile(t *testing.T) {
+	server := newTestAPIServer(t, testToken)
+	shard := int64(2)
+	cluster := makeTestCluster(server.URL, func(c *v1alpha1.ArgoCDCluster) {
+		c.Spec.Name = "production"
+		c.Spec.Namespaces = []string{"team-a", "team-b"}
+		c.Spec.ClusterResources = true
+		c.Spec.Shard = &shard
+		c.Spec.Labels = map[string]string{"env": "production"}
+	})
+	r := makeTestReconciler(t, cluster, makeTestTokenSecret(testToken), makeTestCAConfigMap(server))
+
+	result, cluster := reconcileTestCluster(t, r, cluster)
+
+	assert.Equal(t, v1alpha1.ArgoCDClusterPhaseConnected, cluster.Status.Phase)
+	assert.Equal(t, "v1.28.3", cluster.Status.ServerVersion)
+	assert.Equal(t, "cluster-remote", cluster.Status.SecretName)
+	assert.NotNil(t, cluster.Status.LastConnectionTime)
+	assert.Equal(t, int64(1), cluster.Status.ObservedGeneration)
+	assert.Equal(t, connectionCheckInterval, result.RequeueAfter)
+
+	secret := getTestClusterSecret(t, r)
+	assert.Equal(t, map[string]string{common.ArgoCDSecretTypeLabel: "cluster", "env": "production"}, secret.Labels)
+	assert.True(t, metav1.IsControlledBy(secret, cluster))
+	assert.Equal(t, "production", string(secret.Data["name"]))
+	assert.Equal(t, server.URL, string(secret.Data["server"]))
+	assert.Equal(t, "team-a,team-b", string(secret.Data["namespaces"]))
+	assert.Equal(t, "true", string(secret.Data["clusterResources"]))
+	assert.Equal(t, "2", string(secret.Data["shard"]))
+
+	config := clusterConfig{}
+	assert.NoError(t, json.Unmarshal(secret.Data["config"], &config))
+	assert.Equal(t, testToken, config.BearerToken)
+	assert.Equal(t, makeTestCAConfigMap(server).Data["ca.crt"], string(config.TLSClientConfig.CAData))
+	assert.Nil(t, config.ExecProviderConfig)
+
+	// the cluster secret follows the ArgoCDCluster
+	cluster.Spec.Shard = nil
+	cluster.Spec.Namespaces = nil
+	assert.NoError(t, r.Client.Update(context.TODO(), cluster))
+	reconcileTestCluster(t, r, cluster)
+
+	secret = getTestClusterSecret(t, r)
+	assert.NotContains(t, secret.Data, "shard")
+	assert.NotContains(t, secret.Data, "namespaces")
+	assert.NotContains(t, secret.Data, "clusterResources")
+}
+
+func TestArgoCDClusterReconciler_Reconcile_connectionFailed(t *testing.T) {
+	server := newTestAPIServer(t, testToken)
+	cluster := makeTestCluster(server.URL)
+	r := makeTestReconciler(t, cluster, makeTestTokenSecret("wrong"), makeTestCAConfigMap(server))
+
+	result, cluster := reconcileTestCluster(t, r, cluster)
+
+	// the token is rejected although the version is served without credentials, the cluster secret is written
+	// anyway, the cluster may be unreachable from the operator only
+	assert.Equal(t, v1alpha1.ArgoCDClusterPhaseConnectionFailed, cluster.Status.Phase)
+	assert.Contains(t, cluster.Status.Message, "connection test failed: Unauthorized")
+	assert.Empty(t, cluster.Status.ServerVersion)
+	assert.Equal(t, connectionRetryInterval, result.RequeueAfter)
+	getTestClusterSecret(t, r)
+}
+
+func TestArgoCDClusterReconciler_Reconcile_untrustedCertificate(t *testing.T) {
+	server := newTestAPIServer(t, testToken)
+	cluster := makeTestCluster(server.URL, func(c *v1alpha1.ArgoCDCluster) {
+		c.Spec.TLS.CAConfigMap = nil
+	})
+	r := makeTestReconciler(t, cluster, makeTestTokenSecret(testToken))
+
+	_, cluster = reconcileTestCluster(t, r, cluster)
+	assert.Equal(t, v1alpha1.ArgoCDClusterPhaseConnectionFailed, cluster.Status.Phase)
+
+	cluster.Spec.TLS.Insecure = true
+	assert.NoError(t, r.Client.Update(context.TODO(), cluster))
+	_, cluster = reconcileTestCluster(t, r, cluster)
+	assert.Equal(t, v1alpha1.ArgoCDClusterPhaseConnected, cluster.Status.Phase)
+}
+
+func TestArgoCDClusterReconciler_Reconcile_execProvider(t *testing.T) {
+	server := newTestAPIServer(t, "")
+	cluster := makeTestCluster(server.URL, func(c *v1alpha1.ArgoCDCluster) {
+		c.Spec.BearerTokenSecret = nil
+		c.Spec.ExecProvider = &v1alpha1.ArgoCDClusterExecProvider{
+			Command:       "argocd-k8s-auth",
+			Args:          []string{"aws", "--cluster-name", "remote"},
+			Env:           map[string]string{"AWS_REGION": "eu-west-1"},
+			EnvSecretName: "cluster-env",
+			APIVersion:    "client.authentication.k8s.io/v1beta1",
+		}
+	})
+	env := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-env", Namespace: testNamespace},
+		Data:       map[string][]byte{"AWS_SECRET_ACCESS_KEY": []byte("key")},
+	}
+	r := makeTestReconciler(t, cluster, env, makeTestCAConfigMap(server))
+
+	_, cluster = reconcileTestCluster(t, r, cluster)
+
+	assert.Equal(t, v1alpha1.ArgoCDClusterPhaseConnected, cluster.Status.Phase)
+	assert.Contains(t, cluster.Status.Message, "not verified")
+
+	config := clusterConfig{}
+	assert.NoError(t, json.Unmarshal(getTestClusterSecret(t, r).Data["config"], &config))
+	assert.Empty(t, config.BearerToken)
+	assert.Equal(t, &clusterExecProviderConfig{
+		Command:    "argocd-k8s-auth",
+		Args:       []string{"aws", "--cluster-name", "remote"},
+		Env:        map[string]string{"AWS_REGION": "eu-west-1", "AWS_SECRET_ACCESS_KEY": "key"},
+		APIVersion: "client.authentication.k8s.io/v1beta1",
+	}, config.ExecProviderConfig)
+}
+
+func TestArgoCDClusterReconciler_Reconcile_invalid(t *testing.T) {
+	server := newTestAPIServer(t, testToken)
+	tests := []struct {
+		name    string
+		opt     func(*v1alpha1.ArgoCDCluster)
+		objs    []client.Object
+		message string
+	}{
+		{
+			name:    "missing token secret",
+			opt:     func(c *v1alpha1.ArgoCDCluster) {},
+			objs:    []client.Object{makeTestCAConfigMap(server)},
+			message: "secret cluster-token not found",
+		},
+		{
+			name: "missing token key",
+			opt: func(c *v1alpha1.ArgoCDCluster) {
+				c.Spec.BearerTokenSecret.Key = "other"
+			},
+			objs:    []client.Object{makeTestTokenSecret(testToken), makeTestCAConfigMap(server)},
+			message: "key other not found in secret cluster-token",
+		},
+		{
+			name: "in-cluster server",
+			opt: func(c *v1alpha1.ArgoCDCluster) {
+				c.Spec.Server = common.ArgoCDDefaultServer
+			},
+			objs:    []client.Object{makeTestTokenSecret(testToken), makeTestCAConfigMap(server)},
+			message: "the in-cluster cluster https://kubernetes.default.svc is registered by the ArgoCD itself",
+		},
+		{
+			name: "client certificate without key",
+			opt: func(c *v1alpha1.ArgoCDCluster) {
+				c.Spec.TLS.ClientCertSecret = &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "cluster-cert"},
+					Key:                  "tls.crt",
+				}
+			},
+			objs:    []client.Object{makeTestTokenSecret(testToken), makeTestCAConfigMap(server)},
+			message: "the client certificate and its key must be set together",
+		},
+		{
+			name: "unmanaged secret",
+			opt:  func(c *v1alpha1.ArgoCDCluster) {},
+			objs: []client.Object{
+				makeTestTokenSecret(testToken),
+				makeTestCAConfigMap(server),
+				&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "cluster-remote", Namespace: testNamespace}},
+			},
+			message: "secret cluster-remote already exists and is not managed by the ArgoCDCluster",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cluster := makeTestCluster(server.URL, test.opt)
+			r := makeTestReconciler(t, append(test.objs, cluster)...)
+
+			result, cluster := reconcileTestCluster(t, r, cluster)
+
+			assert.Equal(t, v1alpha1.ArgoCDClusterPhaseInvalid, cluster.Status.Phase)
+			assert.Equal(t, test.message, cluster.Status.Message)
+			assert.Equal(t, reconcile.Result{}, result)
+		})
+	}
+}
+
+func TestArgoCDClusterReconciler_referenceMapper(t *testing.T) {
+	cluster := makeTestCluster("https://remote.example.com")
+	other := makeTestCluster("https://other.example.com", func(c *v1alpha1.ArgoCDCluster) {
+		c.Name = "other"
+		c.Spec.BearerTokenSecret = nil
+		c.Spec.TLS.CAConfigMap = nil
+	})
+	r := makeTestReconciler(t, cluster, other)
+
+	requests := r.referenceMapper(context.TODO(), makeTestTokenSecret(testToken))
+	assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: "remote"}}}, requests)
+
+	requests = r.referenceMapper(context.TODO(), &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cluster-ca", Namespace: testNamespace}})
+	assert.Len(t, requests, 1)
+
+	// a ConfigMap named like a referenced Secret is not a reference
+	requests = r.referenceMapper(context.TODO(), &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cluster-token", Namespace: testNamespace}})
+	assert.Empty(t, requests)
+}
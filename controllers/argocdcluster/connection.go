@@ -0,0 +1,62 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package argocdcluster
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+)
+
+// connectionTimeout bounds the connection test of a cluster.
+const connectionTimeout = 10 * time.Second
+
+// testConnection requests the API resources of the core group of the given cluster with the given configuration, and
+// returns the version of its API server. Unlike the version, readable without credentials by default, the API
+// resources are only served to authenticated users, so that credentials rejected by the API server fail the test. The
+// command of an exec provider is not run, the operator image does not ship it: the API server is only required to be
+// reachable then.
+func testConnection(server string, config clusterConfig) (string, error) {
+	restConfig := &rest.Config{
+		Host: server,
+		TLSClientConfig: rest.TLSClientConfig{
+			Insecure:   config.TLSClientConfig.Insecure,
+			ServerName: config.TLSClientConfig.ServerName,
+			CertData:   config.TLSClientConfig.CertData,
+			KeyData:    config.TLSClientConfig.KeyData,
+			CAData:     config.TLSClientConfig.CAData,
+		},
+		BearerToken: config.BearerToken,
+		Timeout:     connectionTimeout,
+	}
+	client, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return "", err
+	}
+	if config.ExecProviderConfig == nil {
+		if err := client.RESTClient().Get().AbsPath("/api").Do(context.TODO()).Error(); err != nil {
+			return "", err
+		}
+	}
+	version, err := client.ServerVersion()
+	if err != nil {
+		return "", err
+	}
+	return version.GitVersion, nil
+}
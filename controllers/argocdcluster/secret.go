@@ -0,0 +1,266 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package argocdcluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	v1alpha1 "github.com/argoproj-labs/argocd-operator/api/v1alpha1"
+	"github.com/argoproj-labs/argocd-operator/common"
+)
+
+// clusterConfig is the configuration of a cluster in the cluster secret, as read by Argo CD.
+type clusterConfig struct {
+	BearerToken        string                     `json:"bearerToken,omitempty"`
+	TLSClientConfig    clusterTLSClientConfig     `json:"tlsClientConfig"`
+	ExecProviderConfig *clusterExecProviderConfig `json:"execProviderConfig,omitempty"`
+}
+
+type clusterTLSClientConfig struct {
+	Insecure   bool   `json:"insecure"`
+	ServerName string `json:"serverName,omitempty"`
+	CertData   []byte `json:"certData,omitempty"`
+	KeyData    []byte `json:"keyData,omitempty"`
+	CAData     []byte `json:"caData,omitempty"`
+}
+
+type clusterExecProviderConfig struct {
+	Command     string            `json:"command,omitempty"`
+	Args        []string          `json:"args,omitempty"`
+	Env         map[string]string `json:"env,omitempty"`
+	APIVersion  string            `json:"apiVersion,omitempty"`
+	InstallHint string            `json:"installHint,omitempty"`
+}
+
+// invalidError reports an ArgoCDCluster that cannot be turned into a cluster secret until it, or a resource it
+// references, changes.
+type invalidError struct {
+	error
+}
+
+func invalidf(format string, args ...interface{}) error {
+	return invalidError{fmt.Errorf(format, args...)}
+}
+
+// getClusterSecretName returns the name of the cluster secret of the given ArgoCDCluster.
+func getClusterSecretName(cluster *v1alpha1.ArgoCDCluster) string {
+	return fmt.Sprintf("cluster-%s", cluster.Name)
+}
+
+// validateCluster returns an invalidError when the given ArgoCDCluster is misconfigured.
+func validateCluster(cluster *v1alpha1.ArgoCDCluster) error {
+	server, err := url.Parse(cluster.Spec.Server)
+	if err != nil || server.Host == "" {
+		return invalidf("invalid server URL '%s'", cluster.Spec.Server)
+	}
+	if strings.TrimSuffix(cluster.Spec.Server, "/") == common.ArgoCDDefaultServer {
+		return invalidf("the in-cluster cluster %s is registered by the ArgoCD itself", common.ArgoCDDefaultServer)
+	}
+	if cluster.Spec.BearerTokenSecret != nil && cluster.Spec.ExecProvider != nil {
+		return invalidf("the bearer token and the exec provider are mutually exclusive")
+	}
+	tls := cluster.Spec.TLS
+	if tls.Insecure && tls.CAConfigMap != nil {
+		return invalidf("the CA certificate cannot be set along with insecure")
+	}
+	if (tls.ClientCertSecret == nil) != (tls.ClientKeySecret == nil) {
+		return invalidf("the client certificate and its key must be set together")
+	}
+	return nil
+}
+
+// getClusterConfig returns the configuration of the cluster of the given ArgoCDCluster, with the credentials read from
+// the Secrets it references.
+func (r *ArgoCDClusterReconciler) getClusterConfig(ctx context.Context, cluster *v1alpha1.ArgoCDCluster) (clusterConfig, error) {
+	spec := cluster.Spec
+	config := clusterConfig{
+		TLSClientConfig: clusterTLSClientConfig{
+			Insecure:   spec.TLS.Insecure,
+			ServerName: spec.TLS.ServerName,
+		},
+	}
+
+	var err error
+	if spec.BearerTokenSecret != nil {
+		token, err := r.getSecretKeyValue(ctx, cluster.Namespace, spec.BearerTokenSecret)
+		if err != nil {
+			return config, err
+		}
+		config.BearerToken = strings.TrimSpace(string(token))
+	}
+	if spec.TLS.CAConfigMap != nil {
+		if config.TLSClientConfig.CAData, err = r.getConfigMapKeyValue(ctx, cluster.Namespace, spec.TLS.CAConfigMap); err != nil {
+			return config, err
+		}
+	}
+	if spec.TLS.ClientCertSecret != nil {
+		if config.TLSClientConfig.CertData, err = r.getSecretKeyValue(ctx, cluster.Namespace, spec.TLS.ClientCertSecret); err != nil {
+			return config, err
+		}
+		if config.TLSClientConfig.KeyData, err = r.getSecretKeyValue(ctx, cluster.Namespace, spec.TLS.ClientKeySecret); err != nil {
+			return config, err
+		}
+	}
+
+	if exec := spec.ExecProvider; exec != nil {
+		config.ExecProviderConfig = &clusterExecProviderConfig{
+			Command:     exec.Command,
+			Args:        exec.Args,
+			APIVersion:  exec.APIVersion,
+			InstallHint: exec.InstallHint,
+		}
+		env := map[string]string{}
+		for name, value := range exec.Env {
+			env[name] = value
+		}
+		if exec.EnvSecretName != "" {
+			secret := &corev1.Secret{}
+			if err := r.Client.Get(ctx, types.NamespacedName{Namespace: cluster.Namespace, Name: exec.EnvSecretName}, secret); err != nil {
+				if errors.IsNotFound(err) {
+					return config, invalidf("secret %s not found", exec.EnvSecretName)
+				}
+				return config, err
+			}
+			for name, value := range secret.Data {
+				env[name] = string(value)
+			}
+		}
+		if len(env) > 0 {
+			config.ExecProviderConfig.Env = env
+		}
+	}
+	return config, nil
+}
+
+// getSecretKeyValue returns the value of the Secret key of the given reference.
+func (r *ArgoCDClusterReconciler) getSecretKeyValue(ctx context.Context, namespace string, ref *corev1.SecretKeySelector) ([]byte, error) {
+	secret := &corev1.Secret{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, secret); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, invalidf("secret %s not found", ref.Name)
+		}
+		return nil, err
+	}
+	value, ok := secret.Data[ref.Key]
+	if !ok {
+		return nil, invalidf("key %s not found in secret %s", ref.Key, ref.Name)
+	}
+	return value, nil
+}
+
+// getConfigMapKeyValue returns the value of the ConfigMap key of the given reference.
+func (r *ArgoCDClusterReconciler) getConfigMapKeyValue(ctx context.Context, namespace string, ref *corev1.ConfigMapKeySelector) ([]byte, error) {
+	configMap := &corev1.ConfigMap{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, configMap); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, invalidf("configmap %s not found", ref.Name)
+		}
+		return nil, err
+	}
+	value, ok := configMap.Data[ref.Key]
+	if !ok {
+		return nil, invalidf("key %s not found in configmap %s", ref.Key, ref.Name)
+	}
+	return []byte(value), nil
+}
+
+// newClusterSecret returns the cluster secret of the given ArgoCDCluster with the given configuration.
+func newClusterSecret(cluster *v1alpha1.ArgoCDCluster, config clusterConfig) (*corev1.Secret, error) {
+	configData, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+
+	name := cluster.Spec.Name
+	if name == "" {
+		name = cluster.Name
+	}
+	data := map[string][]byte{
+		"name":   []byte(name),
+		"server": []byte(cluster.Spec.Server),
+		"config": configData,
+	}
+	if len(cluster.Spec.Namespaces) > 0 {
+		data["namespaces"] = []byte(strings.Join(cluster.Spec.Namespaces, ","))
+		if cluster.Spec.ClusterResources {
+			data["clusterResources"] = []byte("true")
+		}
+	}
+	if cluster.Spec.Shard != nil {
+		data["shard"] = []byte(strconv.FormatInt(*cluster.Spec.Shard, 10))
+	}
+
+	labels := map[string]string{}
+	for key, value := range cluster.Spec.Labels {
+		labels[key] = value
+	}
+	labels[common.ArgoCDSecretTypeLabel] = "cluster"
+
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getClusterSecretName(cluster),
+			Namespace: cluster.Namespace,
+			Labels:    labels,
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: data,
+	}, nil
+}
+
+// reconcileClusterSecret creates or updates the cluster secret of the given ArgoCDCluster. A Secret of the same name
+// that is not controlled by the ArgoCDCluster is left untouched.
+func (r *ArgoCDClusterReconciler) reconcileClusterSecret(ctx context.Context, cluster *v1alpha1.ArgoCDCluster, config clusterConfig) error {
+	secret, err := newClusterSecret(cluster, config)
+	if err != nil {
+		return err
+	}
+
+	existing := &corev1.Secret{}
+	err = r.Client.Get(ctx, types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name}, existing)
+	if errors.IsNotFound(err) {
+		if err := controllerutil.SetControllerReference(cluster, secret, r.Scheme); err != nil {
+			return err
+		}
+		log.Info(fmt.Sprintf("creating cluster secret %s for ArgoCDCluster %s/%s", secret.Name, cluster.Namespace, cluster.Name))
+		return r.Client.Create(ctx, secret)
+	} else if err != nil {
+		return err
+	}
+
+	if !metav1.IsControlledBy(existing, cluster) {
+		return invalidf("secret %s already exists and is not managed by the ArgoCDCluster", existing.Name)
+	}
+	if reflect.DeepEqual(existing.Data, secret.Data) && reflect.DeepEqual(existing.Labels, secret.Labels) {
+		return nil
+	}
+	existing.Data = secret.Data
+	existing.Labels = secret.Labels
+	log.Info(fmt.Sprintf("updating cluster secret %s for ArgoCDCluster %s/%s", secret.Name, cluster.Namespace, cluster.Name))
+	return r.Client.Update(ctx, existing)
+}
@@ -28,6 +28,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	argoproj "github.com/argoproj-labs/argocd-operator/api/v1alpha1"
+	"github.com/argoproj-labs/argocd-operator/controllers/argocd"
 )
 
 var log = logr.Log.WithName("controller_argocdexport")
@@ -42,6 +43,8 @@ type ReconcileArgoCDExport struct {
 	// that reads objects from the cache and writes to the apiserver
 	Client client.Client
 	Scheme *runtime.Scheme
+	// Stores the optional APIs served by the cluster
+	ClusterAPIs *argocd.ClusterAPIs
 }
 
 //+kubebuilder:rbac:groups=argoproj.io,resources=argocdexports;argocdexports/finalizers;argocdexports/status,verbs=*
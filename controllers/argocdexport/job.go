@@ -165,7 +165,7 @@ func newCronJob(cr *argoproj.ArgoCDExport) *batchv1.CronJob {
 	}
 }
 
-func newExportPodSpec(cr *argoproj.ArgoCDExport, argocdName string, client client.Client) corev1.PodSpec {
+func newExportPodSpec(cr *argoproj.ArgoCDExport, argocdName string, client client.Client, apis *argocd.ClusterAPIs) corev1.PodSpec {
 	pod := corev1.PodSpec{}
 
 	boolPtr := func(value bool) *bool {
@@ -205,19 +205,19 @@ func newExportPodSpec(cr *argoproj.ArgoCDExport, argocdName string, client clien
 		RunAsGroup: &id,
 		FSGroup:    &id,
 	}
-	argocd.AddSeccompProfileForOpenShift(client, &pod)
+	argocd.AddSeccompProfileForOpenShift(client, apis, &pod)
 
 	return pod
 }
 
-func newPodTemplateSpec(cr *argoproj.ArgoCDExport, argocdName string, client client.Client) corev1.PodTemplateSpec {
+func newPodTemplateSpec(cr *argoproj.ArgoCDExport, argocdName string, client client.Client, apis *argocd.ClusterAPIs) corev1.PodTemplateSpec {
 	return corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      cr.Name,
 			Namespace: cr.Namespace,
 			Labels:    common.DefaultLabels(cr.Name),
 		},
-		Spec: newExportPodSpec(cr, argocdName, client),
+		Spec: newExportPodSpec(cr, argocdName, client, apis),
 	}
 }
 
@@ -239,14 +239,14 @@ func (r *ReconcileArgoCDExport) reconcileCronJob(cr *argoproj.ArgoCDExport) erro
 	cj.Spec.Schedule = *cr.Spec.Schedule
 
 	// To create the job, we need the name of the argocd instance.  Although the argocd export cr contains a field with
-	// the argocd instance name, it's never used anywhere, and so there may be existing argocd export resources with the
-	// wrong name. To avoid these breaking, we look up the name of the argocd instance in the namespace of the export cr.
-	argocdName, err := r.argocdName(cr.Namespace)
+	// the argocd instance name, it used to be ignored, and so there may be existing argocd export resources with the
+	// wrong name. To avoid these breaking, the name is only used when the namespace contains several instances.
+	argocdName, err := r.argocdName(cr)
 	if err != nil {
 		return err
 	}
 	job := newJob(cr)
-	job.Spec.Template = newPodTemplateSpec(cr, argocdName, r.Client)
+	job.Spec.Template = newPodTemplateSpec(cr, argocdName, r.Client, r.ClusterAPIs)
 
 	cj.Spec.JobTemplate.Spec = job.Spec
 
@@ -273,13 +273,13 @@ func (r *ReconcileArgoCDExport) reconcileJob(cr *argoproj.ArgoCDExport) error {
 	}
 
 	// To create the job, we need the name of the argocd instance.  Although the argocd export cr contains a field with
-	// the argocd instance name, it's never used anywhere, and so there may be existing argocd export resources with the
-	// wrong name. To avoid these breaking, we look up the name of the argocd instance in the namespace of the export cr.
-	argocdName, err := r.argocdName(cr.Namespace)
+	// the argocd instance name, it used to be ignored, and so there may be existing argocd export resources with the
+	// wrong name. To avoid these breaking, the name is only used when the namespace contains several instances.
+	argocdName, err := r.argocdName(cr)
 	if err != nil {
 		return err
 	}
-	job.Spec.Template = newPodTemplateSpec(cr, argocdName, r.Client)
+	job.Spec.Template = newPodTemplateSpec(cr, argocdName, r.Client, r.ClusterAPIs)
 
 	if err := controllerutil.SetControllerReference(cr, job, r.Scheme); err != nil {
 		return err
@@ -287,14 +287,23 @@ func (r *ReconcileArgoCDExport) reconcileJob(cr *argoproj.ArgoCDExport) error {
 	return r.Client.Create(context.TODO(), job)
 }
 
-func (r *ReconcileArgoCDExport) argocdName(namespace string) (string, error) {
+// argocdName returns the name of the Argo CD instance to export: the only instance in the namespace of the export cr,
+// or the instance named by the export cr when the namespace contains several instances.
+func (r *ReconcileArgoCDExport) argocdName(cr *argoproj.ArgoCDExport) (string, error) {
 	argocds := &argoproj.ArgoCDList{}
-	if err := r.Client.List(context.TODO(), argocds, &client.ListOptions{Namespace: namespace}); err != nil {
+	if err := r.Client.List(context.TODO(), argocds, &client.ListOptions{Namespace: cr.Namespace}); err != nil {
 		return "", err
 	}
-	if len(argocds.Items) != 1 {
-		return "", fmt.Errorf("No Argo CD instance found in namespace %s", namespace)
+	if len(argocds.Items) == 1 {
+		return argocds.Items[0].Name, nil
 	}
-	argocd := argocds.Items[0]
-	return argocd.Name, nil
+	for _, argocd := range argocds.Items {
+		if argocd.Name == cr.Spec.Argocd {
+			return argocd.Name, nil
+		}
+	}
+	if len(argocds.Items) == 0 {
+		return "", fmt.Errorf("No Argo CD instance found in namespace %s", cr.Namespace)
+	}
+	return "", fmt.Errorf("Argo CD instance %s not found in namespace %s", cr.Spec.Argocd, cr.Namespace)
 }
@@ -0,0 +1,190 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package argocdoperatorconfig
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logr "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1alpha1 "github.com/argoproj-labs/argocd-operator/api/v1alpha1"
+	"github.com/argoproj-labs/argocd-operator/common"
+)
+
+var log = logr.Log.WithName("controller_argocdoperatorconfig")
+
+// blank assignment to verify that ArgoCDOperatorConfigReconciler implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ArgoCDOperatorConfigReconciler{}
+
+// ArgoCDOperatorConfigReconciler reconciles the ArgoCDOperatorConfig of the cluster: it puts its values in effect in
+// place of the environment variables of the operator, and reports the values in effect in its status.
+type ArgoCDOperatorConfigReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	// Stores the label selector of the command-line option, used when the configuration sets none
+	LabelSelector string
+	// Stores whether the conversion webhook was started with the operator
+	ConversionWebhook bool
+	// Stores the level of the logs of the operator, changed along with the configuration
+	LogLevel *zap.AtomicLevel
+	// Reconciles the ArgoCD instances once a new configuration is in effect
+	OnChange func(ctx context.Context) error
+
+	// Tracks whether the ArgoCD instances are still to be reconciled with the configuration in effect
+	changePending bool
+}
+
+//+kubebuilder:rbac:groups=argoproj.io,resources=argocdoperatorconfigs,verbs=get;list;watch
+//+kubebuilder:rbac:groups=argoproj.io,resources=argocdoperatorconfigs/status,verbs=get;update;patch
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.9.2/pkg/reconcile
+func (r *ArgoCDOperatorConfigReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	reqLogger := logr.FromContext(ctx, "Request.Name", request.Name)
+	reqLogger.Info("Reconciling ArgoCDOperatorConfig")
+
+	config := &v1alpha1.ArgoCDOperatorConfig{}
+	err := r.Client.Get(ctx, request.NamespacedName, config)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			if request.Name == common.ArgoCDOperatorConfigName {
+				// the environment variables apply again
+				return reconcile.Result{}, r.apply(ctx, v1alpha1.ArgoCDOperatorConfigSpec{})
+			}
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, err
+	}
+
+	if config.Name != common.ArgoCDOperatorConfigName {
+		message := fmt.Sprintf("the operator only reads the ArgoCDOperatorConfig named %s", common.ArgoCDOperatorConfigName)
+		return reconcile.Result{}, r.updateStatus(ctx, config, v1alpha1.ArgoCDOperatorConfigPhaseFailed, message)
+	}
+
+	if err := validate(config.Spec); err != nil {
+		// the configuration is fixed through a new generation, which is reconciled anyway
+		message := fmt.Sprintf("%v, the previous configuration stays in effect", err)
+		return reconcile.Result{}, r.updateStatus(ctx, config, v1alpha1.ArgoCDOperatorConfigPhaseFailed, message)
+	}
+	if err := r.apply(ctx, config.Spec); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	message := ""
+	if config.Spec.ConversionWebhook != nil && *config.Spec.ConversionWebhook != r.ConversionWebhook {
+		message = fmt.Sprintf("the conversion webhook is enabled or disabled once the operator restarts, it is currently enabled: %t", r.ConversionWebhook)
+	}
+	return reconcile.Result{}, r.updateStatus(ctx, config, v1alpha1.ArgoCDOperatorConfigPhaseAvailable, message)
+}
+
+// apply puts the given configuration in effect, and reconciles the ArgoCD instances with it when it changed.
+func (r *ArgoCDOperatorConfigReconciler) apply(ctx context.Context, spec v1alpha1.ArgoCDOperatorConfigSpec) error {
+	if setOverrides(spec) {
+		log.Info("a new operator configuration is in effect")
+		r.changePending = true
+	}
+
+	level, err := ParseLogLevel(Getenv(common.ArgoCDLogLevelEnvName))
+	if err != nil {
+		log.Info(fmt.Sprintf("%v, using the %s log level", err, level))
+	}
+	if r.LogLevel != nil && r.LogLevel.Level() != level {
+		log.Info(fmt.Sprintf("changing the log level to %s", level))
+		r.LogLevel.SetLevel(level)
+	}
+
+	if !r.changePending || r.OnChange == nil {
+		return nil
+	}
+	if err := r.OnChange(ctx); err != nil {
+		return fmt.Errorf("failed to reconcile the ArgoCD instances with the new operator configuration: %w", err)
+	}
+	r.changePending = false
+	return nil
+}
+
+// effective returns the configuration in effect.
+func (r *ArgoCDOperatorConfigReconciler) effective() v1alpha1.ArgoCDOperatorConfigSpec {
+	labelSelector := r.LabelSelector
+	if value, ok := Lookup(common.ArgoCDLabelSelectorKey); ok {
+		labelSelector = value
+	}
+	var clusterConfigNamespaces []string
+	for _, namespace := range strings.Split(Getenv(common.ArgoCDClusterConfigNamespacesEnvName), ",") {
+		if namespace = strings.TrimSpace(namespace); namespace != "" {
+			clusterConfigNamespaces = append(clusterConfigNamespaces, namespace)
+		}
+	}
+	conversionWebhook := r.ConversionWebhook
+	level, _ := ParseLogLevel(Getenv(common.ArgoCDLogLevelEnvName))
+
+	return v1alpha1.ArgoCDOperatorConfigSpec{
+		LabelSelector:           &labelSelector,
+		ClusterConfigNamespaces: clusterConfigNamespaces,
+		ControllerClusterRole:   Getenv(common.ArgoCDControllerClusterRoleEnvName),
+		ServerClusterRole:       Getenv(common.ArgoCDServerClusterRoleEnvName),
+		ConversionWebhook:       &conversionWebhook,
+		Images: v1alpha1.ArgoCDOperatorConfigImages{
+			ArgoCD:       Getenv(common.ArgoCDImageEnvName),
+			Dex:          Getenv(common.ArgoCDDexImageEnvName),
+			Keycloak:     Getenv(common.ArgoCDKeycloakImageEnvName),
+			Redis:        Getenv(common.ArgoCDRedisImageEnvName),
+			RedisHA:      Getenv(common.ArgoCDRedisHAImageEnvName),
+			RedisHAProxy: Getenv(common.ArgoCDRedisHAProxyImageEnvName),
+		},
+		LogLevel: level.String(),
+	}
+}
+
+// updateStatus updates the status of the given ArgoCDOperatorConfig when it changed.
+func (r *ArgoCDOperatorConfigReconciler) updateStatus(ctx context.Context, config *v1alpha1.ArgoCDOperatorConfig, phase, message string) error {
+	status := v1alpha1.ArgoCDOperatorConfigStatus{
+		Phase:              phase,
+		Message:            message,
+		ObservedGeneration: config.Generation,
+	}
+	if config.Name == common.ArgoCDOperatorConfigName {
+		status.Effective = r.effective()
+	}
+	if reflect.DeepEqual(config.Status, status) {
+		return nil
+	}
+	config.Status = status
+	return r.Client.Status().Update(ctx, config)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ArgoCDOperatorConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.ArgoCDOperatorConfig{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
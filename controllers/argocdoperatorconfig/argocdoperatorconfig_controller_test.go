@@ -0,0 +1,199 @@
+package argocdoperatorconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/argoproj-labs/argocd-operator/api/v1alpha1"
+	"github.com/argoproj-labs/argocd-operator/common"
+)
+
+func makeTestOperatorConfig(name string, spec v1alpha1.ArgoCDOperatorConfigSpec) *v1alpha1.ArgoCDOperatorConfig {
+	return &v1alpha1.ArgoCDOperatorConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       name,
+			Generation: 1,
+		},
+		Spec: spec,
+	}
+}
+
+func makeTestReconciler(t *testing.T, objs ...client.Object) (*ArgoCDOperatorConfigReconciler, *int) {
+	t.Helper()
+	// the configuration in effect is global, every test starts without one
+	setOverrides(v1alpha1.ArgoCDOperatorConfigSpec{})
+	t.Cleanup(func() { setOverrides(v1alpha1.ArgoCDOperatorConfigSpec{}) })
+
+	sch := runtime.NewScheme()
+	assert.NoError(t, scheme.AddToScheme(sch))
+	assert.NoError(t, v1alpha1.AddToScheme(sch))
+	cl := fake.NewClientBuilder().WithScheme(sch).WithObjects(objs...).WithStatusSubresource(objs...).Build()
+
+	changes := 0
+	level := zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	return &ArgoCDOperatorConfigReconciler{
+		Client:        cl,
+		Scheme:        sch,
+		LabelSelector: "flag=true",
+		LogLevel:      &level,
+		OnChange: func(ctx context.Context) error {
+			changes++
+			return nil
+		},
+	}, &changes
+}
+
+func reconcileTestOperatorConfig(t *testing.T, r *ArgoCDOperatorConfigReconciler, name string) *v1alpha1.ArgoCDOperatorConfig {
+	t.Helper()
+	_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: name}})
+	assert.NoError(t, err)
+
+	config := &v1alpha1.ArgoCDOperatorConfig{}
+	if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: name}, config); err != nil {
+		return nil
+	}
+	return config
+}
+
+func TestArgoCDOperatorConfigReconciler_Reconcile(t *testing.T) {
+	t.Setenv(common.ArgoCDImageEnvName, "env/argocd:v1")
+	t.Setenv(common.ArgoCDServerClusterRoleEnvName, "env-server-role")
+	t.Setenv(common.ArgoCDClusterConfigNamespacesEnvName, "argocd, other")
+	selector := "team=a"
+	config := makeTestOperatorConfig(common.ArgoCDOperatorConfigName, v1alpha1.ArgoCDOperatorConfigSpec{
+		LabelSelector:         &selector,
+		ControllerClusterRole: "controller-role",
+		Images:                v1alpha1.ArgoCDOperatorConfigImages{ArgoCD: "custom/argocd:v2"},
+		LogLevel:              "debug",
+	})
+	r, changes := makeTestReconciler(t, config)
+
+	config = reconcileTestOperatorConfig(t, r, config.Name)
+
+	// the values of the configuration override the environment variables
+	assert.Equal(t, "custom/argocd:v2", Getenv(common.ArgoCDImageEnvName))
+	assert.Equal(t, "controller-role", Getenv(common.ArgoCDControllerClusterRoleEnvName))
+	assert.Equal(t, "env-server-role", Getenv(common.ArgoCDServerClusterRoleEnvName))
+	value, ok := Lookup(common.ArgoCDLabelSelectorKey)
+	assert.True(t, ok)
+	assert.Equal(t, "team=a", value)
+	assert.Equal(t, zapcore.DebugLevel, r.LogLevel.Level())
+	assert.Equal(t, 1, *changes)
+
+	assert.Equal(t, v1alpha1.ArgoCDOperatorConfigPhaseAvailable, config.Status.Phase)
+	assert.Equal(t, int64(1), config.Status.ObservedGeneration)
+	effective := config.Status.Effective
+	assert.Equal(t, "team=a", *effective.LabelSelector)
+	assert.Equal(t, []string{"argocd", "other"}, effective.ClusterConfigNamespaces)
+	assert.Equal(t, "controller-role", effective.ControllerClusterRole)
+	assert.Equal(t, "env-server-role", effective.ServerClusterRole)
+	assert.Equal(t, "custom/argocd:v2", effective.Images.ArgoCD)
+	assert.Equal(t, "debug", effective.LogLevel)
+	assert.False(t, *effective.ConversionWebhook)
+
+	// an unchanged configuration does not reconcile the ArgoCD instances again
+	reconcileTestOperatorConfig(t, r, config.Name)
+	assert.Equal(t, 1, *changes)
+
+	// without configuration, the environment variables apply again
+	assert.NoError(t, r.Client.Delete(context.TODO(), config))
+	reconcileTestOperatorConfig(t, r, config.Name)
+	assert.Equal(t, "env/argocd:v1", Getenv(common.ArgoCDImageEnvName))
+	_, ok = Lookup(common.ArgoCDLabelSelectorKey)
+	assert.False(t, ok)
+	assert.Equal(t, zapcore.InfoLevel, r.LogLevel.Level())
+	assert.Equal(t, 2, *changes)
+}
+
+func TestArgoCDOperatorConfigReconciler_Reconcile_invalid(t *testing.T) {
+	config := makeTestOperatorConfig(common.ArgoCDOperatorConfigName, v1alpha1.ArgoCDOperatorConfigSpec{
+		ServerClusterRole: "server-role",
+	})
+	r, changes := makeTestReconciler(t, config)
+	reconcileTestOperatorConfig(t, r, config.Name)
+	assert.Equal(t, 1, *changes)
+
+	config = reconcileTestOperatorConfig(t, r, config.Name)
+	selector := "team in (a"
+	config.Spec.LabelSelector = &selector
+	config.Spec.ServerClusterRole = "other-role"
+	assert.NoError(t, r.Client.Update(context.TODO(), config))
+
+	config = reconcileTestOperatorConfig(t, r, config.Name)
+
+	// the previous configuration stays in effect
+	assert.Equal(t, v1alpha1.ArgoCDOperatorConfigPhaseFailed, config.Status.Phase)
+	assert.Contains(t, config.Status.Message, "invalid label selector 'team in (a'")
+	assert.Equal(t, "server-role", Getenv(common.ArgoCDServerClusterRoleEnvName))
+	assert.Equal(t, "server-role", config.Status.Effective.ServerClusterRole)
+	assert.Equal(t, "flag=true", *config.Status.Effective.LabelSelector)
+	assert.Equal(t, 1, *changes)
+}
+
+func TestArgoCDOperatorConfigReconciler_Reconcile_conversionWebhook(t *testing.T) {
+	enabled := true
+	config := makeTestOperatorConfig(common.ArgoCDOperatorConfigName, v1alpha1.ArgoCDOperatorConfigSpec{
+		ConversionWebhook: &enabled,
+	})
+	r, _ := makeTestReconciler(t, config)
+
+	config = reconcileTestOperatorConfig(t, r, config.Name)
+
+	// the conversion webhook is started with the operator
+	assert.True(t, ConversionWebhookEnabled())
+	assert.Equal(t, v1alpha1.ArgoCDOperatorConfigPhaseAvailable, config.Status.Phase)
+	assert.Contains(t, config.Status.Message, "once the operator restarts")
+	assert.False(t, *config.Status.Effective.ConversionWebhook)
+}
+
+func TestArgoCDOperatorConfigReconciler_Reconcile_otherName(t *testing.T) {
+	config := makeTestOperatorConfig("other", v1alpha1.ArgoCDOperatorConfigSpec{
+		ServerClusterRole: "server-role",
+	})
+	r, changes := makeTestReconciler(t, config)
+
+	config = reconcileTestOperatorConfig(t, r, config.Name)
+
+	assert.Equal(t, v1alpha1.ArgoCDOperatorConfigPhaseFailed, config.Status.Phase)
+	assert.Equal(t, "the operator only reads the ArgoCDOperatorConfig named cluster", config.Status.Message)
+	_, ok := Lookup(common.ArgoCDServerClusterRoleEnvName)
+	assert.False(t, ok)
+	assert.Equal(t, 0, *changes)
+}
+
+func TestLoad(t *testing.T) {
+	config := makeTestOperatorConfig(common.ArgoCDOperatorConfigName, v1alpha1.ArgoCDOperatorConfigSpec{
+		ClusterConfigNamespaces: []string{"argocd", "team-a"},
+	})
+	r, _ := makeTestReconciler(t, config)
+
+	assert.NoError(t, Load(context.TODO(), r.Client))
+	assert.Equal(t, "argocd,team-a", Getenv(common.ArgoCDClusterConfigNamespacesEnvName))
+}
+
+func TestParseLogLevel(t *testing.T) {
+	for name, want := range map[string]zapcore.Level{
+		"":      zapcore.InfoLevel,
+		"debug": zapcore.DebugLevel,
+		"WARN":  zapcore.WarnLevel,
+		"error": zapcore.ErrorLevel,
+	} {
+		level, err := ParseLogLevel(name)
+		assert.NoError(t, err)
+		assert.Equal(t, want, level)
+	}
+
+	_, err := ParseLogLevel("verbose")
+	assert.EqualError(t, err, "invalid log level 'verbose'")
+}
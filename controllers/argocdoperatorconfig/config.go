@@ -0,0 +1,164 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package argocdoperatorconfig
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1alpha1 "github.com/argoproj-labs/argocd-operator/api/v1alpha1"
+	"github.com/argoproj-labs/argocd-operator/common"
+)
+
+var (
+	mu sync.RWMutex
+	// overrides are the values of the ArgoCDOperatorConfig in effect, by the environment variable they override.
+	overrides = map[string]string{}
+)
+
+// Lookup returns the value the ArgoCDOperatorConfig sets for the given environment variable of the operator, and
+// whether it sets one.
+func Lookup(name string) (string, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	value, ok := overrides[name]
+	return value, ok
+}
+
+// Getenv returns the value of the given environment variable of the operator, or the value the ArgoCDOperatorConfig
+// sets for it instead.
+func Getenv(name string) string {
+	if value, ok := Lookup(name); ok {
+		return value
+	}
+	return os.Getenv(name)
+}
+
+// setOverrides puts the values of the given configuration in effect, and returns true when they changed.
+func setOverrides(spec v1alpha1.ArgoCDOperatorConfigSpec) bool {
+	values := overridesOf(spec)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if reflect.DeepEqual(overrides, values) {
+		return false
+	}
+	overrides = values
+	return true
+}
+
+// overridesOf returns the values of the given configuration by the environment variable they override.
+func overridesOf(spec v1alpha1.ArgoCDOperatorConfigSpec) map[string]string {
+	values := map[string]string{}
+	if spec.LabelSelector != nil {
+		values[common.ArgoCDLabelSelectorKey] = *spec.LabelSelector
+	}
+	if len(spec.ClusterConfigNamespaces) > 0 {
+		values[common.ArgoCDClusterConfigNamespacesEnvName] = strings.Join(spec.ClusterConfigNamespaces, ",")
+	}
+	if spec.ConversionWebhook != nil {
+		values[common.ArgoCDEnableConversionWebhookEnvName] = strconv.FormatBool(*spec.ConversionWebhook)
+	}
+	for name, value := range map[string]string{
+		common.ArgoCDControllerClusterRoleEnvName: spec.ControllerClusterRole,
+		common.ArgoCDServerClusterRoleEnvName:     spec.ServerClusterRole,
+		common.ArgoCDImageEnvName:                 spec.Images.ArgoCD,
+		common.ArgoCDDexImageEnvName:              spec.Images.Dex,
+		common.ArgoCDKeycloakImageEnvName:         spec.Images.Keycloak,
+		common.ArgoCDRedisImageEnvName:            spec.Images.Redis,
+		common.ArgoCDRedisHAImageEnvName:          spec.Images.RedisHA,
+		common.ArgoCDRedisHAProxyImageEnvName:     spec.Images.RedisHAProxy,
+		common.ArgoCDLogLevelEnvName:              spec.LogLevel,
+	} {
+		if value != "" {
+			values[name] = value
+		}
+	}
+	return values
+}
+
+// validate returns an error when the given configuration cannot be put in effect.
+func validate(spec v1alpha1.ArgoCDOperatorConfigSpec) error {
+	if spec.LabelSelector != nil {
+		if _, err := labels.Parse(*spec.LabelSelector); err != nil {
+			return fmt.Errorf("invalid label selector '%s': %w", *spec.LabelSelector, err)
+		}
+	}
+	for _, namespace := range spec.ClusterConfigNamespaces {
+		if strings.TrimSpace(namespace) == "" {
+			return fmt.Errorf("invalid empty cluster config namespace")
+		}
+	}
+	if _, err := ParseLogLevel(spec.LogLevel); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ConversionWebhookEnabled returns true when the conversion webhook of the ArgoCD API is to be started with the
+// operator.
+func ConversionWebhookEnabled() bool {
+	return strings.EqualFold(Getenv(common.ArgoCDEnableConversionWebhookEnvName), "true")
+}
+
+// ParseLogLevel returns the log level of the given name, info when the name is empty.
+func ParseLogLevel(name string) (zapcore.Level, error) {
+	switch strings.ToLower(name) {
+	case "", "info":
+		return zapcore.InfoLevel, nil
+	case "debug":
+		return zapcore.DebugLevel, nil
+	case "warn":
+		return zapcore.WarnLevel, nil
+	case "error":
+		return zapcore.ErrorLevel, nil
+	case "panic":
+		return zapcore.PanicLevel, nil
+	case "fatal":
+		return zapcore.FatalLevel, nil
+	}
+	return zapcore.InfoLevel, fmt.Errorf("invalid log level '%s'", name)
+}
+
+// Load puts the ArgoCDOperatorConfig of the cluster in effect, so that the operator starts with it. It is meant to be
+// called before the manager starts, with a client that does not rely on its cache.
+func Load(ctx context.Context, c client.Reader) error {
+	config := &v1alpha1.ArgoCDOperatorConfig{}
+	err := c.Get(ctx, client.ObjectKey{Name: common.ArgoCDOperatorConfigName}, config)
+	if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		// no configuration, or the API is not installed yet
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if err := validate(config.Spec); err != nil {
+		return fmt.Errorf("ArgoCDOperatorConfig %s is invalid: %w", config.Name, err)
+	}
+	setOverrides(config.Spec)
+	return nil
+}
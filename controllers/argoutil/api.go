@@ -50,3 +50,44 @@ func VerifyAPI(group string, version string) (bool, error) {
 	log.Info(fmt.Sprintf("%s/%s API verified", group, version))
 	return true, nil
 }
+
+// VerifyAPIResource will verify that the given resource of the given group/version is served by the cluster. A
+// group/version may be served without all of its resources, such as the Gateway API standard channel serving
+// gateway.networking.k8s.io/v1alpha2 for ReferenceGrant but not for GRPCRoute.
+func VerifyAPIResource(group string, version string, resource string) (bool, error) {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		log.Error(err, "unable to get k8s config")
+		return false, err
+	}
+
+	k8s, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		log.Error(err, "unable to create k8s client")
+		return false, err
+	}
+
+	return verifyAPIResource(k8s.Discovery(), group, version, resource), nil
+}
+
+// verifyAPIResource returns true if the given discovery client lists the given resource of the given group/version.
+func verifyAPIResource(client discovery.DiscoveryInterface, group string, version string, resource string) bool {
+	gv := schema.GroupVersion{
+		Group:   group,
+		Version: version,
+	}
+
+	resources, err := client.ServerResourcesForGroupVersion(gv.String())
+	if err != nil {
+		// error, API not available
+		return false
+	}
+
+	for _, r := range resources.APIResources {
+		if r.Name == resource {
+			log.Info(fmt.Sprintf("%s %s API verified", gv.String(), resource))
+			return true
+		}
+	}
+	return false
+}
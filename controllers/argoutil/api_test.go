@@ -0,0 +1,34 @@
+package argoutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func Test_verifyAPIResource(t *testing.T) {
+	client := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{}}
+
+	t.Run("Group version not served", func(t *testing.T) {
+		assert.False(t, verifyAPIResource(client, "gateway.networking.k8s.io", "v1alpha2", "grpcroutes"))
+	})
+
+	t.Run("Group version served without the resource", func(t *testing.T) {
+		client.Resources = []*metav1.APIResourceList{
+			{
+				GroupVersion: "gateway.networking.k8s.io/v1alpha2",
+				APIResources: []metav1.APIResource{{Name: "referencegrants", Kind: "ReferenceGrant"}},
+			},
+		}
+		assert.False(t, verifyAPIResource(client, "gateway.networking.k8s.io", "v1alpha2", "grpcroutes"))
+	})
+
+	t.Run("Group version served with the resource", func(t *testing.T) {
+		client.Resources[0].APIResources = append(client.Resources[0].APIResources,
+			metav1.APIResource{Name: "grpcroutes", Kind: "GRPCRoute"})
+		assert.True(t, verifyAPIResource(client, "gateway.networking.k8s.io", "v1alpha2", "grpcroutes"))
+	})
+}
@@ -0,0 +1,96 @@
+// Copyright 2019 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argoutil
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/csaupgrade"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	"github.com/argoproj-labs/argocd-operator/common"
+)
+
+// ApplyResource creates or updates obj with server-side apply, using the field manager of the operator.
+//
+// obj must hold only the fields managed by the operator: the fields it holds are enforced, the fields set by other
+// controllers, such as the replicas of a Deployment scaled by an HPA, are left untouched, and the fields the operator
+// applied before but obj no longer holds are removed. When another field manager owns a field obj holds with a
+// different value, the conflict is reported through a log and an Event, and the operator takes the ownership of the
+// field. On success obj holds the resulting state of the resource.
+//
+// The fields the operator wrote with updates before it moved to server-side apply are migrated to its field manager
+// before the first apply, so that they are removed as well once obj no longer holds them.
+func ApplyResource(c client.Client, obj client.Object) error {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return err
+	}
+	if err := migrateManagedFields(c, obj, gvk.Kind); err != nil {
+		return fmt.Errorf("failed to migrate the managed fields of %s %s: %w", gvk.Kind, client.ObjectKeyFromObject(obj), err)
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	obj.SetManagedFields(nil)
+	obj.SetResourceVersion("")
+
+	err = c.Patch(context.TODO(), obj, client.Apply, client.FieldOwner(common.ArgoCDFieldManager))
+	if !apierrors.IsConflict(err) {
+		return err
+	}
+
+	message := fmt.Sprintf("%s %s is managed by another field manager, taking ownership of the conflicting fields: %v", gvk.Kind, client.ObjectKeyFromObject(obj), err)
+	log.Info(message)
+	if obj.GetNamespace() != "" {
+		objectMeta := metav1.ObjectMeta{Name: obj.GetName(), Namespace: obj.GetNamespace(), UID: obj.GetUID(), Labels: obj.GetLabels()}
+		typeMeta := metav1.TypeMeta{Kind: gvk.Kind, APIVersion: gvk.GroupVersion().String()}
+		if err := CreateEvent(c, corev1.EventTypeWarning, "Apply", message, "FieldConflict", objectMeta, typeMeta); err != nil {
+			log.Error(err, fmt.Sprintf("failed to report the field conflict of %s %s", gvk.Kind, client.ObjectKeyFromObject(obj)))
+		}
+	}
+
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	obj.SetManagedFields(nil)
+	obj.SetResourceVersion("")
+	return c.Patch(context.TODO(), obj, client.Apply, client.FieldOwner(common.ArgoCDFieldManager), client.ForceOwnership)
+}
+
+// migrateManagedFields moves the ownership of the fields of the existing resource written with updates by the
+// operator to its apply field manager. The migration only happens once, while the operator has never applied the
+// resource: the fields it updates afterwards, such as the rollout labels of the pod templates, keep their own owner.
+func migrateManagedFields(c client.Client, obj client.Object, kind string) error {
+	live := obj.DeepCopyObject().(client.Object)
+	if err := c.Get(context.TODO(), client.ObjectKeyFromObject(obj), live); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	for _, entry := range live.GetManagedFields() {
+		if entry.Manager == common.ArgoCDFieldManager && entry.Operation == metav1.ManagedFieldsOperationApply {
+			return nil
+		}
+	}
+
+	patch, err := csaupgrade.UpgradeManagedFieldsPatch(live, sets.New(common.ArgoCDLegacyFieldManager), common.ArgoCDFieldManager)
+	if err != nil || patch == nil {
+		return err
+	}
+	log.Info(fmt.Sprintf("migrating the fields of %s %s updated by the operator to server-side apply", kind, client.ObjectKeyFromObject(live)))
+	return c.Patch(context.TODO(), live, client.RawPatch(types.JSONPatchType, patch))
+}
@@ -0,0 +1,259 @@
+// Copyright 2019 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argoutil
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+
+	"github.com/argoproj-labs/argocd-operator/common"
+)
+
+// fakeApplyServer records the apply patches it receives, and rejects the ones not forcing the ownership of the
+// fields when conflict is set.
+type fakeApplyServer struct {
+	conflict bool
+	applied  []*client.PatchOptions
+}
+
+func (s *fakeApplyServer) patch(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if patch.Type() != types.ApplyPatchType {
+		return c.Patch(ctx, obj, patch, opts...)
+	}
+	patchOpts := &client.PatchOptions{}
+	patchOpts.ApplyOptions(opts)
+	s.applied = append(s.applied, patchOpts)
+	if s.conflict && (patchOpts.Force == nil || !*patchOpts.Force) {
+		return apierrors.NewConflict(schema.GroupResource{Resource: "services"}, obj.GetName(), nil)
+	}
+	return nil
+}
+
+func newTestApplyClient(s *fakeApplyServer) client.Client {
+	sch := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(sch)
+	return fake.NewClientBuilder().WithScheme(sch).WithInterceptorFuncs(interceptor.Funcs{Patch: s.patch}).Build()
+}
+
+func newTestService() *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "argocd-server",
+			Namespace:       "argocd",
+			ResourceVersion: "42",
+			ManagedFields:   []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+		},
+	}
+}
+
+func TestApplyResource(t *testing.T) {
+	s := &fakeApplyServer{}
+	c := newTestApplyClient(s)
+	svc := newTestService()
+
+	assert.NoError(t, ApplyResource(c, svc))
+
+	assert.Len(t, s.applied, 1)
+	assert.Equal(t, common.ArgoCDFieldManager, s.applied[0].FieldManager)
+	assert.Nil(t, s.applied[0].Force)
+	assert.Equal(t, "Service", svc.GetObjectKind().GroupVersionKind().Kind)
+	assert.Empty(t, svc.ResourceVersion)
+	assert.Empty(t, svc.ManagedFields)
+
+	events := &corev1.EventList{}
+	assert.NoError(t, c.List(context.TODO(), events))
+	assert.Empty(t, events.Items)
+}
+
+func TestApplyResource_conflict(t *testing.T) {
+	s := &fakeApplyServer{conflict: true}
+	c := newTestApplyClient(s)
+	svc := newTestService()
+
+	assert.NoError(t, ApplyResource(c, svc))
+
+	// the conflicting fields are taken over
+	assert.Len(t, s.applied, 2)
+	assert.Nil(t, s.applied[0].Force)
+	assert.True(t, *s.applied[1].Force)
+	assert.Equal(t, common.ArgoCDFieldManager, s.applied[1].FieldManager)
+
+	// and the conflict is reported
+	events := &corev1.EventList{}
+	assert.NoError(t, c.List(context.TODO(), events))
+	assert.Len(t, events.Items, 1)
+	assert.Equal(t, corev1.EventTypeWarning, events.Items[0].Type)
+	assert.Equal(t, "FieldConflict", events.Items[0].Reason)
+	assert.Equal(t, "Service", events.Items[0].InvolvedObject.Kind)
+	assert.Equal(t, svc.Name, events.Items[0].InvolvedObject.Name)
+}
+
+func newTestLegacyConfigMap(managedFields ...metav1.ManagedFieldsEntry) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:          "argocd-cm",
+			Namespace:     "argocd",
+			ManagedFields: managedFields,
+		},
+		Data: map[string]string{"admin.enabled": "true"},
+	}
+}
+
+func newTestManagedFieldsEntry(manager string, operation metav1.ManagedFieldsOperationType) metav1.ManagedFieldsEntry {
+	return metav1.ManagedFieldsEntry{
+		Manager:    manager,
+		Operation:  operation,
+		APIVersion: "v1",
+		FieldsType: "FieldsV1",
+		FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:data":{"f:admin.enabled":{}}}`)},
+	}
+}
+
+func TestMigrateManagedFields(t *testing.T) {
+	legacy := newTestManagedFieldsEntry(common.ArgoCDLegacyFieldManager, metav1.ManagedFieldsOperationUpdate)
+	applied := newTestManagedFieldsEntry(common.ArgoCDFieldManager, metav1.ManagedFieldsOperationApply)
+	kubectl := newTestManagedFieldsEntry("kubectl-edit", metav1.ManagedFieldsOperationUpdate)
+
+	tests := []struct {
+		name          string
+		managedFields []metav1.ManagedFieldsEntry
+		want          []metav1.ManagedFieldsEntry
+	}{
+		{
+			name:          "updated by the operator",
+			managedFields: []metav1.ManagedFieldsEntry{legacy, kubectl},
+			want:          []metav1.ManagedFieldsEntry{applied, kubectl},
+		},
+		{
+			name:          "applied by the operator",
+			managedFields: []metav1.ManagedFieldsEntry{applied, legacy},
+			want:          []metav1.ManagedFieldsEntry{applied, legacy},
+		},
+		{
+			name:          "updated by others",
+			managedFields: []metav1.ManagedFieldsEntry{kubectl},
+			want:          []metav1.ManagedFieldsEntry{kubectl},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := newTestApplyClient(&fakeApplyServer{})
+			assert.NoError(t, c.Create(context.TODO(), newTestLegacyConfigMap(test.managedFields...)))
+
+			assert.NoError(t, migrateManagedFields(c, newTestLegacyConfigMap(), "ConfigMap"))
+
+			live := &corev1.ConfigMap{}
+			assert.NoError(t, c.Get(context.TODO(), client.ObjectKeyFromObject(newTestLegacyConfigMap()), live))
+			assert.Equal(t, test.want, live.ManagedFields)
+		})
+	}
+}
+
+func TestMigrateManagedFields_notFound(t *testing.T) {
+	c := newTestApplyClient(&fakeApplyServer{})
+	assert.NoError(t, migrateManagedFields(c, newTestLegacyConfigMap(), "ConfigMap"))
+}
+
+// startTestAPIServer starts an API server with envtest, for the tests relying on the actual server-side apply. The
+// test is skipped when the envtest binaries are not installed, see the test target of the Makefile, unless it runs in
+// CI.
+func startTestAPIServer(t *testing.T) client.Client {
+	t.Helper()
+	if os.Getenv("KUBEBUILDER_ASSETS") == "" {
+		if os.Getenv("CI") != "" {
+			t.Fatal("KUBEBUILDER_ASSETS is not set, run the tests with the test target of the Makefile")
+		}
+		t.Skip("KUBEBUILDER_ASSETS is not set, skipping the test against an API server")
+	}
+	env := &envtest.Environment{}
+	cfg, err := env.Start()
+	if err != nil {
+		t.Fatalf("failed to start the API server: %v", err)
+	}
+	t.Cleanup(func() { _ = env.Stop() })
+
+	sch := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(sch)
+	c, err := client.New(cfg, client.Options{Scheme: sch})
+	if err != nil {
+		t.Fatalf("failed to create the client: %v", err)
+	}
+	assert.NoError(t, c.Create(context.TODO(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "argocd"}}))
+	return c
+}
+
+func getTestConfigMapData(t *testing.T, c client.Client) map[string]string {
+	t.Helper()
+	cm := &corev1.ConfigMap{}
+	assert.NoError(t, c.Get(context.TODO(), client.ObjectKeyFromObject(newTestLegacyConfigMap()), cm))
+	return cm.Data
+}
+
+func TestApplyResource_apiServer(t *testing.T) {
+	c := startTestAPIServer(t)
+
+	// the ConfigMap is written by a previous version of the operator, with updates
+	legacy := newTestLegacyConfigMap()
+	legacy.Data = map[string]string{"admin.enabled": "true", "users.anonymous.enabled": "false"}
+	assert.NoError(t, c.Create(context.TODO(), legacy, client.FieldOwner(common.ArgoCDLegacyFieldManager)))
+
+	// and edited by a user
+	edited := legacy.DeepCopy()
+	edited.Data["accounts.alice"] = "login"
+	assert.NoError(t, c.Update(context.TODO(), edited, client.FieldOwner("kubectl-edit")))
+
+	// the entries the operator wrote before and no longer applies are removed, the ones of the user are kept
+	cm := newTestLegacyConfigMap()
+	assert.NoError(t, ApplyResource(c, cm))
+	assert.Equal(t, map[string]string{"admin.enabled": "true", "accounts.alice": "login"}, getTestConfigMapData(t, c))
+
+	// the entries the operator applies are reverted when the user changes them
+	edited = &corev1.ConfigMap{}
+	assert.NoError(t, c.Get(context.TODO(), client.ObjectKeyFromObject(cm), edited))
+	edited.Data["admin.enabled"] = "false"
+	assert.NoError(t, c.Update(context.TODO(), edited, client.FieldOwner("kubectl-edit")))
+
+	assert.NoError(t, ApplyResource(c, newTestLegacyConfigMap()))
+	assert.Equal(t, map[string]string{"admin.enabled": "true", "accounts.alice": "login"}, getTestConfigMapData(t, c))
+
+	events := &corev1.EventList{}
+	assert.NoError(t, c.List(context.TODO(), events, client.InNamespace("argocd")))
+	assert.Len(t, events.Items, 1)
+	assert.Equal(t, "FieldConflict", events.Items[0].Reason)
+
+	// the operator updates made once the resource is applied are not migrated
+	updated := &corev1.ConfigMap{}
+	assert.NoError(t, c.Get(context.TODO(), client.ObjectKeyFromObject(cm), updated))
+	updated.Data["oidc.config"] = "issuer: https://keycloak"
+	assert.NoError(t, c.Update(context.TODO(), updated, client.FieldOwner(common.ArgoCDLegacyFieldManager)))
+
+	assert.NoError(t, ApplyResource(c, newTestLegacyConfigMap()))
+	assert.Equal(t, "issuer: https://keycloak", getTestConfigMapData(t, c)["oidc.config"])
+}
@@ -0,0 +1,251 @@
+// Copyright 2019 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argoutil
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultRedisProbeTimeout is the default timeout of a Redis connectivity probe.
+const DefaultRedisProbeTimeout = 3 * time.Second
+
+// RedisProbeOptions defines the options used to probe a Redis server.
+type RedisProbeOptions struct {
+	// Username is the username used to authenticate, requires Password.
+	Username string
+	// Password is the password used to authenticate, no authentication is performed when empty.
+	Password string
+	// SentinelUsername is the username used to authenticate with the sentinels, requires SentinelPassword.
+	SentinelUsername string
+	// SentinelPassword is the password used to authenticate with the sentinels, no authentication is performed when
+	// empty.
+	SentinelPassword string
+	// TLS enables TLS for the connection.
+	TLS bool
+	// InsecureSkipVerify disables the verification of the server certificate.
+	InsecureSkipVerify bool
+	// CACert is the PEM encoded CA certificate used to verify the server, the system trust store is used when empty.
+	CACert []byte
+	// Timeout is the timeout of every connection, DefaultRedisProbeTimeout is used when zero.
+	Timeout time.Duration
+}
+
+// ProbeRedis will verify that the Redis server at the given address accepts connections and answers a PING.
+func ProbeRedis(addr string, opts RedisProbeOptions) error {
+	conn, err := dialRedis(addr, opts)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := conn.auth(opts.Username, opts.Password); err != nil {
+		return fmt.Errorf("redis authentication failed: %w", err)
+	}
+
+	reply, err := conn.do("PING")
+	if err != nil {
+		return err
+	}
+	if reply != "PONG" {
+		return fmt.Errorf("unexpected reply to redis PING: %v", reply)
+	}
+	return nil
+}
+
+// ProbeRedisSentinel will ask the given sentinels for the address of the named master and probe it. The sentinels
+// are queried in order and the first one that knows the master is used.
+func ProbeRedisSentinel(sentinels []string, master string, opts RedisProbeOptions) error {
+	if len(sentinels) == 0 {
+		return errors.New("no redis sentinel address given")
+	}
+
+	var errs []string
+	for _, sentinel := range sentinels {
+		addr, err := getRedisSentinelMasterAddr(sentinel, master, opts)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", sentinel, err))
+			continue
+		}
+		return ProbeRedis(addr, opts)
+	}
+	return fmt.Errorf("unable to resolve redis master %q: %s", master, strings.Join(errs, "; "))
+}
+
+// getRedisSentinelMasterAddr will return the host:port of the named master as known by the given sentinel.
+func getRedisSentinelMasterAddr(sentinel, master string, opts RedisProbeOptions) (string, error) {
+	conn, err := dialRedis(sentinel, opts)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	if err := conn.auth(opts.SentinelUsername, opts.SentinelPassword); err != nil {
+		return "", fmt.Errorf("redis sentinel authentication failed: %w", err)
+	}
+
+	reply, err := conn.do("SENTINEL", "get-master-addr-by-name", master)
+	if err != nil {
+		return "", err
+	}
+	addr, ok := reply.([]interface{})
+	if !ok || len(addr) != 2 {
+		return "", fmt.Errorf("master %q is unknown to the sentinel", master)
+	}
+	host, _ := addr[0].(string)
+	port, _ := addr[1].(string)
+	return net.JoinHostPort(host, port), nil
+}
+
+// redisConn is a minimal RESP client connection, sufficient for probing.
+type redisConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+// dialRedis will open a connection to the given address, with TLS if requested.
+func dialRedis(addr string, opts RedisProbeOptions) (*redisConn, error) {
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = DefaultRedisProbeTimeout
+	}
+
+	dialer := &net.Dialer{Timeout: timeout}
+	var conn net.Conn
+	var err error
+	if opts.TLS {
+		var config *tls.Config
+		config, err = getRedisTLSConfig(addr, opts)
+		if err != nil {
+			return nil, err
+		}
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, config)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &redisConn{Conn: conn, reader: bufio.NewReader(conn)}, nil
+}
+
+// getRedisTLSConfig will return the TLS configuration used to connect to the given address.
+func getRedisTLSConfig(addr string, opts RedisProbeOptions) (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: opts.InsecureSkipVerify,
+	}
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		config.ServerName = host
+	}
+	if len(opts.CACert) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(opts.CACert) {
+			return nil, errors.New("invalid redis CA certificate")
+		}
+		config.RootCAs = pool
+	}
+	return config, nil
+}
+
+// auth will authenticate the connection with the given credentials, nothing is done when the password is empty.
+func (c *redisConn) auth(username, password string) error {
+	if password == "" {
+		return nil
+	}
+	args := []string{"AUTH", password}
+	if username != "" {
+		args = []string{"AUTH", username, password}
+	}
+	_, err := c.do(args...)
+	return err
+}
+
+// do will send the given command and return its reply.
+func (c *redisConn) do(args ...string) (interface{}, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(c.Conn, b.String()); err != nil {
+		return nil, err
+	}
+	return c.readReply()
+}
+
+// readReply will read and decode a single RESP reply. Arrays are returned as []interface{}, nil bulk strings and
+// arrays as nil and everything else as a string.
+func (c *redisConn) readReply() (interface{}, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if len(line) == 0 {
+		return nil, errors.New("empty redis reply")
+	}
+
+	switch line[0] {
+	case '+', ':':
+		return line[1:], nil
+	case '-':
+		return nil, errors.New(line[1:])
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid redis bulk string length: %w", err)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(c.reader, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid redis array length: %w", err)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, 0, n)
+		for i := 0; i < n; i++ {
+			item, err := c.readReply()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("unexpected redis reply: %q", line)
+}
@@ -0,0 +1,145 @@
+package argoutil
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// startFakeRedis starts a minimal RESP server that requires the given password (when not empty) and knows
+// the given sentinel master, returning its address.
+func startFakeRedis(t *testing.T, password, master, masterAddr string) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go serveFakeRedis(conn, password, master, masterAddr)
+		}
+	}()
+	return l.Addr().String()
+}
+
+func serveFakeRedis(conn net.Conn, password, master, masterAddr string) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	authenticated := password == ""
+	for {
+		args, err := readFakeRedisCommand(reader)
+		if err != nil {
+			return
+		}
+		switch strings.ToUpper(args[0]) {
+		case "AUTH":
+			if args[len(args)-1] != password {
+				io.WriteString(conn, "-WRONGPASS invalid username-password pair\r\n")
+				continue
+			}
+			authenticated = true
+			io.WriteString(conn, "+OK\r\n")
+		case "PING":
+			if !authenticated {
+				io.WriteString(conn, "-NOAUTH Authentication required.\r\n")
+				continue
+			}
+			io.WriteString(conn, "+PONG\r\n")
+		case "SENTINEL":
+			if !authenticated {
+				io.WriteString(conn, "-NOAUTH Authentication required.\r\n")
+				continue
+			}
+			if len(args) != 3 || args[2] != master {
+				io.WriteString(conn, "*-1\r\n")
+				continue
+			}
+			host, port, _ := net.SplitHostPort(masterAddr)
+			fmt.Fprintf(conn, "*2\r\n$%d\r\n%s\r\n$%d\r\n%s\r\n", len(host), host, len(port), port)
+		default:
+			io.WriteString(conn, "-ERR unknown command\r\n")
+		}
+	}
+}
+
+func readFakeRedisCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		if _, err := reader.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		arg, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, strings.TrimSuffix(arg, "\r\n"))
+	}
+	return args, nil
+}
+
+func TestProbeRedis(t *testing.T) {
+	addr := startFakeRedis(t, "secret", "", "")
+
+	assert.NoError(t, ProbeRedis(addr, RedisProbeOptions{Password: "secret"}))
+	assert.NoError(t, ProbeRedis(addr, RedisProbeOptions{Username: "argocd", Password: "secret"}))
+
+	err := ProbeRedis(addr, RedisProbeOptions{Password: "wrong"})
+	assert.ErrorContains(t, err, "WRONGPASS")
+
+	err = ProbeRedis(addr, RedisProbeOptions{})
+	assert.ErrorContains(t, err, "NOAUTH")
+}
+
+func TestProbeRedis_unreachable(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	addr := l.Addr().String()
+	l.Close()
+
+	assert.Error(t, ProbeRedis(addr, RedisProbeOptions{Timeout: time.Second}))
+}
+
+func TestProbeRedisSentinel(t *testing.T) {
+	redisAddr := startFakeRedis(t, "secret", "", "")
+	sentinelAddr := startFakeRedis(t, "", "argocd", redisAddr)
+
+	assert.NoError(t, ProbeRedisSentinel([]string{sentinelAddr}, "argocd", RedisProbeOptions{Password: "secret"}))
+
+	err := ProbeRedisSentinel([]string{sentinelAddr}, "unknown", RedisProbeOptions{Password: "secret"})
+	assert.ErrorContains(t, err, "unable to resolve redis master")
+
+	assert.Error(t, ProbeRedisSentinel(nil, "argocd", RedisProbeOptions{}))
+}
+
+func TestProbeRedisSentinel_auth(t *testing.T) {
+	redisAddr := startFakeRedis(t, "secret", "", "")
+	sentinelAddr := startFakeRedis(t, "sentinel-secret", "argocd", redisAddr)
+
+	opts := RedisProbeOptions{Password: "secret", SentinelUsername: "sentinel", SentinelPassword: "sentinel-secret"}
+	assert.NoError(t, ProbeRedisSentinel([]string{sentinelAddr}, "argocd", opts))
+
+	err := ProbeRedisSentinel([]string{sentinelAddr}, "argocd", RedisProbeOptions{Password: "secret"})
+	assert.ErrorContains(t, err, "NOAUTH")
+
+	err = ProbeRedisSentinel([]string{sentinelAddr}, "argocd", RedisProbeOptions{Password: "secret", SentinelPassword: "wrong"})
+	assert.ErrorContains(t, err, "redis sentinel authentication failed")
+}
@@ -0,0 +1,167 @@
+// Copyright 2019 ArgoCD Operator Developers
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package inmemory provides the in-memory cluster on which the operator renders the manifests of an ArgoCD without
+// contacting any cluster, also used by the tests of the controllers.
+package inmemory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+// NewClientBuilder returns a builder of clients of an in-memory cluster, which emulate server-side apply.
+func NewClientBuilder() *fake.ClientBuilder {
+	return fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{Patch: (&applier{}).patch})
+}
+
+// applier emulates server-side apply for the fake client, which handles apply patches as strategic merge patches
+// of existing objects only. As kubectl apply does, the applied object is merged into the existing one with a three-way
+// strategic merge against the object applied before, so that fields no longer applied are removed while the fields set
+// by others are left untouched. With the dry-run option obj receives the merged object, which is not persisted.
+// Conflicts between field managers are not emulated.
+type applier struct {
+	mu          sync.Mutex
+	lastApplied map[string][]byte
+}
+
+func (a *applier) patch(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if patch.Type() != types.ApplyPatchType {
+		return c.Patch(ctx, obj, patch, opts...)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	patchOpts := &client.PatchOptions{}
+	patchOpts.ApplyOptions(opts)
+	dryRun := len(patchOpts.DryRun) > 0
+
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return err
+	}
+	applied, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	delete(applied, "status")
+	unstructured.RemoveNestedField(applied, "metadata", "creationTimestamp")
+	modified, err := json.Marshal(applied)
+	if err != nil {
+		return err
+	}
+
+	key := fmt.Sprintf("%s/%s", gvk, client.ObjectKeyFromObject(obj))
+	live, err := c.Scheme().New(gvk)
+	if err != nil {
+		return err
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(obj), live.(client.Object)); apierrors.IsNotFound(err) {
+		if dryRun {
+			return nil
+		}
+		created, _ := c.Scheme().New(gvk)
+		if err := json.Unmarshal(modified, created); err != nil {
+			return err
+		}
+		if err := c.Create(ctx, created.(client.Object)); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	} else {
+		current, err := json.Marshal(live)
+		if err != nil {
+			return err
+		}
+		original := a.lastApplied[key]
+		if original == nil {
+			original = []byte("{}")
+		}
+		// only the entries applied before are removed from the maps no longer applied, not the whole maps
+		originalMap, modifiedMap := map[string]interface{}{}, map[string]interface{}{}
+		if err := json.Unmarshal(original, &originalMap); err != nil {
+			return err
+		}
+		if err := json.Unmarshal(modified, &modifiedMap); err != nil {
+			return err
+		}
+		addMissingMaps(originalMap, modifiedMap)
+		modifiedForMerge, err := json.Marshal(modifiedMap)
+		if err != nil {
+			return err
+		}
+		patchMeta, err := strategicpatch.NewPatchMetaFromStruct(live)
+		if err != nil {
+			return err
+		}
+		threeWayPatch, err := strategicpatch.CreateThreeWayMergePatch(original, modifiedForMerge, current, patchMeta, true)
+		if err != nil {
+			return err
+		}
+		merged, err := strategicpatch.StrategicMergePatch(current, threeWayPatch, live)
+		if err != nil {
+			return err
+		}
+		updated, _ := c.Scheme().New(gvk)
+		if err := json.Unmarshal(merged, updated); err != nil {
+			return err
+		}
+		if dryRun {
+			reflect.ValueOf(obj).Elem().Set(reflect.ValueOf(updated).Elem())
+			return nil
+		}
+		if err := c.Update(ctx, updated.(client.Object)); err != nil {
+			return err
+		}
+	}
+
+	if a.lastApplied == nil {
+		a.lastApplied = map[string][]byte{}
+	}
+	a.lastApplied[key] = modified
+	return c.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+}
+
+// addMissingMaps adds to modified an empty map for each map of original that modified does not hold.
+func addMissingMaps(original, modified map[string]interface{}) {
+	for k, v := range original {
+		originalMap, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		modifiedMap, ok := modified[k].(map[string]interface{})
+		if !ok {
+			if _, found := modified[k]; found {
+				continue
+			}
+			modifiedMap = map[string]interface{}{}
+			modified[k] = modifiedMap
+		}
+		addMissingMaps(originalMap, modifiedMap)
+	}
+}
@@ -17,12 +17,15 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
 	"fmt"
+	"math"
 	"os"
 	goruntime "runtime"
 	"strings"
+	"time"
 
 	"github.com/argoproj/argo-cd/v2/util/env"
 	monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
@@ -36,10 +39,14 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 
 	"github.com/argoproj-labs/argocd-operator/common"
 	"github.com/argoproj-labs/argocd-operator/controllers/argocd"
+	"github.com/argoproj-labs/argocd-operator/controllers/argocdcluster"
 	"github.com/argoproj-labs/argocd-operator/controllers/argocdexport"
+	"github.com/argoproj-labs/argocd-operator/controllers/argocdoperatorconfig"
 
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 
@@ -49,7 +56,7 @@ import (
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
-	"go.uber.org/zap/zapcore"
+	uberzap "go.uber.org/zap"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -69,6 +76,18 @@ var (
 	setupLog = ctrl.Log.WithName("setup")
 )
 
+// optionalAPIs register the types of the optional APIs the operator integrates with.
+var optionalAPIs = []func(*runtime.Scheme) error{
+	monitoringv1.AddToScheme,
+	routev1.Install,
+	gatewayv1.Install,
+	gatewayv1alpha2.Install,
+	configv1.Install,
+	templatev1.Install,
+	appsv1.Install,
+	oauthv1.Install,
+}
+
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
@@ -85,10 +104,16 @@ func printVersion() {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "render" {
+		os.Exit(runRender(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
 	var labelSelectorFlag string
+	var maxConcurrentReconciles int
+	var apiDiscoveryInterval time.Duration
 
 	var secureMetrics = false
 	var enableHTTP2 = false
@@ -96,29 +121,17 @@ func main() {
 	flag.StringVar(&metricsAddr, "metrics-bind-address", fmt.Sprintf(":%d", common.OperatorMetricsPort), "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.StringVar(&labelSelectorFlag, "label-selector", env.StringFromEnv(common.ArgoCDLabelSelectorKey, common.ArgoCDDefaultLabelSelector), "The label selector is used to map to a subset of ArgoCD instances to reconcile")
+	flag.DurationVar(&apiDiscoveryInterval, "api-discovery-interval", env.ParseDurationFromEnv(common.ArgoCDAPIDiscoveryIntervalKey, common.ArgoCDDefaultAPIDiscoveryInterval, 0, math.MaxInt64), "The interval at which the optional APIs served by the cluster, such as Prometheus or OpenShift Routes, are discovered again. 0 disables the discovery.")
+	flag.IntVar(&maxConcurrentReconciles, "max-concurrent-reconciles", env.ParseNumFromEnv(common.ArgoCDMaxConcurrentReconcilesKey, common.ArgoCDDefaultMaxConcurrentReconciles, 1, math.MaxInt32), "The maximum number of ArgoCD instances reconciled concurrently.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", enableHTTP2, "If HTTP/2 should be enabled for the metrics and webhook servers.")
 	flag.BoolVar(&secureMetrics, "metrics-secure", secureMetrics, "If the metrics endpoint should be served securely.")
 
-	//Configure log level
-	logLevelStr := strings.ToLower(os.Getenv("LOG_LEVEL"))
-	logLevel := zapcore.InfoLevel
-	switch logLevelStr {
-	case "debug":
-		logLevel = zapcore.DebugLevel
-	case "info":
-		logLevel = zapcore.InfoLevel
-	case "warn":
-		logLevel = zapcore.WarnLevel
-	case "error":
-		logLevel = zapcore.ErrorLevel
-	case "panic":
-		logLevel = zapcore.PanicLevel
-	case "fatal":
-		logLevel = zapcore.FatalLevel
-	}
+	//Configure log level, the ArgoCDOperatorConfig may change it later on
+	level, err := argocdoperatorconfig.ParseLogLevel(os.Getenv(common.ArgoCDLogLevelEnvName))
+	logLevel := uberzap.NewAtomicLevelAt(level)
 
 	opts := zap.Options{
 		Level:       logLevel,
@@ -146,6 +159,9 @@ func main() {
 	}
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+	if err != nil {
+		setupLog.Info(fmt.Sprintf("%v, using the %s log level", err, level))
+	}
 
 	printVersion()
 
@@ -154,10 +170,10 @@ func main() {
 		setupLog.Error(err, "error parsing the labelSelector '%s'.", labelSelectorFlag)
 		os.Exit(1)
 	}
-	setupLog.Info(fmt.Sprintf("Watching labelselector \"%s\"", labelSelectorFlag))
 
 	// Inspect cluster to verify availability of extra features
-	if err := argocd.InspectCluster(); err != nil {
+	clusterAPIs, err := argocd.InspectCluster()
+	if err != nil {
 		setupLog.Info("unable to inspect cluster")
 	}
 
@@ -189,6 +205,20 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Start with the ArgoCDOperatorConfig of the cluster, the manager cache is not started yet
+	if err := argocdoperatorconfig.Load(context.Background(), mgr.GetAPIReader()); err != nil {
+		setupLog.Error(err, "unable to load the operator configuration, using the environment variables")
+	}
+	if level, err := argocdoperatorconfig.ParseLogLevel(argocdoperatorconfig.Getenv(common.ArgoCDLogLevelEnvName)); err == nil {
+		logLevel.SetLevel(level)
+	}
+	labelSelector := labelSelectorFlag
+	if value, ok := argocdoperatorconfig.Lookup(common.ArgoCDLabelSelectorKey); ok {
+		labelSelector = value
+	}
+	setupLog.Info(fmt.Sprintf("Watching labelselector \"%s\"", labelSelector))
+	enableConversionWebhook := argocdoperatorconfig.ConversionWebhookEnabled()
+
 	setupLog.Info("Registering Components.")
 
 	// Setup Scheme for all resources
@@ -202,62 +232,46 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Setup Scheme for Prometheus if available.
-	if argocd.IsPrometheusAPIAvailable() {
-		if err := monitoringv1.AddToScheme(mgr.GetScheme()); err != nil {
-			setupLog.Error(err, "")
-			os.Exit(1)
-		}
-	}
-
-	// Setup Scheme for OpenShift Routes if available.
-	if argocd.IsRouteAPIAvailable() {
-		if err := routev1.Install(mgr.GetScheme()); err != nil {
-			setupLog.Error(err, "")
-			os.Exit(1)
-		}
-	}
-
-	// Set up the scheme for openshift config if available
-	if argocd.IsVersionAPIAvailable() {
-		if err := configv1.Install(mgr.GetScheme()); err != nil {
+	// Setup Scheme for the optional APIs. The types are registered even when their API is not served by the cluster,
+	// so that they can be used once the API is discovered.
+	for _, addToScheme := range optionalAPIs {
+		if err := addToScheme(mgr.GetScheme()); err != nil {
 			setupLog.Error(err, "")
 			os.Exit(1)
 		}
 	}
 
-	// Setup Schemes for SSO if template instance is available.
-	if argocd.CanUseKeycloakWithTemplate() {
+	if clusterAPIs.CanUseKeycloakWithTemplate() {
 		setupLog.Info("Keycloak instance can be managed using OpenShift Template")
-		if err := templatev1.Install(mgr.GetScheme()); err != nil {
-			setupLog.Error(err, "")
-			os.Exit(1)
-		}
-		if err := appsv1.Install(mgr.GetScheme()); err != nil {
-			setupLog.Error(err, "")
-			os.Exit(1)
-		}
-		if err := oauthv1.Install(mgr.GetScheme()); err != nil {
-			setupLog.Error(err, "")
-			os.Exit(1)
-		}
 	} else {
 		setupLog.Info("Keycloak instance cannot be managed using OpenShift Template, as DeploymentConfig/Template API is not present")
 	}
 
-	if err = (&argocd.ReconcileArgoCD{
-		Client:        mgr.GetClient(),
-		Scheme:        mgr.GetScheme(),
-		LabelSelector: labelSelectorFlag,
-	}).SetupWithManager(mgr); err != nil {
+	argocdReconciler := &argocd.ReconcileArgoCD{
+		Client:                  mgr.GetClient(),
+		Scheme:                  mgr.GetScheme(),
+		LabelSelector:           labelSelectorFlag,
+		ClusterAPIs:             clusterAPIs,
+		MaxConcurrentReconciles: maxConcurrentReconciles,
+		APIDiscoveryInterval:    apiDiscoveryInterval,
+	}
+	if err = argocdReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ArgoCD")
 		os.Exit(1)
 	}
 	if err = (&argocdexport.ReconcileArgoCDExport{
+		Client:      mgr.GetClient(),
+		Scheme:      mgr.GetScheme(),
+		ClusterAPIs: clusterAPIs,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ArgoCDExport")
+		os.Exit(1)
+	}
+	if err = (&argocdcluster.ArgoCDClusterReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "ArgoCDExport")
+		setupLog.Error(err, "unable to create controller", "controller", "ArgoCDCluster")
 		os.Exit(1)
 	}
 	if err = (&notificationsConfig.NotificationsConfigurationReconciler{
@@ -268,8 +282,20 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Start webhook only if ENABLE_CONVERSION_WEBHOOK is set
-	if strings.EqualFold(os.Getenv("ENABLE_CONVERSION_WEBHOOK"), "true") {
+	if err = (&argocdoperatorconfig.ArgoCDOperatorConfigReconciler{
+		Client:            mgr.GetClient(),
+		Scheme:            mgr.GetScheme(),
+		LabelSelector:     labelSelectorFlag,
+		ConversionWebhook: enableConversionWebhook,
+		LogLevel:          &logLevel,
+		OnChange:          argocdReconciler.RequeueInstances,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ArgoCDOperatorConfig")
+		os.Exit(1)
+	}
+
+	// Start webhook only if ENABLE_CONVERSION_WEBHOOK, or the ArgoCDOperatorConfig, enables it
+	if enableConversionWebhook {
 		if err = (&v1beta1.ArgoCD{}).SetupWebhookWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "ArgoCD")
 			os.Exit(1)
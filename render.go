@@ -0,0 +1,323 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/pmezard/go-difflib/difflib"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/yaml"
+
+	v1alpha1 "github.com/argoproj-labs/argocd-operator/api/v1alpha1"
+	v1beta1 "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/controllers/argocd"
+)
+
+const renderUsage = `Render the manifests the operator creates for an ArgoCD, without contacting any cluster.
+
+Usage:
+  argocd-operator render --file argocd.yaml [--existing objects.yaml] [--diff-from previous-argocd.yaml]
+
+With --diff-from, the manifests rendered for both revisions of the ArgoCD are compared, and the exit code is 1 when
+they differ.
+
+Flags:
+`
+
+// redactedValue replaces the values of the rendered Secrets.
+const redactedValue = "<redacted>"
+
+// errRenderDiff reports that the rendered revisions differ.
+var errRenderDiff = errors.New("the rendered manifests differ")
+
+// runRender runs the render subcommand with the given arguments, and returns its exit code.
+func runRender(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("render", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, renderUsage)
+		flags.PrintDefaults()
+	}
+	var file, existingFile, diffFrom, namespace, apis string
+	var verbose, showSecrets bool
+	flags.StringVar(&file, "file", "", "The file holding the ArgoCD to render.")
+	flags.StringVar(&existingFile, "existing", "", "The file holding the objects held by the cluster before the reconciliation, such as Secrets referenced by the ArgoCD.")
+	flags.StringVar(&diffFrom, "diff-from", "", "The file holding the previous revision of the ArgoCD, to print the differences between the manifests of both revisions.")
+	flags.StringVar(&namespace, "namespace", "default", "The namespace of the ArgoCD, when its manifest sets none.")
+	flags.StringVar(&apis, "apis", "", "The comma separated optional APIs served by the cluster, among route, gateway, grpcroute and prometheus.")
+	flags.BoolVar(&verbose, "verbose", false, "Print the logs of the reconciliation.")
+	flags.BoolVar(&showSecrets, "show-secrets", false, "Print the data of the Secrets, which is redacted otherwise.")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if file == "" {
+		fmt.Fprintln(stderr, "--file is required")
+		flags.Usage()
+		return 2
+	}
+
+	if verbose {
+		ctrl.SetLogger(zap.New(zap.WriteTo(stderr), zap.UseDevMode(true)))
+	} else {
+		ctrl.SetLogger(logr.Discard())
+	}
+
+	err := render(stdout, renderOptions{
+		file:         file,
+		existingFile: existingFile,
+		diffFrom:     diffFrom,
+		namespace:    namespace,
+		apis:         apis,
+		showSecrets:  showSecrets,
+	})
+	if errors.Is(err, errRenderDiff) {
+		return 1
+	} else if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 2
+	}
+	return 0
+}
+
+type renderOptions struct {
+	file         string
+	existingFile string
+	diffFrom     string
+	namespace    string
+	apis         string
+	showSecrets  bool
+}
+
+// render prints the manifests rendered for the ArgoCD of opts, or their differences with the previous revision.
+func render(out io.Writer, opts renderOptions) error {
+	// The reconciliation runs against an in-memory cluster, serving all the optional APIs types
+	sch := scheme
+	for _, addToScheme := range optionalAPIs {
+		if err := addToScheme(sch); err != nil {
+			return err
+		}
+	}
+
+	renderOpts := argocd.RenderOptions{}
+	if opts.apis != "" {
+		renderOpts.APIs = strings.Split(opts.apis, ",")
+	}
+	if opts.existingFile != "" {
+		existing, err := readObjects(sch, opts.existingFile)
+		if err != nil {
+			return err
+		}
+		renderOpts.Existing = existing
+	}
+
+	cr, err := readArgoCD(sch, opts.file, opts.namespace)
+	if err != nil {
+		return err
+	}
+	if opts.diffFrom == "" {
+		rendered, err := argocd.Render(context.Background(), sch, cr, renderOpts)
+		if err != nil {
+			return err
+		}
+		manifests, err := toManifests(rendered, opts.showSecrets)
+		if err != nil {
+			return err
+		}
+		for _, key := range sortedKeys(manifests) {
+			fmt.Fprintf(out, "---\n%s", manifests[key])
+		}
+		return nil
+	}
+
+	previousCR, err := readArgoCD(sch, opts.diffFrom, opts.namespace)
+	if err != nil {
+		return err
+	}
+	before, after, err := argocd.RenderRevisions(context.Background(), sch, previousCR, cr, renderOpts)
+	if err != nil {
+		return err
+	}
+	previous, err := toManifests(before, opts.showSecrets)
+	if err != nil {
+		return err
+	}
+	manifests, err := toManifests(after, opts.showSecrets)
+	if err != nil {
+		return err
+	}
+	keys := sortedKeys(manifests)
+	for key := range previous {
+		if _, ok := manifests[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	differ := false
+	for _, key := range keys {
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(previous[key]),
+			B:        difflib.SplitLines(manifests[key]),
+			FromFile: "a/" + key,
+			ToFile:   "b/" + key,
+			Context:  3,
+		})
+		if err != nil {
+			return err
+		}
+		if diff != "" {
+			differ = true
+			fmt.Fprint(out, diff)
+		}
+	}
+	if differ {
+		return errRenderDiff
+	}
+	return nil
+}
+
+// readArgoCD reads the ArgoCD of the given file, converted to v1beta1.
+func readArgoCD(sch *runtime.Scheme, file, namespace string) (*v1beta1.ArgoCD, error) {
+	objs, err := readObjects(sch, file)
+	if err != nil {
+		return nil, err
+	}
+	if len(objs) != 1 {
+		return nil, fmt.Errorf("%s must hold a single ArgoCD, found %d objects", file, len(objs))
+	}
+
+	var cr *v1beta1.ArgoCD
+	switch obj := objs[0].(type) {
+	case *v1beta1.ArgoCD:
+		cr = obj
+	case *v1alpha1.ArgoCD:
+		cr = &v1beta1.ArgoCD{}
+		if err := obj.ConvertTo(cr); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%s must hold an ArgoCD, found a %T", file, obj)
+	}
+	if cr.Namespace == "" {
+		cr.Namespace = namespace
+	}
+	return cr, nil
+}
+
+// toManifests returns the YAML manifests of the given objects by kind, namespace and name. The data of the Secrets is
+// redacted unless showSecrets is true.
+func toManifests(objs []*unstructured.Unstructured, showSecrets bool) (map[string]string, error) {
+	manifests := map[string]string{}
+	for _, obj := range objs {
+		if !showSecrets {
+			obj = redactSecret(obj)
+		}
+		manifest, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return nil, err
+		}
+		manifests[manifestKey(obj)] = string(manifest)
+	}
+	return manifests, nil
+}
+
+// redactSecret returns a copy of the given object whose data and stringData values are replaced with redactedValue
+// when it is a Secret, the object itself otherwise.
+func redactSecret(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	if obj.GetAPIVersion() != "v1" || obj.GetKind() != "Secret" {
+		return obj
+	}
+	obj = obj.DeepCopy()
+	for _, field := range []string{"data", "stringData"} {
+		values, found, err := unstructured.NestedMap(obj.Object, field)
+		if !found || err != nil {
+			continue
+		}
+		for key := range values {
+			values[key] = redactedValue
+		}
+		if err := unstructured.SetNestedMap(obj.Object, values, field); err != nil {
+			unstructured.RemoveNestedField(obj.Object, field)
+		}
+	}
+	return obj
+}
+
+// readObjects decodes the objects of the given YAML or JSON file, which may hold several documents.
+func readObjects(sch *runtime.Scheme, file string) ([]client.Object, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	decoder := serializer.NewCodecFactory(sch).UniversalDeserializer()
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+	var objs []client.Object
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		if len(bytes.TrimSpace(doc)) == 0 || bytes.Equal(bytes.TrimSpace(doc), []byte("null")) {
+			continue
+		}
+		obj, _, err := decoder.Decode(doc, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", file, err)
+		}
+		clientObj, ok := obj.(client.Object)
+		if !ok {
+			return nil, fmt.Errorf("failed to decode %s: %T is not an object", file, obj)
+		}
+		objs = append(objs, clientObj)
+	}
+	return objs, nil
+}
+
+func manifestKey(obj *unstructured.Unstructured) string {
+	if obj.GetNamespace() == "" {
+		return fmt.Sprintf("%s/%s", obj.GetKind(), obj.GetName())
+	}
+	return fmt.Sprintf("%s/%s/%s", obj.GetKind(), obj.GetNamespace(), obj.GetName())
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
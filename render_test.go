@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestToManifests_redactsSecrets(t *testing.T) {
+	secret := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]interface{}{"name": "argocd-secret", "namespace": "argocd"},
+		"data":       map[string]interface{}{"admin.password": "c2VjcmV0"},
+		"stringData": map[string]interface{}{"server.secretkey": "secret"},
+	}}
+	cm := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "argocd-cm", "namespace": "argocd"},
+		"data":       map[string]interface{}{"admin.enabled": "true"},
+	}}
+
+	manifests, err := toManifests([]*unstructured.Unstructured{secret, cm}, false)
+	assert.NoError(t, err)
+	assert.NotContains(t, manifests["Secret/argocd/argocd-secret"], "c2VjcmV0")
+	assert.NotContains(t, manifests["Secret/argocd/argocd-secret"], "server.secretkey: secret")
+	assert.Contains(t, manifests["Secret/argocd/argocd-secret"], "admin.password: <redacted>")
+	assert.Contains(t, manifests["ConfigMap/argocd/argocd-cm"], `admin.enabled: "true"`)
+	// the rendered objects are left untouched
+	assert.Equal(t, "c2VjcmV0", secret.Object["data"].(map[string]interface{})["admin.password"])
+
+	manifests, err = toManifests([]*unstructured.Unstructured{secret}, true)
+	assert.NoError(t, err)
+	assert.Contains(t, manifests["Secret/argocd/argocd-secret"], "admin.password: c2VjcmV0")
+}